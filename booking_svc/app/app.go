@@ -0,0 +1,281 @@
+// Package app wires booking_svc together: services, the booking.accepted
+// and payout.paid consumers, the webhook dispatcher, the payment hold expirer
+// and the HTTP and gRPC servers. The binary runs it on Postgres and Kafka;
+// the end-to-end tests run it in-process on the in-memory repositories and
+// bus.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/bus"
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/config"
+	"booking_svc/internal/fare"
+	"booking_svc/internal/grpcserver"
+	handlergrpc "booking_svc/internal/handler/grpc"
+	handlerhttp "booking_svc/internal/handler/http"
+	"booking_svc/internal/httpserver"
+	"booking_svc/internal/ledger"
+	"booking_svc/internal/money"
+	"booking_svc/internal/mq"
+	"booking_svc/internal/payment"
+	"booking_svc/internal/repository"
+	"booking_svc/internal/repository/memory"
+	"booking_svc/internal/scheduler"
+	"booking_svc/internal/service"
+	"booking_svc/internal/webhook"
+)
+
+// Aliases let callers outside this module configure the app and bridge the
+// bus without importing internal packages.
+type (
+	Config     = config.Config
+	Bus        = bus.Bus
+	Message    = bus.Message
+	Header     = bus.Header
+	Subscriber = bus.Subscriber
+)
+
+// ErrBusClosed is what a Bus returns once closed.
+var ErrBusClosed = bus.ErrClosed
+
+func LoadConfig() Config {
+	return config.LoadFromEnv("booking_svc", "8080")
+}
+
+// Deps are the storage and broker the app runs on. The caller owns them and
+// closes the bus after the app has stopped.
+type Deps struct {
+	Bookings repository.BookingRepository
+	Webhooks repository.WebhookRepository
+	Payments repository.PaymentRepository
+	Ledger   repository.LedgerRepository
+	// Promotions must share storage with Bookings, which redeems them.
+	Promotions repository.PromotionRepository
+	Ratings    repository.RatingRepository
+	Bus        bus.Bus
+}
+
+// InMemory returns fresh in-memory repositories on top of b.
+func InMemory(b Bus) Deps {
+	bookings := memory.NewBookingRepo()
+	return Deps{
+		Bookings:   bookings,
+		Webhooks:   memory.NewWebhookRepo(),
+		Payments:   memory.NewPaymentRepo(),
+		Ledger:     memory.NewLedgerRepo(),
+		Promotions: memory.NewPromotionRepo(bookings),
+		Ratings:    memory.NewRatingRepo(),
+		Bus:        b,
+	}
+}
+
+func NewMemoryBus() Bus {
+	return bus.NewMemory()
+}
+
+type App struct {
+	cfg        Config
+	logger     *slog.Logger
+	http       *httpserver.Server
+	grpc       *grpcserver.Server
+	consumer   *mq.BookingAcceptedConsumer
+	payouts    *mq.PayoutPaidConsumer
+	dispatcher *webhook.Dispatcher
+	expirer    *payment.Expirer
+	scheduler  *scheduler.Scheduler
+}
+
+func New(cfg Config, logger *slog.Logger, deps Deps) (*App, error) {
+	if !money.ValidCurrency(cfg.DefaultCurrency) {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY %q is not a supported ISO 4217 code", cfg.DefaultCurrency)
+	}
+	commission := ledger.Rate(cfg.CommissionBPS)
+	if err := commission.Validate(); err != nil {
+		return nil, fmt.Errorf("LEDGER_COMMISSION_BPS: %w", err)
+	}
+	proxies, err := httpserver.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+	policy := cancellation.Policy{
+		FreeWindow:   cfg.CancellationFreeWindow,
+		LateFeeBPS:   cfg.CancellationFeeBPS,
+		NoShowWait:   cfg.NoShowWait,
+		NoShowFeeBPS: cfg.NoShowFeeBPS,
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("cancellation policy: %w", err)
+	}
+	reserve := service.Reservations{Lead: cfg.ScheduleLead, MaxAhead: cfg.ScheduleMaxAhead}
+	if err := reserve.Validate(); err != nil {
+		return nil, fmt.Errorf("SCHEDULE_LEAD_SECONDS/SCHEDULE_MAX_AHEAD_DAYS: %w", err)
+	}
+	tariff := fare.Tariff{Currency: cfg.DefaultCurrency, Base: cfg.FareBase, PerKm: cfg.FarePerKm, PerStop: cfg.FarePerStop}
+	if err := tariff.Validate(); err != nil {
+		return nil, fmt.Errorf("FARE_BASE/FARE_PER_KM/FARE_PER_STOP: %w", err)
+	}
+	authn, err := auth.NewAuthenticator(authConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("auth setup: %w", err)
+	}
+
+	gateway, err := payment.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	producer := mq.NewProducer(cfg, deps.Bus, logger)
+	webhookSvc := service.NewWebhookService(deps.Webhooks)
+	// changes wakes gRPC WatchBooking streams as soon as a booking moves
+	changes := service.NewBroadcaster()
+	payments := service.NewPayments(deps.Payments, gateway, cfg.PaymentHoldTTL, logger)
+	ledgerSvc := service.NewLedger(deps.Ledger, commission, cfg.DefaultCurrency, logger)
+	promos := service.NewPromotions(deps.Promotions, logger)
+	fares := service.NewFares(tariff)
+	svc := service.NewBookingService(deps.Bookings, producer, webhookSvc, changes, payments, promos, fares, ledgerSvc, policy, reserve, cfg.DefaultCurrency, logger)
+	ratings := service.NewRatingService(deps.Ratings, svc, producer, logger)
+	// Consumer: booking.accepted -> mark booking Accepted
+	consumer := mq.NewBookingAcceptedConsumer(cfg, deps.Bus, deps.Bookings, service.Notifiers{webhookSvc, changes}, logger)
+	// Consumer: payout.paid -> settle the driver's ledger account
+	payouts := mq.NewPayoutPaidConsumer(cfg, deps.Bus, ledgerSvc, logger)
+	// Webhook dispatcher: drains the delivery queue with retries
+	dispatcher := webhook.NewDispatcher(deps.Webhooks, webhook.DispatcherConfig{
+		PollInterval: cfg.WebhookPollInterval,
+		BatchSize:    cfg.WebhookBatchSize,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		BaseBackoff:  cfg.WebhookBaseBackoff,
+		MaxBackoff:   cfg.WebhookMaxBackoff,
+		Timeout:      cfg.WebhookTimeout,
+	}, logger)
+	// Expirer: settles authorization holds nobody captured or released
+	expirer := payment.NewExpirer(svc, cfg.PaymentExpiryInterval, logger)
+	// Scheduler: releases reserved rides to drivers ahead of pickup
+	releaser := scheduler.New(svc, cfg.ScheduleInterval, logger)
+
+	srv := httpserver.New(cfg, logger, authn, proxies)
+	handlerhttp.NewBookingHandler(svc).RegisterRoutes(srv.Router())
+	handlerhttp.NewWebhookHandler(webhookSvc).RegisterRoutes(srv.Router())
+	handlerhttp.NewReconcileHandler(svc).RegisterRoutes(srv.Router())
+	handlerhttp.NewLedgerHandler(ledgerSvc).RegisterRoutes(srv.Router())
+	handlerhttp.NewPromotionHandler(promos).RegisterRoutes(srv.Router())
+	handlerhttp.NewRatingHandler(ratings).RegisterRoutes(srv.Router())
+	handlerhttp.NewFareHandler(fares).RegisterRoutes(srv.Router())
+	srv.AddReadinessCheck(cfg.BusDriver, func(ctx context.Context) error {
+		return deps.Bus.Check(ctx, cfg.TopicBookingCreated, cfg.TopicBookingAccepted, cfg.TopicBookingCancelled, cfg.TopicBookingCompleted, cfg.TopicRatingSubmitted, cfg.TopicPayoutPaid)
+	})
+	srv.AddReadinessCheck("consumer."+cfg.TopicBookingAccepted, consumer.Healthy)
+	srv.AddReadinessCheck("consumer."+cfg.TopicPayoutPaid, payouts.Healthy)
+
+	// gRPC server shares the authenticator and service with HTTP
+	grpcSrv := grpcserver.New(cfg, logger, authn)
+	handlergrpc.NewBookingServer(svc).Register(grpcSrv.Registrar())
+
+	return &App{cfg: cfg, logger: logger, http: srv, grpc: grpcSrv, consumer: consumer, payouts: payouts, dispatcher: dispatcher, expirer: expirer, scheduler: releaser}, nil
+}
+
+// AddReadinessCheck registers another dependency probed by /readyz.
+func (a *App) AddReadinessCheck(name string, fn func(context.Context) error) {
+	a.http.AddReadinessCheck(name, fn)
+}
+
+// ShedOnPoolWait feeds the load shedder a DB pool wait signal.
+func (a *App) ShedOnPoolWait(fn func() time.Duration) {
+	a.http.ShedOnPoolWait(fn)
+}
+
+// Handler is the full HTTP API, for serving from a test server.
+func (a *App) Handler() http.Handler {
+	return a.http.Handler()
+}
+
+// Start runs the consumers, webhook dispatcher, hold expirer and scheduler in
+// the background until ctx ends.
+func (a *App) Start(ctx context.Context) {
+	go func() {
+		if err := a.consumer.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("booking.accepted consumer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.payouts.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("payout.paid consumer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.dispatcher.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("webhook dispatcher stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.expirer.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("payment hold expirer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.scheduler.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("scheduled booking releaser stopped", slog.String("err", err.Error()))
+		}
+	}()
+}
+
+// Run starts the workers and both servers, blocks until ctx ends or a server
+// fails, then shuts the servers down gracefully.
+func (a *App) Run(ctx context.Context) {
+	a.Start(ctx)
+	errCh := a.http.Start()
+	grpcErrCh := a.grpc.Start()
+
+	select {
+	case <-ctx.Done():
+	case err := <-errCh:
+		if err != nil {
+			a.logger.Error("server error", slog.String("err", err.Error()))
+		}
+	case err := <-grpcErrCh:
+		if err != nil {
+			a.logger.Error("grpc server error", slog.String("err", err.Error()))
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.GracefulTimeout)
+	defer cancel()
+	a.grpc.Shutdown(shutdownCtx)
+	_ = a.http.Shutdown(shutdownCtx)
+}
+
+// Close leaves the consumer groups.
+func (a *App) Close() error {
+	return errors.Join(a.consumer.Close(), a.payouts.Close())
+}
+
+// Token mints an HS256 token for role (rider, driver or admin) acting as sub.
+func Token(secret, role, sub string, ttl time.Duration) (string, error) {
+	p := auth.Principal{Subject: sub, Role: auth.Role(role)}
+	switch p.Role {
+	case auth.RoleRider:
+		p.RiderID = sub
+	case auth.RoleDriver:
+		p.DriverID = sub
+	case auth.RoleAdmin:
+	default:
+		return "", fmt.Errorf("unknown role %q", role)
+	}
+	return auth.NewHS256Token(secret, p, ttl)
+}
+
+func authConfig(cfg Config) auth.Config {
+	return auth.Config{
+		HS256Secret:    cfg.JWTHS256Secret,
+		RS256PublicKey: cfg.JWTRS256PublicKey,
+		JWKSFile:       cfg.JWTJWKSFile,
+		Issuer:         cfg.JWTIssuer,
+		Audience:       cfg.JWTAudience,
+	}
+}
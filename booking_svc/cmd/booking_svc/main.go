@@ -2,86 +2,124 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"booking_svc/internal/config"
+	"booking_svc/app"
+	"booking_svc/internal/bus"
 	"booking_svc/internal/db"
-	handlerhttp "booking_svc/internal/handler/http"
-	"booking_svc/internal/httpserver"
 	"booking_svc/internal/logging"
-	"booking_svc/internal/mq"
+	"booking_svc/internal/metrics"
 	"booking_svc/internal/repository/postgres"
-	"booking_svc/internal/service"
+	"booking_svc/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var version = "0.1.0"
 
 func main() {
-	cfg := config.LoadFromEnv("booking_svc", "8080")
+	cfg := app.LoadConfig()
 	logger := logging.New(cfg.LogLevel, cfg.ServiceName).With(slog.String("version", version))
 
 	// Signal context for graceful shutdown and consumers
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// DB connect + bootstrap
-	startupCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer cancel()
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		if err := runToken(cfg, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
 
-	pool, err := db.Connect(startupCtx, db.Params{
-		Host:     cfg.DBHost,
-		Port:     cfg.DBPort,
-		User:     cfg.DBUser,
-		Password: cfg.DBPassword,
-		Name:     cfg.DBName,
-	})
-	if err != nil {
-		logger.Error("db connect failed", slog.String("err", err.Error()))
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, cfg, logger, os.Args[2:]); err != nil {
+			logger.Error("migrate failed", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
 		return
 	}
-	defer pool.Close()
 
-	if err := db.Bootstrap(startupCtx, pool); err != nil {
-		logger.Error("db bootstrap failed", slog.String("err", err.Error()))
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if err := runReconcile(ctx, cfg, logger, os.Args[2:]); err != nil {
+			if !errors.Is(err, errUnhealed) {
+				logger.Error("reconcile failed", slog.String("err", err.Error()))
+			}
+			os.Exit(1)
+		}
 		return
 	}
 
-	// Repo + MQ producer + service
-	repo := postgres.NewBookingRepo(pool)
-	producer := mq.NewProducer(cfg, logger)
+	// Tracing: exporter chosen by OTEL_TRACES_EXPORTER
+	exp, err := tracing.NewExporter(ctx, cfg.TracesExporter)
+	if err != nil {
+		logger.Error("tracing exporter failed", slog.String("err", err.Error()))
+		return
+	}
+	tp := tracing.Init(cfg.ServiceName, version, exp)
 	defer func() {
-		_ = producer.Close()
-	}()
-	svc := service.NewBookingService(repo, producer, logger)
-	// Consumer: booking.accepted -> mark booking Accepted
-	acceptConsumer := mq.NewBookingAcceptedConsumer(cfg, repo, logger)
-	defer func() { _ = acceptConsumer.Close() }()
-	go func() {
-		if err := acceptConsumer.Run(ctx); err != nil && ctx.Err() == nil {
-			logger.Error("booking.accepted consumer stopped", slog.String("err", err.Error()))
-		}
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = tp.Shutdown(flushCtx)
 	}()
-	// HTTP server + routes
-	srv := httpserver.New(cfg, logger)
-	handler := handlerhttp.NewBookingHandler(svc)
-	handler.RegisterRoutes(srv.Router())
 
-	// Start and graceful shutdown
-	errCh := srv.Start()
+	// DB connect + migrate
+	startupCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	pool, err := db.Connect(startupCtx, dbParams(cfg))
+	if err != nil {
+		logger.Error("db connect failed", slog.String("err", err.Error()))
+		return
+	}
+	defer pool.Close()
 
-	select {
-	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.GracefulTimeout)
-		defer cancel()
-		_ = srv.Shutdown(shutdownCtx)
-	case err := <-errCh:
+	if cfg.MigrateOnStart {
+		migrator, err := db.NewMigrator(pool, logger)
 		if err != nil {
-			logger.Error("server error", slog.String("err", err.Error()))
+			logger.Error("load migrations failed", slog.String("err", err.Error()))
+			return
+		}
+		if err := migrator.Up(startupCtx); err != nil {
+			logger.Error("db migrate failed", slog.String("err", err.Error()))
+			return
 		}
 	}
+
+	prometheus.MustRegister(metrics.NewPoolCollector(pool))
+
+	// Bus + app (services, consumer, webhook dispatcher, HTTP and gRPC)
+	msgBus, err := bus.Open(cfg)
+	if err != nil {
+		logger.Error("message bus setup failed", slog.String("err", err.Error()))
+		return
+	}
+	defer func() { _ = msgBus.Close() }()
+	a, err := app.New(cfg, logger, app.Deps{
+		Bookings:   postgres.NewBookingRepo(pool),
+		Webhooks:   postgres.NewWebhookRepo(pool),
+		Payments:   postgres.NewPaymentRepo(pool),
+		Ledger:     postgres.NewLedgerRepo(pool),
+		Promotions: postgres.NewPromotionRepo(pool),
+		Ratings:    postgres.NewRatingRepo(pool),
+		Bus:        msgBus,
+	})
+	if err != nil {
+		logger.Error("app setup failed", slog.String("err", err.Error()))
+		return
+	}
+	defer func() { _ = a.Close() }()
+	a.ShedOnPoolWait(db.NewAcquireWait(pool, time.Second).Current)
+	a.AddReadinessCheck("postgres", pool.Ping)
+
+	// Run until signalled, then shut down gracefully
+	a.Run(ctx)
 	logger.Info("exit")
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"booking_svc/app"
+	"booking_svc/internal/auth"
+	"booking_svc/internal/config"
+	"booking_svc/internal/reconcile"
+)
+
+// errUnhealed makes the reconcile subcommand exit non-zero so cron and CI
+// notice drift that needs a human.
+var errUnhealed = errors.New("unhealed mismatches remain")
+
+// runReconcile implements the `reconcile` subcommand, which compares bookings
+// with driver_svc's jobs over the admin-only /internal APIs and, with -heal,
+// republishes the events the lagging side missed. With -interval it repeats
+// until ctx is cancelled.
+func runReconcile(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	bookingURL := fs.String("booking-url", "http://localhost:"+cfg.HTTPPort, "booking_svc base URL")
+	driverURL := fs.String("driver-url", "http://localhost:8081", "driver_svc base URL")
+	token := fs.String("token", "", "admin bearer token (default: minted from JWT_HS256_SECRET)")
+	lookback := fs.Duration("lookback", 24*time.Hour, "how far back to compare")
+	grace := fs.Duration("grace", time.Minute, "skip items newer than this; they may still be in flight")
+	heal := fs.Bool("heal", false, "republish missing booking.created/booking.accepted/booking.cancelled events")
+	interval := fs.Duration("interval", 0, "repeat every interval; 0 runs once")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *token == "" {
+		if cfg.JWTHS256Secret == "" {
+			return errors.New("pass -token or set JWT_HS256_SECRET")
+		}
+		tok, err := app.Token(cfg.JWTHS256Secret, string(auth.RoleAdmin), "reconcile", 24*time.Hour)
+		if err != nil {
+			return err
+		}
+		*token = tok
+	}
+
+	bookings := reconcile.NewBookingClient(*bookingURL, *token)
+	jobs := reconcile.NewJobClient(*driverURL, *token)
+	once := func() error {
+		rep, err := reconcile.Run(ctx, bookings, jobs, reconcile.Options{
+			From:  time.Now().Add(-*lookback),
+			Grace: *grace,
+			Heal:  *heal,
+		}, logger)
+		if err != nil {
+			return err
+		}
+		printReport(rep)
+		if rep.Unhealed() > 0 {
+			return errUnhealed
+		}
+		return nil
+	}
+
+	if *interval <= 0 {
+		return once()
+	}
+	t := time.NewTicker(*interval)
+	defer t.Stop()
+	for {
+		if err := once(); err != nil && !errors.Is(err, errUnhealed) {
+			logger.Error("reconcile failed", slog.String("err", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+func printReport(rep reconcile.Report) {
+	w := os.Stdout
+	fmt.Fprintf(w, "window %s .. %s: %d bookings, %d jobs, %d mismatches (%d unhealed)\n",
+		rep.From.UTC().Format(time.RFC3339), rep.To.UTC().Format(time.RFC3339),
+		rep.Bookings, rep.Jobs, len(rep.Mismatches), rep.Unhealed())
+	counts := rep.Counts()
+	kinds := make([]string, 0, len(counts))
+	for k := range counts {
+		kinds = append(kinds, string(k))
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Fprintf(w, "  %-20s %d\n", k, counts[reconcile.Kind(k)])
+	}
+	for _, m := range rep.Mismatches {
+		state := "unhealed"
+		switch {
+		case m.Healed:
+			state = "healed"
+		case m.HealErr != nil:
+			state = "heal failed: " + m.HealErr.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.BookingID, m.Kind, state, m.Detail)
+	}
+}
@@ -0,0 +1,44 @@
+// Package auth validates bearer JWTs and carries the caller's identity through request contexts.
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+type Role string
+
+const (
+	RoleRider  Role = "rider"
+	RoleDriver Role = "driver"
+	RoleAdmin  Role = "admin"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject  string
+	Role     Role
+	RiderID  string
+	DriverID string
+}
+
+func (p Principal) HasRole(roles ...Role) bool {
+	return slices.Contains(roles, p.Role)
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
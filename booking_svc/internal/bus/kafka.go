@@ -0,0 +1,119 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka publishes through one shared writer and opens a group reader per
+// subscription.
+type Kafka struct {
+	brokers []string
+	writer  *kafka.Writer
+	client  *kafka.Client
+}
+
+func NewKafka(brokers []string) *Kafka {
+	return &Kafka{
+		brokers: brokers,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{}, // same key, same partition
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true, // dead-letter topics are created on first use
+		},
+		client: &kafka.Client{Addr: kafka.TCP(brokers...)},
+	}
+}
+
+func (k *Kafka) Publish(ctx context.Context, msgs ...Message) error {
+	out := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = kafka.Message{Topic: m.Topic, Key: m.Key, Value: m.Value, Headers: toKafkaHeaders(m.Headers)}
+	}
+	return k.writer.WriteMessages(ctx, out...)
+}
+
+func (k *Kafka) Subscribe(topic, group string) Subscriber {
+	return &kafkaSubscriber{reader: kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        k.brokers,
+		GroupID:        group,
+		Topic:          topic,
+		MinBytes:       1,
+		MaxBytes:       10e6,
+		StartOffset:    kafka.FirstOffset,
+		CommitInterval: 0, // commit only when the handler says so
+	})}
+}
+
+// Check fetches cluster metadata for topics.
+func (k *Kafka) Check(ctx context.Context, topics ...string) error {
+	md, err := k.client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return err
+	}
+	if len(md.Brokers) == 0 {
+		return errors.New("no brokers in metadata")
+	}
+	for _, t := range md.Topics {
+		if t.Error != nil {
+			return fmt.Errorf("topic %s: %w", t.Name, t.Error)
+		}
+	}
+	return nil
+}
+
+func (k *Kafka) Close() error { return k.writer.Close() }
+
+type kafkaSubscriber struct {
+	reader *kafka.Reader
+}
+
+func (s *kafkaSubscriber) Fetch(ctx context.Context) (Message, error) {
+	m, err := s.reader.FetchMessage(ctx)
+	if errors.Is(err, io.EOF) {
+		return Message{}, ErrClosed
+	}
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Topic:         m.Topic,
+		Key:           m.Key,
+		Value:         m.Value,
+		Headers:       fromKafkaHeaders(m.Headers),
+		Partition:     m.Partition,
+		Offset:        m.Offset,
+		HighWaterMark: m.HighWaterMark,
+	}, nil
+}
+
+func (s *kafkaSubscriber) Commit(ctx context.Context, msgs ...Message) error {
+	out := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = kafka.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset}
+	}
+	return s.reader.CommitMessages(ctx, out...)
+}
+
+func (s *kafkaSubscriber) Close() error { return s.reader.Close() }
+
+func toKafkaHeaders(hs []Header) []kafka.Header {
+	out := make([]kafka.Header, len(hs))
+	for i, h := range hs {
+		out[i] = kafka.Header{Key: h.Key, Value: h.Value}
+	}
+	return out
+}
+
+func fromKafkaHeaders(hs []kafka.Header) []Header {
+	out := make([]Header, len(hs))
+	for i, h := range hs {
+		out[i] = Header{Key: h.Key, Value: h.Value}
+	}
+	return out
+}
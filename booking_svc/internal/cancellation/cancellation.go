@@ -0,0 +1,119 @@
+// Package cancellation decides what a rider owes for calling off a booking.
+// Cancelling is free until a driver is assigned, and for a short window
+// after booking even then; after that a late fee applies. Once the driver
+// has waited at the pickup for long enough, the booking can be cancelled as
+// a no-show, which carries its own fee.
+//
+// Fees are a share of what the rider was held for, so they never exceed it.
+package cancellation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+// ErrNotCancellable means the booking is already completed or cancelled.
+var ErrNotCancellable = errors.New("booking is not cancellable")
+
+// Policy is the cancellation policy. Fees are in basis points of the charge:
+// 1000 is 10%.
+type Policy struct {
+	// FreeWindow is how long after booking the rider may cancel for free,
+	// even with a driver assigned.
+	FreeWindow time.Duration
+	// LateFeeBPS is charged for cancelling after FreeWindow once a driver is
+	// assigned.
+	LateFeeBPS int64
+	// NoShowWait is how long the driver must wait at the pickup before the
+	// booking counts as a no-show.
+	NoShowWait time.Duration
+	// NoShowFeeBPS is charged for a no-show.
+	NoShowFeeBPS int64
+}
+
+// Validate checks that durations are not negative and fees are between 0%
+// and 100%.
+func (p Policy) Validate() error {
+	if p.FreeWindow < 0 {
+		return fmt.Errorf("cancellation free window %s must not be negative", p.FreeWindow)
+	}
+	if p.NoShowWait < 0 {
+		return fmt.Errorf("no-show wait %s must not be negative", p.NoShowWait)
+	}
+	if p.LateFeeBPS < 0 || p.LateFeeBPS > 10000 {
+		return fmt.Errorf("cancellation fee %d bps must be between 0 and 10000", p.LateFeeBPS)
+	}
+	if p.NoShowFeeBPS < 0 || p.NoShowFeeBPS > 10000 {
+		return fmt.Errorf("no-show fee %d bps must be between 0 and 10000", p.NoShowFeeBPS)
+	}
+	return nil
+}
+
+// Booking is the state the policy looks at.
+type Booking struct {
+	Status models.RideStatus
+	// Charge is what the rider was held for: the price less any discount.
+	Charge    money.Money
+	CreatedAt time.Time
+	// ArrivedAt is when the driver reached the pickup; nil if they have not.
+	ArrivedAt *time.Time
+}
+
+// FromBooking is the policy's view of b.
+func FromBooking(b models.Booking) (Booking, error) {
+	charge := b.Price
+	if b.Discount != nil {
+		var err error
+		if charge, err = b.Price.Sub(*b.Discount); err != nil {
+			return Booking{}, err
+		}
+	}
+	return Booking{Status: b.RideStatus, Charge: charge, CreatedAt: b.CreatedAt, ArrivedAt: b.ArrivedAt}, nil
+}
+
+// Outcome is why a cancellation at a given moment is charged, and how much.
+// Fee is zero, in the charge's currency, when it is free.
+type Outcome struct {
+	Reason models.CancellationReason
+	Fee    money.Money
+}
+
+// Evaluate decides what cancelling b at now costs:
+//
+//   - a Scheduled or Requested booking has no driver yet, so it is free;
+//   - an Accepted booking whose driver has waited at the pickup for at least
+//     NoShowWait is a no-show;
+//   - otherwise an Accepted booking is free within FreeWindow of booking, and
+//     charged the late fee after it.
+//
+// Fees round half away from zero. A completed or cancelled booking returns
+// ErrNotCancellable.
+func (p Policy) Evaluate(b Booking, now time.Time) (Outcome, error) {
+	free := Outcome{Reason: models.CancellationFree, Fee: money.Money{Currency: b.Charge.Currency}}
+	switch b.Status {
+	case models.RideStatusScheduled, models.RideStatusRequested:
+		return free, nil
+	case models.RideStatusAccepted:
+	default:
+		return Outcome{}, ErrNotCancellable
+	}
+	if b.ArrivedAt != nil && now.Sub(*b.ArrivedAt) >= p.NoShowWait {
+		return p.charge(models.CancellationNoShow, b.Charge, p.NoShowFeeBPS)
+	}
+	if now.Sub(b.CreatedAt) < p.FreeWindow {
+		return free, nil
+	}
+	return p.charge(models.CancellationLate, b.Charge, p.LateFeeBPS)
+}
+
+func (p Policy) charge(reason models.CancellationReason, charge money.Money, bps int64) (Outcome, error) {
+	fee, err := charge.Mul(bps, 10000)
+	if err != nil {
+		return Outcome{}, err
+	}
+	return Outcome{Reason: reason, Fee: fee}, nil
+}
@@ -0,0 +1,100 @@
+package cancellation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+var booked = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func TestEvaluate(t *testing.T) {
+	policy := Policy{FreeWindow: 2 * time.Minute, LateFeeBPS: 1000, NoShowWait: 5 * time.Minute, NoShowFeeBPS: 2500}
+	charge := money.Money{Amount: 20005, Currency: "INR"}
+	arrived := booked.Add(10 * time.Minute)
+	accepted := Booking{Status: models.RideStatusAccepted, Charge: charge, CreatedAt: booked}
+	atPickup := accepted
+	atPickup.ArrivedAt = &arrived
+
+	cases := []struct {
+		name   string
+		b      Booking
+		at     time.Time
+		reason models.CancellationReason
+		fee    int64
+		err    error
+	}{
+		{"scheduled is free", Booking{Status: models.RideStatusScheduled, Charge: charge, CreatedAt: booked}, booked.Add(time.Hour), models.CancellationFree, 0, nil},
+		{"requested is free", Booking{Status: models.RideStatusRequested, Charge: charge, CreatedAt: booked}, booked.Add(time.Hour), models.CancellationFree, 0, nil},
+		{"accepted within window", accepted, booked.Add(2*time.Minute - time.Second), models.CancellationFree, 0, nil},
+		{"accepted at window end", accepted, booked.Add(2 * time.Minute), models.CancellationLate, 2001, nil},
+		{"driver waiting briefly", atPickup, arrived.Add(5*time.Minute - time.Second), models.CancellationLate, 2001, nil},
+		{"driver waited long enough", atPickup, arrived.Add(5 * time.Minute), models.CancellationNoShow, 5001, nil},
+		{"completed", Booking{Status: models.RideStatusCompleted, Charge: charge, CreatedAt: booked}, booked, "", 0, ErrNotCancellable},
+		{"cancelled", Booking{Status: models.RideStatusCancelled, Charge: charge, CreatedAt: booked}, booked, "", 0, ErrNotCancellable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := policy.Evaluate(c.b, c.at)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("want err %v, got %v", c.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.Reason != c.reason || got.Fee != (money.Money{Amount: c.fee, Currency: "INR"}) {
+				t.Fatalf("want %s %d, got %+v", c.reason, c.fee, got)
+			}
+		})
+	}
+}
+
+func TestEvaluate_NoShowWithinFreeWindow(t *testing.T) {
+	// A driver who arrives early and waits long enough charges a no-show
+	// even though the free window has not passed.
+	policy := Policy{FreeWindow: time.Hour, LateFeeBPS: 1000, NoShowWait: time.Minute, NoShowFeeBPS: 10000}
+	arrived := booked.Add(time.Minute)
+	b := Booking{Status: models.RideStatusAccepted, Charge: money.Money{Amount: 500, Currency: "INR"}, CreatedAt: booked, ArrivedAt: &arrived}
+	got, err := policy.Evaluate(b, arrived.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Reason != models.CancellationNoShow || got.Fee.Amount != 500 {
+		t.Fatalf("unexpected outcome: %+v", got)
+	}
+}
+
+func TestFromBooking(t *testing.T) {
+	discount := money.Money{Amount: 3000, Currency: "INR"}
+	b, err := FromBooking(models.Booking{
+		Price:      money.Money{Amount: 20000, Currency: "INR"},
+		Discount:   &discount,
+		RideStatus: models.RideStatusAccepted,
+		CreatedAt:  booked,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Charge.Amount != 17000 || b.Status != models.RideStatusAccepted || !b.CreatedAt.Equal(booked) {
+		t.Fatalf("unexpected view: %+v", b)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	for _, p := range []Policy{
+		{FreeWindow: -time.Second},
+		{NoShowWait: -time.Second},
+		{LateFeeBPS: 10001},
+		{NoShowFeeBPS: -1},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("%+v must be invalid", p)
+		}
+	}
+	if err := (Policy{FreeWindow: time.Minute, LateFeeBPS: 10000, NoShowWait: time.Minute, NoShowFeeBPS: 0}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
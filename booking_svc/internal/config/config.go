@@ -7,53 +7,244 @@ import (
 )
 
 type Config struct {
-	ServiceName     string
-	HTTPPort        string
+	ServiceName string
+	HTTPPort    string
+	// GRPCPort serves the gRPC API next to the HTTP port.
+	GRPCPort        string
 	GracefulTimeout time.Duration
-	LogLevel        string
+	// ShutdownDrainDelay is how long /readyz reports draining before the listener closes.
+	ShutdownDrainDelay time.Duration
+	LogLevel           string
+	// TracesExporter selects the span exporter: otlp, stdout or none.
+	TracesExporter string
 
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+	// MigrateOnStart applies pending schema migrations before serving.
+	MigrateOnStart bool
 
-	KafkaBrokers         string
-	TopicBookingCreated  string
-	TopicBookingAccepted string
-	ConsumerGroupAccepts string
+	JWTHS256Secret    string
+	JWTRS256PublicKey string
+	JWTJWKSFile       string
+	JWTIssuer         string
+	JWTAudience       string
+
+	// Per-client token buckets; a zero rate disables limiting for that class.
+	RateLimitReadRPS    float64
+	RateLimitReadBurst  int
+	RateLimitWriteRPS   float64
+	RateLimitWriteBurst int
+	// RateLimitUnauthRPS and RateLimitUnauthBurst budget, per client IP,
+	// requests that fail authentication.
+	RateLimitUnauthRPS   float64
+	RateLimitUnauthBurst int
+	// TrustedProxies lists the addresses and CIDR prefixes whose
+	// X-Forwarded-For is believed; empty trusts none.
+	TrustedProxies string
+	// Load shedding thresholds; zero disables the check.
+	ShedMaxInFlight int
+	ShedMaxPoolWait time.Duration
+	// OpenAPIResponseValidation is off, log or strict; requests are always validated.
+	OpenAPIResponseValidation string
+	// DefaultCurrency is the ISO 4217 code given to legacy integer prices,
+	// which counted whole units.
+	DefaultCurrency string
+
+	// BusDriver selects the message bus: kafka or memory (in-process only).
+	BusDriver             string
+	KafkaBrokers          string
+	TopicBookingCreated   string
+	TopicBookingAccepted  string
+	TopicBookingCancelled string
+	TopicBookingCompleted string
+	TopicRatingSubmitted  string
+	TopicPayoutPaid       string
+	ConsumerGroupAccepts  string
+	ConsumerGroupPayouts  string
+	TopicDLQSuffix        string
+
+	WebhookPollInterval time.Duration
+	WebhookBatchSize    int
+	WebhookMaxAttempts  int
+	WebhookBaseBackoff  time.Duration
+	WebhookMaxBackoff   time.Duration
+	WebhookTimeout      time.Duration
+
+	// PaymentGateway selects the payment provider; only fake exists so far.
+	PaymentGateway string
+	// PaymentFakeDeclineAbove makes the fake gateway decline holds above this
+	// many minor units; zero never declines.
+	PaymentFakeDeclineAbove int64
+	// PaymentHoldTTL is how long an authorization hold lives before the
+	// expirer cancels the booking, if still Requested, and releases it.
+	PaymentHoldTTL        time.Duration
+	PaymentExpiryInterval time.Duration
+
+	// CommissionBPS is the platform's share of each completed fare, in basis
+	// points (2000 = 20%); the driver earns the rest.
+	CommissionBPS int64
+
+	// CancellationFreeWindow is how long after booking a rider may cancel
+	// for free even with a driver assigned; after it CancellationFeeBPS of
+	// the charge is kept. Once the driver has waited at the pickup for
+	// NoShowWait the booking may be cancelled as a no-show for NoShowFeeBPS.
+	CancellationFreeWindow time.Duration
+	CancellationFeeBPS     int64
+	NoShowWait             time.Duration
+	NoShowFeeBPS           int64
+
+	// ScheduleLead is how long before pickup the scheduler releases a
+	// reserved ride to drivers, and the soonest a ride can be reserved for;
+	// ScheduleMaxAhead is the furthest. The scheduler polls every
+	// ScheduleInterval.
+	ScheduleLead     time.Duration
+	ScheduleMaxAhead time.Duration
+	ScheduleInterval time.Duration
+
+	// FareBase, FarePerKm and FarePerStop price fare estimates, in minor
+	// units of DefaultCurrency.
+	FareBase    int64
+	FarePerKm   int64
+	FarePerStop int64
 }
 
 func LoadFromEnv(serviceName, defaultPort string) Config {
 	logLevel := getEnv("LOG_LEVEL", "info")
 	port := getEnv("HTTP_PORT", defaultPort)
+	grpcPort := getEnv("GRPC_PORT", defByService(serviceName, "9090", "9091"))
 	gt := getEnvInt("GRACEFUL_TIMEOUT_SECONDS", 10)
+	drain := getEnvInt("SHUTDOWN_DRAIN_SECONDS", 0)
+	tracesExporter := getEnv("OTEL_TRACES_EXPORTER", "none")
 
 	dbHost := getEnv("DB_HOST", defByService(serviceName, "booking_db", "driver_db"))
 	dbPort := getEnv("DB_PORT", "5432")
 	dbUser := getEnv("DB_USER", defByService(serviceName, "booking", "driver"))
 	dbPass := getEnv("DB_PASSWORD", dbUser)
 	dbName := getEnv("DB_NAME", dbUser)
+	migrateOnStart := getEnvBool("DB_MIGRATE_ON_START", true)
 
+	jwtSecret := getEnv("JWT_HS256_SECRET", "")
+	jwtPubKey := getEnv("JWT_RS256_PUBLIC_KEY_FILE", "")
+	jwtJWKS := getEnv("JWT_JWKS_FILE", "")
+	jwtIssuer := getEnv("JWT_ISSUER", "")
+	jwtAudience := getEnv("JWT_AUDIENCE", "")
+
+	rlReadRPS := getEnvFloat("RATE_LIMIT_READ_RPS", 20)
+	rlReadBurst := getEnvInt("RATE_LIMIT_READ_BURST", 40)
+	rlWriteRPS := getEnvFloat("RATE_LIMIT_WRITE_RPS", 5)
+	rlWriteBurst := getEnvInt("RATE_LIMIT_WRITE_BURST", 10)
+	rlUnauthRPS := getEnvFloat("RATE_LIMIT_UNAUTH_RPS", 1)
+	rlUnauthBurst := getEnvInt("RATE_LIMIT_UNAUTH_BURST", 10)
+	trustedProxies := getEnv("TRUSTED_PROXIES", "")
+	shedInFlight := getEnvInt("SHED_MAX_INFLIGHT", 256)
+	shedPoolWait := getEnvInt("SHED_MAX_POOL_WAIT_MS", 250)
+	openapiResponses := getEnv("OPENAPI_RESPONSE_VALIDATION", "log")
+	defaultCurrency := getEnv("DEFAULT_CURRENCY", "INR")
+
+	busDriver := getEnv("BUS_DRIVER", "kafka")
 	kBrokers := getEnv("KAFKA_BROKERS", "redpanda:9092")
 	tCreated := getEnv("TOPIC_BOOKING_CREATED", "booking.created")
 	tAccepted := getEnv("TOPIC_BOOKING_ACCEPTED", "booking.accepted")
+	tCancelled := getEnv("TOPIC_BOOKING_CANCELLED", "booking.cancelled")
+	tCompleted := getEnv("TOPIC_BOOKING_COMPLETED", "booking.completed")
+	tRated := getEnv("TOPIC_RATING_SUBMITTED", "rating.submitted")
+	tPaid := getEnv("TOPIC_PAYOUT_PAID", "payout.paid")
 	cgAccepts := getEnv("CONSUMER_GROUP_ACCEPTS", "booking_svc.accepts")
+	cgPayouts := getEnv("CONSUMER_GROUP_PAYOUTS", "booking_svc.payouts")
+	dlqSuffix := getEnv("TOPIC_DLQ_SUFFIX", ".dlq")
+
+	whPoll := getEnvInt("WEBHOOK_POLL_INTERVAL_SECONDS", 1)
+	whBatch := getEnvInt("WEBHOOK_BATCH_SIZE", 20)
+	whAttempts := getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	whBase := getEnvInt("WEBHOOK_BACKOFF_BASE_SECONDS", 2)
+	whMax := getEnvInt("WEBHOOK_BACKOFF_MAX_SECONDS", 600)
+	whTimeout := getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 5)
+
+	payGateway := getEnv("PAYMENT_GATEWAY", "fake")
+	payDeclineAbove := getEnvInt("PAYMENT_FAKE_DECLINE_ABOVE", 0)
+	payHoldTTL := getEnvInt("PAYMENT_HOLD_TTL_SECONDS", 86400)
+	payExpiry := getEnvInt("PAYMENT_EXPIRY_INTERVAL_SECONDS", 60)
+
+	commission := getEnvInt("LEDGER_COMMISSION_BPS", 2000)
+
+	cancelFree := getEnvInt("CANCELLATION_FREE_SECONDS", 120)
+	cancelFee := getEnvInt("CANCELLATION_FEE_BPS", 1000)
+	noShowWait := getEnvInt("NO_SHOW_WAIT_SECONDS", 300)
+	noShowFee := getEnvInt("NO_SHOW_FEE_BPS", 2000)
+
+	scheduleLead := getEnvInt("SCHEDULE_LEAD_SECONDS", 900)
+	scheduleAhead := getEnvInt("SCHEDULE_MAX_AHEAD_DAYS", 30)
+	scheduleInterval := getEnvInt("SCHEDULE_INTERVAL_SECONDS", 15)
+
+	fareBase := getEnvInt("FARE_BASE", 5000)
+	farePerKm := getEnvInt("FARE_PER_KM", 1200)
+	farePerStop := getEnvInt("FARE_PER_STOP", 2500)
 
 	return Config{
-		ServiceName:          serviceName,
-		HTTPPort:             port,
-		GracefulTimeout:      time.Duration(gt) * time.Second,
-		LogLevel:             logLevel,
-		DBHost:               dbHost,
-		DBPort:               dbPort,
-		DBUser:               dbUser,
-		DBPassword:           dbPass,
-		DBName:               dbName,
-		KafkaBrokers:         kBrokers,
-		TopicBookingCreated:  tCreated,
-		TopicBookingAccepted: tAccepted,
-		ConsumerGroupAccepts: cgAccepts,
+		ServiceName:               serviceName,
+		HTTPPort:                  port,
+		GRPCPort:                  grpcPort,
+		GracefulTimeout:           time.Duration(gt) * time.Second,
+		ShutdownDrainDelay:        time.Duration(drain) * time.Second,
+		LogLevel:                  logLevel,
+		TracesExporter:            tracesExporter,
+		DBHost:                    dbHost,
+		DBPort:                    dbPort,
+		DBUser:                    dbUser,
+		DBPassword:                dbPass,
+		DBName:                    dbName,
+		MigrateOnStart:            migrateOnStart,
+		JWTHS256Secret:            jwtSecret,
+		JWTRS256PublicKey:         jwtPubKey,
+		JWTJWKSFile:               jwtJWKS,
+		JWTIssuer:                 jwtIssuer,
+		JWTAudience:               jwtAudience,
+		RateLimitReadRPS:          rlReadRPS,
+		RateLimitReadBurst:        rlReadBurst,
+		RateLimitWriteRPS:         rlWriteRPS,
+		RateLimitWriteBurst:       rlWriteBurst,
+		RateLimitUnauthRPS:        rlUnauthRPS,
+		RateLimitUnauthBurst:      rlUnauthBurst,
+		TrustedProxies:            trustedProxies,
+		ShedMaxInFlight:           shedInFlight,
+		ShedMaxPoolWait:           time.Duration(shedPoolWait) * time.Millisecond,
+		OpenAPIResponseValidation: openapiResponses,
+		DefaultCurrency:           defaultCurrency,
+		BusDriver:                 busDriver,
+		KafkaBrokers:              kBrokers,
+		TopicBookingCreated:       tCreated,
+		TopicBookingAccepted:      tAccepted,
+		TopicBookingCancelled:     tCancelled,
+		TopicBookingCompleted:     tCompleted,
+		TopicRatingSubmitted:      tRated,
+		TopicPayoutPaid:           tPaid,
+		ConsumerGroupAccepts:      cgAccepts,
+		ConsumerGroupPayouts:      cgPayouts,
+		TopicDLQSuffix:            dlqSuffix,
+		WebhookPollInterval:       time.Duration(whPoll) * time.Second,
+		WebhookBatchSize:          whBatch,
+		WebhookMaxAttempts:        whAttempts,
+		WebhookBaseBackoff:        time.Duration(whBase) * time.Second,
+		WebhookMaxBackoff:         time.Duration(whMax) * time.Second,
+		WebhookTimeout:            time.Duration(whTimeout) * time.Second,
+		PaymentGateway:            payGateway,
+		PaymentFakeDeclineAbove:   int64(payDeclineAbove),
+		PaymentHoldTTL:            time.Duration(payHoldTTL) * time.Second,
+		PaymentExpiryInterval:     time.Duration(payExpiry) * time.Second,
+		CommissionBPS:             int64(commission),
+		CancellationFreeWindow:    time.Duration(cancelFree) * time.Second,
+		CancellationFeeBPS:        int64(cancelFee),
+		NoShowWait:                time.Duration(noShowWait) * time.Second,
+		NoShowFeeBPS:              int64(noShowFee),
+		ScheduleLead:              time.Duration(scheduleLead) * time.Second,
+		ScheduleMaxAhead:          time.Duration(scheduleAhead) * 24 * time.Hour,
+		ScheduleInterval:          time.Duration(scheduleInterval) * time.Second,
+		FareBase:                  int64(fareBase),
+		FarePerKm:                 int64(farePerKm),
+		FarePerStop:               int64(farePerStop),
 	}
 }
 
@@ -61,6 +252,10 @@ func (c Config) Addr() string {
 	return ":" + c.HTTPPort
 }
 
+func (c Config) GRPCAddr() string {
+	return ":" + c.GRPCPort
+}
+
 func defByService(name, bookingDefault, driverDefault string) string {
 	switch name {
 	case "booking_svc":
@@ -87,3 +282,21 @@ func getEnvInt(key string, def int) int {
 	}
 	return def
 }
+
+func getEnvFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func getEnvBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"booking_svc/internal/tracing"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -25,6 +27,7 @@ func Connect(ctx context.Context, p Params) (*pgxpool.Pool, error) {
 		return nil, err
 	}
 	cfg.MaxConns = 5
+	cfg.ConnConfig.Tracer = tracing.QueryTracer{}
 
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
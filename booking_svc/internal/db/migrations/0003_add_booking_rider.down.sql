@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bookings_rider_created_at;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS rider_id;
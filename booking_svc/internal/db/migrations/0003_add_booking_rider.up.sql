@@ -0,0 +1,3 @@
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS rider_id TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_rider_created_at ON bookings (rider_id, created_at DESC);
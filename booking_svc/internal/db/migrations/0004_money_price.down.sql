@@ -0,0 +1,4 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS price_currency;
+UPDATE bookings SET price_amount = price_amount / 100;
+ALTER TABLE bookings ALTER COLUMN price_amount TYPE INTEGER;
+ALTER TABLE bookings RENAME COLUMN price_amount TO price;
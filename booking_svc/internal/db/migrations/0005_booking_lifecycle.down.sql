@@ -0,0 +1,6 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS fare_currency;
+ALTER TABLE bookings DROP COLUMN IF EXISTS fare_amount;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_ride_status_check;
+-- NOT VALID keeps rows that finished or were cancelled in the meantime.
+ALTER TABLE bookings ADD CONSTRAINT bookings_ride_status_check
+  CHECK (ride_status IN ('Requested','Accepted')) NOT VALID;
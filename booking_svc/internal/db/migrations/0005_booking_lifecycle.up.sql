@@ -0,0 +1,6 @@
+-- Trips can now finish or be called off; completed trips record the fare charged.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_ride_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_ride_status_check
+  CHECK (ride_status IN ('Requested','Accepted','Completed','Cancelled'));
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS fare_amount BIGINT NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS fare_currency CHAR(3) NULL;
@@ -0,0 +1,31 @@
+-- One payment per booking. The row is written before the booking so that a
+-- hold placed for a booking that was never stored can still be found and
+-- released; hence no foreign key to bookings.
+CREATE TABLE IF NOT EXISTS payments (
+  booking_id TEXT PRIMARY KEY,
+  rider_id TEXT NULL,
+  status TEXT NOT NULL CHECK (status IN ('pending','authorized','captured','released','failed')),
+  amount BIGINT NOT NULL,
+  currency CHAR(3) NOT NULL,
+  captured_amount BIGINT NULL,
+  authorization_id TEXT NULL,
+  failure_reason TEXT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_open_expires_at
+  ON payments (expires_at) WHERE status IN ('pending','authorized');
+
+CREATE TABLE IF NOT EXISTS payment_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  booking_id TEXT NOT NULL REFERENCES payments(booking_id) ON DELETE CASCADE,
+  operation TEXT NOT NULL CHECK (operation IN ('authorize','capture','void')),
+  idempotency_key TEXT NOT NULL,
+  succeeded BOOLEAN NOT NULL,
+  error TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_attempts_booking ON payment_attempts (booking_id, id);
@@ -0,0 +1,29 @@
+-- Double-entry ledger. Entries are append-only; balances are summed from
+-- postings. Balancing is checked by the application before insert.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+  code TEXT PRIMARY KEY,
+  type TEXT NOT NULL CHECK (type IN ('asset','liability','revenue')),
+  currency CHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id TEXT PRIMARY KEY,
+  reference TEXT NOT NULL UNIQUE,
+  description TEXT NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_occurred_at ON ledger_entries (occurred_at);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+  id BIGSERIAL PRIMARY KEY,
+  entry_id TEXT NOT NULL REFERENCES ledger_entries(id),
+  account TEXT NOT NULL REFERENCES ledger_accounts(code),
+  side TEXT NOT NULL CHECK (side IN ('debit','credit')),
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account ON ledger_postings (account, id);
@@ -0,0 +1,8 @@
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_type_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_type_check
+  CHECK (type IN ('asset','liability','revenue'));
+
+DROP TABLE IF EXISTS promo_redemptions;
+ALTER TABLE bookings DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE bookings DROP COLUMN IF EXISTS promo_code;
+DROP TABLE IF EXISTS promotions;
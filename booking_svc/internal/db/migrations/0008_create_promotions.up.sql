@@ -0,0 +1,41 @@
+-- Promotions and their redemptions. redemptions is a counter kept alongside
+-- promo_redemptions so the global limit can be enforced with one conditional
+-- UPDATE; the per-rider limit is counted from promo_redemptions under that
+-- row's lock.
+CREATE TABLE IF NOT EXISTS promotions (
+  code TEXT PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT '',
+  kind TEXT NOT NULL CHECK (kind IN ('percent','flat')),
+  percent_bps BIGINT NOT NULL DEFAULT 0,
+  amount_off BIGINT NULL,
+  max_discount BIGINT NULL,
+  currency CHAR(3) NULL,
+  max_redemptions INTEGER NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+  max_per_rider INTEGER NOT NULL DEFAULT 0 CHECK (max_per_rider >= 0),
+  redemptions INTEGER NOT NULL DEFAULT 0,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NULL,
+  area_lat DOUBLE PRECISION NULL,
+  area_lng DOUBLE PRECISION NULL,
+  area_radius_km DOUBLE PRECISION NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS promo_code TEXT NULL REFERENCES promotions(code);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS discount_amount BIGINT NULL;
+
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+  booking_id TEXT PRIMARY KEY REFERENCES bookings(booking_id),
+  code TEXT NOT NULL REFERENCES promotions(code),
+  rider_id TEXT NULL,
+  discount_amount BIGINT NOT NULL CHECK (discount_amount > 0),
+  currency CHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_code_rider ON promo_redemptions (code, rider_id);
+
+-- Discounts are posted to the ledger as a platform expense.
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_type_check;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_type_check
+  CHECK (type IN ('asset','liability','revenue','expense'));
@@ -0,0 +1,12 @@
+-- One rating per side of a completed trip: the rider rates the driver and the
+-- driver rates the rider. driver_svc keeps the drivers' averages.
+CREATE TABLE IF NOT EXISTS ratings (
+  booking_id TEXT NOT NULL,
+  rater TEXT NOT NULL CHECK (rater IN ('rider','driver')),
+  rater_id TEXT NOT NULL,
+  ratee_id TEXT NOT NULL,
+  stars SMALLINT NOT NULL CHECK (stars BETWEEN 1 AND 5),
+  comment TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (booking_id, rater)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_fee;
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_reason;
+ALTER TABLE bookings DROP COLUMN IF EXISTS arrived_at;
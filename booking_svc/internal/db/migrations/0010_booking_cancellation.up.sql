@@ -0,0 +1,8 @@
+-- When the driver reached the pickup, and why a cancelled booking was called
+-- off and what the rider was charged for it. cancellation_fee is in the
+-- price's currency. Bookings cancelled before this migration keep NULLs.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS arrived_at TIMESTAMPTZ NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancellation_reason TEXT NULL
+  CHECK (cancellation_reason IN ('free','late_cancellation','no_show'));
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancellation_fee BIGINT NULL CHECK (cancellation_fee >= 0);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ NULL;
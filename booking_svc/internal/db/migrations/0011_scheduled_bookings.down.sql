@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS bookings_scheduled_idx;
+ALTER TABLE bookings DROP COLUMN IF EXISTS release_claimed_until;
+ALTER TABLE bookings DROP COLUMN IF EXISTS scheduled_for;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_ride_status_check;
+-- NOT VALID keeps reservations made in the meantime.
+ALTER TABLE bookings ADD CONSTRAINT bookings_ride_status_check
+  CHECK (ride_status IN ('Requested','Accepted','Completed','Cancelled')) NOT VALID;
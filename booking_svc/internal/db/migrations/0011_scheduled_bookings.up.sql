@@ -0,0 +1,9 @@
+-- Reservations: a Scheduled booking waits until shortly before scheduled_for,
+-- when a scheduler releases it as Requested. release_claimed_until is the
+-- lease of the scheduler replica releasing it.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_ride_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_ride_status_check
+  CHECK (ride_status IN ('Scheduled','Requested','Accepted','Completed','Cancelled'));
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS release_claimed_until TIMESTAMPTZ NULL;
+CREATE INDEX IF NOT EXISTS bookings_scheduled_idx ON bookings (scheduled_for) WHERE ride_status = 'Scheduled';
@@ -0,0 +1,10 @@
+-- Intermediate stops of a multi-stop ride, visited in seq order between the
+-- pickup and the dropoff.
+CREATE TABLE IF NOT EXISTS booking_stops (
+  booking_id TEXT NOT NULL REFERENCES bookings (booking_id) ON DELETE CASCADE,
+  seq INTEGER NOT NULL CHECK (seq >= 1),
+  lat DOUBLE PRECISION NOT NULL,
+  lng DOUBLE PRECISION NOT NULL,
+  arrived_at TIMESTAMPTZ,
+  PRIMARY KEY (booking_id, seq)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS passengers;
+ALTER TABLE bookings DROP COLUMN IF EXISTS vehicle_type;
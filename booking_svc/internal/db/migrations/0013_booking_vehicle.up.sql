@@ -0,0 +1,4 @@
+-- The vehicle the rider asked for, if any, and how many are riding.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS vehicle_type TEXT NULL
+  CHECK (vehicle_type IN ('bike','auto','sedan','suv'));
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS passengers INTEGER NOT NULL DEFAULT 1 CHECK (passengers >= 1);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payments_open_expiry_due;
+CREATE INDEX IF NOT EXISTS idx_payments_open_expires_at
+  ON payments (expires_at) WHERE status IN ('pending','authorized');
+ALTER TABLE payments DROP COLUMN IF EXISTS expiry_retry_at;
@@ -0,0 +1,7 @@
+-- expiry_retry_at postpones the expirer's next look at a hold: while its trip
+-- is under way, or after releasing it failed. A hold is due at the later of
+-- expires_at and expiry_retry_at.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS expiry_retry_at TIMESTAMPTZ NULL;
+DROP INDEX IF EXISTS idx_payments_open_expires_at;
+CREATE INDEX IF NOT EXISTS idx_payments_open_expiry_due
+  ON payments ((GREATEST(expires_at, expiry_retry_at))) WHERE status IN ('pending','authorized');
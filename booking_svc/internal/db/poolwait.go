@@ -0,0 +1,71 @@
+package db
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolSample is the subset of pgxpool.Stat the wait estimate needs.
+type poolSample struct {
+	acquires  int64
+	waited    time.Duration
+	saturated bool
+}
+
+// AcquireWait estimates how long requests currently wait for a pooled
+// connection: the mean acquire duration over the most recent window. It is
+// the load shedder's early signal that the DB is the bottleneck.
+type AcquireWait struct {
+	sample func() poolSample
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	last    poolSample
+	lastAt  time.Time
+	current time.Duration
+}
+
+func NewAcquireWait(pool *pgxpool.Pool, window time.Duration) *AcquireWait {
+	return newAcquireWait(func() poolSample {
+		st := pool.Stat()
+		return poolSample{
+			acquires:  st.AcquireCount(),
+			waited:    st.AcquireDuration(),
+			saturated: st.AcquiredConns() >= st.MaxConns(),
+		}
+	}, window)
+}
+
+func newAcquireWait(sample func() poolSample, window time.Duration) *AcquireWait {
+	return &AcquireWait{sample: sample, window: window, now: time.Now}
+}
+
+// Current returns the latest estimate, re-sampling the pool at most once per window.
+func (a *AcquireWait) Current() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if !a.lastAt.IsZero() && now.Sub(a.lastAt) < a.window {
+		return a.current
+	}
+	s := a.sample()
+	if a.lastAt.IsZero() {
+		a.last, a.lastAt = s, now
+		return 0
+	}
+	switch n := s.acquires - a.last.acquires; {
+	case n > 0:
+		a.current = (s.waited - a.last.waited) / time.Duration(n)
+	case !s.saturated:
+		// Idle pool: nobody is waiting.
+		a.current = 0
+	}
+	// With no completed acquires and every connection checked out, callers
+	// are still stuck waiting, so the previous estimate stands.
+	a.last, a.lastAt = s, now
+	return a.current
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquireWait_MeanOverWindow(t *testing.T) {
+	var s poolSample
+	clock := time.Unix(0, 0)
+	a := newAcquireWait(func() poolSample { return s }, time.Second)
+	a.now = func() time.Time { return clock }
+
+	if got := a.Current(); got != 0 {
+		t.Fatalf("first sample: want 0, got %v", got)
+	}
+
+	s = poolSample{acquires: 4, waited: 400 * time.Millisecond}
+	if got := a.Current(); got != 0 {
+		t.Fatalf("within window: want cached 0, got %v", got)
+	}
+	clock = clock.Add(time.Second)
+	if got := a.Current(); got != 100*time.Millisecond {
+		t.Fatalf("want 100ms mean, got %v", got)
+	}
+
+	// saturated with no completed acquires: keep the last estimate
+	s.saturated = true
+	clock = clock.Add(time.Second)
+	if got := a.Current(); got != 100*time.Millisecond {
+		t.Fatalf("saturated: want 100ms kept, got %v", got)
+	}
+
+	// idle: drops back to zero
+	s.saturated = false
+	clock = clock.Add(time.Second)
+	if got := a.Current(); got != 0 {
+		t.Fatalf("idle: want 0, got %v", got)
+	}
+}
@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"booking_svc/internal/money"
+)
+
+// BookingCompleted is published once the fare of a trip is captured and
+// posted to the ledger.
+type BookingCompleted struct {
+	BookingID string `json:"booking_id"`
+	DriverID  string `json:"driver_id"`
+	// Fare is the final fare before any discount. DriverEarnings is what the
+	// ledger credited the driver for it: the fare less the commission.
+	Fare           money.Money `json:"fare"`
+	DriverEarnings money.Money `json:"driver_earnings"`
+	CompletedAt    time.Time   `json:"completed_at"`
+}
@@ -0,0 +1,17 @@
+package events
+
+import (
+	"time"
+
+	"booking_svc/internal/money"
+)
+
+// PayoutPaid is published by driver_svc once its payout provider has paid a
+// driver. Amount is the sum of the earnings the payout covers.
+type PayoutPaid struct {
+	PayoutID    string      `json:"payout_id"`
+	DriverID    string      `json:"driver_id"`
+	Amount      money.Money `json:"amount"`
+	ProviderRef string      `json:"provider_ref"`
+	PaidAt      time.Time   `json:"paid_at"`
+}
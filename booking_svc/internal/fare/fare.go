@@ -0,0 +1,72 @@
+// Package fare estimates what a ride costs from its route: a base fare, a
+// rate per kilometre along the straight legs from the pickup through every
+// stop to the dropoff, and a charge per intermediate stop.
+package fare
+
+import (
+	"fmt"
+	"math"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+// Tariff prices routes. Amounts are minor units of Currency.
+type Tariff struct {
+	Currency string
+	Base     int64
+	PerKm    int64
+	PerStop  int64
+}
+
+func (t Tariff) Validate() error {
+	if !money.ValidCurrency(t.Currency) {
+		return fmt.Errorf("currency %q is not a supported ISO 4217 code", t.Currency)
+	}
+	if t.Base < 0 || t.PerKm < 0 || t.PerStop < 0 {
+		return fmt.Errorf("base %d, per km %d and per stop %d must not be negative", t.Base, t.PerKm, t.PerStop)
+	}
+	if t.Base == 0 && t.PerKm == 0 {
+		return fmt.Errorf("base and per km cannot both be zero")
+	}
+	return nil
+}
+
+// Estimate is the priced route.
+type Estimate struct {
+	// DistanceKm is the length of the route, rounded to metres.
+	DistanceKm float64     `json:"distance_km"`
+	Stops      int         `json:"stops"`
+	Fare       money.Money `json:"fare"`
+}
+
+// DistanceKm is the length of the route from pickup through stops to dropoff.
+func DistanceKm(pickup models.Location, stops []models.Location, dropoff models.Location) float64 {
+	total, from := 0.0, pickup
+	for _, s := range stops {
+		total += from.DistanceKm(s)
+		from = s
+	}
+	return total + from.DistanceKm(dropoff)
+}
+
+// Estimate prices the route from pickup through stops to dropoff.
+func (t Tariff) Estimate(pickup models.Location, stops []models.Location, dropoff models.Location) (Estimate, error) {
+	metres := int64(math.Round(DistanceKm(pickup, stops, dropoff) * 1000))
+	distance, err := money.Money{Amount: t.PerKm, Currency: t.Currency}.Mul(metres, 1000)
+	if err != nil {
+		return Estimate{}, err
+	}
+	perStop, err := money.Money{Amount: t.PerStop, Currency: t.Currency}.Mul(int64(len(stops)), 1)
+	if err != nil {
+		return Estimate{}, err
+	}
+	total, err := money.Money{Amount: t.Base, Currency: t.Currency}.Add(distance)
+	if err == nil {
+		total, err = total.Add(perStop)
+	}
+	if err != nil {
+		return Estimate{}, err
+	}
+	return Estimate{DistanceKm: float64(metres) / 1000, Stops: len(stops), Fare: total}, nil
+}
@@ -0,0 +1,51 @@
+package fare
+
+import (
+	"testing"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+func TestEstimate(t *testing.T) {
+	tariff := Tariff{Currency: "INR", Base: 5000, PerKm: 1200, PerStop: 2500}
+	origin, east := models.Location{}, models.Location{Lng: 1}
+	cases := []struct {
+		name     string
+		stops    []models.Location
+		wantKm   float64
+		wantFare int64
+	}{
+		// One degree of longitude on the equator is 111.195 km.
+		{"direct", nil, 111.195, 5000 + 133434},
+		{"a stop on the way adds only its charge", []models.Location{{Lng: 0.5}}, 111.195, 5000 + 133434 + 2500},
+		{"a detour adds its distance", []models.Location{{Lng: 2}}, 333.585, 5000 + 400302 + 2500},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := tariff.Estimate(origin, c.stops, east)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Estimate{DistanceKm: c.wantKm, Stops: len(c.stops), Fare: money.Money{Amount: c.wantFare, Currency: "INR"}}
+			if got != want {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestTariffValidate(t *testing.T) {
+	for name, tariff := range map[string]Tariff{
+		"unknown currency": {Currency: "XXX", Base: 5000},
+		"negative rate":    {Currency: "INR", Base: 5000, PerKm: -1},
+		"free rides":       {Currency: "INR", PerStop: 2500},
+	} {
+		if tariff.Validate() == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+	if err := (Tariff{Currency: "INR", Base: 5000, PerKm: 1200}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,1053 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: booking/v1/booking.proto
+
+package bookingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RideStatus int32
+
+const (
+	RideStatus_RIDE_STATUS_UNSPECIFIED RideStatus = 0
+	RideStatus_RIDE_STATUS_REQUESTED   RideStatus = 1
+	RideStatus_RIDE_STATUS_ACCEPTED    RideStatus = 2
+	RideStatus_RIDE_STATUS_COMPLETED   RideStatus = 3
+	RideStatus_RIDE_STATUS_CANCELLED   RideStatus = 4
+	// Reserved for a later pickup; becomes REQUESTED when released to drivers.
+	RideStatus_RIDE_STATUS_SCHEDULED RideStatus = 5
+)
+
+// Enum value maps for RideStatus.
+var (
+	RideStatus_name = map[int32]string{
+		0: "RIDE_STATUS_UNSPECIFIED",
+		1: "RIDE_STATUS_REQUESTED",
+		2: "RIDE_STATUS_ACCEPTED",
+		3: "RIDE_STATUS_COMPLETED",
+		4: "RIDE_STATUS_CANCELLED",
+		5: "RIDE_STATUS_SCHEDULED",
+	}
+	RideStatus_value = map[string]int32{
+		"RIDE_STATUS_UNSPECIFIED": 0,
+		"RIDE_STATUS_REQUESTED":   1,
+		"RIDE_STATUS_ACCEPTED":    2,
+		"RIDE_STATUS_COMPLETED":   3,
+		"RIDE_STATUS_CANCELLED":   4,
+		"RIDE_STATUS_SCHEDULED":   5,
+	}
+)
+
+func (x RideStatus) Enum() *RideStatus {
+	p := new(RideStatus)
+	*p = x
+	return p
+}
+
+func (x RideStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RideStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_booking_v1_booking_proto_enumTypes[0].Descriptor()
+}
+
+func (RideStatus) Type() protoreflect.EnumType {
+	return &file_booking_v1_booking_proto_enumTypes[0]
+}
+
+func (x RideStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RideStatus.Descriptor instead.
+func (RideStatus) EnumDescriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{0}
+}
+
+// Money is an amount in minor units (paise, cents) of an ISO 4217 currency.
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_booking_v1_booking_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng           float64                `protobuf:"fixed64,2,opt,name=lng,proto3" json:"lng,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_booking_v1_booking_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Location) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Location) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+// Stop is an intermediate stop of a booking, visited in order.
+type Stop struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Location *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// Set once the driver reports reaching the stop.
+	ArrivedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=arrived_at,json=arrivedAt,proto3" json:"arrived_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stop) Reset() {
+	*x = Stop{}
+	mi := &file_booking_v1_booking_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stop) ProtoMessage() {}
+
+func (x *Stop) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stop.ProtoReflect.Descriptor instead.
+func (*Stop) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Stop) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Stop) GetArrivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArrivedAt
+	}
+	return nil
+}
+
+type Booking struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BookingId string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	RiderId   string                 `protobuf:"bytes,2,opt,name=rider_id,json=riderId,proto3" json:"rider_id,omitempty"`
+	Pickuploc *Location              `protobuf:"bytes,3,opt,name=pickuploc,proto3" json:"pickuploc,omitempty"`
+	Dropoff   *Location              `protobuf:"bytes,4,opt,name=dropoff,proto3" json:"dropoff,omitempty"`
+	// Deprecated: whole units with no currency, rounded down; use price_money.
+	//
+	// Deprecated: Marked as deprecated in booking/v1/booking.proto.
+	Price      int64      `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`
+	RideStatus RideStatus `protobuf:"varint,6,opt,name=ride_status,json=rideStatus,proto3,enum=booking.v1.RideStatus" json:"ride_status,omitempty"`
+	// Empty until a driver accepts.
+	DriverId   string                 `protobuf:"bytes,7,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	PriceMoney *Money                 `protobuf:"bytes,9,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
+	// Set once the trip is completed: what the rider was charged.
+	Fare *Money `protobuf:"bytes,10,opt,name=fare,proto3" json:"fare,omitempty"`
+	// Set when the booking redeemed a promotion; the rider pays price_money
+	// less discount.
+	PromoCode string `protobuf:"bytes,11,opt,name=promo_code,json=promoCode,proto3" json:"promo_code,omitempty"`
+	Discount  *Money `protobuf:"bytes,12,opt,name=discount,proto3" json:"discount,omitempty"`
+	// Set once the driver reports reaching the pickup.
+	ArrivedAt *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=arrived_at,json=arrivedAt,proto3" json:"arrived_at,omitempty"`
+	// Set once the booking is cancelled: "free", "late_cancellation" or
+	// "no_show", and the fee the rider was charged, zero when free.
+	CancellationReason string                 `protobuf:"bytes,14,opt,name=cancellation_reason,json=cancellationReason,proto3" json:"cancellation_reason,omitempty"`
+	CancellationFee    *Money                 `protobuf:"bytes,15,opt,name=cancellation_fee,json=cancellationFee,proto3" json:"cancellation_fee,omitempty"`
+	CancelledAt        *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	// Set when the ride was reserved for a later pickup.
+	ScheduledFor *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=scheduled_for,json=scheduledFor,proto3" json:"scheduled_for,omitempty"`
+	Stops        []*Stop                `protobuf:"bytes,18,rep,name=stops,proto3" json:"stops,omitempty"`
+	// "bike", "auto", "sedan" or "suv"; empty when any vehicle will do.
+	VehicleType   string `protobuf:"bytes,19,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	Passengers    int32  `protobuf:"varint,20,opt,name=passengers,proto3" json:"passengers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Booking) Reset() {
+	*x = Booking{}
+	mi := &file_booking_v1_booking_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Booking) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Booking) ProtoMessage() {}
+
+func (x *Booking) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Booking.ProtoReflect.Descriptor instead.
+func (*Booking) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Booking) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *Booking) GetRiderId() string {
+	if x != nil {
+		return x.RiderId
+	}
+	return ""
+}
+
+func (x *Booking) GetPickuploc() *Location {
+	if x != nil {
+		return x.Pickuploc
+	}
+	return nil
+}
+
+func (x *Booking) GetDropoff() *Location {
+	if x != nil {
+		return x.Dropoff
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in booking/v1/booking.proto.
+func (x *Booking) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Booking) GetRideStatus() RideStatus {
+	if x != nil {
+		return x.RideStatus
+	}
+	return RideStatus_RIDE_STATUS_UNSPECIFIED
+}
+
+func (x *Booking) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+func (x *Booking) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Booking) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+func (x *Booking) GetFare() *Money {
+	if x != nil {
+		return x.Fare
+	}
+	return nil
+}
+
+func (x *Booking) GetPromoCode() string {
+	if x != nil {
+		return x.PromoCode
+	}
+	return ""
+}
+
+func (x *Booking) GetDiscount() *Money {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *Booking) GetArrivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArrivedAt
+	}
+	return nil
+}
+
+func (x *Booking) GetCancellationReason() string {
+	if x != nil {
+		return x.CancellationReason
+	}
+	return ""
+}
+
+func (x *Booking) GetCancellationFee() *Money {
+	if x != nil {
+		return x.CancellationFee
+	}
+	return nil
+}
+
+func (x *Booking) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+func (x *Booking) GetScheduledFor() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledFor
+	}
+	return nil
+}
+
+func (x *Booking) GetStops() []*Stop {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+func (x *Booking) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *Booking) GetPassengers() int32 {
+	if x != nil {
+		return x.Passengers
+	}
+	return 0
+}
+
+type CreateBookingRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Pickuploc *Location              `protobuf:"bytes,1,opt,name=pickuploc,proto3" json:"pickuploc,omitempty"`
+	Dropoff   *Location              `protobuf:"bytes,2,opt,name=dropoff,proto3" json:"dropoff,omitempty"`
+	// Deprecated: whole units of the server's default currency; used only when
+	// price_money is unset.
+	//
+	// Deprecated: Marked as deprecated in booking/v1/booking.proto.
+	Price      int64  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
+	PriceMoney *Money `protobuf:"bytes,4,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
+	// Optional promo code to redeem against the booking.
+	PromoCode string `protobuf:"bytes,5,opt,name=promo_code,json=promoCode,proto3" json:"promo_code,omitempty"`
+	// Optional pickup time to reserve the ride for instead of dispatching it now.
+	ScheduledFor *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=scheduled_for,json=scheduledFor,proto3" json:"scheduled_for,omitempty"`
+	// Optional intermediate stops, visited in order between pickuploc and
+	// dropoff.
+	Stops []*Location `protobuf:"bytes,7,rep,name=stops,proto3" json:"stops,omitempty"`
+	// Optional vehicle type ("bike", "auto", "sedan" or "suv") and passenger
+	// count, which defaults to one.
+	VehicleType   string `protobuf:"bytes,8,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	Passengers    int32  `protobuf:"varint,9,opt,name=passengers,proto3" json:"passengers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBookingRequest) Reset() {
+	*x = CreateBookingRequest{}
+	mi := &file_booking_v1_booking_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBookingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBookingRequest) ProtoMessage() {}
+
+func (x *CreateBookingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBookingRequest.ProtoReflect.Descriptor instead.
+func (*CreateBookingRequest) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateBookingRequest) GetPickuploc() *Location {
+	if x != nil {
+		return x.Pickuploc
+	}
+	return nil
+}
+
+func (x *CreateBookingRequest) GetDropoff() *Location {
+	if x != nil {
+		return x.Dropoff
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in booking/v1/booking.proto.
+func (x *CreateBookingRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *CreateBookingRequest) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+func (x *CreateBookingRequest) GetPromoCode() string {
+	if x != nil {
+		return x.PromoCode
+	}
+	return ""
+}
+
+func (x *CreateBookingRequest) GetScheduledFor() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledFor
+	}
+	return nil
+}
+
+func (x *CreateBookingRequest) GetStops() []*Location {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+func (x *CreateBookingRequest) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *CreateBookingRequest) GetPassengers() int32 {
+	if x != nil {
+		return x.Passengers
+	}
+	return 0
+}
+
+type CreateBookingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Booking       *Booking               `protobuf:"bytes,1,opt,name=booking,proto3" json:"booking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBookingResponse) Reset() {
+	*x = CreateBookingResponse{}
+	mi := &file_booking_v1_booking_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBookingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBookingResponse) ProtoMessage() {}
+
+func (x *CreateBookingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBookingResponse.ProtoReflect.Descriptor instead.
+func (*CreateBookingResponse) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateBookingResponse) GetBooking() *Booking {
+	if x != nil {
+		return x.Booking
+	}
+	return nil
+}
+
+type GetBookingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookingRequest) Reset() {
+	*x = GetBookingRequest{}
+	mi := &file_booking_v1_booking_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookingRequest) ProtoMessage() {}
+
+func (x *GetBookingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookingRequest.ProtoReflect.Descriptor instead.
+func (*GetBookingRequest) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetBookingRequest) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+type GetBookingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Booking       *Booking               `protobuf:"bytes,1,opt,name=booking,proto3" json:"booking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookingResponse) Reset() {
+	*x = GetBookingResponse{}
+	mi := &file_booking_v1_booking_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookingResponse) ProtoMessage() {}
+
+func (x *GetBookingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookingResponse.ProtoReflect.Descriptor instead.
+func (*GetBookingResponse) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetBookingResponse) GetBooking() *Booking {
+	if x != nil {
+		return x.Booking
+	}
+	return nil
+}
+
+type ListBookingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBookingsRequest) Reset() {
+	*x = ListBookingsRequest{}
+	mi := &file_booking_v1_booking_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBookingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBookingsRequest) ProtoMessage() {}
+
+func (x *ListBookingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBookingsRequest.ProtoReflect.Descriptor instead.
+func (*ListBookingsRequest) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{8}
+}
+
+type ListBookingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bookings      []*Booking             `protobuf:"bytes,1,rep,name=bookings,proto3" json:"bookings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBookingsResponse) Reset() {
+	*x = ListBookingsResponse{}
+	mi := &file_booking_v1_booking_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBookingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBookingsResponse) ProtoMessage() {}
+
+func (x *ListBookingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBookingsResponse.ProtoReflect.Descriptor instead.
+func (*ListBookingsResponse) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListBookingsResponse) GetBookings() []*Booking {
+	if x != nil {
+		return x.Bookings
+	}
+	return nil
+}
+
+type WatchBookingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBookingRequest) Reset() {
+	*x = WatchBookingRequest{}
+	mi := &file_booking_v1_booking_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBookingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBookingRequest) ProtoMessage() {}
+
+func (x *WatchBookingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBookingRequest.ProtoReflect.Descriptor instead.
+func (*WatchBookingRequest) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchBookingRequest) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+type WatchBookingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Booking       *Booking               `protobuf:"bytes,1,opt,name=booking,proto3" json:"booking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchBookingResponse) Reset() {
+	*x = WatchBookingResponse{}
+	mi := &file_booking_v1_booking_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBookingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBookingResponse) ProtoMessage() {}
+
+func (x *WatchBookingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_booking_v1_booking_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBookingResponse.ProtoReflect.Descriptor instead.
+func (*WatchBookingResponse) Descriptor() ([]byte, []int) {
+	return file_booking_v1_booking_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchBookingResponse) GetBooking() *Booking {
+	if x != nil {
+		return x.Booking
+	}
+	return nil
+}
+
+var File_booking_v1_booking_proto protoreflect.FileDescriptor
+
+const file_booking_v1_booking_proto_rawDesc = "" +
+	"\n" +
+	"\x18booking/v1/booking.proto\x12\n" +
+	"booking.v1\x1a\x1fgoogle/protobuf/timestamp.proto\";\n" +
+	"\x05Money\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\".\n" +
+	"\bLocation\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\x02 \x01(\x01R\x03lng\"s\n" +
+	"\x04Stop\x120\n" +
+	"\blocation\x18\x01 \x01(\v2\x14.booking.v1.LocationR\blocation\x129\n" +
+	"\n" +
+	"arrived_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tarrivedAt\"\x90\a\n" +
+	"\aBooking\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\x12\x19\n" +
+	"\brider_id\x18\x02 \x01(\tR\ariderId\x122\n" +
+	"\tpickuploc\x18\x03 \x01(\v2\x14.booking.v1.LocationR\tpickuploc\x12.\n" +
+	"\adropoff\x18\x04 \x01(\v2\x14.booking.v1.LocationR\adropoff\x12\x18\n" +
+	"\x05price\x18\x05 \x01(\x03B\x02\x18\x01R\x05price\x127\n" +
+	"\vride_status\x18\x06 \x01(\x0e2\x16.booking.v1.RideStatusR\n" +
+	"rideStatus\x12\x1b\n" +
+	"\tdriver_id\x18\a \x01(\tR\bdriverId\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x122\n" +
+	"\vprice_money\x18\t \x01(\v2\x11.booking.v1.MoneyR\n" +
+	"priceMoney\x12%\n" +
+	"\x04fare\x18\n" +
+	" \x01(\v2\x11.booking.v1.MoneyR\x04fare\x12\x1d\n" +
+	"\n" +
+	"promo_code\x18\v \x01(\tR\tpromoCode\x12-\n" +
+	"\bdiscount\x18\f \x01(\v2\x11.booking.v1.MoneyR\bdiscount\x129\n" +
+	"\n" +
+	"arrived_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tarrivedAt\x12/\n" +
+	"\x13cancellation_reason\x18\x0e \x01(\tR\x12cancellationReason\x12<\n" +
+	"\x10cancellation_fee\x18\x0f \x01(\v2\x11.booking.v1.MoneyR\x0fcancellationFee\x12=\n" +
+	"\fcancelled_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\vcancelledAt\x12?\n" +
+	"\rscheduled_for\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\fscheduledFor\x12&\n" +
+	"\x05stops\x18\x12 \x03(\v2\x10.booking.v1.StopR\x05stops\x12!\n" +
+	"\fvehicle_type\x18\x13 \x01(\tR\vvehicleType\x12\x1e\n" +
+	"\n" +
+	"passengers\x18\x14 \x01(\x05R\n" +
+	"passengers\"\x97\x03\n" +
+	"\x14CreateBookingRequest\x122\n" +
+	"\tpickuploc\x18\x01 \x01(\v2\x14.booking.v1.LocationR\tpickuploc\x12.\n" +
+	"\adropoff\x18\x02 \x01(\v2\x14.booking.v1.LocationR\adropoff\x12\x18\n" +
+	"\x05price\x18\x03 \x01(\x03B\x02\x18\x01R\x05price\x122\n" +
+	"\vprice_money\x18\x04 \x01(\v2\x11.booking.v1.MoneyR\n" +
+	"priceMoney\x12\x1d\n" +
+	"\n" +
+	"promo_code\x18\x05 \x01(\tR\tpromoCode\x12?\n" +
+	"\rscheduled_for\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\fscheduledFor\x12*\n" +
+	"\x05stops\x18\a \x03(\v2\x14.booking.v1.LocationR\x05stops\x12!\n" +
+	"\fvehicle_type\x18\b \x01(\tR\vvehicleType\x12\x1e\n" +
+	"\n" +
+	"passengers\x18\t \x01(\x05R\n" +
+	"passengers\"F\n" +
+	"\x15CreateBookingResponse\x12-\n" +
+	"\abooking\x18\x01 \x01(\v2\x13.booking.v1.BookingR\abooking\"2\n" +
+	"\x11GetBookingRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\"C\n" +
+	"\x12GetBookingResponse\x12-\n" +
+	"\abooking\x18\x01 \x01(\v2\x13.booking.v1.BookingR\abooking\"\x15\n" +
+	"\x13ListBookingsRequest\"G\n" +
+	"\x14ListBookingsResponse\x12/\n" +
+	"\bbookings\x18\x01 \x03(\v2\x13.booking.v1.BookingR\bbookings\"4\n" +
+	"\x13WatchBookingRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\"E\n" +
+	"\x14WatchBookingResponse\x12-\n" +
+	"\abooking\x18\x01 \x01(\v2\x13.booking.v1.BookingR\abooking*\xaf\x01\n" +
+	"\n" +
+	"RideStatus\x12\x1b\n" +
+	"\x17RIDE_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15RIDE_STATUS_REQUESTED\x10\x01\x12\x18\n" +
+	"\x14RIDE_STATUS_ACCEPTED\x10\x02\x12\x19\n" +
+	"\x15RIDE_STATUS_COMPLETED\x10\x03\x12\x19\n" +
+	"\x15RIDE_STATUS_CANCELLED\x10\x04\x12\x19\n" +
+	"\x15RIDE_STATUS_SCHEDULED\x10\x052\xdb\x02\n" +
+	"\x0eBookingService\x12T\n" +
+	"\rCreateBooking\x12 .booking.v1.CreateBookingRequest\x1a!.booking.v1.CreateBookingResponse\x12K\n" +
+	"\n" +
+	"GetBooking\x12\x1d.booking.v1.GetBookingRequest\x1a\x1e.booking.v1.GetBookingResponse\x12Q\n" +
+	"\fListBookings\x12\x1f.booking.v1.ListBookingsRequest\x1a .booking.v1.ListBookingsResponse\x12S\n" +
+	"\fWatchBooking\x12\x1f.booking.v1.WatchBookingRequest\x1a .booking.v1.WatchBookingResponse0\x01B.Z,booking_svc/internal/gen/bookingv1;bookingv1b\x06proto3"
+
+var (
+	file_booking_v1_booking_proto_rawDescOnce sync.Once
+	file_booking_v1_booking_proto_rawDescData []byte
+)
+
+func file_booking_v1_booking_proto_rawDescGZIP() []byte {
+	file_booking_v1_booking_proto_rawDescOnce.Do(func() {
+		file_booking_v1_booking_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_booking_v1_booking_proto_rawDesc), len(file_booking_v1_booking_proto_rawDesc)))
+	})
+	return file_booking_v1_booking_proto_rawDescData
+}
+
+var file_booking_v1_booking_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_booking_v1_booking_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_booking_v1_booking_proto_goTypes = []any{
+	(RideStatus)(0),               // 0: booking.v1.RideStatus
+	(*Money)(nil),                 // 1: booking.v1.Money
+	(*Location)(nil),              // 2: booking.v1.Location
+	(*Stop)(nil),                  // 3: booking.v1.Stop
+	(*Booking)(nil),               // 4: booking.v1.Booking
+	(*CreateBookingRequest)(nil),  // 5: booking.v1.CreateBookingRequest
+	(*CreateBookingResponse)(nil), // 6: booking.v1.CreateBookingResponse
+	(*GetBookingRequest)(nil),     // 7: booking.v1.GetBookingRequest
+	(*GetBookingResponse)(nil),    // 8: booking.v1.GetBookingResponse
+	(*ListBookingsRequest)(nil),   // 9: booking.v1.ListBookingsRequest
+	(*ListBookingsResponse)(nil),  // 10: booking.v1.ListBookingsResponse
+	(*WatchBookingRequest)(nil),   // 11: booking.v1.WatchBookingRequest
+	(*WatchBookingResponse)(nil),  // 12: booking.v1.WatchBookingResponse
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_booking_v1_booking_proto_depIdxs = []int32{
+	2,  // 0: booking.v1.Stop.location:type_name -> booking.v1.Location
+	13, // 1: booking.v1.Stop.arrived_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: booking.v1.Booking.pickuploc:type_name -> booking.v1.Location
+	2,  // 3: booking.v1.Booking.dropoff:type_name -> booking.v1.Location
+	0,  // 4: booking.v1.Booking.ride_status:type_name -> booking.v1.RideStatus
+	13, // 5: booking.v1.Booking.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 6: booking.v1.Booking.price_money:type_name -> booking.v1.Money
+	1,  // 7: booking.v1.Booking.fare:type_name -> booking.v1.Money
+	1,  // 8: booking.v1.Booking.discount:type_name -> booking.v1.Money
+	13, // 9: booking.v1.Booking.arrived_at:type_name -> google.protobuf.Timestamp
+	1,  // 10: booking.v1.Booking.cancellation_fee:type_name -> booking.v1.Money
+	13, // 11: booking.v1.Booking.cancelled_at:type_name -> google.protobuf.Timestamp
+	13, // 12: booking.v1.Booking.scheduled_for:type_name -> google.protobuf.Timestamp
+	3,  // 13: booking.v1.Booking.stops:type_name -> booking.v1.Stop
+	2,  // 14: booking.v1.CreateBookingRequest.pickuploc:type_name -> booking.v1.Location
+	2,  // 15: booking.v1.CreateBookingRequest.dropoff:type_name -> booking.v1.Location
+	1,  // 16: booking.v1.CreateBookingRequest.price_money:type_name -> booking.v1.Money
+	13, // 17: booking.v1.CreateBookingRequest.scheduled_for:type_name -> google.protobuf.Timestamp
+	2,  // 18: booking.v1.CreateBookingRequest.stops:type_name -> booking.v1.Location
+	4,  // 19: booking.v1.CreateBookingResponse.booking:type_name -> booking.v1.Booking
+	4,  // 20: booking.v1.GetBookingResponse.booking:type_name -> booking.v1.Booking
+	4,  // 21: booking.v1.ListBookingsResponse.bookings:type_name -> booking.v1.Booking
+	4,  // 22: booking.v1.WatchBookingResponse.booking:type_name -> booking.v1.Booking
+	5,  // 23: booking.v1.BookingService.CreateBooking:input_type -> booking.v1.CreateBookingRequest
+	7,  // 24: booking.v1.BookingService.GetBooking:input_type -> booking.v1.GetBookingRequest
+	9,  // 25: booking.v1.BookingService.ListBookings:input_type -> booking.v1.ListBookingsRequest
+	11, // 26: booking.v1.BookingService.WatchBooking:input_type -> booking.v1.WatchBookingRequest
+	6,  // 27: booking.v1.BookingService.CreateBooking:output_type -> booking.v1.CreateBookingResponse
+	8,  // 28: booking.v1.BookingService.GetBooking:output_type -> booking.v1.GetBookingResponse
+	10, // 29: booking.v1.BookingService.ListBookings:output_type -> booking.v1.ListBookingsResponse
+	12, // 30: booking.v1.BookingService.WatchBooking:output_type -> booking.v1.WatchBookingResponse
+	27, // [27:31] is the sub-list for method output_type
+	23, // [23:27] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_booking_v1_booking_proto_init() }
+func file_booking_v1_booking_proto_init() {
+	if File_booking_v1_booking_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_booking_v1_booking_proto_rawDesc), len(file_booking_v1_booking_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_booking_v1_booking_proto_goTypes,
+		DependencyIndexes: file_booking_v1_booking_proto_depIdxs,
+		EnumInfos:         file_booking_v1_booking_proto_enumTypes,
+		MessageInfos:      file_booking_v1_booking_proto_msgTypes,
+	}.Build()
+	File_booking_v1_booking_proto = out.File
+	file_booking_v1_booking_proto_goTypes = nil
+	file_booking_v1_booking_proto_depIdxs = nil
+}
@@ -0,0 +1,85 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/config"
+	"booking_svc/internal/gen/bookingv1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const secret = "test-secret-0123456789"
+
+// dial serves s over an in-memory listener and returns a client connection.
+func dial(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = s.grpc.Serve(lis) }()
+	t.Cleanup(s.grpc.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestServer_Auth(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{HS256Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(config.Config{GRPCPort: "0"}, slog.New(slog.NewTextHandler(io.Discard, nil)), authn)
+	bookingv1.RegisterBookingServiceServer(s.Registrar(), bookingv1.UnimplementedBookingServiceServer{})
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	conn := dial(t, s)
+	client := bookingv1.NewBookingServiceClient(conn)
+	health := healthpb.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Health is public, like /livez.
+	resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("health without token: %v %v", resp, err)
+	}
+
+	// API methods need a valid bearer token; the stub service answers
+	// Unimplemented once auth lets the call through.
+	tok, _ := auth.NewHS256Token(secret, auth.Principal{Subject: "r-1", Role: auth.RoleRider}, time.Minute)
+	cases := []struct {
+		name string
+		md   metadata.MD
+		want codes.Code
+	}{
+		{"no token", nil, codes.Unauthenticated},
+		{"bad token", metadata.Pairs("authorization", "Bearer nope"), codes.Unauthenticated},
+		{"valid token", metadata.Pairs("authorization", "Bearer "+tok), codes.Unimplemented},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			callCtx := metadata.NewOutgoingContext(ctx, c.md)
+			_, err := client.ListBookings(callCtx, &bookingv1.ListBookingsRequest{})
+			if got := status.Code(err); got != c.want {
+				t.Fatalf("want %s, got %s (%v)", c.want, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package handlergrpc
+
+import (
+	"context"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/gen/bookingv1"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type BookingServer struct {
+	bookingv1.UnimplementedBookingServiceServer
+	svc service.BookingService
+}
+
+func NewBookingServer(svc service.BookingService) *BookingServer {
+	return &BookingServer{svc: svc}
+}
+
+// Register attaches the service to a gRPC server whose interceptors already
+// authenticate callers.
+func (s *BookingServer) Register(r grpc.ServiceRegistrar) {
+	bookingv1.RegisterBookingServiceServer(r, s)
+}
+
+func (s *BookingServer) CreateBooking(ctx context.Context, req *bookingv1.CreateBookingRequest) (*bookingv1.CreateBookingResponse, error) {
+	p, err := requireRole(ctx, auth.RoleRider)
+	if err != nil {
+		return nil, err
+	}
+	// The deprecated integer price counts whole units; the service prices it.
+	price := money.Money{Amount: req.GetPrice()}
+	if pm := req.GetPriceMoney(); pm != nil {
+		if pm.GetCurrency() == "" {
+			return nil, toStatus(problem.ValidationError{{Field: "price_money.currency", Message: "is required"}})
+		}
+		price = money.Money{Amount: pm.GetAmount(), Currency: pm.GetCurrency()}
+	}
+	var scheduledFor *time.Time
+	if ts := req.GetScheduledFor(); ts != nil {
+		at := ts.AsTime()
+		scheduledFor = &at
+	}
+	var stops []models.Location
+	for _, l := range req.GetStops() {
+		stops = append(stops, locationFromPB(l))
+	}
+	created, err := s.svc.CreateBooking(ctx, service.CreateBookingInput{
+		RiderID:      p.RiderID,
+		PickupLoc:    locationFromPB(req.GetPickuploc()),
+		Dropoff:      locationFromPB(req.GetDropoff()),
+		Stops:        stops,
+		Price:        price,
+		PromoCode:    req.GetPromoCode(),
+		ScheduledFor: scheduledFor,
+		VehicleType:  models.VehicleType(req.GetVehicleType()),
+		Passengers:   int(req.GetPassengers()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookingv1.CreateBookingResponse{Booking: bookingToPB(created)}, nil
+}
+
+func (s *BookingServer) GetBooking(ctx context.Context, req *bookingv1.GetBookingRequest) (*bookingv1.GetBookingResponse, error) {
+	b, err := s.visibleBooking(ctx, req.GetBookingId())
+	if err != nil {
+		return nil, err
+	}
+	return &bookingv1.GetBookingResponse{Booking: bookingToPB(b)}, nil
+}
+
+func (s *BookingServer) ListBookings(ctx context.Context, _ *bookingv1.ListBookingsRequest) (*bookingv1.ListBookingsResponse, error) {
+	p, err := requireRole(ctx, auth.RoleRider, auth.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	var items []models.Booking
+	if p.Role == auth.RoleAdmin {
+		items, err = s.svc.ListBookings(ctx)
+	} else {
+		items, err = s.svc.ListRiderBookings(ctx, p.RiderID)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &bookingv1.ListBookingsResponse{Bookings: make([]*bookingv1.Booking, 0, len(items))}
+	for _, b := range items {
+		out.Bookings = append(out.Bookings, bookingToPB(b))
+	}
+	return out, nil
+}
+
+func (s *BookingServer) WatchBooking(req *bookingv1.WatchBookingRequest, stream grpc.ServerStreamingServer[bookingv1.WatchBookingResponse]) error {
+	ctx := stream.Context()
+	if _, err := s.visibleBooking(ctx, req.GetBookingId()); err != nil {
+		return err
+	}
+	err := s.svc.WatchBooking(ctx, req.GetBookingId(), func(b models.Booking) error {
+		return stream.Send(&bookingv1.WatchBookingResponse{Booking: bookingToPB(b)})
+	})
+	return toStatus(err)
+}
+
+// visibleBooking loads a booking the caller may see: admins see any, riders
+// only their own. Other riders' bookings are reported as not found.
+func (s *BookingServer) visibleBooking(ctx context.Context, id string) (models.Booking, error) {
+	p, err := requireRole(ctx, auth.RoleRider, auth.RoleAdmin)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	b, err := s.svc.GetBooking(ctx, id)
+	if err != nil {
+		return models.Booking{}, toStatus(err)
+	}
+	if p.Role != auth.RoleAdmin && b.RiderID != p.RiderID {
+		return models.Booking{}, toStatus(service.ErrBookingNotFound)
+	}
+	return b, nil
+}
+
+func locationFromPB(l *bookingv1.Location) models.Location {
+	return models.Location{Lat: l.GetLat(), Lng: l.GetLng()}
+}
+
+func locationToPB(l models.Location) *bookingv1.Location {
+	return &bookingv1.Location{Lat: l.Lat, Lng: l.Lng}
+}
+
+var rideStatusToPB = map[models.RideStatus]bookingv1.RideStatus{
+	models.RideStatusScheduled: bookingv1.RideStatus_RIDE_STATUS_SCHEDULED,
+	models.RideStatusRequested: bookingv1.RideStatus_RIDE_STATUS_REQUESTED,
+	models.RideStatusAccepted:  bookingv1.RideStatus_RIDE_STATUS_ACCEPTED,
+	models.RideStatusCompleted: bookingv1.RideStatus_RIDE_STATUS_COMPLETED,
+	models.RideStatusCancelled: bookingv1.RideStatus_RIDE_STATUS_CANCELLED,
+}
+
+func bookingToPB(b models.Booking) *bookingv1.Booking {
+	out := &bookingv1.Booking{
+		BookingId:   b.BookingID,
+		RiderId:     b.RiderID,
+		Pickuploc:   locationToPB(b.PickupLoc),
+		Dropoff:     locationToPB(b.Dropoff),
+		Price:       b.Price.MajorUnits(),
+		PriceMoney:  moneyToPB(b.Price),
+		VehicleType: string(b.VehicleType),
+		Passengers:  int32(b.Passengers),
+		RideStatus:  rideStatusToPB[b.RideStatus],
+		CreatedAt:   timestamppb.New(b.CreatedAt),
+	}
+	if b.DriverID != nil {
+		out.DriverId = *b.DriverID
+	}
+	if b.Fare != nil {
+		out.Fare = moneyToPB(*b.Fare)
+	}
+	if b.PromoCode != nil {
+		out.PromoCode = *b.PromoCode
+	}
+	if b.Discount != nil {
+		out.Discount = moneyToPB(*b.Discount)
+	}
+	if b.ScheduledFor != nil {
+		out.ScheduledFor = timestamppb.New(*b.ScheduledFor)
+	}
+	for _, st := range b.Stops {
+		pb := &bookingv1.Stop{Location: locationToPB(st.Location)}
+		if st.ArrivedAt != nil {
+			pb.ArrivedAt = timestamppb.New(*st.ArrivedAt)
+		}
+		out.Stops = append(out.Stops, pb)
+	}
+	if b.ArrivedAt != nil {
+		out.ArrivedAt = timestamppb.New(*b.ArrivedAt)
+	}
+	if c := b.Cancellation; c != nil {
+		out.CancellationReason = string(c.Reason)
+		out.CancellationFee = moneyToPB(c.Fee)
+		out.CancelledAt = timestamppb.New(c.CancelledAt)
+	}
+	return out
+}
+
+func moneyToPB(m money.Money) *bookingv1.Money {
+	return &bookingv1.Money{Amount: m.Amount, Currency: m.Currency}
+}
@@ -0,0 +1,220 @@
+package handlergrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/gen/bookingv1"
+	"booking_svc/internal/models"
+	"booking_svc/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeBookingService struct {
+	service.BookingService
+	createFn func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error)
+	getFn    func(ctx context.Context, id string) (models.Booking, error)
+	watchFn  func(ctx context.Context, id string, send func(models.Booking) error) error
+}
+
+func (f *fakeBookingService) CreateBooking(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
+	return f.createFn(ctx, in)
+}
+func (f *fakeBookingService) GetBooking(ctx context.Context, id string) (models.Booking, error) {
+	return f.getFn(ctx, id)
+}
+func (f *fakeBookingService) WatchBooking(ctx context.Context, id string, send func(models.Booking) error) error {
+	return f.watchFn(ctx, id, send)
+}
+
+var (
+	rider  = auth.Principal{Subject: "r-1", Role: auth.RoleRider, RiderID: "r-1"}
+	driver = auth.Principal{Subject: "d-1", Role: auth.RoleDriver, DriverID: "d-1"}
+	admin  = auth.Principal{Subject: "ops", Role: auth.RoleAdmin}
+)
+
+// principalKey carries the test principal from client metadata to the server,
+// standing in for the JWT interceptor.
+const principalKey = "x-test-role"
+
+var principals = map[string]auth.Principal{"rider": rider, "driver": driver, "admin": admin}
+
+func withPrincipal(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(principalKey); len(v) > 0 {
+		return auth.WithPrincipal(ctx, principals[v[0]])
+	}
+	return ctx
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s principalStream) Context() context.Context { return s.ctx }
+
+// newClient serves svc over an in-memory connection.
+func newClient(t *testing.T, svc service.BookingService) bookingv1.BookingServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			return h(withPrincipal(ctx), req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			return h(srv, principalStream{ss, withPrincipal(ss.Context())})
+		}),
+	)
+	NewBookingServer(svc).Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return bookingv1.NewBookingServiceClient(conn)
+}
+
+func as(t *testing.T, role string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, principalKey, role)
+}
+
+func TestCreateBooking_GRPC(t *testing.T) {
+	var gotRider string
+	client := newClient(t, &fakeBookingService{
+		createFn: func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
+			if err := in.Validate(); err != nil {
+				return models.Booking{}, err
+			}
+			gotRider = in.RiderID
+			return models.Booking{BookingID: "b-1", RiderID: in.RiderID, Price: in.Price, RideStatus: models.RideStatusRequested}, nil
+		},
+	})
+	req := &bookingv1.CreateBookingRequest{
+		Pickuploc:  &bookingv1.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:    &bookingv1.Location{Lat: 12.95, Lng: 77.64},
+		PriceMoney: &bookingv1.Money{Amount: 22050, Currency: "INR"},
+	}
+
+	resp, err := client.CreateBooking(as(t, "rider"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := resp.GetBooking(); b.GetBookingId() != "b-1" || b.GetRideStatus() != bookingv1.RideStatus_RIDE_STATUS_REQUESTED || gotRider != "r-1" ||
+		b.GetPriceMoney().GetAmount() != 22050 || b.GetPriceMoney().GetCurrency() != "INR" || b.GetPrice() != 220 {
+		t.Fatalf("unexpected: %+v rider=%q", b, gotRider)
+	}
+
+	legacy := &bookingv1.CreateBookingRequest{Pickuploc: req.Pickuploc, Dropoff: req.Dropoff, Price: 220}
+	if resp, err := client.CreateBooking(as(t, "rider"), legacy); err != nil || resp.GetBooking().GetPriceMoney().GetAmount() != 220 ||
+		resp.GetBooking().GetPriceMoney().GetCurrency() != "" {
+		t.Fatalf("legacy price must reach the service as whole units: %v, %v", resp, err)
+	}
+
+	if _, err := client.CreateBooking(as(t, "driver"), req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("driver: want PermissionDenied, got %v", err)
+	}
+
+	_, err = client.CreateBooking(as(t, "rider"), &bookingv1.CreateBookingRequest{Pickuploc: &bookingv1.Location{Lat: 999}, Dropoff: &bookingv1.Location{}})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("want InvalidArgument, got %v", err)
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	if len(fields) != 2 || fields[0] != "pickuploc.lat" || fields[1] != "price" {
+		t.Fatalf("unexpected field violations: %v", fields)
+	}
+}
+
+func TestGetBooking_GRPC(t *testing.T) {
+	client := newClient(t, &fakeBookingService{
+		getFn: func(ctx context.Context, id string) (models.Booking, error) {
+			if id != "b-1" {
+				return models.Booking{}, service.ErrBookingNotFound
+			}
+			return models.Booking{BookingID: id, RiderID: "r-2"}, nil
+		},
+	})
+	cases := []struct {
+		name string
+		as   string
+		id   string
+		want codes.Code
+	}{
+		{"admin sees any", "admin", "b-1", codes.OK},
+		{"other rider sees nothing", "rider", "b-1", codes.NotFound},
+		{"missing", "admin", "b-x", codes.NotFound},
+		{"driver forbidden", "driver", "b-1", codes.PermissionDenied},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := client.GetBooking(as(t, c.as), &bookingv1.GetBookingRequest{BookingId: c.id})
+			if got := status.Code(err); got != c.want {
+				t.Fatalf("want %s, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestWatchBooking_GRPC(t *testing.T) {
+	driverID := "d-9"
+	client := newClient(t, &fakeBookingService{
+		getFn: func(ctx context.Context, id string) (models.Booking, error) {
+			return models.Booking{BookingID: id, RiderID: "r-1", RideStatus: models.RideStatusRequested}, nil
+		},
+		watchFn: func(ctx context.Context, id string, send func(models.Booking) error) error {
+			if err := send(models.Booking{BookingID: id, RiderID: "r-1", RideStatus: models.RideStatusRequested}); err != nil {
+				return err
+			}
+			return send(models.Booking{BookingID: id, RiderID: "r-1", RideStatus: models.RideStatusAccepted, DriverID: &driverID})
+		},
+	})
+
+	stream, err := client.WatchBooking(as(t, "rider"), &bookingv1.WatchBookingRequest{BookingId: "b-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []bookingv1.RideStatus
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, msg.GetBooking().GetRideStatus())
+		if msg.GetBooking().GetRideStatus() == bookingv1.RideStatus_RIDE_STATUS_ACCEPTED && msg.GetBooking().GetDriverId() != driverID {
+			t.Fatalf("driver_id missing: %+v", msg.GetBooking())
+		}
+	}
+	if len(got) != 2 || got[1] != bookingv1.RideStatus_RIDE_STATUS_ACCEPTED {
+		t.Fatalf("unexpected updates: %v", got)
+	}
+}
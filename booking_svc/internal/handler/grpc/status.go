@@ -0,0 +1,96 @@
+// Package handlergrpc adapts the service layer to the generated gRPC
+// interfaces, applying the same role rules as the REST handlers.
+package handlergrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain qualifies ErrorInfo reasons, which are the REST problem codes.
+const errorDomain = "booking_svc"
+
+// toStatus renders a service error as a gRPC status. ErrorInfo.Reason carries
+// the same stable code REST clients see in problem+json, and validation
+// failures list their fields as BadRequest violations.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	p := service.Problems.Map(err)
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+	st := status.New(grpcCode(p.Status), msg)
+	info := &errdetails.ErrorInfo{Reason: string(p.Code), Domain: errorDomain}
+	withDetails, derr := st.WithDetails(info)
+	if len(p.Errors) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, fe := range p.Errors {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fe.Field,
+				Description: fe.Message,
+			})
+		}
+		withDetails, derr = st.WithDetails(info, br)
+	}
+	if derr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// grpcCode translates the problem's HTTP status into the matching gRPC code.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusPaymentRequired, http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// requireRole returns the caller if they hold one of roles, else PermissionDenied.
+func requireRole(ctx context.Context, roles ...auth.Role) (auth.Principal, error) {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Principal{}, status.Error(codes.Unauthenticated, auth.ErrMissingToken.Error())
+	}
+	if !p.HasRole(roles...) {
+		return auth.Principal{}, toStatus(problem.New(http.StatusForbidden, problem.CodeForbidden,
+			"role "+string(p.Role)+" may not perform this action"))
+	}
+	return p, nil
+}
@@ -2,44 +2,136 @@ package handlerhttp
 
 import (
 	"fmt"
-	"strings"
+	"net/url"
+	"slices"
+	"time"
 
 	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/promo"
+	"booking_svc/internal/service"
 )
 
 type CreateBookingRequest struct {
-	PickupLoc models.Location `json:"pickuploc"`
-	Dropoff   models.Location `json:"dropoff"`
-	Price     int             `json:"price"`
+	PickupLoc models.Location   `json:"pickuploc"`
+	Dropoff   models.Location   `json:"dropoff"`
+	Stops     []models.Location `json:"stops,omitempty"`
+	// Price is {"amount":<minor units>,"currency":"<ISO 4217>"}; a bare
+	// integer is still accepted as whole units of DEFAULT_CURRENCY.
+	Price     money.Money `json:"price"`
+	PromoCode string      `json:"promo_code,omitempty"`
+	// ScheduledFor reserves the ride for a later pickup.
+	ScheduledFor *time.Time         `json:"scheduled_for,omitempty"`
+	VehicleType  models.VehicleType `json:"vehicle_type,omitempty"`
+	Passengers   int                `json:"passengers,omitempty"`
 }
 
 func (r CreateBookingRequest) Validate() error {
-	var errs []string
+	return service.CreateBookingInput{
+		PickupLoc:   r.PickupLoc,
+		Dropoff:     r.Dropoff,
+		Stops:       r.Stops,
+		Price:       r.Price,
+		VehicleType: r.VehicleType,
+		Passengers:  r.Passengers,
+	}.Validate()
+}
+
+type EstimateFareRequest struct {
+	PickupLoc models.Location   `json:"pickuploc"`
+	Dropoff   models.Location   `json:"dropoff"`
+	Stops     []models.Location `json:"stops,omitempty"`
+}
+
+func (r EstimateFareRequest) Input() service.EstimateFareInput {
+	return service.EstimateFareInput{PickupLoc: r.PickupLoc, Dropoff: r.Dropoff, Stops: r.Stops}
+}
+
+type CompleteBookingRequest struct {
+	// Fare is the final fare before any promo discount, at most the price;
+	// nil uses the price.
+	Fare *money.Money `json:"fare,omitempty"`
+}
 
-	if !isValidLat(r.PickupLoc.Lat) {
-		errs = append(errs, "pickuploc.lat must be between -90 and 90")
+func (r CompleteBookingRequest) Validate() error {
+	if r.Fare != nil && !money.ValidCurrency(r.Fare.Currency) {
+		return problem.ValidationError{{Field: "fare.currency", Message: "must be a supported ISO 4217 code"}}
 	}
-	if !isValidLng(r.PickupLoc.Lng) {
-		errs = append(errs, "pickuploc.lng must be between -180 and 180")
+	return nil
+}
+
+type CreatePromotionRequest struct {
+	Code           string       `json:"code"`
+	Description    string       `json:"description"`
+	Kind           promo.Kind   `json:"kind"`
+	PercentBPS     int64        `json:"percent_bps"`
+	AmountOff      *money.Money `json:"amount_off"`
+	MaxDiscount    *money.Money `json:"max_discount"`
+	MaxRedemptions int          `json:"max_redemptions"`
+	MaxPerRider    int          `json:"max_per_rider"`
+	// StartsAt defaults to now.
+	StartsAt *time.Time  `json:"starts_at"`
+	EndsAt   *time.Time  `json:"ends_at"`
+	Area     *promo.Area `json:"area"`
+}
+
+// Promotion is the promotion to create; the service validates it.
+func (r CreatePromotionRequest) Promotion() promo.Promotion {
+	p := promo.Promotion{
+		Code:           r.Code,
+		Description:    r.Description,
+		Kind:           r.Kind,
+		PercentBPS:     r.PercentBPS,
+		AmountOff:      r.AmountOff,
+		MaxDiscount:    r.MaxDiscount,
+		MaxRedemptions: r.MaxRedemptions,
+		MaxPerRider:    r.MaxPerRider,
+		EndsAt:         r.EndsAt,
+		Area:           r.Area,
 	}
-	if !isValidLat(r.Dropoff.Lat) {
-		errs = append(errs, "dropoff.lat must be between -90 and 90")
+	if r.StartsAt != nil {
+		p.StartsAt = *r.StartsAt
 	}
-	if !isValidLng(r.Dropoff.Lng) {
-		errs = append(errs, "dropoff.lng must be between -180 and 180")
+	return p
+}
+
+type RatingRequest struct {
+	Stars   int    `json:"stars"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func (r RatingRequest) Input() service.RatingInput {
+	return service.RatingInput{Stars: r.Stars, Comment: r.Comment}
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+func (r CreateWebhookRequest) Validate() error {
+	var errs problem.ValidationError
+
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, problem.FieldError{Field: "url", Message: "must be an absolute http(s) URL"})
 	}
-	if r.Price <= 0 {
-		errs = append(errs, "price must be > 0")
+	if len(r.EventTypes) == 0 {
+		errs = append(errs, problem.FieldError{Field: "event_types", Message: "must not be empty"})
 	}
-	if r.PickupLoc == r.Dropoff {
-		errs = append(errs, "pickuploc and dropoff cannot be the same")
+	for i, et := range r.EventTypes {
+		if !slices.Contains(models.WebhookEventTypes, et) {
+			errs = append(errs, problem.FieldError{Field: fmt.Sprintf("event_types[%d]", i), Message: fmt.Sprintf("unknown event %q", et)})
+		}
+	}
+	if len(r.Secret) < 16 {
+		errs = append(errs, problem.FieldError{Field: "secret", Message: "must be at least 16 characters"})
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("validation failed: %s", strings.Join(errs, "; "))
+		return errs
 	}
 	return nil
 }
-
-func isValidLat(v float64) bool { return v >= -90 && v <= 90 }
-func isValidLng(v float64) bool { return v >= -180 && v <= 180 }
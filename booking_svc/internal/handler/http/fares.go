@@ -0,0 +1,43 @@
+package handlerhttp
+
+import (
+	"net/http"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type FareHandler struct {
+	svc service.FareService
+}
+
+func NewFareHandler(svc service.FareService) *FareHandler {
+	return &FareHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the fare quote endpoint for riders and admins.
+func (h *FareHandler) RegisterRoutes(r chi.Router) {
+	r.With(auth.RequireRole(auth.RoleRider, auth.RoleAdmin)).Post("/fares/estimate", h.estimateFare)
+}
+
+func (h *FareHandler) estimateFare(w http.ResponseWriter, r *http.Request) {
+	var req EstimateFareRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	in := req.Input()
+	if err := in.Validate(); err != nil {
+		problem.Write(w, r, problem.Validation(err))
+		return
+	}
+	est, err := h.svc.EstimateFare(r.Context(), in)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, est)
+}
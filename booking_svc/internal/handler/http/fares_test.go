@@ -0,0 +1,64 @@
+package handlerhttp
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/fare"
+	"booking_svc/internal/money"
+	"booking_svc/internal/openapi"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+)
+
+func TestEstimateFare_Handler(t *testing.T) {
+	h := NewFareHandler(service.NewFares(fare.Tariff{Currency: "INR", Base: 5000, PerKm: 1200, PerStop: 2500}))
+	validate := openapi.MustLoad().Validator(openapi.ResponsesStrict, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	post := func(p auth.Principal, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/fares/estimate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		validate(routerAs(p, h.RegisterRoutes)).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("stops lengthen the route and add their charge", func(t *testing.T) {
+		direct := post(rider, `{"pickuploc":{"lat":0,"lng":0},"dropoff":{"lat":0,"lng":1}}`)
+		via := post(admin, `{"pickuploc":{"lat":0,"lng":0},"dropoff":{"lat":0,"lng":1},"stops":[{"lat":0,"lng":2}]}`)
+		if direct.Code != http.StatusOK || via.Code != http.StatusOK {
+			t.Fatalf("want 200s, got %d %s / %d %s", direct.Code, direct.Body, via.Code, via.Body)
+		}
+		var d, v fare.Estimate
+		if err := json.Unmarshal(direct.Body.Bytes(), &d); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(via.Body.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+		if d.Stops != 0 || d.Fare != (money.Money{Amount: 138434, Currency: "INR"}) {
+			t.Fatalf("unexpected direct estimate: %+v", d)
+		}
+		if v.Stops != 1 || v.DistanceKm <= d.DistanceKm || v.Fare.Amount <= d.Fare.Amount+2500 {
+			t.Fatalf("the detour must cost more than its stop charge: %+v vs %+v", v, d)
+		}
+	})
+
+	t.Run("400 for a route that goes nowhere", func(t *testing.T) {
+		rr := post(rider, `{"pickuploc":{"lat":1,"lng":1},"dropoff":{"lat":1,"lng":1}}`)
+		if p := decodeProblem(t, rr); rr.Code != http.StatusBadRequest || p.Code != problem.CodeValidationFailed || p.Errors[0].Field != "dropoff" {
+			t.Fatalf("want 400 on dropoff, got %d %+v", rr.Code, p)
+		}
+	})
+
+	t.Run("403 for drivers", func(t *testing.T) {
+		if rr := post(driver, `{"pickuploc":{"lat":0,"lng":0},"dropoff":{"lat":0,"lng":1}}`); rr.Code != http.StatusForbidden {
+			t.Fatalf("want 403, got %d", rr.Code)
+		}
+	})
+}
@@ -1,8 +1,14 @@
 package handlerhttp
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
+	"booking_svc/internal/auth"
+	"booking_svc/internal/models"
+	"booking_svc/internal/problem"
 	"booking_svc/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -17,40 +23,201 @@ func NewBookingHandler(svc service.BookingService) *BookingHandler {
 }
 
 // RegisterRoutes attaches endpoints to the provided router.
-// We’ll call this from the HTTP server wiring in the next step.
+// The router must already authenticate callers; riders act on their own bookings.
 func (h *BookingHandler) RegisterRoutes(r chi.Router) {
-	r.Post("/bookings", h.createBooking)
-	r.Get("/bookings", h.listBookings)
+	r.With(auth.RequireRole(auth.RoleRider)).Post("/bookings", h.createBooking)
+	r.With(auth.RequireRole(auth.RoleRider, auth.RoleAdmin)).Get("/bookings", h.listBookings)
+	r.With(auth.RequireRole(auth.RoleRider, auth.RoleAdmin)).Get("/bookings/scheduled", h.listScheduledBookings)
+	r.With(auth.RequireRole(auth.RoleRider, auth.RoleAdmin)).Post("/bookings/{booking_id}/cancel", h.cancelBooking)
+	r.With(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin)).Post("/bookings/{booking_id}/complete", h.completeBooking)
+	r.With(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin)).Post("/bookings/{booking_id}/arrive", h.markArrived)
+	r.With(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin)).Post("/bookings/{booking_id}/stops/{stop}/arrive", h.markStopArrived)
+	r.With(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin)).Post("/bookings/{booking_id}/no-show", h.reportNoShow)
+	r.With(auth.RequireRole(auth.RoleRider, auth.RoleAdmin)).Get("/bookings/{booking_id}/payment", h.getPayment)
 }
 
 func (h *BookingHandler) createBooking(w http.ResponseWriter, r *http.Request) {
 	var req CreateBookingRequest
 	if err := decodeJSON(r, &req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		writeInvalidJSON(w, r, err)
 		return
 	}
 	if err := req.Validate(); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		problem.Write(w, r, problem.Validation(err))
 		return
 	}
 
+	p, _ := auth.FromContext(r.Context())
 	created, err := h.svc.CreateBooking(r.Context(), service.CreateBookingInput{
-		PickupLoc: req.PickupLoc,
-		Dropoff:   req.Dropoff,
-		Price:     req.Price,
+		RiderID:      p.RiderID,
+		PickupLoc:    req.PickupLoc,
+		Dropoff:      req.Dropoff,
+		Stops:        req.Stops,
+		Price:        req.Price,
+		PromoCode:    req.PromoCode,
+		ScheduledFor: req.ScheduledFor,
+		VehicleType:  req.VehicleType,
+		Passengers:   req.Passengers,
 	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to create booking")
+		writeServiceError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, created)
 }
 
 func (h *BookingHandler) listBookings(w http.ResponseWriter, r *http.Request) {
-	items, err := h.svc.ListBookings(r.Context())
+	var items []models.Booking
+	var err error
+	if p, _ := auth.FromContext(r.Context()); p.Role == auth.RoleAdmin {
+		items, err = h.svc.ListBookings(r.Context())
+	} else {
+		items, err = h.svc.ListRiderBookings(r.Context(), p.RiderID)
+	}
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to list bookings")
+		writeServiceError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
 }
+
+// listScheduledBookings lists upcoming reservations: a rider's own, or every
+// rider's for admins.
+func (h *BookingHandler) listScheduledBookings(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	riderID := p.RiderID
+	if p.Role == auth.RoleAdmin {
+		riderID = ""
+	}
+	items, err := h.svc.ListScheduledBookings(r.Context(), riderID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *BookingHandler) cancelBooking(w http.ResponseWriter, r *http.Request) {
+	b, err := h.visibleBooking(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	cancelled, err := h.svc.CancelBooking(r.Context(), b.BookingID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cancelled)
+}
+
+func (h *BookingHandler) completeBooking(w http.ResponseWriter, r *http.Request) {
+	var req CompleteBookingRequest
+	// The body is optional: without a fare the full price is captured.
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, r, problem.Validation(err))
+		return
+	}
+	b, err := h.visibleBooking(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	completed, err := h.svc.CompleteBooking(r.Context(), b.BookingID, req.Fare)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, completed)
+}
+
+func (h *BookingHandler) markArrived(w http.ResponseWriter, r *http.Request) {
+	b, err := h.visibleBooking(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	arrived, err := h.svc.MarkArrived(r.Context(), b.BookingID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, arrived)
+}
+
+func (h *BookingHandler) markStopArrived(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.Atoi(chi.URLParam(r, "stop"))
+	if err != nil || seq < 1 {
+		problem.Write(w, r, problem.Validation(problem.ValidationError{
+			{Field: "stop", Message: "must be a positive stop number"},
+		}))
+		return
+	}
+	b, err := h.visibleBooking(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	arrived, err := h.svc.MarkStopArrived(r.Context(), b.BookingID, seq)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, arrived)
+}
+
+func (h *BookingHandler) reportNoShow(w http.ResponseWriter, r *http.Request) {
+	b, err := h.visibleBooking(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	cancelled, err := h.svc.ReportNoShow(r.Context(), b.BookingID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cancelled)
+}
+
+func (h *BookingHandler) getPayment(w http.ResponseWriter, r *http.Request) {
+	b, err := h.visibleBooking(r)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	pay, err := h.svc.GetPayment(r.Context(), b.BookingID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pay)
+}
+
+// visibleBooking loads the {booking_id} booking if the caller may act on it:
+// admins on any, riders on their own, drivers on the ones assigned to them.
+// Anyone else's booking is reported as not found, as over gRPC.
+func (h *BookingHandler) visibleBooking(r *http.Request) (models.Booking, error) {
+	b, err := h.svc.GetBooking(r.Context(), chi.URLParam(r, "booking_id"))
+	if err != nil {
+		return models.Booking{}, err
+	}
+	p, _ := auth.FromContext(r.Context())
+	switch p.Role {
+	case auth.RoleAdmin:
+		return b, nil
+	case auth.RoleRider:
+		if b.RiderID == p.RiderID {
+			return b, nil
+		}
+	case auth.RoleDriver:
+		if b.DriverID != nil && *b.DriverID == p.DriverID {
+			return b, nil
+		}
+	}
+	return models.Booking{}, service.ErrBookingNotFound
+}
@@ -3,21 +3,39 @@ package handlerhttp
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"booking_svc/internal/auth"
 	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/promo"
 	"booking_svc/internal/service"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type fakeBookingService struct {
-	createFn func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error)
-	listFn   func(ctx context.Context) ([]models.Booking, error)
+	createFn    func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error)
+	listFn      func(ctx context.Context) ([]models.Booking, error)
+	listRiderFn func(ctx context.Context, riderID string) ([]models.Booking, error)
+	scheduledFn func(ctx context.Context, riderID string) ([]models.Booking, error)
+	getFn       func(ctx context.Context, id string) (models.Booking, error)
+	watchFn     func(ctx context.Context, id string, send func(models.Booking) error) error
+	sinceFn     func(ctx context.Context, since time.Time, limit int) ([]models.Booking, error)
+	republishFn func(ctx context.Context, id string) error
+	completeFn  func(ctx context.Context, id string, fare *money.Money) (models.Booking, error)
+	cancelFn    func(ctx context.Context, id string) (models.Booking, error)
+	arriveFn    func(ctx context.Context, id string) (models.Booking, error)
+	noShowFn    func(ctx context.Context, id string) (models.Booking, error)
+	stopFn      func(ctx context.Context, id string, seq int) (models.Booking, error)
+	paymentFn   func(ctx context.Context, id string) (models.Payment, error)
 }
 
 func (f *fakeBookingService) CreateBooking(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
@@ -26,6 +44,67 @@ func (f *fakeBookingService) CreateBooking(ctx context.Context, in service.Creat
 func (f *fakeBookingService) ListBookings(ctx context.Context) ([]models.Booking, error) {
 	return f.listFn(ctx)
 }
+func (f *fakeBookingService) ListRiderBookings(ctx context.Context, riderID string) ([]models.Booking, error) {
+	return f.listRiderFn(ctx, riderID)
+}
+func (f *fakeBookingService) ListScheduledBookings(ctx context.Context, riderID string) ([]models.Booking, error) {
+	return f.scheduledFn(ctx, riderID)
+}
+func (f *fakeBookingService) GetBooking(ctx context.Context, id string) (models.Booking, error) {
+	return f.getFn(ctx, id)
+}
+func (f *fakeBookingService) WatchBooking(ctx context.Context, id string, send func(models.Booking) error) error {
+	return f.watchFn(ctx, id, send)
+}
+
+func (f *fakeBookingService) ListBookingsSince(ctx context.Context, since time.Time, limit int) ([]models.Booking, error) {
+	return f.sinceFn(ctx, since, limit)
+}
+func (f *fakeBookingService) RepublishCreated(ctx context.Context, id string) error {
+	return f.republishFn(ctx, id)
+}
+func (f *fakeBookingService) CompleteBooking(ctx context.Context, id string, fare *money.Money) (models.Booking, error) {
+	return f.completeFn(ctx, id, fare)
+}
+func (f *fakeBookingService) CancelBooking(ctx context.Context, id string) (models.Booking, error) {
+	return f.cancelFn(ctx, id)
+}
+func (f *fakeBookingService) MarkArrived(ctx context.Context, id string) (models.Booking, error) {
+	return f.arriveFn(ctx, id)
+}
+func (f *fakeBookingService) ReportNoShow(ctx context.Context, id string) (models.Booking, error) {
+	return f.noShowFn(ctx, id)
+}
+func (f *fakeBookingService) MarkStopArrived(ctx context.Context, id string, seq int) (models.Booking, error) {
+	return f.stopFn(ctx, id, seq)
+}
+func (f *fakeBookingService) GetPayment(ctx context.Context, id string) (models.Payment, error) {
+	return f.paymentFn(ctx, id)
+}
+func (f *fakeBookingService) ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	return 0, nil
+}
+func (f *fakeBookingService) ReleaseScheduled(ctx context.Context, now time.Time, limit int) (int, error) {
+	return 0, nil
+}
+
+var (
+	rider  = auth.Principal{Subject: "r-1", Role: auth.RoleRider, RiderID: "r-1"}
+	driver = auth.Principal{Subject: "d-1", Role: auth.RoleDriver, DriverID: "d-1"}
+	admin  = auth.Principal{Subject: "ops", Role: auth.RoleAdmin}
+)
+
+// routerAs registers h's routes behind a middleware that authenticates every request as p.
+func routerAs(p auth.Principal, register func(chi.Router)) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(auth.WithPrincipal(req.Context(), p)))
+		})
+	})
+	register(r)
+	return r
+}
 
 func TestCreateBooking_Handler(t *testing.T) {
 	now := time.Now().UTC()
@@ -33,20 +112,29 @@ func TestCreateBooking_Handler(t *testing.T) {
 		BookingID:  "b-1",
 		PickupLoc:  models.Location{Lat: 12.9, Lng: 77.6},
 		Dropoff:    models.Location{Lat: 12.95, Lng: 77.64},
-		Price:      220,
+		Price:      money.Money{Amount: 22050, Currency: "INR"},
 		RideStatus: models.RideStatusRequested,
 		CreatedAt:  now,
 	}
+	var gotRider, gotPromo string
+	var gotPrice money.Money
+	var gotScheduled *time.Time
+	var gotStops []models.Location
+	var gotVehicle models.VehicleType
+	var gotPassengers int
 	h := NewBookingHandler(&fakeBookingService{
-		createFn: func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) { return want, nil },
-		listFn:   func(ctx context.Context) ([]models.Booking, error) { return nil, nil },
+		createFn: func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
+			gotRider, gotPrice, gotPromo, gotScheduled, gotStops = in.RiderID, in.Price, in.PromoCode, in.ScheduledFor, in.Stops
+			gotVehicle, gotPassengers = in.VehicleType, in.Passengers
+			return want, nil
+		},
+		listFn: func(ctx context.Context) ([]models.Booking, error) { return nil, nil },
 	})
 
-	r := chi.NewRouter()
-	h.RegisterRoutes(r)
+	r := routerAs(rider, h.RegisterRoutes)
 
 	t.Run("201", func(t *testing.T) {
-		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220}`
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":{"amount":22050,"currency":"INR"}}`
 		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		rr := httptest.NewRecorder()
@@ -59,9 +147,101 @@ func TestCreateBooking_Handler(t *testing.T) {
 		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
 			t.Fatalf("json: %v", err)
 		}
-		if got.BookingID != "b-1" || got.Price != 220 || got.RideStatus != models.RideStatusRequested {
+		if got.BookingID != "b-1" || got.Price != want.Price || got.RideStatus != models.RideStatusRequested {
 			t.Fatalf("unexpected: %+v", got)
 		}
+		if gotRider != "r-1" || gotPrice != want.Price {
+			t.Fatalf("rider_id must come from the token and price from the body, got %q, %v", gotRider, gotPrice)
+		}
+	})
+
+	t.Run("201 with a legacy integer price", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated || gotPrice != (money.Money{Amount: 220}) {
+			t.Fatalf("want 201 with whole units left for the service to price, got %d %#v", rr.Code, gotPrice)
+		}
+	})
+
+	t.Run("201 with a promo code", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220,"promo_code":"launch15"}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated || gotPromo != "launch15" {
+			t.Fatalf("want 201 with the code passed through, got %d %q", rr.Code, gotPromo)
+		}
+	})
+
+	t.Run("201 scheduled", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220,"scheduled_for":"2030-01-02T08:30:00Z"}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		at := time.Date(2030, 1, 2, 8, 30, 0, 0, time.UTC)
+		if rr.Code != http.StatusCreated || gotScheduled == nil || !gotScheduled.Equal(at) {
+			t.Fatalf("want 201 with the pickup time passed through, got %d %v", rr.Code, gotScheduled)
+		}
+	})
+
+	t.Run("201 with stops", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"stops":[{"lat":12.92,"lng":77.61},{"lat":12.93,"lng":77.62}],"price":220}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated || len(gotStops) != 2 || gotStops[1] != (models.Location{Lat: 12.93, Lng: 77.62}) {
+			t.Fatalf("want 201 with the stops passed through in order, got %d %v", rr.Code, gotStops)
+		}
+	})
+
+	t.Run("400 for a repeated stop", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"stops":[{"lat":12.9,"lng":77.6}],"price":220}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if p := decodeProblem(t, rr); rr.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "stops[0]" {
+			t.Fatalf("want 400 on stops[0], got %d %+v", rr.Code, p)
+		}
+	})
+
+	t.Run("201 with a vehicle type", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220,"vehicle_type":"suv","passengers":5}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated || gotVehicle != models.VehicleSUV || gotPassengers != 5 {
+			t.Fatalf("want 201 with the vehicle passed through, got %d %q %d", rr.Code, gotVehicle, gotPassengers)
+		}
+	})
+
+	t.Run("400 for more passengers than the vehicle seats", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220,"vehicle_type":"bike","passengers":2}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if p := decodeProblem(t, rr); rr.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "passengers" {
+			t.Fatalf("want 400 on passengers, got %d %+v", rr.Code, p)
+		}
+	})
+
+	t.Run("403 for drivers", func(t *testing.T) {
+		body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220}`
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		routerAs(driver, h.RegisterRoutes).ServeHTTP(rr, req)
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("want 403, got %d", rr.Code)
+		}
 	})
 
 	t.Run("400 validation", func(t *testing.T) {
@@ -72,6 +252,11 @@ func TestCreateBooking_Handler(t *testing.T) {
 		if rr.Code != http.StatusBadRequest {
 			t.Fatalf("want 400, got %d", rr.Code)
 		}
+		p := decodeProblem(t, rr)
+		if p.Code != problem.CodeValidationFailed || len(p.Errors) != 2 ||
+			p.Errors[0].Field != "pickuploc.lat" || p.Errors[1].Field != "price" {
+			t.Fatalf("unexpected problem: %+v", p)
+		}
 	})
 
 	t.Run("400 invalid json", func(t *testing.T) {
@@ -85,29 +270,310 @@ func TestCreateBooking_Handler(t *testing.T) {
 	})
 }
 
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+	if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Fatalf("want %s, got %q", problem.ContentType, ct)
+	}
+	var p problem.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	return p
+}
+
+func TestCreateBooking_ServiceErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"not stored", fmt.Errorf("%w: %w", service.ErrBookingNotStored, errors.New("conn refused")), http.StatusServiceUnavailable, problem.CodeBookingNotStored},
+		{"not dispatched", fmt.Errorf("%w: %w", service.ErrBookingNotDispatched, errors.New("broker down")), http.StatusServiceUnavailable, problem.CodeBookingNotDispatched},
+		{"promo exhausted", promo.ErrExhausted, http.StatusUnprocessableEntity, problem.CodePromoExhausted},
+		{"promo already redeemed", promo.ErrAlreadyRedeemed, http.StatusUnprocessableEntity, problem.CodePromoAlreadyRedeemed},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, problem.CodeTimeout},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, problem.CodeInternal},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := NewBookingHandler(&fakeBookingService{
+				createFn: func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
+					return models.Booking{}, c.err
+				},
+			})
+			body := `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220}`
+			req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			routerAs(rider, h.RegisterRoutes).ServeHTTP(rr, req)
+
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d", c.wantStatus, rr.Code)
+			}
+			if p := decodeProblem(t, rr); p.Code != c.wantCode || p.Status != c.wantStatus {
+				t.Fatalf("unexpected problem: %+v", p)
+			}
+		})
+	}
+}
+
 func TestListBookings_Handler(t *testing.T) {
-	items := []models.Booking{{BookingID: "b-2", CreatedAt: time.Now().UTC()}}
+	all := []models.Booking{{BookingID: "b-2", CreatedAt: time.Now().UTC()}, {BookingID: "b-3", RiderID: "r-9"}}
 	h := NewBookingHandler(&fakeBookingService{
 		createFn: func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
 			return models.Booking{}, nil
 		},
-		listFn: func(ctx context.Context) ([]models.Booking, error) { return items, nil },
+		listFn: func(ctx context.Context) ([]models.Booking, error) { return all, nil },
+		listRiderFn: func(ctx context.Context, riderID string) ([]models.Booking, error) {
+			return []models.Booking{{BookingID: "b-own", RiderID: riderID}}, nil
+		},
 	})
-	r := chi.NewRouter()
-	h.RegisterRoutes(r)
 
-	req := httptest.NewRequest(http.MethodGet, "/bookings", nil)
-	rr := httptest.NewRecorder()
-	r.ServeHTTP(rr, req)
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"admin sees all", admin, http.StatusOK, []string{"b-2", "b-3"}},
+		{"rider sees own", rider, http.StatusOK, []string{"b-own"}},
+		{"driver forbidden", driver, http.StatusForbidden, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/bookings", nil)
+			rr := httptest.NewRecorder()
+			routerAs(c.as, h.RegisterRoutes).ServeHTTP(rr, req)
 
-	if rr.Code != http.StatusOK {
-		t.Fatalf("want 200, got %d", rr.Code)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d", c.wantStatus, rr.Code)
+			}
+			if c.wantIDs == nil {
+				return
+			}
+			var got []models.Booking
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json: %v", err)
+			}
+			if len(got) != len(c.wantIDs) {
+				t.Fatalf("unexpected: %+v", got)
+			}
+			for i, id := range c.wantIDs {
+				if got[i].BookingID != id {
+					t.Fatalf("unexpected: %+v", got)
+				}
+			}
+		})
 	}
-	var got []models.Booking
-	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
-		t.Fatalf("json: %v", err)
+}
+
+func TestListScheduledBookings_Handler(t *testing.T) {
+	h := NewBookingHandler(&fakeBookingService{
+		scheduledFn: func(ctx context.Context, riderID string) ([]models.Booking, error) {
+			return []models.Booking{{BookingID: "b-" + riderID, RiderID: riderID, RideStatus: models.RideStatusScheduled}}, nil
+		},
+	})
+
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		wantStatus int
+		wantID     string
+	}{
+		{"admin sees every rider's", admin, http.StatusOK, "b-"},
+		{"rider sees own", rider, http.StatusOK, "b-r-1"},
+		{"driver forbidden", driver, http.StatusForbidden, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/bookings/scheduled", nil)
+			rr := httptest.NewRecorder()
+			routerAs(c.as, h.RegisterRoutes).ServeHTTP(rr, req)
+
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d", c.wantStatus, rr.Code)
+			}
+			if c.wantStatus != http.StatusOK {
+				return
+			}
+			var got []models.Booking
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json: %v", err)
+			}
+			if len(got) != 1 || got[0].BookingID != c.wantID {
+				t.Fatalf("unexpected: %+v", got)
+			}
+		})
+	}
+}
+
+// lifecycleService serves one Accepted booking of rider r-1 driven by d-1.
+func lifecycleService(gotFare **money.Money) *fakeBookingService {
+	driverID := "d-1"
+	b := models.Booking{
+		BookingID:  "b-1",
+		RiderID:    "r-1",
+		Price:      money.Money{Amount: 22050, Currency: "INR"},
+		RideStatus: models.RideStatusAccepted,
+		DriverID:   &driverID,
+		Stops:      []models.Stop{{Location: models.Location{Lat: 12.92, Lng: 77.61}}, {Location: models.Location{Lat: 12.93, Lng: 77.62}}},
+		CreatedAt:  time.Now().UTC(),
+	}
+	return &fakeBookingService{
+		getFn: func(ctx context.Context, id string) (models.Booking, error) {
+			if id != b.BookingID {
+				return models.Booking{}, service.ErrBookingNotFound
+			}
+			return b, nil
+		},
+		completeFn: func(ctx context.Context, id string, fare *money.Money) (models.Booking, error) {
+			*gotFare = fare
+			out := b
+			out.RideStatus = models.RideStatusCompleted
+			out.Fare = &out.Price
+			if fare != nil {
+				out.Fare = fare
+			}
+			return out, nil
+		},
+		cancelFn: func(ctx context.Context, id string) (models.Booking, error) {
+			out := b
+			out.RideStatus = models.RideStatusCancelled
+			out.Cancellation = &models.Cancellation{Reason: models.CancellationFree, Fee: money.Money{Currency: "INR"}, CancelledAt: time.Now().UTC()}
+			return out, nil
+		},
+		arriveFn: func(ctx context.Context, id string) (models.Booking, error) {
+			out, at := b, time.Now().UTC()
+			out.ArrivedAt = &at
+			return out, nil
+		},
+		noShowFn: func(ctx context.Context, id string) (models.Booking, error) {
+			out := b
+			out.RideStatus = models.RideStatusCancelled
+			out.Cancellation = &models.Cancellation{Reason: models.CancellationNoShow, Fee: money.Money{Amount: 4410, Currency: "INR"}, CancelledAt: time.Now().UTC()}
+			return out, nil
+		},
+		stopFn: func(ctx context.Context, id string, seq int) (models.Booking, error) {
+			switch {
+			case seq > len(b.Stops):
+				return models.Booking{}, service.ErrStopNotFound
+			case seq > 1:
+				return models.Booking{}, service.ErrStopOutOfOrder
+			}
+			out, at := b, time.Now().UTC()
+			out.Stops = append([]models.Stop(nil), b.Stops...)
+			out.Stops[seq-1].ArrivedAt = &at
+			return out, nil
+		},
+		paymentFn: func(ctx context.Context, id string) (models.Payment, error) {
+			return models.Payment{BookingID: id, Status: models.PaymentStatusAuthorized, Amount: b.Price}, nil
+		},
+	}
+}
+
+func TestBookingLifecycle_Handler(t *testing.T) {
+	var gotFare *money.Money
+	h := NewBookingHandler(lifecycleService(&gotFare))
+	otherRider := auth.Principal{Subject: "r-2", Role: auth.RoleRider, RiderID: "r-2"}
+	otherDriver := auth.Principal{Subject: "d-2", Role: auth.RoleDriver, DriverID: "d-2"}
+
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"rider cancels own", rider, http.MethodPost, "/bookings/b-1/cancel", "", http.StatusOK, ""},
+		{"admin cancels any", admin, http.MethodPost, "/bookings/b-1/cancel", "", http.StatusOK, ""},
+		{"other rider cannot see it", otherRider, http.MethodPost, "/bookings/b-1/cancel", "", http.StatusNotFound, problem.CodeBookingNotFound},
+		{"driver cannot cancel", driver, http.MethodPost, "/bookings/b-1/cancel", "", http.StatusForbidden, problem.CodeForbidden},
+		{"missing booking", admin, http.MethodPost, "/bookings/b-9/cancel", "", http.StatusNotFound, problem.CodeBookingNotFound},
+		{"assigned driver completes", driver, http.MethodPost, "/bookings/b-1/complete", "", http.StatusOK, ""},
+		{"admin completes with fare", admin, http.MethodPost, "/bookings/b-1/complete", `{"fare":{"amount":19900,"currency":"INR"}}`, http.StatusOK, ""},
+		{"other driver cannot complete", otherDriver, http.MethodPost, "/bookings/b-1/complete", "", http.StatusNotFound, problem.CodeBookingNotFound},
+		{"rider cannot complete", rider, http.MethodPost, "/bookings/b-1/complete", "", http.StatusForbidden, problem.CodeForbidden},
+		{"fare needs a currency", driver, http.MethodPost, "/bookings/b-1/complete", `{"fare":199}`, http.StatusBadRequest, problem.CodeValidationFailed},
+		{"assigned driver arrives", driver, http.MethodPost, "/bookings/b-1/arrive", "", http.StatusOK, ""},
+		{"other driver cannot arrive", otherDriver, http.MethodPost, "/bookings/b-1/arrive", "", http.StatusNotFound, problem.CodeBookingNotFound},
+		{"rider cannot arrive", rider, http.MethodPost, "/bookings/b-1/arrive", "", http.StatusForbidden, problem.CodeForbidden},
+		{"assigned driver reports no-show", driver, http.MethodPost, "/bookings/b-1/no-show", "", http.StatusOK, ""},
+		{"admin reports no-show", admin, http.MethodPost, "/bookings/b-1/no-show", "", http.StatusOK, ""},
+		{"other driver cannot report no-show", otherDriver, http.MethodPost, "/bookings/b-1/no-show", "", http.StatusNotFound, problem.CodeBookingNotFound},
+		{"rider cannot report no-show", rider, http.MethodPost, "/bookings/b-1/no-show", "", http.StatusForbidden, problem.CodeForbidden},
+		{"assigned driver reaches a stop", driver, http.MethodPost, "/bookings/b-1/stops/1/arrive", "", http.StatusOK, ""},
+		{"stops are reached in order", driver, http.MethodPost, "/bookings/b-1/stops/2/arrive", "", http.StatusConflict, problem.CodeStopOutOfOrder},
+		{"missing stop", admin, http.MethodPost, "/bookings/b-1/stops/3/arrive", "", http.StatusNotFound, problem.CodeStopNotFound},
+		{"stop must be a number", driver, http.MethodPost, "/bookings/b-1/stops/first/arrive", "", http.StatusBadRequest, problem.CodeValidationFailed},
+		{"other driver cannot reach a stop", otherDriver, http.MethodPost, "/bookings/b-1/stops/1/arrive", "", http.StatusNotFound, problem.CodeBookingNotFound},
+		{"rider cannot reach a stop", rider, http.MethodPost, "/bookings/b-1/stops/1/arrive", "", http.StatusForbidden, problem.CodeForbidden},
+		{"rider reads own payment", rider, http.MethodGet, "/bookings/b-1/payment", "", http.StatusOK, ""},
+		{"other rider cannot read payment", otherRider, http.MethodGet, "/bookings/b-1/payment", "", http.StatusNotFound, problem.CodeBookingNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotFare = nil
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			rr := httptest.NewRecorder()
+			routerAs(c.as, h.RegisterRoutes).ServeHTTP(rr, req)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if c.wantCode != "" {
+				if p := decodeProblem(t, rr); p.Code != c.wantCode {
+					t.Fatalf("unexpected problem: %+v", p)
+				}
+			}
+		})
+	}
+
+	t.Run("fare is passed through", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/bookings/b-1/complete", strings.NewReader(`{"fare":{"amount":19900,"currency":"INR"}}`))
+		rr := httptest.NewRecorder()
+		routerAs(driver, h.RegisterRoutes).ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK || gotFare == nil || *gotFare != (money.Money{Amount: 19900, Currency: "INR"}) {
+			t.Fatalf("got %d, fare %v", rr.Code, gotFare)
+		}
+		var got models.Booking
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.RideStatus != models.RideStatusCompleted || got.Fare == nil || got.Fare.Amount != 19900 {
+			t.Fatalf("unexpected: %+v", got)
+		}
+	})
+}
+
+func TestBookingLifecycle_ServiceErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"not accepted", service.ErrBookingNotAccepted, http.StatusConflict, problem.CodeBookingNotAccepted},
+		{"no-show too early", service.ErrNoShowTooEarly, http.StatusConflict, problem.CodeNoShowTooEarly},
+		{"settled", fmt.Errorf("%w: payment is released", service.ErrPaymentSettled), http.StatusConflict, problem.CodePaymentSettled},
+		{"gateway down", fmt.Errorf("%w: capture: %w", service.ErrPaymentUnavailable, errors.New("timeout")), http.StatusServiceUnavailable, problem.CodePaymentUnavailable},
+		{"fare too high", problem.ValidationError{{Field: "fare", Message: "must not exceed the price held"}}, http.StatusBadRequest, problem.CodeValidationFailed},
 	}
-	if len(got) != 1 || got[0].BookingID != "b-2" {
-		t.Fatalf("unexpected: %+v", got)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotFare *money.Money
+			svc := lifecycleService(&gotFare)
+			svc.completeFn = func(ctx context.Context, id string, fare *money.Money) (models.Booking, error) {
+				return models.Booking{}, c.err
+			}
+			req := httptest.NewRequest(http.MethodPost, "/bookings/b-1/complete", nil)
+			rr := httptest.NewRecorder()
+			routerAs(admin, NewBookingHandler(svc).RegisterRoutes).ServeHTTP(rr, req)
+			if p := decodeProblem(t, rr); rr.Code != c.wantStatus || p.Code != c.wantCode {
+				t.Fatalf("want %d %s, got %d %+v", c.wantStatus, c.wantCode, rr.Code, p)
+			}
+		})
 	}
 }
@@ -0,0 +1,104 @@
+package handlerhttp
+
+import (
+	"net/http"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxStatementSpan bounds one statement request; page longer periods.
+const maxStatementSpan = 366 * 24 * time.Hour
+
+type LedgerHandler struct {
+	svc service.LedgerService
+}
+
+func NewLedgerHandler(svc service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the driver earnings endpoints. Drivers read their
+// own account; admins read anyone's.
+func (h *LedgerHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin))
+		r.Get("/ledger/drivers/{driver_id}/balance", h.driverBalance)
+		r.Get("/ledger/drivers/{driver_id}/statement", h.driverStatement)
+	})
+}
+
+func (h *LedgerHandler) driverBalance(w http.ResponseWriter, r *http.Request) {
+	driverID, ok := ownDriver(w, r)
+	if !ok {
+		return
+	}
+	asOf := time.Now().UTC()
+	if v := r.URL.Query().Get("as_of"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			problem.Write(w, r, problem.Validation(problem.ValidationError{
+				{Field: "as_of", Message: "must be an RFC 3339 timestamp"},
+			}))
+			return
+		}
+		asOf = t
+	}
+
+	bal, err := h.svc.DriverBalance(r.Context(), driverID, asOf)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bal)
+}
+
+func (h *LedgerHandler) driverStatement(w http.ResponseWriter, r *http.Request) {
+	driverID, ok := ownDriver(w, r)
+	if !ok {
+		return
+	}
+	var errs problem.ValidationError
+	from, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("from"))
+	if err != nil {
+		errs = append(errs, problem.FieldError{Field: "from", Message: "must be an RFC 3339 timestamp"})
+	}
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			errs = append(errs, problem.FieldError{Field: "to", Message: "must be an RFC 3339 timestamp"})
+		}
+	}
+	if len(errs) == 0 && !from.Before(to) {
+		errs = append(errs, problem.FieldError{Field: "to", Message: "must be after from"})
+	}
+	if len(errs) == 0 && to.Sub(from) > maxStatementSpan {
+		errs = append(errs, problem.FieldError{Field: "to", Message: "must be at most 366 days after from"})
+	}
+	if len(errs) > 0 {
+		problem.Write(w, r, problem.Validation(errs))
+		return
+	}
+
+	st, err := h.svc.DriverStatement(r.Context(), driverID, from, to)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+// ownDriver returns {driver_id}, writing 403 when a driver asks for someone
+// else's account.
+func ownDriver(w http.ResponseWriter, r *http.Request) (string, bool) {
+	driverID := chi.URLParam(r, "driver_id")
+	if p, _ := auth.FromContext(r.Context()); p.Role == auth.RoleDriver && p.DriverID != driverID {
+		problem.Write(w, r, problem.New(http.StatusForbidden, problem.CodeForbidden, "drivers may only read their own ledger"))
+		return "", false
+	}
+	return driverID, true
+}
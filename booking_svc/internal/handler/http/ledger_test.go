@@ -0,0 +1,81 @@
+package handlerhttp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/ledger"
+	"booking_svc/internal/money"
+	"booking_svc/internal/openapi"
+	"booking_svc/internal/problem"
+)
+
+type fakeLedgerService struct {
+	driverID string
+	asOf     time.Time
+	from, to time.Time
+}
+
+func (f *fakeLedgerService) DriverBalance(_ context.Context, driverID string, asOf time.Time) (ledger.Balance, error) {
+	f.driverID, f.asOf = driverID, asOf
+	return ledger.Balance{Account: ledger.DriverAccount(driverID), AsOf: asOf, Balance: money.Money{Amount: 17640, Currency: "INR"}}, nil
+}
+
+func (f *fakeLedgerService) DriverStatement(_ context.Context, driverID string, from, to time.Time) (ledger.Statement, error) {
+	f.driverID, f.from, f.to = driverID, from, to
+	acct := ledger.Account{Code: ledger.DriverAccount(driverID), Type: ledger.Liability, Currency: "INR"}
+	return ledger.NewStatement(acct, from, to, ledger.Totals{}, []ledger.Line{{
+		EntryID: "e-1", Reference: "trip:b-1", Description: "Trip b-1", OccurredAt: from.Add(time.Hour),
+		Side: ledger.Credit, Amount: money.Money{Amount: 17640, Currency: "INR"},
+	}}), nil
+}
+
+func TestDriverLedger_Handler(t *testing.T) {
+	otherDriver := auth.Principal{Subject: "d-2", Role: auth.RoleDriver, DriverID: "d-2"}
+	validate := openapi.MustLoad().Validator(openapi.ResponsesStrict, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		path       string
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"own balance", driver, "/ledger/drivers/d-1/balance", http.StatusOK, ""},
+		{"balance as of", driver, "/ledger/drivers/d-1/balance?as_of=2026-03-08T00:00:00Z", http.StatusOK, ""},
+		{"admin reads any driver", admin, "/ledger/drivers/d-1/statement?from=2026-03-01T00:00:00Z&to=2026-03-08T00:00:00Z", http.StatusOK, ""},
+		{"own statement to now", driver, "/ledger/drivers/d-1/statement?from=2026-03-01T00:00:00Z", http.StatusOK, ""},
+		{"another driver's balance", otherDriver, "/ledger/drivers/d-1/balance", http.StatusForbidden, problem.CodeForbidden},
+		{"riders may not read earnings", rider, "/ledger/drivers/d-1/balance", http.StatusForbidden, problem.CodeForbidden},
+		{"missing from", driver, "/ledger/drivers/d-1/statement", http.StatusBadRequest, problem.CodeValidationFailed},
+		{"to before from", driver, "/ledger/drivers/d-1/statement?from=2026-03-08T00:00:00Z&to=2026-03-01T00:00:00Z", http.StatusBadRequest, problem.CodeValidationFailed},
+		{"span too long", admin, "/ledger/drivers/d-1/statement?from=2024-01-01T00:00:00Z&to=2026-01-01T00:00:00Z", http.StatusBadRequest, problem.CodeValidationFailed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := &fakeLedgerService{}
+			rr := httptest.NewRecorder()
+			validate(routerAs(c.as, NewLedgerHandler(svc).RegisterRoutes)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, c.path, nil))
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if c.wantCode != "" {
+				var p problem.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %s (%v)", c.wantCode, p.Code, err)
+				}
+				return
+			}
+			if svc.driverID != "d-1" {
+				t.Fatalf("service asked for %q", svc.driverID)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handlerhttp
+
+import (
+	"net/http"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PromotionHandler struct {
+	svc service.PromotionService
+}
+
+func NewPromotionHandler(svc service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the admin-only promotion endpoints. Riders redeem
+// codes through POST /bookings.
+func (h *PromotionHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleAdmin))
+		r.Post("/promotions", h.createPromotion)
+		r.Get("/promotions", h.listPromotions)
+		r.Get("/promotions/{code}", h.getPromotion)
+	})
+}
+
+func (h *PromotionHandler) createPromotion(w http.ResponseWriter, r *http.Request) {
+	var req CreatePromotionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	p, err := h.svc.CreatePromotion(r.Context(), req.Promotion())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (h *PromotionHandler) listPromotions(w http.ResponseWriter, r *http.Request) {
+	items, err := h.svc.ListPromotions(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *PromotionHandler) getPromotion(w http.ResponseWriter, r *http.Request) {
+	p, err := h.svc.GetPromotion(r.Context(), chi.URLParam(r, "code"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
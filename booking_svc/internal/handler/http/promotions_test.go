@@ -0,0 +1,73 @@
+package handlerhttp
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/openapi"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/promo"
+	"booking_svc/internal/repository/memory"
+	"booking_svc/internal/service"
+)
+
+func TestPromotions_Handler(t *testing.T) {
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	validate := openapi.MustLoad().Validator(openapi.ResponsesStrict, discard)
+	svc := service.NewPromotions(memory.NewPromotionRepo(memory.NewBookingRepo()), discard)
+	serve := func(as auth.Principal, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		validate(routerAs(as, NewPromotionHandler(svc).RegisterRoutes)).ServeHTTP(rr, req)
+		return rr
+	}
+
+	percent := `{"code":"launch15","kind":"percent","percent_bps":1500,"max_discount":{"amount":10000,"currency":"INR"},` +
+		`"max_per_rider":1,"ends_at":"2030-01-01T00:00:00Z","area":{"center":{"lat":12.97,"lng":77.59},"radius_km":25}}`
+	rr := serve(admin, http.MethodPost, "/promotions", percent)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rr.Code, rr.Body.String())
+	}
+	var created promo.Promotion
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.Code != "LAUNCH15" || created.StartsAt.IsZero() {
+		t.Fatalf("created: %+v, %v", created, err)
+	}
+
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		method     string
+		path, body string
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"flat", admin, http.MethodPost, "/promotions", `{"code":"FLAT50","kind":"flat","amount_off":{"amount":5000,"currency":"INR"},"max_redemptions":1000}`, http.StatusCreated, ""},
+		{"duplicate code", admin, http.MethodPost, "/promotions", percent, http.StatusConflict, problem.CodePromoExists},
+		{"invalid", admin, http.MethodPost, "/promotions", `{"code":"X","kind":"flat"}`, http.StatusBadRequest, problem.CodeValidationFailed},
+		{"unknown field", admin, http.MethodPost, "/promotions", `{"code":"FLAT","kind":"flat","bogus":1}`, http.StatusBadRequest, problem.CodeValidationFailed},
+		{"list", admin, http.MethodGet, "/promotions", "", http.StatusOK, ""},
+		{"get is case-insensitive", admin, http.MethodGet, "/promotions/Launch15", "", http.StatusOK, ""},
+		{"missing", admin, http.MethodGet, "/promotions/NOPE", "", http.StatusNotFound, problem.CodePromoNotFound},
+		{"riders may not manage promotions", rider, http.MethodGet, "/promotions", "", http.StatusForbidden, problem.CodeForbidden},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rr := serve(c.as, c.method, c.path, c.body)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if c.wantCode != "" {
+				if p := decodeProblem(t, rr); p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %+v", c.wantCode, p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handlerhttp
+
+import (
+	"net/http"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/models"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RatingHandler struct {
+	svc service.RatingService
+}
+
+func NewRatingHandler(svc service.RatingService) *RatingHandler {
+	return &RatingHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the rating endpoints. Only the parties to a trip
+// rate it: the rider rates the driver and the assigned driver the rider.
+func (h *RatingHandler) RegisterRoutes(r chi.Router) {
+	r.With(auth.RequireRole(auth.RoleRider)).Post("/bookings/{booking_id}/rating", h.rateDriver)
+	r.With(auth.RequireRole(auth.RoleDriver)).Post("/bookings/{booking_id}/rider-rating", h.rateRider)
+}
+
+func (h *RatingHandler) rateDriver(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	h.rate(w, r, models.RatingByRider, p.RiderID)
+}
+
+func (h *RatingHandler) rateRider(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	h.rate(w, r, models.RatingByDriver, p.DriverID)
+}
+
+func (h *RatingHandler) rate(w http.ResponseWriter, r *http.Request, rater models.RatingSide, raterID string) {
+	var req RatingRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	in := req.Input()
+	if err := in.Validate(); err != nil {
+		problem.Write(w, r, problem.Validation(err))
+		return
+	}
+	rating, err := h.svc.RateBooking(r.Context(), chi.URLParam(r, "booking_id"), rater, raterID, in)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, rating)
+}
@@ -0,0 +1,123 @@
+package handlerhttp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/bus"
+	"booking_svc/internal/config"
+	"booking_svc/internal/events"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/mq"
+	"booking_svc/internal/openapi"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/repository/memory"
+	"booking_svc/internal/service"
+)
+
+func TestRatings_Handler(t *testing.T) {
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	validate := openapi.MustLoad().Validator(openapi.ResponsesStrict, discard)
+	driverID := "d-1"
+	trips := map[string]models.Booking{
+		"b-done": {BookingID: "b-done", RiderID: "r-1", DriverID: &driverID, RideStatus: models.RideStatusCompleted},
+		"b-open": {BookingID: "b-open", RiderID: "r-1", DriverID: &driverID, RideStatus: models.RideStatusAccepted},
+	}
+	bookings := &fakeBookingService{getFn: func(ctx context.Context, id string) (models.Booking, error) {
+		b, ok := trips[id]
+		if !ok {
+			return models.Booking{}, service.ErrBookingNotFound
+		}
+		b.Price = money.Money{Amount: 22050, Currency: "INR"}
+		return b, nil
+	}}
+	b := bus.NewMemory()
+	t.Cleanup(func() { _ = b.Close() })
+	cfg := config.Config{TopicRatingSubmitted: "rating.submitted"}
+	svc := service.NewRatingService(memory.NewRatingRepo(), bookings, mq.NewProducer(cfg, b, discard), discard)
+	serve := func(as auth.Principal, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		validate(routerAs(as, NewRatingHandler(svc).RegisterRoutes)).ServeHTTP(rr, req)
+		return rr
+	}
+	otherRider := auth.Principal{Subject: "r-2", Role: auth.RoleRider, RiderID: "r-2"}
+	otherDriver := auth.Principal{Subject: "d-2", Role: auth.RoleDriver, DriverID: "d-2"}
+
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		path, body string
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"rider rates driver", rider, "/bookings/b-done/rating", `{"stars":4,"comment":"smooth"}`, http.StatusCreated, ""},
+		{"same rating again", rider, "/bookings/b-done/rating", `{"stars":4,"comment":"smooth"}`, http.StatusCreated, ""},
+		{"different rating", rider, "/bookings/b-done/rating", `{"stars":1}`, http.StatusConflict, problem.CodeRatingExists},
+		{"driver rates rider", driver, "/bookings/b-done/rider-rating", `{"stars":5}`, http.StatusCreated, ""},
+		{"trip not completed", rider, "/bookings/b-open/rating", `{"stars":5}`, http.StatusConflict, problem.CodeBookingNotCompleted},
+		{"other rider", otherRider, "/bookings/b-done/rating", `{"stars":5}`, http.StatusNotFound, problem.CodeBookingNotFound},
+		{"other driver", otherDriver, "/bookings/b-done/rider-rating", `{"stars":5}`, http.StatusNotFound, problem.CodeBookingNotFound},
+		{"missing booking", rider, "/bookings/b-9/rating", `{"stars":5}`, http.StatusNotFound, problem.CodeBookingNotFound},
+		{"stars out of range", rider, "/bookings/b-done/rating", `{"stars":6}`, http.StatusBadRequest, problem.CodeValidationFailed},
+		{"comment too long", rider, "/bookings/b-done/rating", `{"stars":3,"comment":"` + strings.Repeat("x", 501) + `"}`, http.StatusBadRequest, problem.CodeValidationFailed},
+		{"driver uses rider endpoint", driver, "/bookings/b-done/rating", `{"stars":5}`, http.StatusForbidden, problem.CodeForbidden},
+		{"admins do not rate", admin, "/bookings/b-done/rider-rating", `{"stars":5}`, http.StatusForbidden, problem.CodeForbidden},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rr := serve(c.as, c.path, c.body)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if c.wantCode != "" {
+				if p := decodeProblem(t, rr); p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %+v", c.wantCode, p)
+				}
+			}
+		})
+	}
+
+	t.Run("ratings are published", func(t *testing.T) {
+		sub := b.Subscribe("rating.submitted", "test")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		var got []events.RatingSubmitted
+		for range 3 {
+			msg, err := sub.Fetch(ctx)
+			if err != nil {
+				t.Fatalf("fetch after %d events: %v", len(got), err)
+			}
+			var evt events.RatingSubmitted
+			if err := json.Unmarshal(msg.Value, &evt); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, evt)
+		}
+		byRider, byDriver := 0, 0
+		for _, evt := range got {
+			switch {
+			case evt.Rater == "rider" && evt.RaterID == "r-1" && evt.RateeID == "d-1" && evt.Stars == 4:
+				byRider++
+			case evt.Rater == "driver" && evt.RaterID == "d-1" && evt.RateeID == "r-1" && evt.Stars == 5:
+				byDriver++
+			default:
+				t.Fatalf("unexpected event: %+v", evt)
+			}
+		}
+		// The retried rider rating is published again.
+		if byRider != 2 || byDriver != 1 {
+			t.Fatalf("events: %+v", got)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package handlerhttp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultReconcilePage = 1000
+	maxReconcilePage     = 5000
+)
+
+// ReconcileHandler serves the internal read and repair API that the
+// reconcile command uses to compare bookings with driver_svc's jobs.
+type ReconcileHandler struct {
+	svc service.BookingService
+}
+
+func NewReconcileHandler(svc service.BookingService) *ReconcileHandler {
+	return &ReconcileHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the admin-only /internal endpoints.
+func (h *ReconcileHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleAdmin))
+		r.Get("/internal/bookings", h.listBookings)
+		r.Get("/internal/bookings/{booking_id}", h.getBooking)
+		r.Post("/internal/bookings/{booking_id}/republish", h.republishCreated)
+	})
+}
+
+func (h *ReconcileHandler) listBookings(w http.ResponseWriter, r *http.Request) {
+	var errs problem.ValidationError
+	since, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("created_after"))
+	if err != nil {
+		errs = append(errs, problem.FieldError{Field: "created_after", Message: "must be an RFC 3339 timestamp"})
+	}
+	limit := defaultReconcilePage
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxReconcilePage {
+			errs = append(errs, problem.FieldError{Field: "limit", Message: "must be between 1 and 5000"})
+		}
+		limit = n
+	}
+	if len(errs) > 0 {
+		problem.Write(w, r, problem.Validation(errs))
+		return
+	}
+
+	items, err := h.svc.ListBookingsSince(r.Context(), since, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// getBooking lets reconcile look up a job's booking that was created before
+// the window, such as a reservation released inside it.
+func (h *ReconcileHandler) getBooking(w http.ResponseWriter, r *http.Request) {
+	b, err := h.svc.GetBooking(r.Context(), chi.URLParam(r, "booking_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (h *ReconcileHandler) republishCreated(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.RepublishCreated(r.Context(), chi.URLParam(r, "booking_id")); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "republished"})
+}
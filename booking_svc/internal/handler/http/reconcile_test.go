@@ -0,0 +1,128 @@
+package handlerhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestListBookingsSince_Handler(t *testing.T) {
+	var gotSince time.Time
+	var gotLimit int
+	svc := &fakeBookingService{sinceFn: func(_ context.Context, since time.Time, limit int) ([]models.Booking, error) {
+		gotSince, gotLimit = since, limit
+		return []models.Booking{{BookingID: "b-1"}}, nil
+	}}
+	register := func(r chi.Router) { NewReconcileHandler(svc).RegisterRoutes(r) }
+
+	cases := []struct {
+		name       string
+		as         string
+		query      string
+		wantStatus int
+		wantLimit  int
+	}{
+		{"default limit", "admin", "?created_after=2025-01-02T03:04:05.123456Z", http.StatusOK, defaultReconcilePage},
+		{"explicit limit", "admin", "?created_after=2025-01-02T03:04:05Z&limit=10", http.StatusOK, 10},
+		{"missing created_after", "admin", "", http.StatusBadRequest, 0},
+		{"limit too large", "admin", "?created_after=2025-01-02T03:04:05Z&limit=5001", http.StatusBadRequest, 0},
+		{"riders may not reconcile", "rider", "?created_after=2025-01-02T03:04:05Z", http.StatusForbidden, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotLimit = 0
+			p := admin
+			if c.as == "rider" {
+				p = rider
+			}
+			rr := httptest.NewRecorder()
+			routerAs(p, register).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/internal/bookings"+c.query, nil))
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if gotLimit != c.wantLimit {
+				t.Fatalf("limit passed to service: %d, want %d", gotLimit, c.wantLimit)
+			}
+		})
+	}
+	if want := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC); !gotSince.Equal(want) {
+		t.Fatalf("since passed to service: %s", gotSince)
+	}
+}
+
+func TestRepublishCreated_Handler(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"republished", nil, http.StatusOK, ""},
+		{"unknown booking", service.ErrBookingNotFound, http.StatusNotFound, problem.CodeBookingNotFound},
+		{"already accepted", service.ErrBookingNotRequested, http.StatusConflict, problem.CodeBookingNotRequested},
+		{"bus down", service.ErrBookingNotDispatched, http.StatusServiceUnavailable, problem.CodeBookingNotDispatched},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotID string
+			svc := &fakeBookingService{republishFn: func(_ context.Context, id string) error {
+				gotID = id
+				return c.err
+			}}
+			rr := httptest.NewRecorder()
+			routerAs(admin, func(r chi.Router) { NewReconcileHandler(svc).RegisterRoutes(r) }).
+				ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/internal/bookings/b-7/republish", nil))
+			if rr.Code != c.wantStatus || gotID != "b-7" {
+				t.Fatalf("want %d for b-7, got %d for %q, body=%s", c.wantStatus, rr.Code, gotID, rr.Body.String())
+			}
+			if c.wantCode != "" {
+				var p problem.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %+v (%v)", c.wantCode, p, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBookingInternal_Handler(t *testing.T) {
+	svc := &fakeBookingService{getFn: func(_ context.Context, id string) (models.Booking, error) {
+		if id != "b-7" {
+			return models.Booking{}, service.ErrBookingNotFound
+		}
+		return models.Booking{BookingID: id}, nil
+	}}
+	cases := []struct {
+		name       string
+		as         string
+		id         string
+		wantStatus int
+	}{
+		{"found", "admin", "b-7", http.StatusOK},
+		{"unknown booking", "admin", "b-8", http.StatusNotFound},
+		{"riders may not reconcile", "rider", "b-7", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := admin
+			if c.as == "rider" {
+				p = rider
+			}
+			rr := httptest.NewRecorder()
+			routerAs(p, func(r chi.Router) { NewReconcileHandler(svc).RegisterRoutes(r) }).
+				ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/internal/bookings/"+c.id, nil))
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
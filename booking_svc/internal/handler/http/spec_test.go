@@ -0,0 +1,147 @@
+package handlerhttp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/openapi"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// registerAll mirrors the route registration in cmd/booking_svc.
+func registerAll(bookings service.BookingService) func(chi.Router) {
+	return func(r chi.Router) {
+		NewBookingHandler(bookings).RegisterRoutes(r)
+		NewWebhookHandler(nil).RegisterRoutes(r)
+		NewReconcileHandler(bookings).RegisterRoutes(r)
+		NewLedgerHandler(nil).RegisterRoutes(r)
+		NewPromotionHandler(nil).RegisterRoutes(r)
+		NewRatingHandler(nil).RegisterRoutes(r)
+		NewFareHandler(nil).RegisterRoutes(r)
+	}
+}
+
+// TestRoutesMatchOpenAPISpec fails when a route is added to or removed from
+// RegisterRoutes without the same change in internal/openapi/openapi.yaml.
+func TestRoutesMatchOpenAPISpec(t *testing.T) {
+	var registered []string
+	err := chi.Walk(routerAs(admin, registerAll(nil)), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered = append(registered, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var documented []string
+	for path, item := range openapi.MustLoad().Doc().Paths.Map() {
+		for method, op := range item.Operations() {
+			if slices.Contains(op.Tags, openapi.SystemTag) {
+				continue
+			}
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	slices.Sort(registered)
+	slices.Sort(documented)
+	if !slices.Equal(registered, documented) {
+		t.Fatalf("routes drifted from openapi.yaml\nregistered: %v\ndocumented: %v", registered, documented)
+	}
+}
+
+func TestResponsesMatchOpenAPISpec(t *testing.T) {
+	driverID := "d-9"
+	booking := models.Booking{
+		BookingID:  "b-1",
+		RiderID:    "r-1",
+		PickupLoc:  models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:    models.Location{Lat: 12.95, Lng: 77.64},
+		Price:      money.Money{Amount: 22050, Currency: "INR"},
+		RideStatus: models.RideStatusAccepted,
+		DriverID:   &driverID,
+		CreatedAt:  time.Now().UTC(),
+	}
+	svc := &fakeBookingService{
+		createFn: func(ctx context.Context, in service.CreateBookingInput) (models.Booking, error) {
+			return booking, nil
+		},
+		listFn: func(ctx context.Context) ([]models.Booking, error) { return []models.Booking{booking}, nil },
+		listRiderFn: func(ctx context.Context, riderID string) ([]models.Booking, error) {
+			return []models.Booking{}, nil
+		},
+	}
+	validate := openapi.MustLoad().Validator(openapi.ResponsesStrict, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/bookings", `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":{"amount":22050,"currency":"INR"}}`, http.StatusCreated},
+		{"create with legacy price", http.MethodPost, "/bookings", `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220}`, http.StatusCreated},
+		{"create rejected by spec: bad currency", http.MethodPost, "/bookings", `{"pickuploc":{"lat":12.9,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":{"amount":22050,"currency":"rupees"}}`, http.StatusBadRequest},
+		{"create rejected by spec", http.MethodPost, "/bookings", `{"pickuploc":{"lat":120,"lng":77.6},"dropoff":{"lat":12.95,"lng":77.64},"price":220}`, http.StatusBadRequest},
+		{"handler validation error", http.MethodPost, "/bookings", `{"pickuploc":{"lat":1,"lng":1},"dropoff":{"lat":1,"lng":1},"price":220}`, http.StatusBadRequest},
+		{"list own", http.MethodGet, "/bookings", "", http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := validate(routerAs(rider, registerAll(svc)))
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	var gotFare *money.Money
+	lifecycle := lifecycleService(&gotFare)
+	for _, c := range []struct {
+		name, method, path, body string
+	}{
+		{"cancel", http.MethodPost, "/bookings/b-1/cancel", ""},
+		{"complete", http.MethodPost, "/bookings/b-1/complete", ""},
+		{"complete with fare", http.MethodPost, "/bookings/b-1/complete", `{"fare":{"amount":19900,"currency":"INR"}}`},
+		{"stop arrival", http.MethodPost, "/bookings/b-1/stops/1/arrive", ""},
+		{"payment", http.MethodGet, "/bookings/b-1/payment", ""},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			validate(routerAs(admin, registerAll(lifecycle))).ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("want 200, got %d, body=%s", rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("admin list", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		validate(routerAs(admin, registerAll(svc))).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/bookings", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("want 200, got %d, body=%s", rr.Code, rr.Body.String())
+		}
+	})
+}
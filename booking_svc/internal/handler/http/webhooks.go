@@ -0,0 +1,112 @@
+package handlerhttp
+
+import (
+	"net/http"
+	"strconv"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/models"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const defaultDeliveryLogLimit = 50
+
+type WebhookHandler struct {
+	svc service.WebhookService
+}
+
+func NewWebhookHandler(svc service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the admin-only subscription endpoints.
+func (h *WebhookHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleAdmin))
+		r.Post("/webhooks", h.createSubscription)
+		r.Get("/webhooks", h.listSubscriptions)
+		r.Get("/webhooks/{id}", h.getSubscription)
+		r.Delete("/webhooks/{id}", h.deleteSubscription)
+		r.Get("/webhooks/{id}/deliveries", h.listDeliveries)
+	})
+}
+
+// createdSubscription echoes the secret once so the caller can confirm what was stored.
+type createdSubscription struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+func (h *WebhookHandler) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		problem.Write(w, r, problem.Validation(err))
+		return
+	}
+
+	sub, err := h.svc.CreateSubscription(r.Context(), service.CreateSubscriptionInput{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, createdSubscription{WebhookSubscription: sub, Secret: sub.Secret})
+}
+
+func (h *WebhookHandler) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	items, err := h.svc.ListSubscriptions(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *WebhookHandler) getSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.svc.GetSubscription(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	err := h.svc.DeleteSubscription(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDeliveryLogLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			problem.Write(w, r, problem.Validation(problem.ValidationError{
+				{Field: "limit", Message: "must be between 1 and 500"},
+			}))
+			return
+		}
+		limit = n
+	}
+
+	items, err := h.svc.ListDeliveries(r.Context(), chi.URLParam(r, "id"), limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
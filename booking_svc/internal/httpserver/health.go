@@ -0,0 +1,103 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// CheckFunc reports whether one dependency is usable. A nil error means ready.
+type CheckFunc func(ctx context.Context) error
+
+type namedCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+type checkResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type readinessReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// readiness aggregates dependency checks and the draining flag behind /readyz.
+type readiness struct {
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+func (rd *readiness) add(name string, fn CheckFunc) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	rd.checks = append(rd.checks, namedCheck{name: name, fn: fn})
+}
+
+func (rd *readiness) run(ctx context.Context) readinessReport {
+	rd.mu.RLock()
+	checks := append([]namedCheck(nil), rd.checks...)
+	rd.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	results := make([]checkResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			res := checkResult{Status: "ok"}
+			if err := c.fn(ctx); err != nil {
+				res.Status = "fail"
+				res.Error = err.Error()
+			}
+			res.DurationMS = time.Since(start).Milliseconds()
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	report := readinessReport{Status: "ok", Checks: make(map[string]checkResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != "ok" {
+			report.Status = "fail"
+		}
+	}
+	if rd.draining.Load() {
+		report.Status = "draining"
+	}
+	return report
+}
+
+func (rd *readiness) handleReady(w http.ResponseWriter, r *http.Request) {
+	report := rd.run(r.Context())
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func handleLive(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
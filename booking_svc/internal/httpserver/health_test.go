@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking_svc/internal/auth"
+	"booking_svc/internal/config"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	authn, err := auth.NewAuthenticator(auth.Config{HS256Secret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(config.Config{HTTPPort: "0"}, slog.New(slog.NewTextHandler(io.Discard, nil)), authn, nil)
+}
+
+func getReady(t *testing.T, s *Server) (int, readinessReport) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var rep readinessReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("json: %v body=%s", err, rr.Body.String())
+	}
+	return rr.Code, rep
+}
+
+func TestReadyz(t *testing.T) {
+	s := newTestServer(t)
+	s.AddReadinessCheck("postgres", func(context.Context) error { return nil })
+
+	code, rep := getReady(t, s)
+	if code != http.StatusOK || rep.Status != "ok" || rep.Checks["postgres"].Status != "ok" {
+		t.Fatalf("want ready, got %d %+v", code, rep)
+	}
+
+	s.AddReadinessCheck("kafka", func(context.Context) error { return errors.New("no brokers") })
+	code, rep = getReady(t, s)
+	if code != http.StatusServiceUnavailable || rep.Status != "fail" {
+		t.Fatalf("want 503 fail, got %d %+v", code, rep)
+	}
+	if k := rep.Checks["kafka"]; k.Status != "fail" || k.Error != "no brokers" {
+		t.Fatalf("unexpected kafka check: %+v", k)
+	}
+	if rep.Checks["postgres"].Status != "ok" {
+		t.Fatalf("healthy dependency reported failing: %+v", rep.Checks["postgres"])
+	}
+}
+
+func TestReadyz_FailsOnceShutdownStarts(t *testing.T) {
+	s := newTestServer(t)
+	s.AddReadinessCheck("postgres", func(context.Context) error { return nil })
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	code, rep := getReady(t, s)
+	if code != http.StatusServiceUnavailable || rep.Status != "draining" {
+		t.Fatalf("want 503 draining, got %d %+v", code, rep)
+	}
+
+	// liveness is unaffected by draining
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("livez: want 200, got %d", rr.Code)
+	}
+}
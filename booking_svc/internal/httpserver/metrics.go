@@ -0,0 +1,37 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"booking_svc/internal/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestMetrics records request latency labelled by the matched chi route pattern,
+// so /bookings/{id} stays one series regardless of the id.
+func RequestMetrics() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+
+			next.ServeHTTP(ww, r)
+
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			metrics.HTTPRequestDuration.
+				WithLabelValues(r.Method, route, strconv.Itoa(status)).
+				Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking_svc/internal/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestRequestMetrics_LabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(RequestMetrics())
+	r.Get("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	before := testutil.CollectAndCount(metrics.HTTPRequestDuration)
+	for _, path := range []string{"/things/1", "/things/2", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// two paths share one {id} series; the 404 gets an "unmatched" series
+	if got := testutil.CollectAndCount(metrics.HTTPRequestDuration) - before; got != 2 {
+		t.Fatalf("want 2 new series, got %d", got)
+	}
+	h, err := metrics.HTTPRequestDuration.GetMetricWithLabelValues(http.MethodGet, "/things/{id}", "418")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m dto.Metric
+	if err := h.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetHistogram().GetSampleCount(); got != 2 {
+		t.Fatalf("want 2 observations on the route pattern series, got %d", got)
+	}
+}
@@ -0,0 +1,246 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking_svc/internal/auth"
+)
+
+func TestRateLimiter_PerPrincipalAndClass(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	l := NewRateLimiter(map[RouteClass]Limit{
+		ClassRead:  {RPS: 1, Burst: 2},
+		ClassWrite: {RPS: 0.5, Burst: 1},
+	})
+	l.now = func() time.Time { return clock }
+	h := l.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func(method string, p *auth.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/x", nil)
+		if p != nil {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), *p))
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+	alice := &auth.Principal{Subject: "alice", Role: auth.RoleRider}
+	bob := &auth.Principal{Subject: "bob", Role: auth.RoleRider}
+
+	for i := 0; i < 2; i++ {
+		if rr := do(http.MethodGet, alice); rr.Code != http.StatusOK {
+			t.Fatalf("read %d: want 200, got %d", i, rr.Code)
+		}
+	}
+	rr := do(http.MethodGet, alice)
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("want 429 after burst, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("Retry-After: want 1, got %q", got)
+	}
+
+	// separate budgets for another principal and for the write class
+	if rr := do(http.MethodGet, bob); rr.Code != http.StatusOK {
+		t.Fatalf("bob: want 200, got %d", rr.Code)
+	}
+	if rr := do(http.MethodPost, alice); rr.Code != http.StatusOK {
+		t.Fatalf("write: want 200, got %d", rr.Code)
+	}
+	rr = do(http.MethodPost, alice)
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "2" {
+		t.Fatalf("write: want 429 Retry-After 2, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+
+	// rejected requests do not consume tokens, so the bucket refills on schedule
+	clock = clock.Add(time.Second)
+	if rr := do(http.MethodGet, alice); rr.Code != http.StatusOK {
+		t.Fatalf("after refill: want 200, got %d", rr.Code)
+	}
+}
+
+func TestRateLimiter_FallsBackToIP(t *testing.T) {
+	l := NewRateLimiter(map[RouteClass]Limit{ClassRead: {RPS: 1, Burst: 1}})
+	h := l.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := map[string][]int{}
+	for _, addr := range []string{"10.0.0.1:1111", "10.0.0.1:2222", "10.0.0.2:1111"} {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		codes[addr] = append(codes[addr], rr.Code)
+	}
+	if codes["10.0.0.1:2222"][0] != http.StatusTooManyRequests {
+		t.Fatalf("same IP, different port should share a bucket: %v", codes)
+	}
+	if codes["10.0.0.2:1111"][0] != http.StatusOK {
+		t.Fatalf("different IP should have its own bucket: %v", codes)
+	}
+}
+
+func TestRateLimiter_UnauthenticatedByIP(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	l := NewRateLimiter(map[RouteClass]Limit{ClassUnauthenticated: {RPS: 1, Burst: 2}})
+	l.now = func() time.Time { return clock }
+	h := l.Unauthenticated(fakeAuth(nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(addr, tok string) int {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.RemoteAddr = addr
+		req.Header.Set("Authorization", "Bearer "+tok)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Valid tokens cost nothing, however many share an IP.
+	for i := 0; i < 5; i++ {
+		if code := do("10.0.0.3:1111", "good"); code != http.StatusOK {
+			t.Fatalf("valid request %d: want 200, got %d", i, code)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if code := do("10.0.0.1:1111", "forged"); code != http.StatusUnauthorized {
+			t.Fatalf("bad token %d: want 401, got %d", i, code)
+		}
+	}
+	// The budget is spent: the IP is turned away before its token is checked.
+	if code := do("10.0.0.1:2222", "forged"); code != http.StatusTooManyRequests {
+		t.Fatalf("bad token over budget: want 429, got %d", code)
+	}
+	if code := do("10.0.0.1:2222", "good"); code != http.StatusTooManyRequests {
+		t.Fatalf("same IP over budget: want 429, got %d", code)
+	}
+	if code := do("10.0.0.2:1111", "forged"); code != http.StatusUnauthorized {
+		t.Fatalf("another IP: want 401, got %d", code)
+	}
+	clock = clock.Add(time.Second)
+	if code := do("10.0.0.1:1111", "good"); code != http.StatusOK {
+		t.Fatalf("after refill: want 200, got %d", code)
+	}
+}
+
+func TestRateLimiter_UnauthenticatedConcurrentBurst(t *testing.T) {
+	l := NewRateLimiter(map[RouteClass]Limit{ClassUnauthenticated: {RPS: 0.001, Burst: 2}})
+	verifying := make(chan struct{})
+	h := l.Unauthenticated(fakeAuth(verifying))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// All ten bad tokens arrive before any has failed verification.
+	codes := make(chan int)
+	for i := 0; i < 10; i++ {
+		go func() {
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.RemoteAddr = "10.0.0.1:1111"
+			req.Header.Set("Authorization", "Bearer forged")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			codes <- rr.Code
+		}()
+	}
+	count := map[int]int{}
+	for i := 0; i < 8; i++ {
+		count[<-codes]++
+	}
+	close(verifying)
+	for i := 0; i < 2; i++ {
+		count[<-codes]++
+	}
+	if count[http.StatusTooManyRequests] != 8 || count[http.StatusUnauthorized] != 2 {
+		t.Fatalf("want 2 verified and 8 turned away, got %v", count)
+	}
+}
+
+// fakeAuth stands in for auth.Middleware: only "Bearer good" gets through.
+// Bad tokens wait for verifying to close, when it is set.
+func fakeAuth(verifying <-chan struct{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer good" {
+				if verifying != nil {
+					<-verifying
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRateLimiter_KeysIPv6ByPrefix(t *testing.T) {
+	l := NewRateLimiter(map[RouteClass]Limit{ClassRead: {RPS: 1, Burst: 1}})
+	h := l.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var codes []int
+	for _, addr := range []string{"[2001:db8:1:2::1]:1111", "[2001:db8:1:2::ffff]:1111", "[2001:db8:1:3::1]:1111"} {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		codes = append(codes, rr.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests || codes[2] != http.StatusOK {
+		t.Fatalf("want one bucket per /64, got %v", codes)
+	}
+}
+
+func TestRateLimiter_EvictsRefilledBuckets(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	l := NewRateLimiter(map[RouteClass]Limit{ClassRead: {RPS: 1, Burst: 120}})
+	l.now = func() time.Time { return clock }
+	l.reserve("ip:10.0.0.1", ClassRead)
+	clock = clock.Add(90 * time.Second)
+	l.reserve("ip:10.0.0.2", ClassRead)
+	if len(l.buckets) != 2 {
+		t.Fatalf("a bucket still refilling must be kept, have %d", len(l.buckets))
+	}
+	clock = clock.Add(90 * time.Second)
+	l.reserve("ip:10.0.0.3", ClassRead)
+	if _, ok := l.buckets[string(ClassRead)+"|ip:10.0.0.1"]; ok || len(l.buckets) != 2 {
+		t.Fatalf("a refilled bucket should be dropped, have %v", l.buckets)
+	}
+}
+
+func TestShedder(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	s := NewShedder(1, 100*time.Millisecond)
+	h := s.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		close(done)
+	}()
+	<-started
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("in-flight: want 503 with Retry-After, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	close(release)
+	<-done
+
+	wait := 50 * time.Millisecond
+	s.poolWait = func() time.Duration { return wait }
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("under thresholds: want 200, got %d", rr.Code)
+	}
+
+	wait = time.Second
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("pool wait: want 503, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,313 @@
+// Package ledger is a double-entry ledger of the money a trip moves: what
+// riders are charged, what drivers earn and what the platform keeps. Every
+// journal entry's debits equal its credits, so balances can be derived from
+// postings alone and always add up across accounts.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"booking_svc/internal/money"
+)
+
+// AccountType decides an account's normal side: the side that increases it.
+type AccountType string
+
+const (
+	// Asset: money owed to the platform, such as a rider's charges.
+	Asset AccountType = "asset"
+	// Liability: money the platform owes, such as a driver's earnings.
+	Liability AccountType = "liability"
+	// Revenue: money the platform has earned, such as commission.
+	Revenue AccountType = "revenue"
+	// Expense: money the platform has spent, such as promotional discounts.
+	Expense AccountType = "expense"
+)
+
+func (t AccountType) valid() bool {
+	return t == Asset || t == Liability || t == Revenue || t == Expense
+}
+
+// Normal is the side that increases an account of type t.
+func (t AccountType) Normal() Side {
+	if t == Asset || t == Expense {
+		return Debit
+	}
+	return Credit
+}
+
+type Side string
+
+const (
+	Debit  Side = "debit"
+	Credit Side = "credit"
+)
+
+const (
+	// PlatformCommission collects the platform's share of every fare.
+	PlatformCommission = "platform:commission"
+	// PlatformPromotions collects the discounts the platform paid for.
+	PlatformPromotions = "platform:promotions"
+	// PlatformCancellationFees collects the fees riders pay for late
+	// cancellations and no-shows.
+	PlatformCancellationFees = "platform:cancellation_fees"
+	// PlatformCash is the platform's bank account, which driver payouts are
+	// paid from.
+	PlatformCash = "platform:cash"
+)
+
+// RiderAccount is what riderID has been charged for trips.
+func RiderAccount(riderID string) string { return "rider:" + riderID }
+
+// DriverAccount is what the platform owes driverID: their trip earnings less
+// the payouts they were paid.
+func DriverAccount(driverID string) string { return "driver:" + driverID }
+
+var (
+	// ErrUnbalanced means an entry's debits and credits differ.
+	ErrUnbalanced = errors.New("ledger: entry does not balance")
+	// ErrInvalidEntry means an entry is malformed apart from balancing.
+	ErrInvalidEntry = errors.New("ledger: invalid entry")
+	// ErrAccountMismatch means a posting names an existing account with a
+	// different type or currency.
+	ErrAccountMismatch = errors.New("ledger: posting does not match its account")
+)
+
+// Account is opened by the first posting to its code and keeps that
+// posting's type and currency.
+type Account struct {
+	Code      string      `json:"code"`
+	Type      AccountType `json:"type"`
+	Currency  string      `json:"currency"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Posting moves Amount, always positive, to one side of an account.
+type Posting struct {
+	Account     string      `json:"account"`
+	AccountType AccountType `json:"account_type"`
+	Side        Side        `json:"side"`
+	Amount      money.Money `json:"amount"`
+}
+
+// Entry is one journal entry. Entries are never changed once posted.
+type Entry struct {
+	ID string `json:"id"`
+	// Reference names the business event behind the entry; at most one entry
+	// is posted per reference, so posting is safe to retry.
+	Reference   string    `json:"reference"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	Postings    []Posting `json:"postings"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Validate checks that e has a reference and at least two postings of
+// positive amounts in one currency, and that its debits equal its credits.
+func (e Entry) Validate() error {
+	if e.Reference == "" {
+		return fmt.Errorf("%w: reference is required", ErrInvalidEntry)
+	}
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: %s has %d postings, need at least 2", ErrInvalidEntry, e.Reference, len(e.Postings))
+	}
+	currency := e.Postings[0].Amount.Currency
+	var debits, credits int64
+	for _, p := range e.Postings {
+		switch {
+		case p.Account == "":
+			return fmt.Errorf("%w: %s: posting without an account", ErrInvalidEntry, e.Reference)
+		case !p.AccountType.valid():
+			return fmt.Errorf("%w: %s: account %s has type %q", ErrInvalidEntry, e.Reference, p.Account, p.AccountType)
+		case !p.Amount.IsPositive():
+			return fmt.Errorf("%w: %s: posting to %s must be positive, got %s", ErrInvalidEntry, e.Reference, p.Account, p.Amount)
+		case p.Amount.Currency != currency:
+			return fmt.Errorf("%w: %s mixes %s and %s", ErrInvalidEntry, e.Reference, currency, p.Amount.Currency)
+		}
+		if err := p.Amount.Validate(); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidEntry, e.Reference, err)
+		}
+		switch p.Side {
+		case Debit:
+			debits += p.Amount.Amount
+		case Credit:
+			credits += p.Amount.Amount
+		default:
+			return fmt.Errorf("%w: %s: posting to %s has side %q", ErrInvalidEntry, e.Reference, p.Account, p.Side)
+		}
+	}
+	if debits != credits {
+		return fmt.Errorf("%w: %s debits %d, credits %d", ErrUnbalanced, e.Reference, debits, credits)
+	}
+	return nil
+}
+
+// Rate is a commission rate in basis points: 2000 is 20%.
+type Rate int64
+
+// Validate checks that r is between 0% and 100%.
+func (r Rate) Validate() error {
+	if r < 0 || r > 10000 {
+		return fmt.Errorf("commission rate %d bps must be between 0 and 10000", r)
+	}
+	return nil
+}
+
+// Split divides fare into the platform's commission, r of the fare rounded
+// half away from zero, and the driver's earnings, the rest.
+func (r Rate) Split(fare money.Money) (commission, earnings money.Money, err error) {
+	if err := r.Validate(); err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	if commission, err = fare.Mul(int64(r), 10000); err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	if earnings, err = fare.Sub(commission); err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	return commission, earnings, nil
+}
+
+// TripReference is the reference of a trip's entry.
+func TripReference(bookingID string) string { return "trip:" + bookingID }
+
+// Trip is the entry for a completed trip: rate splits the fare between the
+// platform's commission and the driver's earnings. The rider is debited the fare less
+// discount, and the platform's promotions expense the discount, so a
+// promotion never reduces what the driver earns. A zero discount is none.
+func Trip(bookingID, riderID, driverID string, fare, discount money.Money, rate Rate, at time.Time) (Entry, error) {
+	commission, earnings, err := rate.Split(fare)
+	if err != nil {
+		return Entry{}, err
+	}
+	charged := fare
+	if !discount.IsZero() {
+		if charged, err = fare.Sub(discount); err != nil {
+			return Entry{}, err
+		}
+	}
+	e := Entry{
+		Reference:   TripReference(bookingID),
+		Description: "Trip " + bookingID,
+		OccurredAt:  at,
+		Postings:    []Posting{{Account: RiderAccount(riderID), AccountType: Asset, Side: Debit, Amount: charged}},
+	}
+	if !discount.IsZero() {
+		e.Postings = append(e.Postings, Posting{Account: PlatformPromotions, AccountType: Expense, Side: Debit, Amount: discount})
+	}
+	// A 0% or 100% rate leaves one side nothing; empty postings are omitted.
+	if earnings.IsPositive() {
+		e.Postings = append(e.Postings, Posting{Account: DriverAccount(driverID), AccountType: Liability, Side: Credit, Amount: earnings})
+	}
+	if commission.IsPositive() {
+		e.Postings = append(e.Postings, Posting{Account: PlatformCommission, AccountType: Revenue, Side: Credit, Amount: commission})
+	}
+	return e, e.Validate()
+}
+
+// CancellationReference is the reference of a cancellation fee's entry.
+func CancellationReference(bookingID string) string { return "cancellation:" + bookingID }
+
+// CancellationFee is the entry for the fee a rider was charged for cancelling
+// bookingID: the rider is debited and the platform keeps it as revenue.
+func CancellationFee(bookingID, riderID string, fee money.Money, at time.Time) (Entry, error) {
+	e := Entry{
+		Reference:   CancellationReference(bookingID),
+		Description: "Cancellation fee " + bookingID,
+		OccurredAt:  at,
+		Postings: []Posting{
+			{Account: RiderAccount(riderID), AccountType: Asset, Side: Debit, Amount: fee},
+			{Account: PlatformCancellationFees, AccountType: Revenue, Side: Credit, Amount: fee},
+		},
+	}
+	return e, e.Validate()
+}
+
+// PayoutReference is the reference of a driver payout's entry.
+func PayoutReference(payoutID string) string { return "payout:" + payoutID }
+
+// Payout is the entry for amount paid to driverID by payoutID: the driver's
+// account is debited, settling what the platform owed them, and the
+// platform's cash is credited.
+func Payout(payoutID, driverID string, amount money.Money, at time.Time) (Entry, error) {
+	e := Entry{
+		Reference:   PayoutReference(payoutID),
+		Description: "Payout " + payoutID,
+		OccurredAt:  at,
+		Postings: []Posting{
+			{Account: DriverAccount(driverID), AccountType: Liability, Side: Debit, Amount: amount},
+			{Account: PlatformCash, AccountType: Asset, Side: Credit, Amount: amount},
+		},
+	}
+	return e, e.Validate()
+}
+
+// Totals are the debits and credits posted to one account.
+type Totals struct {
+	Debits, Credits int64
+}
+
+// Balance is t's net amount on the normal side of an account of type typ.
+func (t Totals) Balance(typ AccountType) int64 {
+	if typ.Normal() == Debit {
+		return t.Debits - t.Credits
+	}
+	return t.Credits - t.Debits
+}
+
+// Line is one posting to an account, with its entry's details.
+type Line struct {
+	EntryID     string      `json:"entry_id"`
+	Reference   string      `json:"reference"`
+	Description string      `json:"description"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+	Side        Side        `json:"side"`
+	Amount      money.Money `json:"amount"`
+	// Balance is the account's balance after this line.
+	Balance money.Money `json:"balance"`
+}
+
+// Balance is an account's balance at a point in time.
+type Balance struct {
+	Account string      `json:"account"`
+	AsOf    time.Time   `json:"as_of"`
+	Balance money.Money `json:"balance"`
+}
+
+// Statement lists an account's postings over [From, To) between its opening
+// and closing balances.
+type Statement struct {
+	Account string      `json:"account"`
+	From    time.Time   `json:"from"`
+	To      time.Time   `json:"to"`
+	Opening money.Money `json:"opening_balance"`
+	Closing money.Money `json:"closing_balance"`
+	Lines   []Line      `json:"lines"`
+}
+
+// NewStatement builds acct's statement from the totals posted before from and
+// the lines in [from, to), oldest first, filling in each line's running
+// balance.
+func NewStatement(acct Account, from, to time.Time, opening Totals, lines []Line) Statement {
+	bal := opening.Balance(acct.Type)
+	s := Statement{
+		Account: acct.Code,
+		From:    from,
+		To:      to,
+		Opening: money.Money{Amount: bal, Currency: acct.Currency},
+		Lines:   make([]Line, len(lines)),
+	}
+	for i, l := range lines {
+		if l.Side == acct.Type.Normal() {
+			bal += l.Amount.Amount
+		} else {
+			bal -= l.Amount.Amount
+		}
+		l.Balance = money.Money{Amount: bal, Currency: acct.Currency}
+		s.Lines[i] = l
+	}
+	s.Closing = money.Money{Amount: bal, Currency: acct.Currency}
+	return s
+}
@@ -0,0 +1,162 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/money"
+)
+
+func inr(amount int64) money.Money { return money.Money{Amount: amount, Currency: "INR"} }
+
+func TestTripSplitsFareAndBalances(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name                 string
+		fare                 int64
+		rate                 Rate
+		earnings, commission int64
+	}{
+		{"20%", 22050, 2000, 17640, 4410},
+		{"rounds commission half away from zero", 25, 1000, 22, 3}, // 2.5 -> 3
+		{"no commission", 22050, 0, 22050, 0},
+		{"all commission", 22050, 10000, 0, 22050},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, err := Trip("b-1", "r-1", "d-1", inr(c.fare), money.Money{}, c.rate, at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Reference != "trip:b-1" || !e.OccurredAt.Equal(at) {
+				t.Fatalf("entry: %+v", e)
+			}
+			got := map[string]Posting{}
+			for _, p := range e.Postings {
+				got[p.Account] = p
+			}
+			if p := got["rider:r-1"]; p.Side != Debit || p.Amount != inr(c.fare) || p.AccountType != Asset {
+				t.Fatalf("rider posting: %+v", p)
+			}
+			if p, ok := got["driver:d-1"]; ok != (c.earnings > 0) || p.Amount.Amount != c.earnings || (ok && p.Side != Credit) {
+				t.Fatalf("driver posting: %+v", p)
+			}
+			if p, ok := got[PlatformCommission]; ok != (c.commission > 0) || p.Amount.Amount != c.commission || (ok && p.Side != Credit) {
+				t.Fatalf("commission posting: %+v", p)
+			}
+		})
+	}
+	if _, err := Trip("b-1", "r-1", "d-1", inr(100), money.Money{}, 10001, at); err == nil {
+		t.Fatal("a rate above 100% must be rejected")
+	}
+}
+
+func TestTripChargesPromotionsForDiscount(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	e, err := Trip("b-1", "r-1", "d-1", inr(20000), inr(5000), 2000, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Posting{
+		"rider:r-1":        {Account: "rider:r-1", AccountType: Asset, Side: Debit, Amount: inr(15000)},
+		PlatformPromotions: {Account: PlatformPromotions, AccountType: Expense, Side: Debit, Amount: inr(5000)},
+		"driver:d-1":       {Account: "driver:d-1", AccountType: Liability, Side: Credit, Amount: inr(16000)},
+		PlatformCommission: {Account: PlatformCommission, AccountType: Revenue, Side: Credit, Amount: inr(4000)},
+	}
+	if len(e.Postings) != len(want) {
+		t.Fatalf("postings: %+v", e.Postings)
+	}
+	for _, p := range e.Postings {
+		if p != want[p.Account] {
+			t.Fatalf("posting %s = %+v, want %+v", p.Account, p, want[p.Account])
+		}
+	}
+	if _, err := Trip("b-1", "r-1", "d-1", inr(5000), inr(5000), 2000, at); !errors.Is(err, ErrInvalidEntry) {
+		t.Fatalf("a discount of the whole fare must be rejected: %v", err)
+	}
+}
+
+func TestCancellationFeeIsPlatformRevenue(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	e, err := CancellationFee("b-1", "r-1", inr(2205), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Reference != "cancellation:b-1" || len(e.Postings) != 2 ||
+		e.Postings[0] != (Posting{Account: "rider:r-1", AccountType: Asset, Side: Debit, Amount: inr(2205)}) ||
+		e.Postings[1] != (Posting{Account: PlatformCancellationFees, AccountType: Revenue, Side: Credit, Amount: inr(2205)}) {
+		t.Fatalf("entry: %+v", e)
+	}
+	if _, err := CancellationFee("b-1", "r-1", inr(0), at); !errors.Is(err, ErrInvalidEntry) {
+		t.Fatalf("a zero fee must not be posted, got %v", err)
+	}
+}
+
+func TestPayoutSettlesTheDriverAccount(t *testing.T) {
+	at := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	e, err := Payout("p-1", "d-1", inr(17640), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Reference != "payout:p-1" || len(e.Postings) != 2 ||
+		e.Postings[0] != (Posting{Account: "driver:d-1", AccountType: Liability, Side: Debit, Amount: inr(17640)}) ||
+		e.Postings[1] != (Posting{Account: PlatformCash, AccountType: Asset, Side: Credit, Amount: inr(17640)}) {
+		t.Fatalf("entry: %+v", e)
+	}
+	if _, err := Payout("p-1", "d-1", inr(0), at); !errors.Is(err, ErrInvalidEntry) {
+		t.Fatalf("a zero payout must not be posted, got %v", err)
+	}
+}
+
+func TestValidateRejectsBadEntries(t *testing.T) {
+	debit := Posting{Account: "rider:r-1", AccountType: Asset, Side: Debit, Amount: inr(100)}
+	credit := Posting{Account: "driver:d-1", AccountType: Liability, Side: Credit, Amount: inr(100)}
+	short := credit
+	short.Amount = inr(99)
+	usd := credit
+	usd.Amount = money.Money{Amount: 100, Currency: "USD"}
+	zero := credit
+	zero.Amount = inr(0)
+	sideless := credit
+	sideless.Side = ""
+
+	cases := []struct {
+		name string
+		e    Entry
+		want error
+	}{
+		{"balanced", Entry{Reference: "x", Postings: []Posting{debit, credit}}, nil},
+		{"unbalanced", Entry{Reference: "x", Postings: []Posting{debit, short}}, ErrUnbalanced},
+		{"one posting", Entry{Reference: "x", Postings: []Posting{debit}}, ErrInvalidEntry},
+		{"no reference", Entry{Postings: []Posting{debit, credit}}, ErrInvalidEntry},
+		{"mixed currencies", Entry{Reference: "x", Postings: []Posting{debit, usd}}, ErrInvalidEntry},
+		{"zero amount", Entry{Reference: "x", Postings: []Posting{debit, credit, zero}}, ErrInvalidEntry},
+		{"no side", Entry{Reference: "x", Postings: []Posting{debit, sideless}}, ErrInvalidEntry},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.e.Validate(); !errors.Is(err, c.want) || (c.want == nil) != (err == nil) {
+				t.Fatalf("want %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestStatementRunsBalanceOnNormalSide(t *testing.T) {
+	acct := Account{Code: "driver:d-1", Type: Liability, Currency: "INR"}
+	from, to := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	s := NewStatement(acct, from, to, Totals{Debits: 1000, Credits: 5000}, []Line{
+		{EntryID: "e-1", Side: Credit, Amount: inr(1764)},
+		{EntryID: "e-2", Side: Debit, Amount: inr(4000)},
+	})
+	if s.Opening != inr(4000) || s.Closing != inr(1764) {
+		t.Fatalf("opening %s, closing %s", s.Opening, s.Closing)
+	}
+	if s.Lines[0].Balance != inr(5764) || s.Lines[1].Balance != inr(1764) {
+		t.Fatalf("running balances: %+v", s.Lines)
+	}
+	if got := (Totals{Debits: 300, Credits: 100}).Balance(Asset); got != 200 {
+		t.Fatalf("asset balance %d", got)
+	}
+}
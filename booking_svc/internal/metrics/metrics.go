@@ -0,0 +1,126 @@
+// Package metrics holds the Prometheus collectors exported on /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// factory registers every collector with a constant service label, so both
+// services can share one registry when they run in the same process.
+var factory = promauto.With(prometheus.WrapRegistererWith(
+	prometheus.Labels{"service": "booking_svc"}, prometheus.DefaultRegisterer))
+
+var (
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, chi route pattern and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "API requests currently being served.",
+	})
+
+	HTTPRateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requests rejected with 429 by the per-client rate limiter, by route class.",
+	}, []string{"class"})
+
+	HTTPShed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_shed_total",
+		Help: "Requests rejected with 503 by load shedding, by reason (in_flight, pool_wait).",
+	}, []string{"reason"})
+
+	KafkaProduceDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_produce_duration_seconds",
+		Help:    "Latency of synchronous Kafka produce calls by topic.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"topic"})
+
+	KafkaProduceErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_produce_errors_total",
+		Help: "Kafka produce calls that returned an error, by topic.",
+	}, []string{"topic"})
+
+	ConsumerLag = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_lag",
+		Help: "Messages between the last processed offset and the partition high watermark, by topic.",
+	}, []string{"topic"})
+
+	ConsumerProcessed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_processed_total",
+		Help: "Messages handled and committed, by topic.",
+	}, []string{"topic"})
+
+	ConsumerFailed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_failed_total",
+		Help: "Messages whose handling failed and will be retried, by topic.",
+	}, []string{"topic"})
+
+	ConsumerDeadLettered = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_dlq_total",
+		Help: "Unprocessable messages moved to the dead-letter topic, by source topic.",
+	}, []string{"topic"})
+
+	BookingsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "bookings_created_total",
+		Help: "Bookings created via the API.",
+	})
+
+	BookingsScheduled = factory.NewCounter(prometheus.CounterOpts{
+		Name: "bookings_scheduled_total",
+		Help: "Bookings reserved via the API for a later pickup.",
+	})
+
+	BookingsReleased = factory.NewCounter(prometheus.CounterOpts{
+		Name: "bookings_released_total",
+		Help: "Scheduled bookings released to drivers by the scheduler.",
+	})
+
+	BookingsAccepted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "bookings_accepted_total",
+		Help: "Bookings transitioned to Accepted from booking.accepted events.",
+	})
+
+	BookingsCompleted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "bookings_completed_total",
+		Help: "Bookings completed and charged.",
+	})
+
+	LedgerEntriesPosted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "ledger_entries_posted_total",
+		Help: "Journal entries posted to the ledger.",
+	})
+
+	PromoRedemptions = factory.NewCounter(prometheus.CounterOpts{
+		Name: "promo_redemptions_total",
+		Help: "Bookings created with a promo code.",
+	})
+
+	RatingsSubmitted = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "ratings_submitted_total",
+		Help: "Trip ratings stored, by rater (rider, driver).",
+	}, []string{"rater"})
+
+	BookingsCancelled = factory.NewCounter(prometheus.CounterOpts{
+		Name: "bookings_cancelled_total",
+		Help: "Bookings cancelled by riders, admins or hold expiry.",
+	})
+
+	CancellationFeesCharged = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "cancellation_fees_charged_total",
+		Help: "Cancellations charged a fee, by reason: late_cancellation or no_show.",
+	}, []string{"reason"})
+
+	PaymentGatewayCalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_gateway_calls_total",
+		Help: "Payment gateway calls by operation (authorize, capture, void) and result (ok, declined, error).",
+	}, []string{"operation", "result"})
+
+	WebhookDeliveryAttempts = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_delivery_attempts_total",
+		Help: "Webhook delivery attempts by result (succeeded, retry, failed).",
+	}, []string{"result"})
+)
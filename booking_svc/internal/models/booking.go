@@ -1,25 +1,113 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+
+	"booking_svc/internal/money"
+)
 
 type Location struct {
 	Lat float64 `json:"lat"`
 	Lng float64 `json:"lng"`
 }
 
+const earthRadiusKm = 6371.0
+
+// DistanceKm is the haversine distance from l to q.
+func (l Location) DistanceKm(q Location) float64 {
+	lat1, lat2 := l.Lat*math.Pi/180, q.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (q.Lng - l.Lng) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Stop is an intermediate waypoint of a multi-stop ride.
+type Stop struct {
+	Location
+	// ArrivedAt is when the assigned driver reported reaching the stop.
+	ArrivedAt *time.Time `json:"arrived_at,omitempty"`
+}
+
+// VehicleType is a class of vehicle a rider can ask for.
+type VehicleType string
+
+const (
+	VehicleBike  VehicleType = "bike"
+	VehicleAuto  VehicleType = "auto"
+	VehicleSedan VehicleType = "sedan"
+	VehicleSUV   VehicleType = "suv"
+)
+
+// VehicleTypes lists every vehicle type, smallest first.
+var VehicleTypes = []VehicleType{VehicleBike, VehicleAuto, VehicleSedan, VehicleSUV}
+
+// VehicleSeats is how many passengers each vehicle type carries at most.
+var VehicleSeats = map[VehicleType]int{
+	VehicleBike:  1,
+	VehicleAuto:  3,
+	VehicleSedan: 4,
+	VehicleSUV:   6,
+}
+
 type RideStatus string
 
 const (
+	// RideStatusScheduled is a reservation not yet released to drivers; it
+	// becomes Requested shortly before ScheduledFor.
+	RideStatusScheduled RideStatus = "Scheduled"
 	RideStatusRequested RideStatus = "Requested"
 	RideStatusAccepted  RideStatus = "Accepted"
+	RideStatusCompleted RideStatus = "Completed"
+	RideStatusCancelled RideStatus = "Cancelled"
 )
 
 type Booking struct {
-	BookingID  string     `json:"booking_id"`
-	PickupLoc  Location   `json:"pickuploc"`
-	Dropoff    Location   `json:"dropoff"`
-	Price      int        `json:"price"`
-	RideStatus RideStatus `json:"ride_status"`
-	DriverID   *string    `json:"driver_id,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
+	BookingID string   `json:"booking_id"`
+	RiderID   string   `json:"rider_id,omitempty"`
+	PickupLoc Location `json:"pickuploc"`
+	Dropoff   Location `json:"dropoff"`
+	// Stops are visited in order between PickupLoc and Dropoff.
+	Stops []Stop      `json:"stops,omitempty"`
+	Price money.Money `json:"price"`
+	// VehicleType is the vehicle the rider asked for; empty for any.
+	VehicleType VehicleType `json:"vehicle_type,omitempty"`
+	Passengers  int         `json:"passengers"`
+	RideStatus  RideStatus  `json:"ride_status"`
+	DriverID    *string     `json:"driver_id,omitempty"`
+	// ScheduledFor is the pickup time of a reservation; nil for a ride now.
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	// PromoCode and Discount are set when the booking redeemed a promotion.
+	// Price stays the full price; the rider is held and charged less Discount.
+	PromoCode *string      `json:"promo_code,omitempty"`
+	Discount  *money.Money `json:"discount,omitempty"`
+	// Fare is what the rider was charged; set once the trip is Completed.
+	Fare *money.Money `json:"fare,omitempty"`
+	// ArrivedAt is when the assigned driver reported reaching the pickup.
+	ArrivedAt *time.Time `json:"arrived_at,omitempty"`
+	// Cancellation is set once the booking is Cancelled.
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type CancellationReason string
+
+const (
+	// CancellationFree: called off before the fee applied.
+	CancellationFree CancellationReason = "free"
+	// CancellationLate: called off after a driver was assigned and the free
+	// window had passed.
+	CancellationLate CancellationReason = "late_cancellation"
+	// CancellationNoShow: the driver waited at the pickup for longer than the
+	// policy allows.
+	CancellationNoShow CancellationReason = "no_show"
+)
+
+// Cancellation is why a booking was cancelled and what the rider owes for
+// it. Fee is zero for a free cancellation.
+type Cancellation struct {
+	Reason      CancellationReason `json:"reason"`
+	Fee         money.Money        `json:"fee"`
+	CancelledAt time.Time          `json:"cancelled_at"`
 }
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"booking_svc/internal/money"
+)
+
+type PaymentStatus string
+
+const (
+	// PaymentStatusPending: the authorization was sent but its outcome is not
+	// recorded yet, e.g. the process died mid-call.
+	PaymentStatusPending    PaymentStatus = "pending"
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	PaymentStatusCaptured   PaymentStatus = "captured"
+	PaymentStatusReleased   PaymentStatus = "released"
+	// PaymentStatusFailed: the gateway declined the authorization.
+	PaymentStatusFailed PaymentStatus = "failed"
+)
+
+type PaymentOperation string
+
+const (
+	PaymentOpAuthorize PaymentOperation = "authorize"
+	PaymentOpCapture   PaymentOperation = "capture"
+	PaymentOpVoid      PaymentOperation = "void"
+)
+
+// Payment is the authorization hold placed for a booking and what became of it.
+type Payment struct {
+	BookingID string        `json:"booking_id"`
+	RiderID   string        `json:"rider_id,omitempty"`
+	Status    PaymentStatus `json:"status"`
+	// Amount is the hold; Captured is at most Amount.
+	Amount          money.Money      `json:"amount"`
+	Captured        *money.Money     `json:"captured,omitempty"`
+	AuthorizationID string           `json:"authorization_id,omitempty"`
+	FailureReason   string           `json:"failure_reason,omitempty"`
+	ExpiresAt       time.Time        `json:"expires_at"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	Attempts        []PaymentAttempt `json:"attempts,omitempty"`
+}
+
+// PaymentAttempt records one gateway call.
+type PaymentAttempt struct {
+	ID             int64            `json:"id"`
+	Operation      PaymentOperation `json:"operation"`
+	IdempotencyKey string           `json:"idempotency_key"`
+	Succeeded      bool             `json:"succeeded"`
+	Error          *string          `json:"error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
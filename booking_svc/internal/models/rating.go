@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RatingSide is who gave a rating; each side rates a completed trip once.
+type RatingSide string
+
+const (
+	// RatingByRider is the rider rating the driver.
+	RatingByRider RatingSide = "rider"
+	// RatingByDriver is the driver rating the rider.
+	RatingByDriver RatingSide = "driver"
+)
+
+// Rating is one side's verdict on the other after a trip.
+type Rating struct {
+	BookingID string     `json:"booking_id"`
+	Rater     RatingSide `json:"rater"`
+	RaterID   string     `json:"rater_id"`
+	RateeID   string     `json:"ratee_id"`
+	// Stars is 1 to 5.
+	Stars     int       `json:"stars"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookEventBookingCreated   = "booking.created"
+	WebhookEventBookingAccepted  = "booking.accepted"
+	WebhookEventBookingCompleted = "booking.completed"
+	WebhookEventBookingCancelled = "booking.cancelled"
+	// WebhookEventStopArrived carries the booking each time its driver
+	// reaches one of its stops.
+	WebhookEventStopArrived = "booking.stop_arrived"
+)
+
+// WebhookEventTypes lists every event type a subscription may ask for.
+var WebhookEventTypes = []string{
+	WebhookEventBookingCreated,
+	WebhookEventBookingAccepted,
+	WebhookEventBookingCompleted,
+	WebhookEventBookingCancelled,
+	WebhookEventStopArrived,
+}
+
+type WebhookSubscription struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,136 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func inr(amount int64) Money { return Money{Amount: amount, Currency: "INR"} }
+
+func TestArithmeticRejectsMixedCurrencies(t *testing.T) {
+	usd := Money{Amount: 100, Currency: "USD"}
+	if _, err := inr(100).Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := inr(100).Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Sub: %v", err)
+	}
+	if _, err := inr(100).Cmp(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Cmp: %v", err)
+	}
+	if got, err := inr(250).Sub(inr(100)); err != nil || got != inr(150) {
+		t.Fatalf("Sub: %v, %v", got, err)
+	}
+	if _, err := inr(math.MaxInt64).Add(inr(1)); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("overflow: %v", err)
+	}
+}
+
+func TestMulRoundsHalfAwayFromZero(t *testing.T) {
+	cases := []struct {
+		amount, num, den, want int64
+	}{
+		{1000, 15, 100, 150},
+		{5, 1, 2, 3},   // 2.5 -> 3
+		{-5, 1, 2, -3}, // -2.5 -> -3
+		{7, 1, 3, 2},   // 2.33 -> 2
+		{8, 1, 3, 3},   // 2.67 -> 3
+		{5, 1, -2, -3},
+	}
+	for _, c := range cases {
+		got, err := inr(c.amount).Mul(c.num, c.den)
+		if err != nil || got.Amount != c.want {
+			t.Errorf("%d*%d/%d = %v (%v), want %d", c.amount, c.num, c.den, got, err, c.want)
+		}
+	}
+	if _, err := inr(math.MaxInt64).Mul(2, 1); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("overflow: %v", err)
+	}
+}
+
+func TestAllocateKeepsEveryMinorUnit(t *testing.T) {
+	cases := []struct {
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{-100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{1001, []int64{80, 20}, []int64{801, 200}},
+		{5, []int64{0, 1, 1}, []int64{0, 3, 2}},
+	}
+	for _, c := range cases {
+		got, err := inr(c.amount).Allocate(c.weights...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range got {
+			if got[i].Amount != c.want[i] || got[i].Currency != "INR" {
+				t.Fatalf("Allocate(%d, %v) = %v, want %v", c.amount, c.weights, got, c.want)
+			}
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := map[string]Money{
+		"220.50 INR": inr(22050),
+		"-0.05 INR":  inr(-5),
+		"1500 JPY":   {Amount: 1500, Currency: "JPY"},
+		"1.250 KWD":  {Amount: 1250, Currency: "KWD"},
+	}
+	for want, m := range cases {
+		if got := m.String(); got != want {
+			t.Errorf("%#v: got %q, want %q", m, got, want)
+		}
+	}
+}
+
+func TestUnmarshalAcceptsLegacyInteger(t *testing.T) {
+	var v struct {
+		Price Money `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(`{"price":220}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Price != (Money{Amount: 220}) {
+		t.Fatalf("legacy: %#v", v.Price)
+	}
+	if got, err := v.Price.Resolve("INR"); err != nil || got != inr(22000) {
+		t.Fatalf("resolve: %v, %v", got, err)
+	}
+	if got := inr(22099).MajorUnits(); got != 220 {
+		t.Fatalf("major units: %d", got)
+	}
+	if got, err := (Money{Amount: 1500}).Resolve("JPY"); err != nil || got.Amount != 1500 {
+		t.Fatalf("resolve JPY: %v, %v", got, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":{"amount":22050,"currency":"USD"}}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := v.Price.Resolve("INR"); got != (Money{Amount: 22050, Currency: "USD"}) {
+		t.Fatalf("object: %#v", got)
+	}
+	if err := json.Unmarshal([]byte(`{"price":"220"}`), &v); err == nil {
+		t.Fatal("want an error for a string price")
+	}
+	if err := json.Unmarshal([]byte(`{"price":{"amount":22050}}`), &v); err == nil {
+		t.Fatal("want an error for an object without a currency")
+	}
+
+	out, _ := json.Marshal(inr(22050))
+	if string(out) != `{"amount":22050,"currency":"INR"}` {
+		t.Fatalf("marshal: %s", out)
+	}
+}
+
+func TestNewRejectsUnknownCurrency(t *testing.T) {
+	for _, code := range []string{"", "inr", "XYZ", "INRR"} {
+		if _, err := New(1, code); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("%q: %v", code, err)
+		}
+	}
+}
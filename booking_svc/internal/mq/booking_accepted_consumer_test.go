@@ -0,0 +1,146 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"booking_svc/internal/bus"
+	"booking_svc/internal/config"
+	"booking_svc/internal/events"
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+type fakeRepo struct {
+	repository.BookingRepository
+	mu       sync.Mutex
+	accepted map[string]string
+}
+
+func (f *fakeRepo) MarkAccepted(_ context.Context, bookingID, driverID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.accepted[bookingID]; ok {
+		return false, nil
+	}
+	f.accepted[bookingID] = driverID
+	return true, nil
+}
+
+func (f *fakeRepo) GetByID(_ context.Context, bookingID string) (models.Booking, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	driverID, ok := f.accepted[bookingID]
+	if !ok {
+		return models.Booking{}, false, nil
+	}
+	return models.Booking{BookingID: bookingID, RideStatus: models.RideStatusAccepted, DriverID: &driverID}, true, nil
+}
+
+type notifierFunc func(ctx context.Context, eventType, key string, data any) error
+
+func (f notifierFunc) Notify(ctx context.Context, eventType, key string, data any) error {
+	return f(ctx, eventType, key, data)
+}
+
+func TestBookingAcceptedConsumer_MemoryBus(t *testing.T) {
+	cfg := config.Config{TopicBookingAccepted: "booking.accepted", ConsumerGroupAccepts: "accepts", TopicDLQSuffix: ".dlq"}
+	b := bus.NewMemory()
+	defer b.Close()
+	repo := &fakeRepo{accepted: map[string]string{}}
+	notified := make(chan string, 4)
+	c := NewBookingAcceptedConsumer(cfg, b, repo, notifierFunc(func(_ context.Context, _, key string, data any) error {
+		if key != data.(events.BookingAccepted).BookingID {
+			t.Errorf("notified under key %q", key)
+		}
+		notified <- key
+		return nil
+	}), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	good, _ := json.Marshal(events.BookingAccepted{BookingID: "b-1", DriverID: "d-1", RideStatus: "Accepted"})
+	for _, value := range [][]byte{good, []byte("{"), good} {
+		if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicBookingAccepted, Key: []byte("b-1"), Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The poison message is parked. The duplicate notifies again under the
+	// same key, which queues nothing new.
+	dlq := b.Subscribe(cfg.TopicBookingAccepted+cfg.TopicDLQSuffix, "inspect")
+	parked, err := dlq.Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(parked.Value) != "{" {
+		t.Fatalf("unexpected dead letter: %q", parked.Value)
+	}
+	for range 2 {
+		if id := <-notified; id != "b-1" {
+			t.Fatalf("unexpected notification for %s", id)
+		}
+	}
+	select {
+	case id := <-notified:
+		t.Fatalf("duplicate notification for %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.accepted["b-1"] != "d-1" {
+		t.Fatalf("booking not accepted: %v", repo.accepted)
+	}
+}
+
+func TestBookingAcceptedConsumer_RetriesFailedNotify(t *testing.T) {
+	cfg := config.Config{TopicBookingAccepted: "booking.accepted", ConsumerGroupAccepts: "accepts", TopicDLQSuffix: ".dlq"}
+	b := bus.NewMemory()
+	defer b.Close()
+	repo := &fakeRepo{accepted: map[string]string{}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	attempts := make(chan string, 4)
+	failing := NewBookingAcceptedConsumer(cfg, b, repo, notifierFunc(func(_ context.Context, _, key string, _ any) error {
+		attempts <- key
+		return errors.New("webhook store down")
+	}), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = failing.Run(ctx) }()
+
+	good, _ := json.Marshal(events.BookingAccepted{BookingID: "b-1", DriverID: "d-1", RideStatus: "Accepted"})
+	if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicBookingAccepted, Key: []byte("b-1"), Value: good}); err != nil {
+		t.Fatal(err)
+	}
+	if id := <-attempts; id != "b-1" {
+		t.Fatalf("unexpected notification for %s", id)
+	}
+	// The booking is accepted but the message is left uncommitted, so the
+	// next member of the group gets it and notifies.
+	if err := failing.Close(); err != nil {
+		t.Fatal(err)
+	}
+	notified := make(chan string, 4)
+	c := NewBookingAcceptedConsumer(cfg, b, repo, notifierFunc(func(_ context.Context, _, key string, _ any) error {
+		notified <- key
+		return nil
+	}), logger)
+	go func() { _ = c.Run(ctx) }()
+	select {
+	case id := <-notified:
+		if id != "b-1" {
+			t.Fatalf("unexpected notification for %s", id)
+		}
+	case <-ctx.Done():
+		t.Fatal("failed notification was not retried")
+	}
+}
@@ -3,69 +3,131 @@ package mq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
-	"strings"
 	"time"
 
+	"booking_svc/internal/bus"
 	"booking_svc/internal/config"
 	"booking_svc/internal/events"
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/models"
 	"booking_svc/internal/repository"
+	"booking_svc/internal/tracing"
 
-	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// Notifier receives lifecycle events after they are applied locally.
+// Notifying the same type and key again must be a no-op.
+type Notifier interface {
+	Notify(ctx context.Context, eventType, key string, data any) error
+}
+
 type BookingAcceptedConsumer struct {
-	reader *kafka.Reader
-	repo   repository.BookingRepository
-	logger *slog.Logger
+	sub      bus.Subscriber
+	dlq      *deadLetterWriter
+	repo     repository.BookingRepository
+	notifier Notifier
+	logger   *slog.Logger
+	health   loopHealth
 }
 
-func NewBookingAcceptedConsumer(cfg config.Config, repo repository.BookingRepository, logger *slog.Logger) *BookingAcceptedConsumer {
-	brokers := strings.Split(cfg.KafkaBrokers, ",")
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        brokers,
-		GroupID:        cfg.ConsumerGroupAccepts,
-		Topic:          cfg.TopicBookingAccepted,
-		MinBytes:       1,
-		MaxBytes:       10e6,
-		StartOffset:    kafka.FirstOffset,
-		CommitInterval: 0, // commit after DB success
-	})
-	return &BookingAcceptedConsumer{reader: r, repo: repo, logger: logger}
+// NewBookingAcceptedConsumer joins the accepts consumer group on b. Messages
+// are committed only after the DB update and the notification succeed.
+func NewBookingAcceptedConsumer(cfg config.Config, b bus.Bus, repo repository.BookingRepository, notifier Notifier, logger *slog.Logger) *BookingAcceptedConsumer {
+	return &BookingAcceptedConsumer{
+		sub:      b.Subscribe(cfg.TopicBookingAccepted, cfg.ConsumerGroupAccepts),
+		dlq:      newDeadLetterWriter(cfg, b),
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
+	}
 }
 
 func (c *BookingAcceptedConsumer) Run(ctx context.Context) error {
+	c.health.start()
+	defer c.health.stop()
 	for {
-		msg, err := c.reader.FetchMessage(ctx)
+		msg, err := c.sub.Fetch(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-			c.logger.Error("kafka fetch failed", slog.String("err", err.Error()))
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			c.health.fetchFailed()
+			c.logger.Error("bus fetch failed", slog.String("err", err.Error()))
 			time.Sleep(500 * time.Millisecond)
 			continue
 		}
+		c.health.fetched()
+		observeLag(msg)
+		c.handle(ctx, msg)
+	}
+}
 
-		var evt events.BookingAccepted
-		if err := json.Unmarshal(msg.Value, &evt); err != nil {
-			c.logger.Error("invalid booking.accepted payload", slog.String("err", err.Error()))
-			_ = c.reader.CommitMessages(ctx, msg) // skip poison
-			continue
+func (c *BookingAcceptedConsumer) handle(ctx context.Context, msg bus.Message) {
+	ctx, span := tracing.StartConsume(ctx, msg)
+	defer span.End()
+
+	var evt events.BookingAccepted
+	if err := json.Unmarshal(msg.Value, &evt); err != nil {
+		c.logger.Error("invalid booking.accepted payload", slog.String("err", err.Error()))
+		span.RecordError(err)
+		if err := c.dlq.send(ctx, msg, err.Error()); err != nil {
+			c.logger.Error("dead-letter failed", slog.String("err", err.Error()))
+			return
 		}
+		_ = c.sub.Commit(ctx, msg) // skip poison
+		return
+	}
+	span.SetAttributes(attribute.String("booking_id", evt.BookingID))
 
-		updated, err := c.repo.MarkAccepted(ctx, evt.BookingID, evt.DriverID)
+	updated, err := c.repo.MarkAccepted(ctx, evt.BookingID, evt.DriverID)
+	if err != nil {
+		c.logger.Error("db update failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+		// no commit -> retry later
+		return
+	}
+	notify := updated
+	if updated {
+		metrics.BookingsAccepted.Inc()
+	} else {
+		// Already accepted or missing. A redelivery after a failed
+		// notification sends it again; notifying twice is a no-op.
+		b, ok, err := c.repo.GetByID(ctx, evt.BookingID)
 		if err != nil {
-			c.logger.Error("db update failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
-			// no commit -> retry later
-			continue
+			c.logger.Error("db read failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+			span.SetStatus(codes.Error, err.Error())
+			metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+			return
 		}
-		if !updated {
-			// already accepted or missing — idempotent no-op
-		}
-		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			c.logger.Error("commit failed", slog.String("err", err.Error()))
+		notify = ok && b.DriverID != nil && *b.DriverID == evt.DriverID
+	}
+	if notify {
+		if err := c.notifier.Notify(ctx, models.WebhookEventBookingAccepted, evt.BookingID, evt); err != nil {
+			c.logger.Error("webhook notify failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+			span.SetStatus(codes.Error, err.Error())
+			metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+			// no commit -> retry later
+			return
 		}
 	}
+	if err := c.sub.Commit(ctx, msg); err != nil {
+		c.logger.Error("commit failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.ConsumerProcessed.WithLabelValues(msg.Topic).Inc()
 }
 
-func (c *BookingAcceptedConsumer) Close() error { return c.reader.Close() }
+// Healthy is a readiness check for the consume loop.
+func (c *BookingAcceptedConsumer) Healthy(ctx context.Context) error { return c.health.check(ctx) }
+
+func (c *BookingAcceptedConsumer) Close() error {
+	return c.sub.Close()
+}
@@ -0,0 +1,126 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"booking_svc/internal/bus"
+	"booking_svc/internal/config"
+	"booking_svc/internal/events"
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/money"
+	"booking_svc/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// PayoutRecorder posts the payouts driver_svc has paid to the ledger.
+// Recording the same payout again must post nothing.
+type PayoutRecorder interface {
+	RecordPayout(ctx context.Context, payoutID, driverID string, amount money.Money, paidAt time.Time) (bool, error)
+}
+
+// PayoutPaidConsumer debits drivers' ledger accounts with what driver_svc
+// paid them, so their balance is what is still owed.
+type PayoutPaidConsumer struct {
+	sub    bus.Subscriber
+	dlq    *deadLetterWriter
+	ledger PayoutRecorder
+	logger *slog.Logger
+	health loopHealth
+}
+
+// NewPayoutPaidConsumer joins the payouts consumer group on b. Messages are
+// committed only after the payout is posted.
+func NewPayoutPaidConsumer(cfg config.Config, b bus.Bus, ledger PayoutRecorder, logger *slog.Logger) *PayoutPaidConsumer {
+	return &PayoutPaidConsumer{
+		sub:    b.Subscribe(cfg.TopicPayoutPaid, cfg.ConsumerGroupPayouts),
+		dlq:    newDeadLetterWriter(cfg, b),
+		ledger: ledger,
+		logger: logger,
+	}
+}
+
+func (c *PayoutPaidConsumer) Run(ctx context.Context) error {
+	c.health.start()
+	defer c.health.stop()
+	for {
+		msg, err := c.sub.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			c.health.fetchFailed()
+			c.logger.Error("bus fetch failed", slog.String("err", err.Error()))
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		c.health.fetched()
+		observeLag(msg)
+		c.handle(ctx, msg)
+	}
+}
+
+func validatePaid(evt events.PayoutPaid) error {
+	switch {
+	case evt.PayoutID == "":
+		return errors.New("payout_id is required")
+	case evt.DriverID == "":
+		return errors.New("driver_id is required")
+	case !evt.Amount.IsPositive():
+		return errors.New("amount must be positive")
+	case evt.PaidAt.IsZero():
+		return errors.New("paid_at is required")
+	}
+	return evt.Amount.Validate()
+}
+
+func (c *PayoutPaidConsumer) handle(ctx context.Context, msg bus.Message) {
+	ctx, span := tracing.StartConsume(ctx, msg)
+	defer span.End()
+
+	var evt events.PayoutPaid
+	err := json.Unmarshal(msg.Value, &evt)
+	if err == nil {
+		err = validatePaid(evt)
+	}
+	if err != nil {
+		c.logger.Error("invalid payout.paid payload", slog.String("err", err.Error()))
+		span.RecordError(err)
+		if err := c.dlq.send(ctx, msg, err.Error()); err != nil {
+			c.logger.Error("dead-letter failed", slog.String("err", err.Error()))
+			return
+		}
+		_ = c.sub.Commit(ctx, msg) // skip poison message
+		return
+	}
+	span.SetAttributes(attribute.String("payout_id", evt.PayoutID))
+
+	if _, err := c.ledger.RecordPayout(ctx, evt.PayoutID, evt.DriverID, evt.Amount, evt.PaidAt); err != nil {
+		c.logger.Error("post payout failed", slog.String("payout_id", evt.PayoutID), slog.String("err", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+		// no commit -> retry later
+		return
+	}
+
+	if err := c.sub.Commit(ctx, msg); err != nil {
+		c.logger.Error("commit failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.ConsumerProcessed.WithLabelValues(msg.Topic).Inc()
+}
+
+// Healthy is a readiness check for the consume loop.
+func (c *PayoutPaidConsumer) Healthy(ctx context.Context) error { return c.health.check(ctx) }
+
+func (c *PayoutPaidConsumer) Close() error {
+	return c.sub.Close()
+}
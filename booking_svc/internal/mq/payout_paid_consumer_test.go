@@ -0,0 +1,91 @@
+package mq
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"booking_svc/internal/bus"
+	"booking_svc/internal/config"
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/money"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeLedger posts each payout once.
+type fakeLedger struct {
+	mu     sync.Mutex
+	posted map[string]money.Money
+	calls  chan string
+}
+
+func (f *fakeLedger) RecordPayout(_ context.Context, payoutID, _ string, amount money.Money, _ time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defer func() { f.calls <- payoutID }()
+	if _, ok := f.posted[payoutID]; ok {
+		return false, nil
+	}
+	f.posted[payoutID] = amount
+	return true, nil
+}
+
+func TestPayoutPaidConsumer_MemoryBus(t *testing.T) {
+	cfg := config.Config{TopicPayoutPaid: "payout.paid", ConsumerGroupPayouts: "payouts", TopicDLQSuffix: ".dlq"}
+	b := bus.NewMemory()
+	defer b.Close()
+	ledger := &fakeLedger{posted: map[string]money.Money{}, calls: make(chan string, 8)}
+	c := NewPayoutPaidConsumer(cfg, b, ledger, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	parked := testutil.ToFloat64(metrics.ConsumerDeadLettered.WithLabelValues(cfg.TopicPayoutPaid))
+	go func() { _ = c.Run(ctx) }()
+
+	zero := []byte(`{"payout_id":"p-9","driver_id":"d-1","amount":{"amount":0,"currency":"INR"},"paid_at":"2026-03-02T00:00:00Z"}`)
+	paid := []byte(`{"payout_id":"p-1","driver_id":"d-1","amount":{"amount":17640,"currency":"INR"},"provider_ref":"po_1","paid_at":"2026-03-02T00:00:00Z"}`)
+	for _, value := range [][]byte{
+		[]byte("{"),
+		zero,
+		paid,
+		// A redelivery posts nothing.
+		paid,
+	} {
+		if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicPayoutPaid, Key: []byte("d-1"), Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dlq := b.Subscribe(cfg.TopicPayoutPaid+cfg.TopicDLQSuffix, "inspect")
+	for _, want := range [][]byte{[]byte("{"), zero} {
+		parked, err := dlq.Fetch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(parked.Value) != string(want) {
+			t.Fatalf("unexpected dead letter: %q", parked.Value)
+		}
+	}
+	if n := testutil.ToFloat64(metrics.ConsumerDeadLettered.WithLabelValues(cfg.TopicPayoutPaid)) - parked; n != 2 {
+		t.Fatalf("dead-lettered %v, want 2", n)
+	}
+	for range 2 {
+		select {
+		case id := <-ledger.calls:
+			if id != "p-1" {
+				t.Fatalf("unexpected payout %s", id)
+			}
+		case <-ctx.Done():
+			t.Fatal("payout not posted")
+		}
+	}
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	if len(ledger.posted) != 1 || ledger.posted["p-1"] != (money.Money{Amount: 17640, Currency: "INR"}) {
+		t.Fatalf("posted: %+v", ledger.posted)
+	}
+}
@@ -4,38 +4,56 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
-	"strings"
 	"time"
 
+	"booking_svc/internal/bus"
 	"booking_svc/internal/config"
 	"booking_svc/internal/events"
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/tracing"
 
-	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type Producer struct {
-	writer             *kafka.Writer
-	topicBookingCreated string
-	logger             *slog.Logger
+	pub                   bus.Publisher
+	topicBookingCreated   string
+	topicBookingCancelled string
+	topicBookingCompleted string
+	topicRatingSubmitted  string
+	logger                *slog.Logger
 }
 
-func NewProducer(cfg config.Config, logger *slog.Logger) *Producer {
-	brokers := strings.Split(cfg.KafkaBrokers, ",")
-	w := &kafka.Writer{
-		Addr:         kafka.TCP(brokers...),
-		Topic:        cfg.TopicBookingCreated,
-		Balancer:     &kafka.LeastBytes{},
-		RequiredAcks: kafka.RequireAll,
-		Async:        false,
-	}
+func NewProducer(cfg config.Config, pub bus.Publisher, logger *slog.Logger) *Producer {
 	return &Producer{
-		writer:              w,
-		topicBookingCreated: cfg.TopicBookingCreated,
-		logger:              logger,
+		pub:                   pub,
+		topicBookingCreated:   cfg.TopicBookingCreated,
+		topicBookingCancelled: cfg.TopicBookingCancelled,
+		topicBookingCompleted: cfg.TopicBookingCompleted,
+		topicRatingSubmitted:  cfg.TopicRatingSubmitted,
+		logger:                logger,
 	}
 }
 
 func (p *Producer) ProduceBookingCreated(ctx context.Context, evt events.BookingCreated) error {
+	return p.publish(ctx, p.topicBookingCreated, evt.BookingID, evt)
+}
+
+func (p *Producer) ProduceBookingCancelled(ctx context.Context, evt events.BookingCancelled) error {
+	return p.publish(ctx, p.topicBookingCancelled, evt.BookingID, evt)
+}
+
+func (p *Producer) ProduceBookingCompleted(ctx context.Context, evt events.BookingCompleted) error {
+	return p.publish(ctx, p.topicBookingCompleted, evt.BookingID, evt)
+}
+
+func (p *Producer) ProduceRatingSubmitted(ctx context.Context, evt events.RatingSubmitted) error {
+	return p.publish(ctx, p.topicRatingSubmitted, evt.BookingID, evt)
+}
+
+// publish sends evt keyed by booking id, so every event for a booking lands
+// on the same partition in order.
+func (p *Producer) publish(ctx context.Context, topic, key string, evt any) error {
 	value, err := json.Marshal(evt)
 	if err != nil {
 		return err
@@ -43,13 +61,20 @@ func (p *Producer) ProduceBookingCreated(ctx context.Context, evt events.Booking
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	msg := kafka.Message{
-		Key:   []byte(evt.BookingID),
+	msg := bus.Message{
+		Topic: topic,
+		Key:   []byte(key),
 		Value: value,
 	}
-	return p.writer.WriteMessages(ctx, msg)
-}
+	ctx, span := tracing.StartProduce(ctx, topic, &msg)
+	defer span.End()
 
-func (p *Producer) Close() error {
-	return p.writer.Close()
-}
\ No newline at end of file
+	start := time.Now()
+	err = p.pub.Publish(ctx, msg)
+	metrics.KafkaProduceDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.KafkaProduceErrors.WithLabelValues(topic).Inc()
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,221 @@
+// Package openapi embeds the service's OpenAPI 3 contract, serves it at
+// /openapi.json and validates API traffic against it.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"booking_svc/internal/problem"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// SystemTag marks unauthenticated operations served outside the API router
+// (probes, metrics, this document).
+const SystemTag = "system"
+
+// ResponseMode controls what happens when a response does not match the spec.
+type ResponseMode string
+
+const (
+	ResponsesOff    ResponseMode = "off"
+	ResponsesLog    ResponseMode = "log"    // log the mismatch, send the response anyway
+	ResponsesStrict ResponseMode = "strict" // replace the response with a 500
+)
+
+// Spec is a loaded, validated OpenAPI document with its route matcher.
+type Spec struct {
+	doc    *openapi3.T
+	router routers.Router
+	json   []byte
+}
+
+// Load parses and validates the embedded document.
+func Load() (*Spec, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: load: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("openapi: invalid document: %w", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: build router: %w", err)
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: marshal: %w", err)
+	}
+	return &Spec{doc: doc, router: router, json: js}, nil
+}
+
+// MustLoad is Load for the embedded document, which tests keep valid.
+func MustLoad() *Spec {
+	s, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *Spec) Doc() *openapi3.T { return s.doc }
+
+// ServeHTTP serves the document as JSON.
+func (s *Spec) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(s.json)
+}
+
+// Validator rejects requests that do not match the spec with 400 and checks
+// responses according to mode. Requests for paths or methods the spec does not
+// know are passed through so the router can answer 404/405 itself.
+func (s *Spec) Validator(mode ResponseMode, logger *slog.Logger) func(http.Handler) http.Handler {
+	opts := &openapi3filter.Options{
+		// auth.Middleware has already checked the token.
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, params, err := s.router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			in := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: params,
+				Route:      route,
+				Options:    opts,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), in); err != nil {
+				problem.Write(w, r, problem.Validation(problem.ValidationError{fieldError(err)}))
+				return
+			}
+			if mode != ResponsesLog && mode != ResponsesStrict {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, buffer: mode == ResponsesStrict}
+			next.ServeHTTP(rec, r)
+
+			err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: in,
+				Status:                 rec.statusCode(),
+				Header:                 w.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			})
+			if err != nil {
+				logger.Error("response violates openapi spec",
+					slog.String("method", r.Method),
+					slog.String("path", route.Path),
+					slog.Int("status", rec.statusCode()),
+					slog.String("err", fieldError(err).Message),
+				)
+				if mode == ResponsesStrict {
+					w.Header().Del("Content-Length")
+					problem.Write(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "response does not match API contract"))
+					return
+				}
+			}
+			if rec.buffer {
+				rec.flush()
+			}
+		})
+	}
+}
+
+// fieldError points at the offending field of a kin-openapi error,
+// whose own message embeds whole schemas.
+func fieldError(err error) problem.FieldError {
+	var re *openapi3filter.RequestError
+	hasRE := errors.As(err, &re)
+	var se *openapi3.SchemaError
+	if errors.As(err, &se) {
+		field := strings.Join(se.JSONPointer(), ".")
+		if hasRE && re.Parameter != nil {
+			field = re.Parameter.Name
+		}
+		if field == "" {
+			field = "body"
+		}
+		return problem.FieldError{Field: field, Message: se.Reason}
+	}
+	if hasRE {
+		if re.Parameter != nil {
+			return problem.FieldError{Field: re.Parameter.Name, Message: re.Reason}
+		}
+		if re.RequestBody != nil {
+			return problem.FieldError{Field: "body", Message: re.Reason}
+		}
+		if re.Reason != "" {
+			return problem.FieldError{Field: "request", Message: re.Reason}
+		}
+	}
+	var rse *openapi3filter.ResponseError
+	if errors.As(err, &rse) && rse.Reason != "" {
+		return problem.FieldError{Field: "response", Message: rse.Reason}
+	}
+	return problem.FieldError{Field: "request", Message: err.Error()}
+}
+
+// recorder captures the status and body for response validation. When
+// buffer is set nothing reaches the client until flush, so a strict-mode
+// violation can still be turned into a 500.
+type recorder struct {
+	http.ResponseWriter
+	buffer bool
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status != 0 {
+		return
+	}
+	r.status = code
+	if !r.buffer {
+		r.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(p)
+	if r.buffer {
+		return len(p), nil
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *recorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+func (r *recorder) flush() {
+	r.ResponseWriter.WriteHeader(r.statusCode())
+	_, _ = r.ResponseWriter.Write(r.body.Bytes())
+}
@@ -0,0 +1,55 @@
+package payment
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const expireBatchSize = 100
+
+// HoldExpirer is the slice of service.BookingService the Expirer drives.
+type HoldExpirer interface {
+	ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Expirer periodically settles authorization holds past their expiry. Every
+// step it takes is conditional and idempotent, so replicas may run one each.
+type Expirer struct {
+	svc      HoldExpirer
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewExpirer(svc HoldExpirer, interval time.Duration, logger *slog.Logger) *Expirer {
+	return &Expirer{svc: svc, interval: interval, logger: logger, now: time.Now}
+}
+
+func (e *Expirer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := e.ExpireDue(ctx); err != nil && ctx.Err() == nil {
+			e.logger.Error("expire payment holds failed", slog.String("err", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDue settles expired holds a batch at a time until none are left and
+// returns how many it settled.
+func (e *Expirer) ExpireDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := e.svc.ExpireHolds(ctx, e.now(), expireBatchSize)
+		total += n
+		if err != nil || n < expireBatchSize {
+			return total, err
+		}
+	}
+}
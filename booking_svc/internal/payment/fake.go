@@ -0,0 +1,135 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"booking_svc/internal/money"
+	"booking_svc/internal/service"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownAuthorization = errors.New("payment: unknown authorization")
+	ErrHoldSettled          = errors.New("payment: hold already captured or voided")
+	ErrCaptureExceedsHold   = errors.New("payment: capture exceeds hold")
+)
+
+type holdState int
+
+const (
+	holdOpen holdState = iota
+	holdCaptured
+	holdVoided
+)
+
+type hold struct {
+	amount money.Money
+	state  holdState
+}
+
+// Fake is an in-process gateway for local runs and tests. It keeps holds in
+// memory and, like a real provider, answers a repeated idempotency key with
+// the first call's outcome.
+type Fake struct {
+	declineAbove int64
+
+	mu      sync.Mutex
+	holds   map[string]*hold
+	results map[string]result
+	// Fail, when set, is consulted before every call; a non-nil error is
+	// returned without acting or remembering the key, like a network failure.
+	Fail func(op, key string) error
+}
+
+type result struct {
+	authID string
+	err    error
+}
+
+// NewFake declines holds above declineAbove minor units; zero never declines.
+func NewFake(declineAbove int64) *Fake {
+	return &Fake{declineAbove: declineAbove, holds: make(map[string]*hold), results: make(map[string]result)}
+}
+
+func (f *Fake) Authorize(_ context.Context, key string, req service.AuthorizeRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("authorize", key); err != nil {
+		return "", err
+	}
+	if r, ok := f.results[key]; ok {
+		return r.authID, r.err
+	}
+	var r result
+	if f.declineAbove > 0 && req.Amount.Amount > f.declineAbove {
+		r.err = fmt.Errorf("%w: %s is over the limit", service.ErrPaymentDeclined, req.Amount)
+	} else {
+		r.authID = "auth_" + uuid.NewString()
+		f.holds[r.authID] = &hold{amount: req.Amount}
+	}
+	f.results[key] = r
+	return r.authID, r.err
+}
+
+func (f *Fake) Capture(_ context.Context, key, authorizationID string, amount money.Money) error {
+	return f.settle("capture", key, authorizationID, func(h *hold) error {
+		if c, err := amount.Cmp(h.amount); err != nil || c > 0 {
+			return ErrCaptureExceedsHold
+		}
+		h.state = holdCaptured
+		return nil
+	})
+}
+
+func (f *Fake) Void(_ context.Context, key, authorizationID string) error {
+	return f.settle("void", key, authorizationID, func(h *hold) error {
+		h.state = holdVoided
+		return nil
+	})
+}
+
+func (f *Fake) settle(op, key, authorizationID string, apply func(*hold) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail(op, key); err != nil {
+		return err
+	}
+	if r, ok := f.results[key]; ok {
+		return r.err
+	}
+	var err error
+	switch h := f.holds[authorizationID]; {
+	case h == nil:
+		err = ErrUnknownAuthorization
+	case h.state != holdOpen:
+		err = ErrHoldSettled
+	default:
+		err = apply(h)
+	}
+	f.results[key] = result{err: err}
+	return err
+}
+
+func (f *Fake) fail(op, key string) error {
+	if f.Fail == nil {
+		return nil
+	}
+	return f.Fail(op, key)
+}
+
+// Held returns the open holds' total per currency, for tests.
+func (f *Fake) Held() map[string]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]int64)
+	for _, h := range f.holds {
+		if h.state == holdOpen {
+			out[h.amount.Currency] += h.amount.Amount
+		}
+	}
+	return out
+}
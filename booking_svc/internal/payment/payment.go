@@ -0,0 +1,22 @@
+// Package payment holds the payment gateways and the worker that settles
+// expired authorization holds.
+package payment
+
+import (
+	"fmt"
+
+	"booking_svc/internal/config"
+	"booking_svc/internal/service"
+)
+
+const GatewayFake = "fake"
+
+// Open returns the gateway selected by PAYMENT_GATEWAY.
+func Open(cfg config.Config) (service.PaymentGateway, error) {
+	switch cfg.PaymentGateway {
+	case GatewayFake:
+		return NewFake(cfg.PaymentFakeDeclineAbove), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_GATEWAY %q (want %s)", cfg.PaymentGateway, GatewayFake)
+	}
+}
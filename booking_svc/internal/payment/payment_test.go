@@ -0,0 +1,81 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"booking_svc/internal/money"
+	"booking_svc/internal/payment"
+	"booking_svc/internal/service"
+)
+
+func inr(amount int64) money.Money { return money.Money{Amount: amount, Currency: "INR"} }
+
+func TestFakeAnswersARepeatedKeyWithTheFirstOutcome(t *testing.T) {
+	g, ctx := payment.NewFake(0), context.Background()
+	req := service.AuthorizeRequest{Reference: "b-1", Customer: "r-1", Amount: inr(22050)}
+	first, err := g.Authorize(ctx, "b-1:authorize", req)
+	if err != nil || first == "" {
+		t.Fatalf("authorize: %q, %v", first, err)
+	}
+	again, err := g.Authorize(ctx, "b-1:authorize", req)
+	if err != nil || again != first {
+		t.Fatalf("repeat: %q, %v; want %q", again, err, first)
+	}
+	if held := g.Held()["INR"]; held != 22050 {
+		t.Fatalf("one hold expected, held %d", held)
+	}
+
+	if err := g.Capture(ctx, "b-1:capture", first, inr(19900)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Capture(ctx, "b-1:capture", first, inr(19900)); err != nil {
+		t.Fatalf("repeated capture: %v", err)
+	}
+	if err := g.Void(ctx, "b-1:void", first); !errors.Is(err, payment.ErrHoldSettled) {
+		t.Fatalf("voiding a captured hold: %v", err)
+	}
+	if held := g.Held()["INR"]; held != 0 {
+		t.Fatalf("held %d after capture", held)
+	}
+}
+
+func TestFakeDeclinesAndRejects(t *testing.T) {
+	g, ctx := payment.NewFake(10000), context.Background()
+	if _, err := g.Authorize(ctx, "b-1:authorize", service.AuthorizeRequest{Reference: "b-1", Amount: inr(22050)}); !errors.Is(err, service.ErrPaymentDeclined) {
+		t.Fatalf("want a decline, got %v", err)
+	}
+	id, err := g.Authorize(ctx, "b-2:authorize", service.AuthorizeRequest{Reference: "b-2", Amount: inr(10000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Capture(ctx, "b-2:capture", id, inr(10001)); !errors.Is(err, payment.ErrCaptureExceedsHold) {
+		t.Fatalf("capture above the hold: %v", err)
+	}
+	if err := g.Void(ctx, "b-3:void", "auth_missing"); !errors.Is(err, payment.ErrUnknownAuthorization) {
+		t.Fatalf("void of an unknown hold: %v", err)
+	}
+	if err := g.Void(ctx, "b-2:void", id); err != nil {
+		t.Fatal(err)
+	}
+	if held := g.Held()["INR"]; held != 0 {
+		t.Fatalf("held %d after void", held)
+	}
+}
+
+func TestFakeFailuresAreNotRemembered(t *testing.T) {
+	g, ctx := payment.NewFake(0), context.Background()
+	g.Fail = func(op, key string) error { return errors.New("connection reset") }
+	req := service.AuthorizeRequest{Reference: "b-1", Amount: inr(22050)}
+	if _, err := g.Authorize(ctx, "b-1:authorize", req); err == nil {
+		t.Fatal("want the injected failure")
+	}
+	if held := g.Held()["INR"]; held != 0 {
+		t.Fatalf("a failed call must not hold anything, held %d", held)
+	}
+	g.Fail = nil
+	if id, err := g.Authorize(ctx, "b-1:authorize", req); err != nil || id == "" {
+		t.Fatalf("retry under the same key: %q, %v", id, err)
+	}
+}
@@ -0,0 +1,41 @@
+package problem
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Rule maps a sentinel error (matched with errors.Is) to a status and code.
+type Rule struct {
+	Err    error
+	Status int
+	Code   Code
+}
+
+// Mapper translates service errors into problems. Each transport keeps one
+// Mapper listing every sentinel the service layer can return.
+type Mapper []Rule
+
+// Map returns the problem for err. Unknown errors become a 500 whose detail
+// does not leak internals; a *Problem is passed through unchanged and a
+// ValidationError becomes a 400.
+func (m Mapper) Map(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	var ve ValidationError
+	if errors.As(err, &ve) {
+		return Validation(ve)
+	}
+	for _, rule := range m {
+		if errors.Is(err, rule.Err) {
+			return New(rule.Status, rule.Code, rule.Err.Error())
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return New(http.StatusGatewayTimeout, CodeTimeout, "")
+	}
+	return New(http.StatusInternalServerError, CodeInternal, "")
+}
@@ -0,0 +1,168 @@
+// Package problem renders API errors as RFC 7807 application/problem+json
+// documents with stable, machine-readable codes.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const ContentType = "application/problem+json"
+
+// typePrefix namespaces the problem "type" URI; the suffix is the Code.
+const typePrefix = "urn:problem-type:"
+
+// Code identifies a kind of failure. Codes are part of the API contract:
+// clients branch on them, so never rename one.
+type Code string
+
+const (
+	CodeInvalidJSON           Code = "invalid_json"
+	CodeValidationFailed      Code = "validation_failed"
+	CodeUnauthenticated       Code = "unauthenticated"
+	CodeForbidden             Code = "forbidden"
+	CodeNotFound              Code = "not_found"
+	CodeMethodNotAllowed      Code = "method_not_allowed"
+	CodeRateLimited           Code = "rate_limited"
+	CodeOverloaded            Code = "overloaded"
+	CodeTimeout               Code = "timeout"
+	CodeInternal              Code = "internal"
+	CodeWebhookNotFound       Code = "webhook_not_found"
+	CodeBookingNotFound       Code = "booking_not_found"
+	CodeBookingNotStored      Code = "booking_not_stored"
+	CodeBookingNotDispatched  Code = "booking_not_dispatched"
+	CodeBookingNotRequested   Code = "booking_not_requested"
+	CodeBookingNotAccepted    Code = "booking_not_accepted"
+	CodeBookingNotCancellable Code = "booking_not_cancellable"
+	CodePaymentDeclined       Code = "payment_declined"
+	CodePaymentUnavailable    Code = "payment_unavailable"
+	CodePaymentNotFound       Code = "payment_not_found"
+	CodePaymentSettled        Code = "payment_settled"
+	CodePromoNotFound         Code = "promo_not_found"
+	CodePromoExists           Code = "promo_exists"
+	CodePromoUnknown          Code = "promo_unknown"
+	CodePromoNotActive        Code = "promo_not_active"
+	CodePromoNotApplicable    Code = "promo_not_applicable"
+	CodePromoExhausted        Code = "promo_exhausted"
+	CodePromoAlreadyRedeemed  Code = "promo_already_redeemed"
+	CodeBookingNotCompleted   Code = "booking_not_completed"
+	CodeRatingExists          Code = "rating_exists"
+	CodeRatingNotDispatched   Code = "rating_not_dispatched"
+	CodeNoShowTooEarly        Code = "no_show_too_early"
+	CodeStopNotFound          Code = "stop_not_found"
+	CodeStopOutOfOrder        Code = "stop_out_of_order"
+)
+
+var titles = map[Code]string{
+	CodeInvalidJSON:           "Request body is not valid JSON",
+	CodeValidationFailed:      "Request failed validation",
+	CodeUnauthenticated:       "Authentication required",
+	CodeForbidden:             "Not allowed for this role",
+	CodeNotFound:              "Resource not found",
+	CodeMethodNotAllowed:      "Method not allowed",
+	CodeRateLimited:           "Rate limit exceeded",
+	CodeOverloaded:            "Server overloaded",
+	CodeTimeout:               "Request timed out",
+	CodeInternal:              "Internal server error",
+	CodeWebhookNotFound:       "Webhook subscription not found",
+	CodeBookingNotFound:       "Booking not found",
+	CodeBookingNotStored:      "Booking could not be stored",
+	CodeBookingNotDispatched:  "Booking stored but not dispatched to drivers",
+	CodeBookingNotRequested:   "Booking is no longer requested",
+	CodeBookingNotAccepted:    "Booking is not an accepted trip",
+	CodeBookingNotCancellable: "Booking can no longer be cancelled",
+	CodePaymentDeclined:       "Payment declined",
+	CodePaymentUnavailable:    "Payment provider unavailable",
+	CodePaymentNotFound:       "Payment not found",
+	CodePaymentSettled:        "Payment already settled",
+	CodePromoNotFound:         "Promotion not found",
+	CodePromoExists:           "Promo code already exists",
+	CodePromoUnknown:          "Unknown promo code",
+	CodePromoNotActive:        "Promo code is not active",
+	CodePromoNotApplicable:    "Promo code does not apply to this booking",
+	CodePromoExhausted:        "Promo code has no redemptions left",
+	CodePromoAlreadyRedeemed:  "Promo code already redeemed",
+	CodeBookingNotCompleted:   "Booking is not a completed trip",
+	CodeRatingExists:          "Booking already rated",
+	CodeRatingNotDispatched:   "Rating stored but not published",
+	CodeNoShowTooEarly:        "Driver has not waited long enough for a no-show",
+	CodeStopNotFound:          "Booking has no such stop",
+	CodeStopOutOfOrder:        "Earlier stops have not been reached",
+}
+
+// FieldError points at one invalid input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is the error body returned by every API endpoint.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      Code         `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// New builds a Problem; the title is derived from code.
+func New(status int, code Code, detail string) *Problem {
+	title, ok := titles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+	return &Problem{
+		Type:   typePrefix + string(code),
+		Title:  title,
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return string(p.Code) + ": " + p.Detail
+	}
+	return string(p.Code)
+}
+
+// Write renders p for request r, stamping the request path and chi request ID.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	out := *p
+	out.Instance = r.URL.Path
+	out.RequestID = middleware.GetReqID(r.Context())
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(out.Status)
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// ValidationError collects field-level failures from request validation.
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validation turns err into a 400 validation_failed problem, keeping field
+// errors when err is a ValidationError.
+func Validation(err error) *Problem {
+	var ve ValidationError
+	if errors.As(err, &ve) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "")
+		p.Errors = ve
+		return p
+	}
+	return New(http.StatusBadRequest, CodeValidationFailed, err.Error())
+}
@@ -0,0 +1,208 @@
+// Package promo holds promotions: codes that take a percentage or a flat
+// amount off a booking's price while they are active, optionally only for
+// pickups inside an area, up to global and per-rider redemption limits.
+//
+// A discount never takes the whole price: the rider always pays at least one
+// minor unit, so there is still a hold to authorize.
+package promo
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+)
+
+type Kind string
+
+const (
+	// Percent takes PercentBPS of the price off, up to MaxDiscount.
+	Percent Kind = "percent"
+	// Flat takes AmountOff off the price.
+	Flat Kind = "flat"
+)
+
+var (
+	// ErrUnknownCode means no promotion has the code.
+	ErrUnknownCode = errors.New("unknown promo code")
+	// ErrNotActive means the promotion has not started or has ended.
+	ErrNotActive = errors.New("promo code is not active")
+	// ErrNotApplicable means the promotion does not cover this booking: the
+	// pickup is outside its area or the price is in another currency.
+	ErrNotApplicable = errors.New("promo code does not apply to this booking")
+	// ErrExhausted means every redemption the promotion allows is used up.
+	ErrExhausted = errors.New("promo code has no redemptions left")
+	// ErrAlreadyRedeemed means the rider has used the code as often as it
+	// allows.
+	ErrAlreadyRedeemed = errors.New("promo code already redeemed by this rider")
+)
+
+// Area is a circle around Center; a promotion with one applies only to
+// pickups inside it.
+type Area struct {
+	Center   models.Location `json:"center"`
+	RadiusKm float64         `json:"radius_km"`
+}
+
+// Contains reports whether p is within the area.
+func (a Area) Contains(p models.Location) bool {
+	return a.Center.DistanceKm(p) <= a.RadiusKm
+}
+
+// Promotion is a promo code and the rules for redeeming it.
+type Promotion struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+	Kind        Kind   `json:"kind"`
+	// PercentBPS is a Percent promotion's discount in basis points: 1500 is 15%.
+	PercentBPS int64 `json:"percent_bps,omitempty"`
+	// AmountOff is a Flat promotion's discount.
+	AmountOff *money.Money `json:"amount_off,omitempty"`
+	// MaxDiscount caps a Percent promotion's discount.
+	MaxDiscount *money.Money `json:"max_discount,omitempty"`
+	// MaxRedemptions limits redemptions across all riders; 0 is unlimited.
+	MaxRedemptions int `json:"max_redemptions"`
+	// MaxPerRider limits redemptions by one rider; 0 is unlimited.
+	MaxPerRider int `json:"max_per_rider"`
+	// Redemptions counts the bookings that have used the code.
+	Redemptions int       `json:"redemptions"`
+	StartsAt    time.Time `json:"starts_at"`
+	// EndsAt is exclusive; nil never ends.
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Area      *Area      `json:"area,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// NormalizeCode returns the stored form of code: codes are case-insensitive
+// and kept upper-case.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate reports every invalid field, named as in the REST API.
+func (p Promotion) Validate() error {
+	var errs problem.ValidationError
+
+	if !codePattern.MatchString(p.Code) {
+		errs = append(errs, problem.FieldError{Field: "code", Message: "must be 3-32 letters, digits, '-' or '_'"})
+	}
+	switch p.Kind {
+	case Percent:
+		if p.PercentBPS < 1 || p.PercentBPS > 10000 {
+			errs = append(errs, problem.FieldError{Field: "percent_bps", Message: "must be between 1 and 10000"})
+		}
+		if p.AmountOff != nil {
+			errs = append(errs, problem.FieldError{Field: "amount_off", Message: "must not be set for a percent promotion"})
+		}
+		if p.MaxDiscount != nil {
+			errs = append(errs, validAmount("max_discount", *p.MaxDiscount)...)
+		}
+	case Flat:
+		if p.AmountOff == nil {
+			errs = append(errs, problem.FieldError{Field: "amount_off", Message: "is required for a flat promotion"})
+		} else {
+			errs = append(errs, validAmount("amount_off", *p.AmountOff)...)
+		}
+		if p.PercentBPS != 0 {
+			errs = append(errs, problem.FieldError{Field: "percent_bps", Message: "must not be set for a flat promotion"})
+		}
+		if p.MaxDiscount != nil {
+			errs = append(errs, problem.FieldError{Field: "max_discount", Message: "must not be set for a flat promotion"})
+		}
+	default:
+		errs = append(errs, problem.FieldError{Field: "kind", Message: "must be percent or flat"})
+	}
+	if p.MaxRedemptions < 0 {
+		errs = append(errs, problem.FieldError{Field: "max_redemptions", Message: "must be >= 0"})
+	}
+	if p.MaxPerRider < 0 {
+		errs = append(errs, problem.FieldError{Field: "max_per_rider", Message: "must be >= 0"})
+	}
+	if p.EndsAt != nil && !p.EndsAt.After(p.StartsAt) {
+		errs = append(errs, problem.FieldError{Field: "ends_at", Message: "must be after starts_at"})
+	}
+	if a := p.Area; a != nil {
+		if a.Center.Lat < -90 || a.Center.Lat > 90 {
+			errs = append(errs, problem.FieldError{Field: "area.center.lat", Message: "must be between -90 and 90"})
+		}
+		if a.Center.Lng < -180 || a.Center.Lng > 180 {
+			errs = append(errs, problem.FieldError{Field: "area.center.lng", Message: "must be between -180 and 180"})
+		}
+		if !(a.RadiusKm > 0) {
+			errs = append(errs, problem.FieldError{Field: "area.radius_km", Message: "must be > 0"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validAmount(field string, m money.Money) []problem.FieldError {
+	var errs []problem.FieldError
+	if !m.IsPositive() {
+		errs = append(errs, problem.FieldError{Field: field, Message: "must be > 0"})
+	}
+	if !money.ValidCurrency(m.Currency) {
+		errs = append(errs, problem.FieldError{Field: field + ".currency", Message: "must be a supported ISO 4217 code"})
+	}
+	return errs
+}
+
+// Trip is the booking a code is being redeemed against.
+type Trip struct {
+	Pickup models.Location
+	Price  money.Money
+	At     time.Time
+}
+
+// Active reports whether p can be redeemed at t.
+func (p Promotion) Active(t time.Time) bool {
+	return !t.Before(p.StartsAt) && (p.EndsAt == nil || t.Before(*p.EndsAt))
+}
+
+// Discount is what p takes off t's price. Redemption limits are not checked
+// here; they depend on every other booking and are enforced where the
+// redemption is stored.
+func (p Promotion) Discount(t Trip) (money.Money, error) {
+	if !p.Active(t.At) {
+		return money.Money{}, ErrNotActive
+	}
+	if p.Area != nil && !p.Area.Contains(t.Pickup) {
+		return money.Money{}, ErrNotApplicable
+	}
+	var d money.Money
+	switch p.Kind {
+	case Percent:
+		var err error
+		if d, err = t.Price.Mul(p.PercentBPS, 10000); err != nil {
+			return money.Money{}, err
+		}
+		if p.MaxDiscount != nil {
+			if p.MaxDiscount.Currency != t.Price.Currency {
+				return money.Money{}, ErrNotApplicable
+			}
+			d.Amount = min(d.Amount, p.MaxDiscount.Amount)
+		}
+	case Flat:
+		if p.AmountOff == nil || p.AmountOff.Currency != t.Price.Currency {
+			return money.Money{}, ErrNotApplicable
+		}
+		d = *p.AmountOff
+	default:
+		return money.Money{}, ErrNotApplicable
+	}
+	// The rider always pays something.
+	d.Amount = min(d.Amount, t.Price.Amount-1)
+	if !d.IsPositive() {
+		return money.Money{}, ErrNotApplicable
+	}
+	return d, nil
+}
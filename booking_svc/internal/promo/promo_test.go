@@ -0,0 +1,94 @@
+package promo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+)
+
+func inr(amount int64) *money.Money { return &money.Money{Amount: amount, Currency: "INR"} }
+
+var (
+	start     = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end       = start.Add(30 * 24 * time.Hour)
+	bangalore = models.Location{Lat: 12.9716, Lng: 77.5946}
+)
+
+func TestDiscount(t *testing.T) {
+	trip := Trip{Pickup: bangalore, Price: *inr(20000), At: start.Add(time.Hour)}
+	cases := []struct {
+		name string
+		p    Promotion
+		trip Trip
+		want int64
+		err  error
+	}{
+		{"percent", Promotion{Kind: Percent, PercentBPS: 1500, StartsAt: start}, trip, 3000, nil},
+		{"percent capped", Promotion{Kind: Percent, PercentBPS: 5000, MaxDiscount: inr(5000), StartsAt: start}, trip, 5000, nil},
+		{"flat", Promotion{Kind: Flat, AmountOff: inr(2500), StartsAt: start}, trip, 2500, nil},
+		{"flat leaves a minor unit to pay", Promotion{Kind: Flat, AmountOff: inr(50000), StartsAt: start}, trip, 19999, nil},
+		{"100% leaves a minor unit to pay", Promotion{Kind: Percent, PercentBPS: 10000, StartsAt: start}, trip, 19999, nil},
+		{"rounds to nothing", Promotion{Kind: Percent, PercentBPS: 1, StartsAt: start}, Trip{Price: *inr(20), At: trip.At}, 0, ErrNotApplicable},
+		{"before start", Promotion{Kind: Percent, PercentBPS: 1500, StartsAt: start}, Trip{Price: trip.Price, At: start.Add(-time.Second)}, 0, ErrNotActive},
+		{"at end", Promotion{Kind: Percent, PercentBPS: 1500, StartsAt: start, EndsAt: &end}, Trip{Price: trip.Price, At: end}, 0, ErrNotActive},
+		{"inside area", Promotion{Kind: Percent, PercentBPS: 1500, StartsAt: start, Area: &Area{Center: bangalore, RadiusKm: 5}},
+			Trip{Pickup: models.Location{Lat: 12.99, Lng: 77.60}, Price: trip.Price, At: trip.At}, 3000, nil},
+		{"outside area", Promotion{Kind: Percent, PercentBPS: 1500, StartsAt: start, Area: &Area{Center: bangalore, RadiusKm: 5}},
+			Trip{Pickup: models.Location{Lat: 13.2, Lng: 77.7}, Price: trip.Price, At: trip.At}, 0, ErrNotApplicable},
+		{"other currency", Promotion{Kind: Flat, AmountOff: &money.Money{Amount: 500, Currency: "USD"}, StartsAt: start}, trip, 0, ErrNotApplicable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.p.Discount(c.trip)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("err = %v, want %v", err, c.err)
+			}
+			if err == nil && got != *inr(c.want) {
+				t.Fatalf("discount = %v, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Promotion{Code: "LAUNCH15", Kind: Percent, PercentBPS: 1500, MaxDiscount: inr(5000), StartsAt: start, EndsAt: &end}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid promotion: %v", err)
+	}
+
+	before := start.Add(-time.Hour)
+	cases := []struct {
+		name  string
+		p     Promotion
+		field string
+	}{
+		{"lower-case code", Promotion{Code: "launch", Kind: Flat, AmountOff: inr(100)}, "code"},
+		{"unknown kind", Promotion{Code: "LAUNCH", Kind: "bogo"}, "kind"},
+		{"percent over 100%", Promotion{Code: "LAUNCH", Kind: Percent, PercentBPS: 10001}, "percent_bps"},
+		{"percent with amount off", Promotion{Code: "LAUNCH", Kind: Percent, PercentBPS: 100, AmountOff: inr(100)}, "amount_off"},
+		{"flat without amount", Promotion{Code: "LAUNCH", Kind: Flat}, "amount_off"},
+		{"flat with a cap", Promotion{Code: "LAUNCH", Kind: Flat, AmountOff: inr(100), MaxDiscount: inr(50)}, "max_discount"},
+		{"unknown currency", Promotion{Code: "LAUNCH", Kind: Flat, AmountOff: &money.Money{Amount: 100, Currency: "XYZ"}}, "amount_off.currency"},
+		{"negative limit", Promotion{Code: "LAUNCH", Kind: Flat, AmountOff: inr(100), MaxPerRider: -1}, "max_per_rider"},
+		{"ends before it starts", Promotion{Code: "LAUNCH", Kind: Flat, AmountOff: inr(100), StartsAt: start, EndsAt: &before}, "ends_at"},
+		{"empty area", Promotion{Code: "LAUNCH", Kind: Flat, AmountOff: inr(100), Area: &Area{Center: bangalore}}, "area.radius_km"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var ve problem.ValidationError
+			if err := c.p.Validate(); !errors.As(err, &ve) || len(ve) != 1 || ve[0].Field != c.field {
+				t.Fatalf("Validate() = %v, want one error on %s", err, c.field)
+			}
+		})
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	if got := NormalizeCode("  launch15 "); got != "LAUNCH15" {
+		t.Fatalf("NormalizeCode = %q", got)
+	}
+}
@@ -0,0 +1,158 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking_svc/internal/models"
+)
+
+// pageSize is what the clients ask for per request; the servers cap it at 5000.
+const pageSize = 1000
+
+// client calls a service's admin-only /internal API with a bearer token.
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newClient(baseURL, token string) client {
+	return client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c client) do(ctx context.Context, method, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var p struct {
+			Code   string `json:"code"`
+			Detail string `json:"detail"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		_ = json.Unmarshal(body, &p)
+		return &statusError{method: method, path: path, status: resp.StatusCode, code: p.Code, detail: p.Detail}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// statusError is a non-2xx response, with the problem code when the body had
+// one.
+type statusError struct {
+	method, path string
+	status       int
+	code, detail string
+}
+
+func (e *statusError) Error() string {
+	if e.code != "" {
+		return fmt.Sprintf("%s %s: %d %s: %s", e.method, e.path, e.status, e.code, e.detail)
+	}
+	return fmt.Sprintf("%s %s: %d", e.method, e.path, e.status)
+}
+
+// listSince pages through an /internal listing. The bound is inclusive, so
+// each page restarts at the last created_at seen and repeats are dropped; it
+// stops on a short page or one that adds nothing new.
+func listSince[T any](ctx context.Context, c client, path string, since time.Time, key func(T) (string, time.Time)) ([]T, error) {
+	var out []T
+	seen := make(map[string]bool)
+	for {
+		q := url.Values{}
+		q.Set("created_after", since.UTC().Format(time.RFC3339Nano))
+		q.Set("limit", strconv.Itoa(pageSize))
+		var page []T
+		if err := c.do(ctx, http.MethodGet, path+"?"+q.Encode(), &page); err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, item := range page {
+			id, createdAt := key(item)
+			since = createdAt
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			out = append(out, item)
+			added++
+		}
+		if len(page) < pageSize || added == 0 {
+			return out, nil
+		}
+	}
+}
+
+// BookingClient is a BookingAPI backed by booking_svc's HTTP API.
+type BookingClient struct{ c client }
+
+func NewBookingClient(baseURL, token string) *BookingClient {
+	return &BookingClient{c: newClient(baseURL, token)}
+}
+
+func (b *BookingClient) ListBookings(ctx context.Context, since time.Time) ([]models.Booking, error) {
+	return listSince(ctx, b.c, "/internal/bookings", since, func(x models.Booking) (string, time.Time) {
+		return x.BookingID, x.CreatedAt
+	})
+}
+
+func (b *BookingClient) GetBooking(ctx context.Context, bookingID string) (models.Booking, bool, error) {
+	var out models.Booking
+	err := b.c.do(ctx, http.MethodGet, "/internal/bookings/"+url.PathEscape(bookingID), &out)
+	if se := (*statusError)(nil); errors.As(err, &se) && se.status == http.StatusNotFound {
+		return models.Booking{}, false, nil
+	}
+	if err != nil {
+		return models.Booking{}, false, err
+	}
+	return out, true, nil
+}
+
+func (b *BookingClient) RepublishCreated(ctx context.Context, bookingID string) error {
+	return b.c.do(ctx, http.MethodPost, "/internal/bookings/"+url.PathEscape(bookingID)+"/republish", nil)
+}
+
+// RepublishCancelled repeats the cancel, which is idempotent and publishes
+// booking.cancelled again.
+func (b *BookingClient) RepublishCancelled(ctx context.Context, bookingID string) error {
+	return b.c.do(ctx, http.MethodPost, "/bookings/"+url.PathEscape(bookingID)+"/cancel", nil)
+}
+
+// JobClient is a JobAPI backed by driver_svc's HTTP API.
+type JobClient struct{ c client }
+
+func NewJobClient(baseURL, token string) *JobClient {
+	return &JobClient{c: newClient(baseURL, token)}
+}
+
+func (j *JobClient) ListJobs(ctx context.Context, since time.Time) ([]Job, error) {
+	return listSince(ctx, j.c, "/internal/jobs", since, func(x Job) (string, time.Time) {
+		return x.BookingID, x.CreatedAt
+	})
+}
+
+func (j *JobClient) RepublishAccepted(ctx context.Context, bookingID string) error {
+	return j.c.do(ctx, http.MethodPost, "/internal/jobs/"+url.PathEscape(bookingID)+"/republish", nil)
+}
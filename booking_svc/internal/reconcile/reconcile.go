@@ -0,0 +1,264 @@
+// Package reconcile compares booking_svc's bookings with driver_svc's jobs and
+// can repair drift by republishing the event the lagging side missed.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+// Job is driver_svc's view of a booking, as served by GET /internal/jobs.
+type Job struct {
+	BookingID        string          `json:"booking_id"`
+	PickupLoc        models.Location `json:"pickuploc"`
+	Dropoff          models.Location `json:"dropoff"`
+	Price            money.Money     `json:"price"`
+	Status           string          `json:"status"`
+	AcceptedDriverID string          `json:"accepted_driver_id,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+const (
+	JobStatusOpen      = "Open"
+	JobStatusTaken     = "Taken"
+	JobStatusCancelled = "Cancelled"
+)
+
+// BookingAPI is the booking_svc side of a reconciliation.
+type BookingAPI interface {
+	// ListBookings returns every booking created at or after since.
+	ListBookings(ctx context.Context, since time.Time) ([]models.Booking, error)
+	// GetBooking looks up one booking whenever it was created; ok is false
+	// when booking_svc doesn't have it.
+	GetBooking(ctx context.Context, bookingID string) (b models.Booking, ok bool, err error)
+	RepublishCreated(ctx context.Context, bookingID string) error
+	// RepublishCancelled publishes booking.cancelled again for a Cancelled
+	// booking.
+	RepublishCancelled(ctx context.Context, bookingID string) error
+}
+
+// JobAPI is the driver_svc side of a reconciliation.
+type JobAPI interface {
+	// ListJobs returns every job created at or after since.
+	ListJobs(ctx context.Context, since time.Time) ([]Job, error)
+	RepublishAccepted(ctx context.Context, bookingID string) error
+}
+
+// Kind classifies a disagreement between a booking and its job.
+type Kind string
+
+const (
+	// KindJobMissing: driver_svc never saw booking.created.
+	KindJobMissing Kind = "job_missing"
+	// KindAcceptNotApplied: the job is Taken but booking_svc never saw
+	// booking.accepted.
+	KindAcceptNotApplied Kind = "accept_not_applied"
+	// KindJobNotTaken: the booking is Accepted but the job is still Open.
+	KindJobNotTaken Kind = "job_not_taken"
+	// KindDriverMismatch: both sides are accepted, by different drivers.
+	KindDriverMismatch Kind = "driver_mismatch"
+	// KindBookingMissing: a job exists for a booking booking_svc doesn't have.
+	KindBookingMissing Kind = "booking_missing"
+	// KindDetailsMismatch: price or locations differ.
+	KindDetailsMismatch Kind = "details_mismatch"
+	// KindCancelNotApplied: the booking is Cancelled but driver_svc never saw
+	// booking.cancelled, so the job can still be taken.
+	KindCancelNotApplied Kind = "cancel_not_applied"
+	// KindJobCancelled: the job is Cancelled but the booking is not.
+	KindJobCancelled Kind = "job_cancelled"
+)
+
+type Mismatch struct {
+	Kind      Kind
+	BookingID string
+	Detail    string
+	// Healed reports that the missing event was republished. The fix lands
+	// asynchronously, once the other service consumes it.
+	Healed bool
+	// HealErr is set when healing was attempted and failed.
+	HealErr error
+}
+
+type Options struct {
+	// From is the start of the window; bookings and orphan jobs created
+	// before it are not evaluated.
+	From time.Time
+	// Grace is how long an event may reasonably take to propagate. Items
+	// created within Grace of now are skipped because they may still be in
+	// flight, and both sides are fetched from From-Grace so that an item just
+	// inside the window can find its counterpart just outside it.
+	Grace time.Duration
+	// Heal republishes the missing event for mismatches that allow it.
+	Heal bool
+	// Now overrides the clock in tests.
+	Now func() time.Time
+}
+
+type Report struct {
+	From, To   time.Time
+	Bookings   int
+	Jobs       int
+	Mismatches []Mismatch
+}
+
+// Unhealed counts mismatches that still need attention.
+func (r Report) Unhealed() int {
+	n := 0
+	for _, m := range r.Mismatches {
+		if !m.Healed {
+			n++
+		}
+	}
+	return n
+}
+
+// Counts tallies mismatches by kind.
+func (r Report) Counts() map[Kind]int {
+	out := make(map[Kind]int)
+	for _, m := range r.Mismatches {
+		out[m.Kind]++
+	}
+	return out
+}
+
+// Run compares both sides over the window [opts.From, now-opts.Grace] and,
+// with opts.Heal, republishes events for the mismatches that allow it.
+func Run(ctx context.Context, bookings BookingAPI, jobs JobAPI, opts Options, logger *slog.Logger) (Report, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	rep := Report{From: opts.From, To: now().Add(-opts.Grace)}
+	since := opts.From.Add(-opts.Grace)
+
+	bs, err := bookings.ListBookings(ctx, since)
+	if err != nil {
+		return rep, fmt.Errorf("list bookings: %w", err)
+	}
+	js, err := jobs.ListJobs(ctx, since)
+	if err != nil {
+		return rep, fmt.Errorf("list jobs: %w", err)
+	}
+
+	inWindow := func(t time.Time) bool { return !t.Before(rep.From) && !t.After(rep.To) }
+	jobByID := make(map[string]Job, len(js))
+	for _, j := range js {
+		jobByID[j.BookingID] = j
+	}
+	bookingIDs := make(map[string]bool, len(bs))
+	for _, b := range bs {
+		bookingIDs[b.BookingID] = true
+		if !inWindow(b.CreatedAt) {
+			continue
+		}
+		rep.Bookings++
+		j, ok := jobByID[b.BookingID]
+		if !ok && b.RideStatus == models.RideStatusCancelled {
+			// Cancelled before driver_svc saw it; there is nothing to take.
+			continue
+		}
+		if !ok && b.RideStatus == models.RideStatusScheduled {
+			// Not released to drivers yet.
+			continue
+		}
+		if !ok {
+			rep.Mismatches = append(rep.Mismatches, Mismatch{
+				Kind: KindJobMissing, BookingID: b.BookingID,
+				Detail: "booking is " + string(b.RideStatus) + ", no job",
+			})
+			continue
+		}
+		rep.Jobs++
+		rep.Mismatches = append(rep.Mismatches, compare(b, j)...)
+	}
+	for _, j := range js {
+		if bookingIDs[j.BookingID] || !inWindow(j.CreatedAt) {
+			continue
+		}
+		rep.Jobs++
+		// A reservation is created long before it is released as a job, so
+		// its booking can predate the window.
+		b, ok, err := bookings.GetBooking(ctx, j.BookingID)
+		if err != nil {
+			return rep, fmt.Errorf("get booking %s: %w", j.BookingID, err)
+		}
+		if !ok {
+			rep.Mismatches = append(rep.Mismatches, Mismatch{
+				Kind: KindBookingMissing, BookingID: j.BookingID,
+				Detail: "job is " + j.Status + ", no booking",
+			})
+			continue
+		}
+		bs = append(bs, b)
+		rep.Mismatches = append(rep.Mismatches, compare(b, j)...)
+	}
+	sort.SliceStable(rep.Mismatches, func(i, k int) bool {
+		return rep.Mismatches[i].BookingID < rep.Mismatches[k].BookingID
+	})
+
+	if opts.Heal {
+		status := make(map[string]models.RideStatus, len(bs))
+		for _, b := range bs {
+			status[b.BookingID] = b.RideStatus
+		}
+		for i := range rep.Mismatches {
+			m := &rep.Mismatches[i]
+			var heal func(context.Context, string) error
+			switch {
+			case m.Kind == KindJobMissing && status[m.BookingID] == models.RideStatusRequested:
+				heal = bookings.RepublishCreated
+			case m.Kind == KindAcceptNotApplied:
+				heal = jobs.RepublishAccepted
+			case m.Kind == KindCancelNotApplied:
+				heal = bookings.RepublishCancelled
+			default:
+				continue
+			}
+			if m.HealErr = heal(ctx, m.BookingID); m.HealErr != nil {
+				logger.Warn("reconcile heal failed", slog.String("booking_id", m.BookingID),
+					slog.String("kind", string(m.Kind)), slog.String("err", m.HealErr.Error()))
+				continue
+			}
+			m.Healed = true
+			logger.Info("reconcile healed", slog.String("booking_id", m.BookingID), slog.String("kind", string(m.Kind)))
+		}
+	}
+	return rep, nil
+}
+
+// compare reports every way a booking and its job disagree.
+func compare(b models.Booking, j Job) []Mismatch {
+	var out []Mismatch
+	add := func(k Kind, format string, args ...any) {
+		out = append(out, Mismatch{Kind: k, BookingID: b.BookingID, Detail: fmt.Sprintf(format, args...)})
+	}
+	driverID := ""
+	if b.DriverID != nil {
+		driverID = *b.DriverID
+	}
+	// A Completed trip was accepted first; driver_svc doesn't track completion.
+	accepted := b.RideStatus == models.RideStatusAccepted || b.RideStatus == models.RideStatusCompleted
+	switch {
+	case b.RideStatus == models.RideStatusCancelled && j.Status != JobStatusCancelled:
+		add(KindCancelNotApplied, "booking Cancelled, job still %s", j.Status)
+	case b.RideStatus != models.RideStatusCancelled && j.Status == JobStatusCancelled:
+		add(KindJobCancelled, "job Cancelled, booking is %s", b.RideStatus)
+	case b.RideStatus == models.RideStatusRequested && j.Status == JobStatusTaken:
+		add(KindAcceptNotApplied, "job taken by %s, booking still Requested", j.AcceptedDriverID)
+	case accepted && j.Status == JobStatusOpen:
+		add(KindJobNotTaken, "booking accepted by %s, job still Open", driverID)
+	case accepted && driverID != j.AcceptedDriverID:
+		add(KindDriverMismatch, "booking driver %s, job driver %s", driverID, j.AcceptedDriverID)
+	}
+	if b.Price != j.Price || b.PickupLoc != j.PickupLoc || b.Dropoff != j.Dropoff {
+		add(KindDetailsMismatch, "booking price %s %v→%v, job price %s %v→%v",
+			b.Price, b.PickupLoc, b.Dropoff, j.Price, j.PickupLoc, j.Dropoff)
+	}
+	return out
+}
@@ -0,0 +1,241 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+type fakeBookings struct {
+	items []models.Booking
+	// older are bookings created before the listing's since.
+	older       []models.Booking
+	gotSince    time.Time
+	republished []string
+	cancelled   []string
+}
+
+func (f *fakeBookings) ListBookings(_ context.Context, since time.Time) ([]models.Booking, error) {
+	f.gotSince = since
+	return f.items, nil
+}
+func (f *fakeBookings) GetBooking(_ context.Context, id string) (models.Booking, bool, error) {
+	for _, b := range append(f.items, f.older...) {
+		if b.BookingID == id {
+			return b, true, nil
+		}
+	}
+	return models.Booking{}, false, nil
+}
+func (f *fakeBookings) RepublishCreated(_ context.Context, id string) error {
+	f.republished = append(f.republished, id)
+	return nil
+}
+func (f *fakeBookings) RepublishCancelled(_ context.Context, id string) error {
+	f.cancelled = append(f.cancelled, id)
+	return nil
+}
+
+type fakeJobs struct {
+	items       []Job
+	republished []string
+	err         error
+}
+
+func (f *fakeJobs) ListJobs(context.Context, time.Time) ([]Job, error) { return f.items, nil }
+func (f *fakeJobs) RepublishAccepted(_ context.Context, id string) error {
+	f.republished = append(f.republished, id)
+	return f.err
+}
+
+func TestRun(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	from := now.Add(-time.Hour)
+	at := from.Add(10 * time.Minute)
+	d1, d2 := "d-1", "d-2"
+	loc := models.Location{Lat: 1, Lng: 2}
+	fare := money.Money{Amount: 10000, Currency: "INR"}
+	booking := func(id string, st models.RideStatus, driver *string, created time.Time) models.Booking {
+		return models.Booking{BookingID: id, PickupLoc: loc, Dropoff: loc, Price: fare, RideStatus: st, DriverID: driver, CreatedAt: created}
+	}
+	job := func(id, st, driver string, created time.Time) Job {
+		return Job{BookingID: id, PickupLoc: loc, Dropoff: loc, Price: fare, Status: st, AcceptedDriverID: driver, CreatedAt: created}
+	}
+	repriced := job("b-details", JobStatusOpen, "", at)
+	repriced.Price = money.Money{Amount: 10000, Currency: "USD"}
+
+	bookings := &fakeBookings{items: []models.Booking{
+		booking("b-ok", models.RideStatusAccepted, &d1, at),
+		booking("b-no-job", models.RideStatusRequested, nil, at),
+		booking("b-no-job-accepted", models.RideStatusAccepted, &d1, at),
+		booking("b-accept-lost", models.RideStatusRequested, nil, at),
+		booking("b-open", models.RideStatusAccepted, &d1, at),
+		booking("b-driver", models.RideStatusAccepted, &d1, at),
+		booking("b-details", models.RideStatusRequested, nil, at),
+		booking("b-completed", models.RideStatusCompleted, &d1, at),
+		booking("b-cancel-lost", models.RideStatusCancelled, &d1, at),
+		booking("b-cancelled-early", models.RideStatusCancelled, nil, at),
+		booking("b-scheduled", models.RideStatusScheduled, nil, at),
+		// Created just before the window; its job lands inside it.
+		booking("b-early", models.RideStatusRequested, nil, from.Add(-time.Second)),
+		// Still within grace: its job may be in flight.
+		booking("b-recent", models.RideStatusRequested, nil, now.Add(-10*time.Second)),
+	}, older: []models.Booking{
+		// Reservations made days ago and released inside the window.
+		booking("b-reserved", models.RideStatusAccepted, &d1, from.Add(-72*time.Hour)),
+		booking("b-reserved-lost", models.RideStatusRequested, nil, from.Add(-72*time.Hour)),
+	}}
+	jobs := &fakeJobs{items: []Job{
+		job("b-ok", JobStatusTaken, d1, at),
+		job("b-accept-lost", JobStatusTaken, d2, at),
+		job("b-open", JobStatusOpen, "", at),
+		job("b-driver", JobStatusTaken, d2, at),
+		repriced,
+		job("b-completed", JobStatusTaken, d1, at),
+		job("b-cancel-lost", JobStatusTaken, d1, at),
+		job("b-early", JobStatusOpen, "", from.Add(time.Second)),
+		job("b-orphan", JobStatusOpen, "", at),
+		job("b-reserved", JobStatusTaken, d1, at),
+		job("b-reserved-lost", JobStatusTaken, d2, at),
+	}}
+
+	rep, err := Run(context.Background(), bookings, jobs, Options{
+		From: from, Grace: time.Minute, Heal: true, Now: func() time.Time { return now },
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := from.Add(-time.Minute); !bookings.gotSince.Equal(want) {
+		t.Fatalf("fetched since %s, want %s", bookings.gotSince, want)
+	}
+
+	got := make(map[string]Mismatch)
+	for _, m := range rep.Mismatches {
+		got[m.BookingID+"/"+string(m.Kind)] = m
+	}
+	want := map[string]bool{
+		"b-no-job/job_missing":               true,
+		"b-no-job-accepted/job_missing":      false,
+		"b-accept-lost/accept_not_applied":   true,
+		"b-open/job_not_taken":               false,
+		"b-driver/driver_mismatch":           false,
+		"b-details/details_mismatch":         false,
+		"b-cancel-lost/cancel_not_applied":   true,
+		"b-orphan/booking_missing":           false,
+		"b-reserved-lost/accept_not_applied": true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("mismatches: %+v", rep.Mismatches)
+	}
+	for k, healed := range want {
+		m, ok := got[k]
+		if !ok || m.Healed != healed {
+			t.Fatalf("%s: got %+v, want healed=%v", k, m, healed)
+		}
+	}
+	if rep.Unhealed() != 5 || rep.Bookings != 11 || rep.Jobs != 10 {
+		t.Fatalf("unhealed %d, bookings %d, jobs %d", rep.Unhealed(), rep.Bookings, rep.Jobs)
+	}
+	if fmt.Sprint(bookings.republished) != "[b-no-job]" || fmt.Sprint(jobs.republished) != "[b-accept-lost b-reserved-lost]" ||
+		fmt.Sprint(bookings.cancelled) != "[b-cancel-lost]" {
+		t.Fatalf("republished: bookings %v, jobs %v, cancels %v", bookings.republished, jobs.republished, bookings.cancelled)
+	}
+}
+
+func TestRun_HealFailureLeavesMismatchUnhealed(t *testing.T) {
+	now := time.Now()
+	bookings := &fakeBookings{items: []models.Booking{
+		{BookingID: "b-1", RideStatus: models.RideStatusRequested, CreatedAt: now.Add(-time.Hour)},
+	}}
+	jobs := &fakeJobs{
+		items: []Job{{BookingID: "b-1", Status: JobStatusTaken, AcceptedDriverID: "d-1", CreatedAt: now.Add(-time.Hour)}},
+		err:   errors.New("bus down"),
+	}
+	rep, err := Run(context.Background(), bookings, jobs, Options{From: now.Add(-2 * time.Hour), Heal: true},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Mismatches) != 1 || rep.Mismatches[0].Healed || rep.Mismatches[0].HealErr == nil {
+		t.Fatalf("mismatches: %+v", rep.Mismatches)
+	}
+}
+
+func TestJobClient_PagesThroughInclusiveBound(t *testing.T) {
+	// pageSize+1 jobs, with the page boundary falling inside a run of equal
+	// timestamps, so the second page repeats some of the first.
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	var all []Job
+	for i := 0; i <= pageSize; i++ {
+		all = append(all, Job{BookingID: fmt.Sprintf("b-%04d", i), CreatedAt: base.Add(time.Duration(i/10) * time.Second)})
+	}
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/internal/jobs" || r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		since, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("created_after"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit != pageSize {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		i := sort.Search(len(all), func(i int) bool { return !all[i].CreatedAt.Before(since) })
+		page := all[i:min(i+limit, len(all))]
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	got, err := NewJobClient(srv.URL+"/", "tok").ListJobs(context.Background(), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(all) || requests != 2 {
+		t.Fatalf("got %d jobs in %d requests, want %d in 2", len(got), requests, len(all))
+	}
+}
+
+func TestBookingClient_SurfacesProblemCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = io.WriteString(w, `{"code":"booking_not_requested","detail":"booking is Accepted"}`)
+	}))
+	defer srv.Close()
+
+	err := NewBookingClient(srv.URL, "tok").RepublishCreated(context.Background(), "b-1")
+	if err == nil || err.Error() != "POST /internal/bookings/b-1/republish: 409 booking_not_requested: booking is Accepted" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestBookingClient_GetBookingNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/bookings/b-1" {
+			t.Errorf("path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"code":"booking_not_found"}`)
+	}))
+	defer srv.Close()
+
+	_, ok, err := NewBookingClient(srv.URL, "tok").GetBooking(context.Background(), "b-1")
+	if ok || err != nil {
+		t.Fatalf("ok = %v, err = %v", ok, err)
+	}
+}
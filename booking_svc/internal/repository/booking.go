@@ -2,23 +2,76 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"booking_svc/internal/models"
+	"booking_svc/internal/money"
 )
 
 type CreateBookingParams struct {
-	BookingID  string
-	PickupLoc  models.Location
-	Dropoff    models.Location
-	Price      int
-	RideStatus models.RideStatus
-	DriverID   *string
+	BookingID string
+	RiderID   string
+	PickupLoc models.Location
+	Dropoff   models.Location
+	// Stops are stored in order, numbered from 1.
+	Stops       []models.Location
+	Price       money.Money
+	VehicleType models.VehicleType
+	Passengers  int
+	RideStatus  models.RideStatus
+	DriverID    *string
+	// ScheduledFor is the pickup time of a Scheduled booking.
+	ScheduledFor *time.Time
+	// Promo, when set, is redeemed together with the booking.
+	Promo *PromoRedemption
+}
+
+// PromoRedemption is a promotion applied to a booking being created.
+type PromoRedemption struct {
+	Code     string
+	Discount money.Money
 }
 
 type BookingRepository interface {
+	// Create stores a booking. With params.Promo set it also redeems the
+	// promotion, atomically: if no promotion has the code, or redeeming it
+	// would exceed its global or per-rider limit, it stores nothing and
+	// returns promo.ErrUnknownCode, promo.ErrExhausted or
+	// promo.ErrAlreadyRedeemed.
 	Create(ctx context.Context, params CreateBookingParams) (models.Booking, error)
 	ListAll(ctx context.Context) ([]models.Booking, error)
+	ListByRider(ctx context.Context, riderID string) ([]models.Booking, error)
+	GetByID(ctx context.Context, bookingID string) (models.Booking, bool, error)
+	// ListCreatedSince returns up to limit bookings created at or after since,
+	// oldest first, ties broken by booking_id, so callers can page by created_at.
+	ListCreatedSince(ctx context.Context, since time.Time, limit int) ([]models.Booking, error)
+	// ListScheduled returns the Scheduled bookings of riderID, or of every
+	// rider when riderID is empty, soonest pickup first.
+	ListScheduled(ctx context.Context, riderID string) ([]models.Booking, error)
+	// ClaimDueScheduled leases up to limit Scheduled bookings picked up at or
+	// before dueBy, soonest first, skipping those whose lease runs past now,
+	// and extends their lease to now+lease so concurrent schedulers skip them.
+	ClaimDueScheduled(ctx context.Context, dueBy, now time.Time, lease time.Duration, limit int) ([]models.Booking, error)
+	// MarkReleased sets ride_status=Requested if currently Scheduled.
+	// Returns true if the row was updated, false if not Scheduled or missing.
+	MarkReleased(ctx context.Context, bookingID string) (bool, error)
 	// MarkAccepted sets ride_status=Accepted and driver_id if currently Requested.
 	// Returns true if the row was updated (first time), false if already Accepted or missing.
 	MarkAccepted(ctx context.Context, bookingID string, driverID string) (bool, error)
+	// MarkCompleted sets ride_status=Completed and the fare if currently Accepted.
+	// Returns true if the row was updated, false if not Accepted or missing.
+	MarkCompleted(ctx context.Context, bookingID string, fare money.Money) (bool, error)
+	// MarkArrived records at as when the driver reached the pickup of an
+	// Accepted booking. Returns true the first time, false if the driver
+	// already arrived, the booking is not Accepted or it is missing.
+	MarkArrived(ctx context.Context, bookingID string, at time.Time) (bool, error)
+	// MarkStopArrived records at as the arrival at stop seq, numbered from 1,
+	// of an Accepted booking. Returns false if the stop has already been
+	// reached, the booking is not Accepted, or either is missing.
+	MarkStopArrived(ctx context.Context, bookingID string, seq int, at time.Time) (bool, error)
+	// MarkCancelled sets ride_status=Cancelled and records c if the booking is
+	// still in status from, which must be Scheduled, Requested or Accepted: c
+	// was decided for that status. Returns true if the row was updated, false
+	// if its status changed, or it is missing.
+	MarkCancelled(ctx context.Context, bookingID string, from models.RideStatus, c models.Cancellation) (bool, error)
 }
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"booking_svc/internal/ledger"
+)
+
+type LedgerRepository interface {
+	// PostEntry stores e and its postings atomically, opening accounts on
+	// first use. It reports false and stores nothing when an entry with
+	// e.Reference exists, and fails with ledger.ErrAccountMismatch when a
+	// posting's type or currency differs from its account's.
+	PostEntry(ctx context.Context, e ledger.Entry) (bool, error)
+	// GetEntry returns the entry posted under reference, with its postings
+	// in posting order.
+	GetEntry(ctx context.Context, reference string) (ledger.Entry, bool, error)
+	GetAccount(ctx context.Context, code string) (ledger.Account, bool, error)
+	// SumPostings totals the postings to account by entries that occurred
+	// before before.
+	SumPostings(ctx context.Context, account string, before time.Time) (ledger.Totals, error)
+	// ListLines returns the postings to account by entries that occurred in
+	// [from, to), oldest first, in posting order on ties. Line.Balance is left
+	// zero.
+	ListLines(ctx context.Context, account string, from, to time.Time) ([]ledger.Line, error)
+}
@@ -0,0 +1,302 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/promo"
+	"booking_svc/internal/repository"
+)
+
+type BookingRepo struct {
+	mu       sync.RWMutex
+	clock    clock
+	bookings map[string]models.Booking
+	// leases holds release_claimed_until of Scheduled bookings.
+	leases map[string]time.Time
+	// promos is set by NewPromotionRepo; without it no code can be redeemed.
+	promos *PromotionRepo
+}
+
+func NewBookingRepo() *BookingRepo {
+	return &BookingRepo{bookings: make(map[string]models.Booking), leases: make(map[string]time.Time)}
+}
+
+func (r *BookingRepo) Create(_ context.Context, p repository.CreateBookingParams) (models.Booking, error) {
+	switch p.RideStatus {
+	case models.RideStatusScheduled, models.RideStatusRequested, models.RideStatusAccepted, models.RideStatusCompleted, models.RideStatusCancelled:
+	default:
+		return models.Booking{}, fmt.Errorf("bookings: ride_status %q violates check constraint", p.RideStatus)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.bookings[p.BookingID]; ok {
+		return models.Booking{}, ErrDuplicateKey{Table: "bookings", Key: p.BookingID}
+	}
+	if p.Promo != nil {
+		if r.promos == nil {
+			return models.Booking{}, fmt.Errorf("%w: %q", promo.ErrUnknownCode, p.Promo.Code)
+		}
+		if err := r.promos.redeem(p.Promo.Code, p.RiderID); err != nil {
+			return models.Booking{}, err
+		}
+	}
+	b := models.Booking{
+		BookingID:   p.BookingID,
+		RiderID:     p.RiderID,
+		PickupLoc:   p.PickupLoc,
+		Dropoff:     p.Dropoff,
+		Stops:       newStops(p.Stops),
+		Price:       p.Price,
+		VehicleType: p.VehicleType,
+		Passengers:  p.Passengers,
+		RideStatus:  p.RideStatus,
+		DriverID:    cloneString(p.DriverID),
+		CreatedAt:   r.clock.now(),
+	}
+	if p.ScheduledFor != nil {
+		at := *p.ScheduledFor
+		b.ScheduledFor = &at
+	}
+	if p.Promo != nil {
+		code, discount := p.Promo.Code, p.Promo.Discount
+		b.PromoCode, b.Discount = &code, &discount
+	}
+	r.bookings[b.BookingID] = b
+	return copyBooking(b), nil
+}
+
+func (r *BookingRepo) ListAll(_ context.Context) ([]models.Booking, error) {
+	return r.list(func(models.Booking) bool { return true }), nil
+}
+
+func (r *BookingRepo) ListByRider(_ context.Context, riderID string) ([]models.Booking, error) {
+	return r.list(func(b models.Booking) bool { return b.RiderID == riderID }), nil
+}
+
+// list returns matching bookings newest first.
+func (r *BookingRepo) list(keep func(models.Booking) bool) []models.Booking {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]models.Booking, 0, len(r.bookings))
+	for _, b := range r.bookings {
+		if keep(b) {
+			out = append(out, copyBooking(b))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+func (r *BookingRepo) GetByID(_ context.Context, bookingID string) (models.Booking, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.bookings[bookingID]
+	if !ok {
+		return models.Booking{}, false, nil
+	}
+	return copyBooking(b), true, nil
+}
+
+func (r *BookingRepo) ListCreatedSince(_ context.Context, since time.Time, limit int) ([]models.Booking, error) {
+	out := r.list(func(b models.Booking) bool { return !b.CreatedAt.Before(since) })
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].BookingID < out[j].BookingID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *BookingRepo) ListScheduled(_ context.Context, riderID string) ([]models.Booking, error) {
+	out := r.list(func(b models.Booking) bool {
+		return b.RideStatus == models.RideStatusScheduled && (riderID == "" || b.RiderID == riderID)
+	})
+	sortBySchedule(out)
+	return out, nil
+}
+
+func (r *BookingRepo) ClaimDueScheduled(_ context.Context, dueBy, now time.Time, lease time.Duration, limit int) ([]models.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := make([]models.Booking, 0, limit)
+	for id, b := range r.bookings {
+		if b.RideStatus != models.RideStatusScheduled || b.ScheduledFor == nil || b.ScheduledFor.After(dueBy) {
+			continue
+		}
+		if until, ok := r.leases[id]; ok && until.After(now) {
+			continue
+		}
+		due = append(due, copyBooking(b))
+	}
+	sortBySchedule(due)
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	leased := now.Add(lease).Truncate(time.Microsecond)
+	for _, b := range due {
+		r.leases[b.BookingID] = leased
+	}
+	return due, nil
+}
+
+// sortBySchedule orders bookings by pickup time, then booking_id.
+func sortBySchedule(bs []models.Booking) {
+	sort.Slice(bs, func(i, j int) bool {
+		if !bs[i].ScheduledFor.Equal(*bs[j].ScheduledFor) {
+			return bs[i].ScheduledFor.Before(*bs[j].ScheduledFor)
+		}
+		return bs[i].BookingID < bs[j].BookingID
+	})
+}
+
+func (r *BookingRepo) MarkReleased(_ context.Context, bookingID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[bookingID]
+	if !ok || b.RideStatus != models.RideStatusScheduled {
+		return false, nil
+	}
+	b.RideStatus = models.RideStatusRequested
+	r.bookings[bookingID] = b
+	return true, nil
+}
+
+func (r *BookingRepo) MarkAccepted(_ context.Context, bookingID, driverID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[bookingID]
+	if !ok || b.RideStatus != models.RideStatusRequested {
+		return false, nil
+	}
+	b.RideStatus = models.RideStatusAccepted
+	b.DriverID = &driverID
+	r.bookings[bookingID] = b
+	return true, nil
+}
+
+func (r *BookingRepo) MarkCompleted(_ context.Context, bookingID string, fare money.Money) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[bookingID]
+	if !ok || b.RideStatus != models.RideStatusAccepted {
+		return false, nil
+	}
+	b.RideStatus = models.RideStatusCompleted
+	b.Fare = &fare
+	r.bookings[bookingID] = b
+	return true, nil
+}
+
+func (r *BookingRepo) MarkArrived(_ context.Context, bookingID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[bookingID]
+	if !ok || b.RideStatus != models.RideStatusAccepted || b.ArrivedAt != nil {
+		return false, nil
+	}
+	b.ArrivedAt = &at
+	r.bookings[bookingID] = b
+	return true, nil
+}
+
+func (r *BookingRepo) MarkStopArrived(_ context.Context, bookingID string, seq int, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[bookingID]
+	if !ok || b.RideStatus != models.RideStatusAccepted || seq < 1 || seq > len(b.Stops) || b.Stops[seq-1].ArrivedAt != nil {
+		return false, nil
+	}
+	b.Stops[seq-1].ArrivedAt = &at
+	return true, nil
+}
+
+func (r *BookingRepo) MarkCancelled(_ context.Context, bookingID string, from models.RideStatus, c models.Cancellation) (bool, error) {
+	switch from {
+	case models.RideStatusScheduled, models.RideStatusRequested, models.RideStatusAccepted:
+	default:
+		return false, nil
+	}
+	if c.Fee.IsNegative() {
+		return false, fmt.Errorf("bookings: cancellation_fee %d violates check constraint", c.Fee.Amount)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[bookingID]
+	if !ok || b.RideStatus != from {
+		return false, nil
+	}
+	b.RideStatus = models.RideStatusCancelled
+	// The fee is stored as an amount in the price's currency.
+	c.Fee.Currency = b.Price.Currency
+	b.Cancellation = &c
+	r.bookings[bookingID] = b
+	return true, nil
+}
+
+func newStops(locs []models.Location) []models.Stop {
+	if len(locs) == 0 {
+		return nil
+	}
+	stops := make([]models.Stop, len(locs))
+	for i, l := range locs {
+		stops[i] = models.Stop{Location: l}
+	}
+	return stops
+}
+
+func copyBooking(b models.Booking) models.Booking {
+	if b.Stops != nil {
+		stops := make([]models.Stop, len(b.Stops))
+		for i, s := range b.Stops {
+			if s.ArrivedAt != nil {
+				at := *s.ArrivedAt
+				s.ArrivedAt = &at
+			}
+			stops[i] = s
+		}
+		b.Stops = stops
+	}
+	b.DriverID = cloneString(b.DriverID)
+	b.PromoCode = cloneString(b.PromoCode)
+	b.Discount = cloneMoney(b.Discount)
+	b.Fare = cloneMoney(b.Fare)
+	if b.ScheduledFor != nil {
+		at := *b.ScheduledFor
+		b.ScheduledFor = &at
+	}
+	if b.ArrivedAt != nil {
+		at := *b.ArrivedAt
+		b.ArrivedAt = &at
+	}
+	if b.Cancellation != nil {
+		c := *b.Cancellation
+		b.Cancellation = &c
+	}
+	return b
+}
+
+func cloneMoney(m *money.Money) *money.Money {
+	if m == nil {
+		return nil
+	}
+	v := *m
+	return &v
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"booking_svc/internal/ledger"
+)
+
+type LedgerRepo struct {
+	mu       sync.RWMutex
+	clock    clock
+	accounts map[string]ledger.Account
+	entries  map[string]ledger.Entry
+	// refs maps each entry reference to its entry id.
+	refs map[string]string
+	// postings are kept in posting order across all entries.
+	postings []storedPosting
+}
+
+type storedPosting struct {
+	entryID string
+	ledger.Posting
+}
+
+func NewLedgerRepo() *LedgerRepo {
+	return &LedgerRepo{
+		accounts: make(map[string]ledger.Account),
+		entries:  make(map[string]ledger.Entry),
+		refs:     make(map[string]string),
+	}
+}
+
+func (r *LedgerRepo) PostEntry(_ context.Context, e ledger.Entry) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.refs[e.Reference]; ok {
+		return false, nil
+	}
+	if _, ok := r.entries[e.ID]; ok {
+		return false, ErrDuplicateKey{Table: "ledger_entries", Key: e.ID}
+	}
+	// Check every posting before writing anything, as the transaction would.
+	opened := make(map[string]ledger.Account)
+	for _, p := range e.Postings {
+		acct, ok := r.accounts[p.Account]
+		if !ok {
+			acct, ok = opened[p.Account]
+		}
+		if !ok {
+			acct = ledger.Account{Code: p.Account, Type: p.AccountType, Currency: p.Amount.Currency}
+			opened[p.Account] = acct
+		}
+		if acct.Type != p.AccountType || acct.Currency != p.Amount.Currency {
+			return false, fmt.Errorf("%w: %s is a %s account in %s, posting is %s in %s",
+				ledger.ErrAccountMismatch, p.Account, acct.Type, acct.Currency, p.AccountType, p.Amount.Currency)
+		}
+	}
+	now := r.clock.now()
+	for code, acct := range opened {
+		acct.CreatedAt = now
+		r.accounts[code] = acct
+	}
+	e.OccurredAt = e.OccurredAt.Truncate(time.Microsecond)
+	e.CreatedAt = now
+	e.Postings = append([]ledger.Posting(nil), e.Postings...)
+	r.entries[e.ID] = e
+	r.refs[e.Reference] = e.ID
+	for _, p := range e.Postings {
+		r.postings = append(r.postings, storedPosting{entryID: e.ID, Posting: p})
+	}
+	return true, nil
+}
+
+func (r *LedgerRepo) GetEntry(_ context.Context, reference string) (ledger.Entry, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.refs[reference]
+	if !ok {
+		return ledger.Entry{}, false, nil
+	}
+	e := r.entries[id]
+	e.Postings = append([]ledger.Posting(nil), e.Postings...)
+	return e, true, nil
+}
+
+func (r *LedgerRepo) GetAccount(_ context.Context, code string) (ledger.Account, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	acct, ok := r.accounts[code]
+	return acct, ok, nil
+}
+
+func (r *LedgerRepo) SumPostings(_ context.Context, account string, before time.Time) (ledger.Totals, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var t ledger.Totals
+	for _, p := range r.postings {
+		if p.Account != account || !r.entries[p.entryID].OccurredAt.Before(before) {
+			continue
+		}
+		if p.Side == ledger.Debit {
+			t.Debits += p.Amount.Amount
+		} else {
+			t.Credits += p.Amount.Amount
+		}
+	}
+	return t, nil
+}
+
+func (r *LedgerRepo) ListLines(_ context.Context, account string, from, to time.Time) ([]ledger.Line, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]ledger.Line, 0, 16)
+	for _, p := range r.postings {
+		e := r.entries[p.entryID]
+		if p.Account != account || e.OccurredAt.Before(from) || !e.OccurredAt.Before(to) {
+			continue
+		}
+		out = append(out, ledger.Line{
+			EntryID:     e.ID,
+			Reference:   e.Reference,
+			Description: e.Description,
+			OccurredAt:  e.OccurredAt,
+			Side:        p.Side,
+			Amount:      p.Amount,
+		})
+	}
+	// Stable, so ties keep posting order.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].OccurredAt.Before(out[j].OccurredAt) })
+	return out, nil
+}
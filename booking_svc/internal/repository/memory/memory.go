@@ -0,0 +1,39 @@
+// Package memory holds in-process reference implementations of the
+// repository interfaces. They follow the Postgres implementations exactly —
+// ordering, idempotency, conditional updates and constraint failures — and
+// the repotest conformance suite runs against both to keep it that way.
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateKey mirrors a primary-key violation in Postgres.
+type ErrDuplicateKey struct {
+	Table string
+	Key   string
+}
+
+func (e ErrDuplicateKey) Error() string {
+	return fmt.Sprintf("duplicate key %q in %s", e.Key, e.Table)
+}
+
+// clock hands out created_at values the way Postgres NOW() does: microsecond
+// precision, and strictly increasing here so that DESC orderings are stable.
+type clock struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := time.Now().Truncate(time.Microsecond)
+	if !t.After(c.last) {
+		t = c.last.Add(time.Microsecond)
+	}
+	c.last = t
+	return t
+}
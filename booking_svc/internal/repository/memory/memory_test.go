@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"testing"
+
+	"booking_svc/internal/repository"
+	"booking_svc/internal/repository/repotest"
+)
+
+func TestBookingRepo(t *testing.T) {
+	repotest.BookingRepository(t, func(*testing.T) repository.BookingRepository { return NewBookingRepo() })
+}
+
+func TestWebhookRepo(t *testing.T) {
+	repotest.WebhookRepository(t, func(*testing.T) repository.WebhookRepository { return NewWebhookRepo() })
+}
+
+func TestPaymentRepo(t *testing.T) {
+	repotest.PaymentRepository(t, func(*testing.T) repository.PaymentRepository { return NewPaymentRepo() })
+}
+
+func TestLedgerRepo(t *testing.T) {
+	repotest.LedgerRepository(t, func(*testing.T) repository.LedgerRepository { return NewLedgerRepo() })
+}
+
+func TestPromotionRepo(t *testing.T) {
+	repotest.PromotionRepository(t, func(*testing.T) (repository.PromotionRepository, repository.BookingRepository) {
+		bookings := NewBookingRepo()
+		return NewPromotionRepo(bookings), bookings
+	})
+}
+
+func TestRatingRepo(t *testing.T) {
+	repotest.RatingRepository(t, func(*testing.T) repository.RatingRepository { return NewRatingRepo() })
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+type PaymentRepo struct {
+	mu       sync.Mutex
+	clock    clock
+	payments map[string]models.Payment
+	attempts map[string][]models.PaymentAttempt
+	// retryAt is each payment's expiry_retry_at.
+	retryAt map[string]time.Time
+	nextID  int64
+}
+
+func NewPaymentRepo() *PaymentRepo {
+	return &PaymentRepo{
+		payments: make(map[string]models.Payment),
+		attempts: make(map[string][]models.PaymentAttempt),
+		retryAt:  make(map[string]time.Time),
+	}
+}
+
+func (r *PaymentRepo) CreatePayment(_ context.Context, p repository.CreatePaymentParams) (models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.payments[p.BookingID]; ok {
+		return models.Payment{}, ErrDuplicateKey{Table: "payments", Key: p.BookingID}
+	}
+	now := r.clock.now()
+	pay := models.Payment{
+		BookingID: p.BookingID,
+		RiderID:   p.RiderID,
+		Status:    models.PaymentStatusPending,
+		Amount:    p.Amount,
+		ExpiresAt: p.ExpiresAt.Truncate(time.Microsecond),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.payments[pay.BookingID] = pay
+	return copyPayment(pay), nil
+}
+
+func (r *PaymentRepo) GetPayment(_ context.Context, bookingID string) (models.Payment, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payments[bookingID]
+	if !ok {
+		return models.Payment{}, false, nil
+	}
+	return copyPayment(p), true, nil
+}
+
+func (r *PaymentRepo) UpdatePayment(_ context.Context, bookingID string, from models.PaymentStatus, u repository.PaymentUpdate) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payments[bookingID]
+	if !ok || p.Status != from {
+		return false, nil
+	}
+	p.Status = u.Status
+	if u.AuthorizationID != nil {
+		p.AuthorizationID = *u.AuthorizationID
+	}
+	if u.Captured != nil {
+		// Only the amount is stored; the currency is the hold's.
+		p.Captured = cloneMoney(u.Captured)
+		p.Captured.Currency = p.Amount.Currency
+	}
+	if u.FailureReason != nil {
+		p.FailureReason = *u.FailureReason
+	}
+	p.UpdatedAt = r.clock.now()
+	r.payments[bookingID] = p
+	return true, nil
+}
+
+func (r *PaymentRepo) RecordAttempt(_ context.Context, p repository.RecordPaymentAttemptParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.payments[p.BookingID]; !ok {
+		return fmt.Errorf("payment_attempts: booking_id %q violates foreign key constraint", p.BookingID)
+	}
+	r.nextID++
+	r.attempts[p.BookingID] = append(r.attempts[p.BookingID], models.PaymentAttempt{
+		ID:             r.nextID,
+		Operation:      p.Operation,
+		IdempotencyKey: p.IdempotencyKey,
+		Succeeded:      p.Succeeded,
+		Error:          cloneString(p.Error),
+		CreatedAt:      r.clock.now(),
+	})
+	return nil
+}
+
+func (r *PaymentRepo) ListAttempts(_ context.Context, bookingID string) ([]models.PaymentAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	src := r.attempts[bookingID]
+	out := make([]models.PaymentAttempt, len(src))
+	for i, a := range src {
+		a.Error = cloneString(a.Error)
+		out[i] = a
+	}
+	return out, nil
+}
+
+func (r *PaymentRepo) ListExpiredHolds(_ context.Context, now time.Time, limit int) ([]models.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.Payment, 0, limit)
+	for _, p := range r.payments {
+		open := p.Status == models.PaymentStatusPending || p.Status == models.PaymentStatusAuthorized
+		if open && !r.due(p).After(now) {
+			out = append(out, copyPayment(p))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if di, dj := r.due(out[i]), r.due(out[j]); !di.Equal(dj) {
+			return di.Before(dj)
+		}
+		return out[i].BookingID < out[j].BookingID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// due is when the expirer next looks at p: the later of its expiry and its
+// postponement.
+func (r *PaymentRepo) due(p models.Payment) time.Time {
+	if retry, ok := r.retryAt[p.BookingID]; ok && retry.After(p.ExpiresAt) {
+		return retry
+	}
+	return p.ExpiresAt
+}
+
+func (r *PaymentRepo) PostponeExpiry(_ context.Context, bookingID string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.payments[bookingID]; ok {
+		r.retryAt[bookingID] = until.Truncate(time.Microsecond)
+	}
+	return nil
+}
+
+func copyPayment(p models.Payment) models.Payment {
+	p.Captured = cloneMoney(p.Captured)
+	p.Attempts = nil
+	return p
+}
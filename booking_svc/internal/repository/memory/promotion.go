@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"booking_svc/internal/promo"
+)
+
+type PromotionRepo struct {
+	mu         sync.RWMutex
+	clock      clock
+	promotions map[string]promo.Promotion
+	// redeemed counts redemptions by code, then rider.
+	redeemed map[string]map[string]int
+}
+
+// NewPromotionRepo returns the promotions that bookings redeems codes
+// against, as Postgres shares them through promo_redemptions.
+func NewPromotionRepo(bookings *BookingRepo) *PromotionRepo {
+	r := &PromotionRepo{
+		promotions: make(map[string]promo.Promotion),
+		redeemed:   make(map[string]map[string]int),
+	}
+	bookings.mu.Lock()
+	bookings.promos = r
+	bookings.mu.Unlock()
+	return r
+}
+
+func (r *PromotionRepo) CreatePromotion(_ context.Context, p promo.Promotion) (promo.Promotion, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.promotions[p.Code]; ok {
+		return promo.Promotion{}, false, nil
+	}
+	p = clonePromotion(p)
+	p.Redemptions = 0
+	p.StartsAt = p.StartsAt.Truncate(time.Microsecond)
+	if p.EndsAt != nil {
+		*p.EndsAt = p.EndsAt.Truncate(time.Microsecond)
+	}
+	p.CreatedAt = r.clock.now()
+	r.promotions[p.Code] = p
+	return clonePromotion(p), true, nil
+}
+
+func (r *PromotionRepo) GetPromotion(_ context.Context, code string) (promo.Promotion, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.promotions[code]
+	if !ok {
+		return promo.Promotion{}, false, nil
+	}
+	return clonePromotion(p), true, nil
+}
+
+func (r *PromotionRepo) ListPromotions(_ context.Context) ([]promo.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]promo.Promotion, 0, len(r.promotions))
+	for _, p := range r.promotions {
+		out = append(out, clonePromotion(p))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *PromotionRepo) CountRiderRedemptions(_ context.Context, code, riderID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.redeemed[code][riderID], nil
+}
+
+// redeem uses one of code's redemptions for riderID, or returns why it
+// cannot; the caller stores the booking only if it succeeds.
+func (r *PromotionRepo) redeem(code, riderID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.promotions[code]
+	if !ok {
+		return fmt.Errorf("%w: %q", promo.ErrUnknownCode, code)
+	}
+	if p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions {
+		return promo.ErrExhausted
+	}
+	if p.MaxPerRider > 0 && r.redeemed[code][riderID] >= p.MaxPerRider {
+		return promo.ErrAlreadyRedeemed
+	}
+	p.Redemptions++
+	r.promotions[code] = p
+	if r.redeemed[code] == nil {
+		r.redeemed[code] = make(map[string]int)
+	}
+	r.redeemed[code][riderID]++
+	return nil
+}
+
+func clonePromotion(p promo.Promotion) promo.Promotion {
+	p.AmountOff = cloneMoney(p.AmountOff)
+	p.MaxDiscount = cloneMoney(p.MaxDiscount)
+	if p.EndsAt != nil {
+		v := *p.EndsAt
+		p.EndsAt = &v
+	}
+	if p.Area != nil {
+		v := *p.Area
+		p.Area = &v
+	}
+	return p
+}
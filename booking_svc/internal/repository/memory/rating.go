@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"booking_svc/internal/models"
+)
+
+type ratingKey struct {
+	bookingID string
+	rater     models.RatingSide
+}
+
+type RatingRepo struct {
+	mu      sync.Mutex
+	clock   clock
+	ratings map[ratingKey]models.Rating
+}
+
+func NewRatingRepo() *RatingRepo {
+	return &RatingRepo{ratings: make(map[ratingKey]models.Rating)}
+}
+
+func (r *RatingRepo) CreateRating(_ context.Context, rt models.Rating) (models.Rating, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := ratingKey{bookingID: rt.BookingID, rater: rt.Rater}
+	if existing, ok := r.ratings[k]; ok {
+		return existing, false, nil
+	}
+	rt.CreatedAt = r.clock.now()
+	r.ratings[k] = rt
+	return rt, true, nil
+}
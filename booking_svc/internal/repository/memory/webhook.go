@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+type WebhookRepo struct {
+	mu         sync.Mutex
+	clock      clock
+	subs       map[string]models.WebhookSubscription
+	deliveries map[string]models.WebhookDelivery
+}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{
+		subs:       make(map[string]models.WebhookSubscription),
+		deliveries: make(map[string]models.WebhookDelivery),
+	}
+}
+
+func (r *WebhookRepo) CreateSubscription(_ context.Context, p repository.CreateSubscriptionParams) (models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subs[p.ID]; ok {
+		return models.WebhookSubscription{}, ErrDuplicateKey{Table: "webhook_subscriptions", Key: p.ID}
+	}
+	s := models.WebhookSubscription{
+		ID:         p.ID,
+		URL:        p.URL,
+		EventTypes: slices.Clone(p.EventTypes),
+		Secret:     p.Secret,
+		CreatedAt:  r.clock.now(),
+	}
+	r.subs[s.ID] = s
+	return copySubscription(s), nil
+}
+
+// ListSubscriptions returns subscriptions newest first.
+func (r *WebhookRepo) ListSubscriptions(_ context.Context) ([]models.WebhookSubscription, error) {
+	out := r.subscriptions(func(models.WebhookSubscription) bool { return true })
+	slices.Reverse(out)
+	return out, nil
+}
+
+// ListSubscriptionsForEvent returns subscribers to eventType oldest first.
+func (r *WebhookRepo) ListSubscriptionsForEvent(_ context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	return r.subscriptions(func(s models.WebhookSubscription) bool {
+		return slices.Contains(s.EventTypes, eventType)
+	}), nil
+}
+
+// subscriptions returns matching subscriptions oldest first.
+func (r *WebhookRepo) subscriptions(keep func(models.WebhookSubscription) bool) []models.WebhookSubscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.WebhookSubscription, 0, len(r.subs))
+	for _, s := range r.subs {
+		if keep(s) {
+			out = append(out, copySubscription(s))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+func (r *WebhookRepo) GetSubscription(_ context.Context, id string) (models.WebhookSubscription, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.subs[id]
+	if !ok {
+		return models.WebhookSubscription{}, false, nil
+	}
+	return copySubscription(s), true, nil
+}
+
+// DeleteSubscription also drops its deliveries, like ON DELETE CASCADE.
+func (r *WebhookRepo) DeleteSubscription(_ context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subs[id]; !ok {
+		return false, nil
+	}
+	delete(r.subs, id)
+	for did, d := range r.deliveries {
+		if d.SubscriptionID == id {
+			delete(r.deliveries, did)
+		}
+	}
+	return true, nil
+}
+
+// EnqueueDeliveries inserts all deliveries or none. Existing ids are skipped;
+// an unknown subscription fails the whole batch, as the foreign key does.
+func (r *WebhookRepo) EnqueueDeliveries(_ context.Context, ps []repository.EnqueueDeliveryParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range ps {
+		if _, ok := r.subs[p.SubscriptionID]; !ok {
+			return fmt.Errorf("webhook_deliveries: subscription %q violates foreign key constraint", p.SubscriptionID)
+		}
+	}
+	for _, p := range ps {
+		if _, ok := r.deliveries[p.ID]; ok {
+			continue
+		}
+		now := r.clock.now()
+		r.deliveries[p.ID] = models.WebhookDelivery{
+			ID:             p.ID,
+			SubscriptionID: p.SubscriptionID,
+			EventID:        p.EventID,
+			EventType:      p.EventType,
+			Payload:        slices.Clone(p.Payload),
+			Status:         models.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+	}
+	return nil
+}
+
+func (r *WebhookRepo) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := make([]models.WebhookDelivery, 0, limit)
+	for _, d := range r.deliveries {
+		if d.Status == models.DeliveryStatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	leased := now.Add(lease).Truncate(time.Microsecond)
+	for i := range due {
+		due[i].NextAttemptAt = leased
+		r.deliveries[due[i].ID] = due[i]
+		due[i] = copyDelivery(due[i])
+	}
+	return due, nil
+}
+
+// RecordAttempt is a no-op for unknown ids, like an UPDATE matching no rows.
+func (r *WebhookRepo) RecordAttempt(_ context.Context, deliveryID string, res repository.DeliveryAttemptResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[deliveryID]
+	if !ok {
+		return nil
+	}
+	d.Attempts++
+	d.LastStatusCode = cloneInt(res.StatusCode)
+	d.LastError = cloneString(res.Error)
+	switch {
+	case res.Succeeded:
+		d.Status = models.DeliveryStatusSucceeded
+		now := r.clock.now()
+		d.DeliveredAt = &now
+	case res.NextAttemptAt == nil:
+		d.Status = models.DeliveryStatusFailed
+	default:
+		d.Status = models.DeliveryStatusPending
+	}
+	if res.NextAttemptAt != nil {
+		d.NextAttemptAt = res.NextAttemptAt.Truncate(time.Microsecond)
+	}
+	r.deliveries[deliveryID] = d
+	return nil
+}
+
+// ListDeliveries returns up to limit deliveries for a subscription, newest first.
+func (r *WebhookRepo) ListDeliveries(_ context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.WebhookDelivery, 0, limit)
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			out = append(out, copyDelivery(d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func copySubscription(s models.WebhookSubscription) models.WebhookSubscription {
+	s.EventTypes = slices.Clone(s.EventTypes)
+	return s
+}
+
+func copyDelivery(d models.WebhookDelivery) models.WebhookDelivery {
+	d.Payload = slices.Clone(d.Payload)
+	d.LastStatusCode = cloneInt(d.LastStatusCode)
+	d.LastError = cloneString(d.LastError)
+	if d.DeliveredAt != nil {
+		t := *d.DeliveredAt
+		d.DeliveredAt = &t
+	}
+	return d
+}
+
+func cloneInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	v := *n
+	return &v
+}
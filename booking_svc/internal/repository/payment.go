@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+)
+
+type CreatePaymentParams struct {
+	BookingID string
+	RiderID   string
+	Amount    money.Money
+	ExpiresAt time.Time
+}
+
+// PaymentUpdate is applied by UpdatePayment; nil fields are left unchanged.
+type PaymentUpdate struct {
+	Status          models.PaymentStatus
+	AuthorizationID *string
+	Captured        *money.Money
+	FailureReason   *string
+}
+
+type RecordPaymentAttemptParams struct {
+	BookingID      string
+	Operation      models.PaymentOperation
+	IdempotencyKey string
+	Succeeded      bool
+	Error          *string
+}
+
+type PaymentRepository interface {
+	// CreatePayment stores a pending payment; the booking id must be unused.
+	CreatePayment(ctx context.Context, p CreatePaymentParams) (models.Payment, error)
+	// GetPayment returns the payment without its attempts.
+	GetPayment(ctx context.Context, bookingID string) (models.Payment, bool, error)
+	// UpdatePayment applies u if the payment's status is still from. Returns
+	// true if the row was updated, false if the status moved on or it is missing.
+	UpdatePayment(ctx context.Context, bookingID string, from models.PaymentStatus, u PaymentUpdate) (bool, error)
+	RecordAttempt(ctx context.Context, p RecordPaymentAttemptParams) error
+	// ListAttempts returns a payment's attempts, oldest first.
+	ListAttempts(ctx context.Context, bookingID string) ([]models.PaymentAttempt, error)
+	// ListExpiredHolds returns up to limit pending or authorized payments whose
+	// hold expired at or before now and whose expiry is not postponed past
+	// now, soonest due first.
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]models.Payment, error)
+	// PostponeExpiry keeps the payment out of ListExpiredHolds until until.
+	PostponeExpiry(ctx context.Context, bookingID string, until time.Time) error
+}
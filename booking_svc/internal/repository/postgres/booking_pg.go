@@ -2,10 +2,17 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/promo"
 	"booking_svc/internal/repository"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,42 +24,281 @@ func NewBookingRepo(pool *pgxpool.Pool) *BookingRepoPG {
 	return &BookingRepoPG{pool: pool}
 }
 
+const bookingColumns = `booking_id, rider_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price_amount, price_currency, ride_status, driver_id, scheduled_for, fare_amount, fare_currency, promo_code, discount_amount, arrived_at, cancellation_reason, cancellation_fee, cancelled_at, vehicle_type, passengers, created_at`
+
+func scanBooking(row pgx.Row) (models.Booking, error) {
+	var b models.Booking
+	var status string
+	var riderID, fareCurrency, cancelReason, vehicleType *string
+	var fareAmount, discountAmount, cancelFee *int64
+	var cancelledAt *time.Time
+	if err := row.Scan(
+		&b.BookingID, &riderID,
+		&b.PickupLoc.Lat, &b.PickupLoc.Lng,
+		&b.Dropoff.Lat, &b.Dropoff.Lng,
+		&b.Price.Amount, &b.Price.Currency, &status, &b.DriverID, &b.ScheduledFor,
+		&fareAmount, &fareCurrency, &b.PromoCode, &discountAmount,
+		&b.ArrivedAt, &cancelReason, &cancelFee, &cancelledAt, &vehicleType, &b.Passengers, &b.CreatedAt,
+	); err != nil {
+		return models.Booking{}, err
+	}
+	if riderID != nil {
+		b.RiderID = *riderID
+	}
+	if vehicleType != nil {
+		b.VehicleType = models.VehicleType(*vehicleType)
+	}
+	if fareAmount != nil && fareCurrency != nil {
+		b.Fare = &money.Money{Amount: *fareAmount, Currency: *fareCurrency}
+	}
+	if discountAmount != nil {
+		// A discount is always in the price's currency.
+		b.Discount = &money.Money{Amount: *discountAmount, Currency: b.Price.Currency}
+	}
+	if cancelReason != nil && cancelFee != nil && cancelledAt != nil {
+		b.Cancellation = &models.Cancellation{
+			Reason:      models.CancellationReason(*cancelReason),
+			Fee:         money.Money{Amount: *cancelFee, Currency: b.Price.Currency},
+			CancelledAt: *cancelledAt,
+		}
+	}
+	b.RideStatus = models.RideStatus(status)
+	return b, nil
+}
+
 func (r *BookingRepoPG) Create(ctx context.Context, p repository.CreateBookingParams) (models.Booking, error) {
 	const q = `
 INSERT INTO bookings
-  (booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price, ride_status, driver_id)
+  (booking_id, rider_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price_amount, price_currency, ride_status, driver_id, promo_code, discount_amount, scheduled_for, vehicle_type, passengers)
 VALUES
-  ($1,$2,$3,$4,$5,$6,$7,$8)
-RETURNING booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price, ride_status, driver_id, created_at;
+  ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+RETURNING ` + bookingColumns + `;
 `
-	row := r.pool.QueryRow(ctx, q,
-		p.BookingID,
+	var code, vehicleType *string
+	var discount *int64
+	if p.Promo != nil {
+		code, discount = &p.Promo.Code, &p.Promo.Discount.Amount
+	}
+	if p.VehicleType != "" {
+		vt := string(p.VehicleType)
+		vehicleType = &vt
+	}
+	args := []any{
+		p.BookingID, p.RiderID,
 		p.PickupLoc.Lat, p.PickupLoc.Lng,
 		p.Dropoff.Lat, p.Dropoff.Lng,
-		p.Price, string(p.RideStatus), p.DriverID,
-	)
+		p.Price.Amount, p.Price.Currency, string(p.RideStatus), p.DriverID,
+		code, discount, p.ScheduledFor, vehicleType, p.Passengers,
+	}
+	if p.Promo == nil && len(p.Stops) == 0 {
+		return scanBooking(r.pool.QueryRow(ctx, q, args...))
+	}
 
 	var b models.Booking
-	var status string
-	if err := row.Scan(
-		&b.BookingID,
-		&b.PickupLoc.Lat, &b.PickupLoc.Lng,
-		&b.Dropoff.Lat, &b.Dropoff.Lng,
-		&b.Price, &status, &b.DriverID, &b.CreatedAt,
-	); err != nil {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if p.Promo != nil {
+			if err := redeemPromotion(ctx, tx, p.Promo.Code, p.RiderID); err != nil {
+				return err
+			}
+		}
+		var err error
+		if b, err = scanBooking(tx.QueryRow(ctx, q, args...)); err != nil {
+			return err
+		}
+		if p.Promo != nil {
+			const insertRedemption = `
+INSERT INTO promo_redemptions (booking_id, code, rider_id, discount_amount, currency)
+VALUES ($1,$2,$3,$4,$5);
+`
+			if _, err := tx.Exec(ctx, insertRedemption, p.BookingID, p.Promo.Code, p.RiderID, p.Promo.Discount.Amount, p.Promo.Discount.Currency); err != nil {
+				return err
+			}
+		}
+		const insertStop = `INSERT INTO booking_stops (booking_id, seq, lat, lng) VALUES ($1,$2,$3,$4);`
+		for i, s := range p.Stops {
+			if _, err := tx.Exec(ctx, insertStop, p.BookingID, i+1, s.Lat, s.Lng); err != nil {
+				return err
+			}
+			b.Stops = append(b.Stops, models.Stop{Location: s})
+		}
+		return nil
+	})
+	if err != nil {
 		return models.Booking{}, err
 	}
-	b.RideStatus = models.RideStatus(status)
 	return b, nil
 }
 
+// loadStops fills in the stops of bs.
+func (r *BookingRepoPG) loadStops(ctx context.Context, bs []models.Booking) error {
+	if len(bs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(bs))
+	byID := make(map[string]int, len(bs))
+	for i, b := range bs {
+		ids[i] = b.BookingID
+		byID[b.BookingID] = i
+	}
+	const q = `
+SELECT booking_id, lat, lng, arrived_at
+FROM booking_stops
+WHERE booking_id = ANY($1)
+ORDER BY booking_id, seq;
+`
+	rows, err := r.pool.Query(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var s models.Stop
+		if err := rows.Scan(&id, &s.Lat, &s.Lng, &s.ArrivedAt); err != nil {
+			return err
+		}
+		i := byID[id]
+		bs[i].Stops = append(bs[i].Stops, s)
+	}
+	return rows.Err()
+}
+
+// redeemPromotion uses one of code's redemptions for riderID within tx. The
+// promotion's row lock serializes concurrent redemptions of the same code, so
+// both limits hold under concurrency.
+func redeemPromotion(ctx context.Context, tx pgx.Tx, code, riderID string) error {
+	const lock = `
+SELECT max_redemptions, max_per_rider, redemptions
+FROM promotions
+WHERE code = $1
+FOR UPDATE;
+`
+	const countRider = `SELECT COUNT(*) FROM promo_redemptions WHERE code = $1 AND rider_id = $2;`
+	const use = `UPDATE promotions SET redemptions = redemptions + 1 WHERE code = $1;`
+
+	var maxTotal, maxPerRider, used int
+	err := tx.QueryRow(ctx, lock, code).Scan(&maxTotal, &maxPerRider, &used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %q", promo.ErrUnknownCode, code)
+	}
+	if err != nil {
+		return err
+	}
+	if maxTotal > 0 && used >= maxTotal {
+		return promo.ErrExhausted
+	}
+	if maxPerRider > 0 {
+		var n int
+		if err := tx.QueryRow(ctx, countRider, code, riderID).Scan(&n); err != nil {
+			return err
+		}
+		if n >= maxPerRider {
+			return promo.ErrAlreadyRedeemed
+		}
+	}
+	_, err = tx.Exec(ctx, use, code)
+	return err
+}
+
 func (r *BookingRepoPG) ListAll(ctx context.Context) ([]models.Booking, error) {
 	const q = `
-SELECT booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price, ride_status, driver_id, created_at
+SELECT ` + bookingColumns + `
 FROM bookings
 ORDER BY created_at DESC;
 `
-	rows, err := r.pool.Query(ctx, q)
+	return r.queryBookings(ctx, q)
+}
+
+func (r *BookingRepoPG) ListByRider(ctx context.Context, riderID string) ([]models.Booking, error) {
+	const q = `
+SELECT ` + bookingColumns + `
+FROM bookings
+WHERE rider_id = $1
+ORDER BY created_at DESC;
+`
+	return r.queryBookings(ctx, q, riderID)
+}
+
+func (r *BookingRepoPG) GetByID(ctx context.Context, bookingID string) (models.Booking, bool, error) {
+	const q = `SELECT ` + bookingColumns + ` FROM bookings WHERE booking_id = $1;`
+	b, err := scanBooking(r.pool.QueryRow(ctx, q, bookingID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Booking{}, false, nil
+	}
+	if err != nil {
+		return models.Booking{}, false, err
+	}
+	bs := []models.Booking{b}
+	if err := r.loadStops(ctx, bs); err != nil {
+		return models.Booking{}, false, err
+	}
+	return bs[0], true, nil
+}
+
+func (r *BookingRepoPG) ListCreatedSince(ctx context.Context, since time.Time, limit int) ([]models.Booking, error) {
+	const q = `
+SELECT ` + bookingColumns + `
+FROM bookings
+WHERE created_at >= $1
+ORDER BY created_at ASC, booking_id ASC
+LIMIT $2;
+`
+	return r.queryBookings(ctx, q, since, limit)
+}
+
+func (r *BookingRepoPG) ListScheduled(ctx context.Context, riderID string) ([]models.Booking, error) {
+	const q = `
+SELECT ` + bookingColumns + `
+FROM bookings
+WHERE ride_status = 'Scheduled' AND ($1 = '' OR rider_id = $1)
+ORDER BY scheduled_for ASC, booking_id ASC;
+`
+	return r.queryBookings(ctx, q, riderID)
+}
+
+func (r *BookingRepoPG) ClaimDueScheduled(ctx context.Context, dueBy, now time.Time, lease time.Duration, limit int) ([]models.Booking, error) {
+	const q = `
+UPDATE bookings
+SET release_claimed_until = $3
+WHERE booking_id IN (
+  SELECT booking_id FROM bookings
+  WHERE ride_status = 'Scheduled' AND scheduled_for <= $1
+    AND (release_claimed_until IS NULL OR release_claimed_until <= $2)
+  ORDER BY scheduled_for, booking_id
+  LIMIT $4
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + bookingColumns + `;
+`
+	out, err := r.queryBookings(ctx, q, dueBy, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the subquery's order.
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ScheduledFor.Equal(*out[j].ScheduledFor) {
+			return out[i].ScheduledFor.Before(*out[j].ScheduledFor)
+		}
+		return out[i].BookingID < out[j].BookingID
+	})
+	return out, nil
+}
+
+func (r *BookingRepoPG) MarkReleased(ctx context.Context, bookingID string) (bool, error) {
+	const q = `
+UPDATE bookings
+SET ride_status = 'Requested', release_claimed_until = NULL
+WHERE booking_id = $1 AND ride_status = 'Scheduled';
+`
+	cmd, err := r.pool.Exec(ctx, q, bookingID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *BookingRepoPG) queryBookings(ctx context.Context, q string, args ...any) ([]models.Booking, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,22 +306,19 @@ ORDER BY created_at DESC;
 
 	bookings := make([]models.Booking, 0, 32)
 	for rows.Next() {
-		var b models.Booking
-		var status string
-		if err := rows.Scan(
-			&b.BookingID,
-			&b.PickupLoc.Lat, &b.PickupLoc.Lng,
-			&b.Dropoff.Lat, &b.Dropoff.Lng,
-			&b.Price, &status, &b.DriverID, &b.CreatedAt,
-		); err != nil {
+		b, err := scanBooking(rows)
+		if err != nil {
 			return nil, err
 		}
-		b.RideStatus = models.RideStatus(status)
 		bookings = append(bookings, b)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+	if err := r.loadStops(ctx, bookings); err != nil {
+		return nil, err
+	}
 	return bookings, nil
 }
 
@@ -91,3 +334,57 @@ WHERE booking_id = $2 AND ride_status = 'Requested';
 	}
 	return cmd.RowsAffected() == 1, nil
 }
+
+func (r *BookingRepoPG) MarkCompleted(ctx context.Context, bookingID string, fare money.Money) (bool, error) {
+	const q = `
+UPDATE bookings
+SET ride_status = 'Completed', fare_amount = $1, fare_currency = $2
+WHERE booking_id = $3 AND ride_status = 'Accepted';
+`
+	cmd, err := r.pool.Exec(ctx, q, fare.Amount, fare.Currency, bookingID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *BookingRepoPG) MarkArrived(ctx context.Context, bookingID string, at time.Time) (bool, error) {
+	const q = `
+UPDATE bookings
+SET arrived_at = $1
+WHERE booking_id = $2 AND ride_status = 'Accepted' AND arrived_at IS NULL;
+`
+	cmd, err := r.pool.Exec(ctx, q, at, bookingID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *BookingRepoPG) MarkStopArrived(ctx context.Context, bookingID string, seq int, at time.Time) (bool, error) {
+	const q = `
+UPDATE booking_stops s
+SET arrived_at = $3
+FROM bookings b
+WHERE s.booking_id = $1 AND s.seq = $2 AND s.arrived_at IS NULL
+  AND b.booking_id = s.booking_id AND b.ride_status = 'Accepted';
+`
+	cmd, err := r.pool.Exec(ctx, q, bookingID, seq, at)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *BookingRepoPG) MarkCancelled(ctx context.Context, bookingID string, from models.RideStatus, c models.Cancellation) (bool, error) {
+	const q = `
+UPDATE bookings
+SET ride_status = 'Cancelled', cancellation_reason = $1, cancellation_fee = $2, cancelled_at = $3
+WHERE booking_id = $4 AND ride_status = $5 AND ride_status IN ('Scheduled', 'Requested', 'Accepted');
+`
+	cmd, err := r.pool.Exec(ctx, q, string(c.Reason), c.Fee.Amount, c.CancelledAt, bookingID, string(from))
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking_svc/internal/ledger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LedgerRepoPG struct {
+	pool *pgxpool.Pool
+}
+
+func NewLedgerRepo(pool *pgxpool.Pool) *LedgerRepoPG {
+	return &LedgerRepoPG{pool: pool}
+}
+
+// errDuplicateEntry rolls back PostEntry when the reference is already posted.
+var errDuplicateEntry = errors.New("ledger entry already posted")
+
+func (r *LedgerRepoPG) PostEntry(ctx context.Context, e ledger.Entry) (bool, error) {
+	const insertEntry = `
+INSERT INTO ledger_entries (id, reference, description, occurred_at)
+VALUES ($1,$2,$3,$4)
+ON CONFLICT (reference) DO NOTHING;
+`
+	const openAccount = `
+INSERT INTO ledger_accounts (code, type, currency)
+VALUES ($1,$2,$3)
+ON CONFLICT (code) DO NOTHING;
+`
+	const getAccount = `SELECT type, currency FROM ledger_accounts WHERE code = $1;`
+	const insertPosting = `
+INSERT INTO ledger_postings (entry_id, account, side, amount, currency)
+VALUES ($1,$2,$3,$4,$5);
+`
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		cmd, err := tx.Exec(ctx, insertEntry, e.ID, e.Reference, e.Description, e.OccurredAt)
+		if err != nil {
+			return err
+		}
+		if cmd.RowsAffected() == 0 {
+			return errDuplicateEntry
+		}
+		for _, p := range e.Postings {
+			if _, err := tx.Exec(ctx, openAccount, p.Account, string(p.AccountType), p.Amount.Currency); err != nil {
+				return err
+			}
+			var typ, currency string
+			if err := tx.QueryRow(ctx, getAccount, p.Account).Scan(&typ, &currency); err != nil {
+				return err
+			}
+			if ledger.AccountType(typ) != p.AccountType || currency != p.Amount.Currency {
+				return fmt.Errorf("%w: %s is a %s account in %s, posting is %s in %s",
+					ledger.ErrAccountMismatch, p.Account, typ, currency, p.AccountType, p.Amount.Currency)
+			}
+			if _, err := tx.Exec(ctx, insertPosting, e.ID, p.Account, string(p.Side), p.Amount.Amount, p.Amount.Currency); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errDuplicateEntry) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *LedgerRepoPG) GetEntry(ctx context.Context, reference string) (ledger.Entry, bool, error) {
+	const getEntry = `
+SELECT id, reference, description, occurred_at, created_at
+FROM ledger_entries WHERE reference = $1;
+`
+	const listPostings = `
+SELECT p.account, a.type, p.side, p.amount, p.currency
+FROM ledger_postings p
+JOIN ledger_accounts a ON a.code = p.account
+WHERE p.entry_id = $1
+ORDER BY p.id;
+`
+	var e ledger.Entry
+	err := r.pool.QueryRow(ctx, getEntry, reference).Scan(&e.ID, &e.Reference, &e.Description, &e.OccurredAt, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ledger.Entry{}, false, nil
+	}
+	if err != nil {
+		return ledger.Entry{}, false, err
+	}
+	rows, err := r.pool.Query(ctx, listPostings, e.ID)
+	if err != nil {
+		return ledger.Entry{}, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p ledger.Posting
+		var typ, side string
+		if err := rows.Scan(&p.Account, &typ, &side, &p.Amount.Amount, &p.Amount.Currency); err != nil {
+			return ledger.Entry{}, false, err
+		}
+		p.AccountType, p.Side = ledger.AccountType(typ), ledger.Side(side)
+		e.Postings = append(e.Postings, p)
+	}
+	if err := rows.Err(); err != nil {
+		return ledger.Entry{}, false, err
+	}
+	return e, true, nil
+}
+
+func (r *LedgerRepoPG) GetAccount(ctx context.Context, code string) (ledger.Account, bool, error) {
+	const q = `SELECT code, type, currency, created_at FROM ledger_accounts WHERE code = $1;`
+	var a ledger.Account
+	var typ string
+	err := r.pool.QueryRow(ctx, q, code).Scan(&a.Code, &typ, &a.Currency, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ledger.Account{}, false, nil
+	}
+	if err != nil {
+		return ledger.Account{}, false, err
+	}
+	a.Type = ledger.AccountType(typ)
+	return a, true, nil
+}
+
+func (r *LedgerRepoPG) SumPostings(ctx context.Context, account string, before time.Time) (ledger.Totals, error) {
+	const q = `
+SELECT COALESCE(SUM(p.amount) FILTER (WHERE p.side = 'debit'), 0),
+       COALESCE(SUM(p.amount) FILTER (WHERE p.side = 'credit'), 0)
+FROM ledger_postings p
+JOIN ledger_entries e ON e.id = p.entry_id
+WHERE p.account = $1 AND e.occurred_at < $2;
+`
+	var t ledger.Totals
+	err := r.pool.QueryRow(ctx, q, account, before).Scan(&t.Debits, &t.Credits)
+	return t, err
+}
+
+func (r *LedgerRepoPG) ListLines(ctx context.Context, account string, from, to time.Time) ([]ledger.Line, error) {
+	const q = `
+SELECT e.id, e.reference, e.description, e.occurred_at, p.side, p.amount, p.currency
+FROM ledger_postings p
+JOIN ledger_entries e ON e.id = p.entry_id
+WHERE p.account = $1 AND e.occurred_at >= $2 AND e.occurred_at < $3
+ORDER BY e.occurred_at, p.id;
+`
+	rows, err := r.pool.Query(ctx, q, account, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ledger.Line, 0, 16)
+	for rows.Next() {
+		var l ledger.Line
+		var side string
+		if err := rows.Scan(&l.EntryID, &l.Reference, &l.Description, &l.OccurredAt, &side, &l.Amount.Amount, &l.Amount.Currency); err != nil {
+			return nil, err
+		}
+		l.Side = ledger.Side(side)
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentRepoPG struct {
+	pool *pgxpool.Pool
+}
+
+func NewPaymentRepo(pool *pgxpool.Pool) *PaymentRepoPG {
+	return &PaymentRepoPG{pool: pool}
+}
+
+const paymentColumns = `booking_id, rider_id, status, amount, currency, captured_amount, authorization_id, failure_reason, expires_at, created_at, updated_at`
+
+func scanPayment(row pgx.Row) (models.Payment, error) {
+	var p models.Payment
+	var status string
+	var riderID, authID, reason *string
+	var captured *int64
+	if err := row.Scan(
+		&p.BookingID, &riderID, &status, &p.Amount.Amount, &p.Amount.Currency,
+		&captured, &authID, &reason, &p.ExpiresAt, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return models.Payment{}, err
+	}
+	p.Status = models.PaymentStatus(status)
+	if riderID != nil {
+		p.RiderID = *riderID
+	}
+	if captured != nil {
+		p.Captured = &money.Money{Amount: *captured, Currency: p.Amount.Currency}
+	}
+	if authID != nil {
+		p.AuthorizationID = *authID
+	}
+	if reason != nil {
+		p.FailureReason = *reason
+	}
+	return p, nil
+}
+
+func (r *PaymentRepoPG) CreatePayment(ctx context.Context, p repository.CreatePaymentParams) (models.Payment, error) {
+	const q = `
+INSERT INTO payments (booking_id, rider_id, status, amount, currency, expires_at)
+VALUES ($1,$2,'pending',$3,$4,$5)
+RETURNING ` + paymentColumns + `;
+`
+	return scanPayment(r.pool.QueryRow(ctx, q, p.BookingID, p.RiderID, p.Amount.Amount, p.Amount.Currency, p.ExpiresAt))
+}
+
+func (r *PaymentRepoPG) GetPayment(ctx context.Context, bookingID string) (models.Payment, bool, error) {
+	const q = `SELECT ` + paymentColumns + ` FROM payments WHERE booking_id = $1;`
+	p, err := scanPayment(r.pool.QueryRow(ctx, q, bookingID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Payment{}, false, nil
+	}
+	if err != nil {
+		return models.Payment{}, false, err
+	}
+	return p, true, nil
+}
+
+func (r *PaymentRepoPG) UpdatePayment(ctx context.Context, bookingID string, from models.PaymentStatus, u repository.PaymentUpdate) (bool, error) {
+	const q = `
+UPDATE payments
+SET status = $3,
+    authorization_id = COALESCE($4, authorization_id),
+    captured_amount = COALESCE($5, captured_amount),
+    failure_reason = COALESCE($6, failure_reason),
+    updated_at = NOW()
+WHERE booking_id = $1 AND status = $2;
+`
+	var captured *int64
+	if u.Captured != nil {
+		captured = &u.Captured.Amount
+	}
+	cmd, err := r.pool.Exec(ctx, q, bookingID, string(from), string(u.Status), u.AuthorizationID, captured, u.FailureReason)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *PaymentRepoPG) RecordAttempt(ctx context.Context, p repository.RecordPaymentAttemptParams) error {
+	const q = `
+INSERT INTO payment_attempts (booking_id, operation, idempotency_key, succeeded, error)
+VALUES ($1,$2,$3,$4,$5);
+`
+	_, err := r.pool.Exec(ctx, q, p.BookingID, string(p.Operation), p.IdempotencyKey, p.Succeeded, p.Error)
+	return err
+}
+
+func (r *PaymentRepoPG) ListAttempts(ctx context.Context, bookingID string) ([]models.PaymentAttempt, error) {
+	const q = `
+SELECT id, operation, idempotency_key, succeeded, error, created_at
+FROM payment_attempts
+WHERE booking_id = $1
+ORDER BY id;
+`
+	rows, err := r.pool.Query(ctx, q, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.PaymentAttempt, 0, 4)
+	for rows.Next() {
+		var a models.PaymentAttempt
+		var op string
+		if err := rows.Scan(&a.ID, &op, &a.IdempotencyKey, &a.Succeeded, &a.Error, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Operation = models.PaymentOperation(op)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *PaymentRepoPG) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]models.Payment, error) {
+	const q = `
+SELECT ` + paymentColumns + `
+FROM payments
+WHERE status IN ('pending','authorized') AND GREATEST(expires_at, expiry_retry_at) <= $1
+ORDER BY GREATEST(expires_at, expiry_retry_at), booking_id
+LIMIT $2;
+`
+	rows, err := r.pool.Query(ctx, q, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Payment, 0, limit)
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *PaymentRepoPG) PostponeExpiry(ctx context.Context, bookingID string, until time.Time) error {
+	const q = `UPDATE payments SET expiry_retry_at = $2 WHERE booking_id = $1;`
+	_, err := r.pool.Exec(ctx, q, bookingID, until)
+	return err
+}
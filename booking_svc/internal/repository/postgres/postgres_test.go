@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"booking_svc/internal/db"
+	"booking_svc/internal/repository"
+	"booking_svc/internal/repository/repotest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to TEST_DATABASE_URL and migrates it; the suite is skipped
+// when the variable is unset. The database is wiped before every case, so
+// never point it at anything but a scratch database.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	migrator, err := db.NewMigrator(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+func truncate(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), `TRUNCATE promo_redemptions, booking_stops, bookings, promotions, webhook_subscriptions, webhook_deliveries, payments, payment_attempts, ledger_accounts, ledger_entries, ledger_postings, ratings;`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBookingRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.BookingRepository(t, func(t *testing.T) repository.BookingRepository {
+		truncate(t, pool)
+		return NewBookingRepo(pool)
+	})
+}
+
+func TestWebhookRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.WebhookRepository(t, func(t *testing.T) repository.WebhookRepository {
+		truncate(t, pool)
+		return NewWebhookRepo(pool)
+	})
+}
+
+func TestPaymentRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.PaymentRepository(t, func(t *testing.T) repository.PaymentRepository {
+		truncate(t, pool)
+		return NewPaymentRepo(pool)
+	})
+}
+
+func TestLedgerRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.LedgerRepository(t, func(t *testing.T) repository.LedgerRepository {
+		truncate(t, pool)
+		return NewLedgerRepo(pool)
+	})
+}
+
+func TestPromotionRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.PromotionRepository(t, func(t *testing.T) (repository.PromotionRepository, repository.BookingRepository) {
+		truncate(t, pool)
+		return NewPromotionRepo(pool), NewBookingRepo(pool)
+	})
+}
+
+func TestRatingRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.RatingRepository(t, func(t *testing.T) repository.RatingRepository {
+		truncate(t, pool)
+		return NewRatingRepo(pool)
+	})
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/promo"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PromotionRepoPG struct {
+	pool *pgxpool.Pool
+}
+
+func NewPromotionRepo(pool *pgxpool.Pool) *PromotionRepoPG {
+	return &PromotionRepoPG{pool: pool}
+}
+
+const promotionColumns = `code, description, kind, percent_bps, amount_off, max_discount, currency, max_redemptions, max_per_rider, redemptions, starts_at, ends_at, area_lat, area_lng, area_radius_km, created_at`
+
+func scanPromotion(row pgx.Row) (promo.Promotion, error) {
+	var p promo.Promotion
+	var kind string
+	var amountOff, maxDiscount *int64
+	var currency *string
+	var lat, lng, radius *float64
+	if err := row.Scan(
+		&p.Code, &p.Description, &kind, &p.PercentBPS, &amountOff, &maxDiscount, &currency,
+		&p.MaxRedemptions, &p.MaxPerRider, &p.Redemptions, &p.StartsAt, &p.EndsAt,
+		&lat, &lng, &radius, &p.CreatedAt,
+	); err != nil {
+		return promo.Promotion{}, err
+	}
+	p.Kind = promo.Kind(kind)
+	if currency != nil {
+		if amountOff != nil {
+			p.AmountOff = &money.Money{Amount: *amountOff, Currency: *currency}
+		}
+		if maxDiscount != nil {
+			p.MaxDiscount = &money.Money{Amount: *maxDiscount, Currency: *currency}
+		}
+	}
+	if lat != nil && lng != nil && radius != nil {
+		p.Area = &promo.Area{Center: models.Location{Lat: *lat, Lng: *lng}, RadiusKm: *radius}
+	}
+	return p, nil
+}
+
+func (r *PromotionRepoPG) CreatePromotion(ctx context.Context, p promo.Promotion) (promo.Promotion, bool, error) {
+	const q = `
+INSERT INTO promotions
+  (code, description, kind, percent_bps, amount_off, max_discount, currency, max_redemptions, max_per_rider, starts_at, ends_at, area_lat, area_lng, area_radius_km)
+VALUES
+  ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+ON CONFLICT (code) DO NOTHING
+RETURNING ` + promotionColumns + `;
+`
+	var amountOff, maxDiscount *int64
+	var currency *string
+	// Validate allows one amount per promotion, so they share a currency column.
+	if p.AmountOff != nil {
+		amountOff, currency = &p.AmountOff.Amount, &p.AmountOff.Currency
+	}
+	if p.MaxDiscount != nil {
+		maxDiscount, currency = &p.MaxDiscount.Amount, &p.MaxDiscount.Currency
+	}
+	var lat, lng, radius *float64
+	if p.Area != nil {
+		lat, lng, radius = &p.Area.Center.Lat, &p.Area.Center.Lng, &p.Area.RadiusKm
+	}
+	created, err := scanPromotion(r.pool.QueryRow(ctx, q,
+		p.Code, p.Description, string(p.Kind), p.PercentBPS, amountOff, maxDiscount, currency,
+		p.MaxRedemptions, p.MaxPerRider, p.StartsAt, p.EndsAt, lat, lng, radius,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return promo.Promotion{}, false, nil
+	}
+	if err != nil {
+		return promo.Promotion{}, false, err
+	}
+	return created, true, nil
+}
+
+func (r *PromotionRepoPG) GetPromotion(ctx context.Context, code string) (promo.Promotion, bool, error) {
+	const q = `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1;`
+	p, err := scanPromotion(r.pool.QueryRow(ctx, q, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return promo.Promotion{}, false, nil
+	}
+	if err != nil {
+		return promo.Promotion{}, false, err
+	}
+	return p, true, nil
+}
+
+func (r *PromotionRepoPG) ListPromotions(ctx context.Context) ([]promo.Promotion, error) {
+	const q = `SELECT ` + promotionColumns + ` FROM promotions ORDER BY created_at DESC;`
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]promo.Promotion, 0, 16)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *PromotionRepoPG) CountRiderRedemptions(ctx context.Context, code, riderID string) (int, error) {
+	const q = `SELECT COUNT(*) FROM promo_redemptions WHERE code = $1 AND rider_id = $2;`
+	var n int
+	err := r.pool.QueryRow(ctx, q, code, riderID).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"booking_svc/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingRepoPG struct {
+	pool *pgxpool.Pool
+}
+
+func NewRatingRepo(pool *pgxpool.Pool) *RatingRepoPG {
+	return &RatingRepoPG{pool: pool}
+}
+
+const ratingColumns = `booking_id, rater, rater_id, ratee_id, stars, comment, created_at`
+
+func scanRating(row pgx.Row) (models.Rating, error) {
+	var r models.Rating
+	var rater string
+	if err := row.Scan(&r.BookingID, &rater, &r.RaterID, &r.RateeID, &r.Stars, &r.Comment, &r.CreatedAt); err != nil {
+		return models.Rating{}, err
+	}
+	r.Rater = models.RatingSide(rater)
+	return r, nil
+}
+
+func (r *RatingRepoPG) CreateRating(ctx context.Context, rt models.Rating) (models.Rating, bool, error) {
+	const insert = `
+INSERT INTO ratings (booking_id, rater, rater_id, ratee_id, stars, comment)
+VALUES ($1,$2,$3,$4,$5,$6)
+ON CONFLICT (booking_id, rater) DO NOTHING
+RETURNING ` + ratingColumns + `;
+`
+	created, err := scanRating(r.pool.QueryRow(ctx, insert,
+		rt.BookingID, string(rt.Rater), rt.RaterID, rt.RateeID, rt.Stars, rt.Comment,
+	))
+	if err == nil {
+		return created, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.Rating{}, false, err
+	}
+	const existing = `SELECT ` + ratingColumns + ` FROM ratings WHERE booking_id = $1 AND rater = $2;`
+	got, err := scanRating(r.pool.QueryRow(ctx, existing, rt.BookingID, string(rt.Rater)))
+	if err != nil {
+		return models.Rating{}, false, err
+	}
+	return got, false, nil
+}
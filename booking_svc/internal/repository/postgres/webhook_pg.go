@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepoPG struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepo(pool *pgxpool.Pool) *WebhookRepoPG {
+	return &WebhookRepoPG{pool: pool}
+}
+
+const subscriptionColumns = `id, url, event_types, secret, created_at`
+
+func scanSubscription(row pgx.Row) (models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	err := row.Scan(&s.ID, &s.URL, &s.EventTypes, &s.Secret, &s.CreatedAt)
+	return s, err
+}
+
+func (r *WebhookRepoPG) CreateSubscription(ctx context.Context, p repository.CreateSubscriptionParams) (models.WebhookSubscription, error) {
+	const q = `
+INSERT INTO webhook_subscriptions (id, url, event_types, secret)
+VALUES ($1,$2,$3,$4)
+RETURNING ` + subscriptionColumns + `;
+`
+	return scanSubscription(r.pool.QueryRow(ctx, q, p.ID, p.URL, p.EventTypes, p.Secret))
+}
+
+func (r *WebhookRepoPG) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	const q = `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at DESC;`
+	return r.querySubscriptions(ctx, q)
+}
+
+func (r *WebhookRepoPG) ListSubscriptionsForEvent(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	const q = `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE $1 = ANY(event_types) ORDER BY created_at;`
+	return r.querySubscriptions(ctx, q, eventType)
+}
+
+func (r *WebhookRepoPG) querySubscriptions(ctx context.Context, q string, args ...any) ([]models.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]models.WebhookSubscription, 0, 8)
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (r *WebhookRepoPG) GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, bool, error) {
+	const q = `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1;`
+	s, err := scanSubscription(r.pool.QueryRow(ctx, q, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return models.WebhookSubscription{}, false, nil
+		}
+		return models.WebhookSubscription{}, false, err
+	}
+	return s, true, nil
+}
+
+func (r *WebhookRepoPG) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	cmd, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1;`, id)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *WebhookRepoPG) EnqueueDeliveries(ctx context.Context, ps []repository.EnqueueDeliveryParams) error {
+	const q = `
+INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status)
+VALUES ($1,$2,$3,$4,$5,'pending')
+ON CONFLICT (id) DO NOTHING;
+`
+	batch := &pgx.Batch{}
+	for _, p := range ps {
+		batch.Queue(q, p.ID, p.SubscriptionID, p.EventID, p.EventType, []byte(p.Payload))
+	}
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+func scanDelivery(row pgx.Row) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var status string
+	var payload []byte
+	if err := row.Scan(
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &status,
+		&d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d.Status = models.DeliveryStatus(status)
+	d.Payload = payload
+	return d, nil
+}
+
+func (r *WebhookRepoPG) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	const q = `
+UPDATE webhook_deliveries
+SET next_attempt_at = $2
+WHERE id IN (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending' AND next_attempt_at <= $1
+  ORDER BY next_attempt_at
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + deliveryColumns + `;
+`
+	rows, err := r.pool.Query(ctx, q, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *WebhookRepoPG) RecordAttempt(ctx context.Context, deliveryID string, res repository.DeliveryAttemptResult) error {
+	const q = `
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = $3,
+    status = CASE WHEN $4 THEN 'succeeded' WHEN $5::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+    next_attempt_at = COALESCE($5, next_attempt_at),
+    delivered_at = CASE WHEN $4 THEN NOW() ELSE delivered_at END
+WHERE id = $1;
+`
+	_, err := r.pool.Exec(ctx, q, deliveryID, res.StatusCode, res.Error, res.Succeeded, res.NextAttemptAt)
+	return err
+}
+
+func (r *WebhookRepoPG) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	const q = `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+`
+	rows, err := r.pool.Query(ctx, q, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.WebhookDelivery, 0, 32)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"booking_svc/internal/promo"
+)
+
+// PromotionRepository stores promotions. Redemptions are written by
+// BookingRepository.Create, together with the booking that uses them.
+type PromotionRepository interface {
+	// CreatePromotion stores p with no redemptions. It returns false, storing
+	// nothing, if the code is taken.
+	CreatePromotion(ctx context.Context, p promo.Promotion) (promo.Promotion, bool, error)
+	GetPromotion(ctx context.Context, code string) (promo.Promotion, bool, error)
+	// ListPromotions returns every promotion, newest first.
+	ListPromotions(ctx context.Context) ([]promo.Promotion, error)
+	// CountRiderRedemptions returns how many bookings riderID has redeemed
+	// code on.
+	CountRiderRedemptions(ctx context.Context, code, riderID string) (int, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"booking_svc/internal/models"
+)
+
+type RatingRepository interface {
+	// CreateRating stores r unless its booking already has a rating from
+	// r.Rater, in which case it returns that rating and false.
+	CreateRating(ctx context.Context, r models.Rating) (models.Rating, bool, error)
+}
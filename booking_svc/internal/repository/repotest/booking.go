@@ -0,0 +1,467 @@
+package repotest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/repository"
+)
+
+func newBooking(id, riderID string) repository.CreateBookingParams {
+	return repository.CreateBookingParams{
+		BookingID:  id,
+		RiderID:    riderID,
+		PickupLoc:  models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:    models.Location{Lat: 12.95, Lng: 77.64},
+		Price:      money.Money{Amount: 22050, Currency: "INR"},
+		Passengers: 1,
+		RideStatus: models.RideStatusRequested,
+	}
+}
+
+func freeCancellation() models.Cancellation {
+	return models.Cancellation{
+		Reason:      models.CancellationFree,
+		Fee:         money.Money{Currency: "INR"},
+		CancelledAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func bookingIDs(bs []models.Booking) []string {
+	ids := make([]string, len(bs))
+	for i, b := range bs {
+		ids[i] = b.BookingID
+	}
+	return ids
+}
+
+func equalIDs(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BookingRepository runs the conformance suite against implementations made by newRepo.
+func BookingRepository(t *testing.T, newRepo func(t *testing.T) repository.BookingRepository) {
+	t.Run("create and get", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		created, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+		if created.BookingID != "b-1" || created.RiderID != "r-1" || created.Price != (money.Money{Amount: 22050, Currency: "INR"}) ||
+			created.PickupLoc.Lat != 12.9 || created.Dropoff.Lng != 77.64 ||
+			created.RideStatus != models.RideStatusRequested || created.DriverID != nil || created.CreatedAt.IsZero() {
+			t.Fatalf("unexpected created booking: %+v", created)
+		}
+
+		got, ok, err := repo.GetByID(c, "b-1")
+		must(t, err)
+		if !ok || got.BookingID != created.BookingID || got.RiderID != created.RiderID || !sameInstant(got.CreatedAt, created.CreatedAt) {
+			t.Fatalf("GetByID: ok=%v got=%+v want=%+v", ok, got, created)
+		}
+		if _, ok, err := repo.GetByID(c, "missing"); ok || err != nil {
+			t.Fatalf("GetByID(missing): ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("duplicate id", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+		if _, err := repo.Create(c, newBooking("b-1", "r-2")); err == nil {
+			t.Fatal("second Create with the same id must fail")
+		}
+	})
+
+	t.Run("lists newest first", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		for _, p := range []repository.CreateBookingParams{newBooking("b-1", "r-1"), newBooking("b-2", "r-2"), newBooking("b-3", "r-1")} {
+			_, err := repo.Create(c, p)
+			must(t, err)
+			tick()
+		}
+
+		all, err := repo.ListAll(c)
+		must(t, err)
+		if ids := bookingIDs(all); !equalIDs(ids, "b-3", "b-2", "b-1") {
+			t.Fatalf("ListAll: %v", ids)
+		}
+		mine, err := repo.ListByRider(c, "r-1")
+		must(t, err)
+		if ids := bookingIDs(mine); !equalIDs(ids, "b-3", "b-1") {
+			t.Fatalf("ListByRider: %v", ids)
+		}
+		none, err := repo.ListByRider(c, "r-9")
+		must(t, err)
+		if len(none) != 0 {
+			t.Fatalf("ListByRider(unknown): %v", bookingIDs(none))
+		}
+	})
+
+	t.Run("list created since, oldest first", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		var created []models.Booking
+		for _, id := range []string{"b-1", "b-2", "b-3", "b-4"} {
+			b, err := repo.Create(c, newBooking(id, "r-1"))
+			must(t, err)
+			created = append(created, b)
+			tick()
+		}
+
+		all, err := repo.ListCreatedSince(c, created[0].CreatedAt.Add(-time.Second), 10)
+		must(t, err)
+		if ids := bookingIDs(all); !equalIDs(ids, "b-1", "b-2", "b-3", "b-4") {
+			t.Fatalf("ListCreatedSince: %v", ids)
+		}
+		// since is inclusive, so a page can start at the last seen created_at.
+		page, err := repo.ListCreatedSince(c, created[1].CreatedAt, 2)
+		must(t, err)
+		if ids := bookingIDs(page); !equalIDs(ids, "b-2", "b-3") {
+			t.Fatalf("ListCreatedSince page: %v", ids)
+		}
+		none, err := repo.ListCreatedSince(c, created[3].CreatedAt.Add(time.Second), 10)
+		must(t, err)
+		if len(none) != 0 {
+			t.Fatalf("ListCreatedSince(future): %v", bookingIDs(none))
+		}
+	})
+
+	t.Run("mark accepted once", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+
+		ok, err := repo.MarkAccepted(c, "b-1", "d-1")
+		must(t, err)
+		if !ok {
+			t.Fatal("first MarkAccepted must update")
+		}
+		if ok, _ := repo.MarkAccepted(c, "b-1", "d-2"); ok {
+			t.Fatal("second MarkAccepted must be a no-op")
+		}
+		if ok, _ := repo.MarkAccepted(c, "missing", "d-1"); ok {
+			t.Fatal("MarkAccepted on a missing booking must be a no-op")
+		}
+		got, _, err := repo.GetByID(c, "b-1")
+		must(t, err)
+		if got.RideStatus != models.RideStatusAccepted || got.DriverID == nil || *got.DriverID != "d-1" {
+			t.Fatalf("unexpected booking after accept: %+v", got)
+		}
+	})
+
+	t.Run("mark accepted concurrently", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+
+		const n = 8
+		var wg sync.WaitGroup
+		wins := make(chan string, n)
+		for i := 0; i < n; i++ {
+			driverID := string(rune('a' + i))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, err := repo.MarkAccepted(c, "b-1", driverID); err == nil && ok {
+					wins <- driverID
+				}
+			}()
+		}
+		wg.Wait()
+		close(wins)
+		var winners []string
+		for w := range wins {
+			winners = append(winners, w)
+		}
+		got, _, _ := repo.GetByID(c, "b-1")
+		if len(winners) != 1 || got.DriverID == nil || *got.DriverID != winners[0] {
+			t.Fatalf("want exactly one winner stored, got winners=%v booking=%+v", winners, got)
+		}
+	})
+
+	t.Run("complete only accepted bookings", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+		fare := money.Money{Amount: 19900, Currency: "INR"}
+
+		if ok, _ := repo.MarkCompleted(c, "b-1", fare); ok {
+			t.Fatal("MarkCompleted on a Requested booking must be a no-op")
+		}
+		_, err = repo.MarkAccepted(c, "b-1", "d-1")
+		must(t, err)
+		ok, err := repo.MarkCompleted(c, "b-1", fare)
+		must(t, err)
+		if !ok {
+			t.Fatal("MarkCompleted on an Accepted booking must update")
+		}
+		if ok, _ := repo.MarkCompleted(c, "b-1", fare); ok {
+			t.Fatal("second MarkCompleted must be a no-op")
+		}
+		got, _, err := repo.GetByID(c, "b-1")
+		must(t, err)
+		if got.RideStatus != models.RideStatusCompleted || got.Fare == nil || *got.Fare != fare {
+			t.Fatalf("unexpected booking after complete: %+v", got)
+		}
+		for _, from := range []models.RideStatus{models.RideStatusRequested, models.RideStatusAccepted} {
+			if ok, _ := repo.MarkCancelled(c, "b-1", from, freeCancellation()); ok {
+				t.Fatal("MarkCancelled on a Completed booking must be a no-op")
+			}
+		}
+	})
+
+	t.Run("cancel requested or accepted bookings once", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		for _, id := range []string{"b-1", "b-2"} {
+			_, err := repo.Create(c, newBooking(id, "r-1"))
+			must(t, err)
+		}
+		_, err := repo.MarkAccepted(c, "b-2", "d-1")
+		must(t, err)
+
+		from := map[string]models.RideStatus{"b-1": models.RideStatusRequested, "b-2": models.RideStatusAccepted}
+		for _, id := range []string{"b-1", "b-2"} {
+			ok, err := repo.MarkCancelled(c, id, from[id], freeCancellation())
+			must(t, err)
+			if !ok {
+				t.Fatalf("MarkCancelled(%s) must update", id)
+			}
+			if ok, _ := repo.MarkCancelled(c, id, from[id], freeCancellation()); ok {
+				t.Fatalf("second MarkCancelled(%s) must be a no-op", id)
+			}
+			got, _, err := repo.GetByID(c, id)
+			must(t, err)
+			if got.RideStatus != models.RideStatusCancelled || got.Fare != nil {
+				t.Fatalf("unexpected booking after cancel: %+v", got)
+			}
+		}
+		if ok, _ := repo.MarkAccepted(c, "b-1", "d-2"); ok {
+			t.Fatal("MarkAccepted on a Cancelled booking must be a no-op")
+		}
+		if ok, _ := repo.MarkCancelled(c, "missing", models.RideStatusRequested, freeCancellation()); ok {
+			t.Fatal("MarkCancelled on a missing booking must be a no-op")
+		}
+	})
+
+	t.Run("list and release scheduled bookings", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		pickup := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		for _, r := range []struct {
+			id, rider string
+			hours     int
+		}{{"b-3", "r-1", 3}, {"b-2", "r-2", 1}, {"b-1", "r-1", 1}} {
+			p := newBooking(r.id, r.rider)
+			p.RideStatus = models.RideStatusScheduled
+			at := pickup.Add(time.Duration(r.hours) * time.Hour)
+			p.ScheduledFor = &at
+			_, err := repo.Create(c, p)
+			must(t, err)
+		}
+		_, err := repo.Create(c, newBooking("b-now", "r-1"))
+		must(t, err)
+
+		all, err := repo.ListScheduled(c, "")
+		must(t, err)
+		// b-2 and b-1 share a pickup hour, so booking_id breaks the tie.
+		if !equalIDs(bookingIDs(all), "b-1", "b-2", "b-3") {
+			t.Fatalf("ListScheduled: %v", bookingIDs(all))
+		}
+		mine, err := repo.ListScheduled(c, "r-1")
+		must(t, err)
+		if !equalIDs(bookingIDs(mine), "b-1", "b-3") || mine[1].ScheduledFor == nil || !sameInstant(*mine[1].ScheduledFor, pickup.Add(3*time.Hour)) {
+			t.Fatalf("ListScheduled(r-1): %+v", mine)
+		}
+
+		ok, err := repo.MarkReleased(c, "b-3")
+		must(t, err)
+		if !ok {
+			t.Fatal("MarkReleased on a Scheduled booking must update")
+		}
+		if ok, _ := repo.MarkReleased(c, "b-3"); ok {
+			t.Fatal("second MarkReleased must be a no-op")
+		}
+		if ok, _ := repo.MarkReleased(c, "b-now"); ok {
+			t.Fatal("MarkReleased on a Requested booking must be a no-op")
+		}
+		got, _, err := repo.GetByID(c, "b-3")
+		must(t, err)
+		if got.RideStatus != models.RideStatusRequested || got.ScheduledFor == nil {
+			t.Fatalf("unexpected booking after release: %+v", got)
+		}
+		if ok, _ := repo.MarkAccepted(c, "b-1", "d-1"); ok {
+			t.Fatal("MarkAccepted on a Scheduled booking must be a no-op")
+		}
+		ok, err = repo.MarkCancelled(c, "b-1", models.RideStatusScheduled, freeCancellation())
+		must(t, err)
+		if !ok {
+			t.Fatal("MarkCancelled on a Scheduled booking must update")
+		}
+		if left, _ := repo.ListScheduled(c, ""); !equalIDs(bookingIDs(left), "b-2") {
+			t.Fatalf("only b-2 is still Scheduled, got %v", bookingIDs(left))
+		}
+	})
+
+	t.Run("claim due scheduled bookings under a lease", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		for i, id := range []string{"b-1", "b-2", "b-3"} {
+			p := newBooking(id, "r-1")
+			p.RideStatus = models.RideStatusScheduled
+			at := now.Add(time.Duration(i*10) * time.Minute)
+			p.ScheduledFor = &at
+			_, err := repo.Create(c, p)
+			must(t, err)
+		}
+		dueBy := now.Add(15 * time.Minute)
+
+		got, err := repo.ClaimDueScheduled(c, dueBy, now, time.Minute, 10)
+		must(t, err)
+		if !equalIDs(bookingIDs(got), "b-1", "b-2") || got[0].RideStatus != models.RideStatusScheduled {
+			t.Fatalf("claim: %+v", got)
+		}
+		if none, _ := repo.ClaimDueScheduled(c, dueBy, now.Add(30*time.Second), time.Minute, 10); len(none) != 0 {
+			t.Fatalf("leased bookings must not be claimed again: %v", bookingIDs(none))
+		}
+		_, err = repo.MarkReleased(c, "b-1")
+		must(t, err)
+		later, err := repo.ClaimDueScheduled(c, dueBy, now.Add(time.Minute), time.Minute, 10)
+		must(t, err)
+		if !equalIDs(bookingIDs(later), "b-2") {
+			t.Fatalf("an expired lease must be claimable and released bookings not, got %v", bookingIDs(later))
+		}
+		one, err := repo.ClaimDueScheduled(c, now.Add(time.Hour), now.Add(5*time.Minute), time.Minute, 1)
+		must(t, err)
+		if !equalIDs(bookingIDs(one), "b-2") {
+			t.Fatalf("limit keeps the soonest, got %v", bookingIDs(one))
+		}
+	})
+
+	t.Run("cancel only from the evaluated status", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+		_, err = repo.MarkAccepted(c, "b-1", "d-1")
+		must(t, err)
+		// The policy priced a Requested booking, but a driver took it since.
+		if ok, _ := repo.MarkCancelled(c, "b-1", models.RideStatusRequested, freeCancellation()); ok {
+			t.Fatal("MarkCancelled from a stale status must be a no-op")
+		}
+		at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+		late := models.Cancellation{Reason: models.CancellationLate, Fee: money.Money{Amount: 2205, Currency: "INR"}, CancelledAt: at}
+		ok, err := repo.MarkCancelled(c, "b-1", models.RideStatusAccepted, late)
+		must(t, err)
+		if !ok {
+			t.Fatal("MarkCancelled from the current status must update")
+		}
+		got, _, err := repo.GetByID(c, "b-1")
+		must(t, err)
+		if got.RideStatus != models.RideStatusCancelled || got.Cancellation == nil ||
+			got.Cancellation.Reason != models.CancellationLate || got.Cancellation.Fee != late.Fee ||
+			!sameInstant(got.Cancellation.CancelledAt, at) {
+			t.Fatalf("unexpected booking after cancel: %+v", got)
+		}
+	})
+
+	t.Run("driver arrives once at an accepted booking", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+		at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+		if ok, _ := repo.MarkArrived(c, "b-1", at); ok {
+			t.Fatal("MarkArrived on a Requested booking must be a no-op")
+		}
+		_, err = repo.MarkAccepted(c, "b-1", "d-1")
+		must(t, err)
+		ok, err := repo.MarkArrived(c, "b-1", at)
+		must(t, err)
+		if !ok {
+			t.Fatal("MarkArrived on an Accepted booking must update")
+		}
+		if ok, _ := repo.MarkArrived(c, "b-1", at.Add(time.Minute)); ok {
+			t.Fatal("second MarkArrived must be a no-op")
+		}
+		got, _, err := repo.GetByID(c, "b-1")
+		must(t, err)
+		if got.ArrivedAt == nil || !sameInstant(*got.ArrivedAt, at) || got.Cancellation != nil {
+			t.Fatalf("unexpected booking after arrival: %+v", got)
+		}
+		if ok, _ := repo.MarkArrived(c, "missing", at); ok {
+			t.Fatal("MarkArrived on a missing booking must be a no-op")
+		}
+	})
+
+	t.Run("stops are kept in order and reached once", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		p := newBooking("b-1", "r-1")
+		p.Stops = []models.Location{{Lat: 12.91, Lng: 77.61}, {Lat: 12.93, Lng: 77.62}}
+		created, err := repo.Create(c, p)
+		must(t, err)
+		_, err = repo.Create(c, newBooking("b-2", "r-1"))
+		must(t, err)
+		if len(created.Stops) != 2 || created.Stops[0].Location != p.Stops[0] || created.Stops[1].Location != p.Stops[1] {
+			t.Fatalf("created stops: %+v", created.Stops)
+		}
+
+		at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+		if ok, _ := repo.MarkStopArrived(c, "b-1", 1, at); ok {
+			t.Fatal("MarkStopArrived on a Requested booking must be a no-op")
+		}
+		_, err = repo.MarkAccepted(c, "b-1", "d-1")
+		must(t, err)
+		ok, err := repo.MarkStopArrived(c, "b-1", 2, at)
+		must(t, err)
+		if !ok {
+			t.Fatal("MarkStopArrived on an Accepted booking must update")
+		}
+		if ok, _ := repo.MarkStopArrived(c, "b-1", 2, at.Add(time.Minute)); ok {
+			t.Fatal("second MarkStopArrived must be a no-op")
+		}
+		for _, seq := range []int{0, 3} {
+			if ok, _ := repo.MarkStopArrived(c, "b-1", seq, at); ok {
+				t.Fatalf("MarkStopArrived on missing stop %d must be a no-op", seq)
+			}
+		}
+
+		got, _, err := repo.GetByID(c, "b-1")
+		must(t, err)
+		if len(got.Stops) != 2 || got.Stops[0].ArrivedAt != nil || got.Stops[1].ArrivedAt == nil || !sameInstant(*got.Stops[1].ArrivedAt, at) {
+			t.Fatalf("stops after arrival: %+v", got.Stops)
+		}
+		all, err := repo.ListByRider(c, "r-1")
+		must(t, err)
+		for _, b := range all {
+			if want := map[string]int{"b-1": 2, "b-2": 0}[b.BookingID]; len(b.Stops) != want {
+				t.Fatalf("%s listed with %d stops, want %d", b.BookingID, len(b.Stops), want)
+			}
+		}
+	})
+
+	t.Run("requested vehicle and passengers are kept", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		p := newBooking("b-1", "r-1")
+		p.VehicleType, p.Passengers = models.VehicleSUV, 5
+		created, err := repo.Create(c, p)
+		must(t, err)
+		_, err = repo.Create(c, newBooking("b-2", "r-1"))
+		must(t, err)
+		if created.VehicleType != models.VehicleSUV || created.Passengers != 5 {
+			t.Fatalf("created: %+v", created)
+		}
+		all, err := repo.ListByRider(c, "r-1")
+		must(t, err)
+		for _, b := range all {
+			want := map[string]models.VehicleType{"b-1": models.VehicleSUV}[b.BookingID]
+			if b.VehicleType != want || b.Passengers != map[string]int{"b-1": 5, "b-2": 1}[b.BookingID] {
+				t.Fatalf("%s listed as %q for %d", b.BookingID, b.VehicleType, b.Passengers)
+			}
+		}
+	})
+}
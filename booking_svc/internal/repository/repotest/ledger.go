@@ -0,0 +1,132 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"booking_svc/internal/ledger"
+	"booking_svc/internal/money"
+	"booking_svc/internal/repository"
+)
+
+func trip(t *testing.T, bookingID, driverID string, fare int64, at time.Time) ledger.Entry {
+	t.Helper()
+	e, err := ledger.Trip(bookingID, "r-1", driverID, money.Money{Amount: fare, Currency: "INR"}, money.Money{}, 2000, at)
+	must(t, err)
+	e.ID = "e-" + bookingID
+	return e
+}
+
+func lineRefs(ls []ledger.Line) []string {
+	refs := make([]string, len(ls))
+	for i, l := range ls {
+		refs[i] = l.Reference
+	}
+	return refs
+}
+
+// LedgerRepository runs the conformance suite against implementations made by newRepo.
+func LedgerRepository(t *testing.T, newRepo func(t *testing.T) repository.LedgerRepository) {
+	t.Run("post once per reference", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		at := time.Now().Add(-time.Hour)
+		ok, err := repo.PostEntry(c, trip(t, "b-1", "d-1", 10000, at))
+		must(t, err)
+		if !ok {
+			t.Fatal("first PostEntry must store the entry")
+		}
+		again := trip(t, "b-1", "d-1", 99900, at)
+		again.ID = "e-other"
+		if ok, err := repo.PostEntry(c, again); ok || err != nil {
+			t.Fatalf("second PostEntry for a reference: ok=%v err=%v", ok, err)
+		}
+		acct, ok, err := repo.GetAccount(c, ledger.DriverAccount("d-1"))
+		must(t, err)
+		if !ok || acct.Type != ledger.Liability || acct.Currency != "INR" || acct.CreatedAt.IsZero() {
+			t.Fatalf("driver account: ok=%v %+v", ok, acct)
+		}
+		if _, ok, err := repo.GetAccount(c, "missing"); ok || err != nil {
+			t.Fatalf("GetAccount(missing): ok=%v err=%v", ok, err)
+		}
+		// The first entry is the one kept.
+		got, ok, err := repo.GetEntry(c, ledger.TripReference("b-1"))
+		must(t, err)
+		want := trip(t, "b-1", "d-1", 10000, at)
+		if !ok || got.ID != want.ID || got.CreatedAt.IsZero() || !reflect.DeepEqual(got.Postings, want.Postings) {
+			t.Fatalf("GetEntry: ok=%v %+v", ok, got)
+		}
+		if _, ok, err := repo.GetEntry(c, "missing"); ok || err != nil {
+			t.Fatalf("GetEntry(missing): ok=%v err=%v", ok, err)
+		}
+		totals, err := repo.SumPostings(c, ledger.DriverAccount("d-1"), time.Now())
+		must(t, err)
+		if totals != (ledger.Totals{Credits: 8000}) {
+			t.Fatalf("redelivered entry was posted: %+v", totals)
+		}
+	})
+
+	t.Run("mismatched postings store nothing", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.PostEntry(c, trip(t, "b-1", "d-1", 10000, time.Now()))
+		must(t, err)
+
+		usd := trip(t, "b-2", "d-2", 10000, time.Now())
+		for i := range usd.Postings {
+			usd.Postings[i].Amount.Currency = "USD"
+		}
+		wrongType := trip(t, "b-3", "d-3", 10000, time.Now())
+		wrongType.Postings[0].AccountType = ledger.Liability
+		for _, e := range []ledger.Entry{usd, wrongType} {
+			if ok, err := repo.PostEntry(c, e); ok || !errors.Is(err, ledger.ErrAccountMismatch) {
+				t.Fatalf("PostEntry(%s): ok=%v err=%v", e.Reference, ok, err)
+			}
+		}
+		// The rejected entries opened no accounts on the way.
+		for _, code := range []string{ledger.DriverAccount("d-2"), ledger.DriverAccount("d-3")} {
+			if _, ok, err := repo.GetAccount(c, code); ok || err != nil {
+				t.Fatalf("GetAccount(%s): ok=%v err=%v", code, ok, err)
+			}
+		}
+		// A later entry with the rejected reference still posts.
+		if ok, err := repo.PostEntry(c, trip(t, "b-3", "d-3", 10000, time.Now())); !ok || err != nil {
+			t.Fatalf("retry after mismatch: ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("sums and lines by occurrence", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		// Posted out of order: b-3 happened first.
+		for i, at := range []time.Time{base.Add(48 * time.Hour), base.Add(24 * time.Hour), base.Add(-time.Hour), base.Add(24 * time.Hour)} {
+			id := fmt.Sprintf("b-%d", i+1)
+			_, err := repo.PostEntry(c, trip(t, id, "d-1", int64(1000*(i+1)), at))
+			must(t, err)
+		}
+		_, err := repo.PostEntry(c, trip(t, "b-other", "d-2", 5000, base))
+		must(t, err)
+
+		acct := ledger.DriverAccount("d-1")
+		before, err := repo.SumPostings(c, acct, base)
+		must(t, err)
+		if before != (ledger.Totals{Credits: 2400}) {
+			t.Fatalf("totals before the window: %+v", before)
+		}
+		lines, err := repo.ListLines(c, acct, base, base.Add(48*time.Hour))
+		must(t, err)
+		if got := lineRefs(lines); !equalIDs(got, "trip:b-2", "trip:b-4") {
+			t.Fatalf("lines: %v", got)
+		}
+		if l := lines[0]; l.EntryID != "e-b-2" || l.Description != "Trip b-2" || l.Side != ledger.Credit ||
+			l.Amount != (money.Money{Amount: 1600, Currency: "INR"}) || !sameInstant(l.OccurredAt, base.Add(24*time.Hour)) {
+			t.Fatalf("line: %+v", l)
+		}
+		riders, err := repo.SumPostings(c, ledger.RiderAccount("r-1"), base.Add(72*time.Hour))
+		must(t, err)
+		if riders != (ledger.Totals{Debits: 15000}) {
+			t.Fatalf("rider totals: %+v", riders)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/repository"
+)
+
+func newPayment(bookingID string, expiresAt time.Time) repository.CreatePaymentParams {
+	return repository.CreatePaymentParams{
+		BookingID: bookingID,
+		RiderID:   "r-1",
+		Amount:    money.Money{Amount: 22050, Currency: "INR"},
+		ExpiresAt: expiresAt,
+	}
+}
+
+func paymentIDs(ps []models.Payment) []string {
+	ids := make([]string, len(ps))
+	for i, p := range ps {
+		ids[i] = p.BookingID
+	}
+	return ids
+}
+
+func strPtr(s string) *string { return &s }
+
+// PaymentRepository runs the conformance suite against implementations made by newRepo.
+func PaymentRepository(t *testing.T, newRepo func(t *testing.T) repository.PaymentRepository) {
+	t.Run("create and get", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		expires := time.Now().Add(time.Hour)
+		created, err := repo.CreatePayment(c, newPayment("b-1", expires))
+		must(t, err)
+		if created.BookingID != "b-1" || created.RiderID != "r-1" || created.Status != models.PaymentStatusPending ||
+			created.Amount != (money.Money{Amount: 22050, Currency: "INR"}) || created.Captured != nil ||
+			created.AuthorizationID != "" || !sameInstant(created.ExpiresAt, expires) || created.CreatedAt.IsZero() {
+			t.Fatalf("unexpected payment: %+v", created)
+		}
+		if _, err := repo.CreatePayment(c, newPayment("b-1", expires)); err == nil {
+			t.Fatal("second CreatePayment for a booking must fail")
+		}
+		got, ok, err := repo.GetPayment(c, "b-1")
+		must(t, err)
+		if !ok || got.Status != models.PaymentStatusPending || !sameInstant(got.CreatedAt, created.CreatedAt) {
+			t.Fatalf("GetPayment: ok=%v got=%+v", ok, got)
+		}
+		if _, ok, err := repo.GetPayment(c, "missing"); ok || err != nil {
+			t.Fatalf("GetPayment(missing): ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("conditional updates", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.CreatePayment(c, newPayment("b-1", time.Now().Add(time.Hour)))
+		must(t, err)
+
+		ok, err := repo.UpdatePayment(c, "b-1", models.PaymentStatusPending, repository.PaymentUpdate{
+			Status: models.PaymentStatusAuthorized, AuthorizationID: strPtr("auth-1"),
+		})
+		must(t, err)
+		if !ok {
+			t.Fatal("pending -> authorized must update")
+		}
+		if ok, _ := repo.UpdatePayment(c, "b-1", models.PaymentStatusPending, repository.PaymentUpdate{
+			Status: models.PaymentStatusFailed, FailureReason: strPtr("late"),
+		}); ok {
+			t.Fatal("an update from a stale status must be a no-op")
+		}
+		captured := money.Money{Amount: 19900, Currency: "INR"}
+		ok, err = repo.UpdatePayment(c, "b-1", models.PaymentStatusAuthorized, repository.PaymentUpdate{
+			Status: models.PaymentStatusCaptured, Captured: &captured,
+		})
+		must(t, err)
+		if !ok {
+			t.Fatal("authorized -> captured must update")
+		}
+		got, _, err := repo.GetPayment(c, "b-1")
+		must(t, err)
+		if got.Status != models.PaymentStatusCaptured || got.AuthorizationID != "auth-1" ||
+			got.Captured == nil || *got.Captured != captured || got.FailureReason != "" {
+			t.Fatalf("unexpected payment after updates: %+v", got)
+		}
+		if ok, _ := repo.UpdatePayment(c, "missing", models.PaymentStatusPending, repository.PaymentUpdate{Status: models.PaymentStatusFailed}); ok {
+			t.Fatal("UpdatePayment on a missing payment must be a no-op")
+		}
+	})
+
+	t.Run("attempts oldest first", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.CreatePayment(c, newPayment("b-1", time.Now().Add(time.Hour)))
+		must(t, err)
+		must(t, repo.RecordAttempt(c, repository.RecordPaymentAttemptParams{
+			BookingID: "b-1", Operation: models.PaymentOpAuthorize, IdempotencyKey: "b-1:authorize", Error: strPtr("timeout"),
+		}))
+		must(t, repo.RecordAttempt(c, repository.RecordPaymentAttemptParams{
+			BookingID: "b-1", Operation: models.PaymentOpAuthorize, IdempotencyKey: "b-1:authorize", Succeeded: true,
+		}))
+		if err := repo.RecordAttempt(c, repository.RecordPaymentAttemptParams{
+			BookingID: "missing", Operation: models.PaymentOpVoid, IdempotencyKey: "missing:void",
+		}); err == nil {
+			t.Fatal("RecordAttempt for a missing payment must fail")
+		}
+
+		got, err := repo.ListAttempts(c, "b-1")
+		must(t, err)
+		if len(got) != 2 || got[0].Succeeded || got[0].Error == nil || *got[0].Error != "timeout" ||
+			!got[1].Succeeded || got[1].Error != nil || got[0].ID >= got[1].ID ||
+			got[1].Operation != models.PaymentOpAuthorize || got[1].IdempotencyKey != "b-1:authorize" {
+			t.Fatalf("unexpected attempts: %+v", got)
+		}
+		none, err := repo.ListAttempts(c, "missing")
+		must(t, err)
+		if len(none) != 0 {
+			t.Fatalf("ListAttempts(missing): %+v", none)
+		}
+	})
+
+	t.Run("expired holds", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		now := time.Now()
+		for id, expires := range map[string]time.Time{
+			"b-1": now.Add(-time.Minute),
+			"b-2": now.Add(-time.Hour),
+			"b-3": now.Add(time.Hour),
+			"b-4": now.Add(-2 * time.Hour),
+		} {
+			_, err := repo.CreatePayment(c, newPayment(id, expires))
+			must(t, err)
+		}
+		_, err := repo.UpdatePayment(c, "b-1", models.PaymentStatusPending, repository.PaymentUpdate{Status: models.PaymentStatusAuthorized})
+		must(t, err)
+		_, err = repo.UpdatePayment(c, "b-4", models.PaymentStatusPending, repository.PaymentUpdate{Status: models.PaymentStatusReleased})
+		must(t, err)
+
+		got, err := repo.ListExpiredHolds(c, now, 10)
+		must(t, err)
+		if ids := paymentIDs(got); !equalIDs(ids, "b-2", "b-1") {
+			t.Fatalf("ListExpiredHolds: %v", ids)
+		}
+		page, err := repo.ListExpiredHolds(c, now, 1)
+		must(t, err)
+		if ids := paymentIDs(page); !equalIDs(ids, "b-2") {
+			t.Fatalf("ListExpiredHolds page: %v", ids)
+		}
+
+		// A postponed hold is left out until then, and then queues behind
+		// holds that were due before it.
+		must(t, repo.PostponeExpiry(c, "b-2", now.Add(90*time.Minute)))
+		got, err = repo.ListExpiredHolds(c, now, 10)
+		must(t, err)
+		if ids := paymentIDs(got); !equalIDs(ids, "b-1") {
+			t.Fatalf("ListExpiredHolds while postponed: %v", ids)
+		}
+		got, err = repo.ListExpiredHolds(c, now.Add(2*time.Hour), 10)
+		must(t, err)
+		if ids := paymentIDs(got); !equalIDs(ids, "b-1", "b-3", "b-2") {
+			t.Fatalf("ListExpiredHolds after the postponement: %v", ids)
+		}
+	})
+}
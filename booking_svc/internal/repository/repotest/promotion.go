@@ -0,0 +1,244 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/promo"
+	"booking_svc/internal/repository"
+)
+
+func flatPromotion(code string, maxTotal, maxPerRider int) promo.Promotion {
+	return promo.Promotion{
+		Code:           code,
+		Kind:           promo.Flat,
+		AmountOff:      &money.Money{Amount: 5000, Currency: "INR"},
+		MaxRedemptions: maxTotal,
+		MaxPerRider:    maxPerRider,
+		StartsAt:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func withPromo(p repository.CreateBookingParams, code string) repository.CreateBookingParams {
+	p.Promo = &repository.PromoRedemption{Code: code, Discount: money.Money{Amount: 5000, Currency: "INR"}}
+	return p
+}
+
+// PromotionRepository runs the conformance suite against implementations made
+// by newRepos. Redemptions go through the booking repository, which must
+// share the promotions' storage.
+func PromotionRepository(t *testing.T, newRepos func(t *testing.T) (repository.PromotionRepository, repository.BookingRepository)) {
+	t.Run("create get and list", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		c := ctx(t)
+		ends := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		percent := promo.Promotion{
+			Code:        "LAUNCH15",
+			Description: "15% off",
+			Kind:        promo.Percent,
+			PercentBPS:  1500,
+			MaxDiscount: &money.Money{Amount: 10000, Currency: "INR"},
+			StartsAt:    time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			EndsAt:      &ends,
+			Area:        &promo.Area{Center: models.Location{Lat: 12.97, Lng: 77.59}, RadiusKm: 10},
+		}
+		created, ok, err := repo.CreatePromotion(c, percent)
+		must(t, err)
+		if !ok || created.Code != "LAUNCH15" || created.Kind != promo.Percent || created.PercentBPS != 1500 ||
+			created.AmountOff != nil || *created.MaxDiscount != *percent.MaxDiscount || created.Redemptions != 0 ||
+			!sameInstant(created.StartsAt, percent.StartsAt) || created.EndsAt == nil || !sameInstant(*created.EndsAt, ends) ||
+			*created.Area != *percent.Area || created.CreatedAt.IsZero() {
+			t.Fatalf("created: ok=%v %+v", ok, created)
+		}
+		tick()
+		_, _, err = repo.CreatePromotion(c, flatPromotion("FLAT50", 0, 0))
+		must(t, err)
+
+		got, ok, err := repo.GetPromotion(c, "FLAT50")
+		must(t, err)
+		if !ok || got.Kind != promo.Flat || *got.AmountOff != (money.Money{Amount: 5000, Currency: "INR"}) ||
+			got.MaxDiscount != nil || got.EndsAt != nil || got.Area != nil {
+			t.Fatalf("get: ok=%v %+v", ok, got)
+		}
+		if _, ok, err := repo.GetPromotion(c, "NOPE"); err != nil || ok {
+			t.Fatalf("missing promotion: ok=%v err=%v", ok, err)
+		}
+		list, err := repo.ListPromotions(c)
+		must(t, err)
+		if len(list) != 2 || list[0].Code != "FLAT50" || list[1].Code != "LAUNCH15" {
+			t.Fatalf("list must be newest first: %+v", list)
+		}
+	})
+
+	t.Run("duplicate code is not created", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		c := ctx(t)
+		_, ok, err := repo.CreatePromotion(c, flatPromotion("FLAT50", 0, 0))
+		must(t, err)
+		if !ok {
+			t.Fatal("first create must succeed")
+		}
+		if _, ok, err := repo.CreatePromotion(c, flatPromotion("FLAT50", 5, 0)); err != nil || ok {
+			t.Fatalf("duplicate: ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("redemption is stored with the booking", func(t *testing.T) {
+		repo, bookings := newRepos(t)
+		c := ctx(t)
+		_, _, err := repo.CreatePromotion(c, flatPromotion("FLAT50", 0, 0))
+		must(t, err)
+		b, err := bookings.Create(c, withPromo(newBooking("b-1", "r-1"), "FLAT50"))
+		must(t, err)
+		if b.PromoCode == nil || *b.PromoCode != "FLAT50" || b.Discount == nil || *b.Discount != (money.Money{Amount: 5000, Currency: "INR"}) {
+			t.Fatalf("created booking: %+v", b)
+		}
+		got, _, err := bookings.GetByID(c, "b-1")
+		must(t, err)
+		if got.PromoCode == nil || *got.PromoCode != "FLAT50" || got.Discount == nil || *got.Discount != *b.Discount {
+			t.Fatalf("stored booking: %+v", got)
+		}
+		p, _, err := repo.GetPromotion(c, "FLAT50")
+		must(t, err)
+		n, err := repo.CountRiderRedemptions(c, "FLAT50", "r-1")
+		must(t, err)
+		if p.Redemptions != 1 || n != 1 {
+			t.Fatalf("redemptions=%d rider=%d, want 1 and 1", p.Redemptions, n)
+		}
+
+		plain, err := bookings.Create(c, newBooking("b-2", "r-1"))
+		must(t, err)
+		if plain.PromoCode != nil || plain.Discount != nil {
+			t.Fatalf("booking without a code: %+v", plain)
+		}
+	})
+
+	t.Run("unknown code stores nothing", func(t *testing.T) {
+		_, bookings := newRepos(t)
+		c := ctx(t)
+		if _, err := bookings.Create(c, withPromo(newBooking("b-1", "r-1"), "NOPE")); !errors.Is(err, promo.ErrUnknownCode) {
+			t.Fatalf("redeeming an unknown code: %v", err)
+		}
+		if _, ok, err := bookings.GetByID(c, "b-1"); err != nil || ok {
+			t.Fatalf("booking stored: ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("per-rider limit", func(t *testing.T) {
+		repo, bookings := newRepos(t)
+		c := ctx(t)
+		_, _, err := repo.CreatePromotion(c, flatPromotion("ONCE", 0, 1))
+		must(t, err)
+		_, err = bookings.Create(c, withPromo(newBooking("b-1", "r-1"), "ONCE"))
+		must(t, err)
+		if _, err := bookings.Create(c, withPromo(newBooking("b-2", "r-1"), "ONCE")); !errors.Is(err, promo.ErrAlreadyRedeemed) {
+			t.Fatalf("second redemption by r-1: %v", err)
+		}
+		if _, ok, err := bookings.GetByID(c, "b-2"); err != nil || ok {
+			t.Fatalf("rejected booking stored: ok=%v err=%v", ok, err)
+		}
+		_, err = bookings.Create(c, withPromo(newBooking("b-3", "r-2"), "ONCE"))
+		must(t, err)
+		p, _, err := repo.GetPromotion(c, "ONCE")
+		must(t, err)
+		if p.Redemptions != 2 {
+			t.Fatalf("redemptions = %d, want 2", p.Redemptions)
+		}
+	})
+
+	t.Run("failed booking redeems nothing", func(t *testing.T) {
+		repo, bookings := newRepos(t)
+		c := ctx(t)
+		_, _, err := repo.CreatePromotion(c, flatPromotion("FLAT50", 0, 0))
+		must(t, err)
+		_, err = bookings.Create(c, newBooking("b-1", "r-1"))
+		must(t, err)
+		if _, err := bookings.Create(c, withPromo(newBooking("b-1", "r-1"), "FLAT50")); err == nil {
+			t.Fatal("second Create with the same id must fail")
+		}
+		p, _, err := repo.GetPromotion(c, "FLAT50")
+		must(t, err)
+		n, err := repo.CountRiderRedemptions(c, "FLAT50", "r-1")
+		must(t, err)
+		if p.Redemptions != 0 || n != 0 {
+			t.Fatalf("redemptions=%d rider=%d, want none", p.Redemptions, n)
+		}
+	})
+
+	t.Run("per-rider limit holds under concurrency", func(t *testing.T) {
+		repo, bookings := newRepos(t)
+		c := ctx(t)
+		_, _, err := repo.CreatePromotion(c, flatPromotion("ONCE", 0, 1))
+		must(t, err)
+
+		const n = 8
+		var wg sync.WaitGroup
+		errs := make([]error, n)
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = bookings.Create(c, withPromo(newBooking(fmt.Sprintf("b-%d", i), "r-1"), "ONCE"))
+			}()
+		}
+		wg.Wait()
+
+		redeemed := 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				redeemed++
+			case !errors.Is(err, promo.ErrAlreadyRedeemed):
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		all, err := bookings.ListAll(c)
+		must(t, err)
+		p, _, err := repo.GetPromotion(c, "ONCE")
+		must(t, err)
+		if redeemed != 1 || len(all) != 1 || p.Redemptions != 1 {
+			t.Fatalf("redeemed=%d stored=%d counter=%d, want 1 each", redeemed, len(all), p.Redemptions)
+		}
+	})
+
+	t.Run("global limit holds under concurrency", func(t *testing.T) {
+		repo, bookings := newRepos(t)
+		c := ctx(t)
+		_, _, err := repo.CreatePromotion(c, flatPromotion("FIRST2", 2, 0))
+		must(t, err)
+
+		const n = 8
+		var wg sync.WaitGroup
+		errs := make([]error, n)
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = bookings.Create(c, withPromo(newBooking(fmt.Sprintf("b-%d", i), fmt.Sprintf("r-%d", i)), "FIRST2"))
+			}()
+		}
+		wg.Wait()
+
+		redeemed := 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				redeemed++
+			case !errors.Is(err, promo.ErrExhausted):
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		all, err := bookings.ListAll(c)
+		must(t, err)
+		p, _, err := repo.GetPromotion(c, "FIRST2")
+		must(t, err)
+		if redeemed != 2 || len(all) != 2 || p.Redemptions != 2 {
+			t.Fatalf("redeemed=%d stored=%d counter=%d, want 2 each", redeemed, len(all), p.Redemptions)
+		}
+	})
+}
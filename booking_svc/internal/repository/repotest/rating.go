@@ -0,0 +1,54 @@
+package repotest
+
+import (
+	"testing"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+func riderRating(bookingID string, stars int) models.Rating {
+	return models.Rating{
+		BookingID: bookingID,
+		Rater:     models.RatingByRider,
+		RaterID:   "r-1",
+		RateeID:   "d-1",
+		Stars:     stars,
+		Comment:   "smooth ride",
+	}
+}
+
+// RatingRepository runs the conformance suite against implementations made by
+// newRepo.
+func RatingRepository(t *testing.T, newRepo func(t *testing.T) repository.RatingRepository) {
+	t.Run("create stores the rating", func(t *testing.T) {
+		repo := newRepo(t)
+		c := ctx(t)
+		got, ok, err := repo.CreateRating(c, riderRating("b-1", 4))
+		must(t, err)
+		want := riderRating("b-1", 4)
+		if !ok || got.BookingID != want.BookingID || got.Rater != want.Rater || got.RaterID != want.RaterID ||
+			got.RateeID != want.RateeID || got.Stars != 4 || got.Comment != want.Comment || got.CreatedAt.IsZero() {
+			t.Fatalf("created: ok=%v %+v", ok, got)
+		}
+	})
+
+	t.Run("each side rates once", func(t *testing.T) {
+		repo := newRepo(t)
+		c := ctx(t)
+		first, _, err := repo.CreateRating(c, riderRating("b-1", 4))
+		must(t, err)
+		again, ok, err := repo.CreateRating(c, riderRating("b-1", 1))
+		must(t, err)
+		if ok || again.Stars != 4 || !sameInstant(again.CreatedAt, first.CreatedAt) {
+			t.Fatalf("second rider rating: ok=%v %+v, want the first back", ok, again)
+		}
+
+		byDriver := models.Rating{BookingID: "b-1", Rater: models.RatingByDriver, RaterID: "d-1", RateeID: "r-1", Stars: 5}
+		got, ok, err := repo.CreateRating(c, byDriver)
+		must(t, err)
+		if !ok || got.Rater != models.RatingByDriver || got.Stars != 5 || got.Comment != "" {
+			t.Fatalf("driver rating: ok=%v %+v", ok, got)
+		}
+	})
+}
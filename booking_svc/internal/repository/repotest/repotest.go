@@ -0,0 +1,36 @@
+// Package repotest is the conformance suite for repository implementations.
+// Every implementation — Postgres and in-memory — runs the same cases, so the
+// in-memory repositories can stand in for the database in tests.
+//
+// Each suite takes a factory that returns an empty repository; it is called
+// once per case.
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func ctx(t *testing.T) context.Context {
+	t.Helper()
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return c
+}
+
+// sameInstant compares timestamps at the microsecond precision Postgres keeps.
+func sameInstant(a, b time.Time) bool {
+	return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+}
+
+// tick separates writes so created_at orderings are unambiguous on every
+// implementation.
+func tick() { time.Sleep(2 * time.Millisecond) }
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,201 @@
+package repotest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+func newSubscription(id string, eventTypes ...string) repository.CreateSubscriptionParams {
+	return repository.CreateSubscriptionParams{ID: id, URL: "https://example.test/" + id, EventTypes: eventTypes, Secret: "s-" + id}
+}
+
+func newDelivery(id, subscriptionID string) repository.EnqueueDeliveryParams {
+	return repository.EnqueueDeliveryParams{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		EventID:        "e-" + id,
+		EventType:      models.WebhookEventBookingCreated,
+		Payload:        json.RawMessage(`{"booking_id":"b-1","price":220}`),
+	}
+}
+
+func subscriptionIDs(ss []models.WebhookSubscription) []string {
+	ids := make([]string, len(ss))
+	for i, s := range ss {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func deliveryIDs(ds []models.WebhookDelivery) []string {
+	ids := make([]string, len(ds))
+	for i, d := range ds {
+		ids[i] = d.ID
+	}
+	return ids
+}
+
+// sameJSON compares payloads by value; jsonb does not keep the input bytes.
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb any
+	must(t, json.Unmarshal(a, &va))
+	must(t, json.Unmarshal(b, &vb))
+	return reflect.DeepEqual(va, vb)
+}
+
+// WebhookRepository runs the conformance suite against implementations made by newRepo.
+func WebhookRepository(t *testing.T, newRepo func(t *testing.T) repository.WebhookRepository) {
+	t.Run("subscriptions", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		created, err := repo.CreateSubscription(c, newSubscription("s-1", models.WebhookEventBookingCreated))
+		must(t, err)
+		if created.ID != "s-1" || created.URL != "https://example.test/s-1" || created.Secret != "s-s-1" ||
+			!reflect.DeepEqual(created.EventTypes, []string{models.WebhookEventBookingCreated}) || created.CreatedAt.IsZero() {
+			t.Fatalf("unexpected subscription: %+v", created)
+		}
+		tick()
+		_, err = repo.CreateSubscription(c, newSubscription("s-2", models.WebhookEventBookingCreated, models.WebhookEventBookingAccepted))
+		must(t, err)
+		tick()
+		_, err = repo.CreateSubscription(c, newSubscription("s-3", models.WebhookEventBookingAccepted))
+		must(t, err)
+		if _, err := repo.CreateSubscription(c, newSubscription("s-1")); err == nil {
+			t.Fatal("duplicate subscription id must fail")
+		}
+
+		all, err := repo.ListSubscriptions(c)
+		must(t, err)
+		if ids := subscriptionIDs(all); !equalIDs(ids, "s-3", "s-2", "s-1") {
+			t.Fatalf("ListSubscriptions: %v", ids)
+		}
+		accepted, err := repo.ListSubscriptionsForEvent(c, models.WebhookEventBookingAccepted)
+		must(t, err)
+		if ids := subscriptionIDs(accepted); !equalIDs(ids, "s-2", "s-3") {
+			t.Fatalf("ListSubscriptionsForEvent: %v", ids)
+		}
+
+		got, ok, err := repo.GetSubscription(c, "s-1")
+		must(t, err)
+		if !ok || got.ID != "s-1" || !sameInstant(got.CreatedAt, created.CreatedAt) {
+			t.Fatalf("GetSubscription: ok=%v %+v", ok, got)
+		}
+		if ok, err := repo.DeleteSubscription(c, "s-1"); !ok || err != nil {
+			t.Fatalf("DeleteSubscription: ok=%v err=%v", ok, err)
+		}
+		if ok, _ := repo.DeleteSubscription(c, "s-1"); ok {
+			t.Fatal("deleting twice must report false")
+		}
+		if _, ok, _ := repo.GetSubscription(c, "s-1"); ok {
+			t.Fatal("deleted subscription still found")
+		}
+	})
+
+	t.Run("enqueue is idempotent and all-or-nothing", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.CreateSubscription(c, newSubscription("s-1", models.WebhookEventBookingCreated))
+		must(t, err)
+
+		must(t, repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("d-1", "s-1")}))
+		tick()
+		must(t, repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("d-1", "s-1"), newDelivery("d-2", "s-1")}))
+		if err := repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("d-3", "s-1"), newDelivery("d-4", "missing")}); err == nil {
+			t.Fatal("delivery for an unknown subscription must fail")
+		}
+
+		ds, err := repo.ListDeliveries(c, "s-1", 10)
+		must(t, err)
+		if ids := deliveryIDs(ds); !equalIDs(ids, "d-2", "d-1") {
+			t.Fatalf("ListDeliveries: %v", ids)
+		}
+		d := ds[1]
+		if d.Status != models.DeliveryStatusPending || d.Attempts != 0 || d.EventID != "e-d-1" ||
+			d.EventType != models.WebhookEventBookingCreated || d.LastStatusCode != nil || d.DeliveredAt != nil ||
+			!sameJSON(t, d.Payload, newDelivery("d-1", "s-1").Payload) {
+			t.Fatalf("unexpected delivery: %+v", d)
+		}
+		if limited, _ := repo.ListDeliveries(c, "s-1", 1); !equalIDs(deliveryIDs(limited), "d-2") {
+			t.Fatalf("ListDeliveries limit 1: %v", deliveryIDs(limited))
+		}
+	})
+
+	t.Run("claim leases due deliveries", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.CreateSubscription(c, newSubscription("s-1", models.WebhookEventBookingCreated))
+		must(t, err)
+		must(t, repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("d-1", "s-1")}))
+		tick()
+		must(t, repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("d-2", "s-1")}))
+
+		now := time.Now().Add(time.Second)
+		first, err := repo.ClaimDueDeliveries(c, now, time.Minute, 1)
+		must(t, err)
+		if ids := deliveryIDs(first); !equalIDs(ids, "d-1") || !sameInstant(first[0].NextAttemptAt, now.Add(time.Minute)) {
+			t.Fatalf("first claim: %+v", first)
+		}
+		rest, err := repo.ClaimDueDeliveries(c, now, time.Minute, 10)
+		must(t, err)
+		if ids := deliveryIDs(rest); !equalIDs(ids, "d-2") {
+			t.Fatalf("leased deliveries must not be claimed again: %v", ids)
+		}
+		if none, _ := repo.ClaimDueDeliveries(c, now, time.Minute, 10); len(none) != 0 {
+			t.Fatalf("everything is leased, got %v", deliveryIDs(none))
+		}
+		if later, _ := repo.ClaimDueDeliveries(c, now.Add(2*time.Minute), time.Minute, 10); len(later) != 2 {
+			t.Fatalf("expired leases must be claimable, got %v", deliveryIDs(later))
+		}
+	})
+
+	t.Run("record attempts", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.CreateSubscription(c, newSubscription("s-1", models.WebhookEventBookingCreated))
+		must(t, err)
+		must(t, repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("ok", "s-1"), newDelivery("dead", "s-1")}))
+
+		code, msg := 500, "server error"
+		retryAt := time.Now().Add(time.Hour)
+		must(t, repo.RecordAttempt(c, "ok", repository.DeliveryAttemptResult{StatusCode: &code, Error: &msg, NextAttemptAt: &retryAt}))
+		ok200 := 200
+		must(t, repo.RecordAttempt(c, "ok", repository.DeliveryAttemptResult{Succeeded: true, StatusCode: &ok200}))
+		must(t, repo.RecordAttempt(c, "dead", repository.DeliveryAttemptResult{StatusCode: &code, Error: &msg}))
+		must(t, repo.RecordAttempt(c, "missing", repository.DeliveryAttemptResult{Succeeded: true}))
+
+		ds, err := repo.ListDeliveries(c, "s-1", 10)
+		must(t, err)
+		byID := map[string]models.WebhookDelivery{}
+		for _, d := range ds {
+			byID[d.ID] = d
+		}
+		if d := byID["ok"]; d.Status != models.DeliveryStatusSucceeded || d.Attempts != 2 || d.DeliveredAt == nil ||
+			d.LastStatusCode == nil || *d.LastStatusCode != 200 || d.LastError != nil || !sameInstant(d.NextAttemptAt, retryAt) {
+			t.Fatalf("unexpected succeeded delivery: %+v", d)
+		}
+		if d := byID["dead"]; d.Status != models.DeliveryStatusFailed || d.Attempts != 1 || d.DeliveredAt != nil ||
+			d.LastError == nil || *d.LastError != msg {
+			t.Fatalf("unexpected failed delivery: %+v", d)
+		}
+		if due, _ := repo.ClaimDueDeliveries(c, time.Now().Add(2*time.Hour), time.Minute, 10); len(due) != 0 {
+			t.Fatalf("finished deliveries must not be claimed: %v", deliveryIDs(due))
+		}
+	})
+
+	t.Run("delete cascades to deliveries", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		_, err := repo.CreateSubscription(c, newSubscription("s-1", models.WebhookEventBookingCreated))
+		must(t, err)
+		must(t, repo.EnqueueDeliveries(c, []repository.EnqueueDeliveryParams{newDelivery("d-1", "s-1")}))
+		_, err = repo.DeleteSubscription(c, "s-1")
+		must(t, err)
+		if ds, _ := repo.ListDeliveries(c, "s-1", 10); len(ds) != 0 {
+			t.Fatalf("deliveries survived their subscription: %v", deliveryIDs(ds))
+		}
+		if due, _ := repo.ClaimDueDeliveries(c, time.Now().Add(time.Second), time.Minute, 10); len(due) != 0 {
+			t.Fatalf("deliveries survived their subscription: %v", deliveryIDs(due))
+		}
+	})
+}
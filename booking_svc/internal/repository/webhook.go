@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"booking_svc/internal/models"
+)
+
+type CreateSubscriptionParams struct {
+	ID         string
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+type EnqueueDeliveryParams struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	Payload        json.RawMessage
+}
+
+// DeliveryAttemptResult records the outcome of one HTTP attempt.
+// A nil NextAttemptAt on a failed attempt marks the delivery as permanently failed.
+type DeliveryAttemptResult struct {
+	Succeeded     bool
+	StatusCode    *int
+	Error         *string
+	NextAttemptAt *time.Time
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, p CreateSubscriptionParams) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, bool, error)
+	DeleteSubscription(ctx context.Context, id string) (bool, error)
+	ListSubscriptionsForEvent(ctx context.Context, eventType string) ([]models.WebhookSubscription, error)
+
+	EnqueueDeliveries(ctx context.Context, ps []EnqueueDeliveryParams) error
+	// ClaimDueDeliveries returns pending deliveries whose next attempt is due and pushes
+	// their next_attempt_at forward by lease so concurrent dispatchers skip them.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, deliveryID string, res DeliveryAttemptResult) error
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error)
+}
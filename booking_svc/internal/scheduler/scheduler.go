@@ -0,0 +1,57 @@
+// Package scheduler releases scheduled rides to drivers ahead of pickup.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const releaseBatchSize = 100
+
+// Releaser is the slice of service.BookingService the Scheduler drives.
+type Releaser interface {
+	ReleaseScheduled(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Scheduler periodically releases reservations that are due. Reservations
+// are claimed under a lease and every step of a release is conditional and
+// idempotent, so replicas may run one each.
+type Scheduler struct {
+	svc      Releaser
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func New(svc Releaser, interval time.Duration, logger *slog.Logger) *Scheduler {
+	return &Scheduler{svc: svc, interval: interval, logger: logger, now: time.Now}
+}
+
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.ReleaseDue(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("release scheduled bookings failed", slog.String("err", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReleaseDue releases due reservations a batch at a time until none are left
+// and returns how many it released.
+func (s *Scheduler) ReleaseDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.svc.ReleaseScheduled(ctx, s.now(), releaseBatchSize)
+		total += n
+		if err != nil || n < releaseBatchSize {
+			return total, err
+		}
+	}
+}
@@ -2,68 +2,764 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"reflect"
+	"strconv"
+	"time"
 
+	"booking_svc/internal/cancellation"
 	"booking_svc/internal/events"
+	"booking_svc/internal/metrics"
 	"booking_svc/internal/models"
+	"booking_svc/internal/money"
 	"booking_svc/internal/mq"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/promo"
 	"booking_svc/internal/repository"
 
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrBookingNotStored means nothing was persisted; the caller may retry.
+	ErrBookingNotStored = errors.New("booking could not be stored")
+	// ErrBookingNotDispatched means the booking row exists but booking.created
+	// was not published, so drivers will not see it.
+	ErrBookingNotDispatched = errors.New("booking stored but not dispatched to drivers")
+	ErrBookingNotFound      = errors.New("booking not found")
+	// ErrBookingNotRequested means the booking has already been accepted, so
+	// announcing it to drivers again would reopen a finished job.
+	ErrBookingNotRequested = errors.New("booking is no longer requested")
+	// ErrBookingNotAccepted means the booking has no driver yet, or the trip
+	// is already over, so it cannot be completed.
+	ErrBookingNotAccepted = errors.New("booking is not an accepted trip")
+	// ErrBookingNotCancellable means the trip has been completed, or a no-show
+	// was reported for a booking cancelled otherwise.
+	ErrBookingNotCancellable = errors.New("booking can no longer be cancelled")
+	// ErrNoShowTooEarly means the driver has not arrived at the pickup, or
+	// has not waited there as long as the cancellation policy requires.
+	ErrNoShowTooEarly = errors.New("driver has not waited long enough for a no-show")
+	ErrStopNotFound   = errors.New("booking has no such stop")
+	// ErrStopOutOfOrder means an earlier stop has not been reached yet.
+	ErrStopOutOfOrder = errors.New("earlier stops have not been reached")
+)
+
+// MaxStops is how many intermediate stops a booking may have.
+const MaxStops = 5
+
+// watchPollInterval bounds how stale a watcher can be when the change was
+// applied by another replica, which this process's Broadcaster never hears about.
+const watchPollInterval = 2 * time.Second
+
+const (
+	// holdRecheckDelay is how long ExpireHolds leaves the expired hold of a
+	// trip under way before looking at it again.
+	holdRecheckDelay = 15 * time.Minute
+	// holdRetryDelay is how long ExpireHolds waits to retry settling a hold
+	// that failed to settle.
+	holdRetryDelay = time.Minute
+)
+
 type CreateBookingInput struct {
+	RiderID   string
 	PickupLoc models.Location
 	Dropoff   models.Location
-	Price     int
+	// Stops, at most MaxStops, are visited in order between PickupLoc and
+	// Dropoff.
+	Stops []models.Location
+	// Price without a currency is a legacy whole-unit amount; CreateBooking
+	// prices it in the service's default currency.
+	Price money.Money
+	// PromoCode, if not empty, is redeemed against the booking.
+	PromoCode string
+	// ScheduledFor, if set, reserves the ride for that pickup time instead
+	// of dispatching it now.
+	ScheduledFor *time.Time
+	// VehicleType, if set, limits the ride to drivers of that vehicle type.
+	VehicleType models.VehicleType
+	// Passengers defaults to one.
+	Passengers int
+}
+
+// Validate reports every invalid field, named as in the REST API.
+func (in CreateBookingInput) Validate() error {
+	errs := validateRoute(in.PickupLoc, in.Stops, in.Dropoff)
+	if !in.Price.IsPositive() {
+		errs = append(errs, problem.FieldError{Field: "price", Message: "must be > 0"})
+	}
+	if in.Price.Currency != "" && !money.ValidCurrency(in.Price.Currency) {
+		errs = append(errs, problem.FieldError{Field: "price.currency", Message: "must be a supported ISO 4217 code"})
+	}
+	seats, known := models.VehicleSeats[in.VehicleType]
+	if !known {
+		if in.VehicleType != "" {
+			errs = append(errs, problem.FieldError{Field: "vehicle_type", Message: fmt.Sprintf("must be one of %v", models.VehicleTypes)})
+		}
+		// Any vehicle will do, up to the largest.
+		seats = models.VehicleSeats[models.VehicleSUV]
+	}
+	if in.Passengers < 0 || in.Passengers > seats {
+		errs = append(errs, problem.FieldError{Field: "passengers", Message: fmt.Sprintf("must be between 1 and %d", seats)})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateRoute checks every point of a route, named as in the REST API.
+// Consecutive points must differ; a route with stops may end where it began.
+func validateRoute(pickup models.Location, stops []models.Location, dropoff models.Location) problem.ValidationError {
+	var errs problem.ValidationError
+	point := func(field string, l models.Location) {
+		if !isValidLat(l.Lat) {
+			errs = append(errs, problem.FieldError{Field: field + ".lat", Message: "must be between -90 and 90"})
+		}
+		if !isValidLng(l.Lng) {
+			errs = append(errs, problem.FieldError{Field: field + ".lng", Message: "must be between -180 and 180"})
+		}
+	}
+	point("pickuploc", pickup)
+	point("dropoff", dropoff)
+	if len(stops) > MaxStops {
+		errs = append(errs, problem.FieldError{Field: "stops", Message: fmt.Sprintf("must have at most %d stops", MaxStops)})
+	}
+	prev, prevField := pickup, "pickuploc"
+	for i, s := range stops {
+		field := fmt.Sprintf("stops[%d]", i)
+		point(field, s)
+		if s == prev {
+			errs = append(errs, problem.FieldError{Field: field, Message: "cannot be the same as " + prevField})
+		}
+		prev, prevField = s, field
+	}
+	if dropoff == prev {
+		errs = append(errs, problem.FieldError{Field: "dropoff", Message: "cannot be the same as " + prevField})
+	}
+	return errs
 }
 
+func isValidLat(v float64) bool { return v >= -90 && v <= 90 }
+func isValidLng(v float64) bool { return v >= -180 && v <= 180 }
+
 type BookingService interface {
 	CreateBooking(ctx context.Context, in CreateBookingInput) (models.Booking, error)
 	ListBookings(ctx context.Context) ([]models.Booking, error)
+	ListRiderBookings(ctx context.Context, riderID string) ([]models.Booking, error)
+	GetBooking(ctx context.Context, bookingID string) (models.Booking, error)
+	// WatchBooking calls send with the booking's current state and again after
+	// every change, until ctx is done or send fails.
+	WatchBooking(ctx context.Context, bookingID string, send func(models.Booking) error) error
+	// ListBookingsSince returns up to limit bookings created at or after since,
+	// oldest first, for reconciliation against driver_svc.
+	ListBookingsSince(ctx context.Context, since time.Time, limit int) ([]models.Booking, error)
+	// RepublishCreated publishes booking.created again for a Requested
+	// booking whose job never reached driver_svc.
+	RepublishCreated(ctx context.Context, bookingID string) error
+	// ListScheduledBookings returns the upcoming reservations of riderID, or
+	// of every rider when riderID is empty, soonest pickup first.
+	ListScheduledBookings(ctx context.Context, riderID string) ([]models.Booking, error)
+	// ReleaseScheduled dispatches up to limit Scheduled bookings whose pickup
+	// is within the reservation lead time of now: the fare is held and
+	// booking.created published. A declined hold cancels the reservation.
+	// It returns the number handled.
+	ReleaseScheduled(ctx context.Context, now time.Time, limit int) (int, error)
+	// CompleteBooking captures fare, or the full hold when fare is nil,
+	// records the trip in the ledger and marks the booking Completed. Completing it again returns it unchanged.
+	CompleteBooking(ctx context.Context, bookingID string, fare *money.Money) (models.Booking, error)
+	// CancelBooking marks a Scheduled, Requested or Accepted booking Cancelled with the
+	// fee the cancellation policy charges, collects that fee from the hold,
+	// releases the rest and tells driver_svc. Cancelling again retries
+	// whatever failed.
+	CancelBooking(ctx context.Context, bookingID string) (models.Booking, error)
+	// MarkArrived records that the assigned driver reached the pickup of an
+	// Accepted booking, which starts the no-show wait. Arriving again returns
+	// the booking unchanged.
+	MarkArrived(ctx context.Context, bookingID string) (models.Booking, error)
+	// MarkStopArrived records that the assigned driver reached stop seq,
+	// numbered from 1, of an Accepted booking once every earlier stop has
+	// been reached. Arriving again returns the booking unchanged.
+	MarkStopArrived(ctx context.Context, bookingID string, seq int) (models.Booking, error)
+	// ReportNoShow cancels an Accepted booking as a no-show once its driver
+	// has waited at the pickup as long as the policy requires, charging the
+	// no-show fee. Reporting it again retries whatever failed.
+	ReportNoShow(ctx context.Context, bookingID string) (models.Booking, error)
+	GetPayment(ctx context.Context, bookingID string) (models.Payment, error)
+	// ExpireHolds settles up to limit holds that expired by now: the booking
+	// is cancelled if no driver took it, otherwise only the hold is released.
+	// The hold of a trip under way is kept for its fare and looked at again
+	// later, as is one whose settling failed. It returns the number settled.
+	ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error)
 }
 
 type bookingService struct {
 	repo     repository.BookingRepository
 	producer *mq.Producer
+	notifier EventNotifier
+	changes  *Broadcaster
+	payments *Payments
+	promos   *Promotions
+	fares    *Fares
+	trips    TripRecorder
+	policy   cancellation.Policy
+	reserve  Reservations
+	currency string
 	logger   *slog.Logger
+	now      func() time.Time
 }
 
-func NewBookingService(repo repository.BookingRepository, producer *mq.Producer, logger *slog.Logger) BookingService {
-	return &bookingService{repo: repo, producer: producer, logger: logger}
+// NewBookingService wires the booking flow. changes must also be registered as a
+// notifier wherever bookings are updated (see mq.BookingAcceptedConsumer) so
+// watchers wake promptly. Promo codes are priced by promos, and routes with
+// stops by fares. Completed trips and cancellation fees are posted to trips. Cancellations are charged by
+// policy, and scheduled rides are bounded by reserve. defaultCurrency prices
+// legacy integer prices.
+func NewBookingService(repo repository.BookingRepository, producer *mq.Producer, notifier EventNotifier, changes *Broadcaster, payments *Payments, promos *Promotions, fares *Fares, trips TripRecorder, policy cancellation.Policy, reserve Reservations, defaultCurrency string, logger *slog.Logger) BookingService {
+	return &bookingService{repo: repo, producer: producer, notifier: notifier, changes: changes, payments: payments, promos: promos, fares: fares, trips: trips, policy: policy, reserve: reserve, currency: defaultCurrency, logger: logger, now: time.Now}
 }
 
 func (s *bookingService) CreateBooking(ctx context.Context, in CreateBookingInput) (models.Booking, error) {
+	if err := in.Validate(); err != nil {
+		return models.Booking{}, err
+	}
+	price, err := in.Price.Resolve(s.currency)
+	if err != nil {
+		return models.Booking{}, problem.ValidationError{{Field: "price", Message: "out of range"}}
+	}
+	// A route with stops is priced by the tariff, not by the client.
+	if len(in.Stops) > 0 {
+		if err := s.fares.checkPrice(price, in.PickupLoc, in.Stops, in.Dropoff); err != nil {
+			return models.Booking{}, err
+		}
+	}
+	if in.ScheduledFor != nil {
+		if err := s.reserve.Check(*in.ScheduledFor, s.now()); err != nil {
+			return models.Booking{}, err
+		}
+	}
 	bookingID := uuid.NewString()
 	rideStatus := models.RideStatusRequested
+	if in.ScheduledFor != nil {
+		rideStatus = models.RideStatusScheduled
+	}
 	var driverID *string
 
+	var redemption *repository.PromoRedemption
+	charge := price
+	if in.PromoCode != "" {
+		r, err := s.promos.Quote(ctx, in.PromoCode, in.RiderID, in.PickupLoc, price)
+		if err != nil {
+			if isPromoRejection(err) {
+				return models.Booking{}, err
+			}
+			return models.Booking{}, fmt.Errorf("%w: %w", ErrBookingNotStored, err)
+		}
+		redemption = &r
+		if charge, err = price.Sub(r.Discount); err != nil {
+			return models.Booking{}, err
+		}
+	}
+
+	// Hold the price before the booking exists, so a declined card never
+	// reaches drivers. A reservation is held when it is released, as a hold
+	// would expire long before a distant pickup.
+	if in.ScheduledFor == nil {
+		if _, err := s.payments.Authorize(ctx, bookingID, in.RiderID, charge); err != nil {
+			if errors.Is(err, ErrPaymentDeclined) || errors.Is(err, ErrPaymentUnavailable) {
+				return models.Booking{}, err
+			}
+			return models.Booking{}, fmt.Errorf("%w: %w", ErrBookingNotStored, err)
+		}
+	}
 	created, err := s.repo.Create(ctx, repository.CreateBookingParams{
-		BookingID:  bookingID,
-		PickupLoc:  in.PickupLoc,
-		Dropoff:    in.Dropoff,
-		Price:      in.Price,
-		RideStatus: rideStatus,
-		DriverID:   driverID,
+		BookingID:    bookingID,
+		RiderID:      in.RiderID,
+		PickupLoc:    in.PickupLoc,
+		Dropoff:      in.Dropoff,
+		Stops:        in.Stops,
+		Price:        price,
+		VehicleType:  in.VehicleType,
+		Passengers:   max(in.Passengers, 1),
+		RideStatus:   rideStatus,
+		DriverID:     driverID,
+		ScheduledFor: in.ScheduledFor,
+		Promo:        redemption,
 	})
 	if err != nil {
-		return models.Booking{}, err
+		// Failing that, ExpireHolds releases it once it expires.
+		if _, rerr := s.payments.Release(ctx, bookingID); rerr != nil && !errors.Is(rerr, ErrPaymentNotFound) {
+			s.logger.Error("release hold of unstored booking failed",
+				slog.String("booking_id", bookingID),
+				slog.String("err", rerr.Error()),
+			)
+		}
+		// Someone else took the last redemption since the quote.
+		if isPromoRejection(err) {
+			return models.Booking{}, err
+		}
+		return models.Booking{}, fmt.Errorf("%w: %w", ErrBookingNotStored, err)
+	}
+	if redemption != nil {
+		metrics.PromoRedemptions.Inc()
+	}
+	if created.RideStatus == models.RideStatusScheduled {
+		// Drivers hear of it when the scheduler releases it.
+		metrics.BookingsScheduled.Inc()
+		s.changes.Broadcast()
+	} else {
+		if err := s.producer.ProduceBookingCreated(ctx, createdEvent(created)); err != nil {
+			// Strong consistency for assignment: fail request if event not produced
+			return models.Booking{}, fmt.Errorf("%w: %w", ErrBookingNotDispatched, err)
+		}
+		metrics.BookingsCreated.Inc()
 	}
+	// The booking exists now. POST /bookings has no idempotency key, so
+	// failing the call would have the rider book a second ride; the webhook
+	// is lost instead.
+	if err := s.notify(ctx, models.WebhookEventBookingCreated, created.BookingID, created); err != nil {
+		s.logger.Error("queue booking.created webhook failed",
+			slog.String("booking_id", created.BookingID),
+			slog.String("err", err.Error()),
+		)
+	}
+	return created, nil
+}
 
+// isPromoRejection reports whether err is a promo code being turned down,
+// which the rider can fix, as opposed to a failure to store the booking.
+func isPromoRejection(err error) bool {
+	return errors.Is(err, promo.ErrUnknownCode) || errors.Is(err, promo.ErrNotActive) ||
+		errors.Is(err, promo.ErrNotApplicable) || errors.Is(err, promo.ErrExhausted) ||
+		errors.Is(err, promo.ErrAlreadyRedeemed)
+}
+
+func createdEvent(b models.Booking) events.BookingCreated {
 	evt := events.BookingCreated{
-		BookingID:  created.BookingID,
-		PickupLoc:  created.PickupLoc,
-		Dropoff:    created.Dropoff,
-		Price:      created.Price,
-		RideStatus: string(created.RideStatus),
-	}
-	if err := s.producer.ProduceBookingCreated(ctx, evt); err != nil {
-		// Strong consistency for assignment: fail request if event not produced
-		return models.Booking{}, err
+		BookingID:    b.BookingID,
+		PickupLoc:    b.PickupLoc,
+		Dropoff:      b.Dropoff,
+		Price:        b.Price,
+		RideStatus:   string(b.RideStatus),
+		Discount:     b.Discount,
+		VehicleType:  string(b.VehicleType),
+		Passengers:   b.Passengers,
+		ScheduledFor: b.ScheduledFor,
 	}
-	return created, nil
+	for _, st := range b.Stops {
+		evt.Stops = append(evt.Stops, st.Location)
+	}
+	if b.PromoCode != nil {
+		evt.PromoCode = *b.PromoCode
+	}
+	return evt
 }
 
 func (s *bookingService) ListBookings(ctx context.Context) ([]models.Booking, error) {
 	return s.repo.ListAll(ctx)
 }
+
+func (s *bookingService) ListRiderBookings(ctx context.Context, riderID string) ([]models.Booking, error) {
+	return s.repo.ListByRider(ctx, riderID)
+}
+
+func (s *bookingService) GetBooking(ctx context.Context, bookingID string) (models.Booking, error) {
+	b, ok, err := s.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if !ok {
+		return models.Booking{}, ErrBookingNotFound
+	}
+	return b, nil
+}
+
+func (s *bookingService) WatchBooking(ctx context.Context, bookingID string, send func(models.Booking) error) error {
+	wake, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+	tick := time.NewTicker(watchPollInterval)
+	defer tick.Stop()
+
+	var last models.Booking
+	for first := true; ; first = false {
+		cur, err := s.GetBooking(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+		if first || !reflect.DeepEqual(cur, last) {
+			if err := send(cur); err != nil {
+				return err
+			}
+			last = cur
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-tick.C:
+		}
+	}
+}
+
+func (s *bookingService) ListBookingsSince(ctx context.Context, since time.Time, limit int) ([]models.Booking, error) {
+	return s.repo.ListCreatedSince(ctx, since, limit)
+}
+
+func (s *bookingService) RepublishCreated(ctx context.Context, bookingID string) error {
+	b, err := s.GetBooking(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+	if b.RideStatus != models.RideStatusRequested {
+		return ErrBookingNotRequested
+	}
+	if err := s.producer.ProduceBookingCreated(ctx, createdEvent(b)); err != nil {
+		return fmt.Errorf("%w: %w", ErrBookingNotDispatched, err)
+	}
+	s.logger.Info("booking.created republished", slog.String("booking_id", bookingID))
+	return nil
+}
+
+func (s *bookingService) CompleteBooking(ctx context.Context, bookingID string, fare *money.Money) (models.Booking, error) {
+	b, err := s.GetBooking(ctx, bookingID)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	switch b.RideStatus {
+	case models.RideStatusCompleted:
+		return b, nil
+	case models.RideStatusAccepted:
+	default:
+		return models.Booking{}, ErrBookingNotAccepted
+	}
+	// amount is the fare before the discount; the rider is charged the rest.
+	amount := b.Price
+	if fare != nil {
+		if err := validateFare(*fare, b.Price, b.Discount); err != nil {
+			return models.Booking{}, err
+		}
+		amount = *fare
+	}
+	charge := amount
+	if b.Discount != nil {
+		if charge, err = amount.Sub(*b.Discount); err != nil {
+			return models.Booking{}, err
+		}
+	}
+
+	charged, err := s.payments.Capture(ctx, bookingID, charge)
+	switch {
+	case errors.Is(err, ErrPaymentNotFound):
+		// Booked before payments existed: there is no hold to capture.
+	case err != nil:
+		return models.Booking{}, err
+	default:
+		// A repeated call after a capture keeps what was actually charged.
+		charge, amount = *charged.Captured, *charged.Captured
+		if b.Discount != nil {
+			if amount, err = charge.Add(*b.Discount); err != nil {
+				return models.Booking{}, err
+			}
+		}
+	}
+	// Posted, published and announced before the booking is marked Completed,
+	// which ends retries. driver_svc pays the driver the earnings the event
+	// carries.
+	earnings, err := s.trips.RecordTrip(ctx, b, amount)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	evt := events.BookingCompleted{BookingID: bookingID, DriverID: *b.DriverID, Fare: amount, DriverEarnings: earnings, CompletedAt: s.now().UTC()}
+	if err := s.producer.ProduceBookingCompleted(ctx, evt); err != nil {
+		return models.Booking{}, fmt.Errorf("%w: %w", ErrBookingNotDispatched, err)
+	}
+	done := b
+	done.RideStatus = models.RideStatusCompleted
+	done.Fare = &charge
+	if err := s.notify(ctx, models.WebhookEventBookingCompleted, bookingID, done); err != nil {
+		return models.Booking{}, err
+	}
+
+	ok, err := s.repo.MarkCompleted(ctx, bookingID, charge)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if !ok {
+		// Completed or cancelled concurrently.
+		if b, err = s.GetBooking(ctx, bookingID); err != nil {
+			return models.Booking{}, err
+		}
+		if b.RideStatus != models.RideStatusCompleted {
+			s.logger.Error("booking left Accepted after its fare was captured",
+				slog.String("booking_id", bookingID),
+				slog.String("ride_status", string(b.RideStatus)),
+			)
+			return models.Booking{}, ErrBookingNotAccepted
+		}
+		return b, nil
+	}
+	metrics.BookingsCompleted.Inc()
+	s.changes.Broadcast()
+	return done, nil
+}
+
+// validateFare checks a final fare, before any discount, against the price.
+// The rider must be left something to pay after the discount.
+func validateFare(fare, price money.Money, discount *money.Money) error {
+	if fare.Currency != price.Currency {
+		return problem.ValidationError{{Field: "fare.currency", Message: "must be " + price.Currency + ", the currency of the price"}}
+	}
+	if !fare.IsPositive() {
+		return problem.ValidationError{{Field: "fare", Message: "must be > 0"}}
+	}
+	if fare.Amount > price.Amount {
+		return problem.ValidationError{{Field: "fare", Message: "must not exceed the price, " + price.String()}}
+	}
+	if discount != nil && fare.Amount <= discount.Amount {
+		return problem.ValidationError{{Field: "fare", Message: "must be more than the discount, " + discount.String()}}
+	}
+	return nil
+}
+
+func (s *bookingService) CancelBooking(ctx context.Context, bookingID string) (models.Booking, error) {
+	return s.cancel(ctx, bookingID, false)
+}
+
+func (s *bookingService) ReportNoShow(ctx context.Context, bookingID string) (models.Booking, error) {
+	return s.cancel(ctx, bookingID, true)
+}
+
+// cancel cancels bookingID at the fee the policy charges now. With noShow it
+// only cancels a no-show.
+func (s *bookingService) cancel(ctx context.Context, bookingID string, noShow bool) (models.Booking, error) {
+	b, err := s.GetBooking(ctx, bookingID)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	// The fee depends on the status, which a driver may change between the
+	// evaluation and the update; MarkCancelled only applies to the status
+	// that was evaluated, so a lost race is evaluated again.
+	for b.RideStatus != models.RideStatusCancelled {
+		view, err := cancellation.FromBooking(b)
+		if err != nil {
+			return models.Booking{}, err
+		}
+		now := s.now()
+		out, err := s.policy.Evaluate(view, now)
+		if errors.Is(err, cancellation.ErrNotCancellable) {
+			return models.Booking{}, ErrBookingNotCancellable
+		}
+		if err != nil {
+			return models.Booking{}, err
+		}
+		if noShow && out.Reason != models.CancellationNoShow {
+			return models.Booking{}, ErrNoShowTooEarly
+		}
+		c := models.Cancellation{Reason: out.Reason, Fee: out.Fee, CancelledAt: now}
+		changed, err := s.repo.MarkCancelled(ctx, bookingID, b.RideStatus, c)
+		if err != nil {
+			return models.Booking{}, err
+		}
+		if changed {
+			b.RideStatus = models.RideStatusCancelled
+			b.Cancellation = &c
+			metrics.BookingsCancelled.Inc()
+			if c.Fee.IsPositive() {
+				metrics.CancellationFeesCharged.WithLabelValues(string(c.Reason)).Inc()
+			}
+			s.changes.Broadcast()
+			break
+		}
+		if b, err = s.GetBooking(ctx, bookingID); err != nil {
+			return models.Booking{}, err
+		}
+	}
+	if noShow && (b.Cancellation == nil || b.Cancellation.Reason != models.CancellationNoShow) {
+		return models.Booking{}, ErrBookingNotCancellable
+	}
+
+	// The steps below are idempotent, so a repeated cancel finishes the job.
+	if err := s.settleCancellation(ctx, b); err != nil {
+		return models.Booking{}, err
+	}
+	evt := events.BookingCancelled{BookingID: bookingID, RideStatus: string(models.RideStatusCancelled)}
+	if c := b.Cancellation; c != nil {
+		evt.Reason, evt.Fee, evt.CancelledAt = string(c.Reason), &c.Fee, &c.CancelledAt
+	}
+	if err := s.producer.ProduceBookingCancelled(ctx, evt); err != nil {
+		return models.Booking{}, fmt.Errorf("%w: %w", ErrBookingNotDispatched, err)
+	}
+	if err := s.notify(ctx, models.WebhookEventBookingCancelled, bookingID, b); err != nil {
+		return models.Booking{}, err
+	}
+	return b, nil
+}
+
+// settleCancellation collects a cancelled booking's fee from its hold and
+// posts it to the ledger; a free cancellation releases the hold instead.
+func (s *bookingService) settleCancellation(ctx context.Context, b models.Booking) error {
+	if b.Cancellation == nil || !b.Cancellation.Fee.IsPositive() {
+		if _, err := s.payments.Release(ctx, b.BookingID); err != nil && !errors.Is(err, ErrPaymentNotFound) {
+			// The booking stays cancelled; ExpireHolds releases the hold later.
+			s.logger.Error("release hold failed",
+				slog.String("booking_id", b.BookingID),
+				slog.String("err", err.Error()),
+			)
+		}
+		return nil
+	}
+	fee := b.Cancellation.Fee
+	// Capturing the fee releases the rest of the hold.
+	_, err := s.payments.Capture(ctx, b.BookingID, fee)
+	switch {
+	case errors.Is(err, ErrPaymentNotFound), errors.Is(err, ErrPaymentSettled):
+		// Booked before payments existed, or the hold expired first: there
+		// is nothing left to take the fee from.
+		s.logger.Warn("cancellation fee not collected",
+			slog.String("booking_id", b.BookingID),
+			slog.String("fee", fee.String()),
+			slog.String("err", err.Error()),
+		)
+		return nil
+	case err != nil:
+		return err
+	}
+	return s.trips.RecordCancellationFee(ctx, b, fee)
+}
+
+func (s *bookingService) MarkArrived(ctx context.Context, bookingID string) (models.Booking, error) {
+	b, err := s.GetBooking(ctx, bookingID)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if b.RideStatus != models.RideStatusAccepted {
+		return models.Booking{}, ErrBookingNotAccepted
+	}
+	if b.ArrivedAt != nil {
+		return b, nil
+	}
+	at := s.now()
+	ok, err := s.repo.MarkArrived(ctx, bookingID, at)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if !ok {
+		// Arrived, completed or cancelled concurrently.
+		if b, err = s.GetBooking(ctx, bookingID); err != nil {
+			return models.Booking{}, err
+		}
+		if b.RideStatus != models.RideStatusAccepted || b.ArrivedAt == nil {
+			return models.Booking{}, ErrBookingNotAccepted
+		}
+		return b, nil
+	}
+	b.ArrivedAt = &at
+	s.changes.Broadcast()
+	s.logger.Info("driver arrived at pickup", slog.String("booking_id", bookingID))
+	return b, nil
+}
+
+func (s *bookingService) MarkStopArrived(ctx context.Context, bookingID string, seq int) (models.Booking, error) {
+	b, err := s.GetBooking(ctx, bookingID)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if seq < 1 || seq > len(b.Stops) {
+		return models.Booking{}, ErrStopNotFound
+	}
+	if b.RideStatus != models.RideStatusAccepted {
+		return models.Booking{}, ErrBookingNotAccepted
+	}
+	key := bookingID + "/" + strconv.Itoa(seq)
+	if b.Stops[seq-1].ArrivedAt != nil {
+		// A repeated call re-sends a notification that failed.
+		if err := s.notify(ctx, models.WebhookEventStopArrived, key, b); err != nil {
+			return models.Booking{}, err
+		}
+		return b, nil
+	}
+	for _, prev := range b.Stops[:seq-1] {
+		if prev.ArrivedAt == nil {
+			return models.Booking{}, ErrStopOutOfOrder
+		}
+	}
+	at := s.now()
+	ok, err := s.repo.MarkStopArrived(ctx, bookingID, seq, at)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if !ok {
+		// Arrived, completed or cancelled concurrently.
+		if b, err = s.GetBooking(ctx, bookingID); err != nil {
+			return models.Booking{}, err
+		}
+		if b.RideStatus != models.RideStatusAccepted || b.Stops[seq-1].ArrivedAt == nil {
+			return models.Booking{}, ErrBookingNotAccepted
+		}
+		return b, nil
+	}
+	b.Stops[seq-1].ArrivedAt = &at
+	s.changes.Broadcast()
+	s.logger.Info("driver arrived at stop", slog.String("booking_id", bookingID), slog.Int("stop", seq))
+	if err := s.notify(ctx, models.WebhookEventStopArrived, key, b); err != nil {
+		return models.Booking{}, err
+	}
+	return b, nil
+}
+
+func (s *bookingService) GetPayment(ctx context.Context, bookingID string) (models.Payment, error) {
+	return s.payments.Get(ctx, bookingID)
+}
+
+func (s *bookingService) ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	holds, err := s.payments.ListExpiredHolds(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	settled := 0
+	for _, h := range holds {
+		b, ok, err := s.repo.GetByID(ctx, h.BookingID)
+		if err != nil {
+			return settled, err
+		}
+		switch {
+		case ok && (b.RideStatus == models.RideStatusAccepted || b.RideStatus == models.RideStatusCompleted):
+			// The trip's fare is captured from this hold, so voiding it
+			// would leave nothing to charge.
+			if err := s.payments.PostponeExpiry(ctx, h.BookingID, now.Add(holdRecheckDelay)); err != nil {
+				return settled, err
+			}
+			continue
+		case ok && b.RideStatus == models.RideStatusRequested:
+			// No driver took the ride while the hold lasted.
+			_, err = s.CancelBooking(ctx, h.BookingID)
+		default:
+			_, err = s.payments.Release(ctx, h.BookingID)
+		}
+		if err != nil {
+			s.logger.Warn("expire hold failed",
+				slog.String("booking_id", h.BookingID),
+				slog.String("err", err.Error()),
+			)
+			// Retried after the holds behind it, not at the head of every batch.
+			if err := s.payments.PostponeExpiry(ctx, h.BookingID, now.Add(holdRetryDelay)); err != nil {
+				return settled, err
+			}
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+// notify queues the webhook deliveries of a change. Notifying the same type
+// and key again queues nothing new, so a failure is returned for the caller
+// to retry rather than dropped.
+func (s *bookingService) notify(ctx context.Context, eventType, key string, b models.Booking) error {
+	if err := s.notifier.Notify(ctx, eventType, key, b); err != nil {
+		return fmt.Errorf("%w: webhook %s: %w", ErrBookingNotDispatched, eventType, err)
+	}
+	return nil
+}
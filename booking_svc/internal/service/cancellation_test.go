@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/events"
+	"booking_svc/internal/ledger"
+	"booking_svc/internal/models"
+	"booking_svc/internal/service"
+)
+
+// instantFees charges the late fee as soon as a driver is assigned and the
+// no-show fee as soon as the driver arrives.
+var instantFees = cancellation.Policy{LateFeeBPS: 1000, NoShowFeeBPS: 2000}
+
+func TestLateCancellationCapturesFee(t *testing.T) {
+	f, ctx := newFixture(t, 0, instantFees), context.Background()
+	msgs := f.bus.Subscribe("booking.cancelled", "test")
+	defer msgs.Close()
+	b := f.create(t, inr(22050))
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := f.svc.CancelBooking(ctx, b.BookingID)
+		if err != nil {
+			t.Fatalf("cancel %d: %v", i, err)
+		}
+		if c := got.Cancellation; got.RideStatus != models.RideStatusCancelled || c == nil ||
+			c.Reason != models.CancellationLate || c.Fee != inr(2205) {
+			t.Fatalf("cancel %d: %+v", i, got)
+		}
+	}
+	p := f.payment(t, b.BookingID)
+	if p.Status != models.PaymentStatusCaptured || p.Captured == nil || *p.Captured != inr(2205) || len(p.Attempts) != 2 {
+		t.Fatalf("the fee must be captured once: %+v", p)
+	}
+	if held := f.gateway.Held()["INR"]; held != 0 {
+		t.Fatalf("held %d after capturing the fee", held)
+	}
+	totals, err := f.ledger.SumPostings(ctx, ledger.PlatformCancellationFees, time.Now().Add(time.Minute))
+	if err != nil || totals.Credits != 2205 {
+		t.Fatalf("cancellation fees posted once: %+v, %v", totals, err)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	m, err := msgs.Fetch(fetchCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt events.BookingCancelled
+	if err := json.Unmarshal(m.Value, &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt.Reason != "late_cancellation" || evt.Fee == nil || *evt.Fee != inr(2205) || evt.CancelledAt == nil {
+		t.Fatalf("booking.cancelled: %+v", evt)
+	}
+}
+
+func TestNoShowNeedsTheDriverToWait(t *testing.T) {
+	policy := cancellation.Policy{FreeWindow: time.Hour, NoShowWait: time.Hour, NoShowFeeBPS: 2000}
+	f, ctx := newFixture(t, 0, policy), context.Background()
+	b := f.create(t, inr(22050))
+
+	if _, err := f.svc.MarkArrived(ctx, b.BookingID); !errors.Is(err, service.ErrBookingNotAccepted) {
+		t.Fatalf("arriving before a driver is assigned: %v", err)
+	}
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.ReportNoShow(ctx, b.BookingID); !errors.Is(err, service.ErrNoShowTooEarly) {
+		t.Fatalf("no-show before arriving: %v", err)
+	}
+	arrived, err := f.svc.MarkArrived(ctx, b.BookingID)
+	if err != nil || arrived.ArrivedAt == nil {
+		t.Fatalf("arrive: %+v, %v", arrived, err)
+	}
+	again, err := f.svc.MarkArrived(ctx, b.BookingID)
+	if err != nil || !again.ArrivedAt.Equal(*arrived.ArrivedAt) {
+		t.Fatalf("arriving again must keep the first arrival: %+v, %v", again, err)
+	}
+	if _, err := f.svc.ReportNoShow(ctx, b.BookingID); !errors.Is(err, service.ErrNoShowTooEarly) {
+		t.Fatalf("no-show before the wait is over: %v", err)
+	}
+	if got, _, _ := f.bookings.GetByID(ctx, b.BookingID); got.RideStatus != models.RideStatusAccepted {
+		t.Fatalf("an early no-show must not cancel: %+v", got)
+	}
+	// Within the free window and before the wait is over, the rider cancels
+	// for free.
+	got, err := f.svc.CancelBooking(ctx, b.BookingID)
+	if err != nil || got.Cancellation == nil || got.Cancellation.Reason != models.CancellationFree {
+		t.Fatalf("cancel: %+v, %v", got, err)
+	}
+	if p := f.payment(t, b.BookingID); p.Status != models.PaymentStatusReleased {
+		t.Fatalf("a free cancellation releases the hold: %+v", p)
+	}
+	if _, err := f.svc.ReportNoShow(ctx, b.BookingID); !errors.Is(err, service.ErrBookingNotCancellable) {
+		t.Fatalf("no-show after the rider cancelled: %v", err)
+	}
+}
+
+func TestNoShowChargesTheNoShowFee(t *testing.T) {
+	f, ctx := newFixture(t, 0, instantFees), context.Background()
+	b := f.create(t, inr(22050))
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.MarkArrived(ctx, b.BookingID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.svc.ReportNoShow(ctx, b.BookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := got.Cancellation; got.RideStatus != models.RideStatusCancelled || c == nil ||
+		c.Reason != models.CancellationNoShow || c.Fee != inr(4410) {
+		t.Fatalf("no-show: %+v", got)
+	}
+	// The rider cancelling afterwards changes nothing.
+	if again, err := f.svc.CancelBooking(ctx, b.BookingID); err != nil || again.Cancellation.Reason != models.CancellationNoShow {
+		t.Fatalf("cancel after no-show: %+v, %v", again, err)
+	}
+	if p := f.payment(t, b.BookingID); p.Captured == nil || *p.Captured != inr(4410) {
+		t.Fatalf("no-show fee captured: %+v", p)
+	}
+}
+
+func TestUncollectableFeeStillCancels(t *testing.T) {
+	f, ctx := newFixture(t, 0, instantFees), context.Background()
+	b := f.create(t, inr(22050))
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+	// The hold was already given back, say by hold expiry.
+	if _, err := service.NewPayments(f.payments, f.gateway, time.Hour, discard).Release(ctx, b.BookingID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.svc.CancelBooking(ctx, b.BookingID)
+	if err != nil || got.Cancellation == nil || got.Cancellation.Fee != inr(2205) {
+		t.Fatalf("cancel: %+v, %v", got, err)
+	}
+	if totals, _ := f.ledger.SumPostings(ctx, ledger.PlatformCancellationFees, time.Now().Add(time.Minute)); totals.Credits != 0 {
+		t.Fatalf("an uncollected fee must not be posted: %+v", totals)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"net/http"
+
+	"booking_svc/internal/problem"
+	"booking_svc/internal/promo"
+)
+
+// Problems maps every sentinel this package returns to a status and stable
+// problem code. The HTTP and gRPC transports both render errors through it.
+var Problems = problem.Mapper{
+	{Err: ErrBookingNotFound, Status: http.StatusNotFound, Code: problem.CodeBookingNotFound},
+	{Err: ErrSubscriptionNotFound, Status: http.StatusNotFound, Code: problem.CodeWebhookNotFound},
+	{Err: ErrPaymentNotFound, Status: http.StatusNotFound, Code: problem.CodePaymentNotFound},
+	{Err: ErrPromotionNotFound, Status: http.StatusNotFound, Code: problem.CodePromoNotFound},
+	{Err: ErrStopNotFound, Status: http.StatusNotFound, Code: problem.CodeStopNotFound},
+	{Err: ErrBookingNotRequested, Status: http.StatusConflict, Code: problem.CodeBookingNotRequested},
+	{Err: ErrBookingNotAccepted, Status: http.StatusConflict, Code: problem.CodeBookingNotAccepted},
+	{Err: ErrBookingNotCancellable, Status: http.StatusConflict, Code: problem.CodeBookingNotCancellable},
+	{Err: ErrNoShowTooEarly, Status: http.StatusConflict, Code: problem.CodeNoShowTooEarly},
+	{Err: ErrStopOutOfOrder, Status: http.StatusConflict, Code: problem.CodeStopOutOfOrder},
+	{Err: ErrPaymentSettled, Status: http.StatusConflict, Code: problem.CodePaymentSettled},
+	{Err: ErrPromotionExists, Status: http.StatusConflict, Code: problem.CodePromoExists},
+	{Err: ErrBookingNotCompleted, Status: http.StatusConflict, Code: problem.CodeBookingNotCompleted},
+	{Err: ErrRatingExists, Status: http.StatusConflict, Code: problem.CodeRatingExists},
+	{Err: promo.ErrUnknownCode, Status: http.StatusUnprocessableEntity, Code: problem.CodePromoUnknown},
+	{Err: promo.ErrNotActive, Status: http.StatusUnprocessableEntity, Code: problem.CodePromoNotActive},
+	{Err: promo.ErrNotApplicable, Status: http.StatusUnprocessableEntity, Code: problem.CodePromoNotApplicable},
+	{Err: promo.ErrExhausted, Status: http.StatusUnprocessableEntity, Code: problem.CodePromoExhausted},
+	{Err: promo.ErrAlreadyRedeemed, Status: http.StatusUnprocessableEntity, Code: problem.CodePromoAlreadyRedeemed},
+	{Err: ErrPaymentDeclined, Status: http.StatusPaymentRequired, Code: problem.CodePaymentDeclined},
+	{Err: ErrPaymentUnavailable, Status: http.StatusServiceUnavailable, Code: problem.CodePaymentUnavailable},
+	{Err: ErrBookingNotStored, Status: http.StatusServiceUnavailable, Code: problem.CodeBookingNotStored},
+	{Err: ErrBookingNotDispatched, Status: http.StatusServiceUnavailable, Code: problem.CodeBookingNotDispatched},
+	{Err: ErrRatingNotDispatched, Status: http.StatusServiceUnavailable, Code: problem.CodeRatingNotDispatched},
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"booking_svc/internal/fare"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+)
+
+// FareService quotes a route before it is booked.
+type FareService interface {
+	EstimateFare(ctx context.Context, in EstimateFareInput) (fare.Estimate, error)
+}
+
+type EstimateFareInput struct {
+	PickupLoc models.Location
+	Dropoff   models.Location
+	Stops     []models.Location
+}
+
+// Validate checks the route as CreateBooking would.
+func (in EstimateFareInput) Validate() error {
+	if errs := validateRoute(in.PickupLoc, in.Stops, in.Dropoff); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Fares prices routes with a tariff.
+type Fares struct {
+	tariff fare.Tariff
+}
+
+func NewFares(tariff fare.Tariff) *Fares {
+	return &Fares{tariff: tariff}
+}
+
+func (f *Fares) EstimateFare(_ context.Context, in EstimateFareInput) (fare.Estimate, error) {
+	if err := in.Validate(); err != nil {
+		return fare.Estimate{}, err
+	}
+	return f.tariff.Estimate(in.PickupLoc, in.Stops, in.Dropoff)
+}
+
+// checkPrice refuses a price other than the fare the tariff quotes for the
+// route.
+func (f *Fares) checkPrice(price money.Money, pickup models.Location, stops []models.Location, dropoff models.Location) error {
+	est, err := f.tariff.Estimate(pickup, stops, dropoff)
+	if err != nil {
+		return err
+	}
+	if price != est.Fare {
+		return problem.ValidationError{{Field: "price", Message: "must be " + est.Fare.String() + ", the fare estimate for this route"}}
+	}
+	return nil
+}
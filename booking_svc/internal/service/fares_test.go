@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/models"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+)
+
+func TestRouteWithStopsIsPricedByTheTariff(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	route := service.EstimateFareInput{
+		PickupLoc: models.Location{Lat: 12.9, Lng: 77.6},
+		Stops:     []models.Location{{Lat: 12.92, Lng: 77.61}},
+		Dropoff:   models.Location{Lat: 12.95, Lng: 77.64},
+	}
+	est, err := service.NewFares(tariff).EstimateFare(ctx, route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := service.CreateBookingInput{RiderID: "r-1", PickupLoc: route.PickupLoc, Stops: route.Stops, Dropoff: route.Dropoff}
+
+	for _, price := range []int64{est.Fare.Amount - 1, est.Fare.Amount + 1} {
+		in.Price = inr(price)
+		var verr problem.ValidationError
+		if _, err := f.svc.CreateBooking(ctx, in); !errors.As(err, &verr) || verr[0].Field != "price" {
+			t.Fatalf("price %d for a route quoted at %d: %v", price, est.Fare.Amount, err)
+		}
+	}
+	if held := f.gateway.Held()["INR"]; held != 0 {
+		t.Fatalf("held %d for refused bookings", held)
+	}
+
+	in.Price = est.Fare
+	b, err := f.svc.CreateBooking(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Price != est.Fare || len(b.Stops) != 1 {
+		t.Fatalf("booking: %+v", b)
+	}
+}
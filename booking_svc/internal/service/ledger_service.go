@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"booking_svc/internal/ledger"
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type LedgerService interface {
+	// DriverBalance returns what the platform owes driverID as of asOf: their
+	// earnings from trips less the payouts driver_svc has paid them.
+	DriverBalance(ctx context.Context, driverID string, asOf time.Time) (ledger.Balance, error)
+	// DriverStatement lists driverID's postings over [from, to) between the
+	// opening and closing balances.
+	DriverStatement(ctx context.Context, driverID string, from, to time.Time) (ledger.Statement, error)
+}
+
+// TripRecorder is the slice of the ledger the booking flow depends on.
+type TripRecorder interface {
+	// RecordTrip posts a completed trip with fare, before any discount, and
+	// returns the driver's earnings from it. Recording the same booking again
+	// posts nothing and returns the earnings posted the first time.
+	RecordTrip(ctx context.Context, b models.Booking, fare money.Money) (money.Money, error)
+	// RecordCancellationFee posts the fee the rider was charged for
+	// cancelling b. Recording the same booking again posts nothing.
+	RecordCancellationFee(ctx context.Context, b models.Booking, fee money.Money) error
+}
+
+// Ledger posts completed trips to the double-entry ledger and reads driver
+// balances back from it.
+type Ledger struct {
+	repo       repository.LedgerRepository
+	commission ledger.Rate
+	// currency prices the zero balance of an account with no postings yet.
+	currency string
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewLedger splits every fare at commission between the platform and the
+// driver.
+func NewLedger(repo repository.LedgerRepository, commission ledger.Rate, defaultCurrency string, logger *slog.Logger) *Ledger {
+	return &Ledger{repo: repo, commission: commission, currency: defaultCurrency, logger: logger, now: time.Now}
+}
+
+func (l *Ledger) RecordTrip(ctx context.Context, b models.Booking, fare money.Money) (money.Money, error) {
+	if b.DriverID == nil {
+		return money.Money{}, errors.New("ledger: trip " + b.BookingID + " has no driver")
+	}
+	var discount money.Money
+	if b.Discount != nil {
+		discount = *b.Discount
+	}
+	e, err := ledger.Trip(b.BookingID, b.RiderID, *b.DriverID, fare, discount, l.commission, l.now())
+	if err != nil {
+		return money.Money{}, err
+	}
+	_, earnings, err := l.commission.Split(fare)
+	if err != nil {
+		return money.Money{}, err
+	}
+	e.ID = uuid.NewString()
+	posted, err := l.repo.PostEntry(ctx, e)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !posted {
+		// The commission may have changed since; what was posted stands.
+		return l.postedEarnings(ctx, e.Reference, *b.DriverID, earnings.Currency)
+	}
+	metrics.LedgerEntriesPosted.Inc()
+	l.logger.Info("trip posted to ledger",
+		slog.String("booking_id", b.BookingID),
+		slog.String("driver_id", *b.DriverID),
+		slog.String("fare", fare.String()),
+	)
+	return earnings, nil
+}
+
+// postedEarnings returns what the entry posted under reference credited
+// driverID, in currency if it credited nothing.
+func (l *Ledger) postedEarnings(ctx context.Context, reference, driverID, currency string) (money.Money, error) {
+	e, ok, err := l.repo.GetEntry(ctx, reference)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !ok {
+		return money.Money{}, errors.New("ledger: entry " + reference + " vanished after posting")
+	}
+	earnings := money.Money{Currency: currency}
+	for _, p := range e.Postings {
+		if p.Account == ledger.DriverAccount(driverID) && p.Side == ledger.Credit {
+			earnings = p.Amount
+		}
+	}
+	return earnings, nil
+}
+
+func (l *Ledger) RecordCancellationFee(ctx context.Context, b models.Booking, fee money.Money) error {
+	e, err := ledger.CancellationFee(b.BookingID, b.RiderID, fee, l.now())
+	if err != nil {
+		return err
+	}
+	e.ID = uuid.NewString()
+	posted, err := l.repo.PostEntry(ctx, e)
+	if err != nil {
+		return err
+	}
+	if posted {
+		metrics.LedgerEntriesPosted.Inc()
+		l.logger.Info("cancellation fee posted to ledger",
+			slog.String("booking_id", b.BookingID),
+			slog.String("fee", fee.String()),
+		)
+	}
+	return nil
+}
+
+// RecordPayout posts amount paid to driverID by driver_svc's payoutID. It
+// reports false, posting nothing, if the payout is already posted.
+func (l *Ledger) RecordPayout(ctx context.Context, payoutID, driverID string, amount money.Money, paidAt time.Time) (bool, error) {
+	e, err := ledger.Payout(payoutID, driverID, amount, paidAt)
+	if err != nil {
+		return false, err
+	}
+	e.ID = uuid.NewString()
+	posted, err := l.repo.PostEntry(ctx, e)
+	if err != nil {
+		return false, err
+	}
+	if posted {
+		metrics.LedgerEntriesPosted.Inc()
+		l.logger.Info("payout posted to ledger",
+			slog.String("payout_id", payoutID),
+			slog.String("driver_id", driverID),
+			slog.String("amount", amount.String()),
+		)
+	}
+	return posted, nil
+}
+
+func (l *Ledger) DriverBalance(ctx context.Context, driverID string, asOf time.Time) (ledger.Balance, error) {
+	acct, err := l.driverAccount(ctx, driverID)
+	if err != nil {
+		return ledger.Balance{}, err
+	}
+	totals, err := l.repo.SumPostings(ctx, acct.Code, asOf)
+	if err != nil {
+		return ledger.Balance{}, err
+	}
+	return ledger.Balance{
+		Account: acct.Code,
+		AsOf:    asOf,
+		Balance: money.Money{Amount: totals.Balance(acct.Type), Currency: acct.Currency},
+	}, nil
+}
+
+func (l *Ledger) DriverStatement(ctx context.Context, driverID string, from, to time.Time) (ledger.Statement, error) {
+	acct, err := l.driverAccount(ctx, driverID)
+	if err != nil {
+		return ledger.Statement{}, err
+	}
+	opening, err := l.repo.SumPostings(ctx, acct.Code, from)
+	if err != nil {
+		return ledger.Statement{}, err
+	}
+	lines, err := l.repo.ListLines(ctx, acct.Code, from, to)
+	if err != nil {
+		return ledger.Statement{}, err
+	}
+	return ledger.NewStatement(acct, from, to, opening, lines), nil
+}
+
+// driverAccount returns driverID's earnings account. A driver who has not
+// completed a trip yet has none, and reads as an empty one.
+func (l *Ledger) driverAccount(ctx context.Context, driverID string) (ledger.Account, error) {
+	code := ledger.DriverAccount(driverID)
+	acct, ok, err := l.repo.GetAccount(ctx, code)
+	if err != nil {
+		return ledger.Account{}, err
+	}
+	if !ok {
+		return ledger.Account{Code: code, Type: ledger.Liability, Currency: l.currency}, nil
+	}
+	return acct, nil
+}
@@ -0,0 +1,35 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository/memory"
+	"booking_svc/internal/service"
+)
+
+func TestRecordTripAgainReturnsPostedEarnings(t *testing.T) {
+	repo, ctx := memory.NewLedgerRepo(), context.Background()
+	driver := "d-1"
+	b := models.Booking{BookingID: "b-1", RiderID: "r-1", DriverID: &driver}
+
+	earnings, err := service.NewLedger(repo, 2000, "INR", discard).RecordTrip(ctx, b, inr(10000))
+	if err != nil || earnings != inr(8000) {
+		t.Fatalf("first record: %v, %v", earnings, err)
+	}
+	// The commission changed before the trip was recorded again.
+	again, err := service.NewLedger(repo, 3000, "INR", discard).RecordTrip(ctx, b, inr(10000))
+	if err != nil || again != inr(8000) {
+		t.Fatalf("second record: want the posted %v, got %v, %v", earnings, again, err)
+	}
+
+	// A trip that earned the driver nothing posts no driver line.
+	b.BookingID = "b-2"
+	free := service.NewLedger(repo, 10000, "INR", discard)
+	for i := 0; i < 2; i++ {
+		if got, err := free.RecordTrip(ctx, b, inr(10000)); err != nil || got != inr(0) {
+			t.Fatalf("record %d at full commission: %v, %v", i, got, err)
+		}
+	}
+}
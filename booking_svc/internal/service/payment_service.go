@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/repository"
+)
+
+var (
+	// ErrPaymentDeclined means the gateway refused the hold; gateways wrap it
+	// in their decline errors. Nothing was charged.
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrPaymentUnavailable means the gateway kept failing after retries. The
+	// call is safe to repeat: every operation is idempotent per booking.
+	ErrPaymentUnavailable = errors.New("payment provider unavailable")
+	ErrPaymentNotFound    = errors.New("payment not found")
+	// ErrPaymentSettled means the hold was already released, so there is
+	// nothing left to capture.
+	ErrPaymentSettled = errors.New("payment already settled")
+)
+
+// PaymentGateway is the payment provider. Every call carries an idempotency
+// key: repeating a call with a key the gateway has seen returns the original
+// outcome instead of acting twice, so callers may retry freely.
+type PaymentGateway interface {
+	// Authorize places a hold for req.Amount and returns its authorization id.
+	Authorize(ctx context.Context, key string, req AuthorizeRequest) (string, error)
+	// Capture charges amount, at most the held amount, against a hold.
+	Capture(ctx context.Context, key, authorizationID string, amount money.Money) error
+	// Void releases a hold without charging.
+	Void(ctx context.Context, key, authorizationID string) error
+}
+
+type AuthorizeRequest struct {
+	// Reference is the booking the hold is for.
+	Reference string
+	Customer  string
+	Amount    money.Money
+}
+
+const (
+	gatewayAttempts = 3
+	gatewayBackoff  = 100 * time.Millisecond
+)
+
+// Payments places, captures and releases the authorization hold of each
+// booking, recording every gateway call as a payment attempt.
+type Payments struct {
+	repo    repository.PaymentRepository
+	gateway PaymentGateway
+	holdTTL time.Duration
+	logger  *slog.Logger
+	now     func() time.Time
+	backoff time.Duration
+}
+
+// NewPayments holds fares on gateway for holdTTL, after which the hold is
+// released by ExpireHolds.
+func NewPayments(repo repository.PaymentRepository, gateway PaymentGateway, holdTTL time.Duration, logger *slog.Logger) *Payments {
+	return &Payments{repo: repo, gateway: gateway, holdTTL: holdTTL, logger: logger, now: time.Now, backoff: gatewayBackoff}
+}
+
+// idempotencyKey is the same for every retry of an operation on a booking,
+// across requests and replicas.
+func idempotencyKey(bookingID string, op models.PaymentOperation) string {
+	return bookingID + ":" + string(op)
+}
+
+// Authorize records a payment for bookingID and places its hold.
+func (p *Payments) Authorize(ctx context.Context, bookingID, riderID string, amount money.Money) (models.Payment, error) {
+	pay, err := p.repo.CreatePayment(ctx, repository.CreatePaymentParams{
+		BookingID: bookingID,
+		RiderID:   riderID,
+		Amount:    amount,
+		ExpiresAt: p.now().Add(p.holdTTL),
+	})
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return p.authorize(ctx, pay)
+}
+
+// Hold is Authorize for a booking whose authorization may already have been
+// attempted, as when a scheduled booking's release is retried: an authorized
+// hold is returned as is, a pending one is placed under the same key and a
+// declined one fails again.
+func (p *Payments) Hold(ctx context.Context, bookingID, riderID string, amount money.Money) (models.Payment, error) {
+	pay, ok, err := p.repo.GetPayment(ctx, bookingID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if !ok {
+		return p.Authorize(ctx, bookingID, riderID, amount)
+	}
+	switch pay.Status {
+	case models.PaymentStatusAuthorized:
+		return pay, nil
+	case models.PaymentStatusPending:
+		return p.authorize(ctx, pay)
+	case models.PaymentStatusFailed:
+		return pay, fmt.Errorf("%w: %s", ErrPaymentDeclined, pay.FailureReason)
+	default:
+		return pay, fmt.Errorf("%w: payment is %s", ErrPaymentSettled, pay.Status)
+	}
+}
+
+// authorize places the hold for a pending payment. A gateway outage leaves
+// the payment pending, for Release to settle once the hold expires.
+func (p *Payments) authorize(ctx context.Context, pay models.Payment) (models.Payment, error) {
+	var authID string
+	err := p.call(ctx, pay.BookingID, models.PaymentOpAuthorize, func(ctx context.Context, key string) error {
+		var err error
+		authID, err = p.gateway.Authorize(ctx, key, AuthorizeRequest{Reference: pay.BookingID, Customer: pay.RiderID, Amount: pay.Amount})
+		return err
+	})
+	if errors.Is(err, ErrPaymentDeclined) {
+		reason := err.Error()
+		if _, terr := p.transition(ctx, pay, repository.PaymentUpdate{Status: models.PaymentStatusFailed, FailureReason: &reason}); terr != nil {
+			return pay, terr
+		}
+		return pay, err
+	}
+	if err != nil {
+		return pay, err
+	}
+	return p.transition(ctx, pay, repository.PaymentUpdate{Status: models.PaymentStatusAuthorized, AuthorizationID: &authID})
+}
+
+// Capture charges amount against the booking's hold. Capturing a payment
+// that is already captured returns it unchanged.
+func (p *Payments) Capture(ctx context.Context, bookingID string, amount money.Money) (models.Payment, error) {
+	pay, err := p.get(ctx, bookingID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	switch pay.Status {
+	case models.PaymentStatusCaptured:
+		return pay, nil
+	case models.PaymentStatusAuthorized:
+	default:
+		return pay, fmt.Errorf("%w: payment is %s", ErrPaymentSettled, pay.Status)
+	}
+	err = p.call(ctx, bookingID, models.PaymentOpCapture, func(ctx context.Context, key string) error {
+		return p.gateway.Capture(ctx, key, pay.AuthorizationID, amount)
+	})
+	if err != nil {
+		return pay, err
+	}
+	return p.transition(ctx, pay, repository.PaymentUpdate{Status: models.PaymentStatusCaptured, Captured: &amount})
+}
+
+// Release voids the booking's hold. Payments that hold nothing — released,
+// captured or declined — are returned unchanged.
+func (p *Payments) Release(ctx context.Context, bookingID string) (models.Payment, error) {
+	pay, err := p.get(ctx, bookingID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	switch pay.Status {
+	case models.PaymentStatusReleased, models.PaymentStatusCaptured, models.PaymentStatusFailed:
+		return pay, nil
+	case models.PaymentStatusPending:
+		// The authorization's outcome was never recorded. Repeating it under
+		// the same key yields the hold's id, or places the hold the first call
+		// never got to, so there is always a definite hold to void.
+		pay, err = p.authorize(ctx, pay)
+		if errors.Is(err, ErrPaymentDeclined) {
+			return pay, nil
+		}
+		if err != nil || pay.Status != models.PaymentStatusAuthorized {
+			return pay, err
+		}
+	}
+	err = p.call(ctx, bookingID, models.PaymentOpVoid, func(ctx context.Context, key string) error {
+		return p.gateway.Void(ctx, key, pay.AuthorizationID)
+	})
+	if err != nil {
+		return pay, err
+	}
+	return p.transition(ctx, pay, repository.PaymentUpdate{Status: models.PaymentStatusReleased})
+}
+
+// Get returns the booking's payment with its attempts.
+func (p *Payments) Get(ctx context.Context, bookingID string) (models.Payment, error) {
+	pay, err := p.get(ctx, bookingID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if pay.Attempts, err = p.repo.ListAttempts(ctx, bookingID); err != nil {
+		return models.Payment{}, err
+	}
+	return pay, nil
+}
+
+// ListExpiredHolds returns up to limit payments still holding funds past
+// their expiry.
+func (p *Payments) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]models.Payment, error) {
+	return p.repo.ListExpiredHolds(ctx, now, limit)
+}
+
+// PostponeExpiry keeps the booking's hold out of ListExpiredHolds until until.
+func (p *Payments) PostponeExpiry(ctx context.Context, bookingID string, until time.Time) error {
+	return p.repo.PostponeExpiry(ctx, bookingID, until)
+}
+
+func (p *Payments) get(ctx context.Context, bookingID string) (models.Payment, error) {
+	pay, ok, err := p.repo.GetPayment(ctx, bookingID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	if !ok {
+		return models.Payment{}, ErrPaymentNotFound
+	}
+	return pay, nil
+}
+
+// transition applies u if pay's status is unchanged and returns the stored
+// payment. When another caller moved it first, their outcome is returned.
+func (p *Payments) transition(ctx context.Context, pay models.Payment, u repository.PaymentUpdate) (models.Payment, error) {
+	if _, err := p.repo.UpdatePayment(ctx, pay.BookingID, pay.Status, u); err != nil {
+		return pay, err
+	}
+	return p.get(ctx, pay.BookingID)
+}
+
+// call runs one gateway operation under the booking's idempotency key and
+// records each attempt. Failures other than declines are retried with
+// backoff under the same key, so the gateway acts at most once.
+func (p *Payments) call(ctx context.Context, bookingID string, op models.PaymentOperation, fn func(ctx context.Context, key string) error) error {
+	key := idempotencyKey(bookingID, op)
+	var err error
+	for attempt := 0; attempt < gatewayAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %s: %w", ErrPaymentUnavailable, op, err)
+			case <-time.After(p.backoff << (attempt - 1)):
+			}
+		}
+		err = fn(ctx, key)
+		p.record(ctx, bookingID, op, key, err)
+		switch {
+		case err == nil:
+			metrics.PaymentGatewayCalls.WithLabelValues(string(op), "ok").Inc()
+			return nil
+		case errors.Is(err, ErrPaymentDeclined):
+			metrics.PaymentGatewayCalls.WithLabelValues(string(op), "declined").Inc()
+			return err
+		}
+		metrics.PaymentGatewayCalls.WithLabelValues(string(op), "error").Inc()
+		p.logger.Warn("payment gateway call failed",
+			slog.String("booking_id", bookingID),
+			slog.String("operation", string(op)),
+			slog.Int("attempt", attempt+1),
+			slog.String("err", err.Error()),
+		)
+	}
+	return fmt.Errorf("%w: %s: %w", ErrPaymentUnavailable, op, err)
+}
+
+// record keeps the attempt log. It is an audit trail, so a failure to write
+// it does not fail the operation.
+func (p *Payments) record(ctx context.Context, bookingID string, op models.PaymentOperation, key string, callErr error) {
+	params := repository.RecordPaymentAttemptParams{BookingID: bookingID, Operation: op, IdempotencyKey: key, Succeeded: callErr == nil}
+	if callErr != nil {
+		msg := callErr.Error()
+		params.Error = &msg
+	}
+	if err := p.repo.RecordAttempt(ctx, params); err != nil {
+		p.logger.Error("record payment attempt failed",
+			slog.String("booking_id", bookingID),
+			slog.String("operation", string(op)),
+			slog.String("err", err.Error()),
+		)
+	}
+}
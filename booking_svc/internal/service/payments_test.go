@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/models"
+	"booking_svc/internal/service"
+)
+
+// pastHolds is a time after every hold the fixture places has expired.
+func pastHolds() time.Time { return time.Now().Add(2 * time.Hour) }
+
+func TestCaptureOnCompletion(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	b := f.create(t, inr(22050))
+	if p := f.payment(t, b.BookingID); p.Status != models.PaymentStatusAuthorized || p.Amount != inr(22050) || p.AuthorizationID == "" {
+		t.Fatalf("after create: %+v", p)
+	}
+	if held := f.gateway.Held()["INR"]; held != 22050 {
+		t.Fatalf("held %d", held)
+	}
+
+	if _, err := f.svc.CompleteBooking(ctx, b.BookingID, nil); !errors.Is(err, service.ErrBookingNotAccepted) {
+		t.Fatalf("completing a Requested booking: %v", err)
+	}
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+	over := inr(30000)
+	if _, err := f.svc.CompleteBooking(ctx, b.BookingID, &over); err == nil {
+		t.Fatal("a fare above the hold must be rejected")
+	}
+	fare := inr(19900)
+	done, err := f.svc.CompleteBooking(ctx, b.BookingID, &fare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.RideStatus != models.RideStatusCompleted || done.Fare == nil || *done.Fare != fare {
+		t.Fatalf("completed: %+v", done)
+	}
+	// Completing again is a no-op that keeps the original fare.
+	again, err := f.svc.CompleteBooking(ctx, b.BookingID, nil)
+	if err != nil || *again.Fare != fare {
+		t.Fatalf("second complete: %+v, %v", again, err)
+	}
+	p := f.payment(t, b.BookingID)
+	if p.Status != models.PaymentStatusCaptured || p.Captured == nil || *p.Captured != fare || len(p.Attempts) != 2 {
+		t.Fatalf("after capture: %+v", p)
+	}
+	if _, err := f.svc.CancelBooking(ctx, b.BookingID); !errors.Is(err, service.ErrBookingNotCancellable) {
+		t.Fatalf("cancelling a completed trip: %v", err)
+	}
+}
+
+func TestDeclinedHoldCreatesNoBooking(t *testing.T) {
+	f := newFixture(t, 10000, cancellation.Policy{})
+	_, err := f.svc.CreateBooking(context.Background(), service.CreateBookingInput{
+		RiderID:   "r-1",
+		PickupLoc: models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:   models.Location{Lat: 12.95, Lng: 77.64},
+		Price:     inr(22050),
+	})
+	if !errors.Is(err, service.ErrPaymentDeclined) {
+		t.Fatalf("want a decline, got %v", err)
+	}
+	if all, _ := f.bookings.ListAll(context.Background()); len(all) != 0 {
+		t.Fatalf("declined booking was stored: %+v", all)
+	}
+}
+
+func TestCancelReleasesHoldOnce(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	msgs := f.bus.Subscribe("booking.cancelled", "test")
+	defer msgs.Close()
+	b := f.create(t, inr(22050))
+
+	for i := 0; i < 2; i++ {
+		got, err := f.svc.CancelBooking(ctx, b.BookingID)
+		if err != nil || got.RideStatus != models.RideStatusCancelled {
+			t.Fatalf("cancel %d: %+v, %v", i, got, err)
+		}
+		// No driver was assigned, so it is free.
+		if c := got.Cancellation; c == nil || c.Reason != models.CancellationFree || c.Fee != inr(0) {
+			t.Fatalf("cancel %d: %+v", i, got.Cancellation)
+		}
+	}
+	p := f.payment(t, b.BookingID)
+	if p.Status != models.PaymentStatusReleased || len(p.Attempts) != 2 || f.gateway.Held()["INR"] != 0 {
+		t.Fatalf("after cancel: %+v, held %v", p, f.gateway.Held())
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if m, err := msgs.Fetch(fetchCtx); err != nil || string(m.Key) != b.BookingID {
+		t.Fatalf("booking.cancelled: %+v, %v", m, err)
+	}
+}
+
+func TestTransientFailuresRetryUnderOneKey(t *testing.T) {
+	f := newFixture(t, 0, cancellation.Policy{})
+	failures := 1
+	var keys []string
+	f.gateway.Fail = func(op, key string) error {
+		keys = append(keys, key)
+		if failures > 0 {
+			failures--
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	b := f.create(t, inr(22050))
+
+	p := f.payment(t, b.BookingID)
+	if p.Status != models.PaymentStatusAuthorized || len(p.Attempts) != 2 || p.Attempts[0].Succeeded || !p.Attempts[1].Succeeded {
+		t.Fatalf("attempts: %+v", p)
+	}
+	if len(keys) != 2 || keys[0] != keys[1] || keys[0] != b.BookingID+":authorize" {
+		t.Fatalf("idempotency keys: %v", keys)
+	}
+	if held := f.gateway.Held()["INR"]; held != 22050 {
+		t.Fatalf("one hold expected, held %d", held)
+	}
+}
+
+func TestGatewayOutageFailsCreateAndExpiryReleases(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	// The gateway is unreachable, leaving the first payment pending.
+	f.gateway.Fail = func(op, key string) error { return errors.New("timeout") }
+	_, err := f.svc.CreateBooking(ctx, service.CreateBookingInput{
+		RiderID:   "r-1",
+		PickupLoc: models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:   models.Location{Lat: 12.95, Lng: 77.64},
+		Price:     inr(22050),
+	})
+	if !errors.Is(err, service.ErrPaymentUnavailable) {
+		t.Fatalf("want unavailable, got %v", err)
+	}
+	f.gateway.Fail = nil
+	b := f.create(t, inr(10000))
+
+	n, err := f.svc.ExpireHolds(ctx, pastHolds(), 100)
+	if err != nil || n != 2 {
+		t.Fatalf("expired %d, %v", n, err)
+	}
+	if got, _, _ := f.bookings.GetByID(ctx, b.BookingID); got.RideStatus != models.RideStatusCancelled {
+		t.Fatalf("an unaccepted booking with an expired hold must be cancelled: %+v", got)
+	}
+	if held := f.gateway.Held(); held["INR"] != 0 {
+		t.Fatalf("holds left: %v", held)
+	}
+	if n, _ := f.svc.ExpireHolds(ctx, pastHolds(), 100); n != 0 {
+		t.Fatalf("second run expired %d", n)
+	}
+}
+
+func TestAcceptedTripSurvivesHoldExpiry(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	b := f.create(t, inr(22050))
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if n, err := f.svc.ExpireHolds(ctx, pastHolds(), 100); err != nil || n != 0 {
+			t.Fatalf("run %d expired %d, %v", i, n, err)
+		}
+	}
+	if p := f.payment(t, b.BookingID); p.Status != models.PaymentStatusAuthorized {
+		t.Fatalf("the hold of a trip under way must be kept: %+v", p)
+	}
+	fare := inr(19900)
+	done, err := f.svc.CompleteBooking(ctx, b.BookingID, &fare)
+	if err != nil || done.RideStatus != models.RideStatusCompleted {
+		t.Fatalf("complete: %+v, %v", done, err)
+	}
+	if p := f.payment(t, b.BookingID); p.Status != models.PaymentStatusCaptured || *p.Captured != fare {
+		t.Fatalf("after capture: %+v", p)
+	}
+}
+
+func TestFailingReleaseDoesNotStarveOtherHolds(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	stuck := f.create(t, inr(22050))
+	next := f.create(t, inr(10000))
+	f.gateway.Fail = func(op, key string) error {
+		if key == stuck.BookingID+":void" {
+			return errors.New("timeout")
+		}
+		return nil
+	}
+	// The booking is cancelled but its hold could not be voided.
+	if _, err := f.svc.CancelBooking(ctx, stuck.BookingID); err != nil {
+		t.Fatal(err)
+	}
+
+	now := pastHolds()
+	// One hold per batch: the stuck one is tried first, then waits its turn.
+	if n, err := f.svc.ExpireHolds(ctx, now, 1); err != nil || n != 0 {
+		t.Fatalf("first batch: %d, %v", n, err)
+	}
+	if n, err := f.svc.ExpireHolds(ctx, now, 1); err != nil || n != 1 {
+		t.Fatalf("second batch: %d, %v", n, err)
+	}
+	if got, _, _ := f.bookings.GetByID(ctx, next.BookingID); got.RideStatus != models.RideStatusCancelled {
+		t.Fatalf("the hold behind the stuck one must be settled: %+v", got)
+	}
+	if n, err := f.svc.ExpireHolds(ctx, now, 1); err != nil || n != 0 {
+		t.Fatalf("nothing is due until the retry: %d, %v", n, err)
+	}
+
+	f.gateway.Fail = nil
+	if n, err := f.svc.ExpireHolds(ctx, now.Add(time.Hour), 10); err != nil || n != 1 {
+		t.Fatalf("retry: %d, %v", n, err)
+	}
+	if held := f.gateway.Held()["INR"]; held != 0 {
+		t.Fatalf("held %d", held)
+	}
+}
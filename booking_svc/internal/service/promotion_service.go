@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/promo"
+	"booking_svc/internal/repository"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promotion not found")
+	// ErrPromotionExists means another promotion already has the code.
+	ErrPromotionExists = errors.New("promo code already exists")
+)
+
+// PromotionService manages promotions; redemption happens in CreateBooking.
+type PromotionService interface {
+	// CreatePromotion stores p under its normalized code. A zero StartsAt
+	// starts it now.
+	CreatePromotion(ctx context.Context, p promo.Promotion) (promo.Promotion, error)
+	GetPromotion(ctx context.Context, code string) (promo.Promotion, error)
+	ListPromotions(ctx context.Context) ([]promo.Promotion, error)
+}
+
+// Promotions looks promotions up and prices their discounts for the booking
+// flow.
+type Promotions struct {
+	repo   repository.PromotionRepository
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewPromotions(repo repository.PromotionRepository, logger *slog.Logger) *Promotions {
+	return &Promotions{repo: repo, logger: logger, now: time.Now}
+}
+
+func (s *Promotions) CreatePromotion(ctx context.Context, p promo.Promotion) (promo.Promotion, error) {
+	p.Code = promo.NormalizeCode(p.Code)
+	if p.StartsAt.IsZero() {
+		p.StartsAt = s.now()
+	}
+	if err := p.Validate(); err != nil {
+		return promo.Promotion{}, err
+	}
+	created, ok, err := s.repo.CreatePromotion(ctx, p)
+	if err != nil {
+		return promo.Promotion{}, err
+	}
+	if !ok {
+		return promo.Promotion{}, ErrPromotionExists
+	}
+	s.logger.Info("promotion created", slog.String("code", created.Code), slog.String("kind", string(created.Kind)))
+	return created, nil
+}
+
+func (s *Promotions) GetPromotion(ctx context.Context, code string) (promo.Promotion, error) {
+	p, ok, err := s.repo.GetPromotion(ctx, promo.NormalizeCode(code))
+	if err != nil {
+		return promo.Promotion{}, err
+	}
+	if !ok {
+		return promo.Promotion{}, ErrPromotionNotFound
+	}
+	return p, nil
+}
+
+func (s *Promotions) ListPromotions(ctx context.Context) ([]promo.Promotion, error) {
+	return s.repo.ListPromotions(ctx)
+}
+
+// Quote returns the redemption of code by riderID for a trip from pickup at
+// price. It checks the redemption limits as they stand, so a rider who is
+// out of luck is told before a hold is placed; the repository checks them
+// again when the booking is stored.
+func (s *Promotions) Quote(ctx context.Context, code, riderID string, pickup models.Location, price money.Money) (repository.PromoRedemption, error) {
+	p, ok, err := s.repo.GetPromotion(ctx, promo.NormalizeCode(code))
+	if err != nil {
+		return repository.PromoRedemption{}, err
+	}
+	if !ok {
+		return repository.PromoRedemption{}, promo.ErrUnknownCode
+	}
+	discount, err := p.Discount(promo.Trip{Pickup: pickup, Price: price, At: s.now()})
+	if err != nil {
+		return repository.PromoRedemption{}, err
+	}
+	if p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions {
+		return repository.PromoRedemption{}, promo.ErrExhausted
+	}
+	if p.MaxPerRider > 0 {
+		n, err := s.repo.CountRiderRedemptions(ctx, p.Code, riderID)
+		if err != nil {
+			return repository.PromoRedemption{}, err
+		}
+		if n >= p.MaxPerRider {
+			return repository.PromoRedemption{}, promo.ErrAlreadyRedeemed
+		}
+	}
+	return repository.PromoRedemption{Code: p.Code, Discount: discount}, nil
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/events"
+	"booking_svc/internal/ledger"
+	"booking_svc/internal/models"
+	"booking_svc/internal/promo"
+	"booking_svc/internal/service"
+)
+
+func TestPromoDiscountIsHeldChargedAndExpensed(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	msgs := f.bus.Subscribe("booking.created", "test")
+	defer msgs.Close()
+	completions := f.bus.Subscribe("booking.completed", "test")
+	defer completions.Close()
+	off := inr(5000)
+	if _, err := f.promos.CreatePromotion(ctx, promo.Promotion{Code: "flat50", Kind: promo.Flat, AmountOff: &off, MaxPerRider: 1}); err != nil {
+		t.Fatal(err)
+	}
+	in := service.CreateBookingInput{
+		RiderID:   "r-1",
+		PickupLoc: models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:   models.Location{Lat: 12.95, Lng: 77.64},
+		Price:     inr(20000),
+		PromoCode: "Flat50",
+	}
+	b, err := f.svc.CreateBooking(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Price != inr(20000) || b.PromoCode == nil || *b.PromoCode != "FLAT50" || b.Discount == nil || *b.Discount != off {
+		t.Fatalf("created: %+v", b)
+	}
+	if p := f.payment(t, b.BookingID); p.Amount != inr(15000) {
+		t.Fatalf("hold must be the discounted price: %+v", p)
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	m, err := msgs.Fetch(fetchCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt events.BookingCreated
+	if err := json.Unmarshal(m.Value, &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt.Price != inr(20000) || evt.PromoCode != "FLAT50" || evt.Discount == nil || *evt.Discount != off {
+		t.Fatalf("booking.created: %+v", evt)
+	}
+
+	// One redemption per rider: the second attempt is refused before any hold.
+	if _, err := f.svc.CreateBooking(ctx, in); !errors.Is(err, promo.ErrAlreadyRedeemed) {
+		t.Fatalf("second redemption: %v", err)
+	}
+	if held := f.gateway.Held()["INR"]; held != 15000 {
+		t.Fatalf("held %d, want only the first hold", held)
+	}
+
+	if _, err := f.bookings.MarkAccepted(ctx, b.BookingID, "d-1"); err != nil {
+		t.Fatal(err)
+	}
+	tooLow := inr(5000)
+	if _, err := f.svc.CompleteBooking(ctx, b.BookingID, &tooLow); err == nil {
+		t.Fatal("a fare the discount would cover entirely must be rejected")
+	}
+	fare := inr(18000)
+	done, err := f.svc.CompleteBooking(ctx, b.BookingID, &fare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *done.Fare != inr(13000) {
+		t.Fatalf("rider must be charged the fare less the discount: %+v", done.Fare)
+	}
+	m, err = completions.Fetch(fetchCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var completed events.BookingCompleted
+	if err := json.Unmarshal(m.Value, &completed); err != nil {
+		t.Fatal(err)
+	}
+	// driver_svc pays out what the ledger credits the driver below.
+	if completed.DriverID != "d-1" || completed.Fare != fare || completed.DriverEarnings != inr(14400) {
+		t.Fatalf("booking.completed: %+v", completed)
+	}
+	for account, want := range map[string]int64{"rider:r-1": 13000, ledger.PlatformPromotions: 5000, "driver:d-1": 14400, ledger.PlatformCommission: 3600} {
+		acct, _, err := f.ledger.GetAccount(ctx, account)
+		if err != nil {
+			t.Fatal(err)
+		}
+		totals, err := f.ledger.SumPostings(ctx, account, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := totals.Balance(acct.Type); got != want {
+			t.Fatalf("%s balance = %d, want %d", account, got, want)
+		}
+	}
+}
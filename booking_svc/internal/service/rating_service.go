@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"booking_svc/internal/events"
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/models"
+	"booking_svc/internal/mq"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/repository"
+)
+
+var (
+	// ErrBookingNotCompleted means the trip has not finished, so there is
+	// nothing to rate yet.
+	ErrBookingNotCompleted = errors.New("booking is not a completed trip")
+	// ErrRatingExists means this side already rated the trip differently.
+	ErrRatingExists = errors.New("booking already rated")
+	// ErrRatingNotDispatched means the rating is stored but rating.submitted
+	// was not published; submitting the same rating again retries it.
+	ErrRatingNotDispatched = errors.New("rating stored but not published")
+)
+
+const maxRatingComment = 500
+
+type RatingInput struct {
+	Stars   int
+	Comment string
+}
+
+// Validate reports every invalid field, named as in the REST API.
+func (in RatingInput) Validate() error {
+	var errs problem.ValidationError
+
+	if in.Stars < 1 || in.Stars > 5 {
+		errs = append(errs, problem.FieldError{Field: "stars", Message: "must be between 1 and 5"})
+	}
+	if utf8.RuneCountInString(in.Comment) > maxRatingComment {
+		errs = append(errs, problem.FieldError{Field: "comment", Message: fmt.Sprintf("must be at most %d characters", maxRatingComment)})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type RatingService interface {
+	// RateBooking records raterID's rating of the other party to a completed
+	// trip: the rider rates the driver, the driver rates the rider. Each side
+	// rates once; repeating the same rating returns it and publishes
+	// rating.submitted again. A booking raterID was not party to is reported
+	// as not found.
+	RateBooking(ctx context.Context, bookingID string, rater models.RatingSide, raterID string, in RatingInput) (models.Rating, error)
+}
+
+type ratingService struct {
+	repo     repository.RatingRepository
+	bookings BookingService
+	producer *mq.Producer
+	logger   *slog.Logger
+}
+
+func NewRatingService(repo repository.RatingRepository, bookings BookingService, producer *mq.Producer, logger *slog.Logger) RatingService {
+	return &ratingService{repo: repo, bookings: bookings, producer: producer, logger: logger}
+}
+
+func (s *ratingService) RateBooking(ctx context.Context, bookingID string, rater models.RatingSide, raterID string, in RatingInput) (models.Rating, error) {
+	if err := in.Validate(); err != nil {
+		return models.Rating{}, err
+	}
+	b, err := s.bookings.GetBooking(ctx, bookingID)
+	if err != nil {
+		return models.Rating{}, err
+	}
+	var rateeID string
+	switch {
+	case rater == models.RatingByRider && raterID != "" && b.RiderID == raterID:
+		if b.DriverID != nil {
+			rateeID = *b.DriverID
+		}
+	case rater == models.RatingByDriver && raterID != "" && b.DriverID != nil && *b.DriverID == raterID:
+		rateeID = b.RiderID
+	default:
+		return models.Rating{}, ErrBookingNotFound
+	}
+	if b.RideStatus != models.RideStatusCompleted || rateeID == "" {
+		return models.Rating{}, ErrBookingNotCompleted
+	}
+
+	r, created, err := s.repo.CreateRating(ctx, models.Rating{
+		BookingID: bookingID,
+		Rater:     rater,
+		RaterID:   raterID,
+		RateeID:   rateeID,
+		Stars:     in.Stars,
+		Comment:   in.Comment,
+	})
+	if err != nil {
+		return models.Rating{}, err
+	}
+	if created {
+		metrics.RatingsSubmitted.WithLabelValues(string(rater)).Inc()
+		s.logger.Info("rating submitted",
+			slog.String("booking_id", bookingID),
+			slog.String("rater", string(rater)),
+			slog.Int("stars", r.Stars),
+		)
+	} else if r.Stars != in.Stars || r.Comment != in.Comment {
+		return models.Rating{}, ErrRatingExists
+	}
+
+	// Consumers keep one rating per booking and side, so publishing again on
+	// a retry is harmless.
+	evt := events.RatingSubmitted{
+		BookingID:   r.BookingID,
+		Rater:       string(r.Rater),
+		RaterID:     r.RaterID,
+		RateeID:     r.RateeID,
+		Stars:       r.Stars,
+		SubmittedAt: r.CreatedAt,
+	}
+	if err := s.producer.ProduceRatingSubmitted(ctx, evt); err != nil {
+		return models.Rating{}, fmt.Errorf("%w: %w", ErrRatingNotDispatched, err)
+	}
+	return r, nil
+}
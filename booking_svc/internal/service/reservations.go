@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+)
+
+// releaseLease is how long a scheduler owns the reservations it claimed. A
+// replica that dies mid-batch leaves them to another once it runs out.
+const releaseLease = time.Minute
+
+// Reservations bounds when scheduled rides may be picked up.
+type Reservations struct {
+	// Lead is how long before pickup a reservation is released to drivers,
+	// and so the earliest pickup that can be reserved.
+	Lead time.Duration
+	// MaxAhead is the latest pickup that can be reserved, from now.
+	MaxAhead time.Duration
+}
+
+func (r Reservations) Validate() error {
+	if r.Lead <= 0 {
+		return errors.New("reservation lead time must be positive")
+	}
+	if r.MaxAhead <= r.Lead {
+		return errors.New("reservation horizon must be longer than its lead time")
+	}
+	return nil
+}
+
+// Check reports whether a ride can be reserved for pickup at at.
+func (r Reservations) Check(at, now time.Time) error {
+	if at.Before(now.Add(r.Lead)) {
+		return problem.ValidationError{{Field: "scheduled_for", Message: fmt.Sprintf("must be at least %s from now", r.Lead)}}
+	}
+	if at.After(now.Add(r.MaxAhead)) {
+		return problem.ValidationError{{Field: "scheduled_for", Message: fmt.Sprintf("must be at most %s from now", r.MaxAhead)}}
+	}
+	return nil
+}
+
+func (s *bookingService) ListScheduledBookings(ctx context.Context, riderID string) ([]models.Booking, error) {
+	return s.repo.ListScheduled(ctx, riderID)
+}
+
+func (s *bookingService) ReleaseScheduled(ctx context.Context, now time.Time, limit int) (int, error) {
+	due, err := s.repo.ClaimDueScheduled(ctx, now.Add(s.reserve.Lead), now, releaseLease, limit)
+	if err != nil {
+		return 0, err
+	}
+	released := 0
+	for _, b := range due {
+		if err := s.release(ctx, b); err != nil {
+			// The lease runs out and a later pass retries it.
+			s.logger.Warn("release scheduled booking failed",
+				slog.String("booking_id", b.BookingID),
+				slog.String("err", err.Error()),
+			)
+			continue
+		}
+		released++
+	}
+	return released, nil
+}
+
+// release holds a reservation's fare and dispatches it as Requested. Every
+// step is idempotent, so a retry after a crash picks up where it stopped.
+func (s *bookingService) release(ctx context.Context, b models.Booking) error {
+	charge := b.Price
+	if b.Discount != nil {
+		var err error
+		if charge, err = b.Price.Sub(*b.Discount); err != nil {
+			return err
+		}
+	}
+	_, err := s.payments.Hold(ctx, b.BookingID, b.RiderID, charge)
+	if errors.Is(err, ErrPaymentDeclined) {
+		// Like a booking made now, a declined card never reaches drivers.
+		c := models.Cancellation{Reason: models.CancellationFree, Fee: money.Money{Currency: b.Price.Currency}, CancelledAt: s.now()}
+		cancelled := b
+		cancelled.RideStatus = models.RideStatusCancelled
+		cancelled.Cancellation = &c
+		// Announced before the status change, which ends retries.
+		if err := s.notify(ctx, models.WebhookEventBookingCancelled, b.BookingID, cancelled); err != nil {
+			return err
+		}
+		ok, cerr := s.repo.MarkCancelled(ctx, b.BookingID, models.RideStatusScheduled, c)
+		if cerr != nil {
+			return cerr
+		}
+		if ok {
+			metrics.BookingsCancelled.Inc()
+			s.changes.Broadcast()
+			s.logger.Info("scheduled booking cancelled: payment declined",
+				slog.String("booking_id", b.BookingID),
+				slog.String("err", err.Error()),
+			)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.repo.MarkReleased(ctx, b.BookingID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		cur, err := s.GetBooking(ctx, b.BookingID)
+		if err != nil || cur.RideStatus != models.RideStatusCancelled {
+			// Released by a scheduler whose lease this one outlived.
+			return err
+		}
+		// Cancelled since it was claimed, possibly before the hold existed
+		// for the cancellation to release.
+		_, err = s.payments.Release(ctx, b.BookingID)
+		return err
+	}
+	b.RideStatus = models.RideStatusRequested
+	metrics.BookingsReleased.Inc()
+	s.changes.Broadcast()
+	if err := s.producer.ProduceBookingCreated(ctx, createdEvent(b)); err != nil {
+		// The booking is Requested; reconciliation republishes it.
+		return fmt.Errorf("%w: %w", ErrBookingNotDispatched, err)
+	}
+	s.logger.Info("scheduled booking released", slog.String("booking_id", b.BookingID))
+	return nil
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/events"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/problem"
+	"booking_svc/internal/service"
+)
+
+func (f *fixture) schedule(t *testing.T, price money.Money, at time.Time) models.Booking {
+	t.Helper()
+	b, err := f.svc.CreateBooking(context.Background(), service.CreateBookingInput{
+		RiderID:      "r-1",
+		PickupLoc:    models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:      models.Location{Lat: 12.95, Lng: 77.64},
+		Price:        price,
+		ScheduledFor: &at,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestScheduledBookingIsHeldAndDispatchedOnRelease(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	msgs := f.bus.Subscribe("booking.created", "test")
+	defer msgs.Close()
+	pickup := time.Now().Add(2 * time.Hour)
+	b := f.schedule(t, inr(22050), pickup)
+	if b.RideStatus != models.RideStatusScheduled || b.ScheduledFor == nil || !b.ScheduledFor.Equal(pickup) {
+		t.Fatalf("created: %+v", b)
+	}
+	if _, err := f.svc.GetPayment(ctx, b.BookingID); !errors.Is(err, service.ErrPaymentNotFound) {
+		t.Fatalf("a reservation must not be held yet: %v", err)
+	}
+	if got, err := f.svc.ListScheduledBookings(ctx, "r-1"); err != nil || len(got) != 1 || got[0].BookingID != b.BookingID {
+		t.Fatalf("scheduled: %+v, %v", got, err)
+	}
+
+	// Not due until the lead time before pickup.
+	if n, err := f.svc.ReleaseScheduled(ctx, pickup.Add(-reserve.Lead-time.Minute), 10); err != nil || n != 0 {
+		t.Fatalf("early release: %d, %v", n, err)
+	}
+	now := pickup.Add(-reserve.Lead)
+	if n, err := f.svc.ReleaseScheduled(ctx, now, 10); err != nil || n != 1 {
+		t.Fatalf("release: %d, %v", n, err)
+	}
+	// The first release holds the lease, so another scheduler finds nothing.
+	if n, err := f.svc.ReleaseScheduled(ctx, now, 10); err != nil || n != 0 {
+		t.Fatalf("second release: %d, %v", n, err)
+	}
+	got, err := f.svc.GetBooking(ctx, b.BookingID)
+	if err != nil || got.RideStatus != models.RideStatusRequested {
+		t.Fatalf("released: %+v, %v", got, err)
+	}
+	if p := f.payment(t, b.BookingID); p.Status != models.PaymentStatusAuthorized || p.Amount != inr(22050) {
+		t.Fatalf("hold: %+v", p)
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	m, err := msgs.Fetch(fetchCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt events.BookingCreated
+	if err := json.Unmarshal(m.Value, &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt.BookingID != b.BookingID || evt.RideStatus != string(models.RideStatusRequested) || evt.ScheduledFor == nil || !evt.ScheduledFor.Equal(pickup) {
+		t.Fatalf("booking.created: %+v", evt)
+	}
+	if got, _ := f.svc.ListScheduledBookings(ctx, "r-1"); len(got) != 0 {
+		t.Fatalf("released booking still scheduled: %+v", got)
+	}
+}
+
+func TestReservationWindow(t *testing.T) {
+	f := newFixture(t, 0, cancellation.Policy{})
+	for name, at := range map[string]time.Time{
+		"inside the lead time": time.Now().Add(reserve.Lead / 2),
+		"beyond the max ahead": time.Now().Add(reserve.MaxAhead + time.Hour),
+	} {
+		_, err := f.svc.CreateBooking(context.Background(), service.CreateBookingInput{
+			RiderID:      "r-1",
+			PickupLoc:    models.Location{Lat: 12.9, Lng: 77.6},
+			Dropoff:      models.Location{Lat: 12.95, Lng: 77.64},
+			Price:        inr(22050),
+			ScheduledFor: &at,
+		})
+		var ve problem.ValidationError
+		if !errors.As(err, &ve) || ve[0].Field != "scheduled_for" {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestCancelledReservationIsNeverReleased(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	pickup := time.Now().Add(time.Hour)
+	b := f.schedule(t, inr(22050), pickup)
+	got, err := f.svc.CancelBooking(ctx, b.BookingID)
+	if err != nil || got.RideStatus != models.RideStatusCancelled || got.Cancellation.Reason != models.CancellationFree {
+		t.Fatalf("cancel: %+v, %v", got, err)
+	}
+	if n, err := f.svc.ReleaseScheduled(ctx, pickup, 10); err != nil || n != 0 {
+		t.Fatalf("release: %d, %v", n, err)
+	}
+	if f.gateway.Held()["INR"] != 0 {
+		t.Fatalf("held %v", f.gateway.Held())
+	}
+}
+
+func TestDeclinedReleaseCancelsTheReservation(t *testing.T) {
+	f, ctx := newFixture(t, 10000, cancellation.Policy{}), context.Background()
+	pickup := time.Now().Add(time.Hour)
+	b := f.schedule(t, inr(22050), pickup)
+	if n, err := f.svc.ReleaseScheduled(ctx, pickup, 10); err != nil || n != 1 {
+		t.Fatalf("release: %d, %v", n, err)
+	}
+	got, err := f.svc.GetBooking(ctx, b.BookingID)
+	if err != nil || got.RideStatus != models.RideStatusCancelled || got.Cancellation == nil || got.Cancellation.Fee != inr(0) {
+		t.Fatalf("after a declined release: %+v, %v", got, err)
+	}
+	if p := f.payment(t, b.BookingID); p.Status != models.PaymentStatusFailed {
+		t.Fatalf("payment: %+v", p)
+	}
+}
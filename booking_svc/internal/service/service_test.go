@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"booking_svc/internal/bus"
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/config"
+	"booking_svc/internal/fare"
+	"booking_svc/internal/models"
+	"booking_svc/internal/money"
+	"booking_svc/internal/mq"
+	"booking_svc/internal/payment"
+	"booking_svc/internal/repository/memory"
+	"booking_svc/internal/service"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func inr(amount int64) money.Money { return money.Money{Amount: amount, Currency: "INR"} }
+
+// tariff prices routes with stops.
+var tariff = fare.Tariff{Currency: "INR", Base: 5000, PerKm: 1200, PerStop: 2500}
+
+// reserve releases reservations a quarter hour before pickup.
+var reserve = service.Reservations{Lead: 15 * time.Minute, MaxAhead: 24 * time.Hour}
+
+// fixture is the booking flow over in-memory stores, the in-memory bus and
+// the fake payment gateway.
+type fixture struct {
+	gateway  *payment.Fake
+	payments *memory.PaymentRepo
+	bookings *memory.BookingRepo
+	promos   *service.Promotions
+	ledger   *memory.LedgerRepo
+	webhooks service.WebhookService
+	notifier *flakyNotifier
+	bus      *bus.Memory
+	svc      service.BookingService
+}
+
+// newFixture declines holds above declineAbove (0 accepts any) and cancels
+// under policy.
+func newFixture(t *testing.T, declineAbove int64, policy cancellation.Policy) *fixture {
+	t.Helper()
+	f := &fixture{
+		gateway:  payment.NewFake(declineAbove),
+		payments: memory.NewPaymentRepo(),
+		bookings: memory.NewBookingRepo(),
+		ledger:   memory.NewLedgerRepo(),
+		bus:      bus.NewMemory(),
+	}
+	f.promos = service.NewPromotions(memory.NewPromotionRepo(f.bookings), discard)
+	f.webhooks = service.NewWebhookService(memory.NewWebhookRepo())
+	f.notifier = &flakyNotifier{next: f.webhooks}
+	t.Cleanup(func() { _ = f.bus.Close() })
+	cfg := config.Config{TopicBookingCreated: "booking.created", TopicBookingCancelled: "booking.cancelled", TopicBookingCompleted: "booking.completed"}
+	f.svc = service.NewBookingService(f.bookings, mq.NewProducer(cfg, f.bus, discard), f.notifier,
+		service.NewBroadcaster(), service.NewPayments(f.payments, f.gateway, time.Hour, discard), f.promos, service.NewFares(tariff),
+		service.NewLedger(f.ledger, 2000, "INR", discard), policy, reserve, "INR", discard)
+	return f
+}
+
+// flakyNotifier fails the next failures notifications and passes the rest
+// to next.
+type flakyNotifier struct {
+	next     service.EventNotifier
+	failures int
+}
+
+func (n *flakyNotifier) Notify(ctx context.Context, eventType, key string, data any) error {
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("webhook store unavailable")
+	}
+	return n.next.Notify(ctx, eventType, key, data)
+}
+
+func (f *fixture) create(t *testing.T, price money.Money) models.Booking {
+	t.Helper()
+	b, err := f.svc.CreateBooking(context.Background(), service.CreateBookingInput{
+		RiderID:   "r-1",
+		PickupLoc: models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:   models.Location{Lat: 12.95, Lng: 77.64},
+		Price:     price,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func (f *fixture) payment(t *testing.T, bookingID string) models.Payment {
+	t.Helper()
+	p, err := f.svc.GetPayment(context.Background(), bookingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
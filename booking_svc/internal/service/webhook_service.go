@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+type CreateSubscriptionInput struct {
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, in CreateSubscriptionInput) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error)
+	// Notify fans an event out to every matching subscription's delivery queue.
+	Notify(ctx context.Context, eventType, key string, data any) error
+}
+
+// EventNotifier is the slice of WebhookService the booking flow depends on.
+// key names the occurrence of the event, such as the booking it is about.
+// Notifying the same type and key again enqueues nothing new, so a failed
+// notification is retried by repeating the step that made it.
+type EventNotifier interface {
+	Notify(ctx context.Context, eventType, key string, data any) error
+}
+
+// eventSpace is the UUID namespace webhook event ids are derived in.
+var eventSpace = uuid.MustParse("5b0e4a8c-3f2d-4d51-9a57-0c2f1f6de9b3")
+
+// WebhookEnvelope is the JSON body POSTed to subscribers.
+type WebhookEnvelope struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, in CreateSubscriptionInput) (models.WebhookSubscription, error) {
+	return s.repo.CreateSubscription(ctx, repository.CreateSubscriptionParams{
+		ID:         uuid.NewString(),
+		URL:        in.URL,
+		EventTypes: in.EventTypes,
+		Secret:     in.Secret,
+	})
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx)
+}
+
+func (s *webhookService) GetSubscription(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	sub, ok, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if !ok {
+		return models.WebhookSubscription{}, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id string) error {
+	deleted, err := s.repo.DeleteSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, subscriptionID, limit)
+}
+
+func (s *webhookService) Notify(ctx context.Context, eventType, key string, data any) error {
+	subs, err := s.repo.ListSubscriptionsForEvent(ctx, eventType)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	// Ids are derived from the event, so a repeated notification finds its
+	// deliveries already queued.
+	eventID := uuid.NewSHA1(eventSpace, []byte(eventType+"/"+key))
+	env := WebhookEnvelope{
+		ID:        eventID.String(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	ps := make([]repository.EnqueueDeliveryParams, 0, len(subs))
+	for _, sub := range subs {
+		ps = append(ps, repository.EnqueueDeliveryParams{
+			ID:             uuid.NewSHA1(eventID, []byte(sub.ID)).String(),
+			SubscriptionID: sub.ID,
+			EventID:        env.ID,
+			EventType:      eventType,
+			Payload:        payload,
+		})
+	}
+	return s.repo.EnqueueDeliveries(ctx, ps)
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"booking_svc/internal/cancellation"
+	"booking_svc/internal/models"
+	"booking_svc/internal/service"
+)
+
+func TestNotifyQueuesAnEventOnce(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	sub, err := f.webhooks.CreateSubscription(ctx, service.CreateSubscriptionInput{
+		URL: "https://partner.example/hooks", EventTypes: []string{models.WebhookEventBookingCreated}, Secret: "0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := f.webhooks.Notify(ctx, models.WebhookEventBookingCreated, "b-1", map[string]string{"booking_id": "b-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.webhooks.Notify(ctx, models.WebhookEventBookingCreated, "b-2", map[string]string{"booking_id": "b-2"}); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := f.webhooks.ListDeliveries(ctx, sub.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 2 || ds[0].EventID == ds[1].EventID {
+		t.Fatalf("want one delivery per event: %+v", ds)
+	}
+}
+
+func TestFailedWebhookNotifyIsRetriedByRepeatingTheCall(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	sub, err := f.webhooks.CreateSubscription(ctx, service.CreateSubscriptionInput{
+		URL: "https://partner.example/hooks", EventTypes: []string{models.WebhookEventBookingCancelled}, Secret: "0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := f.create(t, inr(22050))
+
+	f.notifier.failures = 1
+	if _, err := f.svc.CancelBooking(ctx, b.BookingID); !errors.Is(err, service.ErrBookingNotDispatched) {
+		t.Fatalf("cancel with the webhook store down: %v", err)
+	}
+	// The booking is cancelled; cancelling again queues the event it missed.
+	for i := 0; i < 2; i++ {
+		if got, err := f.svc.CancelBooking(ctx, b.BookingID); err != nil || got.RideStatus != models.RideStatusCancelled {
+			t.Fatalf("cancel %d: %+v %v", i, got, err)
+		}
+	}
+	ds, err := f.webhooks.ListDeliveries(ctx, sub.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 || ds[0].EventType != models.WebhookEventBookingCancelled {
+		t.Fatalf("want the cancellation queued once: %+v", ds)
+	}
+}
+
+func TestCreateBookingSurvivesAFailedWebhookNotify(t *testing.T) {
+	f, ctx := newFixture(t, 0, cancellation.Policy{}), context.Background()
+	f.notifier.failures = 1
+	b := f.create(t, inr(22050))
+	if b.RideStatus != models.RideStatusRequested {
+		t.Fatalf("booking not dispatched: %+v", b)
+	}
+	all, err := f.svc.ListBookings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("want the one booking stored, got %d", len(all))
+	}
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+
+	"booking_svc/internal/bus"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderCarrier adapts bus message headers to propagation.TextMapCarrier.
+type HeaderCarrier struct {
+	Headers *[]bus.Header
+}
+
+func (c HeaderCarrier) Get(key string) string {
+	for _, h := range *c.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	for i, h := range *c.Headers {
+		if h.Key == key {
+			(*c.Headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.Headers = append(*c.Headers, bus.Header{Key: key, Value: []byte(value)})
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.Headers))
+	for _, h := range *c.Headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// StartProduce starts a producer span for topic and injects its context into msg headers.
+func StartProduce(ctx context.Context, topic string, msg *bus.Message) (context.Context, trace.Span) {
+	ctx, span := Start(ctx, topic+" publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("messaging.kafka.message.key", string(msg.Key)),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, HeaderCarrier{Headers: &msg.Headers})
+	return ctx, span
+}
+
+// StartConsume extracts the producer's trace context from msg and starts a consumer span under it.
+func StartConsume(ctx context.Context, msg bus.Message) (context.Context, trace.Span) {
+	headers := msg.Headers
+	ctx = otel.GetTextMapPropagator().Extract(ctx, HeaderCarrier{Headers: &headers})
+	return Start(ctx, msg.Topic+" process", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.partition", msg.Partition),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		))
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"booking_svc/internal/metrics"
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+}
+
+// Dispatcher drains the persistent delivery queue, POSTing signed payloads to
+// subscribers and rescheduling failures with exponential backoff.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    DispatcherConfig
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewDispatcher(repo repository.WebhookRepository, cfg DispatcherConfig, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("webhook dispatch failed", slog.String("err", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue claims one batch of due deliveries and attempts each once.
+// It returns the number of deliveries attempted.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	// Lease long enough to cover the HTTP timeout so another replica won't pick it up mid-flight.
+	lease := d.cfg.Timeout + 30*time.Second
+	due, err := d.repo.ClaimDueDeliveries(ctx, d.now(), lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, del := range due {
+		sub, ok, err := d.repo.GetSubscription(ctx, del.SubscriptionID)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue // subscription deleted; cascade removes the delivery
+		}
+		res := d.attempt(ctx, sub, del)
+		if err := d.repo.RecordAttempt(ctx, del.ID, res); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, sub models.WebhookSubscription, del models.WebhookDelivery) repository.DeliveryAttemptResult {
+	status, err := d.post(ctx, sub, del)
+	if err == nil {
+		metrics.WebhookDeliveryAttempts.WithLabelValues("succeeded").Inc()
+		return repository.DeliveryAttemptResult{Succeeded: true, StatusCode: status}
+	}
+
+	msg := err.Error()
+	res := repository.DeliveryAttemptResult{StatusCode: status, Error: &msg}
+	attempts := del.Attempts + 1
+	if attempts < d.cfg.MaxAttempts {
+		next := d.now().Add(Backoff(attempts, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+		res.NextAttemptAt = &next
+		metrics.WebhookDeliveryAttempts.WithLabelValues("retry").Inc()
+	} else {
+		metrics.WebhookDeliveryAttempts.WithLabelValues("failed").Inc()
+	}
+	d.logger.Warn("webhook delivery attempt failed",
+		slog.String("delivery_id", del.ID),
+		slog.String("subscription_id", sub.ID),
+		slog.Int("attempt", attempts),
+		slog.Bool("will_retry", res.NextAttemptAt != nil),
+		slog.String("err", msg),
+	)
+	return res
+}
+
+func (d *Dispatcher) post(ctx context.Context, sub models.WebhookSubscription, del models.WebhookDelivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return nil, err
+	}
+	ts := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, del.EventType)
+	req.Header.Set(HeaderDelivery, del.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, del.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	code := resp.StatusCode
+	if code < 200 || code > 299 {
+		return &code, fmt.Errorf("unexpected status %d", code)
+	}
+	return &code, nil
+}
+
+// Backoff returns base * 2^(attempt-1), capped at max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return d
+}
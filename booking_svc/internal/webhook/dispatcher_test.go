@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"booking_svc/internal/models"
+	"booking_svc/internal/repository"
+)
+
+// fakeRepo keeps just enough state to drive the dispatcher.
+type fakeRepo struct {
+	mu         sync.Mutex
+	subs       map[string]models.WebhookSubscription
+	deliveries map[string]*models.WebhookDelivery
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{subs: map[string]models.WebhookSubscription{}, deliveries: map[string]*models.WebhookDelivery{}}
+}
+
+func (f *fakeRepo) CreateSubscription(_ context.Context, p repository.CreateSubscriptionParams) (models.WebhookSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := models.WebhookSubscription{ID: p.ID, URL: p.URL, EventTypes: p.EventTypes, Secret: p.Secret}
+	f.subs[p.ID] = s
+	return s, nil
+}
+func (f *fakeRepo) ListSubscriptions(context.Context) ([]models.WebhookSubscription, error) {
+	return nil, nil
+}
+func (f *fakeRepo) GetSubscription(_ context.Context, id string) (models.WebhookSubscription, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.subs[id]
+	return s, ok, nil
+}
+func (f *fakeRepo) DeleteSubscription(context.Context, string) (bool, error) { return false, nil }
+func (f *fakeRepo) ListSubscriptionsForEvent(context.Context, string) ([]models.WebhookSubscription, error) {
+	return nil, nil
+}
+func (f *fakeRepo) EnqueueDeliveries(_ context.Context, ps []repository.EnqueueDeliveryParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range ps {
+		f.deliveries[p.ID] = &models.WebhookDelivery{
+			ID: p.ID, SubscriptionID: p.SubscriptionID, EventID: p.EventID, EventType: p.EventType,
+			Payload: p.Payload, Status: models.DeliveryStatusPending,
+		}
+	}
+	return nil
+}
+func (f *fakeRepo) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []models.WebhookDelivery
+	for _, d := range f.deliveries {
+		if d.Status == models.DeliveryStatusPending && !d.NextAttemptAt.After(now) && len(out) < limit {
+			d.NextAttemptAt = now.Add(lease)
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+func (f *fakeRepo) RecordAttempt(_ context.Context, id string, res repository.DeliveryAttemptResult) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.deliveries[id]
+	d.Attempts++
+	d.LastStatusCode = res.StatusCode
+	d.LastError = res.Error
+	switch {
+	case res.Succeeded:
+		d.Status = models.DeliveryStatusSucceeded
+	case res.NextAttemptAt == nil:
+		d.Status = models.DeliveryStatusFailed
+	default:
+		d.NextAttemptAt = *res.NextAttemptAt
+	}
+	return nil
+}
+func (f *fakeRepo) ListDeliveries(context.Context, string, int) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) delivery(id string) models.WebhookDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.deliveries[id]
+}
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func setup(t *testing.T, handler http.HandlerFunc, maxAttempts int) (*Dispatcher, *fakeRepo, *clock) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	repo := newFakeRepo()
+	_, _ = repo.CreateSubscription(context.Background(), repository.CreateSubscriptionParams{
+		ID: "sub-1", URL: srv.URL, EventTypes: []string{models.WebhookEventBookingCreated}, Secret: "0123456789abcdef",
+	})
+	_ = repo.EnqueueDeliveries(context.Background(), []repository.EnqueueDeliveryParams{{
+		ID: "del-1", SubscriptionID: "sub-1", EventID: "evt-1", EventType: models.WebhookEventBookingCreated,
+		Payload: json.RawMessage(`{"id":"evt-1","type":"booking.created","data":{"booking_id":"b-1"}}`),
+	}})
+
+	c := &clock{t: time.Unix(1_700_000_000, 0)}
+	d := NewDispatcher(repo, DispatcherConfig{
+		PollInterval: time.Second, BatchSize: 10, MaxAttempts: maxAttempts,
+		BaseBackoff: 2 * time.Second, MaxBackoff: time.Minute, Timeout: time.Second,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.now = c.now
+	return d, repo, c
+}
+
+func TestDispatcher_SignsPayload(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	d, repo, _ := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeader = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}, 3)
+
+	n, err := d.DispatchDue(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("dispatch: n=%d err=%v", n, err)
+	}
+	if got := repo.delivery("del-1"); got.Status != models.DeliveryStatusSucceeded || got.Attempts != 1 {
+		t.Fatalf("unexpected delivery state: %+v", got)
+	}
+	ts, _ := strconv.ParseInt(gotHeader.Get(HeaderTimestamp), 10, 64)
+	if !Verify("0123456789abcdef", ts, gotBody, gotHeader.Get(HeaderSignature)) {
+		t.Fatalf("signature did not verify: %s", gotHeader.Get(HeaderSignature))
+	}
+	if gotHeader.Get(HeaderEvent) != models.WebhookEventBookingCreated || gotHeader.Get(HeaderDelivery) != "del-1" {
+		t.Fatalf("unexpected headers: %v", gotHeader)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	d, repo, c := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}, 5)
+	ctx := context.Background()
+
+	if _, err := d.DispatchDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := repo.delivery("del-1")
+	if got.Status != models.DeliveryStatusPending || got.Attempts != 1 || *got.LastStatusCode != 503 {
+		t.Fatalf("after 1st attempt: %+v", got)
+	}
+	if want := c.t.Add(2 * time.Second); !got.NextAttemptAt.Equal(want) {
+		t.Fatalf("next attempt: want %v got %v", want, got.NextAttemptAt)
+	}
+
+	// not due yet -> nothing claimed
+	if n, _ := d.DispatchDue(ctx); n != 0 {
+		t.Fatalf("want 0 due, got %d", n)
+	}
+
+	c.advance(2 * time.Second)
+	_, _ = d.DispatchDue(ctx)
+	if got := repo.delivery("del-1"); !got.NextAttemptAt.Equal(c.t.Add(4 * time.Second)) {
+		t.Fatalf("want doubled backoff, got next=%v", got.NextAttemptAt)
+	}
+
+	c.advance(4 * time.Second)
+	_, _ = d.DispatchDue(ctx)
+	if got := repo.delivery("del-1"); got.Status != models.DeliveryStatusSucceeded || got.Attempts != 3 {
+		t.Fatalf("want success on 3rd attempt, got %+v", got)
+	}
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	d, repo, c := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}, 2)
+	ctx := context.Background()
+
+	_, _ = d.DispatchDue(ctx)
+	c.advance(time.Minute)
+	_, _ = d.DispatchDue(ctx)
+
+	got := repo.delivery("del-1")
+	if got.Status != models.DeliveryStatusFailed || got.Attempts != 2 || got.LastError == nil {
+		t.Fatalf("want permanently failed after 2 attempts, got %+v", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second}, {2, 2 * time.Second}, {3, 4 * time.Second}, {10, 30 * time.Second},
+	}
+	for _, c := range cases {
+		if got := Backoff(c.attempt, time.Second, 30*time.Second); got != c.want {
+			t.Fatalf("attempt %d: want %v got %v", c.attempt, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Package app wires driver_svc together: the jobs and payout services, the
+// booking.created, booking.cancelled, rating.submitted and booking.completed
+// consumers, the payout runner, which publishes payout.paid, and the HTTP and
+// gRPC servers. The binary runs it on Postgres and Kafka; the end-to-end tests
+// run it in-process on the in-memory repositories and bus.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/grpcserver"
+	handlergrpc "driver_svc/internal/handler/grpc"
+	handlerhttp "driver_svc/internal/handler/http"
+	"driver_svc/internal/httpserver"
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/mq"
+	"driver_svc/internal/payout"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/repository/memory"
+	"driver_svc/internal/seed"
+	"driver_svc/internal/service"
+)
+
+// Aliases let callers outside this module configure the app and bridge the
+// bus without importing internal packages.
+type (
+	Config     = config.Config
+	Bus        = bus.Bus
+	Message    = bus.Message
+	Header     = bus.Header
+	Subscriber = bus.Subscriber
+	Driver     = models.Driver
+)
+
+// ErrBusClosed is what a Bus returns once closed.
+var ErrBusClosed = bus.ErrClosed
+
+func LoadConfig() Config {
+	return config.LoadFromEnv("driver_svc", "8081")
+}
+
+// Deps are the storage and broker the app runs on. The caller owns them and
+// closes the bus after the app has stopped.
+type Deps struct {
+	Drivers repository.DriverRepository
+	Jobs    repository.JobRepository
+	Payouts repository.PayoutRepository
+	Bus     bus.Bus
+}
+
+// InMemory returns fresh in-memory repositories on top of b, holding drivers
+// or, when none are given, the seeded roster.
+func InMemory(b Bus, drivers ...Driver) Deps {
+	if len(drivers) == 0 {
+		drivers = seed.Drivers
+	}
+	jobs := memory.NewJobRepo()
+	return Deps{Drivers: memory.NewDriverRepo(drivers...), Jobs: jobs, Payouts: memory.NewPayoutRepo(jobs), Bus: b}
+}
+
+func NewMemoryBus() Bus {
+	return bus.NewMemory()
+}
+
+type App struct {
+	cfg      Config
+	logger   *slog.Logger
+	http     *httpserver.Server
+	grpc     *grpcserver.Server
+	consumer *mq.BookingCreatedConsumer
+	cancels  *mq.BookingCancelledConsumer
+	ratings  *mq.RatingSubmittedConsumer
+	earnings *mq.BookingCompletedConsumer
+	payouts  *payout.Runner
+}
+
+func New(cfg Config, logger *slog.Logger, deps Deps) (*App, error) {
+	if !money.ValidCurrency(cfg.DefaultCurrency) {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY %q is not a supported ISO 4217 code", cfg.DefaultCurrency)
+	}
+	policy := service.PayoutPolicy{
+		MinAmount:   cfg.PayoutMinAmount,
+		MaxAttempts: cfg.PayoutMaxAttempts,
+		BaseBackoff: cfg.PayoutBaseBackoff,
+		MaxBackoff:  cfg.PayoutMaxBackoff,
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("payout settings: %w", err)
+	}
+	if cfg.PayoutPeriod <= 0 || cfg.PayoutInterval <= 0 {
+		return nil, fmt.Errorf("PAYOUT_PERIOD_SECONDS and PAYOUT_INTERVAL_SECONDS must be positive")
+	}
+	if cfg.RatingWindow <= 0 {
+		return nil, fmt.Errorf("RATING_WINDOW must be positive")
+	}
+	proxies, err := httpserver.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+	authn, err := auth.NewAuthenticator(authConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("auth setup: %w", err)
+	}
+	provider, err := payout.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Producer for booking.accepted and payout.paid
+	producer := mq.NewProducer(cfg, deps.Bus, logger)
+	// changes wakes gRPC WatchJobs streams as soon as a job opens or is taken
+	changes := service.NewBroadcaster()
+	jobsSvc := service.NewJobsService(deps.Drivers, deps.Jobs, producer, changes, logger)
+	// Consumer: booking.created -> upsert Open job
+	consumer := mq.NewBookingCreatedConsumer(cfg, deps.Bus, deps.Jobs, changes, logger)
+	// Consumer: booking.cancelled -> withdraw the job
+	cancels := mq.NewBookingCancelledConsumer(cfg, deps.Bus, deps.Jobs, changes, logger)
+	// Consumer: rating.submitted -> update the driver's rolling average
+	ratings := mq.NewRatingSubmittedConsumer(cfg, deps.Bus, deps.Drivers, logger)
+	// Consumer: booking.completed -> record what the ledger credited the driver
+	earnings := mq.NewBookingCompletedConsumer(cfg, deps.Bus, deps.Payouts, logger)
+	// Payouts: pays drivers their recorded earnings once a period ends
+	payoutSvc := service.NewPayoutService(deps.Drivers, deps.Payouts, provider, producer, policy, logger)
+	runner := payout.NewRunner(payoutSvc, cfg.PayoutPeriod, cfg.PayoutInterval, logger)
+
+	srv := httpserver.New(cfg, logger, authn, proxies)
+	handlerhttp.NewJobsHandler(jobsSvc).RegisterRoutes(srv.Router())
+	handlerhttp.NewReconcileHandler(jobsSvc).RegisterRoutes(srv.Router())
+	handlerhttp.NewPayoutsHandler(payoutSvc).RegisterRoutes(srv.Router())
+	srv.AddReadinessCheck(cfg.BusDriver, func(ctx context.Context) error {
+		return deps.Bus.Check(ctx, cfg.TopicBookingCreated, cfg.TopicBookingAccepted, cfg.TopicBookingCancelled, cfg.TopicRatingSubmitted, cfg.TopicBookingCompleted, cfg.TopicPayoutPaid)
+	})
+	srv.AddReadinessCheck("consumer."+cfg.TopicBookingCreated, consumer.Healthy)
+	srv.AddReadinessCheck("consumer."+cfg.TopicBookingCancelled, cancels.Healthy)
+	srv.AddReadinessCheck("consumer."+cfg.TopicRatingSubmitted, ratings.Healthy)
+	srv.AddReadinessCheck("consumer."+cfg.TopicBookingCompleted, earnings.Healthy)
+
+	// gRPC server shares the authenticator and service with HTTP
+	grpcSrv := grpcserver.New(cfg, logger, authn)
+	handlergrpc.NewJobsServer(jobsSvc).Register(grpcSrv.Registrar())
+
+	return &App{cfg: cfg, logger: logger, http: srv, grpc: grpcSrv, consumer: consumer, cancels: cancels, ratings: ratings, earnings: earnings, payouts: runner}, nil
+}
+
+// AddReadinessCheck registers another dependency probed by /readyz.
+func (a *App) AddReadinessCheck(name string, fn func(context.Context) error) {
+	a.http.AddReadinessCheck(name, fn)
+}
+
+// ShedOnPoolWait feeds the load shedder a DB pool wait signal.
+func (a *App) ShedOnPoolWait(fn func() time.Duration) {
+	a.http.ShedOnPoolWait(fn)
+}
+
+// Handler is the full HTTP API, for serving from a test server.
+func (a *App) Handler() http.Handler {
+	return a.http.Handler()
+}
+
+// Start runs the consumers and the payout runner in the background until ctx
+// ends.
+func (a *App) Start(ctx context.Context) {
+	go func() {
+		if err := a.consumer.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("booking.created consumer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.cancels.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("booking.cancelled consumer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.ratings.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("rating.submitted consumer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.earnings.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("booking.completed consumer stopped", slog.String("err", err.Error()))
+		}
+	}()
+	go func() {
+		if err := a.payouts.Run(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("payout runner stopped", slog.String("err", err.Error()))
+		}
+	}()
+}
+
+// Run starts the background workers and both servers, blocks until ctx ends or a server
+// fails, then shuts the servers down gracefully.
+func (a *App) Run(ctx context.Context) {
+	a.Start(ctx)
+	errCh := a.http.Start()
+	grpcErrCh := a.grpc.Start()
+
+	select {
+	case <-ctx.Done():
+	case err := <-errCh:
+		if err != nil {
+			a.logger.Error("server error", slog.String("err", err.Error()))
+		}
+	case err := <-grpcErrCh:
+		if err != nil {
+			a.logger.Error("grpc server error", slog.String("err", err.Error()))
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.GracefulTimeout)
+	defer cancel()
+	a.grpc.Shutdown(shutdownCtx)
+	_ = a.http.Shutdown(shutdownCtx)
+}
+
+// Close leaves the consumer groups.
+func (a *App) Close() error {
+	return errors.Join(a.consumer.Close(), a.cancels.Close(), a.ratings.Close(), a.earnings.Close())
+}
+
+// Token mints an HS256 token for role (rider, driver or admin) acting as sub.
+func Token(secret, role, sub string, ttl time.Duration) (string, error) {
+	p := auth.Principal{Subject: sub, Role: auth.Role(role)}
+	switch p.Role {
+	case auth.RoleRider:
+		p.RiderID = sub
+	case auth.RoleDriver:
+		p.DriverID = sub
+	case auth.RoleAdmin:
+	default:
+		return "", fmt.Errorf("unknown role %q", role)
+	}
+	return auth.NewHS256Token(secret, p, ttl)
+}
+
+func authConfig(cfg Config) auth.Config {
+	return auth.Config{
+		HS256Secret:    cfg.JWTHS256Secret,
+		RS256PublicKey: cfg.JWTRS256PublicKey,
+		JWKSFile:       cfg.JWTJWKSFile,
+		Issuer:         cfg.JWTIssuer,
+		Audience:       cfg.JWTAudience,
+	}
+}
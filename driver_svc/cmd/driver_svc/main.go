@@ -2,94 +2,115 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"driver_svc/internal/config"
+	"driver_svc/app"
+	"driver_svc/internal/bus"
 	"driver_svc/internal/db"
-	handlerhttp "driver_svc/internal/handler/http"
-	"driver_svc/internal/httpserver"
 	"driver_svc/internal/logging"
-	"driver_svc/internal/mq"
+	"driver_svc/internal/metrics"
 	"driver_svc/internal/repository/postgres"
 	"driver_svc/internal/seed"
-	"driver_svc/internal/service"
+	"driver_svc/internal/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var version = "0.1.0"
 
 func main() {
-	cfg := config.LoadFromEnv("driver_svc", "8081")
+	cfg := app.LoadConfig()
 	logger := logging.New(cfg.LogLevel, cfg.ServiceName).With(slog.String("version", version))
 
 	// Shared shutdown context
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// DB connect + bootstrap + seed
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		if err := runToken(cfg, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, cfg, logger, os.Args[2:]); err != nil {
+			logger.Error("migrate failed", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Tracing: exporter chosen by OTEL_TRACES_EXPORTER
+	exp, err := tracing.NewExporter(ctx, cfg.TracesExporter)
+	if err != nil {
+		logger.Error("tracing exporter failed", slog.String("err", err.Error()))
+		return
+	}
+	tp := tracing.Init(cfg.ServiceName, version, exp)
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = tp.Shutdown(flushCtx)
+	}()
+
+	// DB connect + migrate + seed
 	startupCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	pool, err := db.Connect(startupCtx, db.Params{
-		Host:     cfg.DBHost,
-		Port:     cfg.DBPort,
-		User:     cfg.DBUser,
-		Password: cfg.DBPassword,
-		Name:     cfg.DBName,
-	})
+	pool, err := db.Connect(startupCtx, dbParams(cfg))
 	if err != nil {
 		logger.Error("db connect failed", slog.String("err", err.Error()))
 		return
 	}
 	defer pool.Close()
 
-	if err := db.Bootstrap(startupCtx, pool); err != nil {
-		logger.Error("db bootstrap failed", slog.String("err", err.Error()))
-		return
+	if cfg.MigrateOnStart {
+		migrator, err := db.NewMigrator(pool, logger)
+		if err != nil {
+			logger.Error("load migrations failed", slog.String("err", err.Error()))
+			return
+		}
+		if err := migrator.Up(startupCtx); err != nil {
+			logger.Error("db migrate failed", slog.String("err", err.Error()))
+			return
+		}
 	}
 	if err := seed.SeedDrivers(startupCtx, pool); err != nil {
 		logger.Error("seed drivers failed", slog.String("err", err.Error()))
 		return
 	}
 
-	// Repos
-	driverRepo := postgres.NewDriverRepo(pool) // kept for next parts (GET /drivers)
-	jobRepo := postgres.NewJobRepo(pool)
-
-	// Producer for booking.accepted
-	producer := mq.NewProducer(cfg, logger)
-	defer func() { _ = producer.Close() }()
+	prometheus.MustRegister(metrics.NewPoolCollector(pool))
 
-	// Service + HTTP
-	jobsSvc := service.NewJobsService(driverRepo, jobRepo, producer, logger)
-	srv := httpserver.New(cfg, logger)
-	h := handlerhttp.NewJobsHandler(jobsSvc)
-	h.RegisterRoutes(srv.Router())
-
-	// Kafka consumer: booking.created -> upsert Open job
-	consumer := mq.NewBookingCreatedConsumer(cfg, jobRepo, logger)
-	defer func() { _ = consumer.Close() }()
-	go func() {
-		if err := consumer.Run(ctx); err != nil && ctx.Err() == nil {
-			logger.Error("booking.created consumer stopped", slog.String("err", err.Error()))
-		}
-	}()
-
-	// HTTP server
-	errCh := srv.Start()
-
-	select {
-	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.GracefulTimeout)
-		defer cancel()
-		_ = srv.Shutdown(shutdownCtx)
-	case err := <-errCh:
-		if err != nil {
-			logger.Error("server error", slog.String("err", err.Error()))
-		}
+	// Bus + app (jobs service, consumer, HTTP and gRPC)
+	msgBus, err := bus.Open(cfg)
+	if err != nil {
+		logger.Error("message bus setup failed", slog.String("err", err.Error()))
+		return
 	}
+	defer func() { _ = msgBus.Close() }()
+	a, err := app.New(cfg, logger, app.Deps{
+		Drivers: postgres.NewDriverRepo(pool),
+		Jobs:    postgres.NewJobRepo(pool),
+		Payouts: postgres.NewPayoutRepo(pool),
+		Bus:     msgBus,
+	})
+	if err != nil {
+		logger.Error("app setup failed", slog.String("err", err.Error()))
+		return
+	}
+	defer func() { _ = a.Close() }()
+	a.ShedOnPoolWait(db.NewAcquireWait(pool, time.Second).Current)
+	a.AddReadinessCheck("postgres", pool.Ping)
+
+	// Run until signalled, then shut down gracefully
+	a.Run(ctx)
 	logger.Info("exit")
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"driver_svc/app"
+	"driver_svc/internal/auth"
+	"driver_svc/internal/config"
+)
+
+// runToken implements the `token` subcommand, which mints an HS256 token with
+// JWT_HS256_SECRET for local development and testing.
+func runToken(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	role := fs.String("role", string(auth.RoleRider), "rider|driver|admin")
+	sub := fs.String("sub", "", "subject; also used as rider_id/driver_id")
+	ttl := fs.Duration("ttl", time.Hour, "token lifetime")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cfg.JWTHS256Secret == "" {
+		return errors.New("JWT_HS256_SECRET is not set")
+	}
+	if *sub == "" {
+		return errors.New("usage: driver_svc token -role rider|driver|admin -sub <id> [-ttl 1h]")
+	}
+	tok, err := app.Token(cfg.JWTHS256Secret, *role, *sub, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(tok)
+	return nil
+}
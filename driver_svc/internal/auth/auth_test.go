@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "test-secret-0123456789"
+
+func TestAuthenticate_HS256(t *testing.T) {
+	a, err := NewAuthenticator(Config{HS256Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok, _ := NewHS256Token(secret, Principal{Subject: "d-1", Role: RoleDriver}, time.Minute)
+	p, err := a.Authenticate(tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Role != RoleDriver || p.DriverID != "d-1" {
+		t.Fatalf("driver_id should default to sub, got %+v", p)
+	}
+
+	cases := map[string]string{
+		"expired":      mustHS256(t, secret, Claims{Role: RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}}),
+		"wrong secret": mustHS256(t, "other", Claims{Role: RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}),
+		"no exp":       mustHS256(t, secret, Claims{Role: RoleAdmin}),
+		"unknown role": mustHS256(t, secret, Claims{Role: "root", RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}),
+		"alg none":     mustNone(t),
+	}
+	for name, tok := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := a.Authenticate(tok); err == nil {
+				t.Fatalf("want error")
+			}
+		})
+	}
+}
+
+func TestAuthenticate_RS256FromJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA", "kid": "k1", "use": "sig", "alg": "RS256",
+		"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	b, _ := json.Marshal(doc)
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewAuthenticator(Config{JWKSFile: path, Issuer: "idp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(kid, iss string) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, Claims{
+			Role:    RoleRider,
+			RiderID: "r-7",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    iss,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	p, err := a.Authenticate(sign("k1", "idp"))
+	if err != nil || p.RiderID != "r-7" {
+		t.Fatalf("want rider r-7, got %+v err=%v", p, err)
+	}
+	if _, err := a.Authenticate(sign("k2", "idp")); err == nil {
+		t.Fatal("unknown kid must be rejected")
+	}
+	if _, err := a.Authenticate(sign("k1", "someone-else")); err == nil {
+		t.Fatal("wrong issuer must be rejected")
+	}
+	// HS256 isn't enabled, so an HMAC token is rejected regardless of its key
+	if _, err := a.Authenticate(mustHS256(t, secret, Claims{Role: RoleAdmin})); err == nil {
+		t.Fatal("HS256 must be rejected when only RS256 keys are configured")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	a, _ := NewAuthenticator(Config{HS256Secret: secret})
+	var seen Principal
+	h := Middleware(a)(RequireRole(RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	})))
+
+	adminTok, _ := NewHS256Token(secret, Principal{Subject: "ops", Role: RoleAdmin}, time.Minute)
+	riderTok, _ := NewHS256Token(secret, Principal{Subject: "r-1", Role: RoleRider}, time.Minute)
+
+	cases := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"malformed", "Token abc", http.StatusUnauthorized},
+		{"garbage", "Bearer abc", http.StatusUnauthorized},
+		{"wrong role", "Bearer " + riderTok, http.StatusForbidden},
+		{"ok", "Bearer " + adminTok, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if c.header != "" {
+				req.Header.Set("Authorization", c.header)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != c.want {
+				t.Fatalf("want %d, got %d body=%s", c.want, rr.Code, rr.Body.String())
+			}
+			if c.want == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("401 without WWW-Authenticate")
+			}
+		})
+	}
+	if seen.Subject != "ops" {
+		t.Fatalf("principal not propagated, got %+v", seen)
+	}
+}
+
+func mustHS256(t *testing.T, key string, c Claims) string {
+	t.Helper()
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func mustNone(t *testing.T) string {
+	t.Helper()
+	c := Claims{Role: RoleAdmin, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+	s, err := jwt.NewWithClaims(jwt.SigningMethodNone, c).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
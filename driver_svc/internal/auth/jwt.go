@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the token payload: registered claims plus the role and the
+// rider/driver identity the caller acts as.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role     Role   `json:"role"`
+	RiderID  string `json:"rider_id,omitempty"`
+	DriverID string `json:"driver_id,omitempty"`
+}
+
+type Config struct {
+	HS256Secret    string
+	RS256PublicKey string // path to a PEM-encoded RSA public key
+	JWKSFile       string // path to a local JWKS document
+	Issuer         string
+	Audience       string
+}
+
+// Authenticator verifies HS256 and RS256 tokens against the configured keys.
+type Authenticator struct {
+	hmacKey []byte
+	rsaKeys map[string]*rsa.PublicKey // by kid; "" holds a key configured without one
+	parser  *jwt.Parser
+}
+
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{rsaKeys: map[string]*rsa.PublicKey{}}
+	methods := []string{}
+
+	if cfg.HS256Secret != "" {
+		a.hmacKey = []byte(cfg.HS256Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.RS256PublicKey != "" {
+		pemBytes, err := os.ReadFile(cfg.RS256PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("auth: parse public key: %w", err)
+		}
+		a.rsaKeys[""] = key
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, k := range keys {
+			a.rsaKeys[kid] = k
+		}
+	}
+	if len(a.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("auth: no verification keys configured (set JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE or JWT_JWKS_FILE)")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+	return a, nil
+}
+
+// Authenticate parses and verifies raw and returns the principal it names.
+func (a *Authenticator) Authenticate(raw string) (Principal, error) {
+	var claims Claims
+	if _, err := a.parser.ParseWithClaims(raw, &claims, a.keyFor); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	p := Principal{Subject: claims.Subject, Role: claims.Role, RiderID: claims.RiderID, DriverID: claims.DriverID}
+	switch p.Role {
+	case RoleRider:
+		if p.RiderID == "" {
+			p.RiderID = p.Subject
+		}
+		if p.RiderID == "" {
+			return Principal{}, fmt.Errorf("%w: rider token without rider_id or sub", ErrInvalidToken)
+		}
+	case RoleDriver:
+		if p.DriverID == "" {
+			p.DriverID = p.Subject
+		}
+		if p.DriverID == "" {
+			return Principal{}, fmt.Errorf("%w: driver token without driver_id or sub", ErrInvalidToken)
+		}
+	case RoleAdmin:
+	default:
+		return Principal{}, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, p.Role)
+	}
+	return p, nil
+}
+
+func (a *Authenticator) keyFor(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.hmacKey, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := t.Header["kid"].(string)
+		if k, ok := a.rsaKeys[kid]; ok {
+			return k, nil
+		}
+		if kid == "" && len(a.rsaKeys) == 1 {
+			for _, k := range a.rsaKeys {
+				return k, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}
+}
+
+// NewHS256Token mints a token for p; used by tests and local tooling.
+func NewHS256Token(secret string, p Principal, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   p.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Role:     p.Role,
+		RiderID:  p.RiderID,
+		DriverID: p.DriverID,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: read jwks: %w", err)
+	}
+	var doc jwks
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("auth: parse jwks: %w", err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("auth: jwks key %q: bad modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("auth: jwks key %q: bad exponent: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("auth: jwks has no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"driver_svc/internal/problem"
+)
+
+// Middleware rejects requests without a valid bearer token and stores the
+// resulting Principal in the request context.
+func Middleware(a *Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, r, ErrMissingToken.Error())
+				return
+			}
+			p, err := a.Authenticate(raw)
+			if err != nil {
+				unauthorized(w, r, err.Error())
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// RequireRole allows the request through only if the principal holds one of roles.
+func RequireRole(roles ...Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			if !ok {
+				unauthorized(w, r, ErrMissingToken.Error())
+				return
+			}
+			if !p.HasRole(roles...) {
+				problem.Write(w, r, problem.New(http.StatusForbidden, problem.CodeForbidden,
+					"role "+string(p.Role)+" may not perform this action"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	problem.Write(w, r, problem.New(http.StatusUnauthorized, problem.CodeUnauthenticated, msg))
+}
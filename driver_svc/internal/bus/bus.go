@@ -0,0 +1,74 @@
+// Package bus hides the message broker behind small Publisher/Subscriber
+// interfaces. Kafka is the production implementation; Memory runs in-process
+// with the same per-key ordering and consumer-group semantics, so services
+// can run without a broker in tests and local development.
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"driver_svc/internal/config"
+)
+
+// ErrClosed is returned once the bus or subscriber has been closed.
+var ErrClosed = errors.New("bus closed")
+
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a record on a topic. Partition, Offset and HighWaterMark are
+// filled in on fetched messages and ignored when publishing.
+type Message struct {
+	Topic         string
+	Key           []byte
+	Value         []byte
+	Headers       []Header
+	Partition     int
+	Offset        int64
+	HighWaterMark int64
+}
+
+// Publisher writes messages to their Topic. Messages with the same key land
+// in the same partition and are delivered in publish order.
+type Publisher interface {
+	Publish(ctx context.Context, msgs ...Message) error
+}
+
+// Subscriber reads one topic as a member of a consumer group. Each message is
+// delivered to one member of the group; messages fetched but not committed
+// are redelivered after a rebalance or restart.
+type Subscriber interface {
+	Fetch(ctx context.Context) (Message, error)
+	Commit(ctx context.Context, msgs ...Message) error
+	Close() error
+}
+
+type Bus interface {
+	Publisher
+	Subscribe(topic, group string) Subscriber
+	// Check is a readiness check that the broker serves topics.
+	Check(ctx context.Context, topics ...string) error
+	Close() error
+}
+
+const (
+	DriverKafka  = "kafka"
+	DriverMemory = "memory"
+)
+
+// Open returns the implementation selected by cfg.BusDriver.
+func Open(cfg config.Config) (Bus, error) {
+	switch cfg.BusDriver {
+	case DriverKafka:
+		return NewKafka(strings.Split(cfg.KafkaBrokers, ",")), nil
+	case DriverMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown BUS_DRIVER %q (want %s or %s)", cfg.BusDriver, DriverKafka, DriverMemory)
+	}
+}
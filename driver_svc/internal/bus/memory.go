@@ -0,0 +1,245 @@
+package bus
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// memoryPartitions is how many partitions each in-memory topic has. More than
+// one, so per-key ordering and group assignment behave as they do on Kafka.
+const memoryPartitions = 4
+
+// Memory is an in-process broker. Topics are append-only partitioned logs that
+// are never trimmed; each consumer group keeps a committed offset per
+// partition and spreads partitions across its live members. Close the bus to
+// release blocked fetches.
+type Memory struct {
+	mu     sync.Mutex
+	topics map[string]*memTopic
+	closed bool
+}
+
+type memTopic struct {
+	parts  [][]Message
+	groups map[string]*memGroup
+	rr     int // partition for the next unkeyed message
+	// changed is closed and replaced whenever a fetch may now succeed.
+	changed chan struct{}
+}
+
+type memGroup struct {
+	committed [memoryPartitions]int64
+	members   []*memSubscriber
+}
+
+func NewMemory() *Memory {
+	return &Memory{topics: make(map[string]*memTopic)}
+}
+
+// topic returns the named topic, creating it on first use. Callers hold m.mu.
+func (m *Memory) topic(name string) *memTopic {
+	t, ok := m.topics[name]
+	if !ok {
+		t = &memTopic{
+			parts:   make([][]Message, memoryPartitions),
+			groups:  make(map[string]*memGroup),
+			changed: make(chan struct{}),
+		}
+		m.topics[name] = t
+	}
+	return t
+}
+
+func (t *memTopic) wake() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+func (m *Memory) Publish(ctx context.Context, msgs ...Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	touched := make(map[*memTopic]bool)
+	for _, msg := range msgs {
+		t := m.topic(msg.Topic)
+		p := t.partition(msg.Key)
+		t.parts[p] = append(t.parts[p], Message{
+			Topic:     msg.Topic,
+			Key:       clone(msg.Key),
+			Value:     clone(msg.Value),
+			Headers:   cloneHeaders(msg.Headers),
+			Partition: p,
+			Offset:    int64(len(t.parts[p])),
+		})
+		touched[t] = true
+	}
+	for t := range touched {
+		t.wake()
+	}
+	return nil
+}
+
+// partition hashes key like kafka.Hash; unkeyed messages go round-robin.
+func (t *memTopic) partition(key []byte) int {
+	if len(key) == 0 {
+		t.rr = (t.rr + 1) % memoryPartitions
+		return t.rr
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % memoryPartitions)
+}
+
+// Subscribe joins group on topic, which rebalances the group's partitions.
+func (m *Memory) Subscribe(topic, group string) Subscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.topic(topic)
+	g, ok := t.groups[group]
+	if !ok {
+		g = &memGroup{}
+		t.groups[group] = g
+	}
+	s := &memSubscriber{bus: m, topic: t, group: g}
+	g.members = append(g.members, s)
+	t.rebalance(g)
+	return s
+}
+
+// rebalance hands partition p to member p mod len(members) and rewinds every
+// member to the committed offsets, so fetched-but-uncommitted messages are
+// delivered again, as after a Kafka rebalance. Callers hold m.mu.
+func (t *memTopic) rebalance(g *memGroup) {
+	for _, s := range g.members {
+		s.assigned = s.assigned[:0]
+		s.pos = make(map[int]int64)
+	}
+	if len(g.members) > 0 {
+		for p := 0; p < memoryPartitions; p++ {
+			s := g.members[p%len(g.members)]
+			s.assigned = append(s.assigned, p)
+			s.pos[p] = g.committed[p]
+		}
+	}
+	t.wake()
+}
+
+func (m *Memory) Check(context.Context, ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.closed {
+		m.closed = true
+		for _, t := range m.topics {
+			t.wake()
+		}
+	}
+	return nil
+}
+
+type memSubscriber struct {
+	bus      *Memory
+	topic    *memTopic
+	group    *memGroup
+	assigned []int
+	pos      map[int]int64
+	next     int // index into assigned to try first, for fairness
+	closed   bool
+}
+
+func (s *memSubscriber) Fetch(ctx context.Context) (Message, error) {
+	m := s.bus
+	for {
+		m.mu.Lock()
+		if m.closed || s.closed {
+			m.mu.Unlock()
+			return Message{}, ErrClosed
+		}
+		for i := range s.assigned {
+			p := s.assigned[(s.next+i)%len(s.assigned)]
+			log := s.topic.parts[p]
+			if off := s.pos[p]; off < int64(len(log)) {
+				s.pos[p] = off + 1
+				s.next = (s.next + i + 1) % len(s.assigned)
+				msg := log[off]
+				msg.HighWaterMark = int64(len(log))
+				m.mu.Unlock()
+				return msg, nil
+			}
+		}
+		changed := s.topic.changed
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Commit records msgs as processed for the group. Offsets only move forward.
+func (s *memSubscriber) Commit(ctx context.Context, msgs ...Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.bus.closed || s.closed {
+		return ErrClosed
+	}
+	for _, msg := range msgs {
+		if next := msg.Offset + 1; next > s.group.committed[msg.Partition] {
+			s.group.committed[msg.Partition] = next
+		}
+	}
+	return nil
+}
+
+// Close leaves the group; its partitions move to the remaining members.
+func (s *memSubscriber) Close() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	members := s.group.members[:0]
+	for _, other := range s.group.members {
+		if other != s {
+			members = append(members, other)
+		}
+	}
+	s.group.members = members
+	s.topic.rebalance(s.group)
+	return nil
+}
+
+func clone(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+func cloneHeaders(hs []Header) []Header {
+	out := make([]Header, len(hs))
+	for i, h := range hs {
+		out[i] = Header{Key: h.Key, Value: clone(h.Value)}
+	}
+	return out
+}
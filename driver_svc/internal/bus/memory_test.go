@@ -0,0 +1,131 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func fetchN(t *testing.T, s Subscriber, n int) []Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out := make([]Message, 0, n)
+	for len(out) < n {
+		msg, err := s.Fetch(ctx)
+		if err != nil {
+			t.Fatalf("fetch %d/%d: %v", len(out)+1, n, err)
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+// assertEmpty fails if s has anything left to deliver.
+func assertEmpty(t *testing.T, s Subscriber) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if msg, err := s.Fetch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want nothing pending, got %+v %v", msg, err)
+	}
+}
+
+func TestMemory_PerKeyOrder(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	sub := b.Subscribe("t", "g")
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			if err := b.Publish(ctx, Message{Topic: "t", Key: []byte(key), Value: []byte(fmt.Sprint(i))}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	last := map[string]int{"a": -1, "b": -1, "c": -1}
+	for _, msg := range fetchN(t, sub, 30) {
+		var i int
+		fmt.Sscan(string(msg.Value), &i)
+		if i != last[string(msg.Key)]+1 {
+			t.Fatalf("key %s: got %d after %d", msg.Key, i, last[string(msg.Key)])
+		}
+		last[string(msg.Key)] = i
+	}
+}
+
+func TestMemory_ConsumerGroups(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	ctx := context.Background()
+	a1 := b.Subscribe("t", "a")
+	a2 := b.Subscribe("t", "a")
+	other := b.Subscribe("t", "b")
+	for i := 0; i < 20; i++ {
+		_ = b.Publish(ctx, Message{Topic: "t", Key: []byte(fmt.Sprint("k", i))})
+	}
+
+	// Every group sees every message once; members of a group split them.
+	if got := fetchN(t, other, 20); len(got) != 20 {
+		t.Fatalf("group b: want 20, got %d", len(got))
+	}
+	seen := map[string]bool{}
+	for _, s := range []Subscriber{a1, a2} {
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		for {
+			msg, err := s.Fetch(ctx)
+			if err != nil {
+				break
+			}
+			if seen[string(msg.Key)] {
+				t.Fatalf("%s delivered twice within group a", msg.Key)
+			}
+			seen[string(msg.Key)] = true
+		}
+		cancel()
+	}
+	if len(seen) != 20 {
+		t.Fatalf("group a: want 20 distinct, got %d", len(seen))
+	}
+}
+
+func TestMemory_UncommittedRedeliveredAfterRebalance(t *testing.T) {
+	b := NewMemory()
+	defer b.Close()
+	ctx := context.Background()
+	first := b.Subscribe("t", "g")
+	_ = b.Publish(ctx, Message{Topic: "t", Key: []byte("k"), Value: []byte("1")}, Message{Topic: "t", Key: []byte("k"), Value: []byte("2")})
+
+	got := fetchN(t, first, 2)
+	if err := first.Commit(ctx, got[0]); err != nil {
+		t.Fatal(err)
+	}
+	_ = first.Close()
+
+	second := b.Subscribe("t", "g")
+	redelivered := fetchN(t, second, 1)
+	if string(redelivered[0].Value) != "2" {
+		t.Fatalf("want uncommitted message 2 again, got %q", redelivered[0].Value)
+	}
+	assertEmpty(t, second)
+}
+
+func TestMemory_Close(t *testing.T) {
+	b := NewMemory()
+	sub := b.Subscribe("t", "g")
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := sub.Fetch(context.Background())
+		errCh <- err
+	}()
+	_ = b.Close()
+	if err := <-errCh; !errors.Is(err, ErrClosed) {
+		t.Fatalf("blocked fetch: want ErrClosed, got %v", err)
+	}
+	if err := b.Publish(context.Background(), Message{Topic: "t"}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("publish: want ErrClosed, got %v", err)
+	}
+}
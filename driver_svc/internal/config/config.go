@@ -7,57 +7,201 @@ import (
 )
 
 type Config struct {
-	ServiceName     string
-	HTTPPort        string
+	ServiceName string
+	HTTPPort    string
+	// GRPCPort serves the gRPC API next to the HTTP port.
+	GRPCPort        string
 	GracefulTimeout time.Duration
-	LogLevel        string
+	// ShutdownDrainDelay is how long /readyz reports draining before the listener closes.
+	ShutdownDrainDelay time.Duration
+	LogLevel           string
+	// TracesExporter selects the span exporter: otlp, stdout or none.
+	TracesExporter string
 
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+	// MigrateOnStart applies pending schema migrations before serving.
+	MigrateOnStart bool
 
-	KafkaBrokers         string
-	TopicBookingCreated  string
-	TopicBookingAccepted string
-	ConsumerGroupJobs    string
+	JWTHS256Secret    string
+	JWTRS256PublicKey string
+	JWTJWKSFile       string
+	JWTIssuer         string
+	JWTAudience       string
+
+	// Per-client token buckets; a zero rate disables limiting for that class.
+	RateLimitReadRPS    float64
+	RateLimitReadBurst  int
+	RateLimitWriteRPS   float64
+	RateLimitWriteBurst int
+	// RateLimitUnauthRPS and RateLimitUnauthBurst budget, per client IP,
+	// requests that fail authentication.
+	RateLimitUnauthRPS   float64
+	RateLimitUnauthBurst int
+	// TrustedProxies lists the addresses and CIDR prefixes whose
+	// X-Forwarded-For is believed; empty trusts none.
+	TrustedProxies string
+	// Load shedding thresholds; zero disables the check.
+	ShedMaxInFlight int
+	ShedMaxPoolWait time.Duration
+	// OpenAPIResponseValidation is off, log or strict; requests are always validated.
+	OpenAPIResponseValidation string
+	// DefaultCurrency is the ISO 4217 code given to legacy integer prices,
+	// which counted whole units.
+	DefaultCurrency string
+
+	// BusDriver selects the message bus: kafka or memory (in-process only).
+	BusDriver             string
+	KafkaBrokers          string
+	TopicBookingCreated   string
+	TopicBookingAccepted  string
+	TopicBookingCancelled string
+	TopicRatingSubmitted  string
+	TopicBookingCompleted string
+	TopicPayoutPaid       string
+	ConsumerGroupJobs     string
+	ConsumerGroupCancels  string
+	ConsumerGroupRatings  string
+	ConsumerGroupEarnings string
+	TopicDLQSuffix        string
+	// RatingWindow is how many of a driver's latest ratings their average
+	// covers.
+	RatingWindow int
+
+	// PayoutProvider selects how drivers are paid; only fake exists so far.
+	PayoutProvider string
+	// PayoutFakeRejectAbove makes the fake provider reject payouts above this
+	// many minor units; zero never rejects.
+	PayoutFakeRejectAbove int64
+	// PayoutMinAmount is the smallest payout in minor units; smaller
+	// balances carry over to the next period.
+	PayoutMinAmount int64
+	// PayoutPeriod is the length of a payout period: each run pays the
+	// earnings of trips completed before the start of the current period.
+	PayoutPeriod      time.Duration
+	PayoutInterval    time.Duration
+	PayoutMaxAttempts int
+	PayoutBaseBackoff time.Duration
+	PayoutMaxBackoff  time.Duration
 }
 
 func LoadFromEnv(serviceName, defaultPort string) Config {
 	logLevel := getEnv("LOG_LEVEL", "info")
 	port := getEnv("HTTP_PORT", defaultPort)
+	grpcPort := getEnv("GRPC_PORT", defByService(serviceName, "9090", "9091"))
 	gt := getEnvInt("GRACEFUL_TIMEOUT_SECONDS", 10)
+	drain := getEnvInt("SHUTDOWN_DRAIN_SECONDS", 0)
+	tracesExporter := getEnv("OTEL_TRACES_EXPORTER", "none")
 
 	dbHost := getEnv("DB_HOST", defByService(serviceName, "booking_db", "driver_db"))
 	dbPort := getEnv("DB_PORT", "5432")
 	dbUser := getEnv("DB_USER", defByService(serviceName, "booking", "driver"))
 	dbPass := getEnv("DB_PASSWORD", dbUser)
 	dbName := getEnv("DB_NAME", dbUser)
+	migrateOnStart := getEnvBool("DB_MIGRATE_ON_START", true)
+
+	jwtSecret := getEnv("JWT_HS256_SECRET", "")
+	jwtPubKey := getEnv("JWT_RS256_PUBLIC_KEY_FILE", "")
+	jwtJWKS := getEnv("JWT_JWKS_FILE", "")
+	jwtIssuer := getEnv("JWT_ISSUER", "")
+	jwtAudience := getEnv("JWT_AUDIENCE", "")
+
+	rlReadRPS := getEnvFloat("RATE_LIMIT_READ_RPS", 20)
+	rlReadBurst := getEnvInt("RATE_LIMIT_READ_BURST", 40)
+	rlWriteRPS := getEnvFloat("RATE_LIMIT_WRITE_RPS", 5)
+	rlWriteBurst := getEnvInt("RATE_LIMIT_WRITE_BURST", 10)
+	rlUnauthRPS := getEnvFloat("RATE_LIMIT_UNAUTH_RPS", 1)
+	rlUnauthBurst := getEnvInt("RATE_LIMIT_UNAUTH_BURST", 10)
+	trustedProxies := getEnv("TRUSTED_PROXIES", "")
+	shedInFlight := getEnvInt("SHED_MAX_INFLIGHT", 256)
+	shedPoolWait := getEnvInt("SHED_MAX_POOL_WAIT_MS", 250)
+	openapiResponses := getEnv("OPENAPI_RESPONSE_VALIDATION", "log")
+	defaultCurrency := getEnv("DEFAULT_CURRENCY", "INR")
 
+	busDriver := getEnv("BUS_DRIVER", "kafka")
 	kBrokers := getEnv("KAFKA_BROKERS", "redpanda:9092")
 	tCreated := getEnv("TOPIC_BOOKING_CREATED", "booking.created")
 	tAccepted := getEnv("TOPIC_BOOKING_ACCEPTED", "booking.accepted")
+	tCancelled := getEnv("TOPIC_BOOKING_CANCELLED", "booking.cancelled")
 	cgJobs := getEnv("CONSUMER_GROUP_JOBS", "driver_svc.jobs")
+	tRated := getEnv("TOPIC_RATING_SUBMITTED", "rating.submitted")
+	cgCancels := getEnv("CONSUMER_GROUP_CANCELS", "driver_svc.cancels")
+	cgRatings := getEnv("CONSUMER_GROUP_RATINGS", "driver_svc.ratings")
+	tCompleted := getEnv("TOPIC_BOOKING_COMPLETED", "booking.completed")
+	cgEarnings := getEnv("CONSUMER_GROUP_EARNINGS", "driver_svc.earnings")
+	tPaid := getEnv("TOPIC_PAYOUT_PAID", "payout.paid")
+	dlqSuffix := getEnv("TOPIC_DLQ_SUFFIX", ".dlq")
+	ratingWindow := getEnvInt("RATING_WINDOW", 100)
+
+	payProvider := getEnv("PAYOUT_PROVIDER", "fake")
+	payRejectAbove := getEnvInt("PAYOUT_FAKE_REJECT_ABOVE", 0)
+	payMin := getEnvInt("PAYOUT_MIN_AMOUNT", 10000)
+	payPeriod := getEnvInt("PAYOUT_PERIOD_SECONDS", 86400)
+	payInterval := getEnvInt("PAYOUT_INTERVAL_SECONDS", 3600)
+	payAttempts := getEnvInt("PAYOUT_MAX_ATTEMPTS", 5)
+	payBase := getEnvInt("PAYOUT_BACKOFF_BASE_SECONDS", 60)
+	payMax := getEnvInt("PAYOUT_BACKOFF_MAX_SECONDS", 3600)
 
 	return Config{
-		ServiceName:          serviceName,
-		HTTPPort:             port,
-		GracefulTimeout:      time.Duration(gt) * time.Second,
-		LogLevel:             logLevel,
-		DBHost:               dbHost,
-		DBPort:               dbPort,
-		DBUser:               dbUser,
-		DBPassword:           dbPass,
-		DBName:               dbName,
-		KafkaBrokers:         kBrokers,
-		TopicBookingCreated:  tCreated,
-		TopicBookingAccepted: tAccepted,
-		ConsumerGroupJobs:    cgJobs,
+		ServiceName:               serviceName,
+		HTTPPort:                  port,
+		GRPCPort:                  grpcPort,
+		GracefulTimeout:           time.Duration(gt) * time.Second,
+		ShutdownDrainDelay:        time.Duration(drain) * time.Second,
+		LogLevel:                  logLevel,
+		TracesExporter:            tracesExporter,
+		DBHost:                    dbHost,
+		DBPort:                    dbPort,
+		DBUser:                    dbUser,
+		DBPassword:                dbPass,
+		DBName:                    dbName,
+		MigrateOnStart:            migrateOnStart,
+		JWTHS256Secret:            jwtSecret,
+		JWTRS256PublicKey:         jwtPubKey,
+		JWTJWKSFile:               jwtJWKS,
+		JWTIssuer:                 jwtIssuer,
+		JWTAudience:               jwtAudience,
+		RateLimitReadRPS:          rlReadRPS,
+		RateLimitReadBurst:        rlReadBurst,
+		RateLimitWriteRPS:         rlWriteRPS,
+		RateLimitWriteBurst:       rlWriteBurst,
+		RateLimitUnauthRPS:        rlUnauthRPS,
+		RateLimitUnauthBurst:      rlUnauthBurst,
+		TrustedProxies:            trustedProxies,
+		ShedMaxInFlight:           shedInFlight,
+		ShedMaxPoolWait:           time.Duration(shedPoolWait) * time.Millisecond,
+		OpenAPIResponseValidation: openapiResponses,
+		DefaultCurrency:           defaultCurrency,
+		BusDriver:                 busDriver,
+		KafkaBrokers:              kBrokers,
+		TopicBookingCreated:       tCreated,
+		TopicBookingAccepted:      tAccepted,
+		TopicBookingCancelled:     tCancelled,
+		TopicRatingSubmitted:      tRated,
+		TopicBookingCompleted:     tCompleted,
+		TopicPayoutPaid:           tPaid,
+		ConsumerGroupJobs:         cgJobs,
+		ConsumerGroupCancels:      cgCancels,
+		ConsumerGroupRatings:      cgRatings,
+		ConsumerGroupEarnings:     cgEarnings,
+		TopicDLQSuffix:            dlqSuffix,
+		RatingWindow:              ratingWindow,
+		PayoutProvider:            payProvider,
+		PayoutFakeRejectAbove:     int64(payRejectAbove),
+		PayoutMinAmount:           int64(payMin),
+		PayoutPeriod:              time.Duration(payPeriod) * time.Second,
+		PayoutInterval:            time.Duration(payInterval) * time.Second,
+		PayoutMaxAttempts:         payAttempts,
+		PayoutBaseBackoff:         time.Duration(payBase) * time.Second,
+		PayoutMaxBackoff:          time.Duration(payMax) * time.Second,
 	}
 }
 
-func (c Config) Addr() string { return ":" + c.HTTPPort }
+func (c Config) Addr() string     { return ":" + c.HTTPPort }
+func (c Config) GRPCAddr() string { return ":" + c.GRPCPort }
 
 func defByService(name, bookingDefault, driverDefault string) string {
 	switch name {
@@ -84,3 +228,21 @@ func getEnvInt(key string, def int) int {
 	}
 	return def
 }
+
+func getEnvFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func getEnvBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migs, err := LoadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		t.Fatalf("embedded migrations: %v", err)
+	}
+	if len(migs) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range migs {
+		if m.Version != int64(i+1) {
+			t.Fatalf("versions must be contiguous from 1: got %d at index %d", m.Version, i)
+		}
+	}
+}
+
+func TestLoadMigrations_Table(t *testing.T) {
+	cases := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"m/0002_b.up.sql":   {Data: []byte("B")},
+				"m/0002_b.down.sql": {Data: []byte("-B")},
+				"m/0001_a.up.sql":   {Data: []byte("A")},
+				"m/0001_a.down.sql": {Data: []byte("-A")},
+			},
+			want: []int64{1, 2},
+		},
+		{
+			name:    "missing down",
+			files:   fstest.MapFS{"m/0001_a.up.sql": {Data: []byte("A")}},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"m/0001_a.up.sql":   {Data: []byte("A")},
+				"m/0001_b.down.sql": {Data: []byte("-B")},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "bad file name",
+			files:   fstest.MapFS{"m/init.sql": {Data: []byte("A")}},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			migs, err := LoadMigrations(c.files, "m")
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", migs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migs) != len(c.want) {
+				t.Fatalf("want %d migrations, got %d", len(c.want), len(migs))
+			}
+			for i, v := range c.want {
+				if migs[i].Version != v {
+					t.Fatalf("index %d: want %d got %d", i, v, migs[i].Version)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviousVersion(t *testing.T) {
+	migs := []Migration{{Version: 1}, {Version: 2}, {Version: 5}}
+	if got := previousVersion(migs, 5); got != 2 {
+		t.Fatalf("want 2 got %d", got)
+	}
+	if got := previousVersion(migs, 1); got != 0 {
+		t.Fatalf("want 0 got %d", got)
+	}
+}
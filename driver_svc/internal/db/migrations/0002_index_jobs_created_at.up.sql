@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs (created_at);
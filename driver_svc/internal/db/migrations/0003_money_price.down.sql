@@ -0,0 +1,4 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS price_currency;
+UPDATE jobs SET price_amount = price_amount / 100;
+ALTER TABLE jobs ALTER COLUMN price_amount TYPE INTEGER;
+ALTER TABLE jobs RENAME COLUMN price_amount TO price;
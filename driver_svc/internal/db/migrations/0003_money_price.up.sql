@@ -0,0 +1,6 @@
+-- Prices were whole rupees with no currency; store minor units plus an ISO 4217 code.
+ALTER TABLE jobs RENAME COLUMN price TO price_amount;
+ALTER TABLE jobs ALTER COLUMN price_amount TYPE BIGINT;
+UPDATE jobs SET price_amount = price_amount * 100;
+ALTER TABLE jobs ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'INR';
+ALTER TABLE jobs ALTER COLUMN price_currency DROP DEFAULT;
@@ -0,0 +1,4 @@
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+-- NOT VALID keeps jobs that were cancelled in the meantime.
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check
+  CHECK (status IN ('Open','Taken')) NOT VALID;
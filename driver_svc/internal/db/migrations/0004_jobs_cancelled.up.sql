@@ -0,0 +1,4 @@
+-- A booking cancelled by its rider withdraws the job.
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check
+  CHECK (status IN ('Open','Taken','Cancelled'));
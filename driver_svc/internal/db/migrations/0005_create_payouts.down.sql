@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payout_items;
+DROP TABLE IF EXISTS payouts;
+ALTER TABLE jobs DROP COLUMN IF EXISTS accepted_at;
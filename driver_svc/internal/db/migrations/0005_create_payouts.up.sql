@@ -0,0 +1,35 @@
+-- accepted_at places a taken job's earnings in a payout period.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS accepted_at TIMESTAMPTZ NULL;
+UPDATE jobs SET accepted_at = created_at WHERE accepted_driver_id IS NOT NULL AND accepted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS payouts (
+  id TEXT PRIMARY KEY,
+  driver_id TEXT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  currency CHAR(3) NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('pending','paid','failed')) DEFAULT 'pending',
+  period_start TIMESTAMPTZ NOT NULL,
+  period_end TIMESTAMPTZ NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  provider_ref TEXT NULL,
+  last_error TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  paid_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_driver ON payouts (driver_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payouts_due ON payouts (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS payout_items (
+  payout_id TEXT NOT NULL REFERENCES payouts (id) ON DELETE CASCADE,
+  booking_id TEXT NOT NULL REFERENCES jobs (booking_id),
+  fare_amount BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount >= 0),
+  -- Cleared when the payout fails, which returns the job to the unpaid pool.
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  PRIMARY KEY (payout_id, booking_id)
+);
+
+-- A job is covered by at most one pending or paid payout.
+CREATE UNIQUE INDEX IF NOT EXISTS payout_items_active_booking ON payout_items (booking_id) WHERE active;
@@ -0,0 +1,3 @@
+ALTER TABLE drivers DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE drivers DROP COLUMN IF EXISTS rating_average;
+DROP TABLE IF EXISTS driver_ratings;
@@ -0,0 +1,14 @@
+-- Riders' ratings of drivers, one per booking, from booking_svc's
+-- rating.submitted. drivers keeps the rolling average so GET /drivers does
+-- not recompute it.
+CREATE TABLE IF NOT EXISTS driver_ratings (
+  booking_id TEXT PRIMARY KEY,
+  driver_id TEXT NOT NULL,
+  stars SMALLINT NOT NULL CHECK (stars BETWEEN 1 AND 5),
+  rated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_ratings_driver_rated_at ON driver_ratings (driver_id, rated_at DESC, booking_id DESC);
+
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS rating_average DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
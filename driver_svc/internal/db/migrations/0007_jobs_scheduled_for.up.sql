@@ -0,0 +1,2 @@
+-- Pickup time of a reserved ride, released to drivers shortly before it.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ;
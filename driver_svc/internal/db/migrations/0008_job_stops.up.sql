@@ -0,0 +1,9 @@
+-- Intermediate stops of a multi-stop ride, in seq order between the pickup
+-- and the dropoff.
+CREATE TABLE IF NOT EXISTS job_stops (
+  booking_id TEXT NOT NULL REFERENCES jobs (booking_id) ON DELETE CASCADE,
+  seq INTEGER NOT NULL CHECK (seq >= 1),
+  lat DOUBLE PRECISION NOT NULL,
+  lng DOUBLE PRECISION NOT NULL,
+  PRIMARY KEY (booking_id, seq)
+);
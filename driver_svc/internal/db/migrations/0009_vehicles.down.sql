@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS passengers;
+ALTER TABLE jobs DROP COLUMN IF EXISTS vehicle_type;
+DROP TABLE IF EXISTS vehicles;
@@ -0,0 +1,12 @@
+-- One vehicle per driver; a job's vehicle_type and passengers restrict
+-- which drivers may see and accept it.
+CREATE TABLE IF NOT EXISTS vehicles (
+  driver_id TEXT PRIMARY KEY REFERENCES drivers (driver_id) ON DELETE CASCADE,
+  vehicle_type TEXT NOT NULL CHECK (vehicle_type IN ('bike','auto','sedan','suv')),
+  seats INTEGER NOT NULL CHECK (seats >= 1),
+  plate TEXT NOT NULL UNIQUE
+);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS vehicle_type TEXT NULL
+  CHECK (vehicle_type IN ('bike','auto','sedan','suv'));
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS passengers INTEGER NOT NULL DEFAULT 1 CHECK (passengers >= 1);
@@ -0,0 +1,13 @@
+-- What booking_svc's ledger credited the driver for each completed trip, as
+-- carried by booking.completed. Payouts pay these amounts, so they always
+-- match the ledger.
+CREATE TABLE IF NOT EXISTS earnings (
+  booking_id TEXT PRIMARY KEY REFERENCES jobs (booking_id),
+  driver_id TEXT NOT NULL,
+  fare_amount BIGINT NOT NULL CHECK (fare_amount > 0),
+  amount BIGINT NOT NULL CHECK (amount >= 0),
+  currency CHAR(3) NOT NULL,
+  completed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_earnings_completed_at ON earnings (completed_at);
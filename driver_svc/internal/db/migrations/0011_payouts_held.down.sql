@@ -0,0 +1,4 @@
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+-- NOT VALID keeps payouts that were held in the meantime.
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending','paid','failed')) NOT VALID;
@@ -0,0 +1,5 @@
+-- A payout that runs out of attempts on errors other than a rejection is
+-- held with its jobs, as the provider may have paid it.
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending','paid','failed','held'));
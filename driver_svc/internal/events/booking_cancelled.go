@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"driver_svc/internal/money"
+)
+
+type BookingCancelled struct {
+	BookingID  string `json:"booking_id"`
+	RideStatus string `json:"ride_status"` // "Cancelled"
+	// Reason, Fee and CancelledAt come from the cancellation policy: Reason
+	// is "free", "late_cancellation" or "no_show", and Fee is what the rider
+	// was charged, zero when free. Events published before the policy
+	// existed carry none of them.
+	Reason      string       `json:"reason,omitempty"`
+	Fee         *money.Money `json:"fee,omitempty"`
+	CancelledAt *time.Time   `json:"cancelled_at,omitempty"`
+}
@@ -0,0 +1,18 @@
+package events
+
+import (
+	"time"
+
+	"driver_svc/internal/money"
+)
+
+// BookingCompleted is published by booking_svc once a trip's fare is
+// captured and posted to its ledger. DriverEarnings is what the ledger
+// credited the driver, and is what payouts pay.
+type BookingCompleted struct {
+	BookingID      string      `json:"booking_id"`
+	DriverID       string      `json:"driver_id"`
+	Fare           money.Money `json:"fare"`
+	DriverEarnings money.Money `json:"driver_earnings"`
+	CompletedAt    time.Time   `json:"completed_at"`
+}
@@ -1,11 +1,33 @@
 package events
 
-import "driver_svc/internal/models"
+import (
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+)
 
 type BookingCreated struct {
-	BookingID  string          `json:"booking_id"`
-	PickupLoc  models.Location `json:"pickuploc"`
-	Dropoff    models.Location `json:"dropoff"`
-	Price      int             `json:"price"`
-	RideStatus string          `json:"ride_status"`
+	BookingID string          `json:"booking_id"`
+	PickupLoc models.Location `json:"pickuploc"`
+	Dropoff   models.Location `json:"dropoff"`
+	// Stops are visited in order between PickupLoc and Dropoff.
+	Stops []models.Location `json:"stops,omitempty"`
+	// Price decodes the integer of events published before currencies
+	// existed with an empty Currency; see money.Money.Resolve.
+	Price      money.Money `json:"price"`
+	RideStatus string      `json:"ride_status"`
+	// PromoCode and Discount are set when the rider redeemed a promotion.
+	// Price is still the full price the driver's earnings are based on; the
+	// rider pays Price less Discount.
+	PromoCode string       `json:"promo_code,omitempty"`
+	Discount  *money.Money `json:"discount,omitempty"`
+	// VehicleType, if set, is the only vehicle type that may take the ride.
+	// Passengers is missing from events published before it existed, which
+	// carried one rider.
+	VehicleType models.VehicleType `json:"vehicle_type,omitempty"`
+	Passengers  int                `json:"passengers,omitempty"`
+	// ScheduledFor is the pickup time of a reserved ride, released to
+	// drivers shortly before it.
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
 }
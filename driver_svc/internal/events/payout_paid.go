@@ -0,0 +1,17 @@
+package events
+
+import (
+	"time"
+
+	"driver_svc/internal/money"
+)
+
+// PayoutPaid is published once the payout provider has paid a driver, so
+// booking_svc's ledger can settle what it owes them.
+type PayoutPaid struct {
+	PayoutID    string      `json:"payout_id"`
+	DriverID    string      `json:"driver_id"`
+	Amount      money.Money `json:"amount"`
+	ProviderRef string      `json:"provider_ref"`
+	PaidAt      time.Time   `json:"paid_at"`
+}
@@ -0,0 +1,15 @@
+package events
+
+import "time"
+
+// RatingSubmitted is published by booking_svc when one side of a completed
+// trip rates the other. Rater "rider" rates the driver, RateeID; "driver"
+// rates the rider.
+type RatingSubmitted struct {
+	BookingID   string    `json:"booking_id"`
+	Rater       string    `json:"rater"`
+	RaterID     string    `json:"rater_id"`
+	RateeID     string    `json:"ratee_id"`
+	Stars       int       `json:"stars"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
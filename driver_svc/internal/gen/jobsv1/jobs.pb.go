@@ -0,0 +1,783 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: jobs/v1/jobs.proto
+
+package jobsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type JobStatus int32
+
+const (
+	JobStatus_JOB_STATUS_UNSPECIFIED JobStatus = 0
+	JobStatus_JOB_STATUS_OPEN        JobStatus = 1
+	JobStatus_JOB_STATUS_TAKEN       JobStatus = 2
+	// The rider cancelled the booking; the job can no longer be taken.
+	JobStatus_JOB_STATUS_CANCELLED JobStatus = 3
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0: "JOB_STATUS_UNSPECIFIED",
+		1: "JOB_STATUS_OPEN",
+		2: "JOB_STATUS_TAKEN",
+		3: "JOB_STATUS_CANCELLED",
+	}
+	JobStatus_value = map[string]int32{
+		"JOB_STATUS_UNSPECIFIED": 0,
+		"JOB_STATUS_OPEN":        1,
+		"JOB_STATUS_TAKEN":       2,
+		"JOB_STATUS_CANCELLED":   3,
+	}
+)
+
+func (x JobStatus) Enum() *JobStatus {
+	p := new(JobStatus)
+	*p = x
+	return p
+}
+
+func (x JobStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobs_v1_jobs_proto_enumTypes[0].Descriptor()
+}
+
+func (JobStatus) Type() protoreflect.EnumType {
+	return &file_jobs_v1_jobs_proto_enumTypes[0]
+}
+
+func (x JobStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobStatus.Descriptor instead.
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{0}
+}
+
+type JobEventType int32
+
+const (
+	JobEventType_JOB_EVENT_TYPE_UNSPECIFIED JobEventType = 0
+	JobEventType_JOB_EVENT_TYPE_OPENED      JobEventType = 1
+	JobEventType_JOB_EVENT_TYPE_TAKEN       JobEventType = 2
+	JobEventType_JOB_EVENT_TYPE_CANCELLED   JobEventType = 3
+)
+
+// Enum value maps for JobEventType.
+var (
+	JobEventType_name = map[int32]string{
+		0: "JOB_EVENT_TYPE_UNSPECIFIED",
+		1: "JOB_EVENT_TYPE_OPENED",
+		2: "JOB_EVENT_TYPE_TAKEN",
+		3: "JOB_EVENT_TYPE_CANCELLED",
+	}
+	JobEventType_value = map[string]int32{
+		"JOB_EVENT_TYPE_UNSPECIFIED": 0,
+		"JOB_EVENT_TYPE_OPENED":      1,
+		"JOB_EVENT_TYPE_TAKEN":       2,
+		"JOB_EVENT_TYPE_CANCELLED":   3,
+	}
+)
+
+func (x JobEventType) Enum() *JobEventType {
+	p := new(JobEventType)
+	*p = x
+	return p
+}
+
+func (x JobEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_jobs_v1_jobs_proto_enumTypes[1].Descriptor()
+}
+
+func (JobEventType) Type() protoreflect.EnumType {
+	return &file_jobs_v1_jobs_proto_enumTypes[1]
+}
+
+func (x JobEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobEventType.Descriptor instead.
+func (JobEventType) EnumDescriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{1}
+}
+
+// Money is an amount in minor units (paise, cents) of an ISO 4217 currency.
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng           float64                `protobuf:"fixed64,2,opt,name=lng,proto3" json:"lng,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Location) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Location) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+type Job struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BookingId string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	Pickuploc *Location              `protobuf:"bytes,2,opt,name=pickuploc,proto3" json:"pickuploc,omitempty"`
+	Dropoff   *Location              `protobuf:"bytes,3,opt,name=dropoff,proto3" json:"dropoff,omitempty"`
+	// Deprecated: whole units with no currency, rounded down; use price_money.
+	//
+	// Deprecated: Marked as deprecated in jobs/v1/jobs.proto.
+	Price  int64     `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Status JobStatus `protobuf:"varint,5,opt,name=status,proto3,enum=jobs.v1.JobStatus" json:"status,omitempty"`
+	// Empty while the job is open.
+	AcceptedDriverId string                 `protobuf:"bytes,6,opt,name=accepted_driver_id,json=acceptedDriverId,proto3" json:"accepted_driver_id,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	PriceMoney       *Money                 `protobuf:"bytes,8,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
+	// Set when the rider reserved the ride for this pickup time.
+	ScheduledFor *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=scheduled_for,json=scheduledFor,proto3" json:"scheduled_for,omitempty"`
+	// Intermediate stops, visited in order between pickuploc and dropoff.
+	Stops []*Location `protobuf:"bytes,10,rep,name=stops,proto3" json:"stops,omitempty"`
+	// "bike", "auto", "sedan" or "suv"; empty when any vehicle will do.
+	VehicleType   string `protobuf:"bytes,11,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	Passengers    int32  `protobuf:"varint,12,opt,name=passengers,proto3" json:"passengers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Job) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *Job) GetPickuploc() *Location {
+	if x != nil {
+		return x.Pickuploc
+	}
+	return nil
+}
+
+func (x *Job) GetDropoff() *Location {
+	if x != nil {
+		return x.Dropoff
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in jobs/v1/jobs.proto.
+func (x *Job) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Job) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *Job) GetAcceptedDriverId() string {
+	if x != nil {
+		return x.AcceptedDriverId
+	}
+	return ""
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+func (x *Job) GetScheduledFor() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledFor
+	}
+	return nil
+}
+
+func (x *Job) GetStops() []*Location {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+func (x *Job) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *Job) GetPassengers() int32 {
+	if x != nil {
+		return x.Passengers
+	}
+	return 0
+}
+
+type ListOpenJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOpenJobsRequest) Reset() {
+	*x = ListOpenJobsRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOpenJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOpenJobsRequest) ProtoMessage() {}
+
+func (x *ListOpenJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOpenJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListOpenJobsRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{3}
+}
+
+type ListOpenJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOpenJobsResponse) Reset() {
+	*x = ListOpenJobsResponse{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOpenJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOpenJobsResponse) ProtoMessage() {}
+
+func (x *ListOpenJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOpenJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListOpenJobsResponse) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListOpenJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type AcceptJobRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BookingId string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	// Optional for drivers (must match the token); required for admins.
+	DriverId      string `protobuf:"bytes,2,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptJobRequest) Reset() {
+	*x = AcceptJobRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptJobRequest) ProtoMessage() {}
+
+func (x *AcceptJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptJobRequest.ProtoReflect.Descriptor instead.
+func (*AcceptJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AcceptJobRequest) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *AcceptJobRequest) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+type AcceptJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	DriverId      string                 `protobuf:"bytes,2,opt,name=driver_id,json=driverId,proto3" json:"driver_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptJobResponse) Reset() {
+	*x = AcceptJobResponse{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptJobResponse) ProtoMessage() {}
+
+func (x *AcceptJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptJobResponse.ProtoReflect.Descriptor instead.
+func (*AcceptJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AcceptJobResponse) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *AcceptJobResponse) GetDriverId() string {
+	if x != nil {
+		return x.DriverId
+	}
+	return ""
+}
+
+type WatchJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchJobsRequest) Reset() {
+	*x = WatchJobsRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchJobsRequest) ProtoMessage() {}
+
+func (x *WatchJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchJobsRequest.ProtoReflect.Descriptor instead.
+func (*WatchJobsRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{7}
+}
+
+type WatchJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          JobEventType           `protobuf:"varint,1,opt,name=type,proto3,enum=jobs.v1.JobEventType" json:"type,omitempty"`
+	Job           *Job                   `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchJobsResponse) Reset() {
+	*x = WatchJobsResponse{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchJobsResponse) ProtoMessage() {}
+
+func (x *WatchJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchJobsResponse.ProtoReflect.Descriptor instead.
+func (*WatchJobsResponse) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchJobsResponse) GetType() JobEventType {
+	if x != nil {
+		return x.Type
+	}
+	return JobEventType_JOB_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *WatchJobsResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+var File_jobs_v1_jobs_proto protoreflect.FileDescriptor
+
+const file_jobs_v1_jobs_proto_rawDesc = "" +
+	"\n" +
+	"\x12jobs/v1/jobs.proto\x12\ajobs.v1\x1a\x1fgoogle/protobuf/timestamp.proto\";\n" +
+	"\x05Money\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\".\n" +
+	"\bLocation\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\x02 \x01(\x01R\x03lng\"\x8f\x04\n" +
+	"\x03Job\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\x12/\n" +
+	"\tpickuploc\x18\x02 \x01(\v2\x11.jobs.v1.LocationR\tpickuploc\x12+\n" +
+	"\adropoff\x18\x03 \x01(\v2\x11.jobs.v1.LocationR\adropoff\x12\x18\n" +
+	"\x05price\x18\x04 \x01(\x03B\x02\x18\x01R\x05price\x12*\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x12.jobs.v1.JobStatusR\x06status\x12,\n" +
+	"\x12accepted_driver_id\x18\x06 \x01(\tR\x10acceptedDriverId\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12/\n" +
+	"\vprice_money\x18\b \x01(\v2\x0e.jobs.v1.MoneyR\n" +
+	"priceMoney\x12?\n" +
+	"\rscheduled_for\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\fscheduledFor\x12'\n" +
+	"\x05stops\x18\n" +
+	" \x03(\v2\x11.jobs.v1.LocationR\x05stops\x12!\n" +
+	"\fvehicle_type\x18\v \x01(\tR\vvehicleType\x12\x1e\n" +
+	"\n" +
+	"passengers\x18\f \x01(\x05R\n" +
+	"passengers\"\x15\n" +
+	"\x13ListOpenJobsRequest\"8\n" +
+	"\x14ListOpenJobsResponse\x12 \n" +
+	"\x04jobs\x18\x01 \x03(\v2\f.jobs.v1.JobR\x04jobs\"N\n" +
+	"\x10AcceptJobRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\x12\x1b\n" +
+	"\tdriver_id\x18\x02 \x01(\tR\bdriverId\"O\n" +
+	"\x11AcceptJobResponse\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\x12\x1b\n" +
+	"\tdriver_id\x18\x02 \x01(\tR\bdriverId\"\x12\n" +
+	"\x10WatchJobsRequest\"^\n" +
+	"\x11WatchJobsResponse\x12)\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x15.jobs.v1.JobEventTypeR\x04type\x12\x1e\n" +
+	"\x03job\x18\x02 \x01(\v2\f.jobs.v1.JobR\x03job*l\n" +
+	"\tJobStatus\x12\x1a\n" +
+	"\x16JOB_STATUS_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fJOB_STATUS_OPEN\x10\x01\x12\x14\n" +
+	"\x10JOB_STATUS_TAKEN\x10\x02\x12\x18\n" +
+	"\x14JOB_STATUS_CANCELLED\x10\x03*\x81\x01\n" +
+	"\fJobEventType\x12\x1e\n" +
+	"\x1aJOB_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15JOB_EVENT_TYPE_OPENED\x10\x01\x12\x18\n" +
+	"\x14JOB_EVENT_TYPE_TAKEN\x10\x02\x12\x1c\n" +
+	"\x18JOB_EVENT_TYPE_CANCELLED\x10\x032\xe4\x01\n" +
+	"\vJobsService\x12K\n" +
+	"\fListOpenJobs\x12\x1c.jobs.v1.ListOpenJobsRequest\x1a\x1d.jobs.v1.ListOpenJobsResponse\x12B\n" +
+	"\tAcceptJob\x12\x19.jobs.v1.AcceptJobRequest\x1a\x1a.jobs.v1.AcceptJobResponse\x12D\n" +
+	"\tWatchJobs\x12\x19.jobs.v1.WatchJobsRequest\x1a\x1a.jobs.v1.WatchJobsResponse0\x01B'Z%driver_svc/internal/gen/jobsv1;jobsv1b\x06proto3"
+
+var (
+	file_jobs_v1_jobs_proto_rawDescOnce sync.Once
+	file_jobs_v1_jobs_proto_rawDescData []byte
+)
+
+func file_jobs_v1_jobs_proto_rawDescGZIP() []byte {
+	file_jobs_v1_jobs_proto_rawDescOnce.Do(func() {
+		file_jobs_v1_jobs_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_jobs_v1_jobs_proto_rawDesc), len(file_jobs_v1_jobs_proto_rawDesc)))
+	})
+	return file_jobs_v1_jobs_proto_rawDescData
+}
+
+var file_jobs_v1_jobs_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_jobs_v1_jobs_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_jobs_v1_jobs_proto_goTypes = []any{
+	(JobStatus)(0),                // 0: jobs.v1.JobStatus
+	(JobEventType)(0),             // 1: jobs.v1.JobEventType
+	(*Money)(nil),                 // 2: jobs.v1.Money
+	(*Location)(nil),              // 3: jobs.v1.Location
+	(*Job)(nil),                   // 4: jobs.v1.Job
+	(*ListOpenJobsRequest)(nil),   // 5: jobs.v1.ListOpenJobsRequest
+	(*ListOpenJobsResponse)(nil),  // 6: jobs.v1.ListOpenJobsResponse
+	(*AcceptJobRequest)(nil),      // 7: jobs.v1.AcceptJobRequest
+	(*AcceptJobResponse)(nil),     // 8: jobs.v1.AcceptJobResponse
+	(*WatchJobsRequest)(nil),      // 9: jobs.v1.WatchJobsRequest
+	(*WatchJobsResponse)(nil),     // 10: jobs.v1.WatchJobsResponse
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_jobs_v1_jobs_proto_depIdxs = []int32{
+	3,  // 0: jobs.v1.Job.pickuploc:type_name -> jobs.v1.Location
+	3,  // 1: jobs.v1.Job.dropoff:type_name -> jobs.v1.Location
+	0,  // 2: jobs.v1.Job.status:type_name -> jobs.v1.JobStatus
+	11, // 3: jobs.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: jobs.v1.Job.price_money:type_name -> jobs.v1.Money
+	11, // 5: jobs.v1.Job.scheduled_for:type_name -> google.protobuf.Timestamp
+	3,  // 6: jobs.v1.Job.stops:type_name -> jobs.v1.Location
+	4,  // 7: jobs.v1.ListOpenJobsResponse.jobs:type_name -> jobs.v1.Job
+	1,  // 8: jobs.v1.WatchJobsResponse.type:type_name -> jobs.v1.JobEventType
+	4,  // 9: jobs.v1.WatchJobsResponse.job:type_name -> jobs.v1.Job
+	5,  // 10: jobs.v1.JobsService.ListOpenJobs:input_type -> jobs.v1.ListOpenJobsRequest
+	7,  // 11: jobs.v1.JobsService.AcceptJob:input_type -> jobs.v1.AcceptJobRequest
+	9,  // 12: jobs.v1.JobsService.WatchJobs:input_type -> jobs.v1.WatchJobsRequest
+	6,  // 13: jobs.v1.JobsService.ListOpenJobs:output_type -> jobs.v1.ListOpenJobsResponse
+	8,  // 14: jobs.v1.JobsService.AcceptJob:output_type -> jobs.v1.AcceptJobResponse
+	10, // 15: jobs.v1.JobsService.WatchJobs:output_type -> jobs.v1.WatchJobsResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_jobs_v1_jobs_proto_init() }
+func file_jobs_v1_jobs_proto_init() {
+	if File_jobs_v1_jobs_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_jobs_v1_jobs_proto_rawDesc), len(file_jobs_v1_jobs_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_jobs_v1_jobs_proto_goTypes,
+		DependencyIndexes: file_jobs_v1_jobs_proto_depIdxs,
+		EnumInfos:         file_jobs_v1_jobs_proto_enumTypes,
+		MessageInfos:      file_jobs_v1_jobs_proto_msgTypes,
+	}.Build()
+	File_jobs_v1_jobs_proto = out.File
+	file_jobs_v1_jobs_proto_goTypes = nil
+	file_jobs_v1_jobs_proto_depIdxs = nil
+}
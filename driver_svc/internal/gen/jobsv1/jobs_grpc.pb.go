@@ -0,0 +1,217 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: jobs/v1/jobs.proto
+
+package jobsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	JobsService_ListOpenJobs_FullMethodName = "/jobs.v1.JobsService/ListOpenJobs"
+	JobsService_AcceptJob_FullMethodName    = "/jobs.v1.JobsService/AcceptJob"
+	JobsService_WatchJobs_FullMethodName    = "/jobs.v1.JobsService/WatchJobs"
+)
+
+// JobsServiceClient is the client API for JobsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// JobsService is the gRPC twin of the REST /jobs API. Calls carry the same
+// bearer token as REST in the "authorization" metadata key.
+type JobsServiceClient interface {
+	// ListOpenJobs returns jobs no driver has accepted yet, newest first.
+	ListOpenJobs(ctx context.Context, in *ListOpenJobsRequest, opts ...grpc.CallOption) (*ListOpenJobsResponse, error)
+	// AcceptJob claims a job; the first accept wins and later ones fail with
+	// ABORTED. Drivers accept as themselves; admins must set driver_id.
+	AcceptJob(ctx context.Context, in *AcceptJobRequest, opts ...grpc.CallOption) (*AcceptJobResponse, error)
+	// WatchJobs sends every currently open job as OPENED, then OPENED/TAKEN
+	// events as jobs appear and are accepted, until the client cancels.
+	WatchJobs(ctx context.Context, in *WatchJobsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchJobsResponse], error)
+}
+
+type jobsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJobsServiceClient(cc grpc.ClientConnInterface) JobsServiceClient {
+	return &jobsServiceClient{cc}
+}
+
+func (c *jobsServiceClient) ListOpenJobs(ctx context.Context, in *ListOpenJobsRequest, opts ...grpc.CallOption) (*ListOpenJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOpenJobsResponse)
+	err := c.cc.Invoke(ctx, JobsService_ListOpenJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobsServiceClient) AcceptJob(ctx context.Context, in *AcceptJobRequest, opts ...grpc.CallOption) (*AcceptJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AcceptJobResponse)
+	err := c.cc.Invoke(ctx, JobsService_AcceptJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobsServiceClient) WatchJobs(ctx context.Context, in *WatchJobsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchJobsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &JobsService_ServiceDesc.Streams[0], JobsService_WatchJobs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchJobsRequest, WatchJobsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JobsService_WatchJobsClient = grpc.ServerStreamingClient[WatchJobsResponse]
+
+// JobsServiceServer is the server API for JobsService service.
+// All implementations must embed UnimplementedJobsServiceServer
+// for forward compatibility.
+//
+// JobsService is the gRPC twin of the REST /jobs API. Calls carry the same
+// bearer token as REST in the "authorization" metadata key.
+type JobsServiceServer interface {
+	// ListOpenJobs returns jobs no driver has accepted yet, newest first.
+	ListOpenJobs(context.Context, *ListOpenJobsRequest) (*ListOpenJobsResponse, error)
+	// AcceptJob claims a job; the first accept wins and later ones fail with
+	// ABORTED. Drivers accept as themselves; admins must set driver_id.
+	AcceptJob(context.Context, *AcceptJobRequest) (*AcceptJobResponse, error)
+	// WatchJobs sends every currently open job as OPENED, then OPENED/TAKEN
+	// events as jobs appear and are accepted, until the client cancels.
+	WatchJobs(*WatchJobsRequest, grpc.ServerStreamingServer[WatchJobsResponse]) error
+	mustEmbedUnimplementedJobsServiceServer()
+}
+
+// UnimplementedJobsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedJobsServiceServer struct{}
+
+func (UnimplementedJobsServiceServer) ListOpenJobs(context.Context, *ListOpenJobsRequest) (*ListOpenJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOpenJobs not implemented")
+}
+func (UnimplementedJobsServiceServer) AcceptJob(context.Context, *AcceptJobRequest) (*AcceptJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptJob not implemented")
+}
+func (UnimplementedJobsServiceServer) WatchJobs(*WatchJobsRequest, grpc.ServerStreamingServer[WatchJobsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchJobs not implemented")
+}
+func (UnimplementedJobsServiceServer) mustEmbedUnimplementedJobsServiceServer() {}
+func (UnimplementedJobsServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeJobsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JobsServiceServer will
+// result in compilation errors.
+type UnsafeJobsServiceServer interface {
+	mustEmbedUnimplementedJobsServiceServer()
+}
+
+func RegisterJobsServiceServer(s grpc.ServiceRegistrar, srv JobsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedJobsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&JobsService_ServiceDesc, srv)
+}
+
+func _JobsService_ListOpenJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOpenJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobsServiceServer).ListOpenJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobsService_ListOpenJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobsServiceServer).ListOpenJobs(ctx, req.(*ListOpenJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobsService_AcceptJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobsServiceServer).AcceptJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobsService_AcceptJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobsServiceServer).AcceptJob(ctx, req.(*AcceptJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobsService_WatchJobs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchJobsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobsServiceServer).WatchJobs(m, &grpc.GenericServerStream[WatchJobsRequest, WatchJobsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JobsService_WatchJobsServer = grpc.ServerStreamingServer[WatchJobsResponse]
+
+// JobsService_ServiceDesc is the grpc.ServiceDesc for JobsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JobsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jobs.v1.JobsService",
+	HandlerType: (*JobsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListOpenJobs",
+			Handler:    _JobsService_ListOpenJobs_Handler,
+		},
+		{
+			MethodName: "AcceptJob",
+			Handler:    _JobsService_AcceptJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchJobs",
+			Handler:       _JobsService_WatchJobs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "jobs/v1/jobs.proto",
+}
@@ -0,0 +1,82 @@
+// Package grpcserver hosts the gRPC API on its own port, next to the REST
+// server, with the same bearer-token auth plus standard health checking and
+// reflection.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/config"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+	addr   string
+	logger *slog.Logger
+}
+
+func New(cfg config.Config, logger *slog.Logger, authn *auth.Authenticator) *Server {
+	gs := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(recoverUnary(logger), logUnary(logger), authUnary(authn)),
+		grpc.ChainStreamInterceptor(recoverStream(logger), logStream(logger), authStream(authn)),
+	)
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(gs, hs)
+	reflection.Register(gs)
+
+	return &Server{grpc: gs, health: hs, addr: cfg.GRPCAddr(), logger: logger}
+}
+
+// Registrar is where API services register themselves before Start.
+func (s *Server) Registrar() grpc.ServiceRegistrar { return s.grpc }
+
+// Start marks every registered service SERVING and begins accepting connections.
+func (s *Server) Start() <-chan error {
+	errCh := make(chan error, 1)
+	for name := range s.grpc.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	go func() {
+		defer close(errCh)
+		lis, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		s.logger.Info("grpc server starting", slog.String("addr", s.addr))
+		if err := s.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			errCh <- err
+		}
+	}()
+	return errCh
+}
+
+// Shutdown reports NOT_SERVING, then waits for in-flight calls. Watch streams
+// never finish on their own, so they are cut off when ctx expires.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
@@ -0,0 +1,111 @@
+package handlergrpc
+
+import (
+	"context"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/gen/jobsv1"
+	"driver_svc/internal/models"
+	"driver_svc/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type JobsServer struct {
+	jobsv1.UnimplementedJobsServiceServer
+	svc service.JobsService
+}
+
+func NewJobsServer(svc service.JobsService) *JobsServer {
+	return &JobsServer{svc: svc}
+}
+
+// Register attaches the service to a gRPC server whose interceptors already
+// authenticate callers.
+func (s *JobsServer) Register(r grpc.ServiceRegistrar) {
+	jobsv1.RegisterJobsServiceServer(r, s)
+}
+
+func (s *JobsServer) ListOpenJobs(ctx context.Context, _ *jobsv1.ListOpenJobsRequest) (*jobsv1.ListOpenJobsResponse, error) {
+	p, err := requireRole(ctx, auth.RoleDriver, auth.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.svc.ListOpenJobs(ctx, service.ViewingDriverID(p))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &jobsv1.ListOpenJobsResponse{Jobs: make([]*jobsv1.Job, 0, len(items))}
+	for _, j := range items {
+		out.Jobs = append(out.Jobs, jobToPB(j))
+	}
+	return out, nil
+}
+
+func (s *JobsServer) AcceptJob(ctx context.Context, req *jobsv1.AcceptJobRequest) (*jobsv1.AcceptJobResponse, error) {
+	p, err := requireRole(ctx, auth.RoleDriver, auth.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	driverID, err := service.ActingDriverID(p, req.GetDriverId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := s.svc.AcceptJob(ctx, req.GetBookingId(), driverID); err != nil {
+		return nil, toStatus(err)
+	}
+	return &jobsv1.AcceptJobResponse{BookingId: req.GetBookingId(), DriverId: driverID}, nil
+}
+
+func (s *JobsServer) WatchJobs(_ *jobsv1.WatchJobsRequest, stream grpc.ServerStreamingServer[jobsv1.WatchJobsResponse]) error {
+	ctx := stream.Context()
+	p, err := requireRole(ctx, auth.RoleDriver, auth.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	err = s.svc.WatchJobs(ctx, service.ViewingDriverID(p), func(e service.JobEvent) error {
+		return stream.Send(&jobsv1.WatchJobsResponse{Type: jobEventTypeToPB[e.Type], Job: jobToPB(e.Job)})
+	})
+	return toStatus(err)
+}
+
+var jobStatusToPB = map[models.JobStatus]jobsv1.JobStatus{
+	models.JobStatusOpen:      jobsv1.JobStatus_JOB_STATUS_OPEN,
+	models.JobStatusTaken:     jobsv1.JobStatus_JOB_STATUS_TAKEN,
+	models.JobStatusCancelled: jobsv1.JobStatus_JOB_STATUS_CANCELLED,
+}
+
+var jobEventTypeToPB = map[service.JobEventType]jobsv1.JobEventType{
+	service.JobOpened:    jobsv1.JobEventType_JOB_EVENT_TYPE_OPENED,
+	service.JobTaken:     jobsv1.JobEventType_JOB_EVENT_TYPE_TAKEN,
+	service.JobCancelled: jobsv1.JobEventType_JOB_EVENT_TYPE_CANCELLED,
+}
+
+func locationToPB(l models.Location) *jobsv1.Location {
+	return &jobsv1.Location{Lat: l.Lat, Lng: l.Lng}
+}
+
+func jobToPB(j models.Job) *jobsv1.Job {
+	out := &jobsv1.Job{
+		BookingId:   j.BookingID,
+		Pickuploc:   locationToPB(j.PickupLoc),
+		Dropoff:     locationToPB(j.Dropoff),
+		Price:       j.Price.MajorUnits(),
+		PriceMoney:  &jobsv1.Money{Amount: j.Price.Amount, Currency: j.Price.Currency},
+		Status:      jobStatusToPB[j.Status],
+		CreatedAt:   timestamppb.New(j.CreatedAt),
+		VehicleType: string(j.VehicleType),
+		Passengers:  int32(j.Passengers),
+	}
+	if j.AcceptedDriverID != nil {
+		out.AcceptedDriverId = *j.AcceptedDriverID
+	}
+	if j.ScheduledFor != nil {
+		out.ScheduledFor = timestamppb.New(*j.ScheduledFor)
+	}
+	for _, s := range j.Stops {
+		out.Stops = append(out.Stops, locationToPB(s))
+	}
+	return out
+}
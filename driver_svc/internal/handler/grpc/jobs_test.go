@@ -0,0 +1,172 @@
+package handlergrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/gen/jobsv1"
+	"driver_svc/internal/models"
+	"driver_svc/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeJobsService struct {
+	service.JobsService
+	acceptFn func(ctx context.Context, bookingID, driverID string) error
+	watchFn  func(ctx context.Context, driverID string, send func(service.JobEvent) error) error
+}
+
+func (f *fakeJobsService) AcceptJob(ctx context.Context, bookingID, driverID string) error {
+	return f.acceptFn(ctx, bookingID, driverID)
+}
+func (f *fakeJobsService) WatchJobs(ctx context.Context, driverID string, send func(service.JobEvent) error) error {
+	return f.watchFn(ctx, driverID, send)
+}
+
+var (
+	driver = auth.Principal{Subject: "d-1", Role: auth.RoleDriver, DriverID: "d-1"}
+	rider  = auth.Principal{Subject: "r-1", Role: auth.RoleRider, RiderID: "r-1"}
+	admin  = auth.Principal{Subject: "ops", Role: auth.RoleAdmin}
+)
+
+// principalKey carries the test principal from client metadata to the server,
+// standing in for the JWT interceptor.
+const principalKey = "x-test-role"
+
+var principals = map[string]auth.Principal{"rider": rider, "driver": driver, "admin": admin}
+
+func withPrincipal(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(principalKey); len(v) > 0 {
+		return auth.WithPrincipal(ctx, principals[v[0]])
+	}
+	return ctx
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s principalStream) Context() context.Context { return s.ctx }
+
+// newClient serves svc over an in-memory connection.
+func newClient(t *testing.T, svc service.JobsService) jobsv1.JobsServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			return h(withPrincipal(ctx), req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			return h(srv, principalStream{ss, withPrincipal(ss.Context())})
+		}),
+	)
+	NewJobsServer(svc).Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return jobsv1.NewJobsServiceClient(conn)
+}
+
+func as(t *testing.T, role string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, principalKey, role)
+}
+
+func TestAcceptJob_GRPC(t *testing.T) {
+	cases := []struct {
+		name       string
+		as         string
+		driverID   string
+		err        error
+		want       codes.Code
+		wantReason string
+		wantDriver string
+	}{
+		{"driver accepts as self", "driver", "", nil, codes.OK, "", "d-1"},
+		{"driver as other driver", "driver", "d-2", nil, codes.PermissionDenied, "forbidden", ""},
+		{"admin on behalf", "admin", "d-7", nil, codes.OK, "", "d-7"},
+		{"admin without driver", "admin", "", nil, codes.InvalidArgument, "validation_failed", ""},
+		{"rider forbidden", "rider", "", nil, codes.PermissionDenied, "forbidden", ""},
+		{"already taken", "driver", "", service.ErrJobAlreadyTaken, codes.Aborted, "job_already_taken", "d-1"},
+		{"driver unavailable", "driver", "", service.ErrDriverNotFound, codes.NotFound, "driver_not_found", "d-1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotDriver string
+			client := newClient(t, &fakeJobsService{
+				acceptFn: func(ctx context.Context, bookingID, driverID string) error {
+					gotDriver = driverID
+					return c.err
+				},
+			})
+			_, err := client.AcceptJob(as(t, c.as), &jobsv1.AcceptJobRequest{BookingId: "b-1", DriverId: c.driverID})
+			st := status.Convert(err)
+			if st.Code() != c.want || gotDriver != c.wantDriver {
+				t.Fatalf("want %s as %q, got %v as %q", c.want, c.wantDriver, err, gotDriver)
+			}
+			if c.wantReason == "" {
+				return
+			}
+			for _, d := range st.Details() {
+				if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() == c.wantReason {
+					return
+				}
+			}
+			t.Fatalf("missing ErrorInfo reason %q in %v", c.wantReason, st.Details())
+		})
+	}
+}
+
+func TestWatchJobs_GRPC(t *testing.T) {
+	client := newClient(t, &fakeJobsService{
+		watchFn: func(ctx context.Context, driverID string, send func(service.JobEvent) error) error {
+			if driverID != "d-1" {
+				t.Errorf("watching as %q, want the driver's own jobs", driverID)
+			}
+			if err := send(service.JobEvent{Type: service.JobOpened, Job: models.Job{BookingID: "b-1", Status: models.JobStatusOpen}}); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	if stream, err := client.WatchJobs(as(t, "rider"), &jobsv1.WatchJobsRequest{}); err == nil {
+		if _, err := stream.Recv(); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("rider: want PermissionDenied, got %v", err)
+		}
+	}
+
+	stream, err := client.WatchJobs(as(t, "driver"), &jobsv1.WatchJobsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.GetType() != jobsv1.JobEventType_JOB_EVENT_TYPE_OPENED || msg.GetJob().GetStatus() != jobsv1.JobStatus_JOB_STATUS_OPEN {
+		t.Fatalf("unexpected: %+v", msg)
+	}
+}
@@ -1,16 +1,29 @@
 package handlerhttp
 
 import (
-	"fmt"
+	"driver_svc/internal/auth"
+	"driver_svc/internal/models"
+	"driver_svc/internal/service"
 )
 
+// AcceptJobRequest is optional for drivers, who always accept as themselves;
+// admins accepting on a driver's behalf must name the driver.
 type AcceptJobRequest struct {
-	DriverID string `json:"driver_id"`
+	DriverID string `json:"driver_id,omitempty"`
 }
 
-func (r AcceptJobRequest) Validate() error {
-	if r.DriverID == "" {
-		return fmt.Errorf("driver_id is required")
-	}
-	return nil
+// ActingDriverID resolves which driver is accepting the job for principal p.
+func (r AcceptJobRequest) ActingDriverID(p auth.Principal) (string, error) {
+	return service.ActingDriverID(p, r.DriverID)
+}
+
+// RegisterVehicleRequest is the body of PUT /drivers/{driver_id}/vehicle.
+type RegisterVehicleRequest struct {
+	Type  models.VehicleType `json:"type"`
+	Seats int                `json:"seats"`
+	Plate string             `json:"plate"`
+}
+
+func (r RegisterVehicleRequest) Vehicle() models.Vehicle {
+	return models.Vehicle{Type: r.Type, Seats: r.Seats, Plate: r.Plate}
 }
@@ -1,8 +1,11 @@
 package handlerhttp
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
+	"driver_svc/internal/auth"
 	"driver_svc/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -16,25 +19,55 @@ func NewJobsHandler(svc service.JobsService) *JobsHandler {
 	return &JobsHandler{svc: svc}
 }
 
+// RegisterRoutes attaches endpoints to an authenticated router; only drivers
+// and admins may use the driver-side API.
 func (h *JobsHandler) RegisterRoutes(r chi.Router) {
-	r.Get("/drivers", h.listDrivers)
-	r.Get("/jobs", h.listJobs)
-	r.Post("/jobs/{booking_id}/accept", h.acceptJob)
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin))
+		r.Get("/drivers", h.listDrivers)
+		r.Put("/drivers/{driver_id}/vehicle", h.registerVehicle)
+		r.Get("/jobs", h.listJobs)
+		r.Post("/jobs/{booking_id}/accept", h.acceptJob)
+	})
 }
 
 func (h *JobsHandler) listDrivers(w http.ResponseWriter, r *http.Request) {
 	items, err := h.svc.ListDrivers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to list drivers")
+		writeServiceError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
 }
 
+// registerVehicle lets drivers register their own vehicle and admins any
+// driver's.
+func (h *JobsHandler) registerVehicle(w http.ResponseWriter, r *http.Request) {
+	var req RegisterVehicleRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	p, _ := auth.FromContext(r.Context())
+	driverID, err := service.ActingDriverID(p, chi.URLParam(r, "driver_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	d, err := h.svc.RegisterVehicle(r.Context(), driverID, req.Vehicle())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
 func (h *JobsHandler) listJobs(w http.ResponseWriter, r *http.Request) {
-	items, err := h.svc.ListOpenJobs(r.Context())
+	p, _ := auth.FromContext(r.Context())
+	items, err := h.svc.ListOpenJobs(r.Context(), service.ViewingDriverID(p))
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to list jobs")
+		writeServiceError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, items)
@@ -43,26 +76,19 @@ func (h *JobsHandler) listJobs(w http.ResponseWriter, r *http.Request) {
 func (h *JobsHandler) acceptJob(w http.ResponseWriter, r *http.Request) {
 	bookingID := chi.URLParam(r, "booking_id")
 	var req AcceptJobRequest
-	if err := decodeJSON(r, &req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeInvalidJSON(w, r, err)
 		return
 	}
-	if err := req.Validate(); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	p, _ := auth.FromContext(r.Context())
+	driverID, err := req.ActingDriverID(p)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	err := h.svc.AcceptJob(r.Context(), bookingID, req.DriverID)
-	if err == service.ErrDriverNotFound {
-		writeError(w, http.StatusNotFound, "driver not found or unavailable")
-		return
-	}
-	if err == service.ErrJobAlreadyTaken {
-		writeError(w, http.StatusConflict, "job already taken")
-		return
-	}
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to accept job")
+	if err := h.svc.AcceptJob(r.Context(), bookingID, driverID); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "accepted"})
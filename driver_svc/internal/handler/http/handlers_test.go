@@ -8,8 +8,11 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"driver_svc/internal/auth"
 	"driver_svc/internal/models"
+	"driver_svc/internal/problem"
 	"driver_svc/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -17,34 +20,68 @@ import (
 
 type fakeJobsService struct {
 	listDriversFn  func(ctx context.Context) ([]models.Driver, error)
-	listOpenJobsFn func(ctx context.Context) ([]models.Job, error)
+	registerFn     func(ctx context.Context, driverID string, v models.Vehicle) (models.Driver, error)
+	listOpenJobsFn func(ctx context.Context, driverID string) ([]models.Job, error)
 	acceptFn       func(ctx context.Context, bookingID string, driverID string) error
+	watchFn        func(ctx context.Context, driverID string, send func(service.JobEvent) error) error
+	sinceFn        func(ctx context.Context, since time.Time, limit int) ([]models.Job, error)
+	republishFn    func(ctx context.Context, bookingID string) error
 }
 
 func (f *fakeJobsService) ListDrivers(ctx context.Context) ([]models.Driver, error) {
 	return f.listDriversFn(ctx)
 }
-func (f *fakeJobsService) ListOpenJobs(ctx context.Context) ([]models.Job, error) {
-	return f.listOpenJobsFn(ctx)
+func (f *fakeJobsService) RegisterVehicle(ctx context.Context, driverID string, v models.Vehicle) (models.Driver, error) {
+	return f.registerFn(ctx, driverID, v)
+}
+func (f *fakeJobsService) ListOpenJobs(ctx context.Context, driverID string) ([]models.Job, error) {
+	return f.listOpenJobsFn(ctx, driverID)
 }
 func (f *fakeJobsService) AcceptJob(ctx context.Context, b, d string) error {
 	return f.acceptFn(ctx, b, d)
 }
+func (f *fakeJobsService) WatchJobs(ctx context.Context, driverID string, send func(service.JobEvent) error) error {
+	return f.watchFn(ctx, driverID, send)
+}
+func (f *fakeJobsService) ListJobsSince(ctx context.Context, since time.Time, limit int) ([]models.Job, error) {
+	return f.sinceFn(ctx, since, limit)
+}
+func (f *fakeJobsService) RepublishAccepted(ctx context.Context, b string) error {
+	return f.republishFn(ctx, b)
+}
+
+var (
+	driver = auth.Principal{Subject: "d-1", Role: auth.RoleDriver, DriverID: "d-1"}
+	rider  = auth.Principal{Subject: "r-1", Role: auth.RoleRider, RiderID: "r-1"}
+	admin  = auth.Principal{Subject: "ops", Role: auth.RoleAdmin}
+)
 
 func setup(t *testing.T, svc *fakeJobsService) *chi.Mux {
+	t.Helper()
+	return setupAs(t, driver, svc)
+}
+
+// setupAs registers the handler's routes behind a middleware that authenticates every request as p.
+func setupAs(t *testing.T, p auth.Principal, svc *fakeJobsService) *chi.Mux {
 	t.Helper()
 	h := NewJobsHandler(svc)
 	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(auth.WithPrincipal(req.Context(), p)))
+		})
+	})
 	h.RegisterRoutes(r)
+	NewReconcileHandler(svc).RegisterRoutes(r)
 	return r
 }
 
 func TestListDrivers(t *testing.T) {
 	r := setup(t, &fakeJobsService{
 		listDriversFn: func(ctx context.Context) ([]models.Driver, error) {
-			return []models.Driver{{DriverID: "d-1", Name: "Asha", IsAvailable: true}}, nil
+			return []models.Driver{{DriverID: "d-1", Name: "Asha", IsAvailable: true, Rating: models.DriverRating{Average: 4.67, Count: 3}}}, nil
 		},
-		listOpenJobsFn: func(ctx context.Context) ([]models.Job, error) { return nil, nil },
+		listOpenJobsFn: func(ctx context.Context, driverID string) ([]models.Job, error) { return nil, nil },
 		acceptFn:       func(ctx context.Context, b, d string) error { return nil },
 	})
 	rr := httptest.NewRecorder()
@@ -55,69 +92,175 @@ func TestListDrivers(t *testing.T) {
 	}
 	var got []models.Driver
 	_ = json.Unmarshal(rr.Body.Bytes(), &got)
-	if len(got) != 1 || got[0].DriverID != "d-1" {
+	if len(got) != 1 || got[0].DriverID != "d-1" || got[0].Rating != (models.DriverRating{Average: 4.67, Count: 3}) {
 		t.Fatalf("unexpected: %+v", got)
 	}
 }
 
 func TestListJobs(t *testing.T) {
-	r := setup(t, &fakeJobsService{
-		listDriversFn: func(ctx context.Context) ([]models.Driver, error) { return nil, nil },
-		listOpenJobsFn: func(ctx context.Context) ([]models.Job, error) {
-			return []models.Job{{BookingID: "b-1"}}, nil
-		},
-		acceptFn: func(ctx context.Context, b, d string) error { return nil },
-	})
-	rr := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
-	r.ServeHTTP(rr, req)
-	if rr.Code != http.StatusOK {
-		t.Fatalf("want 200, got %d", rr.Code)
+	// Drivers see only the jobs their vehicle can serve; admins see all.
+	for _, c := range []struct {
+		as         auth.Principal
+		wantDriver string
+	}{{driver, "d-1"}, {admin, ""}} {
+		gotDriver := "unset"
+		r := setupAs(t, c.as, &fakeJobsService{
+			listDriversFn: func(ctx context.Context) ([]models.Driver, error) { return nil, nil },
+			listOpenJobsFn: func(ctx context.Context, driverID string) ([]models.Job, error) {
+				gotDriver = driverID
+				return []models.Job{{BookingID: "b-1"}}, nil
+			},
+			acceptFn: func(ctx context.Context, b, d string) error { return nil },
+		})
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("want 200, got %d", rr.Code)
+		}
+		if gotDriver != c.wantDriver {
+			t.Fatalf("%s listed as %q, want %q", c.as.Role, gotDriver, c.wantDriver)
+		}
+	}
+}
+
+func TestRegisterVehicle_Table(t *testing.T) {
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		path       string
+		body       string
+		err        error
+		wantStatus int
+		wantDriver string
+		wantCode   problem.Code
+	}{
+		{"driver for themselves", driver, "/drivers/d-1/vehicle", `{"type":"sedan","seats":4,"plate":"KA01AB1234"}`, nil, http.StatusOK, "d-1", ""},
+		{"admin for a driver", admin, "/drivers/d-2/vehicle", `{"type":"suv","seats":6,"plate":"KA05XY0001"}`, nil, http.StatusOK, "d-2", ""},
+		{"driver for another driver", driver, "/drivers/d-2/vehicle", `{"type":"sedan","seats":4,"plate":"X1"}`, nil, http.StatusForbidden, "", problem.CodeForbidden},
+		{"rider forbidden", rider, "/drivers/d-1/vehicle", `{"type":"sedan","seats":4,"plate":"X1"}`, nil, http.StatusForbidden, "", problem.CodeForbidden},
+		{"invalid json", driver, "/drivers/d-1/vehicle", `{`, nil, http.StatusBadRequest, "", problem.CodeInvalidJSON},
+		{"plate taken", driver, "/drivers/d-1/vehicle", `{"type":"auto","seats":3,"plate":"X1"}`, service.ErrPlateTaken, http.StatusConflict, "d-1", problem.CodePlateTaken},
+		{"driver not found", admin, "/drivers/x/vehicle", `{"type":"auto","seats":3,"plate":"X1"}`, service.ErrDriverNotFound, http.StatusNotFound, "x", problem.CodeDriverNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotDriver string
+			r := setupAs(t, c.as, &fakeJobsService{
+				registerFn: func(ctx context.Context, driverID string, v models.Vehicle) (models.Driver, error) {
+					gotDriver = driverID
+					return models.Driver{DriverID: driverID, Vehicle: &v}, c.err
+				},
+			})
+			req := httptest.NewRequest(http.MethodPut, c.path, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if gotDriver != c.wantDriver {
+				t.Fatalf("registered for %q, want %q", gotDriver, c.wantDriver)
+			}
+			if c.wantCode != "" {
+				var p problem.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+					t.Fatal(err)
+				}
+				if p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %+v", c.wantCode, p)
+				}
+				return
+			}
+			var got models.Driver
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.DriverID != c.wantDriver || got.Vehicle == nil || got.Vehicle.Plate == "" {
+				t.Fatalf("unexpected driver: %+v", got)
+			}
+		})
 	}
 }
 
 func TestAcceptJob_Table(t *testing.T) {
 	cases := []struct {
 		name       string
+		as         auth.Principal
 		body       string
 		err        error
 		wantStatus int
+		wantDriver string
+		wantCode   problem.Code
 	}{
-		{"ok", `{"driver_id":"d-1"}`, nil, http.StatusOK},
-		{"missing driver_id", `{}`, nil, http.StatusBadRequest},
-		{"invalid json", `{`, nil, http.StatusBadRequest},
-		{"driver not found", `{"driver_id":"x"}`, service.ErrDriverNotFound, http.StatusNotFound},
-		{"already taken", `{"driver_id":"d-2"}`, service.ErrJobAlreadyTaken, http.StatusConflict},
-		{"generic", `{"driver_id":"d-1"}`, context.Canceled, http.StatusInternalServerError},
+		{"ok", driver, `{"driver_id":"d-1"}`, nil, http.StatusOK, "d-1", ""},
+		{"driver without body", driver, ``, nil, http.StatusOK, "d-1", ""},
+		{"driver without driver_id", driver, `{}`, nil, http.StatusOK, "d-1", ""},
+		{"driver for another driver", driver, `{"driver_id":"d-2"}`, nil, http.StatusForbidden, "", problem.CodeForbidden},
+		{"admin on behalf", admin, `{"driver_id":"d-2"}`, nil, http.StatusOK, "d-2", ""},
+		{"admin missing driver_id", admin, `{}`, nil, http.StatusBadRequest, "", problem.CodeValidationFailed},
+		{"rider forbidden", rider, `{"driver_id":"d-1"}`, nil, http.StatusForbidden, "", problem.CodeForbidden},
+		{"invalid json", driver, `{`, nil, http.StatusBadRequest, "", problem.CodeInvalidJSON},
+		{"driver not found", admin, `{"driver_id":"x"}`, service.ErrDriverNotFound, http.StatusNotFound, "x", problem.CodeDriverNotFound},
+		{"already taken", driver, `{}`, service.ErrJobAlreadyTaken, http.StatusConflict, "d-1", problem.CodeJobAlreadyTaken},
+		{"generic", driver, `{"driver_id":"d-1"}`, context.Canceled, http.StatusInternalServerError, "d-1", problem.CodeInternal},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			r := setup(t, &fakeJobsService{
+			var gotDriver string
+			r := setupAs(t, c.as, &fakeJobsService{
 				listDriversFn:  func(ctx context.Context) ([]models.Driver, error) { return nil, nil },
-				listOpenJobsFn: func(ctx context.Context) ([]models.Job, error) { return nil, nil },
-				acceptFn:       func(ctx context.Context, b, d string) error { return c.err },
+				listOpenJobsFn: func(ctx context.Context, driverID string) ([]models.Job, error) { return nil, nil },
+				acceptFn: func(ctx context.Context, b, d string) error {
+					gotDriver = d
+					return c.err
+				},
 			})
-			var body *strings.Reader
-			if c.name == "invalid json" {
-				body = strings.NewReader(c.body)
-			} else {
-				body = strings.NewReader(c.body)
-			}
-			req := httptest.NewRequest(http.MethodPost, "/jobs/b-1/accept", body)
+			req := httptest.NewRequest(http.MethodPost, "/jobs/b-1/accept", strings.NewReader(c.body))
 			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 			r.ServeHTTP(rr, req)
 			if rr.Code != c.wantStatus {
 				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
 			}
+			if gotDriver != c.wantDriver {
+				t.Fatalf("accepted as %q, want %q", gotDriver, c.wantDriver)
+			}
+			if c.wantCode != "" {
+				if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+					t.Fatalf("want %s, got %q", problem.ContentType, ct)
+				}
+				var p problem.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+					t.Fatal(err)
+				}
+				if p.Code != c.wantCode || p.Status != c.wantStatus {
+					t.Fatalf("want code %s, got %+v", c.wantCode, p)
+				}
+			}
 		})
 	}
 }
 
+func TestListJobs_RiderForbidden(t *testing.T) {
+	r := setupAs(t, rider, &fakeJobsService{
+		listDriversFn:  func(ctx context.Context) ([]models.Driver, error) { return nil, nil },
+		listOpenJobsFn: func(ctx context.Context, driverID string) ([]models.Job, error) { return nil, nil },
+		acceptFn:       func(ctx context.Context, b, d string) error { return nil },
+	})
+	for _, path := range []string{"/jobs", "/drivers"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("%s: want 403, got %d", path, rr.Code)
+		}
+	}
+}
+
 func TestAcceptJob_UnknownField(t *testing.T) {
 	r := setup(t, &fakeJobsService{
 		listDriversFn:  func(ctx context.Context) ([]models.Driver, error) { return nil, nil },
-		listOpenJobsFn: func(ctx context.Context) ([]models.Job, error) { return nil, nil },
+		listOpenJobsFn: func(ctx context.Context, driverID string) ([]models.Job, error) { return nil, nil },
 		acceptFn:       func(ctx context.Context, b, d string) error { return nil },
 	})
 	req := httptest.NewRequest(http.MethodPost, "/jobs/b-1/accept", bytes.NewBufferString(`{"driver_id":"d-1","extra":"x"}`))
@@ -0,0 +1,92 @@
+package handlerhttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/problem"
+	"driver_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPayoutPage = 50
+	maxPayoutPage     = 200
+)
+
+// PayoutsHandler serves drivers' payout history and lets admins trigger a
+// payout run outside the schedule.
+type PayoutsHandler struct {
+	svc service.PayoutService
+}
+
+func NewPayoutsHandler(svc service.PayoutService) *PayoutsHandler {
+	return &PayoutsHandler{svc: svc}
+}
+
+// PayoutRunRequest is optional; until defaults to now.
+type PayoutRunRequest struct {
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// RegisterRoutes attaches the payout endpoints. Drivers may only read their
+// own payouts.
+func (h *PayoutsHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleDriver, auth.RoleAdmin))
+		r.Get("/drivers/{driver_id}/payouts", h.listPayouts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleAdmin))
+		r.Post("/payouts/runs", h.runPayouts)
+	})
+}
+
+func (h *PayoutsHandler) listPayouts(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	driverID, err := service.ActingDriverID(p, chi.URLParam(r, "driver_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	limit := defaultPayoutPage
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPayoutPage {
+			problem.Write(w, r, problem.Validation(problem.ValidationError{{Field: "limit", Message: "must be between 1 and 200"}}))
+			return
+		}
+		limit = n
+	}
+
+	items, err := h.svc.ListDriverPayouts(r.Context(), driverID, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *PayoutsHandler) runPayouts(w http.ResponseWriter, r *http.Request) {
+	var req PayoutRunRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeInvalidJSON(w, r, err)
+		return
+	}
+	until := time.Now()
+	if req.Until != nil {
+		until = *req.Until
+	}
+
+	run, err := h.svc.RunPayouts(r.Context(), until)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,121 @@
+package handlerhttp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/openapi"
+	"driver_svc/internal/problem"
+	"driver_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type fakePayoutService struct {
+	driverID string
+	limit    int
+	until    time.Time
+}
+
+func (f *fakePayoutService) RunPayouts(_ context.Context, until time.Time) (service.PayoutRun, error) {
+	f.until = until
+	return service.PayoutRun{Until: until, Created: 2, Deferred: 1, Paid: 1, Retrying: 1}, nil
+}
+
+func (f *fakePayoutService) ListDriverPayouts(_ context.Context, driverID string, limit int) ([]models.Payout, error) {
+	f.driverID, f.limit = driverID, limit
+	if driverID == "d-9" {
+		return nil, service.ErrDriverNotFound
+	}
+	at := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	ref := "po_1"
+	inr := func(n int64) money.Money { return money.Money{Amount: n, Currency: "INR"} }
+	return []models.Payout{{
+		ID: "p-1", DriverID: driverID, Amount: inr(17640), Status: models.PayoutPaid,
+		PeriodStart: at.Add(-20 * time.Hour), PeriodEnd: at,
+		Items:    []models.PayoutItem{{BookingID: "b-1", Fare: inr(22050), Amount: inr(17640)}},
+		Attempts: 1, NextAttemptAt: at, ProviderRef: &ref, CreatedAt: at, PaidAt: &at,
+	}}, nil
+}
+
+func payoutsRouterAs(p auth.Principal, svc service.PayoutService) http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(auth.WithPrincipal(req.Context(), p)))
+		})
+	})
+	NewPayoutsHandler(svc).RegisterRoutes(r)
+	return openapi.MustLoad().Validator(openapi.ResponsesStrict, slog.New(slog.NewTextHandler(io.Discard, nil)))(r)
+}
+
+func TestPayouts_Handler(t *testing.T) {
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   problem.Code
+		wantLimit  int
+	}{
+		{"own payouts", driver, http.MethodGet, "/drivers/d-1/payouts", "", http.StatusOK, "", defaultPayoutPage},
+		{"admin reads any driver", admin, http.MethodGet, "/drivers/d-1/payouts?limit=5", "", http.StatusOK, "", 5},
+		{"another driver's payouts", driver, http.MethodGet, "/drivers/d-2/payouts", "", http.StatusForbidden, problem.CodeForbidden, 0},
+		{"riders may not read payouts", rider, http.MethodGet, "/drivers/d-1/payouts", "", http.StatusForbidden, problem.CodeForbidden, 0},
+		{"limit too large", admin, http.MethodGet, "/drivers/d-1/payouts?limit=201", "", http.StatusBadRequest, problem.CodeValidationFailed, 0},
+		{"unknown driver", admin, http.MethodGet, "/drivers/d-9/payouts", "", http.StatusNotFound, problem.CodeDriverNotFound, defaultPayoutPage},
+		{"run now", admin, http.MethodPost, "/payouts/runs", "", http.StatusOK, "", 0},
+		{"run until", admin, http.MethodPost, "/payouts/runs", `{"until":"2026-03-02T00:00:00Z"}`, http.StatusOK, "", 0},
+		{"drivers may not run payouts", driver, http.MethodPost, "/payouts/runs", "", http.StatusForbidden, problem.CodeForbidden, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := &fakePayoutService{}
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			payoutsRouterAs(c.as, svc).ServeHTTP(rr, req)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if svc.limit != c.wantLimit {
+				t.Fatalf("limit passed to service: %d, want %d", svc.limit, c.wantLimit)
+			}
+			if c.wantCode != "" {
+				var p problem.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %s (%v)", c.wantCode, p.Code, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPayouts_HandlerPassesUntil(t *testing.T) {
+	svc := &fakePayoutService{}
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/payouts/runs", strings.NewReader(`{"until":"2026-03-02T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	payoutsRouterAs(admin, svc).ServeHTTP(rr, req)
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); rr.Code != http.StatusOK || !svc.until.Equal(want) {
+		t.Fatalf("status %d, until %s", rr.Code, svc.until)
+	}
+	var run service.PayoutRun
+	if err := json.Unmarshal(rr.Body.Bytes(), &run); err != nil || run.Created != 2 || run.Retrying != 1 {
+		t.Fatalf("run %+v (%v)", run, err)
+	}
+}
@@ -0,0 +1,72 @@
+package handlerhttp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/problem"
+	"driver_svc/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultReconcilePage = 1000
+	maxReconcilePage     = 5000
+)
+
+// ReconcileHandler serves the internal read and repair API that the
+// reconcile command uses to compare jobs with booking_svc's bookings.
+type ReconcileHandler struct {
+	svc service.JobsService
+}
+
+func NewReconcileHandler(svc service.JobsService) *ReconcileHandler {
+	return &ReconcileHandler{svc: svc}
+}
+
+// RegisterRoutes attaches the admin-only /internal endpoints.
+func (h *ReconcileHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleAdmin))
+		r.Get("/internal/jobs", h.listJobs)
+		r.Post("/internal/jobs/{booking_id}/republish", h.republishAccepted)
+	})
+}
+
+func (h *ReconcileHandler) listJobs(w http.ResponseWriter, r *http.Request) {
+	var errs problem.ValidationError
+	since, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("created_after"))
+	if err != nil {
+		errs = append(errs, problem.FieldError{Field: "created_after", Message: "must be an RFC 3339 timestamp"})
+	}
+	limit := defaultReconcilePage
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxReconcilePage {
+			errs = append(errs, problem.FieldError{Field: "limit", Message: "must be between 1 and 5000"})
+		}
+		limit = n
+	}
+	if len(errs) > 0 {
+		problem.Write(w, r, problem.Validation(errs))
+		return
+	}
+
+	items, err := h.svc.ListJobsSince(r.Context(), since, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (h *ReconcileHandler) republishAccepted(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.RepublishAccepted(r.Context(), chi.URLParam(r, "booking_id")); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "republished"})
+}
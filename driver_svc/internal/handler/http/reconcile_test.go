@@ -0,0 +1,87 @@
+package handlerhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/models"
+	"driver_svc/internal/problem"
+	"driver_svc/internal/service"
+)
+
+func TestListJobsSince_Handler(t *testing.T) {
+	var gotSince time.Time
+	var gotLimit int
+	svc := &fakeJobsService{sinceFn: func(_ context.Context, since time.Time, limit int) ([]models.Job, error) {
+		gotSince, gotLimit = since, limit
+		return []models.Job{{BookingID: "b-1"}}, nil
+	}}
+
+	cases := []struct {
+		name       string
+		as         auth.Principal
+		query      string
+		wantStatus int
+		wantLimit  int
+	}{
+		{"default limit", admin, "?created_after=2025-01-02T03:04:05.123456Z", http.StatusOK, defaultReconcilePage},
+		{"explicit limit", admin, "?created_after=2025-01-02T03:04:05Z&limit=10", http.StatusOK, 10},
+		{"missing created_after", admin, "", http.StatusBadRequest, 0},
+		{"limit too large", admin, "?created_after=2025-01-02T03:04:05Z&limit=5001", http.StatusBadRequest, 0},
+		{"drivers may not reconcile", driver, "?created_after=2025-01-02T03:04:05Z", http.StatusForbidden, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotLimit = 0
+			rr := httptest.NewRecorder()
+			setupAs(t, c.as, svc).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/internal/jobs"+c.query, nil))
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+			if gotLimit != c.wantLimit {
+				t.Fatalf("limit passed to service: %d, want %d", gotLimit, c.wantLimit)
+			}
+		})
+	}
+	if want := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC); !gotSince.Equal(want) {
+		t.Fatalf("since passed to service: %s", gotSince)
+	}
+}
+
+func TestRepublishAccepted_Handler(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   problem.Code
+	}{
+		{"republished", nil, http.StatusOK, ""},
+		{"unknown job", service.ErrJobNotFound, http.StatusNotFound, problem.CodeJobNotFound},
+		{"still open", service.ErrJobNotTaken, http.StatusConflict, problem.CodeJobNotTaken},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotID string
+			svc := &fakeJobsService{republishFn: func(_ context.Context, id string) error {
+				gotID = id
+				return c.err
+			}}
+			rr := httptest.NewRecorder()
+			setupAs(t, admin, svc).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/internal/jobs/b-7/republish", nil))
+			if rr.Code != c.wantStatus || gotID != "b-7" {
+				t.Fatalf("want %d for b-7, got %d for %q, body=%s", c.wantStatus, rr.Code, gotID, rr.Body.String())
+			}
+			if c.wantCode != "" {
+				var p problem.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.Code != c.wantCode {
+					t.Fatalf("want code %s, got %+v (%v)", c.wantCode, p, err)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package handlerhttp
 import (
 	"encoding/json"
 	"net/http"
+
+	"driver_svc/internal/problem"
+	"driver_svc/internal/service"
 )
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -11,8 +14,12 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{"error": msg})
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, service.Problems.Map(err))
+}
+
+func writeInvalidJSON(w http.ResponseWriter, r *http.Request, err error) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, problem.CodeInvalidJSON, err.Error()))
 }
 
 func decodeJSON(r *http.Request, dst any) error {
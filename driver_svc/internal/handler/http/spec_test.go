@@ -0,0 +1,107 @@
+package handlerhttp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/openapi"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestRoutesMatchOpenAPISpec fails when a route is added to or removed from
+// RegisterRoutes without the same change in internal/openapi/openapi.yaml.
+func TestRoutesMatchOpenAPISpec(t *testing.T) {
+	r := setupAs(t, admin, &fakeJobsService{})
+	NewPayoutsHandler(nil).RegisterRoutes(r)
+	var registered []string
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered = append(registered, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var documented []string
+	for path, item := range openapi.MustLoad().Doc().Paths.Map() {
+		for method, op := range item.Operations() {
+			if slices.Contains(op.Tags, openapi.SystemTag) {
+				continue
+			}
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	slices.Sort(registered)
+	slices.Sort(documented)
+	if !slices.Equal(registered, documented) {
+		t.Fatalf("routes drifted from openapi.yaml\nregistered: %v\ndocumented: %v", registered, documented)
+	}
+}
+
+func TestResponsesMatchOpenAPISpec(t *testing.T) {
+	taken := "d-2"
+	svc := &fakeJobsService{
+		listDriversFn: func(ctx context.Context) ([]models.Driver, error) {
+			return []models.Driver{{DriverID: "d-1", Name: "Asha", IsAvailable: true}}, nil
+		},
+		registerFn: func(ctx context.Context, driverID string, v models.Vehicle) (models.Driver, error) {
+			return models.Driver{DriverID: driverID, Name: "Asha", IsAvailable: true, Vehicle: &v}, nil
+		},
+		listOpenJobsFn: func(ctx context.Context, driverID string) ([]models.Job, error) {
+			return []models.Job{{
+				BookingID:        "b-1",
+				PickupLoc:        models.Location{Lat: 12.9, Lng: 77.6},
+				Dropoff:          models.Location{Lat: 12.95, Lng: 77.64},
+				Price:            money.Money{Amount: 22050, Currency: "INR"},
+				Status:           models.JobStatusOpen,
+				AcceptedDriverID: &taken,
+				VehicleType:      models.VehicleSedan,
+				Passengers:       2,
+				CreatedAt:        time.Now().UTC(),
+			}}, nil
+		},
+		acceptFn: func(ctx context.Context, b, d string) error { return nil },
+	}
+	validate := openapi.MustLoad().Validator(openapi.ResponsesStrict, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"drivers", http.MethodGet, "/drivers", "", http.StatusOK},
+		{"jobs", http.MethodGet, "/jobs", "", http.StatusOK},
+		{"accept", http.MethodPost, "/jobs/b-1/accept", `{"driver_id":"d-1"}`, http.StatusOK},
+		{"accept without body", http.MethodPost, "/jobs/b-1/accept", "", http.StatusOK},
+		{"accept for another driver", http.MethodPost, "/jobs/b-1/accept", `{"driver_id":"d-2"}`, http.StatusForbidden},
+		{"register vehicle", http.MethodPut, "/drivers/d-1/vehicle", `{"type":"sedan","seats":4,"plate":"KA01AB1234"}`, http.StatusOK},
+		{"register unknown vehicle type", http.MethodPut, "/drivers/d-1/vehicle", `{"type":"tram","seats":4,"plate":"KA01AB1234"}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := validate(setup(t, svc))
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
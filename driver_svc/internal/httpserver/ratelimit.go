@@ -0,0 +1,201 @@
+package httpserver
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"driver_svc/internal/auth"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/problem"
+
+	"golang.org/x/time/rate"
+)
+
+// RouteClass groups routes that share a rate limit.
+type RouteClass string
+
+const (
+	ClassRead  RouteClass = "read"
+	ClassWrite RouteClass = "write"
+	// ClassUnauthenticated counts requests that fail authentication, per
+	// client IP.
+	ClassUnauthenticated RouteClass = "unauthenticated"
+)
+
+// classify puts safe methods in the read class and everything else in write,
+// so POST /bookings gets the tighter budget.
+func classify(r *http.Request) RouteClass {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ClassRead
+	default:
+		return ClassWrite
+	}
+}
+
+// Limit is a token bucket: RPS tokens per second up to Burst. A zero RPS
+// disables limiting for the class.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// Buckets are swept every sweepInterval, or every second once there are
+// sweepAt of them, so keys that are never seen again do not pile up.
+const (
+	sweepInterval = time.Minute
+	sweepAt       = 100_000
+)
+
+type bucket struct {
+	lim      *rate.Limiter
+	lastSeen time.Time
+	// refill is how long the bucket takes to fill up from empty. Once that
+	// long untouched, dropping it changes nothing for the client.
+	refill time.Duration
+}
+
+// RateLimiter keeps one token bucket per (client, route class).
+type RateLimiter struct {
+	limits map[RouteClass]Limit
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewRateLimiter(limits map[RouteClass]Limit) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// reserve takes a token for key in class and returns how long the caller
+// would have to wait for it; zero means the request may proceed.
+func (l *RateLimiter) reserve(key string, class RouteClass) time.Duration {
+	wait, _ := l.take(key, class)
+	return wait
+}
+
+// take is reserve that also returns a refund, which puts the token back if
+// the request turns out not to count. The refund does nothing when the
+// request was rejected, since no token was taken.
+func (l *RateLimiter) take(key string, class RouteClass) (time.Duration, func()) {
+	lim, ok := l.limits[class]
+	if !ok || lim.RPS <= 0 {
+		return 0, func() {}
+	}
+	now := l.now()
+	id := string(class) + "|" + key
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if since := now.Sub(l.lastSweep); since > sweepInterval || (len(l.buckets) >= sweepAt && since > time.Second) {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) >= b.refill {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[id]
+	if !ok {
+		burst := max(lim.Burst, 1)
+		b = &bucket{
+			lim:    rate.NewLimiter(rate.Limit(lim.RPS), burst),
+			refill: time.Duration(float64(burst) / lim.RPS * float64(time.Second)),
+		}
+		l.buckets[id] = b
+	}
+	b.lastSeen = now
+
+	res := b.lim.ReserveN(now, 1)
+	if delay := res.DelayFrom(now); delay > 0 {
+		// A rejected request must not push the client's next allowed
+		// request further out.
+		res.CancelAt(now)
+		return delay, func() {}
+	}
+	// Cancelling as of the reservation, not the refund, is what returns
+	// the token; a reservation cancelled after it was due is kept.
+	return 0, func() { res.CancelAt(now) }
+}
+
+// Middleware rejects requests over the client's budget with 429 and a
+// Retry-After header. It must run after auth.Middleware so requests are keyed
+// by principal; unauthenticated requests fall back to the client IP.
+func (l *RateLimiter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class := classify(r)
+			if wait := l.reserve(clientKey(r), class); wait > 0 {
+				rejectLimited(w, r, class, wait)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Unauthenticated wraps authenticate, usually auth.Middleware, and throttles
+// requests that fail it by client IP, so a flood of bad tokens is turned away
+// before they are verified. Every request takes a token before its credentials
+// are checked and gets it back once they pass, so concurrent bad tokens
+// cannot all slip in on one look at the bucket. Once an IP has spent its
+// budget, all its requests get 429 until the bucket refills.
+func (l *RateLimiter) Unauthenticated(authenticate func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wait, refund := l.take(ipKey(r), ClassUnauthenticated)
+			if wait > 0 {
+				rejectLimited(w, r, ClassUnauthenticated, wait)
+				return
+			}
+			authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				refund()
+				next.ServeHTTP(w, r)
+			})).ServeHTTP(w, r)
+		})
+	}
+}
+
+func rejectLimited(w http.ResponseWriter, r *http.Request, class RouteClass, wait time.Duration) {
+	metrics.HTTPRateLimited.WithLabelValues(string(class)).Inc()
+	w.Header().Set("Retry-After", retryAfterSeconds(wait))
+	problem.Write(w, r, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited,
+		"too many "+string(class)+" requests"))
+}
+
+// clientKey identifies who a request counts against. RealIP has already
+// rewritten RemoteAddr from X-Forwarded-For where a trusted proxy set it.
+func clientKey(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok && p.Subject != "" {
+		return "sub:" + p.Subject
+	}
+	return ipKey(r)
+}
+
+// ipKey keys IPv6 clients by their /64, since a single host is usually
+// handed the whole prefix and could otherwise rotate through it.
+func ipKey(r *http.Request) string {
+	a, ok := remoteAddr(r)
+	if !ok {
+		return "ip:" + r.RemoteAddr
+	}
+	if a = a.Unmap(); a.Is6() {
+		p, _ := a.Prefix(64)
+		return "ip:" + p.String()
+	}
+	return "ip:" + a.String()
+}
+
+// retryAfterSeconds formats d as whole seconds, rounded up, as Retry-After requires.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseTrustedProxies("10.0.0.0/8,proxy"); err == nil {
+		t.Fatal("want an error for a hostname")
+	}
+	var got string
+	h := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.RemoteAddr }))
+
+	cases := []struct {
+		name, peer, xff, xrip, want string
+	}{
+		{"untrusted peer keeps its address", "203.0.113.9:1111", "198.51.100.1", "", "203.0.113.9:1111"},
+		{"trusted peer forwards the client", "10.1.2.3:1111", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed hops left of the client are ignored", "10.1.2.3:1111", "1.2.3.4, 198.51.100.1, 10.9.9.9", "", "198.51.100.1"},
+		{"trusted single proxy address", "192.168.1.7:1111", "198.51.100.1", "", "198.51.100.1"},
+		{"X-Real-IP without X-Forwarded-For", "10.1.2.3:1111", "", "198.51.100.2", "198.51.100.2"},
+		{"malformed hop stops the walk", "10.1.2.3:1111", "198.51.100.1, junk", "", "10.1.2.3:1111"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.RemoteAddr = c.peer
+			if c.xff != "" {
+				req.Header.Set("X-Forwarded-For", c.xff)
+			}
+			if c.xrip != "" {
+				req.Header.Set("X-Real-IP", c.xrip)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != c.want {
+				t.Fatalf("want %s, got %s", c.want, got)
+			}
+		})
+	}
+}
@@ -5,30 +5,71 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"time"
 
+	"driver_svc/internal/auth"
 	"driver_svc/internal/config"
+	"driver_svc/internal/openapi"
+	"driver_svc/internal/problem"
+	"driver_svc/internal/tracing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Server struct {
 	httpServer *http.Server
 	router     *chi.Mux
+	api        chi.Router
 	logger     *slog.Logger
+	ready      *readiness
+	shedder    *Shedder
+	drainDelay time.Duration
 }
 
-func New(cfg config.Config, logger *slog.Logger) *Server {
+// New builds the HTTP server. Probe, metrics and /openapi.json routes are
+// public and never limited; everything registered through Router() is subject
+// to load shedding, requires a bearer token verified by authn, is rate
+// limited per principal (per client IP while authentication fails) and is
+// validated against the OpenAPI spec. Forwarded client addresses are
+// believed only from the trusted proxies.
+func New(cfg config.Config, logger *slog.Logger, authn *auth.Authenticator, trusted []netip.Prefix) *Server {
 	r := chi.NewRouter()
+	ready := &readiness{}
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(RealIP(trusted))
 	r.Use(middleware.Recoverer)
+	r.Use(tracing.HTTPMiddleware())
 	r.Use(RequestLogger(logger))
-	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
+	r.Use(RequestMetrics())
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, "no route for "+r.URL.Path))
 	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.New(http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, r.Method+" not supported here"))
+	})
+	r.Handle("/metrics", promhttp.Handler())
+	r.Get("/livez", handleLive)
+	r.Get("/healthz", handleLive) // kept for existing probes; same as /livez
+	r.Get("/readyz", ready.handleReady)
+
+	shedder := NewShedder(cfg.ShedMaxInFlight, cfg.ShedMaxPoolWait)
+	limiter := NewRateLimiter(map[RouteClass]Limit{
+		ClassRead:  {RPS: cfg.RateLimitReadRPS, Burst: cfg.RateLimitReadBurst},
+		ClassWrite: {RPS: cfg.RateLimitWriteRPS, Burst: cfg.RateLimitWriteBurst},
+		// Requests failing auth are limited by IP before any token is checked.
+		ClassUnauthenticated: {RPS: cfg.RateLimitUnauthRPS, Burst: cfg.RateLimitUnauthBurst},
+	})
+	spec := openapi.MustLoad()
+	r.Handle("/openapi.json", spec)
+	api := r.With(
+		shedder.Middleware(),
+		limiter.Unauthenticated(auth.Middleware(authn)),
+		limiter.Middleware(),
+		spec.Validator(openapi.ResponseMode(cfg.OpenAPIResponseValidation), logger),
+	)
 
 	srv := &http.Server{
 		Addr:         cfg.Addr(),
@@ -41,11 +82,32 @@ func New(cfg config.Config, logger *slog.Logger) *Server {
 	return &Server{
 		httpServer: srv,
 		router:     r,
+		api:        api,
 		logger:     logger,
+		ready:      ready,
+		shedder:    shedder,
+		drainDelay: cfg.ShutdownDrainDelay,
 	}
 }
 
-func (s *Server) Router() chi.Router { return s.router }
+// AddReadinessCheck registers a dependency probed by /readyz.
+func (s *Server) AddReadinessCheck(name string, fn CheckFunc) {
+	s.ready.add(name, fn)
+}
+
+// ShedOnPoolWait feeds the load shedder a DB pool wait signal, typically
+// (*db.AcquireWait).Current. Call it before Start.
+func (s *Server) ShedOnPoolWait(fn func() time.Duration) {
+	s.shedder.poolWait = fn
+}
+
+// Router exposes the authenticated chi router so callers can register routes.
+func (s *Server) Router() chi.Router { return s.api }
+
+// Handler serves every route, for mounting in a test server instead of Start.
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
 
 func (s *Server) Start() <-chan error {
 	errCh := make(chan error, 1)
@@ -59,7 +121,14 @@ func (s *Server) Start() <-chan error {
 	return errCh
 }
 
+// Shutdown flips /readyz to failing, waits drainDelay so load balancers stop
+// routing here, then drains in-flight requests.
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.logger.Info("http server shutting down")
+	s.ready.draining.Store(true)
+	s.logger.Info("http server shutting down", slog.Duration("drain_delay", s.drainDelay))
+	select {
+	case <-time.After(s.drainDelay):
+	case <-ctx.Done():
+	}
 	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,62 @@
+package httpserver
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/problem"
+)
+
+// shedRetryAfter is advertised to shed clients; overload usually clears
+// within a second or two once new work stops arriving.
+const shedRetryAfter = time.Second
+
+// Shedder rejects requests up front with 503 once the service is saturated,
+// instead of queueing them behind an exhausted DB pool.
+type Shedder struct {
+	maxInFlight int64
+	maxPoolWait time.Duration
+	// poolWait reports recent connection-acquire latency; nil disables that check.
+	poolWait func() time.Duration
+
+	inFlight atomic.Int64
+}
+
+// NewShedder sheds when more than maxInFlight requests are running or the
+// pool wait signal exceeds maxPoolWait. Zero disables a threshold.
+func NewShedder(maxInFlight int, maxPoolWait time.Duration) *Shedder {
+	return &Shedder{maxInFlight: int64(maxInFlight), maxPoolWait: maxPoolWait}
+}
+
+// Middleware applies the shedding policy to the wrapped handler.
+func (s *Shedder) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := s.inFlight.Add(1)
+			defer s.inFlight.Add(-1)
+
+			if reason := s.overloaded(n); reason != "" {
+				metrics.HTTPShed.WithLabelValues(reason).Inc()
+				w.Header().Set("Retry-After", retryAfterSeconds(shedRetryAfter))
+				problem.Write(w, r, problem.New(http.StatusServiceUnavailable, problem.CodeOverloaded, "retry later"))
+				return
+			}
+
+			metrics.HTTPInFlight.Inc()
+			defer metrics.HTTPInFlight.Dec()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *Shedder) overloaded(inFlight int64) string {
+	if s.maxInFlight > 0 && inFlight > s.maxInFlight {
+		return "in_flight"
+	}
+	if s.maxPoolWait > 0 && s.poolWait != nil && s.poolWait() > s.maxPoolWait {
+		return "pool_wait"
+	}
+	return ""
+}
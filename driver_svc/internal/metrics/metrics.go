@@ -0,0 +1,106 @@
+// Package metrics holds the Prometheus collectors exported on /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// factory registers every collector with a constant service label, so both
+// services can share one registry when they run in the same process.
+var factory = promauto.With(prometheus.WrapRegistererWith(
+	prometheus.Labels{"service": "driver_svc"}, prometheus.DefaultRegisterer))
+
+var (
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, chi route pattern and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "API requests currently being served.",
+	})
+
+	HTTPRateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requests rejected with 429 by the per-client rate limiter, by route class.",
+	}, []string{"class"})
+
+	HTTPShed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_shed_total",
+		Help: "Requests rejected with 503 by load shedding, by reason (in_flight, pool_wait).",
+	}, []string{"reason"})
+
+	KafkaProduceDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_produce_duration_seconds",
+		Help:    "Latency of synchronous Kafka produce calls by topic.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"topic"})
+
+	KafkaProduceErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_produce_errors_total",
+		Help: "Kafka produce calls that returned an error, by topic.",
+	}, []string{"topic"})
+
+	ConsumerLag = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_lag",
+		Help: "Messages between the last processed offset and the partition high watermark, by topic.",
+	}, []string{"topic"})
+
+	ConsumerProcessed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_processed_total",
+		Help: "Messages handled and committed, by topic.",
+	}, []string{"topic"})
+
+	ConsumerFailed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_failed_total",
+		Help: "Messages whose handling failed and will be retried, by topic.",
+	}, []string{"topic"})
+
+	ConsumerDeadLettered = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_dlq_total",
+		Help: "Unprocessable messages moved to the dead-letter topic, by source topic.",
+	}, []string{"topic"})
+
+	JobsOpened = factory.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_opened_total",
+		Help: "booking.created events applied as Open jobs.",
+	})
+
+	JobsAccepted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_accepted_total",
+		Help: "Jobs won by a driver via the accept endpoint.",
+	})
+
+	JobsCancelled = factory.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_cancelled_total",
+		Help: "booking.cancelled events that withdrew a job.",
+	})
+
+	DriverRatingsRecorded = factory.NewCounter(prometheus.CounterOpts{
+		Name: "driver_ratings_recorded_total",
+		Help: "Riders' ratings of drivers folded into the drivers' averages.",
+	})
+
+	EarningsRecorded = factory.NewCounter(prometheus.CounterOpts{
+		Name: "earnings_recorded_total",
+		Help: "Completed trips whose driver earnings were recorded for payout.",
+	})
+
+	JobAcceptConflicts = factory.NewCounter(prometheus.CounterOpts{
+		Name: "job_accept_conflicts_total",
+		Help: "Accept attempts that lost the race because the job was already taken.",
+	})
+
+	PayoutsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "payouts_created_total",
+		Help: "Payouts created by payout runs.",
+	})
+
+	PayoutAttempts = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "payout_attempts_total",
+		Help: "Calls to the payout provider, by result (paid, retry, failed, held).",
+	}, []string{"result"})
+)
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"driver_svc/internal/money"
+)
 
 type Location struct {
 	Lat float64 `json:"lat"`
@@ -11,21 +15,81 @@ type Driver struct {
 	DriverID    string `json:"driver_id"`
 	Name        string `json:"name"`
 	IsAvailable bool   `json:"is_available"`
+	// Rating averages the stars riders gave the driver's latest trips.
+	Rating DriverRating `json:"rating"`
+	// Vehicle is nil until the driver registers one.
+	Vehicle *Vehicle `json:"vehicle,omitempty"`
+}
+
+// CanServe reports whether the driver's vehicle fits the job. A driver
+// registered before vehicles were recorded serves only jobs for one
+// passenger that ask for no particular vehicle.
+func (d Driver) CanServe(j Job) bool {
+	passengers := max(j.Passengers, 1)
+	if d.Vehicle == nil {
+		return j.VehicleType == "" && passengers == 1
+	}
+	return (j.VehicleType == "" || j.VehicleType == d.Vehicle.Type) && passengers <= d.Vehicle.Seats
+}
+
+// VehicleType is a class of vehicle a rider can ask for.
+type VehicleType string
+
+const (
+	VehicleBike  VehicleType = "bike"
+	VehicleAuto  VehicleType = "auto"
+	VehicleSedan VehicleType = "sedan"
+	VehicleSUV   VehicleType = "suv"
+)
+
+// VehicleTypes lists every vehicle type, smallest first.
+var VehicleTypes = []VehicleType{VehicleBike, VehicleAuto, VehicleSedan, VehicleSUV}
+
+// VehicleSeats is how many passengers each vehicle type carries at most.
+var VehicleSeats = map[VehicleType]int{
+	VehicleBike:  1,
+	VehicleAuto:  3,
+	VehicleSedan: 4,
+	VehicleSUV:   6,
+}
+
+// Vehicle is what a driver drives. Seats counts passengers, not the driver.
+type Vehicle struct {
+	Type  VehicleType `json:"type"`
+	Seats int         `json:"seats"`
+	Plate string      `json:"plate"`
+}
+
+// DriverRating is a rolling average over a driver's latest ratings, rounded
+// to two decimals. Count is how many ratings it covers; with none, Average
+// is 0.
+type DriverRating struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
 }
 
 type JobStatus string
 
 const (
-	JobStatusOpen  JobStatus = "Open"
-	JobStatusTaken JobStatus = "Taken"
+	JobStatusOpen      JobStatus = "Open"
+	JobStatusTaken     JobStatus = "Taken"
+	JobStatusCancelled JobStatus = "Cancelled"
 )
 
 type Job struct {
-	BookingID        string    `json:"booking_id"`
-	PickupLoc        Location  `json:"pickuploc"`
-	Dropoff          Location  `json:"dropoff"`
-	Price            int       `json:"price"`
-	Status           JobStatus `json:"status"`
-	AcceptedDriverID *string   `json:"accepted_driver_id,omitempty"`
-	CreatedAt        time.Time `json:"created_at"`
+	BookingID string   `json:"booking_id"`
+	PickupLoc Location `json:"pickuploc"`
+	Dropoff   Location `json:"dropoff"`
+	// Stops are visited in order between PickupLoc and Dropoff.
+	Stops            []Location  `json:"stops,omitempty"`
+	Price            money.Money `json:"price"`
+	Status           JobStatus   `json:"status"`
+	AcceptedDriverID *string     `json:"accepted_driver_id,omitempty"`
+	AcceptedAt       *time.Time  `json:"accepted_at,omitempty"`
+	// VehicleType is the vehicle the rider asked for; empty for any.
+	VehicleType VehicleType `json:"vehicle_type,omitempty"`
+	Passengers  int         `json:"passengers"`
+	// ScheduledFor is the pickup time of a reserved ride.
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"driver_svc/internal/money"
+)
+
+type PayoutStatus string
+
+const (
+	// PayoutPending is waiting for its first or next attempt.
+	PayoutPending PayoutStatus = "pending"
+	PayoutPaid    PayoutStatus = "paid"
+	// PayoutFailed was rejected by the provider; its trips are unpaid again
+	// and join the next run.
+	PayoutFailed PayoutStatus = "failed"
+	// PayoutHeld ran out of attempts on errors that may hide a transfer the
+	// provider made. It keeps its trips, so they cannot be paid again, until
+	// someone checks with the provider.
+	PayoutHeld PayoutStatus = "held"
+)
+
+// PayoutItem is one completed trip a payout covers.
+type PayoutItem struct {
+	BookingID string `json:"booking_id"`
+	// Fare is the trip's final fare before any discount; Amount is what
+	// booking_svc's ledger credited the driver for it.
+	Fare   money.Money `json:"fare"`
+	Amount money.Money `json:"amount"`
+}
+
+// Payout pays a driver their earnings from the trips they completed in a
+// period.
+type Payout struct {
+	ID       string       `json:"id"`
+	DriverID string       `json:"driver_id"`
+	Amount   money.Money  `json:"amount"`
+	Status   PayoutStatus `json:"status"`
+	// PeriodStart is when the earliest trip covered was completed; every
+	// trip covered was completed before PeriodEnd.
+	PeriodStart   time.Time    `json:"period_start"`
+	PeriodEnd     time.Time    `json:"period_end"`
+	Items         []PayoutItem `json:"items"`
+	Attempts      int          `json:"attempts"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	// ProviderRef is the payout provider's id for the transfer, once paid.
+	ProviderRef *string    `json:"provider_ref,omitempty"`
+	LastError   *string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+}
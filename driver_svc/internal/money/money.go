@@ -0,0 +1,250 @@
+// Package money represents prices as an integer amount of a currency's minor
+// unit (cents, paise, yen) plus its ISO 4217 code, so arithmetic is exact and
+// amounts in different currencies can never be combined by accident.
+//
+// Rounding rules: Mul rounds half away from zero; Allocate never creates or
+// loses a minor unit, handing the remainder out one unit at a time from the
+// first share.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrOverflow         = errors.New("money: amount out of range")
+)
+
+// exponents maps each supported ISO 4217 code to the number of decimal
+// places of its minor unit.
+var exponents = map[string]int{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"EUR": 2, "GBP": 2, "HKD": 2, "IDR": 2, "INR": 2, "JPY": 0, "KRW": 0,
+	"KWD": 3, "MXN": 2, "MYR": 2, "NZD": 2, "OMR": 3, "PHP": 2, "SAR": 2,
+	"SGD": 2, "THB": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// ValidCurrency reports whether code is a supported ISO 4217 code.
+func ValidCurrency(code string) bool {
+	_, ok := exponents[code]
+	return ok
+}
+
+// Exponent returns the number of minor-unit decimal places of a supported
+// currency, or -1.
+func Exponent(code string) int {
+	if e, ok := exponents[code]; ok {
+		return e
+	}
+	return -1
+}
+
+// Money is an amount in minor units of Currency. The zero value has no
+// currency; see Resolve.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency string) (Money, error) {
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// FromMajor returns units whole units (rupees, dollars) of currency.
+func FromMajor(units int64, currency string) (Money, error) {
+	m, err := New(0, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	scale := int64(math.Pow10(exponents[currency]))
+	if units > math.MaxInt64/scale || units < math.MinInt64/scale {
+		return Money{}, ErrOverflow
+	}
+	m.Amount = units * scale
+	return m, nil
+}
+
+// Resolve gives a currency to an amount decoded from the legacy integer
+// price, which counted whole units of an unnamed currency: the amount is read
+// as whole units of def. Money that already has a currency is returned as is.
+func (m Money) Resolve(def string) (Money, error) {
+	if m.Currency != "" {
+		return m, nil
+	}
+	return FromMajor(m.Amount, def)
+}
+
+// Validate checks that m names a supported currency.
+func (m Money) Validate() error {
+	if !ValidCurrency(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, m.Currency)
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+func (m Money) Neg() Money { return Money{Amount: -m.Amount, Currency: m.Currency} }
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(o.Neg())
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Mul returns m * num / den rounded half away from zero, e.g. Mul(15, 100)
+// for 15%.
+func (m Money) Mul(num, den int64) (Money, error) {
+	if den == 0 {
+		return Money{}, errors.New("money: division by zero")
+	}
+	n := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		n.Neg(n)
+		d.Neg(d)
+	}
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	// |r| * 2 >= d rounds away from zero.
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(d) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: q.Int64(), Currency: m.Currency}, nil
+}
+
+// Allocate splits m in proportion to weights. The shares always sum to m;
+// leftover minor units go one each to the first shares.
+func (m Money) Allocate(weights ...int64) ([]Money, error) {
+	var total int64
+	for _, w := range weights {
+		if w < 0 {
+			return nil, errors.New("money: negative weight")
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, errors.New("money: weights sum to zero")
+	}
+	out := make([]Money, len(weights))
+	rest := m.Amount
+	for i, w := range weights {
+		share := new(big.Int).Quo(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(w)), big.NewInt(total))
+		out[i] = Money{Amount: share.Int64(), Currency: m.Currency}
+		rest -= out[i].Amount
+	}
+	step := int64(1)
+	if rest < 0 {
+		step = -1
+	}
+	for i := 0; rest != 0; i = (i + 1) % len(out) {
+		if weights[i] == 0 {
+			continue
+		}
+		out[i].Amount += step
+		rest -= step
+	}
+	return out, nil
+}
+
+// MajorUnits returns m in whole units, rounded toward zero, for fields that
+// predate minor units.
+func (m Money) MajorUnits() int64 {
+	if exp := Exponent(m.Currency); exp > 0 {
+		return m.Amount / int64(math.Pow10(exp))
+	}
+	return m.Amount
+}
+
+// String formats m in major units, e.g. "220.50 INR".
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	if exp <= 0 {
+		return strings.TrimSpace(strconv.FormatInt(m.Amount, 10) + " " + m.Currency)
+	}
+	sign, abs := "", uint64(m.Amount)
+	if m.Amount < 0 {
+		sign, abs = "-", uint64(-m.Amount)
+	}
+	scale := uint64(math.Pow10(exp))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, abs/scale, exp, abs%scale, m.Currency)
+}
+
+// UnmarshalJSON accepts {"amount":..,"currency":".."} and, for clients and
+// events that predate currencies, a bare integer of whole units that leaves
+// Currency empty until Resolve. Objects need a currency unless they are the
+// zero value.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] != '{' && !bytes.Equal(t, []byte("null")) {
+		var units int64
+		if err := json.Unmarshal(t, &units); err != nil {
+			return fmt.Errorf("money: want an object or an integer: %w", err)
+		}
+		*m = Money{Amount: units}
+		return nil
+	}
+	type plain Money
+	var v plain
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Currency == "" && v.Amount != 0 {
+		return errors.New("money: currency is required")
+	}
+	*m = Money(v)
+	return nil
+}
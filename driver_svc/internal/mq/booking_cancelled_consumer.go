@@ -0,0 +1,115 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type BookingCancelledConsumer struct {
+	sub    bus.Subscriber
+	dlq    *deadLetterWriter
+	jobs   repository.JobRepository
+	waker  Waker
+	logger *slog.Logger
+	health loopHealth
+}
+
+// NewBookingCancelledConsumer joins the cancels consumer group on b. Messages
+// are committed only after the job is withdrawn.
+func NewBookingCancelledConsumer(cfg config.Config, b bus.Bus, jobs repository.JobRepository, waker Waker, logger *slog.Logger) *BookingCancelledConsumer {
+	return &BookingCancelledConsumer{
+		sub:    b.Subscribe(cfg.TopicBookingCancelled, cfg.ConsumerGroupCancels),
+		dlq:    newDeadLetterWriter(cfg, b),
+		jobs:   jobs,
+		waker:  waker,
+		logger: logger,
+	}
+}
+
+func (c *BookingCancelledConsumer) Run(ctx context.Context) error {
+	c.health.start()
+	defer c.health.stop()
+	for {
+		msg, err := c.sub.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			c.health.fetchFailed()
+			c.logger.Error("bus fetch failed", slog.String("err", err.Error()))
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		c.health.fetched()
+		observeLag(msg)
+		c.handle(ctx, msg)
+	}
+}
+
+func (c *BookingCancelledConsumer) handle(ctx context.Context, msg bus.Message) {
+	ctx, span := tracing.StartConsume(ctx, msg)
+	defer span.End()
+
+	var evt events.BookingCancelled
+	err := json.Unmarshal(msg.Value, &evt)
+	if err == nil && evt.BookingID == "" {
+		err = errors.New("booking_id is required")
+	}
+	if err != nil {
+		c.logger.Error("invalid booking.cancelled payload", slog.String("err", err.Error()))
+		span.RecordError(err)
+		if err := c.dlq.send(ctx, msg, err.Error()); err != nil {
+			c.logger.Error("dead-letter failed", slog.String("err", err.Error()))
+			return
+		}
+		_ = c.sub.Commit(ctx, msg) // skip poison message
+		return
+	}
+	span.SetAttributes(attribute.String("booking_id", evt.BookingID))
+
+	cancelled, err := c.jobs.Cancel(ctx, evt.BookingID)
+	if err != nil {
+		c.logger.Error("cancel job failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+		// no commit -> retry later
+		return
+	}
+	if cancelled {
+		metrics.JobsCancelled.Inc()
+		c.waker.Broadcast()
+	} else {
+		// Either a redelivery, or booking.created has not arrived yet; the
+		// latter leaves an Open job that reconciliation reports and heals.
+		c.logger.Info("booking.cancelled changed no job", slog.String("booking_id", evt.BookingID))
+	}
+
+	if err := c.sub.Commit(ctx, msg); err != nil {
+		c.logger.Error("commit failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.ConsumerProcessed.WithLabelValues(msg.Topic).Inc()
+}
+
+// Healthy is a readiness check for the consume loop.
+func (c *BookingCancelledConsumer) Healthy(ctx context.Context) error { return c.health.check(ctx) }
+
+func (c *BookingCancelledConsumer) Close() error {
+	return c.sub.Close()
+}
@@ -0,0 +1,52 @@
+package mq
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+)
+
+func TestBookingCancelledConsumer_MemoryBus(t *testing.T) {
+	cfg := config.Config{TopicBookingCancelled: "booking.cancelled", ConsumerGroupCancels: "cancels", TopicDLQSuffix: ".dlq"}
+	b := bus.NewMemory()
+	defer b.Close()
+	repo := &fakeJobRepo{cancelled: make(chan string, 4)}
+	woken := make(chan struct{}, 4)
+	c := NewBookingCancelledConsumer(cfg, b, repo, wakerFunc(func() { woken <- struct{}{} }),
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	missingID := []byte(`{"ride_status":"Cancelled"}`)
+	for _, value := range [][]byte{[]byte("{"), missingID, []byte(`{"booking_id":"b-1","ride_status":"Cancelled"}`)} {
+		if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicBookingCancelled, Key: []byte("b-1"), Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dlq := b.Subscribe(cfg.TopicBookingCancelled+cfg.TopicDLQSuffix, "inspect")
+	for _, want := range [][]byte{[]byte("{"), missingID} {
+		parked, err := dlq.Fetch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(parked.Value) != string(want) {
+			t.Fatalf("unexpected dead letter: %q", parked.Value)
+		}
+	}
+	if id := <-repo.cancelled; id != "b-1" {
+		t.Fatalf("unexpected cancel: %q", id)
+	}
+	select {
+	case <-woken:
+	case <-ctx.Done():
+		t.Fatal("watchers were not woken")
+	}
+}
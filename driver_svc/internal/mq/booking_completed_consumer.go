@@ -0,0 +1,135 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BookingCompletedConsumer records the earnings booking_svc's ledger credited
+// drivers for completed trips, so payouts pay exactly those amounts.
+type BookingCompletedConsumer struct {
+	sub     bus.Subscriber
+	dlq     *deadLetterWriter
+	payouts repository.PayoutRepository
+	logger  *slog.Logger
+	health  loopHealth
+}
+
+// NewBookingCompletedConsumer joins the earnings consumer group on b.
+// Messages are committed only after the earning is stored.
+func NewBookingCompletedConsumer(cfg config.Config, b bus.Bus, payouts repository.PayoutRepository, logger *slog.Logger) *BookingCompletedConsumer {
+	return &BookingCompletedConsumer{
+		sub:     b.Subscribe(cfg.TopicBookingCompleted, cfg.ConsumerGroupEarnings),
+		dlq:     newDeadLetterWriter(cfg, b),
+		payouts: payouts,
+		logger:  logger,
+	}
+}
+
+func (c *BookingCompletedConsumer) Run(ctx context.Context) error {
+	c.health.start()
+	defer c.health.stop()
+	for {
+		msg, err := c.sub.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			c.health.fetchFailed()
+			c.logger.Error("bus fetch failed", slog.String("err", err.Error()))
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		c.health.fetched()
+		observeLag(msg)
+		c.handle(ctx, msg)
+	}
+}
+
+func validateCompleted(evt events.BookingCompleted) error {
+	switch {
+	case evt.BookingID == "":
+		return errors.New("booking_id is required")
+	case evt.DriverID == "":
+		return errors.New("driver_id is required")
+	case evt.DriverEarnings.Amount < 0:
+		return errors.New("driver_earnings must not be negative")
+	case evt.Fare.Currency != evt.DriverEarnings.Currency:
+		return errors.New("fare and driver_earnings must share a currency")
+	case evt.CompletedAt.IsZero():
+		return errors.New("completed_at is required")
+	}
+	return nil
+}
+
+func (c *BookingCompletedConsumer) handle(ctx context.Context, msg bus.Message) {
+	ctx, span := tracing.StartConsume(ctx, msg)
+	defer span.End()
+
+	var evt events.BookingCompleted
+	err := json.Unmarshal(msg.Value, &evt)
+	if err == nil {
+		err = validateCompleted(evt)
+	}
+	if err != nil {
+		c.logger.Error("invalid booking.completed payload", slog.String("err", err.Error()))
+		span.RecordError(err)
+		if err := c.dlq.send(ctx, msg, err.Error()); err != nil {
+			c.logger.Error("dead-letter failed", slog.String("err", err.Error()))
+			return
+		}
+		_ = c.sub.Commit(ctx, msg) // skip poison message
+		return
+	}
+	span.SetAttributes(attribute.String("booking_id", evt.BookingID))
+
+	recorded, err := c.payouts.RecordEarning(ctx, repository.Earning{
+		BookingID:   evt.BookingID,
+		DriverID:    evt.DriverID,
+		Fare:        evt.Fare,
+		Amount:      evt.DriverEarnings,
+		CompletedAt: evt.CompletedAt,
+	})
+	if err != nil {
+		c.logger.Error("record earning failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+		// no commit -> retry later
+		return
+	}
+	if recorded {
+		metrics.EarningsRecorded.Inc()
+	} else {
+		// redelivered, or the job was never taken by this driver here
+		c.logger.Warn("earning not recorded", slog.String("booking_id", evt.BookingID), slog.String("driver_id", evt.DriverID))
+	}
+
+	if err := c.sub.Commit(ctx, msg); err != nil {
+		c.logger.Error("commit failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.ConsumerProcessed.WithLabelValues(msg.Topic).Inc()
+}
+
+// Healthy is a readiness check for the consume loop.
+func (c *BookingCompletedConsumer) Healthy(ctx context.Context) error { return c.health.check(ctx) }
+
+func (c *BookingCompletedConsumer) Close() error {
+	return c.sub.Close()
+}
@@ -0,0 +1,87 @@
+package mq
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/money"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/repository/memory"
+)
+
+func TestBookingCompletedConsumer_MemoryBus(t *testing.T) {
+	cfg := config.Config{TopicBookingCompleted: "booking.completed", ConsumerGroupEarnings: "earnings", TopicDLQSuffix: ".dlq"}
+	b := bus.NewMemory()
+	defer b.Close()
+	jobs := memory.NewJobRepo()
+	payouts := memory.NewPayoutRepo(jobs)
+	c := NewBookingCompletedConsumer(cfg, b, payouts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, id := range []string{"b-1", "b-2"} {
+		if err := jobs.UpsertOpenJob(ctx, repository.UpsertJobParams{BookingID: id, Price: money.Money{Amount: 18000, Currency: "INR"}}); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := jobs.TryAccept(ctx, id, "d-1"); !ok || err != nil {
+			t.Fatalf("TryAccept(%s): ok=%v err=%v", id, ok, err)
+		}
+	}
+	go func() { _ = c.Run(ctx) }()
+
+	noDriver := []byte(`{"booking_id":"b-9","fare":{"amount":18000,"currency":"INR"},"driver_earnings":{"amount":14400,"currency":"INR"},"completed_at":"2026-03-01T09:00:00Z"}`)
+	for _, value := range [][]byte{
+		[]byte("{"),
+		noDriver,
+		[]byte(`{"booking_id":"b-1","driver_id":"d-1","fare":{"amount":18000,"currency":"INR"},"driver_earnings":{"amount":14400,"currency":"INR"},"completed_at":"2026-03-01T09:00:00Z"}`),
+		// A redelivery and a trip the driver never took here change nothing.
+		[]byte(`{"booking_id":"b-1","driver_id":"d-1","fare":{"amount":18000,"currency":"INR"},"driver_earnings":{"amount":14400,"currency":"INR"},"completed_at":"2026-03-01T09:00:00Z"}`),
+		[]byte(`{"booking_id":"b-2","driver_id":"d-2","fare":{"amount":9000,"currency":"INR"},"driver_earnings":{"amount":7200,"currency":"INR"},"completed_at":"2026-03-01T09:30:00Z"}`),
+		[]byte(`{"booking_id":"b-2","driver_id":"d-1","fare":{"amount":9000,"currency":"INR"},"driver_earnings":{"amount":7200,"currency":"INR"},"completed_at":"2026-03-01T10:00:00Z"}`),
+	} {
+		if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicBookingCompleted, Key: []byte("b-1"), Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dlq := b.Subscribe(cfg.TopicBookingCompleted+cfg.TopicDLQSuffix, "inspect")
+	for _, want := range [][]byte{[]byte("{"), noDriver} {
+		parked, err := dlq.Fetch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(parked.Value) != string(want) {
+			t.Fatalf("unexpected dead letter: %q", parked.Value)
+		}
+	}
+	until := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	for {
+		es, err := payouts.ListUnpaidEarnings(ctx, until)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(es) == 2 {
+			var total int64
+			for _, e := range es {
+				if e.DriverID != "d-1" {
+					t.Fatalf("earning for %s recorded to %s", e.BookingID, e.DriverID)
+				}
+				total += e.Amount.Amount
+			}
+			if total != 21600 {
+				t.Fatalf("earnings total %d, want 21600", total)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("earnings not recorded: %+v", es)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
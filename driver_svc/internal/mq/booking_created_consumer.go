@@ -3,71 +3,131 @@ package mq
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
+	"driver_svc/internal/bus"
 	"driver_svc/internal/config"
 	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/models"
 	"driver_svc/internal/repository"
+	"driver_svc/internal/tracing"
 
-	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// Waker is told after a job is opened locally so watchers re-read jobs.
+type Waker interface {
+	Broadcast()
+}
+
 type BookingCreatedConsumer struct {
-	reader *kafka.Reader
-	jobs   repository.JobRepository
-	logger *slog.Logger
+	sub   bus.Subscriber
+	dlq   *deadLetterWriter
+	jobs  repository.JobRepository
+	waker Waker
+	// currency prices events published before prices had one.
+	currency string
+	logger   *slog.Logger
+	health   loopHealth
 }
 
-func NewBookingCreatedConsumer(cfg config.Config, jobs repository.JobRepository, logger *slog.Logger) *BookingCreatedConsumer {
-	brokers := strings.Split(cfg.KafkaBrokers, ",")
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        brokers,
-		GroupID:        cfg.ConsumerGroupJobs,
-		Topic:          cfg.TopicBookingCreated,
-		MinBytes:       1,
-		MaxBytes:       10e6,
-		StartOffset:    kafka.FirstOffset,
-		CommitInterval: 0, // manual commit after DB success
-	})
-	return &BookingCreatedConsumer{reader: r, jobs: jobs, logger: logger}
+// NewBookingCreatedConsumer joins the jobs consumer group on b. Messages are
+// committed only after the job is stored.
+func NewBookingCreatedConsumer(cfg config.Config, b bus.Bus, jobs repository.JobRepository, waker Waker, logger *slog.Logger) *BookingCreatedConsumer {
+	return &BookingCreatedConsumer{
+		sub:      b.Subscribe(cfg.TopicBookingCreated, cfg.ConsumerGroupJobs),
+		dlq:      newDeadLetterWriter(cfg, b),
+		jobs:     jobs,
+		waker:    waker,
+		currency: cfg.DefaultCurrency,
+		logger:   logger,
+	}
 }
 
 func (c *BookingCreatedConsumer) Run(ctx context.Context) error {
+	c.health.start()
+	defer c.health.stop()
 	for {
-		msg, err := c.reader.FetchMessage(ctx)
+		msg, err := c.sub.Fetch(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-			c.logger.Error("kafka fetch failed", slog.String("err", err.Error()))
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			c.health.fetchFailed()
+			c.logger.Error("bus fetch failed", slog.String("err", err.Error()))
 			time.Sleep(500 * time.Millisecond)
 			continue
 		}
+		c.health.fetched()
+		observeLag(msg)
+		c.handle(ctx, msg)
+	}
+}
 
-		var evt events.BookingCreated
-		if err := json.Unmarshal(msg.Value, &evt); err != nil {
-			c.logger.Error("invalid booking.created payload", slog.String("err", err.Error()))
-			_ = c.reader.CommitMessages(ctx, msg) // skip poison message
-			continue
-		}
+func (c *BookingCreatedConsumer) handle(ctx context.Context, msg bus.Message) {
+	ctx, span := tracing.StartConsume(ctx, msg)
+	defer span.End()
 
-		if err := c.jobs.UpsertOpenJob(ctx, repository.UpsertJobParams{
-			BookingID: evt.BookingID,
-			PickupLoc: evt.PickupLoc,
-			Dropoff:   evt.Dropoff,
-			Price:     evt.Price,
-		}); err != nil {
-			c.logger.Error("upsert job failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
-			// no commit -> retry later
-			continue
+	var evt events.BookingCreated
+	err := json.Unmarshal(msg.Value, &evt)
+	if err == nil {
+		evt.Price, err = evt.Price.Resolve(c.currency)
+	}
+	if err == nil {
+		err = evt.Price.Validate()
+	}
+	if _, known := models.VehicleSeats[evt.VehicleType]; err == nil && evt.VehicleType != "" && !known {
+		err = fmt.Errorf("unknown vehicle_type %q", evt.VehicleType)
+	}
+	if err != nil {
+		c.logger.Error("invalid booking.created payload", slog.String("err", err.Error()))
+		span.RecordError(err)
+		if err := c.dlq.send(ctx, msg, err.Error()); err != nil {
+			c.logger.Error("dead-letter failed", slog.String("err", err.Error()))
+			return
 		}
+		_ = c.sub.Commit(ctx, msg) // skip poison message
+		return
+	}
+	span.SetAttributes(attribute.String("booking_id", evt.BookingID))
 
-		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			c.logger.Error("commit failed", slog.String("err", err.Error()))
-		}
+	if err := c.jobs.UpsertOpenJob(ctx, repository.UpsertJobParams{
+		BookingID:    evt.BookingID,
+		PickupLoc:    evt.PickupLoc,
+		Dropoff:      evt.Dropoff,
+		Stops:        evt.Stops,
+		Price:        evt.Price,
+		VehicleType:  evt.VehicleType,
+		Passengers:   evt.Passengers,
+		ScheduledFor: evt.ScheduledFor,
+	}); err != nil {
+		c.logger.Error("upsert job failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+		// no commit -> retry later
+		return
 	}
+	metrics.JobsOpened.Inc()
+	c.waker.Broadcast()
+
+	if err := c.sub.Commit(ctx, msg); err != nil {
+		c.logger.Error("commit failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.ConsumerProcessed.WithLabelValues(msg.Topic).Inc()
 }
 
-func (c *BookingCreatedConsumer) Close() error { return c.reader.Close() }
+// Healthy is a readiness check for the consume loop.
+func (c *BookingCreatedConsumer) Healthy(ctx context.Context) error { return c.health.check(ctx) }
+
+func (c *BookingCreatedConsumer) Close() error {
+	return c.sub.Close()
+}
@@ -0,0 +1,87 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/events"
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/repository"
+)
+
+type fakeJobRepo struct {
+	repository.JobRepository
+	upserted  chan repository.UpsertJobParams
+	cancelled chan string
+}
+
+func (f *fakeJobRepo) UpsertOpenJob(_ context.Context, p repository.UpsertJobParams) error {
+	f.upserted <- p
+	return nil
+}
+
+func (f *fakeJobRepo) Cancel(_ context.Context, bookingID string) (bool, error) {
+	f.cancelled <- bookingID
+	return true, nil
+}
+
+type wakerFunc func()
+
+func (f wakerFunc) Broadcast() { f() }
+
+func TestBookingCreatedConsumer_MemoryBus(t *testing.T) {
+	cfg := config.Config{TopicBookingCreated: "booking.created", ConsumerGroupJobs: "jobs", TopicDLQSuffix: ".dlq", DefaultCurrency: "INR"}
+	b := bus.NewMemory()
+	defer b.Close()
+	repo := &fakeJobRepo{upserted: make(chan repository.UpsertJobParams, 4)}
+	woken := make(chan struct{}, 4)
+	c := NewBookingCreatedConsumer(cfg, b, repo, wakerFunc(func() { woken <- struct{}{} }),
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	fare := money.Money{Amount: 22050, Currency: "INR"}
+	evt, _ := json.Marshal(events.BookingCreated{BookingID: "b-1", PickupLoc: models.Location{Lat: 1, Lng: 2}, Price: fare,
+		VehicleType: models.VehicleSUV, Passengers: 5})
+	// Published before prices had a currency: whole units of DEFAULT_CURRENCY.
+	legacy := []byte(`{"booking_id":"b-2","pickuploc":{"lat":1,"lng":2},"dropoff":{"lat":3,"lng":4},"price":220}`)
+	unknown := []byte(`{"booking_id":"b-3","price":{"amount":100,"currency":"XYZ"}}`)
+	tram := []byte(`{"booking_id":"b-4","price":{"amount":100,"currency":"INR"},"vehicle_type":"tram"}`)
+	for _, value := range [][]byte{[]byte("{"), evt, legacy, unknown, tram} {
+		if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicBookingCreated, Key: []byte("b-1"), Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dlq := b.Subscribe(cfg.TopicBookingCreated+cfg.TopicDLQSuffix, "inspect")
+	for _, want := range [][]byte{[]byte("{"), unknown, tram} {
+		parked, err := dlq.Fetch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(parked.Value) != string(want) {
+			t.Fatalf("unexpected dead letter: %q", parked.Value)
+		}
+	}
+	if p := <-repo.upserted; p.BookingID != "b-1" || p.Price != fare || p.PickupLoc.Lng != 2 ||
+		p.VehicleType != models.VehicleSUV || p.Passengers != 5 {
+		t.Fatalf("unexpected upsert: %+v", p)
+	}
+	if p := <-repo.upserted; p.BookingID != "b-2" || p.Price != (money.Money{Amount: 22000, Currency: "INR"}) {
+		t.Fatalf("unexpected legacy upsert: %+v", p)
+	}
+	select {
+	case <-woken:
+	case <-ctx.Done():
+		t.Fatal("watchers were not woken")
+	}
+}
@@ -0,0 +1,53 @@
+package mq
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/metrics"
+)
+
+// deadLetterWriter parks unprocessable messages on "<topic><suffix>" so the
+// consumer can move on without losing them.
+type deadLetterWriter struct {
+	pub    bus.Publisher
+	suffix string
+}
+
+func newDeadLetterWriter(cfg config.Config, pub bus.Publisher) *deadLetterWriter {
+	return &deadLetterWriter{pub: pub, suffix: cfg.TopicDLQSuffix}
+}
+
+func (d *deadLetterWriter) send(ctx context.Context, msg bus.Message, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	headers := append([]bus.Header{}, msg.Headers...)
+	headers = append(headers,
+		bus.Header{Key: "dlq-reason", Value: []byte(reason)},
+		bus.Header{Key: "dlq-source-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		bus.Header{Key: "dlq-source-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+	err := d.pub.Publish(ctx, bus.Message{
+		Topic:   msg.Topic + d.suffix,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err == nil {
+		metrics.ConsumerDeadLettered.WithLabelValues(msg.Topic).Inc()
+	}
+	return err
+}
+
+// observeLag records how far behind the partition head msg is.
+func observeLag(msg bus.Message) {
+	lag := msg.HighWaterMark - msg.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+	metrics.ConsumerLag.WithLabelValues(msg.Topic).Set(float64(lag))
+}
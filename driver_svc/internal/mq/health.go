@@ -0,0 +1,50 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// fetchStallThreshold is how long fetches may keep failing before the loop is reported unhealthy.
+const fetchStallThreshold = 30 * time.Second
+
+// loopHealth tracks a consumer loop: whether it is running, when it last fetched
+// successfully, and since when fetches have been failing.
+type loopHealth struct {
+	running      atomic.Bool
+	lastFetch    atomic.Int64 // unix nanos
+	failingSince atomic.Int64 // unix nanos, 0 when the last fetch succeeded
+}
+
+func (h *loopHealth) start() {
+	h.running.Store(true)
+	h.lastFetch.Store(time.Now().UnixNano())
+}
+
+func (h *loopHealth) stop() { h.running.Store(false) }
+
+func (h *loopHealth) fetched() {
+	h.lastFetch.Store(time.Now().UnixNano())
+	h.failingSince.Store(0)
+}
+
+func (h *loopHealth) fetchFailed() {
+	h.failingSince.CompareAndSwap(0, time.Now().UnixNano())
+}
+
+// check reports an error once the loop has exited or fetches have failed for longer than fetchStallThreshold.
+// A quiet topic is healthy: FetchMessage simply blocks while there is nothing to read.
+func (h *loopHealth) check(context.Context) error {
+	if !h.running.Load() {
+		return errors.New("consumer loop not running")
+	}
+	if since := h.failingSince.Load(); since != 0 && time.Since(time.Unix(0, since)) > fetchStallThreshold {
+		last := time.Unix(0, h.lastFetch.Load()).UTC().Format(time.RFC3339)
+		return fmt.Errorf("fetch failing for %s (last successful fetch %s)",
+			time.Since(time.Unix(0, since)).Round(time.Second), last)
+	}
+	return nil
+}
@@ -4,38 +4,43 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
-	"strings"
 	"time"
 
+	"driver_svc/internal/bus"
 	"driver_svc/internal/config"
 	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/tracing"
 
-	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type Producer struct {
-	writer               *kafka.Writer
+	pub                  bus.Publisher
 	topicBookingAccepted string
+	topicPayoutPaid      string
 	logger               *slog.Logger
 }
 
-func NewProducer(cfg config.Config, logger *slog.Logger) *Producer {
-	brokers := strings.Split(cfg.KafkaBrokers, ",")
-	w := &kafka.Writer{
-		Addr:         kafka.TCP(brokers...),
-		Topic:        cfg.TopicBookingAccepted,
-		Balancer:     &kafka.LeastBytes{},
-		RequiredAcks: kafka.RequireAll,
-		Async:        false,
-	}
+func NewProducer(cfg config.Config, pub bus.Publisher, logger *slog.Logger) *Producer {
 	return &Producer{
-		writer:               w,
+		pub:                  pub,
 		topicBookingAccepted: cfg.TopicBookingAccepted,
+		topicPayoutPaid:      cfg.TopicPayoutPaid,
 		logger:               logger,
 	}
 }
 
 func (p *Producer) ProduceBookingAccepted(ctx context.Context, evt events.BookingAccepted) error {
+	// preserves per-booking ordering
+	return p.publish(ctx, p.topicBookingAccepted, evt.BookingID, evt)
+}
+
+func (p *Producer) ProducePayoutPaid(ctx context.Context, evt events.PayoutPaid) error {
+	return p.publish(ctx, p.topicPayoutPaid, evt.DriverID, evt)
+}
+
+func (p *Producer) publish(ctx context.Context, topic, key string, evt any) error {
 	value, err := json.Marshal(evt)
 	if err != nil {
 		return err
@@ -43,10 +48,20 @@ func (p *Producer) ProduceBookingAccepted(ctx context.Context, evt events.Bookin
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(evt.BookingID), // preserves per-booking ordering
+	msg := bus.Message{
+		Topic: topic,
+		Key:   []byte(key),
 		Value: value,
-	})
-}
+	}
+	ctx, span := tracing.StartProduce(ctx, topic, &msg)
+	defer span.End()
 
-func (p *Producer) Close() error { return p.writer.Close() }
+	start := time.Now()
+	err = p.pub.Publish(ctx, msg)
+	metrics.KafkaProduceDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.KafkaProduceErrors.WithLabelValues(topic).Inc()
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
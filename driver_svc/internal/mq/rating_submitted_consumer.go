@@ -0,0 +1,135 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// RatingSubmittedConsumer folds riders' ratings of drivers into the drivers'
+// rolling averages. Drivers' ratings of riders are not kept here.
+type RatingSubmittedConsumer struct {
+	sub     bus.Subscriber
+	dlq     *deadLetterWriter
+	drivers repository.DriverRepository
+	window  int
+	logger  *slog.Logger
+	health  loopHealth
+}
+
+// NewRatingSubmittedConsumer joins the ratings consumer group on b. Messages
+// are committed only after the rating is stored.
+func NewRatingSubmittedConsumer(cfg config.Config, b bus.Bus, drivers repository.DriverRepository, logger *slog.Logger) *RatingSubmittedConsumer {
+	return &RatingSubmittedConsumer{
+		sub:     b.Subscribe(cfg.TopicRatingSubmitted, cfg.ConsumerGroupRatings),
+		dlq:     newDeadLetterWriter(cfg, b),
+		drivers: drivers,
+		window:  cfg.RatingWindow,
+		logger:  logger,
+	}
+}
+
+func (c *RatingSubmittedConsumer) Run(ctx context.Context) error {
+	c.health.start()
+	defer c.health.stop()
+	for {
+		msg, err := c.sub.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			c.health.fetchFailed()
+			c.logger.Error("bus fetch failed", slog.String("err", err.Error()))
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		c.health.fetched()
+		observeLag(msg)
+		c.handle(ctx, msg)
+	}
+}
+
+func validateRating(evt events.RatingSubmitted) error {
+	switch {
+	case evt.BookingID == "":
+		return errors.New("booking_id is required")
+	case evt.Rater != "rider" && evt.Rater != "driver":
+		return errors.New("rater must be rider or driver")
+	case evt.RateeID == "":
+		return errors.New("ratee_id is required")
+	case evt.Stars < 1 || evt.Stars > 5:
+		return errors.New("stars must be between 1 and 5")
+	case evt.SubmittedAt.IsZero():
+		return errors.New("submitted_at is required")
+	}
+	return nil
+}
+
+func (c *RatingSubmittedConsumer) handle(ctx context.Context, msg bus.Message) {
+	ctx, span := tracing.StartConsume(ctx, msg)
+	defer span.End()
+
+	var evt events.RatingSubmitted
+	err := json.Unmarshal(msg.Value, &evt)
+	if err == nil {
+		err = validateRating(evt)
+	}
+	if err != nil {
+		c.logger.Error("invalid rating.submitted payload", slog.String("err", err.Error()))
+		span.RecordError(err)
+		if err := c.dlq.send(ctx, msg, err.Error()); err != nil {
+			c.logger.Error("dead-letter failed", slog.String("err", err.Error()))
+			return
+		}
+		_ = c.sub.Commit(ctx, msg) // skip poison message
+		return
+	}
+	span.SetAttributes(attribute.String("booking_id", evt.BookingID))
+
+	if evt.Rater == "rider" {
+		recorded, err := c.drivers.RecordRating(ctx, repository.RecordRatingParams{
+			BookingID: evt.BookingID,
+			DriverID:  evt.RateeID,
+			Stars:     evt.Stars,
+			RatedAt:   evt.SubmittedAt,
+		}, c.window)
+		if err != nil {
+			c.logger.Error("record rating failed", slog.String("booking_id", evt.BookingID), slog.String("err", err.Error()))
+			span.SetStatus(codes.Error, err.Error())
+			metrics.ConsumerFailed.WithLabelValues(msg.Topic).Inc()
+			// no commit -> retry later
+			return
+		}
+		if recorded {
+			metrics.DriverRatingsRecorded.Inc()
+		}
+	}
+
+	if err := c.sub.Commit(ctx, msg); err != nil {
+		c.logger.Error("commit failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.ConsumerProcessed.WithLabelValues(msg.Topic).Inc()
+}
+
+// Healthy is a readiness check for the consume loop.
+func (c *RatingSubmittedConsumer) Healthy(ctx context.Context) error { return c.health.check(ctx) }
+
+func (c *RatingSubmittedConsumer) Close() error {
+	return c.sub.Close()
+}
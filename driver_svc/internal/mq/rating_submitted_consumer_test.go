@@ -0,0 +1,69 @@
+package mq
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"driver_svc/internal/bus"
+	"driver_svc/internal/config"
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository/memory"
+)
+
+func TestRatingSubmittedConsumer_MemoryBus(t *testing.T) {
+	cfg := config.Config{TopicRatingSubmitted: "rating.submitted", ConsumerGroupRatings: "ratings", TopicDLQSuffix: ".dlq", RatingWindow: 100}
+	b := bus.NewMemory()
+	defer b.Close()
+	drivers := memory.NewDriverRepo(models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true})
+	c := NewRatingSubmittedConsumer(cfg, b, drivers, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+
+	badStars := []byte(`{"booking_id":"b-9","rater":"rider","rater_id":"r-1","ratee_id":"d-1","stars":9,"submitted_at":"2026-03-01T09:00:00Z"}`)
+	for _, value := range [][]byte{
+		[]byte("{"),
+		badStars,
+		[]byte(`{"booking_id":"b-1","rater":"rider","rater_id":"r-1","ratee_id":"d-1","stars":5,"submitted_at":"2026-03-01T09:00:00Z"}`),
+		// A redelivery and the driver's rating of the rider change nothing.
+		[]byte(`{"booking_id":"b-1","rater":"rider","rater_id":"r-1","ratee_id":"d-1","stars":5,"submitted_at":"2026-03-01T09:00:00Z"}`),
+		[]byte(`{"booking_id":"b-1","rater":"driver","rater_id":"d-1","ratee_id":"r-1","stars":1,"submitted_at":"2026-03-01T09:01:00Z"}`),
+		[]byte(`{"booking_id":"b-2","rater":"rider","rater_id":"r-2","ratee_id":"d-1","stars":4,"submitted_at":"2026-03-01T10:00:00Z"}`),
+	} {
+		if err := b.Publish(ctx, bus.Message{Topic: cfg.TopicRatingSubmitted, Key: []byte("b-1"), Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dlq := b.Subscribe(cfg.TopicRatingSubmitted+cfg.TopicDLQSuffix, "inspect")
+	for _, want := range [][]byte{[]byte("{"), badStars} {
+		parked, err := dlq.Fetch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(parked.Value) != string(want) {
+			t.Fatalf("unexpected dead letter: %q", parked.Value)
+		}
+	}
+	for {
+		d, _, err := drivers.GetByID(ctx, "d-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Rating.Count == 2 {
+			if d.Rating.Average != 4.5 {
+				t.Fatalf("rating = %+v, want 4.5 over 2", d.Rating)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("ratings not recorded: %+v", d.Rating)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
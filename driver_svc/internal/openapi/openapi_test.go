@@ -0,0 +1,68 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	s, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("served document is not JSON: %v", err)
+	}
+	if doc["openapi"] == nil || doc["paths"] == nil {
+		t.Fatalf("unexpected document: %v", doc)
+	}
+}
+
+func TestValidator(t *testing.T) {
+	s := MustLoad()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cases := []struct {
+		name       string
+		mode       ResponseMode
+		method     string
+		path       string
+		body       string
+		respond    string
+		wantStatus int
+	}{
+		{"unknown field", ResponsesStrict, http.MethodPost, "/jobs/b-1/accept", `{"driver_id":"d-1","extra":"x"}`, "", http.StatusBadRequest},
+		{"empty driver_id", ResponsesStrict, http.MethodPost, "/jobs/b-1/accept", `{"driver_id":""}`, "", http.StatusBadRequest},
+		{"optional body", ResponsesStrict, http.MethodPost, "/jobs/b-1/accept", ``, `{"status":"accepted"}`, http.StatusOK},
+		{"unknown path passes through", ResponsesStrict, http.MethodGet, "/nope", ``, `whatever`, http.StatusOK},
+		{"valid response", ResponsesStrict, http.MethodGet, "/jobs", ``, `[]`, http.StatusOK},
+		{"invalid response strict", ResponsesStrict, http.MethodGet, "/jobs", ``, `{"not":"a list"}`, http.StatusInternalServerError},
+		{"invalid response logged", ResponsesLog, http.MethodGet, "/jobs", ``, `{"not":"a list"}`, http.StatusOK},
+		{"responses unchecked", ResponsesOff, http.MethodGet, "/jobs", ``, `{"not":"a list"}`, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := s.Validator(c.mode, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(c.respond))
+			}))
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != c.wantStatus {
+				t.Fatalf("want %d, got %d, body=%s", c.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
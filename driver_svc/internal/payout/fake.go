@@ -0,0 +1,71 @@
+package payout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"driver_svc/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// Fake is an in-process provider for local runs and tests. Like a real
+// provider, it answers a repeated idempotency key with the first call's
+// outcome.
+type Fake struct {
+	rejectAbove int64
+
+	mu      sync.Mutex
+	results map[string]result
+	paid    map[string]map[string]int64
+	// Fail, when set, is consulted before every call; a non-nil error is
+	// returned without paying or remembering the key, like a network failure.
+	Fail func(key string, req service.PayoutRequest) error
+}
+
+type result struct {
+	ref string
+	err error
+}
+
+// NewFake rejects payouts above rejectAbove minor units; zero never rejects.
+func NewFake(rejectAbove int64) *Fake {
+	return &Fake{rejectAbove: rejectAbove, results: make(map[string]result), paid: make(map[string]map[string]int64)}
+}
+
+func (f *Fake) Pay(_ context.Context, key string, req service.PayoutRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Fail != nil {
+		if err := f.Fail(key, req); err != nil {
+			return "", err
+		}
+	}
+	if r, ok := f.results[key]; ok {
+		return r.ref, r.err
+	}
+	var r result
+	if f.rejectAbove > 0 && req.Amount.Amount > f.rejectAbove {
+		r.err = fmt.Errorf("%w: %s is over the limit", service.ErrPayoutRejected, req.Amount)
+	} else {
+		r.ref = "po_" + uuid.NewString()
+		if f.paid[req.DriverID] == nil {
+			f.paid[req.DriverID] = make(map[string]int64)
+		}
+		f.paid[req.DriverID][req.Amount.Currency] += req.Amount.Amount
+	}
+	f.results[key] = r
+	return r.ref, r.err
+}
+
+// Paid returns the total paid to driverID per currency, for tests.
+func (f *Fake) Paid(driverID string) map[string]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]int64)
+	for c, n := range f.paid[driverID] {
+		out[c] = n
+	}
+	return out
+}
@@ -0,0 +1,22 @@
+// Package payout holds the payout providers and the worker that runs payouts
+// on a schedule.
+package payout
+
+import (
+	"fmt"
+
+	"driver_svc/internal/config"
+	"driver_svc/internal/service"
+)
+
+const ProviderFake = "fake"
+
+// Open returns the provider selected by PAYOUT_PROVIDER.
+func Open(cfg config.Config) (service.PayoutProvider, error) {
+	switch cfg.PayoutProvider {
+	case ProviderFake:
+		return NewFake(cfg.PayoutFakeRejectAbove), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYOUT_PROVIDER %q (want %s)", cfg.PayoutProvider, ProviderFake)
+	}
+}
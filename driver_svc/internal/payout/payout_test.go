@@ -0,0 +1,321 @@
+package payout_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"driver_svc/internal/events"
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/payout"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/repository/memory"
+	"driver_svc/internal/service"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func inr(amount int64) money.Money { return money.Money{Amount: amount, Currency: "INR"} }
+
+var policy = service.PayoutPolicy{
+	MinAmount:   10000,
+	MaxAttempts: 3,
+	BaseBackoff: time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+// paidEvents collects payout.paid events and fails while down.
+type paidEvents struct {
+	mu   sync.Mutex
+	down bool
+	evts []events.PayoutPaid
+}
+
+func (p *paidEvents) ProducePayoutPaid(_ context.Context, evt events.PayoutPaid) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errors.New("bus unavailable")
+	}
+	p.evts = append(p.evts, evt)
+	return nil
+}
+
+type fixture struct {
+	provider *payout.Fake
+	paid     *paidEvents
+	jobs     *memory.JobRepo
+	payouts  *memory.PayoutRepo
+	svc      service.PayoutService
+}
+
+func newFixture(t *testing.T, rejectAbove int64) *fixture {
+	t.Helper()
+	f := &fixture{provider: payout.NewFake(rejectAbove), paid: &paidEvents{}, jobs: memory.NewJobRepo()}
+	f.payouts = memory.NewPayoutRepo(f.jobs)
+	drivers := memory.NewDriverRepo(
+		models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true},
+		models.Driver{DriverID: "d-2", Name: "Ravi", IsAvailable: true},
+	)
+	f.svc = service.NewPayoutService(drivers, f.payouts, f.provider, f.paid, policy, discard)
+	return f
+}
+
+// take opens a job priced fare and has driverID accept it.
+func (f *fixture) take(t *testing.T, bookingID, driverID string, fare money.Money) {
+	t.Helper()
+	ctx := context.Background()
+	if err := f.jobs.UpsertOpenJob(ctx, repository.UpsertJobParams{BookingID: bookingID, Price: fare}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := f.jobs.TryAccept(ctx, bookingID, driverID); !ok || err != nil {
+		t.Fatalf("accept %s: ok=%v err=%v", bookingID, ok, err)
+	}
+}
+
+// complete takes a job and records that booking_svc's ledger credited the
+// driver earned for the trip.
+func (f *fixture) complete(t *testing.T, bookingID, driverID string, fare, earned money.Money) {
+	t.Helper()
+	f.take(t, bookingID, driverID, fare)
+	e := repository.Earning{BookingID: bookingID, DriverID: driverID, Fare: fare, Amount: earned, CompletedAt: time.Now()}
+	if ok, err := f.payouts.RecordEarning(context.Background(), e); !ok || err != nil {
+		t.Fatalf("record %s: ok=%v err=%v", bookingID, ok, err)
+	}
+}
+
+func (f *fixture) run(t *testing.T) service.PayoutRun {
+	t.Helper()
+	run, err := f.svc.RunPayouts(context.Background(), time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return run
+}
+
+func (f *fixture) list(t *testing.T, driverID string) []models.Payout {
+	t.Helper()
+	ps, err := f.svc.ListDriverPayouts(context.Background(), driverID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
+
+func TestRunPaysRecordedEarningsAboveMinimum(t *testing.T) {
+	f := newFixture(t, 0)
+	f.complete(t, "b-1", "d-1", inr(22050), inr(17640))
+	f.complete(t, "b-2", "d-1", inr(25), inr(20))
+	f.complete(t, "b-3", "d-2", inr(5000), inr(4000))
+	f.take(t, "b-4", "d-2", inr(90000))
+	if _, err := f.jobs.Cancel(context.Background(), "b-4"); err != nil {
+		t.Fatal(err)
+	}
+	// Taken but not completed yet, so nothing is owed for it.
+	f.take(t, "b-6", "d-1", inr(50000))
+
+	run := f.run(t)
+	if run.Created != 1 || run.Deferred != 1 || run.Paid != 1 || run.Retrying != 0 || run.Failed != 0 {
+		t.Fatalf("first run: %+v", run)
+	}
+	// The earnings as recorded, whatever the fares: 17640 + 20.
+	if got := f.provider.Paid("d-1"); got["INR"] != 17660 {
+		t.Fatalf("paid d-1 %v", got)
+	}
+	ps := f.list(t, "d-1")
+	if len(ps) != 1 || ps[0].Status != models.PayoutPaid || ps[0].Amount != inr(17660) || len(ps[0].Items) != 2 ||
+		ps[0].Items[0] != (models.PayoutItem{BookingID: "b-1", Fare: inr(22050), Amount: inr(17640)}) || ps[0].ProviderRef == nil {
+		t.Fatalf("d-1 payouts: %+v", ps)
+	}
+	if ps := f.list(t, "d-2"); len(ps) != 0 {
+		t.Fatalf("d-2 is owed 4000, below the minimum: %+v", ps)
+	}
+
+	if again := f.run(t); again.Created != 0 || again.Paid != 0 || again.Deferred != 1 {
+		t.Fatalf("a repeated run must not pay twice: %+v", again)
+	}
+	f.complete(t, "b-5", "d-2", inr(10000), inr(8000))
+	if run := f.run(t); run.Created != 1 || run.Paid != 1 {
+		t.Fatalf("carried-over earnings: %+v", run)
+	}
+	if got := f.provider.Paid("d-2"); got["INR"] != 12000 {
+		t.Fatalf("paid d-2 %v", got)
+	}
+	if _, err := f.svc.ListDriverPayouts(context.Background(), "d-9", 10); !errors.Is(err, service.ErrDriverNotFound) {
+		t.Fatalf("unknown driver: %v", err)
+	}
+}
+
+func TestFailedPayoutsRetryWithoutHoldingUpOthers(t *testing.T) {
+	f := newFixture(t, 0)
+	f.complete(t, "b-1", "d-1", inr(22050), inr(17640))
+	f.complete(t, "b-2", "d-2", inr(22050), inr(17640))
+	down := true
+	f.provider.Fail = func(_ string, req service.PayoutRequest) error {
+		if down && req.DriverID == "d-2" {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+
+	if run := f.run(t); run.Created != 2 || run.Paid != 1 || run.Retrying != 1 {
+		t.Fatalf("partial failure: %+v", run)
+	}
+	if p := f.list(t, "d-2")[0]; p.Status != models.PayoutPending || p.Attempts != 1 || p.LastError == nil {
+		t.Fatalf("d-2 after a transient failure: %+v", p)
+	}
+
+	down = false
+	time.Sleep(2 * policy.BaseBackoff)
+	if run := f.run(t); run.Created != 0 || run.Paid != 1 {
+		t.Fatalf("retry: %+v", run)
+	}
+	if got := f.provider.Paid("d-2"); got["INR"] != 17640 {
+		t.Fatalf("paid d-2 %v", got)
+	}
+	if got := f.provider.Paid("d-1"); got["INR"] != 17640 {
+		t.Fatalf("d-1 must be paid once: %v", got)
+	}
+}
+
+func TestPaidPayoutsArePublishedBeforeBeingMarkedPaid(t *testing.T) {
+	f := newFixture(t, 0)
+	f.complete(t, "b-1", "d-1", inr(22050), inr(17640))
+
+	f.paid.down = true
+	for i := 0; i < policy.MaxAttempts; i++ {
+		if run := f.run(t); run.Retrying != 1 {
+			t.Fatalf("run %d while payout.paid cannot be published: %+v", i, run)
+		}
+		time.Sleep(2 * policy.MaxBackoff)
+	}
+	p := f.list(t, "d-1")
+	if len(p) != 1 || p[0].Status != models.PayoutPending {
+		t.Fatalf("an unpublished payout stays pending: %+v", p)
+	}
+
+	// The next run pays under the same key, which the provider answers with
+	// the original transfer.
+	f.paid.down = false
+	if run := f.run(t); run.Paid != 1 {
+		t.Fatalf("retry: %+v", run)
+	}
+	if got := f.provider.Paid("d-1"); got["INR"] != 17640 {
+		t.Fatalf("d-1 must be paid once: %v", got)
+	}
+	p = f.list(t, "d-1")
+	if len(f.paid.evts) != 1 || f.paid.evts[0].PayoutID != p[0].ID || f.paid.evts[0].Amount != inr(17640) ||
+		f.paid.evts[0].ProviderRef != *p[0].ProviderRef {
+		t.Fatalf("payout.paid: %+v for %+v", f.paid.evts, p[0])
+	}
+}
+
+func TestRejectedPayoutsReleaseTheirJobs(t *testing.T) {
+	f := newFixture(t, 20000)
+	f.complete(t, "b-1", "d-1", inr(30000), inr(24000))
+
+	// 24000 is over the fake's limit: rejected outright, never retried.
+	if run := f.run(t); run.Failed != 1 || run.Retrying != 0 {
+		t.Fatalf("first run: %+v", run)
+	}
+	if p := f.list(t, "d-1"); len(p) != 1 || p[0].Status != models.PayoutFailed || p[0].Attempts != 1 {
+		t.Fatalf("rejected payout: %+v", p)
+	}
+
+	// Released jobs are picked up by the next run.
+	if run := f.run(t); run.Created != 1 || run.Failed != 1 {
+		t.Fatalf("run after a rejection: %+v", run)
+	}
+	if p := f.list(t, "d-1"); len(p) != 2 {
+		t.Fatalf("want the jobs paid out again: %+v", p)
+	}
+}
+
+func TestExhaustedPayoutsAreHeldWithTheirJobs(t *testing.T) {
+	f := newFixture(t, 0)
+	f.complete(t, "b-1", "d-1", inr(22050), inr(17640))
+	var keys []string
+	f.provider.Fail = func(key string, _ service.PayoutRequest) error {
+		keys = append(keys, key)
+		return errors.New("timeout")
+	}
+
+	if run := f.run(t); run.Retrying != 1 {
+		t.Fatalf("first run: %+v", run)
+	}
+	for i := 1; i < policy.MaxAttempts; i++ {
+		time.Sleep(2 * policy.MaxBackoff)
+		f.run(t)
+	}
+	p := f.list(t, "d-1")
+	if len(p) != 1 || p[0].Status != models.PayoutHeld || p[0].Attempts != policy.MaxAttempts {
+		t.Fatalf("exhausted payout: %+v", p)
+	}
+	for _, k := range keys {
+		if k != p[0].ID {
+			t.Fatalf("retried under key %s, want %s", k, p[0].ID)
+		}
+	}
+
+	// A timed-out call may have paid the driver, so the jobs are not paid
+	// again under a new key.
+	f.provider.Fail = nil
+	time.Sleep(2 * policy.MaxBackoff)
+	if run := f.run(t); run != (service.PayoutRun{Until: run.Until}) {
+		t.Fatalf("run after the provider recovered: %+v", run)
+	}
+	if got := f.provider.Paid("d-1"); len(got) != 0 {
+		t.Fatalf("held jobs paid again: %v", got)
+	}
+}
+
+func TestConcurrentRunsPayOnce(t *testing.T) {
+	f := newFixture(t, 0)
+	for _, id := range []string{"b-1", "b-2", "b-3"} {
+		f.complete(t, id, "d-1", inr(22050), inr(17640))
+	}
+	// A second service over the same storage stands in for another replica.
+	other := service.NewPayoutService(memory.NewDriverRepo(), f.payouts, f.provider, f.paid, policy, discard)
+
+	var wg sync.WaitGroup
+	for _, svc := range []service.PayoutService{f.svc, other, f.svc, other} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.RunPayouts(context.Background(), time.Now().Add(time.Second)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := f.provider.Paid("d-1"); got["INR"] != 3*17640 {
+		t.Fatalf("paid d-1 %v", got)
+	}
+	if ps := f.list(t, "d-1"); len(ps) != 1 {
+		t.Fatalf("want one payout, got %+v", ps)
+	}
+}
+
+type recordingRunner struct{ until time.Time }
+
+func (r *recordingRunner) RunPayouts(_ context.Context, until time.Time) (service.PayoutRun, error) {
+	r.until = until
+	return service.PayoutRun{Until: until}, nil
+}
+
+func TestRunnerPaysEndedPeriods(t *testing.T) {
+	svc := &recordingRunner{}
+	before := time.Now()
+	if _, err := payout.NewRunner(svc, 24*time.Hour, time.Hour, discard).RunDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	u := svc.until.UTC()
+	if u.After(before) || before.Sub(u) >= 24*time.Hour || u.Hour() != 0 || u.Minute() != 0 || u.Second() != 0 || u.Nanosecond() != 0 {
+		t.Fatalf("until %s is not the last midnight UTC before %s", u, before.UTC())
+	}
+}
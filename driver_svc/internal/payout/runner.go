@@ -0,0 +1,63 @@
+package payout
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"driver_svc/internal/service"
+)
+
+// PayoutRunner is the slice of service.PayoutService the Runner drives.
+type PayoutRunner interface {
+	RunPayouts(ctx context.Context, until time.Time) (service.PayoutRun, error)
+}
+
+// Runner runs payouts every interval for the periods that have ended. Runs
+// only create payouts for jobs no other payout covers and claim due payouts
+// under a lease, so replicas may run one each.
+type Runner struct {
+	svc      PayoutRunner
+	period   time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewRunner(svc PayoutRunner, period, interval time.Duration, logger *slog.Logger) *Runner {
+	return &Runner{svc: svc, period: period, interval: interval, logger: logger, now: time.Now}
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RunDue(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("payout run failed", slog.String("err", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue pays the earnings of every period that ended by now and retries the
+// payouts that are due. Periods are whole multiples of the period length, so
+// daily periods end at 00:00 UTC.
+func (r *Runner) RunDue(ctx context.Context) (service.PayoutRun, error) {
+	run, err := r.svc.RunPayouts(ctx, r.now().Truncate(r.period))
+	if err == nil && run != (service.PayoutRun{Until: run.Until}) {
+		r.logger.Info("payout run",
+			slog.Time("until", run.Until),
+			slog.Int("created", run.Created),
+			slog.Int("deferred", run.Deferred),
+			slog.Int("paid", run.Paid),
+			slog.Int("retrying", run.Retrying),
+			slog.Int("failed", run.Failed),
+			slog.Int("held", run.Held),
+		)
+	}
+	return run, err
+}
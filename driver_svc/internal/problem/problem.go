@@ -0,0 +1,134 @@
+// Package problem renders API errors as RFC 7807 application/problem+json
+// documents with stable, machine-readable codes.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const ContentType = "application/problem+json"
+
+// typePrefix namespaces the problem "type" URI; the suffix is the Code.
+const typePrefix = "urn:problem-type:"
+
+// Code identifies a kind of failure. Codes are part of the API contract:
+// clients branch on them, so never rename one.
+type Code string
+
+const (
+	CodeInvalidJSON      Code = "invalid_json"
+	CodeValidationFailed Code = "validation_failed"
+	CodeUnauthenticated  Code = "unauthenticated"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeRateLimited      Code = "rate_limited"
+	CodeOverloaded       Code = "overloaded"
+	CodeTimeout          Code = "timeout"
+	CodeInternal         Code = "internal"
+	CodeJobAlreadyTaken  Code = "job_already_taken"
+	CodeDriverNotFound   Code = "driver_not_found"
+	CodeJobNotFound      Code = "job_not_found"
+	CodeJobNotTaken      Code = "job_not_taken"
+	CodeJobCancelled     Code = "job_cancelled"
+	CodeVehicleMismatch  Code = "vehicle_mismatch"
+	CodePlateTaken       Code = "plate_taken"
+)
+
+var titles = map[Code]string{
+	CodeInvalidJSON:      "Request body is not valid JSON",
+	CodeValidationFailed: "Request failed validation",
+	CodeUnauthenticated:  "Authentication required",
+	CodeForbidden:        "Not allowed for this role",
+	CodeNotFound:         "Resource not found",
+	CodeMethodNotAllowed: "Method not allowed",
+	CodeRateLimited:      "Rate limit exceeded",
+	CodeOverloaded:       "Server overloaded",
+	CodeTimeout:          "Request timed out",
+	CodeInternal:         "Internal server error",
+	CodeJobAlreadyTaken:  "Job already taken by another driver",
+	CodeDriverNotFound:   "Driver not found or unavailable",
+	CodeJobNotFound:      "Job not found",
+	CodeJobNotTaken:      "Job has not been taken",
+	CodeJobCancelled:     "Job was cancelled by the rider",
+	CodeVehicleMismatch:  "Driver's vehicle cannot serve this job",
+	CodePlateTaken:       "Plate is registered to another driver",
+}
+
+// FieldError points at one invalid input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is the error body returned by every API endpoint.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      Code         `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// New builds a Problem; the title is derived from code.
+func New(status int, code Code, detail string) *Problem {
+	title, ok := titles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+	return &Problem{
+		Type:   typePrefix + string(code),
+		Title:  title,
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return string(p.Code) + ": " + p.Detail
+	}
+	return string(p.Code)
+}
+
+// Write renders p for request r, stamping the request path and chi request ID.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	out := *p
+	out.Instance = r.URL.Path
+	out.RequestID = middleware.GetReqID(r.Context())
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(out.Status)
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// ValidationError collects field-level failures from request validation.
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validation turns err into a 400 validation_failed problem, keeping field
+// errors when err is a ValidationError.
+func Validation(err error) *Problem {
+	var ve ValidationError
+	if errors.As(err, &ve) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "")
+		p.Errors = ve
+		return p
+	}
+	return New(http.StatusBadRequest, CodeValidationFailed, err.Error())
+}
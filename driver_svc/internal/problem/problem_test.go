@@ -0,0 +1,62 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestWrite(t *testing.T) {
+	var got Problem
+	h := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, Validation(ValidationError{{Field: "driver_id", Message: "is required"}}))
+	}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/jobs/b-1/accept", nil))
+
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Code != CodeValidationFailed || got.Type != "urn:problem-type:validation_failed" ||
+		got.Instance != "/jobs/b-1/accept" || got.RequestID == "" || len(got.Errors) != 1 || got.Errors[0].Field != "driver_id" {
+		t.Fatalf("unexpected problem: %+v", got)
+	}
+}
+
+func TestMapper(t *testing.T) {
+	errGone := errors.New("gone")
+	m := Mapper{{Err: errGone, Status: http.StatusNotFound, Code: CodeNotFound}}
+
+	cases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   Code
+	}{
+		{"sentinel", errGone, http.StatusNotFound, CodeNotFound},
+		{"wrapped sentinel", fmt.Errorf("repo: %w", errGone), http.StatusNotFound, CodeNotFound},
+		{"problem passes through", New(http.StatusConflict, CodeForbidden, "x"), http.StatusConflict, CodeForbidden},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, CodeTimeout},
+		{"unknown", errors.New("pq: secret details"), http.StatusInternalServerError, CodeInternal},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := m.Map(c.err)
+			if p.Status != c.wantStatus || p.Code != c.wantCode {
+				t.Fatalf("got %d %s", p.Status, p.Code)
+			}
+			if c.wantCode == CodeInternal && p.Detail != "" {
+				t.Fatalf("internal detail leaked: %q", p.Detail)
+			}
+		})
+	}
+}
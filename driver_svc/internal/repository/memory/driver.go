@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+)
+
+type DriverRepo struct {
+	mu      sync.RWMutex
+	drivers map[string]models.Driver
+	// ratings are keyed by booking id.
+	ratings map[string]repository.RecordRatingParams
+}
+
+// NewDriverRepo starts with drivers already registered, standing in for the
+// seed step the Postgres deployment runs at startup.
+func NewDriverRepo(drivers ...models.Driver) *DriverRepo {
+	r := &DriverRepo{
+		drivers: make(map[string]models.Driver, len(drivers)),
+		ratings: make(map[string]repository.RecordRatingParams),
+	}
+	for _, d := range drivers {
+		r.drivers[d.DriverID] = copyDriver(d)
+	}
+	return r
+}
+
+// copyDriver keeps callers from sharing the stored driver's vehicle.
+func copyDriver(d models.Driver) models.Driver {
+	if d.Vehicle != nil {
+		v := *d.Vehicle
+		d.Vehicle = &v
+	}
+	return d
+}
+
+// ListAll returns every driver ordered by id.
+func (r *DriverRepo) ListAll(_ context.Context) ([]models.Driver, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]models.Driver, 0, len(r.drivers))
+	for _, d := range r.drivers {
+		out = append(out, copyDriver(d))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DriverID < out[j].DriverID })
+	return out, nil
+}
+
+func (r *DriverRepo) GetByID(_ context.Context, driverID string) (models.Driver, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.drivers[driverID]
+	return copyDriver(d), ok, nil
+}
+
+func (r *DriverRepo) SetVehicle(_ context.Context, driverID string, v models.Vehicle) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, d := range r.drivers {
+		if id != driverID && d.Vehicle != nil && d.Vehicle.Plate == v.Plate {
+			return false, nil
+		}
+	}
+	d, ok := r.drivers[driverID]
+	if !ok {
+		// Postgres refuses it with a foreign key violation.
+		return false, fmt.Errorf("driver %s not registered", driverID)
+	}
+	d.Vehicle = &v
+	r.drivers[driverID] = d
+	return true, nil
+}
+
+func (r *DriverRepo) RecordRating(_ context.Context, p repository.RecordRatingParams, window int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ratings[p.BookingID]; ok {
+		return false, nil
+	}
+	p.RatedAt = p.RatedAt.Truncate(time.Microsecond)
+	r.ratings[p.BookingID] = p
+
+	d, ok := r.drivers[p.DriverID]
+	if !ok {
+		// Kept for when the driver is registered, as in Postgres.
+		return true, nil
+	}
+	var latest []repository.RecordRatingParams
+	for _, rt := range r.ratings {
+		if rt.DriverID == p.DriverID {
+			latest = append(latest, rt)
+		}
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		if !latest[i].RatedAt.Equal(latest[j].RatedAt) {
+			return latest[i].RatedAt.After(latest[j].RatedAt)
+		}
+		return latest[i].BookingID > latest[j].BookingID
+	})
+	if len(latest) > window {
+		latest = latest[:window]
+	}
+	sum := 0
+	for _, rt := range latest {
+		sum += rt.Stars
+	}
+	d.Rating = models.DriverRating{Average: roundedAverage(sum, len(latest)), Count: len(latest)}
+	r.drivers[p.DriverID] = d
+	return true, nil
+}
+
+// roundedAverage is sum/n rounded half away from zero to two decimals, as
+// Postgres rounds a numeric AVG.
+func roundedAverage(sum, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	hundredths := (sum*200 + n) / (2 * n)
+	return float64(hundredths) / 100
+}
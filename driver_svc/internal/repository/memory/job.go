@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+)
+
+type JobRepo struct {
+	mu    sync.RWMutex
+	clock clock
+	jobs  map[string]models.Job
+}
+
+func NewJobRepo() *JobRepo {
+	return &JobRepo{jobs: make(map[string]models.Job)}
+}
+
+// UpsertOpenJob inserts an Open job if it does not already exist (idempotent).
+func (r *JobRepo) UpsertOpenJob(_ context.Context, p repository.UpsertJobParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[p.BookingID]; ok {
+		return nil
+	}
+	r.jobs[p.BookingID] = copyJob(models.Job{
+		BookingID:    p.BookingID,
+		PickupLoc:    p.PickupLoc,
+		Dropoff:      p.Dropoff,
+		Stops:        p.Stops,
+		Price:        p.Price,
+		VehicleType:  p.VehicleType,
+		Passengers:   max(p.Passengers, 1),
+		Status:       models.JobStatusOpen,
+		ScheduledFor: p.ScheduledFor,
+		CreatedAt:    r.clock.now(),
+	})
+	return nil
+}
+
+// ListOpenJobs returns Open jobs newest first.
+func (r *JobRepo) ListOpenJobs(_ context.Context) ([]models.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]models.Job, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		if j.Status == models.JobStatusOpen {
+			out = append(out, copyJob(j))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *JobRepo) ListCreatedSince(_ context.Context, since time.Time, limit int) ([]models.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]models.Job, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		if !j.CreatedAt.Before(since) {
+			out = append(out, copyJob(j))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].BookingID < out[j].BookingID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *JobRepo) GetJob(_ context.Context, bookingID string) (models.Job, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	j, ok := r.jobs[bookingID]
+	if !ok {
+		return models.Job{}, false, nil
+	}
+	return copyJob(j), true, nil
+}
+
+// TryAccept marks an Open job Taken by driverID. Only the first caller wins.
+func (r *JobRepo) TryAccept(_ context.Context, bookingID, driverID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[bookingID]
+	if !ok || j.Status != models.JobStatusOpen {
+		return false, nil
+	}
+	at := r.clock.now()
+	j.Status = models.JobStatusTaken
+	j.AcceptedDriverID = &driverID
+	j.AcceptedAt = &at
+	r.jobs[bookingID] = j
+	return true, nil
+}
+
+// Cancel marks a job Cancelled unless it already is.
+func (r *JobRepo) Cancel(_ context.Context, bookingID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[bookingID]
+	if !ok || j.Status == models.JobStatusCancelled {
+		return false, nil
+	}
+	j.Status = models.JobStatusCancelled
+	r.jobs[bookingID] = j
+	return true, nil
+}
+
+func copyJob(j models.Job) models.Job {
+	if j.AcceptedDriverID != nil {
+		id := *j.AcceptedDriverID
+		j.AcceptedDriverID = &id
+	}
+	if j.AcceptedAt != nil {
+		at := *j.AcceptedAt
+		j.AcceptedAt = &at
+	}
+	if j.ScheduledFor != nil {
+		at := *j.ScheduledFor
+		j.ScheduledFor = &at
+	}
+	if j.Stops != nil {
+		j.Stops = append([]models.Location(nil), j.Stops...)
+	}
+	return j
+}
@@ -0,0 +1,28 @@
+// Package memory holds in-process reference implementations of the
+// repository interfaces. They follow the Postgres implementations exactly —
+// ordering, idempotency and conditional updates — and
+// the repotest conformance suite runs against both to keep it that way.
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// clock hands out created_at values the way Postgres NOW() does: microsecond
+// precision, and strictly increasing here so that DESC orderings are stable.
+type clock struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := time.Now().Truncate(time.Microsecond)
+	if !t.After(c.last) {
+		t = c.last.Add(time.Microsecond)
+	}
+	c.last = t
+	return t
+}
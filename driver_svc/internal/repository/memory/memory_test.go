@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"testing"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/repository/repotest"
+)
+
+func TestDriverRepo(t *testing.T) {
+	repotest.DriverRepository(t, func(_ *testing.T, drivers ...models.Driver) repository.DriverRepository {
+		return NewDriverRepo(drivers...)
+	})
+}
+
+func TestJobRepo(t *testing.T) {
+	repotest.JobRepository(t, func(*testing.T) repository.JobRepository { return NewJobRepo() })
+}
+
+func TestPayoutRepo(t *testing.T) {
+	repotest.PayoutRepository(t, func(*testing.T) (repository.JobRepository, repository.PayoutRepository) {
+		jobs := NewJobRepo()
+		return jobs, NewPayoutRepo(jobs)
+	})
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+)
+
+// PayoutRepo keeps payouts for the jobs held by a JobRepo.
+type PayoutRepo struct {
+	jobs  *JobRepo
+	mu    sync.Mutex
+	clock clock
+	// earnings is keyed by booking_id; payouts is keyed by id; active maps
+	// each covered booking_id to the pending, paid or held payout covering it.
+	earnings map[string]repository.Earning
+	payouts  map[string]models.Payout
+	active   map[string]string
+}
+
+func NewPayoutRepo(jobs *JobRepo) *PayoutRepo {
+	return &PayoutRepo{jobs: jobs, earnings: make(map[string]repository.Earning), payouts: make(map[string]models.Payout), active: make(map[string]string)}
+}
+
+func (r *PayoutRepo) RecordEarning(_ context.Context, e repository.Earning) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs.mu.RLock()
+	j, ok := r.jobs.jobs[e.BookingID]
+	r.jobs.mu.RUnlock()
+	if !ok || j.Status != models.JobStatusTaken || j.AcceptedDriverID == nil || *j.AcceptedDriverID != e.DriverID {
+		return false, nil
+	}
+	if _, ok := r.earnings[e.BookingID]; ok {
+		return false, nil
+	}
+	r.earnings[e.BookingID] = e
+	return true, nil
+}
+
+func (r *PayoutRepo) ListUnpaidEarnings(_ context.Context, until time.Time) ([]repository.Earning, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]repository.Earning, 0, len(r.earnings))
+	for _, e := range r.earnings {
+		if !e.CompletedAt.Before(until) {
+			continue
+		}
+		if _, ok := r.active[e.BookingID]; ok {
+			continue
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CompletedAt.Equal(out[j].CompletedAt) {
+			return out[i].CompletedAt.Before(out[j].CompletedAt)
+		}
+		return out[i].BookingID < out[j].BookingID
+	})
+	return out, nil
+}
+
+func (r *PayoutRepo) CreatePayout(_ context.Context, p repository.CreatePayoutParams) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.payouts[p.ID]; ok {
+		return false, fmt.Errorf("memory: payout %s already exists", p.ID)
+	}
+	for _, it := range p.Items {
+		if _, ok := r.active[it.BookingID]; ok {
+			return false, nil
+		}
+	}
+	items := append([]models.PayoutItem(nil), p.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].BookingID < items[j].BookingID })
+	now := r.clock.now()
+	r.payouts[p.ID] = models.Payout{
+		ID:            p.ID,
+		DriverID:      p.DriverID,
+		Amount:        p.Amount,
+		Status:        models.PayoutPending,
+		PeriodStart:   p.PeriodStart,
+		PeriodEnd:     p.PeriodEnd,
+		Items:         items,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	for _, it := range p.Items {
+		r.active[it.BookingID] = p.ID
+	}
+	return true, nil
+}
+
+func (r *PayoutRepo) ClaimDuePayouts(_ context.Context, now time.Time, lease time.Duration, limit int) ([]models.Payout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := make([]models.Payout, 0, limit)
+	for _, p := range r.payouts {
+		if p.Status == models.PayoutPending && !p.NextAttemptAt.After(now) {
+			due = append(due, p)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i, p := range due {
+		p.NextAttemptAt = now.Add(lease)
+		r.payouts[p.ID] = p
+		due[i] = copyPayout(p)
+	}
+	return due, nil
+}
+
+func (r *PayoutRepo) RecordPayoutAttempt(_ context.Context, payoutID string, res repository.PayoutAttemptResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payouts[payoutID]
+	if !ok {
+		return nil
+	}
+	p.Attempts++
+	p.LastError = res.Error
+	switch {
+	case res.ProviderRef != nil:
+		now := r.clock.now()
+		p.Status, p.ProviderRef, p.PaidAt = models.PayoutPaid, res.ProviderRef, &now
+	case res.NextAttemptAt != nil:
+		p.NextAttemptAt = *res.NextAttemptAt
+	case !res.Rejected:
+		p.Status = models.PayoutHeld
+	default:
+		p.Status = models.PayoutFailed
+		for _, it := range p.Items {
+			if r.active[it.BookingID] == p.ID {
+				delete(r.active, it.BookingID)
+			}
+		}
+	}
+	r.payouts[payoutID] = p
+	return nil
+}
+
+func (r *PayoutRepo) ListDriverPayouts(_ context.Context, driverID string, limit int) ([]models.Payout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]models.Payout, 0, limit)
+	for _, p := range r.payouts {
+		if p.DriverID == driverID {
+			out = append(out, copyPayout(p))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func copyPayout(p models.Payout) models.Payout {
+	p.Items = append([]models.PayoutItem(nil), p.Items...)
+	return p
+}
@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"errors"
 
 	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,11 +19,31 @@ func NewDriverRepo(pool *pgxpool.Pool) *DriverRepoPG {
 	return &DriverRepoPG{pool: pool}
 }
 
+// driverColumns is selected from drivers d LEFT JOIN vehicles v.
+const driverColumns = `d.driver_id, d.name, d.is_available, d.rating_average, d.rating_count,
+  v.vehicle_type, v.seats, v.plate`
+
+func scanDriver(row pgx.Row) (models.Driver, error) {
+	var (
+		d           models.Driver
+		vehicleType *string
+		seats       *int
+		plate       *string
+	)
+	err := row.Scan(&d.DriverID, &d.Name, &d.IsAvailable, &d.Rating.Average, &d.Rating.Count,
+		&vehicleType, &seats, &plate)
+	if err == nil && vehicleType != nil {
+		d.Vehicle = &models.Vehicle{Type: models.VehicleType(*vehicleType), Seats: *seats, Plate: *plate}
+	}
+	return d, err
+}
+
 func (r *DriverRepoPG) ListAll(ctx context.Context) ([]models.Driver, error) {
 	const q = `
-SELECT driver_id, name, is_available
-FROM drivers
-ORDER BY driver_id;
+SELECT ` + driverColumns + `
+FROM drivers d
+LEFT JOIN vehicles v ON v.driver_id = d.driver_id
+ORDER BY d.driver_id;
 `
 	rows, err := r.pool.Query(ctx, q)
 	if err != nil {
@@ -31,8 +53,8 @@ ORDER BY driver_id;
 
 	drivers := make([]models.Driver, 0, 16)
 	for rows.Next() {
-		var d models.Driver
-		if err := rows.Scan(&d.DriverID, &d.Name, &d.IsAvailable); err != nil {
+		d, err := scanDriver(rows)
+		if err != nil {
 			return nil, err
 		}
 		drivers = append(drivers, d)
@@ -44,9 +66,14 @@ ORDER BY driver_id;
 }
 
 func (r *DriverRepoPG) GetByID(ctx context.Context, driverID string) (models.Driver, bool, error) {
-	const q = `SELECT driver_id, name, is_available FROM drivers WHERE driver_id = $1;`
-	var d models.Driver
-	if err := r.pool.QueryRow(ctx, q, driverID).Scan(&d.DriverID, &d.Name, &d.IsAvailable); err != nil {
+	const q = `
+SELECT ` + driverColumns + `
+FROM drivers d
+LEFT JOIN vehicles v ON v.driver_id = d.driver_id
+WHERE d.driver_id = $1;
+`
+	d, err := scanDriver(r.pool.QueryRow(ctx, q, driverID))
+	if err != nil {
 		if err == pgx.ErrNoRows {
 			return models.Driver{}, false, nil
 		}
@@ -54,3 +81,82 @@ func (r *DriverRepoPG) GetByID(ctx context.Context, driverID string) (models.Dri
 	}
 	return d, true, nil
 }
+
+// errPlateTaken rolls back SetVehicle's transaction.
+var errPlateTaken = errors.New("plate taken")
+
+func (r *DriverRepoPG) SetVehicle(ctx context.Context, driverID string, v models.Vehicle) (bool, error) {
+	// With the driver's old vehicle gone, the only conflict left is another
+	// driver's plate.
+	const remove = `DELETE FROM vehicles WHERE driver_id = $1;`
+	const insert = `
+INSERT INTO vehicles (driver_id, vehicle_type, seats, plate)
+VALUES ($1,$2,$3,$4)
+ON CONFLICT DO NOTHING;
+`
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, remove, driverID); err != nil {
+			return err
+		}
+		cmd, err := tx.Exec(ctx, insert, driverID, string(v.Type), v.Seats, v.Plate)
+		if err != nil {
+			return err
+		}
+		if cmd.RowsAffected() == 0 {
+			return errPlateTaken
+		}
+		return nil
+	})
+	if errors.Is(err, errPlateTaken) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *DriverRepoPG) RecordRating(ctx context.Context, p repository.RecordRatingParams, window int) (bool, error) {
+	// Locking the driver first serializes ratings of the same driver, so each
+	// recompute sees the ratings committed before it.
+	const lock = `SELECT 1 FROM drivers WHERE driver_id = $1 FOR UPDATE;`
+	const insert = `
+INSERT INTO driver_ratings (booking_id, driver_id, stars, rated_at)
+VALUES ($1,$2,$3,$4)
+ON CONFLICT (booking_id) DO NOTHING;
+`
+	const recompute = `
+UPDATE drivers d
+SET rating_average = w.average, rating_count = w.n
+FROM (
+  SELECT COALESCE(ROUND(AVG(stars), 2), 0)::float8 AS average, COUNT(*) AS n
+  FROM (
+    SELECT stars FROM driver_ratings
+    WHERE driver_id = $1
+    ORDER BY rated_at DESC, booking_id DESC
+    LIMIT $2
+  ) latest
+) w
+WHERE d.driver_id = $1;
+`
+	recorded := false
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lock, p.DriverID); err != nil {
+			return err
+		}
+		cmd, err := tx.Exec(ctx, insert, p.BookingID, p.DriverID, p.Stars, p.RatedAt)
+		if err != nil {
+			return err
+		}
+		if cmd.RowsAffected() == 0 {
+			return nil
+		}
+		recorded = true
+		_, err = tx.Exec(ctx, recompute, p.DriverID, window)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return recorded, nil
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"driver_svc/internal/models"
 	"driver_svc/internal/repository"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,31 +21,65 @@ func NewJobRepo(pool *pgxpool.Pool) *JobRepoPG {
 }
 
 // UpsertOpenJob inserts an Open job if it does not already exist (idempotent).
+// Its stops are written with it, so a redelivered event leaves them alone.
 func (r *JobRepoPG) UpsertOpenJob(ctx context.Context, p repository.UpsertJobParams) error {
 	const q = `
 INSERT INTO jobs
-  (booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price, status)
+  (booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price_amount, price_currency, status, scheduled_for, vehicle_type, passengers)
 VALUES
-  ($1,$2,$3,$4,$5,$6,'Open')
+  ($1,$2,$3,$4,$5,$6,$7,'Open',$8,$9,$10)
 ON CONFLICT (booking_id) DO NOTHING;
 `
-	_, err := r.pool.Exec(ctx, q,
-		p.BookingID,
-		p.PickupLoc.Lat, p.PickupLoc.Lng,
-		p.Dropoff.Lat, p.Dropoff.Lng,
-		p.Price,
-	)
-	return err
+	var vehicleType *string
+	if p.VehicleType != "" {
+		t := string(p.VehicleType)
+		vehicleType = &t
+	}
+	const stop = `INSERT INTO job_stops (booking_id, seq, lat, lng) VALUES ($1,$2,$3,$4);`
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		cmd, err := tx.Exec(ctx, q,
+			p.BookingID,
+			p.PickupLoc.Lat, p.PickupLoc.Lng,
+			p.Dropoff.Lat, p.Dropoff.Lng,
+			p.Price.Amount, p.Price.Currency,
+			p.ScheduledFor,
+			vehicleType, max(p.Passengers, 1),
+		)
+		if err != nil || cmd.RowsAffected() == 0 {
+			return err
+		}
+		for i, s := range p.Stops {
+			if _, err := tx.Exec(ctx, stop, p.BookingID, i+1, s.Lat, s.Lng); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *JobRepoPG) ListOpenJobs(ctx context.Context) ([]models.Job, error) {
 	const q = `
-SELECT booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price, status, accepted_driver_id, created_at
+SELECT booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price_amount, price_currency, status, accepted_driver_id, accepted_at, scheduled_for, vehicle_type, passengers, created_at
 FROM jobs
 WHERE status = 'Open'
 ORDER BY created_at DESC;
 `
-	rows, err := r.pool.Query(ctx, q)
+	return r.queryJobs(ctx, q)
+}
+
+func (r *JobRepoPG) ListCreatedSince(ctx context.Context, since time.Time, limit int) ([]models.Job, error) {
+	const q = `
+SELECT booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price_amount, price_currency, status, accepted_driver_id, accepted_at, scheduled_for, vehicle_type, passengers, created_at
+FROM jobs
+WHERE created_at >= $1
+ORDER BY created_at ASC, booking_id ASC
+LIMIT $2;
+`
+	return r.queryJobs(ctx, q, since, limit)
+}
+
+func (r *JobRepoPG) queryJobs(ctx context.Context, q string, args ...any) ([]models.Job, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -52,29 +89,103 @@ ORDER BY created_at DESC;
 	for rows.Next() {
 		var j models.Job
 		var status string
+		var vehicleType *string
 		if err := rows.Scan(
 			&j.BookingID,
 			&j.PickupLoc.Lat, &j.PickupLoc.Lng,
 			&j.Dropoff.Lat, &j.Dropoff.Lng,
-			&j.Price, &status, &j.AcceptedDriverID, &j.CreatedAt,
+			&j.Price.Amount, &j.Price.Currency, &status, &j.AcceptedDriverID, &j.AcceptedAt, &j.ScheduledFor, &vehicleType, &j.Passengers, &j.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
 		j.Status = models.JobStatus(status)
+		if vehicleType != nil {
+			j.VehicleType = models.VehicleType(*vehicleType)
+		}
 		jobs = append(jobs, j)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+	if err := r.loadStops(ctx, jobs); err != nil {
+		return nil, err
+	}
 	return jobs, nil
 }
 
+// loadStops fills in the stops of js with one query.
+func (r *JobRepoPG) loadStops(ctx context.Context, js []models.Job) error {
+	if len(js) == 0 {
+		return nil
+	}
+	ids := make([]string, len(js))
+	byID := make(map[string]int, len(js))
+	for i, j := range js {
+		ids[i] = j.BookingID
+		byID[j.BookingID] = i
+	}
+	const q = `
+SELECT booking_id, lat, lng
+FROM job_stops
+WHERE booking_id = ANY($1)
+ORDER BY booking_id, seq;
+`
+	rows, err := r.pool.Query(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var s models.Location
+		if err := rows.Scan(&id, &s.Lat, &s.Lng); err != nil {
+			return err
+		}
+		i := byID[id]
+		js[i].Stops = append(js[i].Stops, s)
+	}
+	return rows.Err()
+}
+
+func (r *JobRepoPG) GetJob(ctx context.Context, bookingID string) (models.Job, bool, error) {
+	const q = `
+SELECT booking_id, pickuploc_lat, pickuploc_lng, dropoff_lat, dropoff_lng, price_amount, price_currency, status, accepted_driver_id, accepted_at, scheduled_for, vehicle_type, passengers, created_at
+FROM jobs
+WHERE booking_id = $1;
+`
+	var j models.Job
+	var status string
+	var vehicleType *string
+	err := r.pool.QueryRow(ctx, q, bookingID).Scan(
+		&j.BookingID,
+		&j.PickupLoc.Lat, &j.PickupLoc.Lng,
+		&j.Dropoff.Lat, &j.Dropoff.Lng,
+		&j.Price.Amount, &j.Price.Currency, &status, &j.AcceptedDriverID, &j.AcceptedAt, &j.ScheduledFor, &vehicleType, &j.Passengers, &j.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Job{}, false, nil
+	}
+	if err != nil {
+		return models.Job{}, false, err
+	}
+	j.Status = models.JobStatus(status)
+	if vehicleType != nil {
+		j.VehicleType = models.VehicleType(*vehicleType)
+	}
+	js := []models.Job{j}
+	if err := r.loadStops(ctx, js); err != nil {
+		return models.Job{}, false, err
+	}
+	return js[0], true, nil
+}
+
 // TryAccept atomically marks a job as Taken if it is currently Open.
 // Returns true if this call won (rows affected = 1), false if already taken.
 func (r *JobRepoPG) TryAccept(ctx context.Context, bookingID, driverID string) (bool, error) {
 	const q = `
 UPDATE jobs
-SET status = 'Taken', accepted_driver_id = $1
+SET status = 'Taken', accepted_driver_id = $1, accepted_at = NOW()
 WHERE booking_id = $2 AND status = 'Open';
 `
 	cmd, err := r.pool.Exec(ctx, q, driverID, bookingID)
@@ -83,3 +194,17 @@ WHERE booking_id = $2 AND status = 'Open';
 	}
 	return cmd.RowsAffected() == 1, nil
 }
+
+// Cancel marks a job Cancelled unless it already is.
+func (r *JobRepoPG) Cancel(ctx context.Context, bookingID string) (bool, error) {
+	const q = `
+UPDATE jobs
+SET status = 'Cancelled'
+WHERE booking_id = $1 AND status <> 'Cancelled';
+`
+	cmd, err := r.pool.Exec(ctx, q, bookingID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
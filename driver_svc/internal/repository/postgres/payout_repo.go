@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PayoutRepoPG struct {
+	pool *pgxpool.Pool
+}
+
+func NewPayoutRepo(pool *pgxpool.Pool) *PayoutRepoPG {
+	return &PayoutRepoPG{pool: pool}
+}
+
+// errJobCovered rolls back CreatePayout when another payout covers a job.
+var errJobCovered = errors.New("job already covered by a payout")
+
+func (r *PayoutRepoPG) RecordEarning(ctx context.Context, e repository.Earning) (bool, error) {
+	const q = `
+INSERT INTO earnings (booking_id, driver_id, fare_amount, amount, currency, completed_at)
+SELECT booking_id, $2, $3, $4, $5, $6
+FROM jobs
+WHERE booking_id = $1 AND status = 'Taken' AND accepted_driver_id = $2
+ON CONFLICT (booking_id) DO NOTHING;
+`
+	cmd, err := r.pool.Exec(ctx, q, e.BookingID, e.DriverID, e.Fare.Amount, e.Amount.Amount, e.Amount.Currency, e.CompletedAt)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *PayoutRepoPG) ListUnpaidEarnings(ctx context.Context, until time.Time) ([]repository.Earning, error) {
+	const q = `
+SELECT e.booking_id, e.driver_id, e.fare_amount, e.amount, e.currency, e.completed_at
+FROM earnings e
+WHERE e.completed_at < $1
+  AND NOT EXISTS (SELECT 1 FROM payout_items i WHERE i.booking_id = e.booking_id AND i.active)
+ORDER BY e.completed_at ASC, e.booking_id ASC;
+`
+	rows, err := r.pool.Query(ctx, q, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]repository.Earning, 0, 32)
+	for rows.Next() {
+		var e repository.Earning
+		if err := rows.Scan(&e.BookingID, &e.DriverID, &e.Fare.Amount, &e.Amount.Amount, &e.Amount.Currency, &e.CompletedAt); err != nil {
+			return nil, err
+		}
+		e.Fare.Currency = e.Amount.Currency
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *PayoutRepoPG) CreatePayout(ctx context.Context, p repository.CreatePayoutParams) (bool, error) {
+	const insertPayout = `
+INSERT INTO payouts (id, driver_id, amount, currency, period_start, period_end)
+VALUES ($1,$2,$3,$4,$5,$6);
+`
+	// A concurrent run claiming the same job waits on the unique index, then
+	// finds the conflict once the other commits.
+	const insertItem = `
+INSERT INTO payout_items (payout_id, booking_id, fare_amount, amount)
+VALUES ($1,$2,$3,$4)
+ON CONFLICT (booking_id) WHERE active DO NOTHING;
+`
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insertPayout, p.ID, p.DriverID, p.Amount.Amount, p.Amount.Currency, p.PeriodStart, p.PeriodEnd); err != nil {
+			return err
+		}
+		for _, it := range p.Items {
+			cmd, err := tx.Exec(ctx, insertItem, p.ID, it.BookingID, it.Fare.Amount, it.Amount.Amount)
+			if err != nil {
+				return err
+			}
+			if cmd.RowsAffected() == 0 {
+				return errJobCovered
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errJobCovered) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+const payoutColumns = `id, driver_id, amount, currency, status, period_start, period_end, attempts, next_attempt_at, provider_ref, last_error, created_at, paid_at`
+
+func (r *PayoutRepoPG) ClaimDuePayouts(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Payout, error) {
+	const q = `
+UPDATE payouts
+SET next_attempt_at = $2
+WHERE id IN (
+  SELECT id FROM payouts
+  WHERE status = 'pending' AND next_attempt_at <= $1
+  ORDER BY next_attempt_at
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + payoutColumns + `;
+`
+	return r.queryPayouts(ctx, q, now, now.Add(lease), limit)
+}
+
+func (r *PayoutRepoPG) RecordPayoutAttempt(ctx context.Context, payoutID string, res repository.PayoutAttemptResult) error {
+	const q = `
+UPDATE payouts
+SET attempts = attempts + 1,
+    last_error = $2,
+    provider_ref = $3,
+    status = CASE
+      WHEN $3::text IS NOT NULL THEN 'paid'
+      WHEN $4::timestamptz IS NOT NULL THEN 'pending'
+      WHEN $5::boolean THEN 'failed'
+      ELSE 'held'
+    END,
+    next_attempt_at = COALESCE($4, next_attempt_at),
+    paid_at = CASE WHEN $3::text IS NOT NULL THEN NOW() ELSE paid_at END
+WHERE id = $1
+RETURNING status;
+`
+	const release = `UPDATE payout_items SET active = FALSE WHERE payout_id = $1;`
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var status string
+		err := tx.QueryRow(ctx, q, payoutID, res.Error, res.ProviderRef, res.NextAttemptAt, res.Rejected).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if models.PayoutStatus(status) != models.PayoutFailed {
+			return nil
+		}
+		_, err = tx.Exec(ctx, release, payoutID)
+		return err
+	})
+}
+
+func (r *PayoutRepoPG) ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]models.Payout, error) {
+	const q = `
+SELECT ` + payoutColumns + `
+FROM payouts
+WHERE driver_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+`
+	return r.queryPayouts(ctx, q, driverID, limit)
+}
+
+// queryPayouts runs q and loads the items of every payout it returns.
+func (r *PayoutRepoPG) queryPayouts(ctx context.Context, q string, args ...any) ([]models.Payout, error) {
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Payout, 0, 32)
+	index := make(map[string]int)
+	for rows.Next() {
+		var p models.Payout
+		var status string
+		if err := rows.Scan(
+			&p.ID, &p.DriverID, &p.Amount.Amount, &p.Amount.Currency, &status, &p.PeriodStart, &p.PeriodEnd,
+			&p.Attempts, &p.NextAttemptAt, &p.ProviderRef, &p.LastError, &p.CreatedAt, &p.PaidAt,
+		); err != nil {
+			return nil, err
+		}
+		p.Status = models.PayoutStatus(status)
+		p.Items = []models.PayoutItem{}
+		index[p.ID] = len(out)
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	const items = `
+SELECT payout_id, booking_id, fare_amount, amount
+FROM payout_items
+WHERE payout_id = ANY($1)
+ORDER BY payout_id, booking_id;
+`
+	ids := make([]string, len(out))
+	for i, p := range out {
+		ids[i] = p.ID
+	}
+	itemRows, err := r.pool.Query(ctx, items, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+	for itemRows.Next() {
+		var payoutID string
+		var it models.PayoutItem
+		if err := itemRows.Scan(&payoutID, &it.BookingID, &it.Fare.Amount, &it.Amount.Amount); err != nil {
+			return nil, err
+		}
+		p := &out[index[payoutID]]
+		it.Fare.Currency, it.Amount.Currency = p.Amount.Currency, p.Amount.Currency
+		p.Items = append(p.Items, it)
+	}
+	return out, itemRows.Err()
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"driver_svc/internal/db"
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+	"driver_svc/internal/repository/repotest"
+	"driver_svc/internal/seed"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to TEST_DATABASE_URL and migrates it; the suite is skipped
+// when the variable is unset. The database is wiped before every case, so
+// never point it at anything but a scratch database.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	migrator, err := db.NewMigrator(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+func truncate(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), `TRUNCATE drivers, vehicles, driver_ratings, job_stops, jobs, earnings, payouts, payout_items;`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDriverRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.DriverRepository(t, func(t *testing.T, drivers ...models.Driver) repository.DriverRepository {
+		truncate(t, pool)
+		if err := seed.UpsertDrivers(context.Background(), pool, drivers...); err != nil {
+			t.Fatal(err)
+		}
+		return NewDriverRepo(pool)
+	})
+}
+
+func TestJobRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.JobRepository(t, func(t *testing.T) repository.JobRepository {
+		truncate(t, pool)
+		return NewJobRepo(pool)
+	})
+}
+
+func TestPayoutRepoPG(t *testing.T) {
+	pool := testPool(t)
+	repotest.PayoutRepository(t, func(t *testing.T) (repository.JobRepository, repository.PayoutRepository) {
+		truncate(t, pool)
+		return NewJobRepo(pool), NewPayoutRepo(pool)
+	})
+}
@@ -2,24 +2,113 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"driver_svc/internal/models"
+	"driver_svc/internal/money"
 )
 
 type DriverRepository interface {
 	ListAll(ctx context.Context) ([]models.Driver, error)
 	GetByID(ctx context.Context, driverID string) (models.Driver, bool, error)
+	// SetVehicle registers v as the registered driver's vehicle, replacing
+	// any other.
+	// It reports false, changing nothing, if another driver's vehicle has
+	// the same plate.
+	SetVehicle(ctx context.Context, driverID string, v models.Vehicle) (bool, error)
+	// RecordRating stores a rider's rating of a driver and recomputes the
+	// driver's average over their latest window ratings, newest by RatedAt.
+	// It reports false, changing nothing, if the booking's rating is already
+	// recorded.
+	RecordRating(ctx context.Context, p RecordRatingParams, window int) (bool, error)
+}
+
+type RecordRatingParams struct {
+	BookingID string
+	DriverID  string
+	Stars     int
+	RatedAt   time.Time
 }
 
 type UpsertJobParams struct {
 	BookingID string
 	PickupLoc models.Location
 	Dropoff   models.Location
-	Price     int
+	Stops     []models.Location
+	Price     money.Money
+	// VehicleType, if set, is the only vehicle type that may take the job.
+	// Passengers of 0, from events published before it was sent, is
+	// stored as one.
+	VehicleType models.VehicleType
+	Passengers  int
+	// ScheduledFor is set for a reserved ride.
+	ScheduledFor *time.Time
 }
 
 type JobRepository interface {
 	UpsertOpenJob(ctx context.Context, p UpsertJobParams) error
 	ListOpenJobs(ctx context.Context) ([]models.Job, error)
+	GetJob(ctx context.Context, bookingID string) (models.Job, bool, error)
+	// ListCreatedSince returns up to limit jobs of any status created at or
+	// after since, oldest first, ties broken by booking_id.
+	ListCreatedSince(ctx context.Context, since time.Time, limit int) ([]models.Job, error)
 	TryAccept(ctx context.Context, bookingID string, driverID string) (bool, error)
+	// Cancel marks a job Cancelled, whatever its status, so it can no longer
+	// be taken. It reports false if the job is missing or already Cancelled.
+	Cancel(ctx context.Context, bookingID string) (bool, error)
+}
+
+// Earning is what booking_svc's ledger credited a driver for a completed
+// trip, as carried by booking.completed.
+type Earning struct {
+	BookingID string
+	DriverID  string
+	// Fare is the trip's final fare before any discount; Amount is the
+	// driver's part of it.
+	Fare        money.Money
+	Amount      money.Money
+	CompletedAt time.Time
+}
+
+type CreatePayoutParams struct {
+	ID          string
+	DriverID    string
+	Amount      money.Money
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Items       []models.PayoutItem
+}
+
+// PayoutAttemptResult records the outcome of one call to the payout provider.
+// A nil ProviderRef with a nil NextAttemptAt marks the payout as failed if
+// Rejected, and as held otherwise.
+type PayoutAttemptResult struct {
+	ProviderRef   *string
+	Error         *string
+	NextAttemptAt *time.Time
+	Rejected      bool
+}
+
+type PayoutRepository interface {
+	// RecordEarning stores the earning of a completed trip. It reports false,
+	// storing nothing, if the job is not Taken by e.DriverID or its earning
+	// is already recorded.
+	RecordEarning(ctx context.Context, e Earning) (bool, error)
+	// ListUnpaidEarnings returns the earnings of trips completed before until
+	// that no pending, paid or held payout covers, oldest first, ties broken by
+	// booking_id.
+	ListUnpaidEarnings(ctx context.Context, until time.Time) ([]Earning, error)
+	// CreatePayout stores a pending payout, due now, with its items. It
+	// reports false and stores nothing if another pending, paid or held payout
+	// already covers one of the jobs.
+	CreatePayout(ctx context.Context, p CreatePayoutParams) (bool, error)
+	// ClaimDuePayouts returns pending payouts whose next attempt is due and
+	// pushes their next_attempt_at forward by lease so concurrent runs skip them.
+	ClaimDuePayouts(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Payout, error)
+	// RecordPayoutAttempt counts an attempt. A failed payout releases its
+	// jobs; a held one keeps them.
+	RecordPayoutAttempt(ctx context.Context, payoutID string, res PayoutAttemptResult) error
+	// ListDriverPayouts returns up to limit of a driver's payouts, newest
+	// first, each with its items ordered by booking_id.
+	ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]models.Payout, error)
 }
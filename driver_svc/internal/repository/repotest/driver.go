@@ -0,0 +1,160 @@
+package repotest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/repository"
+)
+
+// DriverRepository runs the conformance suite against implementations made
+// by newRepo, which must start out holding exactly drivers.
+func DriverRepository(t *testing.T, newRepo func(t *testing.T, drivers ...models.Driver) repository.DriverRepository) {
+	t.Run("list ordered by id", func(t *testing.T) {
+		repo := newRepo(t,
+			models.Driver{DriverID: "d-2", Name: "Ravi", IsAvailable: true},
+			models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: false},
+		)
+		got, err := repo.ListAll(ctx(t))
+		must(t, err)
+		want := []models.Driver{{DriverID: "d-1", Name: "Asha"}, {DriverID: "d-2", Name: "Ravi", IsAvailable: true}}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("ListAll: %+v", got)
+		}
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		repo, c := newRepo(t, models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true}), ctx(t)
+		d, ok, err := repo.GetByID(c, "d-1")
+		must(t, err)
+		if !ok || d != (models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true}) {
+			t.Fatalf("GetByID: ok=%v %+v", ok, d)
+		}
+		if _, ok, err := repo.GetByID(c, "missing"); ok || err != nil {
+			t.Fatalf("GetByID(missing): ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("vehicles", func(t *testing.T) {
+		sedan := models.Vehicle{Type: models.VehicleSedan, Seats: 4, Plate: "KA01AB1234"}
+		repo, c := newRepo(t,
+			models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true, Vehicle: &sedan},
+			models.Driver{DriverID: "d-2", Name: "Ravi", IsAvailable: true},
+		), ctx(t)
+		vehicleOf := func(t *testing.T, driverID string) *models.Vehicle {
+			t.Helper()
+			d, ok, err := repo.GetByID(c, driverID)
+			must(t, err)
+			if !ok {
+				t.Fatalf("driver %s missing", driverID)
+			}
+			return d.Vehicle
+		}
+		if v := vehicleOf(t, "d-1"); v == nil || *v != sedan {
+			t.Fatalf("seeded vehicle: %+v", v)
+		}
+		if v := vehicleOf(t, "d-2"); v != nil {
+			t.Fatalf("d-2 has no vehicle yet: %+v", v)
+		}
+
+		// d-2 cannot take d-1's plate.
+		set, err := repo.SetVehicle(c, "d-2", models.Vehicle{Type: models.VehicleSUV, Seats: 6, Plate: sedan.Plate})
+		must(t, err)
+		if set || vehicleOf(t, "d-2") != nil {
+			t.Fatal("SetVehicle must refuse another driver's plate")
+		}
+
+		suv := models.Vehicle{Type: models.VehicleSUV, Seats: 6, Plate: "KA05XY0001"}
+		set, err = repo.SetVehicle(c, "d-2", suv)
+		must(t, err)
+		if !set {
+			t.Fatal("SetVehicle refused a free plate")
+		}
+		// A driver may keep their own plate with a new vehicle.
+		auto := models.Vehicle{Type: models.VehicleAuto, Seats: 3, Plate: sedan.Plate}
+		set, err = repo.SetVehicle(c, "d-1", auto)
+		must(t, err)
+		if !set {
+			t.Fatal("SetVehicle refused the driver's own plate")
+		}
+
+		all, err := repo.ListAll(c)
+		must(t, err)
+		if len(all) != 2 || all[0].Vehicle == nil || *all[0].Vehicle != auto || all[1].Vehicle == nil || *all[1].Vehicle != suv {
+			t.Fatalf("ListAll: %+v", all)
+		}
+		all[0].Vehicle.Seats = 99
+		if v := vehicleOf(t, "d-1"); v.Seats != 3 {
+			t.Fatalf("ListAll must not share stored vehicles: %+v", v)
+		}
+	})
+
+	driverRatings(t, newRepo)
+}
+
+func driverRatings(t *testing.T, newRepo func(t *testing.T, drivers ...models.Driver) repository.DriverRepository) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	rate := func(t *testing.T, repo repository.DriverRepository, bookingID, driverID string, stars int, ratedAt time.Time, window int) bool {
+		t.Helper()
+		ok, err := repo.RecordRating(ctx(t), repository.RecordRatingParams{BookingID: bookingID, DriverID: driverID, Stars: stars, RatedAt: ratedAt}, window)
+		must(t, err)
+		return ok
+	}
+	ratingOf := func(t *testing.T, repo repository.DriverRepository, driverID string) models.DriverRating {
+		t.Helper()
+		d, ok, err := repo.GetByID(ctx(t), driverID)
+		must(t, err)
+		if !ok {
+			t.Fatalf("driver %s missing", driverID)
+		}
+		return d.Rating
+	}
+
+	t.Run("unrated driver", func(t *testing.T) {
+		repo := newRepo(t, models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true})
+		if got := ratingOf(t, repo, "d-1"); got != (models.DriverRating{}) {
+			t.Fatalf("rating = %+v, want zero", got)
+		}
+	})
+
+	t.Run("average is rounded and listed", func(t *testing.T) {
+		repo := newRepo(t, models.Driver{DriverID: "d-1", Name: "Asha", IsAvailable: true}, models.Driver{DriverID: "d-2", Name: "Ravi"})
+		for i, stars := range []int{5, 4, 4} {
+			if !rate(t, repo, fmt.Sprintf("b-%d", i), "d-1", stars, at.Add(time.Duration(i)*time.Minute), 100) {
+				t.Fatalf("rating %d not recorded", i)
+			}
+		}
+		if got := ratingOf(t, repo, "d-1"); got != (models.DriverRating{Average: 4.33, Count: 3}) {
+			t.Fatalf("rating = %+v, want 4.33 over 3", got)
+		}
+		list, err := repo.ListAll(ctx(t))
+		must(t, err)
+		if list[0].Rating.Count != 3 || list[1].Rating != (models.DriverRating{}) {
+			t.Fatalf("ListAll: %+v", list)
+		}
+	})
+
+	t.Run("a booking is rated once", func(t *testing.T) {
+		repo := newRepo(t, models.Driver{DriverID: "d-1", Name: "Asha"})
+		rate(t, repo, "b-1", "d-1", 5, at, 100)
+		if rate(t, repo, "b-1", "d-1", 1, at.Add(time.Minute), 100) {
+			t.Fatal("second rating of b-1 recorded")
+		}
+		if got := ratingOf(t, repo, "d-1"); got != (models.DriverRating{Average: 5, Count: 1}) {
+			t.Fatalf("rating = %+v", got)
+		}
+	})
+
+	t.Run("only the latest window counts", func(t *testing.T) {
+		repo := newRepo(t, models.Driver{DriverID: "d-1", Name: "Asha"})
+		// Delivered out of order: the 1-star rating is the oldest.
+		rate(t, repo, "b-3", "d-1", 4, at.Add(2*time.Minute), 2)
+		rate(t, repo, "b-2", "d-1", 5, at.Add(time.Minute), 2)
+		rate(t, repo, "b-1", "d-1", 1, at, 2)
+		if got := ratingOf(t, repo, "d-1"); got != (models.DriverRating{Average: 4.5, Count: 2}) {
+			t.Fatalf("rating = %+v, want 4.5 over the latest 2", got)
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package repotest
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/repository"
+)
+
+var fare = money.Money{Amount: 22050, Currency: "INR"}
+
+func newJob(id string) repository.UpsertJobParams {
+	return repository.UpsertJobParams{
+		BookingID: id,
+		PickupLoc: models.Location{Lat: 12.9, Lng: 77.6},
+		Dropoff:   models.Location{Lat: 12.95, Lng: 77.64},
+		Price:     fare,
+	}
+}
+
+func jobIDs(js []models.Job) []string {
+	ids := make([]string, len(js))
+	for i, j := range js {
+		ids[i] = j.BookingID
+	}
+	return ids
+}
+
+func equalIDs(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// JobRepository runs the conformance suite against implementations made by newRepo.
+func JobRepository(t *testing.T, newRepo func(t *testing.T) repository.JobRepository) {
+	t.Run("upsert is idempotent", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		must(t, repo.UpsertOpenJob(c, newJob("b-1")))
+		first, ok, err := repo.GetJob(c, "b-1")
+		must(t, err)
+		if !ok || first.Status != models.JobStatusOpen || first.Price != fare || first.PickupLoc.Lat != 12.9 ||
+			first.Dropoff.Lng != 77.64 || first.AcceptedDriverID != nil || first.CreatedAt.IsZero() {
+			t.Fatalf("unexpected job: ok=%v %+v", ok, first)
+		}
+
+		tick()
+		again := newJob("b-1")
+		again.Price = money.Money{Amount: 99900, Currency: "INR"}
+		must(t, repo.UpsertOpenJob(c, again))
+		got, _, err := repo.GetJob(c, "b-1")
+		must(t, err)
+		if got.Price != fare || !sameInstant(got.CreatedAt, first.CreatedAt) {
+			t.Fatalf("redelivered event must not change the job: %+v", got)
+		}
+		if _, ok, err := repo.GetJob(c, "missing"); ok || err != nil {
+			t.Fatalf("GetJob(missing): ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("reserved rides keep their pickup time", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		at := time.Now().Add(20 * time.Minute)
+		p := newJob("b-1")
+		p.ScheduledFor = &at
+		must(t, repo.UpsertOpenJob(c, p))
+		must(t, repo.UpsertOpenJob(c, newJob("b-2")))
+
+		got, _, err := repo.GetJob(c, "b-1")
+		must(t, err)
+		if got.ScheduledFor == nil || !sameInstant(*got.ScheduledFor, at) {
+			t.Fatalf("scheduled_for: %+v", got.ScheduledFor)
+		}
+		open, err := repo.ListOpenJobs(c)
+		must(t, err)
+		for _, j := range open {
+			if (j.BookingID == "b-1") != (j.ScheduledFor != nil) {
+				t.Fatalf("ListOpenJobs: %s scheduled_for %v", j.BookingID, j.ScheduledFor)
+			}
+		}
+	})
+
+	t.Run("multi-stop rides keep their stops in order", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		stops := []models.Location{{Lat: 12.92, Lng: 77.61}, {Lat: 12.93, Lng: 77.62}}
+		p := newJob("b-1")
+		p.Stops = stops
+		must(t, repo.UpsertOpenJob(c, p))
+		must(t, repo.UpsertOpenJob(c, p))
+		must(t, repo.UpsertOpenJob(c, newJob("b-2")))
+
+		got, _, err := repo.GetJob(c, "b-1")
+		must(t, err)
+		if !slices.Equal(got.Stops, stops) {
+			t.Fatalf("stops: %+v", got.Stops)
+		}
+		got.Stops[0].Lat = 0
+		open, err := repo.ListOpenJobs(c)
+		must(t, err)
+		for _, j := range open {
+			want := stops
+			if j.BookingID == "b-2" {
+				want = nil
+			}
+			if !slices.Equal(j.Stops, want) {
+				t.Fatalf("ListOpenJobs: %s stops %+v", j.BookingID, j.Stops)
+			}
+		}
+	})
+
+	t.Run("requested vehicle and passengers are kept", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		p := newJob("b-1")
+		p.VehicleType, p.Passengers = models.VehicleSUV, 5
+		must(t, repo.UpsertOpenJob(c, p))
+		must(t, repo.UpsertOpenJob(c, newJob("b-2")))
+
+		got, _, err := repo.GetJob(c, "b-1")
+		must(t, err)
+		if got.VehicleType != models.VehicleSUV || got.Passengers != 5 {
+			t.Fatalf("GetJob: vehicle_type=%q passengers=%d", got.VehicleType, got.Passengers)
+		}
+		open, err := repo.ListOpenJobs(c)
+		must(t, err)
+		for _, j := range open {
+			want := models.Job{VehicleType: models.VehicleSUV, Passengers: 5}
+			if j.BookingID == "b-2" {
+				// Older events carried no passenger count.
+				want = models.Job{Passengers: 1}
+			}
+			if j.VehicleType != want.VehicleType || j.Passengers != want.Passengers {
+				t.Fatalf("ListOpenJobs: %s vehicle_type=%q passengers=%d", j.BookingID, j.VehicleType, j.Passengers)
+			}
+		}
+	})
+
+	t.Run("open jobs newest first", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		for _, id := range []string{"b-1", "b-2", "b-3"} {
+			must(t, repo.UpsertOpenJob(c, newJob(id)))
+			tick()
+		}
+		ok, err := repo.TryAccept(c, "b-2", "d-1")
+		must(t, err)
+		if !ok {
+			t.Fatal("TryAccept on an open job must win")
+		}
+
+		open, err := repo.ListOpenJobs(c)
+		must(t, err)
+		if ids := jobIDs(open); !equalIDs(ids, "b-3", "b-1") {
+			t.Fatalf("ListOpenJobs: %v", ids)
+		}
+	})
+
+	t.Run("list created since, oldest first", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		var created []models.Job
+		for _, id := range []string{"b-1", "b-2", "b-3", "b-4"} {
+			must(t, repo.UpsertOpenJob(c, newJob(id)))
+			j, _, err := repo.GetJob(c, id)
+			must(t, err)
+			created = append(created, j)
+			tick()
+		}
+		_, err := repo.TryAccept(c, "b-2", "d-1")
+		must(t, err)
+
+		all, err := repo.ListCreatedSince(c, created[0].CreatedAt.Add(-time.Second), 10)
+		must(t, err)
+		if ids := jobIDs(all); !equalIDs(ids, "b-1", "b-2", "b-3", "b-4") {
+			t.Fatalf("ListCreatedSince: %v", ids)
+		}
+		if all[1].Status != models.JobStatusTaken || all[1].AcceptedDriverID == nil || *all[1].AcceptedDriverID != "d-1" {
+			t.Fatalf("taken jobs must be listed with their driver: %+v", all[1])
+		}
+		page, err := repo.ListCreatedSince(c, created[1].CreatedAt, 2)
+		must(t, err)
+		if ids := jobIDs(page); !equalIDs(ids, "b-2", "b-3") {
+			t.Fatalf("ListCreatedSince page: %v", ids)
+		}
+		none, err := repo.ListCreatedSince(c, created[3].CreatedAt.Add(time.Second), 10)
+		must(t, err)
+		if len(none) != 0 {
+			t.Fatalf("ListCreatedSince(future): %v", jobIDs(none))
+		}
+	})
+
+	t.Run("accept once", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		must(t, repo.UpsertOpenJob(c, newJob("b-1")))
+
+		if ok, err := repo.TryAccept(c, "b-1", "d-1"); !ok || err != nil {
+			t.Fatalf("first TryAccept: ok=%v err=%v", ok, err)
+		}
+		if ok, err := repo.TryAccept(c, "b-1", "d-2"); ok || err != nil {
+			t.Fatalf("second TryAccept: ok=%v err=%v", ok, err)
+		}
+		if ok, err := repo.TryAccept(c, "missing", "d-1"); ok || err != nil {
+			t.Fatalf("TryAccept(missing): ok=%v err=%v", ok, err)
+		}
+		got, _, err := repo.GetJob(c, "b-1")
+		must(t, err)
+		if got.Status != models.JobStatusTaken || got.AcceptedDriverID == nil || *got.AcceptedDriverID != "d-1" ||
+			got.AcceptedAt == nil || got.AcceptedAt.Before(got.CreatedAt) {
+			t.Fatalf("unexpected job after accept: %+v", got)
+		}
+		// A taken job stays taken when booking.created is redelivered.
+		must(t, repo.UpsertOpenJob(c, newJob("b-1")))
+		if again, _, _ := repo.GetJob(c, "b-1"); again.Status != models.JobStatusTaken {
+			t.Fatalf("upsert reopened a taken job: %+v", again)
+		}
+	})
+
+	t.Run("cancel withdraws a job once", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		must(t, repo.UpsertOpenJob(c, newJob("b-1")))
+		must(t, repo.UpsertOpenJob(c, newJob("b-2")))
+		_, err := repo.TryAccept(c, "b-2", "d-1")
+		must(t, err)
+
+		for _, id := range []string{"b-1", "b-2"} {
+			if ok, err := repo.Cancel(c, id); !ok || err != nil {
+				t.Fatalf("Cancel(%s): ok=%v err=%v", id, ok, err)
+			}
+			if ok, err := repo.Cancel(c, id); ok || err != nil {
+				t.Fatalf("second Cancel(%s): ok=%v err=%v", id, ok, err)
+			}
+		}
+		if ok, err := repo.Cancel(c, "missing"); ok || err != nil {
+			t.Fatalf("Cancel(missing): ok=%v err=%v", ok, err)
+		}
+		if ok, err := repo.TryAccept(c, "b-1", "d-2"); ok || err != nil {
+			t.Fatalf("TryAccept on a cancelled job: ok=%v err=%v", ok, err)
+		}
+		// Redelivering booking.created must not reopen a cancelled job.
+		must(t, repo.UpsertOpenJob(c, newJob("b-1")))
+		open, err := repo.ListOpenJobs(c)
+		must(t, err)
+		if len(open) != 0 {
+			t.Fatalf("cancelled jobs listed as open: %v", jobIDs(open))
+		}
+		got, _, err := repo.GetJob(c, "b-2")
+		must(t, err)
+		if got.Status != models.JobStatusCancelled || got.AcceptedDriverID == nil || *got.AcceptedDriverID != "d-1" {
+			t.Fatalf("cancelling a taken job must keep its driver: %+v", got)
+		}
+	})
+
+	t.Run("first writer wins under concurrency", func(t *testing.T) {
+		repo, c := newRepo(t), ctx(t)
+		must(t, repo.UpsertOpenJob(c, newJob("b-1")))
+
+		const n = 16
+		var wg sync.WaitGroup
+		wins := make(chan string, n)
+		for i := 0; i < n; i++ {
+			driverID := fmt.Sprintf("d-%d", i)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, err := repo.TryAccept(c, "b-1", driverID); err == nil && ok {
+					wins <- driverID
+				}
+			}()
+		}
+		wg.Wait()
+		close(wins)
+		var winners []string
+		for w := range wins {
+			winners = append(winners, w)
+		}
+		got, _, _ := repo.GetJob(c, "b-1")
+		if len(winners) != 1 || got.AcceptedDriverID == nil || *got.AcceptedDriverID != winners[0] {
+			t.Fatalf("want exactly one winner stored, got winners=%v job=%+v", winners, got)
+		}
+	})
+}
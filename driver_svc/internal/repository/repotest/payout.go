@@ -0,0 +1,241 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/repository"
+)
+
+// earned is the driver's part of fare.
+var earned = money.Money{Amount: 17640, Currency: fare.Currency}
+
+// complete has driverID take a new job and records the trip's earning, as
+// booking.completed does.
+func complete(t *testing.T, jobs repository.JobRepository, repo repository.PayoutRepository, bookingID, driverID string) repository.Earning {
+	t.Helper()
+	c := ctx(t)
+	must(t, jobs.UpsertOpenJob(c, newJob(bookingID)))
+	if ok, err := jobs.TryAccept(c, bookingID, driverID); !ok || err != nil {
+		t.Fatalf("TryAccept(%s): ok=%v err=%v", bookingID, ok, err)
+	}
+	e := repository.Earning{BookingID: bookingID, DriverID: driverID, Fare: fare, Amount: earned, CompletedAt: time.Now()}
+	if ok, err := repo.RecordEarning(c, e); !ok || err != nil {
+		t.Fatalf("RecordEarning(%s): ok=%v err=%v", bookingID, ok, err)
+	}
+	return e
+}
+
+func newPayout(id, driverID string, until time.Time, es ...repository.Earning) repository.CreatePayoutParams {
+	p := repository.CreatePayoutParams{ID: id, DriverID: driverID, Amount: money.Money{Currency: fare.Currency}, PeriodEnd: until}
+	for _, e := range es {
+		p.Items = append(p.Items, models.PayoutItem{BookingID: e.BookingID, Fare: e.Fare, Amount: e.Amount})
+		p.Amount.Amount += e.Amount.Amount
+		if p.PeriodStart.IsZero() || e.CompletedAt.Before(p.PeriodStart) {
+			p.PeriodStart = e.CompletedAt
+		}
+	}
+	return p
+}
+
+func earningIDs(es []repository.Earning) []string {
+	ids := make([]string, len(es))
+	for i, e := range es {
+		ids[i] = e.BookingID
+	}
+	return ids
+}
+
+// PayoutRepository runs the conformance suite against implementations made by
+// newRepos, which returns a payout repository over the jobs it returns.
+func PayoutRepository(t *testing.T, newRepos func(t *testing.T) (repository.JobRepository, repository.PayoutRepository)) {
+	t.Run("earnings are recorded once for the driver who took the job", func(t *testing.T) {
+		jobs, repo := newRepos(t)
+		c := ctx(t)
+		e := complete(t, jobs, repo, "b-1", "d-1")
+		if ok, err := repo.RecordEarning(c, e); ok || err != nil {
+			t.Fatalf("recording again: ok=%v err=%v", ok, err)
+		}
+		must(t, jobs.UpsertOpenJob(c, newJob("b-2")))
+		other := repository.Earning{BookingID: "b-2", DriverID: "d-1", Fare: fare, Amount: earned, CompletedAt: time.Now()}
+		if ok, err := repo.RecordEarning(c, other); ok || err != nil {
+			t.Fatalf("an Open job: ok=%v err=%v", ok, err)
+		}
+		if ok, err := jobs.TryAccept(c, "b-2", "d-2"); !ok || err != nil {
+			t.Fatalf("TryAccept(b-2): ok=%v err=%v", ok, err)
+		}
+		if ok, err := repo.RecordEarning(c, other); ok || err != nil {
+			t.Fatalf("another driver's job: ok=%v err=%v", ok, err)
+		}
+		other.BookingID = "b-missing"
+		if ok, err := repo.RecordEarning(c, other); ok || err != nil {
+			t.Fatalf("a missing job: ok=%v err=%v", ok, err)
+		}
+	})
+
+	t.Run("unpaid earnings are trips completed before until", func(t *testing.T) {
+		jobs, repo := newRepos(t)
+		c := ctx(t)
+		b1 := complete(t, jobs, repo, "b-1", "d-1")
+		tick()
+		// Taken but not completed: nothing is owed yet.
+		must(t, jobs.UpsertOpenJob(c, newJob("b-2")))
+		if ok, err := jobs.TryAccept(c, "b-2", "d-1"); !ok || err != nil {
+			t.Fatalf("TryAccept(b-2): ok=%v err=%v", ok, err)
+		}
+		tick()
+		cutoff := time.Now()
+		tick()
+		b3 := complete(t, jobs, repo, "b-3", "d-1")
+
+		got, err := repo.ListUnpaidEarnings(c, cutoff)
+		must(t, err)
+		if ids := earningIDs(got); !equalIDs(ids, "b-1") {
+			t.Fatalf("ListUnpaidEarnings(cutoff): %v", ids)
+		}
+		if e := got[0]; e.DriverID != "d-1" || e.Fare != fare || e.Amount != earned || !sameInstant(e.CompletedAt, b1.CompletedAt) {
+			t.Fatalf("earning: %+v", e)
+		}
+		all, err := repo.ListUnpaidEarnings(c, b3.CompletedAt.Add(time.Second))
+		must(t, err)
+		if ids := earningIDs(all); !equalIDs(ids, "b-1", "b-3") {
+			t.Fatalf("ListUnpaidEarnings(later): %v", ids)
+		}
+	})
+
+	t.Run("a trip is covered by one payout", func(t *testing.T) {
+		jobs, repo := newRepos(t)
+		c := ctx(t)
+		b1, b2 := complete(t, jobs, repo, "b-1", "d-1"), complete(t, jobs, repo, "b-2", "d-1")
+		until := b2.CompletedAt.Add(time.Second)
+
+		if ok, err := repo.CreatePayout(c, newPayout("p-1", "d-1", until, b1)); !ok || err != nil {
+			t.Fatalf("CreatePayout(p-1): ok=%v err=%v", ok, err)
+		}
+		if ok, err := repo.CreatePayout(c, newPayout("p-2", "d-1", until, b2, b1)); ok || err != nil {
+			t.Fatalf("CreatePayout over a covered job: ok=%v err=%v", ok, err)
+		}
+		left, err := repo.ListUnpaidEarnings(c, until)
+		must(t, err)
+		if ids := earningIDs(left); !equalIDs(ids, "b-2") {
+			t.Fatalf("a rejected payout must store nothing, unpaid: %v", ids)
+		}
+		list, err := repo.ListDriverPayouts(c, "d-1", 10)
+		must(t, err)
+		if len(list) != 1 {
+			t.Fatalf("ListDriverPayouts: %+v", list)
+		}
+		p := list[0]
+		if p.ID != "p-1" || p.Status != models.PayoutPending || p.Amount.Amount != 17640 || p.Attempts != 0 ||
+			!sameInstant(p.PeriodStart, b1.CompletedAt) || !sameInstant(p.PeriodEnd, until) || p.CreatedAt.IsZero() ||
+			len(p.Items) != 1 || p.Items[0].BookingID != "b-1" || p.Items[0].Fare != fare || p.Items[0].Amount.Amount != 17640 {
+			t.Fatalf("stored payout: %+v", p)
+		}
+	})
+
+	t.Run("claim, retry and pay", func(t *testing.T) {
+		jobs, repo := newRepos(t)
+		c := ctx(t)
+		b1 := complete(t, jobs, repo, "b-1", "d-1")
+		_, err := repo.CreatePayout(c, newPayout("p-1", "d-1", b1.CompletedAt.Add(time.Second), b1))
+		must(t, err)
+
+		now := time.Now().Add(time.Minute)
+		due, err := repo.ClaimDuePayouts(c, now, time.Minute, 10)
+		must(t, err)
+		if len(due) != 1 || due[0].ID != "p-1" || due[0].DriverID != "d-1" || due[0].Amount.Amount != 17640 {
+			t.Fatalf("ClaimDuePayouts: %+v", due)
+		}
+		if again, err := repo.ClaimDuePayouts(c, now, time.Minute, 10); err != nil || len(again) != 0 {
+			t.Fatalf("a claimed payout is leased: %+v err=%v", again, err)
+		}
+
+		msg, retryAt := "provider timeout", now.Add(time.Hour)
+		must(t, repo.RecordPayoutAttempt(c, "p-1", repository.PayoutAttemptResult{Error: &msg, NextAttemptAt: &retryAt}))
+		if early, _ := repo.ClaimDuePayouts(c, retryAt.Add(-time.Second), time.Minute, 10); len(early) != 0 {
+			t.Fatalf("claimed before its retry is due: %+v", early)
+		}
+		due, err = repo.ClaimDuePayouts(c, retryAt, time.Minute, 10)
+		must(t, err)
+		if len(due) != 1 || due[0].Attempts != 1 || due[0].LastError == nil || *due[0].LastError != msg {
+			t.Fatalf("retry: %+v", due)
+		}
+
+		ref := "po_1"
+		must(t, repo.RecordPayoutAttempt(c, "p-1", repository.PayoutAttemptResult{ProviderRef: &ref}))
+		list, err := repo.ListDriverPayouts(c, "d-1", 10)
+		must(t, err)
+		if p := list[0]; p.Status != models.PayoutPaid || p.Attempts != 2 || p.ProviderRef == nil || *p.ProviderRef != ref ||
+			p.PaidAt == nil || p.LastError != nil {
+			t.Fatalf("paid payout: %+v", p)
+		}
+		if after, _ := repo.ClaimDuePayouts(c, retryAt.Add(24*time.Hour), time.Minute, 10); len(after) != 0 {
+			t.Fatalf("paid payouts are never claimed: %+v", after)
+		}
+		if left, _ := repo.ListUnpaidEarnings(c, b1.CompletedAt.Add(time.Second)); len(left) != 0 {
+			t.Fatalf("a paid job is not unpaid: %v", earningIDs(left))
+		}
+	})
+
+	t.Run("a held payout keeps its trips", func(t *testing.T) {
+		jobs, repo := newRepos(t)
+		c := ctx(t)
+		b1 := complete(t, jobs, repo, "b-1", "d-1")
+		until := b1.CompletedAt.Add(time.Second)
+		_, err := repo.CreatePayout(c, newPayout("p-1", "d-1", until, b1))
+		must(t, err)
+
+		msg := "provider timeout"
+		must(t, repo.RecordPayoutAttempt(c, "p-1", repository.PayoutAttemptResult{Error: &msg}))
+		if left, _ := repo.ListUnpaidEarnings(c, until); len(left) != 0 {
+			t.Fatalf("a held job is not unpaid: %v", earningIDs(left))
+		}
+		if due, _ := repo.ClaimDuePayouts(c, until.Add(24*time.Hour), time.Minute, 10); len(due) != 0 {
+			t.Fatalf("held payouts are never claimed: %+v", due)
+		}
+		tick()
+		if ok, err := repo.CreatePayout(c, newPayout("p-2", "d-1", until, b1)); ok || err != nil {
+			t.Fatalf("CreatePayout over a held job: ok=%v err=%v", ok, err)
+		}
+		list, err := repo.ListDriverPayouts(c, "d-1", 10)
+		must(t, err)
+		if len(list) != 1 || list[0].Status != models.PayoutHeld || len(list[0].Items) != 1 {
+			t.Fatalf("held payout: %+v", list)
+		}
+	})
+
+	t.Run("a failed payout releases its trips", func(t *testing.T) {
+		jobs, repo := newRepos(t)
+		c := ctx(t)
+		b1 := complete(t, jobs, repo, "b-1", "d-1")
+		until := b1.CompletedAt.Add(time.Second)
+		_, err := repo.CreatePayout(c, newPayout("p-1", "d-1", until, b1))
+		must(t, err)
+
+		msg := "account closed"
+		must(t, repo.RecordPayoutAttempt(c, "p-1", repository.PayoutAttemptResult{Error: &msg, Rejected: true}))
+		left, err := repo.ListUnpaidEarnings(c, until)
+		must(t, err)
+		if ids := earningIDs(left); !equalIDs(ids, "b-1") {
+			t.Fatalf("unpaid after failure: %v", ids)
+		}
+		tick()
+		if ok, err := repo.CreatePayout(c, newPayout("p-2", "d-1", until, b1)); !ok || err != nil {
+			t.Fatalf("CreatePayout after a failure: ok=%v err=%v", ok, err)
+		}
+		list, err := repo.ListDriverPayouts(c, "d-1", 10)
+		must(t, err)
+		if len(list) != 2 || list[0].ID != "p-2" || list[1].ID != "p-1" || list[1].Status != models.PayoutFailed ||
+			len(list[1].Items) != 1 {
+			t.Fatalf("payouts newest first, failed one kept with its items: %+v", list)
+		}
+		if one, _ := repo.ListDriverPayouts(c, "d-1", 1); len(one) != 1 || one[0].ID != "p-2" {
+			t.Fatalf("ListDriverPayouts limit: %+v", one)
+		}
+		if none, _ := repo.ListDriverPayouts(c, "d-2", 10); len(none) != 0 {
+			t.Fatalf("another driver's payouts: %+v", none)
+		}
+	})
+}
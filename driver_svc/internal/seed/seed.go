@@ -3,22 +3,46 @@ package seed
 import (
 	"context"
 
+	"driver_svc/internal/models"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Drivers is the fixed roster every environment starts with.
+var Drivers = []models.Driver{
+	{DriverID: "d-1", Name: "Asha", IsAvailable: true,
+		Vehicle: &models.Vehicle{Type: models.VehicleSedan, Seats: 4, Plate: "KA01AB1234"}},
+	{DriverID: "d-2", Name: "Ravi", IsAvailable: true,
+		Vehicle: &models.Vehicle{Type: models.VehicleAuto, Seats: 3, Plate: "KA02CD5678"}},
+}
+
 func SeedDrivers(ctx context.Context, pool *pgxpool.Pool) error {
-	_, err := pool.Exec(ctx, `
+	return UpsertDrivers(ctx, pool, Drivers...)
+}
+
+// UpsertDrivers inserts drivers, overwriting name and availability of existing
+// ones and the vehicle of those given one.
+func UpsertDrivers(ctx context.Context, pool *pgxpool.Pool, drivers ...models.Driver) error {
+	for _, d := range drivers {
+		_, err := pool.Exec(ctx, `
 INSERT INTO drivers (driver_id, name, is_available)
-VALUES ('d-1','Asha', TRUE)
+VALUES ($1, $2, $3)
 ON CONFLICT (driver_id) DO UPDATE
-SET name = EXCLUDED.name, is_available = EXCLUDED.is_available;`)
-	if err != nil {
-		return err
-	}
-	_, err = pool.Exec(ctx, `
-INSERT INTO drivers (driver_id, name, is_available)
-VALUES ('d-2','Ravi', TRUE)
+SET name = EXCLUDED.name, is_available = EXCLUDED.is_available;`, d.DriverID, d.Name, d.IsAvailable)
+		if err != nil {
+			return err
+		}
+		if v := d.Vehicle; v != nil {
+			_, err := pool.Exec(ctx, `
+INSERT INTO vehicles (driver_id, vehicle_type, seats, plate)
+VALUES ($1, $2, $3, $4)
 ON CONFLICT (driver_id) DO UPDATE
-SET name = EXCLUDED.name, is_available = EXCLUDED.is_available;`)
-	return err
+SET vehicle_type = EXCLUDED.vehicle_type, seats = EXCLUDED.seats, plate = EXCLUDED.plate;`,
+				d.DriverID, string(v.Type), v.Seats, v.Plate)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,40 @@
+package service
+
+import "sync"
+
+// Broadcaster wakes in-process watchers when jobs may have changed. It
+// carries no payload: watchers re-read what they care about, so a missed or
+// coalesced wake-up never leaves them with stale data.
+type Broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subs: make(map[chan struct{}]struct{})}
+}
+
+// Subscribe returns a channel that receives at least one value after every
+// Broadcast, and a func to unsubscribe.
+func (b *Broadcaster) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *Broadcaster) Broadcast() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- struct{}{}:
+		default: // already pending
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"net/http"
+
+	"driver_svc/internal/problem"
+)
+
+// Problems maps every sentinel this package returns to a status and stable
+// problem code. The HTTP and gRPC transports both render errors through it.
+var Problems = problem.Mapper{
+	{Err: ErrDriverNotFound, Status: http.StatusNotFound, Code: problem.CodeDriverNotFound},
+	{Err: ErrJobAlreadyTaken, Status: http.StatusConflict, Code: problem.CodeJobAlreadyTaken},
+	{Err: ErrJobNotFound, Status: http.StatusNotFound, Code: problem.CodeJobNotFound},
+	{Err: ErrJobNotTaken, Status: http.StatusConflict, Code: problem.CodeJobNotTaken},
+	{Err: ErrJobCancelled, Status: http.StatusConflict, Code: problem.CodeJobCancelled},
+	{Err: ErrVehicleMismatch, Status: http.StatusConflict, Code: problem.CodeVehicleMismatch},
+	{Err: ErrPlateTaken, Status: http.StatusConflict, Code: problem.CodePlateTaken},
+	{Err: ErrActingAsOtherDriver, Status: http.StatusForbidden, Code: problem.CodeForbidden},
+}
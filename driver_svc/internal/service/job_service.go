@@ -3,15 +3,71 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"driver_svc/internal/auth"
 	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
 	"driver_svc/internal/models"
+	"driver_svc/internal/problem"
 	"driver_svc/internal/repository"
 )
 
 var ErrJobAlreadyTaken = errors.New("job already taken")
 var ErrDriverNotFound = errors.New("driver not found")
+var ErrActingAsOtherDriver = errors.New("driver_id does not match the authenticated driver")
+var ErrJobNotFound = errors.New("job not found")
+var ErrJobNotTaken = errors.New("job has not been taken")
+var ErrJobCancelled = errors.New("job was cancelled")
+var ErrVehicleMismatch = errors.New("driver's vehicle cannot serve this job")
+var ErrPlateTaken = errors.New("plate is registered to another driver")
+
+// watchPollInterval bounds how stale a watcher can be when the change was
+// made by another replica, which the in-process Broadcaster never sees.
+const watchPollInterval = 2 * time.Second
+
+// ActingDriverID resolves which driver principal p is accepting a job as.
+// Drivers always accept as themselves, so requested may only repeat their own
+// id; admins accepting on a driver's behalf must name the driver.
+func ActingDriverID(p auth.Principal, requested string) (string, error) {
+	if p.Role == auth.RoleDriver {
+		if requested != "" && requested != p.DriverID {
+			return "", ErrActingAsOtherDriver
+		}
+		return p.DriverID, nil
+	}
+	if requested == "" {
+		return "", problem.ValidationError{{Field: "driver_id", Message: "is required when accepting on a driver's behalf"}}
+	}
+	return requested, nil
+}
+
+// ViewingDriverID is the driver whose vehicle filters the jobs p sees:
+// drivers see the jobs they can serve, admins see every job.
+func ViewingDriverID(p auth.Principal) string {
+	if p.Role == auth.RoleDriver {
+		return p.DriverID
+	}
+	return ""
+}
+
+type JobEventType string
+
+const (
+	JobOpened    JobEventType = "opened"
+	JobTaken     JobEventType = "taken"
+	JobCancelled JobEventType = "cancelled"
+)
+
+// JobEvent is one change seen by WatchJobs.
+type JobEvent struct {
+	Type JobEventType
+	Job  models.Job
+}
 
 type AcceptedEventProducer interface {
 	ProduceBookingAccepted(ctx context.Context, evt events.BookingAccepted) error
@@ -19,27 +75,124 @@ type AcceptedEventProducer interface {
 
 type JobsService interface {
 	ListDrivers(ctx context.Context) ([]models.Driver, error)
-	ListOpenJobs(ctx context.Context) ([]models.Job, error)
+	// RegisterVehicle sets the vehicle the driver drives, replacing any
+	// earlier one.
+	RegisterVehicle(ctx context.Context, driverID string, v models.Vehicle) (models.Driver, error)
+	// ListOpenJobs returns the open jobs driverID's vehicle can serve, or
+	// every open job when driverID is empty, as for admins.
+	ListOpenJobs(ctx context.Context, driverID string) ([]models.Job, error)
 	AcceptJob(ctx context.Context, bookingID string, driverID string) error
+	// WatchJobs sends every open job as JobOpened, then JobOpened, JobTaken
+	// or JobCancelled as jobs appear, are accepted or are withdrawn, until ctx
+	// ends or send fails. Like ListOpenJobs, it only reports jobs driverID
+	// can serve unless driverID is empty.
+	WatchJobs(ctx context.Context, driverID string, send func(JobEvent) error) error
+	// ListJobsSince returns up to limit jobs created at or after since, oldest
+	// first, for reconciliation against booking_svc.
+	ListJobsSince(ctx context.Context, since time.Time, limit int) ([]models.Job, error)
+	// RepublishAccepted publishes booking.accepted again for a Taken job
+	// whose acceptance never reached booking_svc.
+	RepublishAccepted(ctx context.Context, bookingID string) error
 }
 
 type jobsService struct {
 	drivers  repository.DriverRepository
 	jobs     repository.JobRepository
 	producer AcceptedEventProducer
+	changes  *Broadcaster
 	logger   *slog.Logger
 }
 
-func NewJobsService(dr repository.DriverRepository, jr repository.JobRepository, prod AcceptedEventProducer, logger *slog.Logger) *jobsService {
-	return &jobsService{drivers: dr, jobs: jr, producer: prod, logger: logger}
+func NewJobsService(dr repository.DriverRepository, jr repository.JobRepository, prod AcceptedEventProducer, changes *Broadcaster, logger *slog.Logger) *jobsService {
+	return &jobsService{drivers: dr, jobs: jr, producer: prod, changes: changes, logger: logger}
 }
 
 func (s *jobsService) ListDrivers(ctx context.Context) ([]models.Driver, error) {
 	return s.drivers.ListAll(ctx)
 }
 
-func (s *jobsService) ListOpenJobs(ctx context.Context) ([]models.Job, error) {
-	return s.jobs.ListOpenJobs(ctx)
+// validateVehicle normalizes the plate and checks v against its vehicle type.
+func validateVehicle(v models.Vehicle) (models.Vehicle, error) {
+	var errs problem.ValidationError
+	v.Plate = strings.ToUpper(strings.TrimSpace(v.Plate))
+	maxSeats, ok := models.VehicleSeats[v.Type]
+	if !ok {
+		errs = append(errs, problem.FieldError{Field: "type", Message: fmt.Sprintf("must be one of %v", models.VehicleTypes)})
+	} else if v.Seats < 1 || v.Seats > maxSeats {
+		errs = append(errs, problem.FieldError{Field: "seats", Message: fmt.Sprintf("must be between 1 and %d for a %s", maxSeats, v.Type)})
+	}
+	if !validPlate(v.Plate) {
+		errs = append(errs, problem.FieldError{Field: "plate", Message: "must be 1 to 15 letters, digits, spaces or hyphens"})
+	}
+	if len(errs) > 0 {
+		return models.Vehicle{}, errs
+	}
+	return v, nil
+}
+
+func validPlate(plate string) bool {
+	if plate == "" || len(plate) > 15 {
+		return false
+	}
+	for _, c := range plate {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == ' ' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *jobsService) RegisterVehicle(ctx context.Context, driverID string, v models.Vehicle) (models.Driver, error) {
+	v, err := validateVehicle(v)
+	if err != nil {
+		return models.Driver{}, err
+	}
+	d, ok, err := s.drivers.GetByID(ctx, driverID)
+	if err != nil {
+		return models.Driver{}, err
+	}
+	if !ok {
+		return models.Driver{}, ErrDriverNotFound
+	}
+	set, err := s.drivers.SetVehicle(ctx, driverID, v)
+	if err != nil {
+		return models.Driver{}, err
+	}
+	if !set {
+		return models.Driver{}, ErrPlateTaken
+	}
+	d.Vehicle = &v
+	// Jobs the driver could not see before may now be theirs to take.
+	s.changes.Broadcast()
+	return d, nil
+}
+
+func (s *jobsService) ListOpenJobs(ctx context.Context, driverID string) ([]models.Job, error) {
+	canServe, err := s.servableBy(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := s.jobs.ListOpenJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(jobs, func(j models.Job) bool { return !canServe(j) }), nil
+}
+
+// servableBy returns a filter for the jobs driverID's vehicle can serve; an
+// empty driverID serves every job.
+func (s *jobsService) servableBy(ctx context.Context, driverID string) (func(models.Job) bool, error) {
+	if driverID == "" {
+		return func(models.Job) bool { return true }, nil
+	}
+	d, ok, err := s.drivers.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDriverNotFound
+	}
+	return d.CanServe, nil
 }
 
 func (s *jobsService) AcceptJob(ctx context.Context, bookingID string, driverID string) error {
@@ -50,19 +203,117 @@ func (s *jobsService) AcceptJob(ctx context.Context, bookingID string, driverID
 	if !ok || !d.IsAvailable {
 		return ErrDriverNotFound
 	}
+	// A job's vehicle and passengers never change, so checking before
+	// TryAccept cannot let through a job the driver cannot serve.
+	if j, ok, err := s.jobs.GetJob(ctx, bookingID); err != nil {
+		return err
+	} else if ok && j.Status == models.JobStatusOpen && !d.CanServe(j) {
+		return ErrVehicleMismatch
+	}
 
 	won, err := s.jobs.TryAccept(ctx, bookingID, driverID)
 	if err != nil {
 		return err
 	}
 	if !won {
+		if j, ok, err := s.jobs.GetJob(ctx, bookingID); err == nil && ok && j.Status == models.JobStatusCancelled {
+			return ErrJobCancelled
+		}
+		metrics.JobAcceptConflicts.Inc()
 		return ErrJobAlreadyTaken
 	}
+	metrics.JobsAccepted.Inc()
+	s.changes.Broadcast()
+
+	return s.producer.ProduceBookingAccepted(ctx, acceptedEvent(bookingID, driverID))
+}
 
-	evt := events.BookingAccepted{
+func acceptedEvent(bookingID, driverID string) events.BookingAccepted {
+	return events.BookingAccepted{
 		BookingID:  bookingID,
 		DriverID:   driverID,
 		RideStatus: "Accepted",
 	}
-	return s.producer.ProduceBookingAccepted(ctx, evt)
+}
+
+func (s *jobsService) WatchJobs(ctx context.Context, driverID string, send func(JobEvent) error) error {
+	wake, unsubscribe := s.changes.Subscribe()
+	defer unsubscribe()
+	tick := time.NewTicker(watchPollInterval)
+	defer tick.Stop()
+
+	open := make(map[string]bool)
+	for {
+		// The driver is looked up each pass so a newly registered vehicle
+		// shows the jobs it can serve.
+		jobs, err := s.ListOpenJobs(ctx, driverID)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(jobs))
+		// ListOpenJobs is newest first; report oldest first.
+		for i := len(jobs) - 1; i >= 0; i-- {
+			j := jobs[i]
+			seen[j.BookingID] = true
+			if open[j.BookingID] {
+				continue
+			}
+			if err := send(JobEvent{Type: JobOpened, Job: j}); err != nil {
+				return err
+			}
+			open[j.BookingID] = true
+		}
+		for id := range open {
+			if seen[id] {
+				continue
+			}
+			delete(open, id)
+			j, ok, err := s.jobs.GetJob(ctx, id)
+			if err != nil {
+				return err
+			}
+			var typ JobEventType
+			switch {
+			case !ok:
+				continue
+			case j.Status == models.JobStatusTaken:
+				typ = JobTaken
+			case j.Status == models.JobStatusCancelled:
+				typ = JobCancelled
+			default:
+				continue
+			}
+			if err := send(JobEvent{Type: typ, Job: j}); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-tick.C:
+		}
+	}
+}
+
+func (s *jobsService) ListJobsSince(ctx context.Context, since time.Time, limit int) ([]models.Job, error) {
+	return s.jobs.ListCreatedSince(ctx, since, limit)
+}
+
+func (s *jobsService) RepublishAccepted(ctx context.Context, bookingID string) error {
+	j, ok, err := s.jobs.GetJob(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobNotFound
+	}
+	if j.Status != models.JobStatusTaken || j.AcceptedDriverID == nil {
+		return ErrJobNotTaken
+	}
+	if err := s.producer.ProduceBookingAccepted(ctx, acceptedEvent(bookingID, *j.AcceptedDriverID)); err != nil {
+		return err
+	}
+	s.logger.Info("booking.accepted republished", slog.String("booking_id", bookingID))
+	return nil
 }
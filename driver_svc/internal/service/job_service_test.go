@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
+	"log/slog"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
 	"driver_svc/internal/events"
 	"driver_svc/internal/models"
+	"driver_svc/internal/problem"
 	"driver_svc/internal/repository"
 )
 
@@ -17,6 +21,7 @@ import (
 type fakeDriverRepo struct {
 	getFn  func(ctx context.Context, driverID string) (models.Driver, bool, error)
 	listFn func(ctx context.Context) ([]models.Driver, error)
+	setFn  func(ctx context.Context, driverID string, v models.Vehicle) (bool, error)
 }
 
 func (f *fakeDriverRepo) ListAll(ctx context.Context) ([]models.Driver, error) {
@@ -31,11 +36,21 @@ func (f *fakeDriverRepo) GetByID(ctx context.Context, driverID string) (models.D
 	}
 	return models.Driver{}, false, nil
 }
+func (f *fakeDriverRepo) SetVehicle(ctx context.Context, driverID string, v models.Vehicle) (bool, error) {
+	if f.setFn != nil {
+		return f.setFn(ctx, driverID, v)
+	}
+	return true, nil
+}
+func (f *fakeDriverRepo) RecordRating(ctx context.Context, p repository.RecordRatingParams, window int) (bool, error) {
+	return false, nil
+}
 
 type fakeJobRepo struct {
 	tryFn    func(ctx context.Context, bookingID, driverID string) (bool, error)
 	upsertFn func(ctx context.Context, p repository.UpsertJobParams) error
 	listFn   func(ctx context.Context) ([]models.Job, error)
+	getFn    func(ctx context.Context, bookingID string) (models.Job, bool, error)
 }
 
 func (f *fakeJobRepo) UpsertOpenJob(ctx context.Context, p repository.UpsertJobParams) error {
@@ -50,12 +65,24 @@ func (f *fakeJobRepo) ListOpenJobs(ctx context.Context) ([]models.Job, error) {
 	}
 	return nil, nil
 }
+func (f *fakeJobRepo) GetJob(ctx context.Context, bookingID string) (models.Job, bool, error) {
+	if f.getFn != nil {
+		return f.getFn(ctx, bookingID)
+	}
+	return models.Job{}, false, nil
+}
+func (f *fakeJobRepo) ListCreatedSince(ctx context.Context, since time.Time, limit int) ([]models.Job, error) {
+	return nil, nil
+}
 func (f *fakeJobRepo) TryAccept(ctx context.Context, bookingID, driverID string) (bool, error) {
 	if f.tryFn != nil {
 		return f.tryFn(ctx, bookingID, driverID)
 	}
 	return false, nil
 }
+func (f *fakeJobRepo) Cancel(ctx context.Context, bookingID string) (bool, error) {
+	return false, nil
+}
 
 type fakeProducer struct {
 	mu     sync.Mutex
@@ -79,10 +106,15 @@ func TestAcceptJob_Table(t *testing.T) {
 		name      string
 		driverOK  bool
 		available bool
+		vehicle   *models.Vehicle
 		tryAccept func(ctx context.Context, bID, dID string) (bool, error)
+		status    models.JobStatus
 		prodErr   error
 		wantErr   error
 		wantCalls int
+		// vehicleType and passengers are what the job asks for.
+		vehicleType models.VehicleType
+		passengers  int
 	}{
 		{
 			name:     "success -> event produced",
@@ -96,6 +128,44 @@ func TestAcceptJob_Table(t *testing.T) {
 			tryAccept: func(_ context.Context, _, _ string) (bool, error) { return false, nil },
 			wantErr:   ErrJobAlreadyTaken, wantCalls: 0,
 		},
+		{
+			name:     "cancelled -> 409",
+			driverOK: true, available: true,
+			tryAccept: func(_ context.Context, _, _ string) (bool, error) { return false, nil },
+			status:    models.JobStatusCancelled,
+			wantErr:   ErrJobCancelled, wantCalls: 0,
+		},
+		{
+			name:     "vehicle cannot serve the job -> 409",
+			driverOK: true, available: true,
+			vehicle:   &models.Vehicle{Type: models.VehicleSedan, Seats: 4},
+			tryAccept: func(_ context.Context, _, _ string) (bool, error) { return true, nil },
+			status:    models.JobStatusOpen, vehicleType: models.VehicleSUV, passengers: 2,
+			wantErr: ErrVehicleMismatch, wantCalls: 0,
+		},
+		{
+			name:     "too many passengers for the vehicle -> 409",
+			driverOK: true, available: true,
+			vehicle:   &models.Vehicle{Type: models.VehicleAuto, Seats: 3},
+			tryAccept: func(_ context.Context, _, _ string) (bool, error) { return true, nil },
+			status:    models.JobStatusOpen, passengers: 4,
+			wantErr: ErrVehicleMismatch, wantCalls: 0,
+		},
+		{
+			name:     "no vehicle registered -> only one rider and any vehicle",
+			driverOK: true, available: true,
+			tryAccept: func(_ context.Context, _, _ string) (bool, error) { return true, nil },
+			status:    models.JobStatusOpen, vehicleType: models.VehicleAuto, passengers: 1,
+			wantErr: ErrVehicleMismatch, wantCalls: 0,
+		},
+		{
+			name:     "vehicle fits -> event produced",
+			driverOK: true, available: true,
+			vehicle:   &models.Vehicle{Type: models.VehicleSUV, Seats: 6},
+			tryAccept: func(_ context.Context, _, _ string) (bool, error) { return true, nil },
+			status:    models.JobStatusOpen, vehicleType: models.VehicleSUV, passengers: 6,
+			wantErr: nil, wantCalls: 1,
+		},
 		{
 			name:     "driver missing -> 404",
 			driverOK: false, available: false,
@@ -121,13 +191,18 @@ func TestAcceptJob_Table(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			dr := &fakeDriverRepo{
 				getFn: func(ctx context.Context, driverID string) (models.Driver, bool, error) {
-					return models.Driver{DriverID: driverID, IsAvailable: tc.available}, tc.driverOK, nil
+					return models.Driver{DriverID: driverID, IsAvailable: tc.available, Vehicle: tc.vehicle}, tc.driverOK, nil
 				},
 			}
 			jr := &fakeJobRepo{tryFn: tc.tryAccept}
+			if tc.status != "" {
+				jr.getFn = func(_ context.Context, id string) (models.Job, bool, error) {
+					return models.Job{BookingID: id, Status: tc.status, VehicleType: tc.vehicleType, Passengers: tc.passengers}, true, nil
+				}
+			}
 			prod := &fakeProducer{err: tc.prodErr}
 
-			svc := NewJobsService(dr, jr, prod, nil)
+			svc := NewJobsService(dr, jr, prod, NewBroadcaster(), nil)
 			err := svc.AcceptJob(context.Background(), "b-1", "d-1")
 
 			if (tc.wantErr == nil) != (err == nil) {
@@ -161,7 +236,7 @@ func TestAcceptJob_FirstWriterWins_Concurrent(t *testing.T) {
 		},
 	}
 	prod := &fakeProducer{}
-	svc := NewJobsService(dr, jr, prod, nil)
+	svc := NewJobsService(dr, jr, prod, NewBroadcaster(), nil)
 
 	var wg sync.WaitGroup
 	errs := make([]error, 2)
@@ -189,3 +264,181 @@ func TestAcceptJob_FirstWriterWins_Concurrent(t *testing.T) {
 		t.Fatalf("producer calls want 1 got %d", prod.calls)
 	}
 }
+
+func TestWatchJobs_OpenedThenTaken(t *testing.T) {
+	var mu sync.Mutex
+	open := []models.Job{{BookingID: "b-3", Status: models.JobStatusOpen}, {BookingID: "b-1", Status: models.JobStatusOpen}}
+	driverID := "d-1"
+	jr := &fakeJobRepo{
+		listFn: func(ctx context.Context) ([]models.Job, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]models.Job(nil), open...), nil
+		},
+		getFn: func(ctx context.Context, bookingID string) (models.Job, bool, error) {
+			if bookingID == "b-3" {
+				return models.Job{BookingID: bookingID, Status: models.JobStatusCancelled}, true, nil
+			}
+			return models.Job{BookingID: bookingID, Status: models.JobStatusTaken, AcceptedDriverID: &driverID}, true, nil
+		},
+	}
+	changes := NewBroadcaster()
+	svc := NewJobsService(&fakeDriverRepo{}, jr, &fakeProducer{}, changes, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan JobEvent, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.WatchJobs(ctx, "", func(e JobEvent) error { got <- e; return nil })
+	}()
+
+	for _, id := range []string{"b-1", "b-3"} {
+		if e := <-got; e.Type != JobOpened || e.Job.BookingID != id {
+			t.Fatalf("want %s opened, got %+v", id, e)
+		}
+	}
+	// b-1 is accepted, b-3 cancelled and b-2 arrives; the wake-up should
+	// report all three.
+	mu.Lock()
+	open = []models.Job{{BookingID: "b-2", Status: models.JobStatusOpen}}
+	mu.Unlock()
+	changes.Broadcast()
+
+	want := map[string]JobEventType{"b-2": JobOpened, "b-1": JobTaken, "b-3": JobCancelled}
+	for range want {
+		e := <-got
+		if want[e.Job.BookingID] != e.Type {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestListOpenJobs_OnlyWhatTheVehicleCanServe(t *testing.T) {
+	drivers := map[string]models.Driver{
+		"sedan": {DriverID: "sedan", Vehicle: &models.Vehicle{Type: models.VehicleSedan, Seats: 4}},
+		"none":  {DriverID: "none"},
+	}
+	dr := &fakeDriverRepo{getFn: func(_ context.Context, id string) (models.Driver, bool, error) {
+		d, ok := drivers[id]
+		return d, ok, nil
+	}}
+	jr := &fakeJobRepo{listFn: func(context.Context) ([]models.Job, error) {
+		return []models.Job{
+			{BookingID: "any-1", Passengers: 1},
+			{BookingID: "any-3", Passengers: 3},
+			{BookingID: "sedan-4", VehicleType: models.VehicleSedan, Passengers: 4},
+			{BookingID: "suv-2", VehicleType: models.VehicleSUV, Passengers: 2},
+			{BookingID: "any-5", Passengers: 5},
+		}, nil
+	}}
+	svc := NewJobsService(dr, jr, &fakeProducer{}, NewBroadcaster(), nil)
+
+	for driverID, want := range map[string][]string{
+		"":      {"any-1", "any-3", "sedan-4", "suv-2", "any-5"},
+		"sedan": {"any-1", "any-3", "sedan-4"},
+		"none":  {"any-1"},
+	} {
+		jobs, err := svc.ListOpenJobs(context.Background(), driverID)
+		if err != nil {
+			t.Fatalf("%q: %v", driverID, err)
+		}
+		var ids []string
+		for _, j := range jobs {
+			ids = append(ids, j.BookingID)
+		}
+		if !slices.Equal(ids, want) {
+			t.Fatalf("%q: want %v, got %v", driverID, want, ids)
+		}
+	}
+	if _, err := svc.ListOpenJobs(context.Background(), "ghost"); !errors.Is(err, ErrDriverNotFound) {
+		t.Fatalf("unknown driver: %v", err)
+	}
+}
+
+func TestRegisterVehicle(t *testing.T) {
+	var stored models.Vehicle
+	dr := &fakeDriverRepo{
+		getFn: func(_ context.Context, id string) (models.Driver, bool, error) {
+			return models.Driver{DriverID: id, Name: "Asha"}, id == "d-1", nil
+		},
+		setFn: func(_ context.Context, _ string, v models.Vehicle) (bool, error) {
+			if v.Plate == "TAKEN" {
+				return false, nil
+			}
+			stored = v
+			return true, nil
+		},
+	}
+	svc := NewJobsService(dr, &fakeJobRepo{}, &fakeProducer{}, NewBroadcaster(), nil)
+	ctx := context.Background()
+
+	d, err := svc.RegisterVehicle(ctx, "d-1", models.Vehicle{Type: models.VehicleAuto, Seats: 3, Plate: " ka 01-ab 1234 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.Vehicle{Type: models.VehicleAuto, Seats: 3, Plate: "KA 01-AB 1234"}
+	if d.Vehicle == nil || *d.Vehicle != want || stored != want {
+		t.Fatalf("want %+v, got driver %+v stored %+v", want, d.Vehicle, stored)
+	}
+
+	for name, tc := range map[string]struct {
+		driverID string
+		v        models.Vehicle
+		wantErr  error
+		field    string
+	}{
+		"unknown type":          {"d-1", models.Vehicle{Type: "tram", Seats: 1, Plate: "X1"}, nil, "type"},
+		"more seats than a car": {"d-1", models.Vehicle{Type: models.VehicleSedan, Seats: 5, Plate: "X1"}, nil, "seats"},
+		"no seats":              {"d-1", models.Vehicle{Type: models.VehicleBike, Seats: 0, Plate: "X1"}, nil, "seats"},
+		"blank plate":           {"d-1", models.Vehicle{Type: models.VehicleBike, Seats: 1, Plate: "  "}, nil, "plate"},
+		"odd plate":             {"d-1", models.Vehicle{Type: models.VehicleBike, Seats: 1, Plate: "KA_01"}, nil, "plate"},
+		"plate taken":           {"d-1", models.Vehicle{Type: models.VehicleBike, Seats: 1, Plate: "taken"}, ErrPlateTaken, ""},
+		"unknown driver":        {"d-9", models.Vehicle{Type: models.VehicleBike, Seats: 1, Plate: "X1"}, ErrDriverNotFound, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.RegisterVehicle(ctx, tc.driverID, tc.v)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("want %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			var verr problem.ValidationError
+			if !errors.As(err, &verr) || len(verr) != 1 || verr[0].Field != tc.field {
+				t.Fatalf("want a %s validation error, got %v", tc.field, err)
+			}
+		})
+	}
+}
+
+func TestRepublishAccepted(t *testing.T) {
+	driverID := "d-1"
+	jobs := map[string]models.Job{
+		"open":  {BookingID: "open", Status: models.JobStatusOpen},
+		"taken": {BookingID: "taken", Status: models.JobStatusTaken, AcceptedDriverID: &driverID},
+	}
+	jr := &fakeJobRepo{getFn: func(ctx context.Context, bookingID string) (models.Job, bool, error) {
+		j, ok := jobs[bookingID]
+		return j, ok, nil
+	}}
+	prod := &fakeProducer{}
+	svc := NewJobsService(&fakeDriverRepo{}, jr, prod, NewBroadcaster(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := svc.RepublishAccepted(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("missing job: %v", err)
+	}
+	if err := svc.RepublishAccepted(context.Background(), "open"); !errors.Is(err, ErrJobNotTaken) {
+		t.Fatalf("open job: %v", err)
+	}
+	if err := svc.RepublishAccepted(context.Background(), "taken"); err != nil {
+		t.Fatal(err)
+	}
+	if len(prod.events) != 1 || prod.events[0] != (events.BookingAccepted{BookingID: "taken", DriverID: "d-1", RideStatus: "Accepted"}) {
+		t.Fatalf("unexpected events: %+v", prod.events)
+	}
+}
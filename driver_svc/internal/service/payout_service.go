@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"driver_svc/internal/events"
+	"driver_svc/internal/metrics"
+	"driver_svc/internal/models"
+	"driver_svc/internal/money"
+	"driver_svc/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ErrPayoutRejected means the provider refused a payout outright, for
+// example for a closed account; providers wrap it in their rejection errors.
+// Rejected payouts are not retried, and their jobs join the next run.
+// Any other error may hide a transfer the provider made.
+var ErrPayoutRejected = errors.New("payout rejected")
+
+// PayoutProvider sends money to drivers. Pay carries an idempotency key:
+// repeating a key the provider has seen returns the original outcome instead
+// of paying twice, so callers may retry freely.
+type PayoutProvider interface {
+	// Pay transfers req.Amount to the driver and returns the provider's
+	// reference for the transfer.
+	Pay(ctx context.Context, key string, req PayoutRequest) (string, error)
+}
+
+// PaidEventProducer tells booking_svc's ledger a payout was paid.
+type PaidEventProducer interface {
+	ProducePayoutPaid(ctx context.Context, evt events.PayoutPaid) error
+}
+
+type PayoutRequest struct {
+	// Reference is the payout being paid.
+	Reference string
+	DriverID  string
+	Amount    money.Money
+}
+
+// PayoutPolicy decides when drivers are paid and how failed payouts retry.
+// What they are paid is what booking_svc's ledger credited them.
+type PayoutPolicy struct {
+	// MinAmount is the smallest payout, in minor units of its currency.
+	// Drivers owed less are carried over to a later run.
+	MinAmount int64
+	// MaxAttempts bounds the provider calls per payout before it is held.
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Validate checks that p describes a payable, retryable policy.
+func (p PayoutPolicy) Validate() error {
+	switch {
+	case p.MinAmount < 0:
+		return fmt.Errorf("minimum payout %d must not be negative", p.MinAmount)
+	case p.MaxAttempts < 1:
+		return fmt.Errorf("max attempts %d must be at least 1", p.MaxAttempts)
+	case p.BaseBackoff <= 0 || p.MaxBackoff < p.BaseBackoff:
+		return fmt.Errorf("backoff %s..%s must be positive and increasing", p.BaseBackoff, p.MaxBackoff)
+	}
+	return nil
+}
+
+// PayoutRun counts what one run did.
+type PayoutRun struct {
+	Until time.Time `json:"until"`
+	// Created payouts, one per driver and currency owed at least MinAmount.
+	Created int `json:"created"`
+	// Deferred drivers were owed less than MinAmount.
+	Deferred int `json:"deferred"`
+	// Paid, Retrying, Failed and Held count the attempts made on due
+	// payouts, including ones created by earlier runs.
+	Paid     int `json:"paid"`
+	Retrying int `json:"retrying"`
+	Failed   int `json:"failed"`
+	Held     int `json:"held"`
+}
+
+type PayoutService interface {
+	// RunPayouts creates payouts for the earnings of trips completed before
+	// until that no payout covers yet, then attempts every due payout once. A failed
+	// payout does not stop the others.
+	RunPayouts(ctx context.Context, until time.Time) (PayoutRun, error)
+	// ListDriverPayouts returns up to limit of a driver's payouts, newest first.
+	ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]models.Payout, error)
+}
+
+const (
+	payoutBatchSize = 100
+	// payoutLease keeps other replicas off a payout while it is being paid.
+	payoutLease = time.Minute
+)
+
+type payoutService struct {
+	drivers  repository.DriverRepository
+	payouts  repository.PayoutRepository
+	provider PayoutProvider
+	producer PaidEventProducer
+	policy   PayoutPolicy
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewPayoutService(dr repository.DriverRepository, pr repository.PayoutRepository, provider PayoutProvider, producer PaidEventProducer, policy PayoutPolicy, logger *slog.Logger) *payoutService {
+	return &payoutService{drivers: dr, payouts: pr, provider: provider, producer: producer, policy: policy, logger: logger, now: time.Now}
+}
+
+func (s *payoutService) ListDriverPayouts(ctx context.Context, driverID string, limit int) ([]models.Payout, error) {
+	if _, ok, err := s.drivers.GetByID(ctx, driverID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrDriverNotFound
+	}
+	return s.payouts.ListDriverPayouts(ctx, driverID, limit)
+}
+
+func (s *payoutService) RunPayouts(ctx context.Context, until time.Time) (PayoutRun, error) {
+	run := PayoutRun{Until: until}
+	if err := s.createPayouts(ctx, &run); err != nil {
+		return run, err
+	}
+	return run, s.payDue(ctx, &run)
+}
+
+// createPayouts groups unpaid earnings by driver and currency and stores a
+// payout for every group that reaches the minimum.
+func (s *payoutService) createPayouts(ctx context.Context, run *PayoutRun) error {
+	earnings, err := s.payouts.ListUnpaidEarnings(ctx, run.Until)
+	if err != nil {
+		return err
+	}
+	type owed struct{ driverID, currency string }
+	groups := make(map[owed]*repository.CreatePayoutParams)
+	var order []owed
+	for _, e := range earnings {
+		k := owed{e.DriverID, e.Amount.Currency}
+		p, ok := groups[k]
+		if !ok {
+			// Earnings are oldest first, so the first one starts the period.
+			p = &repository.CreatePayoutParams{
+				DriverID:    e.DriverID,
+				Amount:      money.Money{Currency: e.Amount.Currency},
+				PeriodStart: e.CompletedAt,
+				PeriodEnd:   run.Until,
+			}
+			groups[k] = p
+			order = append(order, k)
+		}
+		if p.Amount, err = p.Amount.Add(e.Amount); err != nil {
+			return fmt.Errorf("trip %s: %w", e.BookingID, err)
+		}
+		p.Items = append(p.Items, models.PayoutItem{BookingID: e.BookingID, Fare: e.Fare, Amount: e.Amount})
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].driverID != order[j].driverID {
+			return order[i].driverID < order[j].driverID
+		}
+		return order[i].currency < order[j].currency
+	})
+
+	for _, k := range order {
+		p := groups[k]
+		if !p.Amount.IsPositive() || p.Amount.Amount < s.policy.MinAmount {
+			run.Deferred++
+			continue
+		}
+		p.ID = uuid.NewString()
+		ok, err := s.payouts.CreatePayout(ctx, *p)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Another replica's run got to these jobs first.
+			continue
+		}
+		run.Created++
+		metrics.PayoutsCreated.Inc()
+		s.logger.Info("payout created",
+			slog.String("payout_id", p.ID),
+			slog.String("driver_id", p.DriverID),
+			slog.String("amount", p.Amount.String()),
+			slog.Int("jobs", len(p.Items)),
+		)
+	}
+	return nil
+}
+
+// payDue attempts due payouts a batch at a time until none are left.
+func (s *payoutService) payDue(ctx context.Context, run *PayoutRun) error {
+	for {
+		due, err := s.payouts.ClaimDuePayouts(ctx, s.now(), payoutLease, payoutBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, p := range due {
+			res := s.attempt(ctx, p)
+			if res.ProviderRef != nil {
+				res = s.publishPaid(ctx, p, res)
+			}
+			switch {
+			case res.ProviderRef != nil:
+				run.Paid++
+			case res.NextAttemptAt != nil:
+				run.Retrying++
+			case res.Rejected:
+				run.Failed++
+			default:
+				run.Held++
+			}
+			if err := s.payouts.RecordPayoutAttempt(ctx, p.ID, res); err != nil {
+				return err
+			}
+		}
+		if len(due) < payoutBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *payoutService) attempt(ctx context.Context, p models.Payout) repository.PayoutAttemptResult {
+	// The payout id is the idempotency key, so a retry after a lost response
+	// cannot pay the driver twice.
+	ref, err := s.provider.Pay(ctx, p.ID, PayoutRequest{Reference: p.ID, DriverID: p.DriverID, Amount: p.Amount})
+	if err == nil {
+		metrics.PayoutAttempts.WithLabelValues("paid").Inc()
+		s.logger.Info("payout paid", slog.String("payout_id", p.ID), slog.String("provider_ref", ref))
+		return repository.PayoutAttemptResult{ProviderRef: &ref}
+	}
+
+	msg := err.Error()
+	res := repository.PayoutAttemptResult{Error: &msg}
+	attempts := p.Attempts + 1
+	switch {
+	case errors.Is(err, ErrPayoutRejected):
+		res.Rejected = true
+		metrics.PayoutAttempts.WithLabelValues("failed").Inc()
+	case attempts < s.policy.MaxAttempts:
+		next := s.now().Add(payoutBackoff(attempts, s.policy.BaseBackoff, s.policy.MaxBackoff))
+		res.NextAttemptAt = &next
+		metrics.PayoutAttempts.WithLabelValues("retry").Inc()
+	default:
+		// A timed-out call may have paid the driver. Paying the jobs again
+		// under a new payout id would bypass the idempotency key.
+		metrics.PayoutAttempts.WithLabelValues("held").Inc()
+	}
+	s.logger.Warn("payout attempt failed",
+		slog.String("payout_id", p.ID),
+		slog.String("driver_id", p.DriverID),
+		slog.Int("attempt", attempts),
+		slog.Bool("will_retry", res.NextAttemptAt != nil),
+		slog.String("err", msg),
+	)
+	return res
+}
+
+// publishPaid tells booking_svc's ledger that p was paid, before p is marked
+// paid. If that fails, p is retried like a failed call: paying again under
+// the same key returns the same transfer, and publishes then.
+func (s *payoutService) publishPaid(ctx context.Context, p models.Payout, res repository.PayoutAttemptResult) repository.PayoutAttemptResult {
+	err := s.producer.ProducePayoutPaid(ctx, events.PayoutPaid{
+		PayoutID:    p.ID,
+		DriverID:    p.DriverID,
+		Amount:      p.Amount,
+		ProviderRef: *res.ProviderRef,
+		PaidAt:      s.now(),
+	})
+	if err == nil {
+		return res
+	}
+	msg := "publish payout.paid: " + err.Error()
+	next := s.now().Add(payoutBackoff(p.Attempts+1, s.policy.BaseBackoff, s.policy.MaxBackoff))
+	s.logger.Error("payout paid but not published",
+		slog.String("payout_id", p.ID),
+		slog.String("provider_ref", *res.ProviderRef),
+		slog.String("err", err.Error()),
+	)
+	return repository.PayoutAttemptResult{Error: &msg, NextAttemptAt: &next}
+}
+
+// payoutBackoff returns base * 2^(attempt-1), capped at max.
+func payoutBackoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return d
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"driver_svc/internal/bus"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := Init("test", "0", nil)
+	tp.RegisterSpanProcessor(sdktrace.NewSimpleSpanProcessor(exp))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return exp
+}
+
+func TestHTTPMiddleware_NamesSpanByRouteAndContinuesTrace(t *testing.T) {
+	exp := setupExporter(t)
+
+	r := chi.NewRouter()
+	r.Use(HTTPMiddleware())
+	r.Get("/bookings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/bookings/b-1", nil)
+	req.Header.Set("traceparent", parent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("want 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name != "GET /bookings/{id}" || s.SpanKind != trace.SpanKindServer {
+		t.Fatalf("unexpected span: name=%q kind=%v", s.Name, s.SpanKind)
+	}
+	if got := s.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace not continued from traceparent, got trace id %s", got)
+	}
+}
+
+func TestKafka_PropagatesTraceThroughHeaders(t *testing.T) {
+	exp := setupExporter(t)
+
+	msg := bus.Message{Topic: "booking.created", Key: []byte("b-1"), Value: []byte(`{}`)}
+	_, prodSpan := StartProduce(context.Background(), msg.Topic, &msg)
+	prodSpan.End()
+
+	if (HeaderCarrier{Headers: &msg.Headers}).Get("traceparent") == "" {
+		t.Fatalf("traceparent header not injected: %+v", msg.Headers)
+	}
+
+	_, consSpan := StartConsume(context.Background(), msg)
+	consSpan.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("want 2 spans, got %d", len(spans))
+	}
+	prod, cons := spans[0], spans[1]
+	if cons.SpanKind != trace.SpanKindConsumer || prod.SpanKind != trace.SpanKindProducer {
+		t.Fatalf("unexpected kinds: %v %v", prod.SpanKind, cons.SpanKind)
+	}
+	if cons.Parent.SpanID() != prod.SpanContext.SpanID() || cons.SpanContext.TraceID() != prod.SpanContext.TraceID() {
+		t.Fatalf("consumer span is not a child of the producer span")
+	}
+}
+
+func TestHeaderCarrier_SetReplaces(t *testing.T) {
+	var headers []bus.Header
+	c := HeaderCarrier{Headers: &headers}
+	c.Set("traceparent", "a")
+	c.Set("traceparent", "b")
+	if len(headers) != 1 || c.Get("traceparent") != "b" {
+		t.Fatalf("unexpected headers: %+v", headers)
+	}
+	var _ propagation.TextMapCarrier = c
+}
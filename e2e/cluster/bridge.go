@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+
+	bookingapp "booking_svc/app"
+	driverapp "driver_svc/app"
+)
+
+// driverBus lets driver_svc publish to and consume from booking_svc's bus.
+// Each module defines its own bus types, so messages are copied across.
+// Closing it is a no-op; the cluster closes the shared bus.
+type driverBus struct {
+	bus bookingapp.Bus
+}
+
+func (d driverBus) Publish(ctx context.Context, msgs ...driverapp.Message) error {
+	out := make([]bookingapp.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = toBooking(m)
+	}
+	return d.bus.Publish(ctx, out...)
+}
+
+func (d driverBus) Subscribe(topic, group string) driverapp.Subscriber {
+	return driverSubscriber{sub: d.bus.Subscribe(topic, group)}
+}
+
+func (d driverBus) Check(ctx context.Context, topics ...string) error {
+	return d.bus.Check(ctx, topics...)
+}
+
+func (d driverBus) Close() error { return nil }
+
+type driverSubscriber struct {
+	sub bookingapp.Subscriber
+}
+
+func (s driverSubscriber) Fetch(ctx context.Context) (driverapp.Message, error) {
+	m, err := s.sub.Fetch(ctx)
+	if errors.Is(err, bookingapp.ErrBusClosed) {
+		return driverapp.Message{}, driverapp.ErrBusClosed
+	}
+	if err != nil {
+		return driverapp.Message{}, err
+	}
+	return toDriver(m), nil
+}
+
+func (s driverSubscriber) Commit(ctx context.Context, msgs ...driverapp.Message) error {
+	out := make([]bookingapp.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = toBooking(m)
+	}
+	err := s.sub.Commit(ctx, out...)
+	if errors.Is(err, bookingapp.ErrBusClosed) {
+		return driverapp.ErrBusClosed
+	}
+	return err
+}
+
+func (s driverSubscriber) Close() error {
+	return s.sub.Close()
+}
+
+func toBooking(m driverapp.Message) bookingapp.Message {
+	headers := make([]bookingapp.Header, len(m.Headers))
+	for i, h := range m.Headers {
+		headers[i] = bookingapp.Header{Key: h.Key, Value: h.Value}
+	}
+	return bookingapp.Message{
+		Topic: m.Topic, Key: m.Key, Value: m.Value, Headers: headers,
+		Partition: m.Partition, Offset: m.Offset, HighWaterMark: m.HighWaterMark,
+	}
+}
+
+func toDriver(m bookingapp.Message) driverapp.Message {
+	headers := make([]driverapp.Header, len(m.Headers))
+	for i, h := range m.Headers {
+		headers[i] = driverapp.Header{Key: h.Key, Value: h.Value}
+	}
+	return driverapp.Message{
+		Topic: m.Topic, Key: m.Key, Value: m.Value, Headers: headers,
+		Partition: m.Partition, Offset: m.Offset, HighWaterMark: m.HighWaterMark,
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	endpointCreate   = "POST /bookings"
+	endpointBookings = "GET /bookings"
+	endpointJobs     = "GET /jobs"
+	endpointAccept   = "POST /jobs/{id}/accept"
+	endpointDrivers  = "GET /drivers"
+)
+
+// client calls one service as one principal and records every response.
+type client struct {
+	http    *http.Client
+	baseURL string
+	token   string
+	stats   *stats
+}
+
+// do sends body as JSON and, on a 2xx, decodes the response into out. It
+// returns the status, or 0 when the request never got a response.
+func (c client) do(ctx context.Context, endpoint, method, path string, body, out any) (int, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return 0, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, &buf)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.stats.request(endpoint, 0)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	c.stats.request(endpoint, resp.StatusCode)
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("%s: %s", endpoint, resp.Status)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("%s: decode: %w", endpoint, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+type booking struct {
+	BookingID  string    `json:"booking_id"`
+	RideStatus string    `json:"ride_status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type job struct {
+	BookingID string `json:"booking_id"`
+}
+
+type driver struct {
+	DriverID    string `json:"driver_id"`
+	IsAvailable bool   `json:"is_available"`
+}
+
+type money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type createBookingRequest struct {
+	PickupLoc point `json:"pickuploc"`
+	Dropoff   point `json:"dropoff"`
+	Price     money `json:"price"`
+}
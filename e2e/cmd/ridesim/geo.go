@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+type point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+func parsePoint(s string) (point, error) {
+	lat, lng, ok := strings.Cut(s, ",")
+	if !ok {
+		return point{}, fmt.Errorf("want lat,lng, got %q", s)
+	}
+	var p point
+	var err error
+	if p.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil {
+		return point{}, fmt.Errorf("latitude: %w", err)
+	}
+	if p.Lng, err = strconv.ParseFloat(strings.TrimSpace(lng), 64); err != nil {
+		return point{}, fmt.Errorf("longitude: %w", err)
+	}
+	if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+		return point{}, fmt.Errorf("%q is not a valid coordinate", s)
+	}
+	return p, nil
+}
+
+// offset moves p by distKm along bearing (radians), good enough for city scales.
+func (p point) offset(distKm, bearing float64) point {
+	dLat := distKm / earthRadiusKm * math.Cos(bearing)
+	dLng := distKm / earthRadiusKm * math.Sin(bearing) / math.Cos(p.Lat*math.Pi/180)
+	return point{
+		Lat: clamp(p.Lat+dLat*180/math.Pi, -90, 90),
+		Lng: clamp(p.Lng+dLng*180/math.Pi, -180, 180),
+	}
+}
+
+// distanceKm is the haversine distance between p and q.
+func (p point) distanceKm(q point) float64 {
+	toRad := math.Pi / 180
+	dLat, dLng := (q.Lat-p.Lat)*toRad, (q.Lng-p.Lng)*toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(p.Lat*toRad)*math.Cos(q.Lat*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// distribution places pickups around a city centre.
+type distribution interface {
+	pickup(r *rand.Rand) point
+}
+
+// uniform spreads pickups evenly over a disc.
+type uniform struct {
+	center   point
+	radiusKm float64
+}
+
+func (u uniform) pickup(r *rand.Rand) point {
+	// sqrt keeps the density even across the disc instead of piling up in the middle.
+	return u.center.offset(u.radiusKm*math.Sqrt(r.Float64()), 2*math.Pi*r.Float64())
+}
+
+// gaussian concentrates pickups downtown, thinning out towards the edge.
+type gaussian struct {
+	center  point
+	sigmaKm float64
+}
+
+func (g gaussian) pickup(r *rand.Rand) point {
+	return g.center.offset(math.Abs(r.NormFloat64())*g.sigmaKm, 2*math.Pi*r.Float64())
+}
+
+// hotspots clusters pickups around a few fixed points such as stations and
+// airports, picked once per run.
+type hotspots struct {
+	spots   []point
+	sigmaKm float64
+}
+
+func newHotspots(r *rand.Rand, center point, radiusKm float64, n int) hotspots {
+	h := hotspots{sigmaKm: radiusKm / 10}
+	for i := 0; i < n; i++ {
+		h.spots = append(h.spots, uniform{center: center, radiusKm: radiusKm}.pickup(r))
+	}
+	return h
+}
+
+func (h hotspots) pickup(r *rand.Rand) point {
+	spot := h.spots[r.IntN(len(h.spots))]
+	return gaussian{center: spot, sigmaKm: h.sigmaKm}.pickup(r)
+}
+
+func newDistribution(name string, r *rand.Rand, center point, radiusKm float64, nHotspots int) (distribution, error) {
+	switch name {
+	case "uniform":
+		return uniform{center: center, radiusKm: radiusKm}, nil
+	case "gaussian":
+		return gaussian{center: center, sigmaKm: radiusKm / 2}, nil
+	case "hotspots":
+		if nHotspots < 1 {
+			return nil, fmt.Errorf("-hotspots must be at least 1")
+		}
+		return newHotspots(r, center, radiusKm, nHotspots), nil
+	default:
+		return nil, fmt.Errorf("unknown distribution %q (want uniform, gaussian or hotspots)", name)
+	}
+}
+
+// trip picks a pickup from d and a dropoff 1-15 km away, priced in whole rupees
+// from the distance.
+func trip(r *rand.Rand, d distribution) (pickup, dropoff point, price int) {
+	pickup = d.pickup(r)
+	dropoff = pickup.offset(1+14*r.Float64(), 2*math.Pi*r.Float64())
+	return pickup, dropoff, 50 + int(math.Round(pickup.distanceKm(dropoff)*18))
+}
@@ -0,0 +1,168 @@
+// Command ridesim puts production-like load on booking_svc and driver_svc:
+// N riders book rides from a configurable geographic distribution and wait
+// to be picked up, while M drivers poll for jobs and accept them after a
+// human-like delay. It reports booking-to-accept latency percentiles and
+// conflict and error rates.
+//
+// With -booking-url and -driver-url it drives running services through
+// their HTTP APIs, minting tokens with -secret (JWT_HS256_SECRET by default)
+// and using the drivers registered in driver_svc. Without them it boots both
+// services in-process on the in-memory repositories and bus.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"e2e/cluster"
+
+	bookingapp "booking_svc/app"
+	driverapp "driver_svc/app"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "ridesim:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		cfg                           simConfig
+		bookingURL, driverURL, secret string
+		distName, centerFlag          string
+		radiusKm                      float64
+		nHotspots                     int
+		verbose                       bool
+	)
+	flag.IntVar(&cfg.Riders, "riders", 20, "simulated riders")
+	flag.IntVar(&cfg.Drivers, "drivers", 10, "simulated drivers")
+	flag.DurationVar(&cfg.Duration, "duration", 30*time.Second, "how long riders keep booking")
+	flag.StringVar(&distName, "dist", "hotspots", "pickup distribution: uniform, gaussian or hotspots")
+	flag.StringVar(&centerFlag, "center", "12.9716,77.5946", "city centre as lat,lng")
+	flag.Float64Var(&radiusKm, "radius-km", 12, "city radius in km")
+	flag.IntVar(&nHotspots, "hotspots", 4, "number of hotspots for -dist hotspots")
+	flag.DurationVar(&cfg.RiderThink, "rider-think", 3*time.Second, "mean pause between one rider's rides")
+	flag.DurationVar(&cfg.RiderPoll, "rider-poll", 250*time.Millisecond, "how often a waiting rider checks the booking")
+	flag.DurationVar(&cfg.AcceptTimeout, "accept-timeout", 30*time.Second, "how long a rider waits before giving up")
+	flag.DurationVar(&cfg.DriverPoll, "driver-poll", 500*time.Millisecond, "mean interval between a driver's job polls")
+	flag.DurationVar(&cfg.ReactMin, "react-min", 300*time.Millisecond, "fastest a driver accepts after seeing a job")
+	flag.DurationVar(&cfg.ReactMax, "react-max", 2*time.Second, "slowest a driver accepts after seeing a job")
+	flag.DurationVar(&cfg.RideTime, "ride-time", 5*time.Second, "how long a driver is busy after winning a job")
+	flag.Uint64Var(&cfg.Seed, "seed", uint64(time.Now().UnixNano()), "random seed, for reproducible runs")
+	flag.StringVar(&bookingURL, "booking-url", "", "booking_svc base URL; empty runs both services in-process")
+	flag.StringVar(&driverURL, "driver-url", "", "driver_svc base URL; required with -booking-url")
+	flag.StringVar(&secret, "secret", os.Getenv("JWT_HS256_SECRET"), "HS256 secret the services accept, for minting tokens")
+	flag.BoolVar(&verbose, "v", false, "print progress and, in-process, the services' warnings")
+	flag.Parse()
+
+	if cfg.Riders < 1 || cfg.Drivers < 1 {
+		return errors.New("-riders and -drivers must be at least 1")
+	}
+	if cfg.ReactMax < cfg.ReactMin {
+		return errors.New("-react-max must not be below -react-min")
+	}
+	if (bookingURL == "") != (driverURL == "") {
+		return errors.New("set both -booking-url and -driver-url, or neither for in-process mode")
+	}
+	center, err := parsePoint(centerFlag)
+	if err != nil {
+		return fmt.Errorf("-center: %w", err)
+	}
+	if cfg.Dist, err = newDistribution(distName, rand.New(rand.NewPCG(cfg.Seed, 0)), center, radiusKm, nHotspots); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var tgt target
+	if bookingURL == "" {
+		c, err := startLocal(cfg.Drivers, verbose)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		tgt = target{BookingURL: c.BookingURL, DriverURL: c.DriverURL, Token: c.Token}
+		for i := 0; i < cfg.Drivers; i++ {
+			tgt.DriverIDs = append(tgt.DriverIDs, localDriverID(i))
+		}
+	} else {
+		if secret == "" {
+			return errors.New("-secret (or JWT_HS256_SECRET) is required to mint tokens for remote services")
+		}
+		tgt = target{BookingURL: bookingURL, DriverURL: driverURL, Token: func(role, sub string) (string, error) {
+			return bookingapp.Token(secret, role, sub, cfg.Duration+cfg.AcceptTimeout+time.Hour)
+		}}
+		if tgt.DriverIDs, err = remoteDrivers(ctx, tgt, cfg.Drivers); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "ridesim: %d riders, %d drivers, %s, seed %d\n", cfg.Riders, len(tgt.DriverIDs), cfg.Duration, cfg.Seed)
+	if verbose {
+		cfg.Progress = func(s summary) {
+			fmt.Fprintf(os.Stderr, "  %6s created=%d accepted=%d conflicts=%d errors=%d\n",
+				s.Elapsed.Round(time.Second), s.Created, s.Accepted, s.Conflicts, s.Errors)
+		}
+	}
+	sum, err := simulate(ctx, cfg, tgt)
+	if err != nil {
+		return err
+	}
+	sum.print(os.Stdout)
+	return nil
+}
+
+func localDriverID(i int) string {
+	return fmt.Sprintf("sim-d-%d", i+1)
+}
+
+func startLocal(nDrivers int, verbose bool) (*cluster.Cluster, error) {
+	drivers := make([]driverapp.Driver, nDrivers)
+	for i := range drivers {
+		drivers[i] = driverapp.Driver{DriverID: localDriverID(i), Name: fmt.Sprintf("Sim driver %d", i+1), IsAvailable: true}
+	}
+	opts := cluster.Options{Drivers: drivers}
+	if verbose {
+		opts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	}
+	return cluster.Start(opts)
+}
+
+// remoteDrivers asks driver_svc, as an admin, for up to n available drivers
+// to act as.
+func remoteDrivers(ctx context.Context, tgt target, n int) ([]string, error) {
+	tok, err := tgt.Token("admin", "ridesim")
+	if err != nil {
+		return nil, err
+	}
+	c := client{http: &http.Client{Timeout: 10 * time.Second}, baseURL: tgt.DriverURL, token: tok, stats: newStats()}
+	var all []driver
+	if _, err := c.do(ctx, endpointDrivers, http.MethodGet, "/drivers", nil, &all); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, d := range all {
+		if d.IsAvailable && len(ids) < n {
+			ids = append(ids, d.DriverID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("driver_svc has no available drivers")
+	}
+	if len(ids) < n {
+		fmt.Fprintf(os.Stderr, "ridesim: driver_svc only has %d available drivers; simulating those\n", len(ids))
+	}
+	return ids, nil
+}
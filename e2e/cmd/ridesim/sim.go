@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// simConfig shapes the simulated traffic.
+type simConfig struct {
+	Riders, Drivers int
+	// Duration is how long riders keep requesting rides; outstanding
+	// bookings then get up to AcceptTimeout to be taken.
+	Duration      time.Duration
+	Dist          distribution
+	RiderThink    time.Duration // mean pause between one rider's rides
+	RiderPoll     time.Duration
+	AcceptTimeout time.Duration
+	DriverPoll    time.Duration
+	ReactMin      time.Duration // a driver's delay between seeing a job and accepting it
+	ReactMax      time.Duration
+	RideTime      time.Duration // how long a driver is busy after winning a job
+	Seed          uint64
+	Progress      func(summary)
+}
+
+// target is the pair of services under load.
+type target struct {
+	BookingURL, DriverURL string
+	Token                 func(role, sub string) (string, error)
+	DriverIDs             []string
+}
+
+func simulate(ctx context.Context, cfg simConfig, tgt target) (summary, error) {
+	st := newStats()
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	newClient := func(baseURL, role, sub string) (client, error) {
+		tok, err := tgt.Token(role, sub)
+		if err != nil {
+			return client{}, err
+		}
+		return client{http: httpClient, baseURL: baseURL, token: tok, stats: st}, nil
+	}
+
+	riders := make([]client, cfg.Riders)
+	for i := range riders {
+		c, err := newClient(tgt.BookingURL, "rider", fmt.Sprintf("sim-r-%d", i+1))
+		if err != nil {
+			return summary{}, err
+		}
+		riders[i] = c
+	}
+	drivers := make([]client, len(tgt.DriverIDs))
+	for i, id := range tgt.DriverIDs {
+		c, err := newClient(tgt.DriverURL, "driver", id)
+		if err != nil {
+			return summary{}, err
+		}
+		drivers[i] = c
+	}
+
+	start := time.Now()
+	createCtx, stopCreating := context.WithTimeout(ctx, cfg.Duration)
+	defer stopCreating()
+	driverCtx, stopDrivers := context.WithCancel(ctx)
+	defer stopDrivers()
+
+	var driversDone sync.WaitGroup
+	for i, c := range drivers {
+		driversDone.Add(1)
+		go func() {
+			defer driversDone.Done()
+			runDriver(driverCtx, cfg, c, st, rand.New(rand.NewPCG(cfg.Seed, uint64(1_000_000+i))))
+		}()
+	}
+	var ridersDone sync.WaitGroup
+	for i, c := range riders {
+		ridersDone.Add(1)
+		go func() {
+			defer ridersDone.Done()
+			runRider(createCtx, ctx, cfg, c, st, rand.New(rand.NewPCG(cfg.Seed, uint64(i))))
+		}()
+	}
+
+	if cfg.Progress != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			tick := time.NewTicker(5 * time.Second)
+			defer tick.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-tick.C:
+					cfg.Progress(st.summary(time.Since(start)))
+				}
+			}
+		}()
+	}
+
+	ridersDone.Wait()
+	stopDrivers()
+	driversDone.Wait()
+	return st.summary(time.Since(start)), nil
+}
+
+// runRider books a ride, waits for a driver, pauses and repeats until
+// createCtx ends. Waiting continues under ctx so outstanding bookings finish.
+func runRider(createCtx, ctx context.Context, cfg simConfig, c client, st *stats, r *rand.Rand) {
+	// Stagger the first request so riders do not arrive in lockstep.
+	if !sleep(createCtx, time.Duration(r.Int64N(int64(cfg.RiderThink)+1))) {
+		return
+	}
+	for createCtx.Err() == nil {
+		pickup, dropoff, price := trip(r, cfg.Dist)
+		var b booking
+		start := time.Now()
+		if _, err := c.do(createCtx, endpointCreate, http.MethodPost, "/bookings",
+			createBookingRequest{PickupLoc: pickup, Dropoff: dropoff, Price: money{Amount: int64(price) * 100, Currency: "INR"}}, &b); err != nil {
+			if !sleep(createCtx, cfg.RiderPoll) {
+				return
+			}
+			continue
+		}
+		st.bookingCreated()
+
+		switch waitAccepted(ctx, cfg, c, b.BookingID) {
+		case outcomeAccepted:
+			st.bookingAccepted(time.Since(start))
+		case outcomeAbandoned:
+			st.bookingAbandoned()
+		case outcomeInterrupted:
+			return
+		}
+		if !sleep(createCtx, time.Duration(r.ExpFloat64()*float64(cfg.RiderThink))) {
+			return
+		}
+	}
+}
+
+type outcome int
+
+const (
+	outcomeAccepted outcome = iota
+	outcomeAbandoned
+	outcomeInterrupted
+)
+
+// waitAccepted polls the rider's bookings until id is Accepted or
+// AcceptTimeout passes.
+func waitAccepted(ctx context.Context, cfg simConfig, c client, id string) outcome {
+	deadline := time.Now().Add(cfg.AcceptTimeout)
+	for time.Now().Before(deadline) {
+		if !sleep(ctx, cfg.RiderPoll) {
+			return outcomeInterrupted
+		}
+		var bs []booking
+		if _, err := c.do(ctx, endpointBookings, http.MethodGet, "/bookings", nil, &bs); err != nil {
+			continue
+		}
+		for _, b := range bs {
+			if b.BookingID == id && b.RideStatus == "Accepted" {
+				return outcomeAccepted
+			}
+		}
+	}
+	return outcomeAbandoned
+}
+
+// runDriver polls for open jobs, takes a moment to react, tries to accept
+// one and, after a win, is busy for RideTime.
+func runDriver(ctx context.Context, cfg simConfig, c client, st *stats, r *rand.Rand) {
+	for {
+		// Jitter the poll so drivers do not hit the API in lockstep.
+		if !sleep(ctx, cfg.DriverPoll/2+time.Duration(r.Int64N(int64(cfg.DriverPoll)+1))) {
+			return
+		}
+		var jobs []job
+		if _, err := c.do(ctx, endpointJobs, http.MethodGet, "/jobs", nil, &jobs); err != nil || len(jobs) == 0 {
+			continue
+		}
+		pick := jobs[r.IntN(len(jobs))]
+		react := cfg.ReactMin + time.Duration(r.Int64N(int64(cfg.ReactMax-cfg.ReactMin)+1))
+		if !sleep(ctx, react) {
+			return
+		}
+		status, _ := c.do(ctx, endpointAccept, http.MethodPost, "/jobs/"+pick.BookingID+"/accept", nil, nil)
+		switch status {
+		case http.StatusOK:
+			st.acceptResult(true)
+			if !sleep(ctx, cfg.RideTime) {
+				return
+			}
+		case http.StatusConflict:
+			st.acceptResult(false)
+		}
+	}
+}
+
+// sleep waits for d and reports whether ctx is still live.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	sorted := []time.Duration{ms(10), ms(20), ms(30), ms(40), ms(50), ms(60), ms(70), ms(80), ms(90), ms(100)}
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{{50, ms(50)}, {90, ms(90)}, {99, ms(100)}, {0, ms(10)}} {
+		if got := percentile(sorted, tc.p); got != tc.want {
+			t.Errorf("p%v = %s, want %s", tc.p, got, tc.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("empty p50 = %s", got)
+	}
+}
+
+func TestDistributionsStayInTheCity(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	center := point{Lat: 12.9716, Lng: 77.5946}
+	for _, name := range []string{"uniform", "gaussian", "hotspots"} {
+		d, err := newDistribution(name, r, center, 10, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 1000; i++ {
+			pickup, dropoff, price := trip(r, d)
+			// gaussian tails and hotspot spread can leave the radius, but not by much.
+			if km := center.distanceKm(pickup); km > 40 {
+				t.Fatalf("%s: pickup %.1f km from the centre", name, km)
+			}
+			if km := pickup.distanceKm(dropoff); km < 0.9 || km > 15.1 || price <= 0 {
+				t.Fatalf("%s: trip of %.1f km priced %d", name, km, price)
+			}
+		}
+	}
+}
+
+func TestSimulateInProcess(t *testing.T) {
+	c, err := startLocal(3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	cfg := simConfig{
+		Riders: 4, Drivers: 3, Duration: time.Second,
+		Dist:       uniform{center: point{Lat: 12.9716, Lng: 77.5946}, radiusKm: 5},
+		RiderThink: 100 * time.Millisecond, RiderPoll: 100 * time.Millisecond, AcceptTimeout: 5 * time.Second,
+		DriverPoll: 100 * time.Millisecond, ReactMin: 10 * time.Millisecond, ReactMax: 50 * time.Millisecond,
+		RideTime: 50 * time.Millisecond, Seed: 7,
+	}
+	tgt := target{BookingURL: c.BookingURL, DriverURL: c.DriverURL, Token: c.Token,
+		DriverIDs: []string{localDriverID(0), localDriverID(1), localDriverID(2)}}
+
+	sum, err := simulate(context.Background(), cfg, tgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Created == 0 || sum.Accepted != sum.Created || sum.Errors != 0 {
+		t.Fatalf("unexpected run: %+v", sum)
+	}
+	if sum.P50 <= 0 || sum.P99 < sum.P50 || sum.Max < sum.P99 {
+		t.Fatalf("latencies out of order: %+v", sum)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// stats collects results from every simulated rider and driver.
+type stats struct {
+	mu        sync.Mutex
+	requests  map[string]map[int]int // endpoint -> status -> count; 0 is a transport error
+	latencies []time.Duration        // booking created -> seen Accepted by the rider
+	created   int
+	accepted  int
+	abandoned int // bookings no driver took within -accept-timeout
+	wins      int
+	conflicts int
+}
+
+func newStats() *stats {
+	return &stats{requests: make(map[string]map[int]int)}
+}
+
+func (s *stats) request(endpoint string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.requests[endpoint] == nil {
+		s.requests[endpoint] = make(map[int]int)
+	}
+	s.requests[endpoint][status]++
+}
+
+func (s *stats) bookingCreated() {
+	s.mu.Lock()
+	s.created++
+	s.mu.Unlock()
+}
+
+func (s *stats) bookingAccepted(latency time.Duration) {
+	s.mu.Lock()
+	s.accepted++
+	s.latencies = append(s.latencies, latency)
+	s.mu.Unlock()
+}
+
+func (s *stats) bookingAbandoned() {
+	s.mu.Lock()
+	s.abandoned++
+	s.mu.Unlock()
+}
+
+func (s *stats) acceptResult(won bool) {
+	s.mu.Lock()
+	if won {
+		s.wins++
+	} else {
+		s.conflicts++
+	}
+	s.mu.Unlock()
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// summary is a point-in-time copy of the stats, ready to print.
+type summary struct {
+	Elapsed            time.Duration
+	Created, Accepted  int
+	Abandoned, Pending int
+	P50, P90, P99, Max time.Duration
+	AcceptAttempts     int
+	Conflicts          int
+	ConflictRate       float64
+	Requests, Errors   int
+	ErrorRate          float64
+	ByEndpoint         map[string]map[int]int
+}
+
+func (s *stats) summary(elapsed time.Duration) summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lat := slices.Clone(s.latencies)
+	slices.Sort(lat)
+	sum := summary{
+		Elapsed:        elapsed,
+		Created:        s.created,
+		Accepted:       s.accepted,
+		Abandoned:      s.abandoned,
+		Pending:        s.created - s.accepted - s.abandoned,
+		P50:            percentile(lat, 50),
+		P90:            percentile(lat, 90),
+		P99:            percentile(lat, 99),
+		AcceptAttempts: s.wins + s.conflicts,
+		Conflicts:      s.conflicts,
+		ByEndpoint:     make(map[string]map[int]int, len(s.requests)),
+	}
+	if len(lat) > 0 {
+		sum.Max = lat[len(lat)-1]
+	}
+	if sum.AcceptAttempts > 0 {
+		sum.ConflictRate = float64(sum.Conflicts) / float64(sum.AcceptAttempts)
+	}
+	for endpoint, byStatus := range s.requests {
+		sum.ByEndpoint[endpoint] = make(map[int]int, len(byStatus))
+		for status, n := range byStatus {
+			sum.ByEndpoint[endpoint][status] = n
+			sum.Requests += n
+			if isError(endpoint, status) {
+				sum.Errors += n
+			}
+		}
+	}
+	if sum.Requests > 0 {
+		sum.ErrorRate = float64(sum.Errors) / float64(sum.Requests)
+	}
+	return sum
+}
+
+// isError reports whether a response means something went wrong. Losing an
+// accept race is expected and counted as a conflict instead.
+func isError(endpoint string, status int) bool {
+	if endpoint == endpointAccept && status == 409 {
+		return false
+	}
+	return status == 0 || status >= 400
+}
+
+func (sum summary) print(w io.Writer) {
+	fmt.Fprintf(w, "elapsed            %s\n", sum.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "bookings           created=%d accepted=%d abandoned=%d pending=%d (%.1f/s)\n",
+		sum.Created, sum.Accepted, sum.Abandoned, sum.Pending, float64(sum.Created)/sum.Elapsed.Seconds())
+	fmt.Fprintf(w, "booking->accepted  p50=%s p90=%s p99=%s max=%s\n",
+		sum.P50.Round(time.Millisecond), sum.P90.Round(time.Millisecond), sum.P99.Round(time.Millisecond), sum.Max.Round(time.Millisecond))
+	fmt.Fprintf(w, "accept attempts    %d, conflicts=%d (%.1f%%)\n", sum.AcceptAttempts, sum.Conflicts, 100*sum.ConflictRate)
+	fmt.Fprintf(w, "requests           %d, errors=%d (%.2f%%)\n", sum.Requests, sum.Errors, 100*sum.ErrorRate)
+
+	endpoints := make([]string, 0, len(sum.ByEndpoint))
+	for e := range sum.ByEndpoint {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+	for _, e := range endpoints {
+		statuses := make([]int, 0, len(sum.ByEndpoint[e]))
+		for status := range sum.ByEndpoint[e] {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		fmt.Fprintf(w, "  %-22s", e)
+		for _, status := range statuses {
+			label := fmt.Sprint(status)
+			if status == 0 {
+				label = "err"
+			}
+			fmt.Fprintf(w, " %s=%d", label, sum.ByEndpoint[e][status])
+		}
+		fmt.Fprintln(w)
+	}
+}
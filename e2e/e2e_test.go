@@ -0,0 +1,841 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"e2e/cluster"
+
+	bookingapp "booking_svc/app"
+	driverapp "driver_svc/app"
+)
+
+type money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type booking struct {
+	BookingID  string  `json:"booking_id"`
+	RiderID    string  `json:"rider_id"`
+	Price      money   `json:"price"`
+	RideStatus string  `json:"ride_status"`
+	DriverID   *string `json:"driver_id"`
+}
+
+type job struct {
+	BookingID        string  `json:"booking_id"`
+	Price            money   `json:"price"`
+	Status           string  `json:"status"`
+	AcceptedDriverID *string `json:"accepted_driver_id"`
+}
+
+type problemBody struct {
+	Code string `json:"code"`
+}
+
+func startCluster(t *testing.T, drivers ...driverapp.Driver) *cluster.Cluster {
+	t.Helper()
+	c, err := cluster.Start(cluster.Options{Drivers: drivers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func token(t *testing.T, c *cluster.Cluster, role, sub string) string {
+	t.Helper()
+	tok, err := c.Token(role, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+// send sends body as JSON with a bearer token and decodes the response into
+// out, returning the status code. It is safe to use from any goroutine.
+func send(method, url, tok string, body, out any) (int, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("%s %s: decode %d response: %w", method, url, resp.StatusCode, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func call(t *testing.T, method, url, tok string, body, out any) int {
+	t.Helper()
+	status, err := send(method, url, tok, body, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+// eventually polls cond until it holds; the interval stays under the read
+// rate limit.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// newBooking sends price as a bare integer, the legacy whole-unit form.
+func newBooking(price int) map[string]any {
+	return map[string]any{
+		"pickuploc": map[string]float64{"lat": 12.9716, "lng": 77.5946},
+		"dropoff":   map[string]float64{"lat": 12.2958, "lng": 76.6394},
+		"price":     price,
+	}
+}
+
+func createBooking(t *testing.T, c *cluster.Cluster, riderTok string, price int) booking {
+	t.Helper()
+	var b booking
+	status := call(t, http.MethodPost, c.BookingURL+"/bookings", riderTok, newBooking(price), &b)
+	if status != http.StatusCreated || b.BookingID == "" || b.RideStatus != "Requested" {
+		t.Fatalf("create booking: %d %+v", status, b)
+	}
+	return b
+}
+
+func openJobs(t *testing.T, c *cluster.Cluster, driverTok string) map[string]job {
+	t.Helper()
+	var jobs []job
+	if status := call(t, http.MethodGet, c.DriverURL+"/jobs", driverTok, nil, &jobs); status != http.StatusOK {
+		t.Fatalf("list jobs: %d", status)
+	}
+	byID := make(map[string]job, len(jobs))
+	for _, j := range jobs {
+		byID[j.BookingID] = j
+	}
+	return byID
+}
+
+func bookings(t *testing.T, c *cluster.Cluster, tok string) map[string]booking {
+	t.Helper()
+	var bs []booking
+	if status := call(t, http.MethodGet, c.BookingURL+"/bookings", tok, nil, &bs); status != http.StatusOK {
+		t.Fatalf("list bookings: %d", status)
+	}
+	byID := make(map[string]booking, len(bs))
+	for _, b := range bs {
+		byID[b.BookingID] = b
+	}
+	return byID
+}
+
+// accept returns the status and, on failure, the problem code.
+func accept(c *cluster.Cluster, driverTok, bookingID string) (int, string, error) {
+	var p problemBody
+	status, err := send(http.MethodPost, c.DriverURL+"/jobs/"+bookingID+"/accept", driverTok, nil, &p)
+	return status, p.Code, err
+}
+
+func TestBookingIsAcceptedAcrossServices(t *testing.T) {
+	c := startCluster(t)
+	rider, admin := token(t, c, "rider", "r-1"), token(t, c, "admin", "ops")
+	asha, ravi := token(t, c, "driver", "d-1"), token(t, c, "driver", "d-2")
+
+	hooks := make(chan []byte, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hooks <- body
+	}))
+	defer receiver.Close()
+	if status := call(t, http.MethodPost, c.BookingURL+"/webhooks", admin, map[string]any{
+		"url": receiver.URL, "event_types": []string{"booking.accepted"}, "secret": "0123456789abcdef",
+	}, nil); status != http.StatusCreated {
+		t.Fatalf("create webhook: %d", status)
+	}
+
+	b := createBooking(t, c, rider, 220)
+	eventually(t, "the job to open on driver_svc", func() bool {
+		_, ok := openJobs(t, c, asha)[b.BookingID]
+		return ok
+	})
+	if j := openJobs(t, c, asha)[b.BookingID]; j.Price != (money{Amount: 22000, Currency: "INR"}) || j.Status != "Open" {
+		t.Fatalf("unexpected job: %+v", j)
+	}
+
+	if status, _, err := accept(c, asha, b.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	if status, code, err := accept(c, ravi, b.BookingID); status != http.StatusConflict || code != "job_already_taken" || err != nil {
+		t.Fatalf("second accept: %d %s %v", status, code, err)
+	}
+	if _, ok := openJobs(t, c, ravi)[b.BookingID]; ok {
+		t.Fatal("taken job still listed as open")
+	}
+
+	eventually(t, "the booking to be Accepted on booking_svc", func() bool {
+		return bookings(t, c, rider)[b.BookingID].RideStatus == "Accepted"
+	})
+	if got := bookings(t, c, rider)[b.BookingID]; got.DriverID == nil || *got.DriverID != "d-1" {
+		t.Fatalf("booking accepted by the wrong driver: %+v", got)
+	}
+
+	select {
+	case body := <-hooks:
+		var env struct {
+			Type string `json:"type"`
+			Data struct {
+				BookingID string `json:"booking_id"`
+				DriverID  string `json:"driver_id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &env); err != nil {
+			t.Fatal(err)
+		}
+		if env.Type != "booking.accepted" || env.Data.BookingID != b.BookingID || env.Data.DriverID != "d-1" {
+			t.Fatalf("unexpected webhook: %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("booking.accepted webhook not delivered")
+	}
+}
+
+func TestConcurrentAcceptHasOneWinner(t *testing.T) {
+	const nDrivers, nBookings = 8, 4
+	drivers := make([]driverapp.Driver, nDrivers)
+	for i := range drivers {
+		drivers[i] = driverapp.Driver{DriverID: fmt.Sprintf("d-%d", i+1), Name: fmt.Sprintf("Driver %d", i+1), IsAvailable: true}
+	}
+	c := startCluster(t, drivers...)
+	rider := token(t, c, "rider", "r-1")
+	driverToks := make([]string, nDrivers)
+	for i, d := range drivers {
+		driverToks[i] = token(t, c, "driver", d.DriverID)
+	}
+
+	ids := make([]string, nBookings)
+	for i := range ids {
+		ids[i] = createBooking(t, c, rider, 100+i).BookingID
+	}
+	eventually(t, "every job to open", func() bool {
+		return len(openJobs(t, c, driverToks[0])) == nBookings
+	})
+
+	for _, id := range ids {
+		var wg sync.WaitGroup
+		statuses := make([]int, nDrivers)
+		start := make(chan struct{})
+		for i, tok := range driverToks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				var err error
+				if statuses[i], _, err = accept(c, tok, id); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		winner := ""
+		for i, s := range statuses {
+			switch s {
+			case http.StatusOK:
+				if winner != "" {
+					t.Fatalf("booking %s accepted by both %s and %s", id, winner, drivers[i].DriverID)
+				}
+				winner = drivers[i].DriverID
+			case http.StatusConflict:
+			default:
+				t.Fatalf("accept by %s: unexpected status %d", drivers[i].DriverID, s)
+			}
+		}
+		if winner == "" {
+			t.Fatalf("booking %s: no driver won", id)
+		}
+		eventually(t, "booking "+id+" to be Accepted", func() bool {
+			b := bookings(t, c, rider)[id]
+			return b.RideStatus == "Accepted" && b.DriverID != nil && *b.DriverID == winner
+		})
+	}
+}
+
+func TestManyRidersAllGetAccepted(t *testing.T) {
+	const nRiders = 12
+	c := startCluster(t)
+	admin := token(t, c, "admin", "ops")
+	driverToks := []string{token(t, c, "driver", "d-1"), token(t, c, "driver", "d-2")}
+
+	var wg sync.WaitGroup
+	for i := 0; i < nRiders; i++ {
+		rider := token(t, c, "rider", fmt.Sprintf("r-%d", i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, err := send(http.MethodPost, c.BookingURL+"/bookings", rider, newBooking(150), nil); status != http.StatusCreated || err != nil {
+				t.Errorf("create booking: %d %v", status, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	eventually(t, "every job to open", func() bool {
+		return len(openJobs(t, c, driverToks[0])) == nRiders
+	})
+	jobs := openJobs(t, c, driverToks[0])
+	i := 0
+	for id := range jobs {
+		tok := driverToks[i%len(driverToks)]
+		i++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, _, err := accept(c, tok, id); status != http.StatusOK || err != nil {
+				t.Errorf("accept %s: %d %v", id, status, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	eventually(t, "every booking to be Accepted", func() bool {
+		all := bookings(t, c, admin)
+		if len(all) != nRiders {
+			return false
+		}
+		for _, b := range all {
+			if b.RideStatus != "Accepted" {
+				return false
+			}
+		}
+		return true
+	})
+	if left := openJobs(t, c, driverToks[0]); len(left) != 0 {
+		t.Fatalf("open jobs left behind: %d", len(left))
+	}
+}
+
+func TestCancelWithdrawsJobAndCompletionCaptures(t *testing.T) {
+	c := startCluster(t)
+	rider, asha := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1")
+
+	cancelled, trip := createBooking(t, c, rider, 220), createBooking(t, c, rider, 300)
+	eventually(t, "both jobs to open", func() bool {
+		open := openJobs(t, c, asha)
+		_, a := open[cancelled.BookingID]
+		_, b := open[trip.BookingID]
+		return a && b
+	})
+
+	var got booking
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+cancelled.BookingID+"/cancel", rider, nil, &got); status != http.StatusOK || got.RideStatus != "Cancelled" {
+		t.Fatalf("cancel: %d %+v", status, got)
+	}
+	eventually(t, "the cancelled job to be withdrawn", func() bool {
+		_, ok := openJobs(t, c, asha)[cancelled.BookingID]
+		return !ok
+	})
+	if status, code, err := accept(c, asha, cancelled.BookingID); status != http.StatusConflict || code != "job_cancelled" || err != nil {
+		t.Fatalf("accepting a cancelled job: %d %s %v", status, code, err)
+	}
+
+	if status, _, err := accept(c, asha, trip.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	eventually(t, "the trip to be Accepted", func() bool {
+		return bookings(t, c, rider)[trip.BookingID].RideStatus == "Accepted"
+	})
+	fare := map[string]any{"fare": money{Amount: 25000, Currency: "INR"}}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/complete", asha, fare, &got); status != http.StatusOK || got.RideStatus != "Completed" {
+		t.Fatalf("complete: %d %+v", status, got)
+	}
+	var pay struct {
+		Status   string `json:"status"`
+		Captured *money `json:"captured"`
+	}
+	if status := call(t, http.MethodGet, c.BookingURL+"/bookings/"+trip.BookingID+"/payment", rider, nil, &pay); status != http.StatusOK ||
+		pay.Status != "captured" || pay.Captured == nil || pay.Captured.Amount != 25000 {
+		t.Fatalf("payment: %d %+v", status, pay)
+	}
+	// The default 20% commission leaves the driver 80% of the captured fare.
+	var bal struct {
+		Balance money `json:"balance"`
+	}
+	if status := call(t, http.MethodGet, c.BookingURL+"/ledger/drivers/d-1/balance", asha, nil, &bal); status != http.StatusOK || bal.Balance.Amount != 20000 {
+		t.Fatalf("driver balance: %d %+v", status, bal)
+	}
+}
+
+func TestPayoutRunPaysLedgerEarningsOnce(t *testing.T) {
+	c := startCluster(t)
+	rider, asha, ops := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1"), token(t, c, "admin", "ops")
+
+	trip := createBooking(t, c, rider, 300)
+	eventually(t, "the job to open", func() bool {
+		_, ok := openJobs(t, c, asha)[trip.BookingID]
+		return ok
+	})
+	if status, _, err := accept(c, asha, trip.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	eventually(t, "the trip to be Accepted", func() bool {
+		return bookings(t, c, rider)[trip.BookingID].RideStatus == "Accepted"
+	})
+	var got booking
+	// The fare comes in under the quote of 30000.
+	fare := map[string]any{"fare": money{Amount: 28000, Currency: "INR"}}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/complete", asha, fare, &got); status != http.StatusOK {
+		t.Fatalf("complete: %d %+v", status, got)
+	}
+
+	// The ledger credits the driver for the captured fare, not the quote.
+	var bal struct {
+		Balance money `json:"balance"`
+	}
+	if status := call(t, http.MethodGet, c.BookingURL+"/ledger/drivers/d-1/balance", asha, nil, &bal); status != http.StatusOK || bal.Balance.Amount != 22400 {
+		t.Fatalf("driver balance: %d %+v", status, bal)
+	}
+
+	type run struct {
+		Created int `json:"created"`
+		Paid    int `json:"paid"`
+	}
+	// Runs create nothing until driver_svc has recorded the trip's earnings.
+	eventually(t, "a run to pay the trip", func() bool {
+		var r run
+		status := call(t, http.MethodPost, c.DriverURL+"/payouts/runs", ops, nil, &r)
+		return status == http.StatusOK && r == run{Created: 1, Paid: 1}
+	})
+	var r run
+	if status := call(t, http.MethodPost, c.DriverURL+"/payouts/runs", ops, nil, &r); status != http.StatusOK || r != (run{}) {
+		t.Fatalf("second run: %d %+v", status, r)
+	}
+	var payouts []struct {
+		Status string `json:"status"`
+		Amount money  `json:"amount"`
+	}
+	if status := call(t, http.MethodGet, c.DriverURL+"/drivers/d-1/payouts", asha, nil, &payouts); status != http.StatusOK ||
+		len(payouts) != 1 || payouts[0].Status != "paid" || payouts[0].Amount.Amount != 22400 {
+		t.Fatalf("payouts: %d %+v", status, payouts)
+	}
+	// The payout is what the ledger credited, and settles the balance.
+	eventually(t, "the payout to settle the driver's balance", func() bool {
+		status := call(t, http.MethodGet, c.BookingURL+"/ledger/drivers/d-1/balance", asha, nil, &bal)
+		return status == http.StatusOK && bal.Balance.Amount == 0
+	})
+}
+
+func TestPromoCodeDiscountsRiderButNotDriver(t *testing.T) {
+	c := startCluster(t)
+	rider, asha, ops := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1"), token(t, c, "admin", "ops")
+
+	promo := map[string]any{"code": "FLAT50", "kind": "flat", "amount_off": money{Amount: 5000, Currency: "INR"}, "max_per_rider": 1}
+	if status := call(t, http.MethodPost, c.BookingURL+"/promotions", ops, promo, nil); status != http.StatusCreated {
+		t.Fatalf("create promotion: %d", status)
+	}
+	req := newBooking(300)
+	req["promo_code"] = "flat50"
+	var trip struct {
+		booking
+		PromoCode string `json:"promo_code"`
+		Discount  *money `json:"discount"`
+	}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings", rider, req, &trip); status != http.StatusCreated ||
+		trip.PromoCode != "FLAT50" || trip.Discount == nil || trip.Discount.Amount != 5000 || trip.Price.Amount != 30000 {
+		t.Fatalf("create booking: %d %+v", status, trip)
+	}
+	var p problemBody
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings", rider, req, &p); status != http.StatusUnprocessableEntity || p.Code != "promo_already_redeemed" {
+		t.Fatalf("second redemption: %d %+v", status, p)
+	}
+
+	// Drivers see, and are paid on, the full price.
+	eventually(t, "the job to open", func() bool {
+		j, ok := openJobs(t, c, asha)[trip.BookingID]
+		return ok && j.Price.Amount == 30000
+	})
+	if status, _, err := accept(c, asha, trip.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	eventually(t, "the trip to be Accepted", func() bool {
+		return bookings(t, c, rider)[trip.BookingID].RideStatus == "Accepted"
+	})
+	var done struct {
+		Fare *money `json:"fare"`
+	}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/complete", asha, nil, &done); status != http.StatusOK ||
+		done.Fare == nil || done.Fare.Amount != 25000 {
+		t.Fatalf("complete: %d %+v", status, done)
+	}
+	var bal struct {
+		Balance money `json:"balance"`
+	}
+	if status := call(t, http.MethodGet, c.BookingURL+"/ledger/drivers/d-1/balance", asha, nil, &bal); status != http.StatusOK || bal.Balance.Amount != 24000 {
+		t.Fatalf("driver balance: %d %+v", status, bal)
+	}
+	eventually(t, "a run to pay the trip", func() bool {
+		var r struct {
+			Created int `json:"created"`
+		}
+		return call(t, http.MethodPost, c.DriverURL+"/payouts/runs", ops, nil, &r) == http.StatusOK && r.Created == 1
+	})
+	var payouts []struct {
+		Amount money `json:"amount"`
+	}
+	if status := call(t, http.MethodGet, c.DriverURL+"/drivers/d-1/payouts", asha, nil, &payouts); status != http.StatusOK ||
+		len(payouts) != 1 || payouts[0].Amount.Amount != 24000 {
+		t.Fatalf("payouts: %d %+v", status, payouts)
+	}
+}
+
+func TestRatingsUpdateDriverAverage(t *testing.T) {
+	c := startCluster(t)
+	rider, asha := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1")
+
+	trip := createBooking(t, c, rider, 200)
+	rating := map[string]any{"stars": 4, "comment": "smooth ride"}
+	var p problemBody
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/rating", rider, rating, &p); status != http.StatusConflict || p.Code != "booking_not_completed" {
+		t.Fatalf("rating before completion: %d %+v", status, p)
+	}
+
+	eventually(t, "the job to open", func() bool {
+		_, ok := openJobs(t, c, asha)[trip.BookingID]
+		return ok
+	})
+	if status, _, err := accept(c, asha, trip.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	eventually(t, "the trip to be Accepted", func() bool {
+		return bookings(t, c, rider)[trip.BookingID].RideStatus == "Accepted"
+	})
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/complete", asha, nil, nil); status != http.StatusOK {
+		t.Fatalf("complete: %d", status)
+	}
+
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/rating", rider, rating, nil); status != http.StatusCreated {
+		t.Fatalf("rider rating: %d", status)
+	}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/rating", rider, map[string]any{"stars": 1}, &p); status != http.StatusConflict || p.Code != "rating_exists" {
+		t.Fatalf("second rider rating: %d %+v", status, p)
+	}
+	// The driver's rating of the rider does not count towards their own.
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/rider-rating", asha, map[string]any{"stars": 2}, nil); status != http.StatusCreated {
+		t.Fatalf("driver rating: %d", status)
+	}
+
+	type driver struct {
+		DriverID string `json:"driver_id"`
+		Rating   struct {
+			Average float64 `json:"average"`
+			Count   int     `json:"count"`
+		} `json:"rating"`
+	}
+	eventually(t, "d-1's average to include the rating", func() bool {
+		var drivers []driver
+		if status := call(t, http.MethodGet, c.DriverURL+"/drivers", asha, nil, &drivers); status != http.StatusOK {
+			t.Fatalf("list drivers: %d", status)
+		}
+		for _, d := range drivers {
+			if d.DriverID == "d-1" {
+				return d.Rating.Count == 1 && d.Rating.Average == 4
+			}
+		}
+		return false
+	})
+}
+
+func TestNoShowChargesTheRider(t *testing.T) {
+	c, err := cluster.Start(cluster.Options{Configure: func(b *bookingapp.Config, _ *driverapp.Config) {
+		// The rider is a no-show as soon as the driver arrives.
+		b.NoShowWait, b.NoShowFeeBPS = 0, 2000
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	rider, asha := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1")
+
+	trip := createBooking(t, c, rider, 220)
+	var p problemBody
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/no-show", asha, nil, &p); status != http.StatusNotFound || p.Code != "booking_not_found" {
+		t.Fatalf("no-show by a driver not assigned: %d %+v", status, p)
+	}
+	eventually(t, "the job to open", func() bool {
+		_, ok := openJobs(t, c, asha)[trip.BookingID]
+		return ok
+	})
+	if status, _, err := accept(c, asha, trip.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	eventually(t, "the trip to be Accepted", func() bool {
+		return bookings(t, c, rider)[trip.BookingID].RideStatus == "Accepted"
+	})
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/no-show", asha, nil, &p); status != http.StatusConflict || p.Code != "no_show_too_early" {
+		t.Fatalf("no-show before arriving: %d %+v", status, p)
+	}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/arrive", asha, nil, nil); status != http.StatusOK {
+		t.Fatalf("arrive: %d", status)
+	}
+
+	var got struct {
+		RideStatus   string `json:"ride_status"`
+		Cancellation *struct {
+			Reason string `json:"reason"`
+			Fee    money  `json:"fee"`
+		} `json:"cancellation"`
+	}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/no-show", asha, nil, &got); status != http.StatusOK ||
+		got.RideStatus != "Cancelled" || got.Cancellation == nil || got.Cancellation.Reason != "no_show" || got.Cancellation.Fee.Amount != 4400 {
+		t.Fatalf("no-show: %d %+v", status, got)
+	}
+	var pay struct {
+		Status   string `json:"status"`
+		Captured *money `json:"captured"`
+	}
+	if status := call(t, http.MethodGet, c.BookingURL+"/bookings/"+trip.BookingID+"/payment", rider, nil, &pay); status != http.StatusOK ||
+		pay.Status != "captured" || pay.Captured == nil || pay.Captured.Amount != 4400 {
+		t.Fatalf("payment: %d %+v", status, pay)
+	}
+}
+
+func TestScheduledRideIsReleasedToDrivers(t *testing.T) {
+	c, err := cluster.Start(cluster.Options{Configure: func(b *bookingapp.Config, _ *driverapp.Config) {
+		b.ScheduleLead, b.ScheduleInterval = 2*time.Second, 100*time.Millisecond
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	rider, asha := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1")
+
+	reserve := func(at time.Time) (int, booking) {
+		body := newBooking(220)
+		body["scheduled_for"] = at.UTC().Format(time.RFC3339Nano)
+		var b booking
+		return call(t, http.MethodPost, c.BookingURL+"/bookings", rider, body, &b), b
+	}
+	if status, _ := reserve(time.Now().Add(time.Second)); status != http.StatusBadRequest {
+		t.Fatalf("reserving inside the lead time: %d", status)
+	}
+	pickup := time.Now().Add(4 * time.Second).Truncate(time.Millisecond)
+	status, soon := reserve(pickup)
+	if status != http.StatusCreated || soon.RideStatus != "Scheduled" {
+		t.Fatalf("reserve: %d %+v", status, soon)
+	}
+	status, later := reserve(time.Now().Add(time.Hour))
+	if status != http.StatusCreated {
+		t.Fatalf("reserve later: %d", status)
+	}
+	if _, ok := openJobs(t, c, asha)[soon.BookingID]; ok {
+		t.Fatal("a reservation must not reach drivers before its lead time")
+	}
+	var upcoming []booking
+	if status := call(t, http.MethodGet, c.BookingURL+"/bookings/scheduled", rider, nil, &upcoming); status != http.StatusOK ||
+		len(upcoming) != 2 || upcoming[0].BookingID != soon.BookingID || upcoming[1].BookingID != later.BookingID {
+		t.Fatalf("scheduled: %d %+v", status, upcoming)
+	}
+	var cancelled booking
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+later.BookingID+"/cancel", rider, nil, &cancelled); status != http.StatusOK || cancelled.RideStatus != "Cancelled" {
+		t.Fatalf("cancel reservation: %d %+v", status, cancelled)
+	}
+
+	eventually(t, "the reservation to be released to drivers", func() bool {
+		_, ok := openJobs(t, c, asha)[soon.BookingID]
+		return ok
+	})
+	if b := bookings(t, c, rider)[soon.BookingID]; b.RideStatus != "Requested" {
+		t.Fatalf("released booking: %+v", b)
+	}
+	var jobs []struct {
+		BookingID    string     `json:"booking_id"`
+		ScheduledFor *time.Time `json:"scheduled_for"`
+	}
+	if status := call(t, http.MethodGet, c.DriverURL+"/jobs", asha, nil, &jobs); status != http.StatusOK || len(jobs) != 1 ||
+		jobs[0].ScheduledFor == nil || !jobs[0].ScheduledFor.Equal(pickup) {
+		t.Fatalf("jobs: %d %+v", status, jobs)
+	}
+	if status := call(t, http.MethodGet, c.BookingURL+"/bookings/scheduled", rider, nil, &upcoming); status != http.StatusOK || len(upcoming) != 0 {
+		t.Fatalf("scheduled after release: %d %+v", status, upcoming)
+	}
+}
+
+func TestMultiStopRideIsQuotedDispatchedAndReachedInOrder(t *testing.T) {
+	c := startCluster(t)
+	rider, asha := token(t, c, "rider", "r-1"), token(t, c, "driver", "d-1")
+	stops := []map[string]float64{{"lat": 12.9141, "lng": 77.6101}, {"lat": 12.5218, "lng": 76.8951}}
+
+	type estimate struct {
+		DistanceKm float64 `json:"distance_km"`
+		Stops      int     `json:"stops"`
+		Fare       money   `json:"fare"`
+	}
+	route := newBooking(220)
+	delete(route, "price")
+	var direct, via estimate
+	if status := call(t, http.MethodPost, c.BookingURL+"/fares/estimate", rider, route, &direct); status != http.StatusOK || direct.Stops != 0 {
+		t.Fatalf("direct estimate: %d %+v", status, direct)
+	}
+	route["stops"] = stops
+	if status := call(t, http.MethodPost, c.BookingURL+"/fares/estimate", rider, route, &via); status != http.StatusOK ||
+		via.Stops != 2 || via.DistanceKm <= direct.DistanceKm || via.Fare.Amount <= direct.Fare.Amount {
+		t.Fatalf("estimate with stops: %d %+v, direct %+v", status, via, direct)
+	}
+
+	// The price of a route with stops is the quote; any other is refused.
+	body := newBooking(220)
+	body["stops"] = stops
+	var refused problemBody
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings", rider, body, &refused); status != http.StatusBadRequest || refused.Code != "validation_failed" {
+		t.Fatalf("create at a made-up price: %d %+v", status, refused)
+	}
+	body["price"] = via.Fare
+	var trip booking
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings", rider, body, &trip); status != http.StatusCreated || trip.Price != via.Fare {
+		t.Fatalf("create at the quote: %d %+v", status, trip)
+	}
+	eventually(t, "the job to open", func() bool {
+		_, ok := openJobs(t, c, asha)[trip.BookingID]
+		return ok
+	})
+	var jobs []struct {
+		BookingID string               `json:"booking_id"`
+		Stops     []map[string]float64 `json:"stops"`
+	}
+	if status := call(t, http.MethodGet, c.DriverURL+"/jobs", asha, nil, &jobs); status != http.StatusOK || len(jobs) != 1 ||
+		len(jobs[0].Stops) != 2 || jobs[0].Stops[1]["lat"] != stops[1]["lat"] {
+		t.Fatalf("jobs: %d %+v", status, jobs)
+	}
+	if status, _, err := accept(c, asha, trip.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("accept: %d %v", status, err)
+	}
+	eventually(t, "the trip to be Accepted", func() bool {
+		return bookings(t, c, rider)[trip.BookingID].RideStatus == "Accepted"
+	})
+
+	stopURL := func(n int) string {
+		return fmt.Sprintf("%s/bookings/%s/stops/%d/arrive", c.BookingURL, trip.BookingID, n)
+	}
+	var p problemBody
+	if status := call(t, http.MethodPost, stopURL(2), asha, nil, &p); status != http.StatusConflict || p.Code != "stop_out_of_order" {
+		t.Fatalf("second stop first: %d %+v", status, p)
+	}
+	var got struct {
+		Stops []struct {
+			ArrivedAt *time.Time `json:"arrived_at"`
+		} `json:"stops"`
+	}
+	for n := 1; n <= 2; n++ {
+		if status := call(t, http.MethodPost, stopURL(n), asha, nil, &got); status != http.StatusOK || len(got.Stops) != 2 || got.Stops[n-1].ArrivedAt == nil {
+			t.Fatalf("stop %d: %d %+v", n, status, got)
+		}
+	}
+	if status := call(t, http.MethodPost, stopURL(3), asha, nil, &p); status != http.StatusNotFound || p.Code != "stop_not_found" {
+		t.Fatalf("missing stop: %d %+v", status, p)
+	}
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings/"+trip.BookingID+"/complete", asha, nil, nil); status != http.StatusOK {
+		t.Fatalf("complete: %d", status)
+	}
+}
+
+func TestVehicleTypeAndSeatsDecideWhoSeesTheJob(t *testing.T) {
+	// Seeded drivers: Asha drives a sedan for 4, Ravi an auto for 3.
+	c := startCluster(t)
+	rider, admin := token(t, c, "rider", "r-1"), token(t, c, "admin", "ops")
+	asha, ravi := token(t, c, "driver", "d-1"), token(t, c, "driver", "d-2")
+
+	create := func(vehicleType string, passengers int) booking {
+		t.Helper()
+		body := newBooking(220)
+		if vehicleType != "" {
+			body["vehicle_type"] = vehicleType
+		}
+		body["passengers"] = passengers
+		var b booking
+		if status := call(t, http.MethodPost, c.BookingURL+"/bookings", rider, body, &b); status != http.StatusCreated {
+			t.Fatalf("create %s for %d: %d", vehicleType, passengers, status)
+		}
+		return b
+	}
+	var p problemBody
+	crowded := newBooking(220)
+	crowded["vehicle_type"], crowded["passengers"] = "auto", 4
+	if status := call(t, http.MethodPost, c.BookingURL+"/bookings", rider, crowded, &p); status != http.StatusBadRequest || p.Code != "validation_failed" {
+		t.Fatalf("4 in an auto: %d %+v", status, p)
+	}
+
+	four, suv := create("", 4), create("suv", 5)
+	eventually(t, "both jobs to open", func() bool {
+		open := openJobs(t, c, admin)
+		_, okFour := open[four.BookingID]
+		_, okSUV := open[suv.BookingID]
+		return okFour && okSUV
+	})
+	if open := openJobs(t, c, asha); len(open) != 1 || open[four.BookingID].BookingID == "" {
+		t.Fatalf("Asha's sedan seats 4 and is no suv: %+v", open)
+	}
+	if open := openJobs(t, c, ravi); len(open) != 0 {
+		t.Fatalf("Ravi's auto seats 3: %+v", open)
+	}
+	if status, code, err := accept(c, asha, suv.BookingID); status != http.StatusConflict || code != "vehicle_mismatch" || err != nil {
+		t.Fatalf("Asha accepting the suv ride: %d %s %v", status, code, err)
+	}
+
+	vehicleURL := c.DriverURL + "/drivers/d-2/vehicle"
+	if status := call(t, http.MethodPut, vehicleURL, ravi, map[string]any{"type": "suv", "seats": 6, "plate": "ka01ab1234"}, &p); status != http.StatusConflict || p.Code != "plate_taken" {
+		t.Fatalf("Asha's plate: %d %+v", status, p)
+	}
+	if status := call(t, http.MethodPut, c.DriverURL+"/drivers/d-1/vehicle", ravi, map[string]any{"type": "suv", "seats": 6, "plate": "KA05XY0001"}, &p); status != http.StatusForbidden {
+		t.Fatalf("Ravi registering Asha's vehicle: %d", status)
+	}
+	var d struct {
+		Vehicle struct {
+			Type  string `json:"type"`
+			Seats int    `json:"seats"`
+			Plate string `json:"plate"`
+		} `json:"vehicle"`
+	}
+	if status := call(t, http.MethodPut, vehicleURL, ravi, map[string]any{"type": "suv", "seats": 6, "plate": "ka 05 xy 0001"}, &d); status != http.StatusOK ||
+		d.Vehicle.Type != "suv" || d.Vehicle.Seats != 6 || d.Vehicle.Plate != "KA 05 XY 0001" {
+		t.Fatalf("register suv: %d %+v", status, d)
+	}
+	if open := openJobs(t, c, ravi); len(open) != 2 {
+		t.Fatalf("Ravi's suv serves both rides: %+v", open)
+	}
+	if status, _, err := accept(c, ravi, suv.BookingID); status != http.StatusOK || err != nil {
+		t.Fatalf("Ravi accepting the suv ride: %d %v", status, err)
+	}
+	eventually(t, "the suv ride to be Accepted", func() bool {
+		return bookings(t, c, rider)[suv.BookingID].RideStatus == "Accepted"
+	})
+}